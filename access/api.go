@@ -6,7 +6,9 @@ import (
 	"github.com/onflow/flow/protobuf/go/flow/access"
 	"github.com/onflow/flow/protobuf/go/flow/entities"
 
+	"github.com/onflow/flow-go/engine/access/subscription"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/fvm/meter"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -26,11 +28,14 @@ type API interface {
 	GetCollectionByID(ctx context.Context, id flow.Identifier) (*flow.LightCollection, error)
 
 	SendTransaction(ctx context.Context, tx *flow.TransactionBody) error
+	DryRunTransaction(ctx context.Context, tx *flow.TransactionBody, skipSignatureVerification bool) (*TransactionDryRunResult, error)
 	GetTransaction(ctx context.Context, id flow.Identifier) (*flow.TransactionBody, error)
 	GetTransactionsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*flow.TransactionBody, error)
 	GetTransactionResult(ctx context.Context, id flow.Identifier) (*TransactionResult, error)
 	GetTransactionResultByIndex(ctx context.Context, blockID flow.Identifier, index uint32) (*TransactionResult, error)
 	GetTransactionResultsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*TransactionResult, error)
+	SubscribeTransactionStatuses(ctx context.Context, id flow.Identifier) subscription.Subscription
+	GetTransactionsByAccount(ctx context.Context, address flow.Address, startHeight, endHeight uint64, cursor *flow.AccountTransactionCursor, limit uint) (*AccountTransactions, error)
 
 	GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetAccountAtLatestBlock(ctx context.Context, address flow.Address) (*flow.Account, error)
@@ -39,9 +44,12 @@ type API interface {
 	ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments [][]byte) ([]byte, error)
 	ExecuteScriptAtBlockHeight(ctx context.Context, blockHeight uint64, script []byte, arguments [][]byte) ([]byte, error)
 	ExecuteScriptAtBlockID(ctx context.Context, blockID flow.Identifier, script []byte, arguments [][]byte) ([]byte, error)
+	ExecuteScriptsAtBlockHeight(ctx context.Context, blockHeight uint64, scripts []Script) ([]ScriptResult, error)
+	ExecuteScriptsAtBlockID(ctx context.Context, blockID flow.Identifier, scripts []Script) ([]ScriptResult, error)
 
 	GetEventsForHeightRange(ctx context.Context, eventType string, startHeight, endHeight uint64) ([]flow.BlockEvents, error)
 	GetEventsForBlockIDs(ctx context.Context, eventType string, blockIDs []flow.Identifier) ([]flow.BlockEvents, error)
+	GetEventsByType(ctx context.Context, eventType string, startHeight, endHeight uint64, cursor *EventsCursor, limit uint) (*EventsPage, error)
 
 	GetLatestProtocolStateSnapshot(ctx context.Context) ([]byte, error)
 
@@ -49,6 +57,63 @@ type API interface {
 	GetExecutionResultByID(ctx context.Context, id flow.Identifier) (*flow.ExecutionResult, error)
 }
 
+// AccountTransactions is a page of the transactions an account was involved in, ordered by block
+// height and transaction ID.
+type AccountTransactions struct {
+	Transactions []flow.AccountTransaction
+	// NextCursor points to the last returned transaction if more transactions may be available,
+	// and is nil otherwise.
+	NextCursor *flow.AccountTransactionCursor
+}
+
+// TransactionDryRunResult is the result of executing a transaction against the latest sealed
+// execution state, without committing its changes.
+type TransactionDryRunResult struct {
+	BlockID         flow.Identifier
+	BlockHeight     uint64
+	ComputationUsed uint64
+	MemoryEstimate  uint64
+	// ComputationBreakdown is the computation used by each computation kind, and MemoryBreakdown
+	// the memory estimate of each memory kind
+	ComputationBreakdown meter.ComputationBreakdown
+	MemoryBreakdown      meter.MemoryBreakdown
+	// Fees is the amount of fees the payer would be charged, as a UFix64
+	Fees   uint64
+	Events []flow.Event
+	// StorageDelta are the registers the transaction would update
+	StorageDelta flow.RegisterEntries
+	// ErrorMessage is the error the transaction would fail with, and empty if it would succeed
+	ErrorMessage string
+}
+
+// Script is a script to execute as part of a batch of scripts.
+type Script struct {
+	Code      []byte
+	Arguments [][]byte
+}
+
+// ScriptResult is the result of a script executed as part of a batch of scripts. Either the JSON-CDC
+// encoded Value or Err is set.
+type ScriptResult struct {
+	Value []byte
+	Err   error
+}
+
+// EventsPage is a page of the blocks containing events of a type, ordered by block height. Blocks
+// without events of the type are not included.
+type EventsPage struct {
+	Events []flow.BlockEvents
+	// NextCursor points to the last returned block if more blocks may be available, and is nil
+	// otherwise.
+	NextCursor *EventsCursor
+}
+
+// EventsCursor points to a block of a page of events.
+type EventsCursor struct {
+	BlockHeight uint64
+	BlockID     flow.Identifier
+}
+
 // TODO: Combine this with flow.TransactionResult?
 type TransactionResult struct {
 	Status        flow.TransactionStatus
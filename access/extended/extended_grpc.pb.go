@@ -0,0 +1,261 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: access/extended/extended.proto
+
+package extended
+
+import (
+	context "context"
+	access "github.com/onflow/flow/protobuf/go/flow/access"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ExtendedAccessAPIClient is the client API for ExtendedAccessAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ExtendedAccessAPIClient interface {
+	// GetTransactionsByAccount returns a page of the transactions an account was involved in between
+	// the start and end heights (inclusive), ordered by block height and transaction ID. Requires the
+	// account transaction index to be enabled.
+	GetTransactionsByAccount(ctx context.Context, in *GetTransactionsByAccountRequest, opts ...grpc.CallOption) (*GetTransactionsByAccountResponse, error)
+	// SubscribeEvents streams the events of each sealed block matching the filter, starting at the
+	// requested block, up until the latest block with available execution data. The stream then
+	// remains open and a response is sent for each new block once its execution data is available.
+	//
+	// A response is sent for every block, even if none of its events match the filter, so clients
+	// can resume a broken stream by subscribing again from the height after the last one received.
+	// Requires the state stream to be enabled.
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeEventsClient, error)
+	// SubscribeTransactionStatuses streams the result of a transaction each time its status changes,
+	// starting with its current status. Statuses skipped between two finalized blocks are sent as
+	// well, so every transition is received in order. The stream is closed after the transaction is
+	// sealed or expired.
+	SubscribeTransactionStatuses(ctx context.Context, in *SubscribeTransactionStatusesRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeTransactionStatusesClient, error)
+}
+
+type extendedAccessAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExtendedAccessAPIClient(cc grpc.ClientConnInterface) ExtendedAccessAPIClient {
+	return &extendedAccessAPIClient{cc}
+}
+
+func (c *extendedAccessAPIClient) GetTransactionsByAccount(ctx context.Context, in *GetTransactionsByAccountRequest, opts ...grpc.CallOption) (*GetTransactionsByAccountResponse, error) {
+	out := new(GetTransactionsByAccountResponse)
+	err := c.cc.Invoke(ctx, "/flow.access.extended.ExtendedAccessAPI/GetTransactionsByAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *extendedAccessAPIClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ExtendedAccessAPI_ServiceDesc.Streams[0], "/flow.access.extended.ExtendedAccessAPI/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &extendedAccessAPISubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ExtendedAccessAPI_SubscribeEventsClient interface {
+	Recv() (*SubscribeEventsResponse, error)
+	grpc.ClientStream
+}
+
+type extendedAccessAPISubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *extendedAccessAPISubscribeEventsClient) Recv() (*SubscribeEventsResponse, error) {
+	m := new(SubscribeEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *extendedAccessAPIClient) SubscribeTransactionStatuses(ctx context.Context, in *SubscribeTransactionStatusesRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeTransactionStatusesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ExtendedAccessAPI_ServiceDesc.Streams[1], "/flow.access.extended.ExtendedAccessAPI/SubscribeTransactionStatuses", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &extendedAccessAPISubscribeTransactionStatusesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ExtendedAccessAPI_SubscribeTransactionStatusesClient interface {
+	Recv() (*access.TransactionResultResponse, error)
+	grpc.ClientStream
+}
+
+type extendedAccessAPISubscribeTransactionStatusesClient struct {
+	grpc.ClientStream
+}
+
+func (x *extendedAccessAPISubscribeTransactionStatusesClient) Recv() (*access.TransactionResultResponse, error) {
+	m := new(access.TransactionResultResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ExtendedAccessAPIServer is the server API for ExtendedAccessAPI service.
+// All implementations must embed UnimplementedExtendedAccessAPIServer
+// for forward compatibility
+type ExtendedAccessAPIServer interface {
+	// GetTransactionsByAccount returns a page of the transactions an account was involved in between
+	// the start and end heights (inclusive), ordered by block height and transaction ID. Requires the
+	// account transaction index to be enabled.
+	GetTransactionsByAccount(context.Context, *GetTransactionsByAccountRequest) (*GetTransactionsByAccountResponse, error)
+	// SubscribeEvents streams the events of each sealed block matching the filter, starting at the
+	// requested block, up until the latest block with available execution data. The stream then
+	// remains open and a response is sent for each new block once its execution data is available.
+	//
+	// A response is sent for every block, even if none of its events match the filter, so clients
+	// can resume a broken stream by subscribing again from the height after the last one received.
+	// Requires the state stream to be enabled.
+	SubscribeEvents(*SubscribeEventsRequest, ExtendedAccessAPI_SubscribeEventsServer) error
+	// SubscribeTransactionStatuses streams the result of a transaction each time its status changes,
+	// starting with its current status. Statuses skipped between two finalized blocks are sent as
+	// well, so every transition is received in order. The stream is closed after the transaction is
+	// sealed or expired.
+	SubscribeTransactionStatuses(*SubscribeTransactionStatusesRequest, ExtendedAccessAPI_SubscribeTransactionStatusesServer) error
+	mustEmbedUnimplementedExtendedAccessAPIServer()
+}
+
+// UnimplementedExtendedAccessAPIServer must be embedded to have forward compatible implementations.
+type UnimplementedExtendedAccessAPIServer struct {
+}
+
+func (UnimplementedExtendedAccessAPIServer) GetTransactionsByAccount(context.Context, *GetTransactionsByAccountRequest) (*GetTransactionsByAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionsByAccount not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) SubscribeEvents(*SubscribeEventsRequest, ExtendedAccessAPI_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) SubscribeTransactionStatuses(*SubscribeTransactionStatusesRequest, ExtendedAccessAPI_SubscribeTransactionStatusesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTransactionStatuses not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) mustEmbedUnimplementedExtendedAccessAPIServer() {}
+
+// UnsafeExtendedAccessAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExtendedAccessAPIServer will
+// result in compilation errors.
+type UnsafeExtendedAccessAPIServer interface {
+	mustEmbedUnimplementedExtendedAccessAPIServer()
+}
+
+func RegisterExtendedAccessAPIServer(s grpc.ServiceRegistrar, srv ExtendedAccessAPIServer) {
+	s.RegisterService(&ExtendedAccessAPI_ServiceDesc, srv)
+}
+
+func _ExtendedAccessAPI_GetTransactionsByAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionsByAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtendedAccessAPIServer).GetTransactionsByAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flow.access.extended.ExtendedAccessAPI/GetTransactionsByAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtendedAccessAPIServer).GetTransactionsByAccount(ctx, req.(*GetTransactionsByAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExtendedAccessAPI_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExtendedAccessAPIServer).SubscribeEvents(m, &extendedAccessAPISubscribeEventsServer{stream})
+}
+
+type ExtendedAccessAPI_SubscribeEventsServer interface {
+	Send(*SubscribeEventsResponse) error
+	grpc.ServerStream
+}
+
+type extendedAccessAPISubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *extendedAccessAPISubscribeEventsServer) Send(m *SubscribeEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ExtendedAccessAPI_SubscribeTransactionStatuses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTransactionStatusesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExtendedAccessAPIServer).SubscribeTransactionStatuses(m, &extendedAccessAPISubscribeTransactionStatusesServer{stream})
+}
+
+type ExtendedAccessAPI_SubscribeTransactionStatusesServer interface {
+	Send(*access.TransactionResultResponse) error
+	grpc.ServerStream
+}
+
+type extendedAccessAPISubscribeTransactionStatusesServer struct {
+	grpc.ServerStream
+}
+
+func (x *extendedAccessAPISubscribeTransactionStatusesServer) Send(m *access.TransactionResultResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ExtendedAccessAPI_ServiceDesc is the grpc.ServiceDesc for ExtendedAccessAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExtendedAccessAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "flow.access.extended.ExtendedAccessAPI",
+	HandlerType: (*ExtendedAccessAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTransactionsByAccount",
+			Handler:    _ExtendedAccessAPI_GetTransactionsByAccount_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _ExtendedAccessAPI_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeTransactionStatuses",
+			Handler:       _ExtendedAccessAPI_SubscribeTransactionStatuses_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "access/extended/extended.proto",
+}
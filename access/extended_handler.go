@@ -0,0 +1,212 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/onflow/flow-go/access/extended"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ExtendedHandler serves the extended.ExtendedAccessAPI, the methods of the access API which are not
+// part of the flow.access.AccessAPI.
+type ExtendedHandler struct {
+	extended.UnimplementedExtendedAccessAPIServer
+
+	api   API
+	chain flow.Chain
+
+	// stateStreamAPI serves the streaming endpoints, and is nil if the state stream is not enabled
+	stateStreamAPI    state_stream.API
+	stateStreamConfig state_stream.Config
+}
+
+var _ extended.ExtendedAccessAPIServer = (*ExtendedHandler)(nil)
+
+// NewExtendedHandler creates a new ExtendedHandler. stateStreamAPI may be nil if the state stream
+// is not enabled on the node.
+func NewExtendedHandler(api API, chain flow.Chain, stateStreamAPI state_stream.API, stateStreamConfig state_stream.Config) *ExtendedHandler {
+	return &ExtendedHandler{
+		api:               api,
+		chain:             chain,
+		stateStreamAPI:    stateStreamAPI,
+		stateStreamConfig: stateStreamConfig,
+	}
+}
+
+// GetTransactionsByAccount returns a page of the transactions an account was involved in.
+func (h *ExtendedHandler) GetTransactionsByAccount(
+	ctx context.Context,
+	req *extended.GetTransactionsByAccountRequest,
+) (*extended.GetTransactionsByAccountResponse, error) {
+	address, err := convert.Address(req.GetAddress(), h.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor *flow.AccountTransactionCursor
+	if req.GetCursor() != nil {
+		txID, err := convert.TransactionID(req.GetCursor().GetTransactionId())
+		if err != nil {
+			return nil, err
+		}
+		cursor = &flow.AccountTransactionCursor{
+			BlockHeight:   req.GetCursor().GetBlockHeight(),
+			TransactionID: txID,
+		}
+	}
+
+	page, err := h.api.GetTransactionsByAccount(ctx, address, req.GetStartHeight(), req.GetEndHeight(), cursor, uint(req.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+
+	return AccountTransactionsToMessage(page), nil
+}
+
+// SubscribeEvents streams the events of each sealed block matching the filter, starting at the
+// requested block.
+func (h *ExtendedHandler) SubscribeEvents(
+	req *extended.SubscribeEventsRequest,
+	stream extended.ExtendedAccessAPI_SubscribeEventsServer,
+) error {
+	if h.stateStreamAPI == nil {
+		return status.Error(codes.Unavailable, "event streaming is not enabled on this node")
+	}
+
+	startBlockID := flow.ZeroID
+	if req.GetStartBlockId() != nil {
+		blockID, err := convert.BlockID(req.GetStartBlockId())
+		if err != nil {
+			return err
+		}
+		startBlockID = blockID
+	}
+
+	filter, err := state_stream.NewEventFilter(
+		h.stateStreamConfig.EventFilterConfig,
+		h.chain,
+		req.GetFilter().GetEventType(),
+		req.GetFilter().GetAddress(),
+		req.GetFilter().GetContract(),
+	)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid event filter: %v", err)
+	}
+
+	sub := h.stateStreamAPI.SubscribeEvents(stream.Context(), startBlockID, req.GetStartBlockHeight(), filter)
+
+	return streamSubscription(sub, func(v interface{}) error {
+		blockEvents, ok := v.(*flow.BlockEvents)
+		if !ok {
+			return status.Errorf(codes.Internal, "unexpected response type: %T", v)
+		}
+
+		return stream.Send(&extended.SubscribeEventsResponse{
+			BlockId:        convert.IdentifierToMessage(blockEvents.BlockID),
+			BlockHeight:    blockEvents.BlockHeight,
+			BlockTimestamp: timestamppb.New(blockEvents.BlockTimestamp),
+			Events:         convert.EventsToMessages(blockEvents.Events),
+		})
+	})
+}
+
+// SubscribeTransactionStatuses streams the result of a transaction each time its status changes.
+func (h *ExtendedHandler) SubscribeTransactionStatuses(
+	req *extended.SubscribeTransactionStatusesRequest,
+	stream extended.ExtendedAccessAPI_SubscribeTransactionStatusesServer,
+) error {
+	txID, err := convert.TransactionID(req.GetTransactionId())
+	if err != nil {
+		return err
+	}
+
+	sub := h.api.SubscribeTransactionStatuses(stream.Context(), txID)
+
+	return streamSubscription(sub, func(v interface{}) error {
+		result, ok := v.(*TransactionResult)
+		if !ok {
+			return status.Errorf(codes.Internal, "unexpected response type: %T", v)
+		}
+
+		return stream.Send(TransactionResultToMessage(result))
+	})
+}
+
+// streamSubscription calls send with each value received from the subscription, until the
+// subscription is closed or sending fails. The stream is only read after the previous value was
+// sent, so slow clients apply backpressure to the subscription.
+func streamSubscription(sub subscription.Subscription, send func(interface{}) error) error {
+	for v := range sub.Channel() {
+		err := send(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := sub.Err()
+	if err == nil {
+		return nil
+	}
+
+	// subscription errors wrap the status errors returned by the backend, which are not detected
+	// by the status package
+	var statusErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &statusErr) {
+		return status.Error(statusErr.GRPCStatus().Code(), err.Error())
+	}
+	return status.Error(codes.Internal, fmt.Sprintf("stream encountered an error: %v", err))
+}
+
+// AccountTransactionsToMessage converts a page of account transactions to its protobuf message.
+func AccountTransactionsToMessage(page *AccountTransactions) *extended.GetTransactionsByAccountResponse {
+	transactions := make([]*extended.AccountTransaction, len(page.Transactions))
+	for i, tx := range page.Transactions {
+		roles := make([]extended.AccountTransactionRole, 0, len(tx.Roles.Roles()))
+		for _, role := range tx.Roles.Roles() {
+			roles = append(roles, accountTransactionRoleToMessage(role))
+		}
+
+		transactions[i] = &extended.AccountTransaction{
+			Address:       tx.Address.Bytes(),
+			BlockHeight:   tx.BlockHeight,
+			TransactionId: convert.IdentifierToMessage(tx.TransactionID),
+			Roles:         roles,
+		}
+	}
+
+	response := &extended.GetTransactionsByAccountResponse{
+		Transactions: transactions,
+	}
+	if page.NextCursor != nil {
+		response.NextCursor = &extended.AccountTransactionCursor{
+			BlockHeight:   page.NextCursor.BlockHeight,
+			TransactionId: convert.IdentifierToMessage(page.NextCursor.TransactionID),
+		}
+	}
+
+	return response
+}
+
+func accountTransactionRoleToMessage(role flow.AccountTransactionRole) extended.AccountTransactionRole {
+	switch role {
+	case flow.AccountTransactionRolePayer:
+		return extended.AccountTransactionRole_PAYER
+	case flow.AccountTransactionRoleProposer:
+		return extended.AccountTransactionRole_PROPOSER
+	case flow.AccountTransactionRoleAuthorizer:
+		return extended.AccountTransactionRole_AUTHORIZER
+	case flow.AccountTransactionRoleEventEmitter:
+		return extended.AccountTransactionRole_EVENT_EMITTER
+	default:
+		return extended.AccountTransactionRole_UNKNOWN_ROLE
+	}
+}
@@ -0,0 +1,317 @@
+package access_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/access/extended"
+	accessmock "github.com/onflow/flow-go/access/mock"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	statestreammock "github.com/onflow/flow-go/engine/access/state_stream/mock"
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// runExtendedAPI serves the extended access API of the given access and state stream APIs over a
+// unix socket, and calls f with a client connected to it.
+func runExtendedAPI(
+	t *testing.T,
+	api access.API,
+	stateStreamAPI state_stream.API,
+	f func(client extended.ExtendedAccessAPIClient),
+) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		address := filepath.Join(dir, "access.sock")
+		listener, err := net.Listen("unix", address)
+		require.NoError(t, err)
+
+		server := grpc.NewServer()
+		extended.RegisterExtendedAccessAPIServer(server, access.NewExtendedHandler(api, flow.Testnet.Chain(), stateStreamAPI, state_stream.DefaultConfig))
+		go func() {
+			_ = server.Serve(listener)
+		}()
+		defer server.Stop()
+
+		conn, err := grpc.Dial("unix://"+address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		f(extended.NewExtendedAccessAPIClient(conn))
+	})
+}
+
+// TestExtendedHandlerGetTransactionsByAccount tests that pages of account transactions are
+// requested and returned with their cursors
+func TestExtendedHandlerGetTransactionsByAccount(t *testing.T) {
+	address := unittest.AddressFixture()
+	txID1 := unittest.IdentifierFixture()
+	txID2 := unittest.IdentifierFixture()
+	cursor := flow.AccountTransactionCursor{BlockHeight: 9, TransactionID: unittest.IdentifierFixture()}
+
+	api := accessmock.NewAPI(t)
+	api.On("GetTransactionsByAccount", mock.Anything, address, uint64(5), uint64(20), &cursor, uint(2)).
+		Return(&access.AccountTransactions{
+			Transactions: []flow.AccountTransaction{
+				{
+					Address:       address,
+					BlockHeight:   10,
+					TransactionID: txID1,
+					Roles:         flow.AccountTransactionRolePayer | flow.AccountTransactionRoleAuthorizer,
+				},
+				{
+					Address:       address,
+					BlockHeight:   12,
+					TransactionID: txID2,
+					Roles:         flow.AccountTransactionRoleEventEmitter,
+				},
+			},
+			NextCursor: &flow.AccountTransactionCursor{BlockHeight: 12, TransactionID: txID2},
+		}, nil).
+		Once()
+
+	runExtendedAPI(t, api, nil, func(client extended.ExtendedAccessAPIClient) {
+		response, err := client.GetTransactionsByAccount(context.Background(), &extended.GetTransactionsByAccountRequest{
+			Address:     address.Bytes(),
+			StartHeight: 5,
+			EndHeight:   20,
+			Cursor: &extended.AccountTransactionCursor{
+				BlockHeight:   cursor.BlockHeight,
+				TransactionId: cursor.TransactionID[:],
+			},
+			Limit: 2,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, response.Transactions, 2)
+		assert.Equal(t, address.Bytes(), response.Transactions[0].Address)
+		assert.Equal(t, uint64(10), response.Transactions[0].BlockHeight)
+		assert.Equal(t, txID1[:], response.Transactions[0].TransactionId)
+		assert.Equal(t, []extended.AccountTransactionRole{
+			extended.AccountTransactionRole_PAYER,
+			extended.AccountTransactionRole_AUTHORIZER,
+		}, response.Transactions[0].Roles)
+		assert.Equal(t, txID2[:], response.Transactions[1].TransactionId)
+		assert.Equal(t, []extended.AccountTransactionRole{
+			extended.AccountTransactionRole_EVENT_EMITTER,
+		}, response.Transactions[1].Roles)
+
+		require.NotNil(t, response.NextCursor)
+		assert.Equal(t, uint64(12), response.NextCursor.BlockHeight)
+		assert.Equal(t, txID2[:], response.NextCursor.TransactionId)
+	})
+}
+
+// TestExtendedHandlerGetTransactionsByAccountInvalidAddress tests that addresses which are not
+// valid on the chain are rejected
+func TestExtendedHandlerGetTransactionsByAccountInvalidAddress(t *testing.T) {
+	api := accessmock.NewAPI(t)
+
+	runExtendedAPI(t, api, nil, func(client extended.ExtendedAccessAPIClient) {
+		_, err := client.GetTransactionsByAccount(context.Background(), &extended.GetTransactionsByAccountRequest{
+			Address:   flow.HexToAddress("ffffffffffffffff").Bytes(),
+			EndHeight: 20,
+		})
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+// TestExtendedHandlerSubscribeEvents tests that the events of each block are streamed with the
+// requested start block and filter
+func TestExtendedHandlerSubscribeEvents(t *testing.T) {
+	startBlockID := unittest.IdentifierFixture()
+	eventType := "A.0000000000000001.Foo.Bar"
+
+	blocks := make([]*flow.BlockEvents, 3)
+	for i := range blocks {
+		blocks[i] = &flow.BlockEvents{
+			BlockID:        unittest.IdentifierFixture(),
+			BlockHeight:    uint64(10 + i),
+			BlockTimestamp: time.Now().UTC(),
+			Events:         []flow.Event{unittest.EventFixture(flow.EventType(eventType), 0, 0, unittest.IdentifierFixture(), 0)},
+		}
+	}
+	// blocks without matching events are sent as well
+	blocks[1].Events = []flow.Event{}
+
+	sub := subscription.NewSubscription()
+	go func() {
+		for _, block := range blocks {
+			err := sub.Send(context.Background(), block, time.Second)
+			if err != nil {
+				sub.Fail(err)
+				return
+			}
+		}
+		sub.Close()
+	}()
+
+	stateStreamAPI := statestreammock.NewAPI(t)
+	stateStreamAPI.On("SubscribeEvents", mock.Anything, startBlockID, uint64(0), mock.Anything).
+		Return(sub).
+		Run(func(args mock.Arguments) {
+			filter := args.Get(3).(state_stream.EventFilter)
+			assert.Contains(t, filter.EventTypes, flow.EventType(eventType))
+		}).
+		Once()
+
+	runExtendedAPI(t, accessmock.NewAPI(t), stateStreamAPI, func(client extended.ExtendedAccessAPIClient) {
+		stream, err := client.SubscribeEvents(context.Background(), &extended.SubscribeEventsRequest{
+			StartBlockId: startBlockID[:],
+			Filter: &extended.EventFilter{
+				EventType: []string{eventType},
+			},
+		})
+		require.NoError(t, err)
+
+		for _, block := range blocks {
+			response, err := stream.Recv()
+			require.NoError(t, err)
+
+			assert.Equal(t, block.BlockID[:], response.BlockId)
+			assert.Equal(t, block.BlockHeight, response.BlockHeight)
+			assert.Equal(t, block.BlockTimestamp, response.BlockTimestamp.AsTime())
+			require.Len(t, response.Events, len(block.Events))
+			for i, event := range block.Events {
+				assert.Equal(t, string(event.Type), response.Events[i].Type)
+				assert.Equal(t, event.TransactionID[:], response.Events[i].TransactionId)
+			}
+		}
+
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+// TestExtendedHandlerSubscribeEventsErrors tests that requests are rejected if streaming is not
+// enabled or the subscription fails
+func TestExtendedHandlerSubscribeEventsErrors(t *testing.T) {
+	t.Run("streaming not enabled", func(t *testing.T) {
+		runExtendedAPI(t, accessmock.NewAPI(t), nil, func(client extended.ExtendedAccessAPIClient) {
+			stream, err := client.SubscribeEvents(context.Background(), &extended.SubscribeEventsRequest{})
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+		})
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		runExtendedAPI(t, accessmock.NewAPI(t), statestreammock.NewAPI(t), func(client extended.ExtendedAccessAPIClient) {
+			stream, err := client.SubscribeEvents(context.Background(), &extended.SubscribeEventsRequest{
+				Filter: &extended.EventFilter{
+					EventType: []string{"invalid"},
+				},
+			})
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	})
+
+	t.Run("subscription failed", func(t *testing.T) {
+		startBlockID := unittest.IdentifierFixture()
+		sub := subscription.NewFailedSubscription(status.Error(codes.NotFound, "block not found"), "could not get start height")
+
+		stateStreamAPI := statestreammock.NewAPI(t)
+		stateStreamAPI.On("SubscribeEvents", mock.Anything, startBlockID, uint64(0), mock.Anything).Return(sub).Once()
+
+		runExtendedAPI(t, accessmock.NewAPI(t), stateStreamAPI, func(client extended.ExtendedAccessAPIClient) {
+			stream, err := client.SubscribeEvents(context.Background(), &extended.SubscribeEventsRequest{
+				StartBlockId: startBlockID[:],
+			})
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			assert.Equal(t, codes.NotFound, status.Code(err))
+		})
+	})
+}
+
+// TestExtendedHandlerSubscribeTransactionStatuses tests that each status transition of a
+// transaction is streamed until the subscription is closed
+func TestExtendedHandlerSubscribeTransactionStatuses(t *testing.T) {
+	txID := unittest.IdentifierFixture()
+	blockID := unittest.IdentifierFixture()
+
+	statuses := []flow.TransactionStatus{
+		flow.TransactionStatusPending,
+		flow.TransactionStatusFinalized,
+		flow.TransactionStatusExecuted,
+		flow.TransactionStatusSealed,
+	}
+
+	sub := subscription.NewSubscription()
+	go func() {
+		for _, txStatus := range statuses {
+			result := &access.TransactionResult{
+				Status:        txStatus,
+				TransactionID: txID,
+			}
+			if txStatus != flow.TransactionStatusPending {
+				result.BlockID = blockID
+				result.BlockHeight = 10
+			}
+
+			err := sub.Send(context.Background(), result, time.Second)
+			if err != nil {
+				sub.Fail(err)
+				return
+			}
+		}
+		sub.Close()
+	}()
+
+	api := accessmock.NewAPI(t)
+	api.On("SubscribeTransactionStatuses", mock.Anything, txID).Return(sub).Once()
+
+	runExtendedAPI(t, api, nil, func(client extended.ExtendedAccessAPIClient) {
+		stream, err := client.SubscribeTransactionStatuses(context.Background(), &extended.SubscribeTransactionStatusesRequest{
+			TransactionId: txID[:],
+		})
+		require.NoError(t, err)
+
+		for _, txStatus := range statuses {
+			response, err := stream.Recv()
+			require.NoError(t, err)
+
+			assert.Equal(t, entities.TransactionStatus(txStatus), response.Status)
+			assert.Equal(t, txID[:], response.TransactionId)
+			if txStatus != flow.TransactionStatusPending {
+				assert.Equal(t, blockID[:], response.BlockId)
+				assert.Equal(t, uint64(10), response.BlockHeight)
+			}
+		}
+
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+// TestExtendedHandlerSubscribeTransactionStatusesInvalidID tests that requests without a transaction
+// ID are rejected
+func TestExtendedHandlerSubscribeTransactionStatusesInvalidID(t *testing.T) {
+	runExtendedAPI(t, accessmock.NewAPI(t), nil, func(client extended.ExtendedAccessAPIClient) {
+		stream, err := client.SubscribeTransactionStatuses(context.Background(), &extended.SubscribeTransactionStatusesRequest{})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
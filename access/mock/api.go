@@ -10,6 +10,8 @@ import (
 	flow "github.com/onflow/flow-go/model/flow"
 
 	mock "github.com/stretchr/testify/mock"
+
+	subscription "github.com/onflow/flow-go/engine/access/subscription"
 )
 
 // API is an autogenerated mock type for the API type
@@ -17,6 +19,29 @@ type API struct {
 	mock.Mock
 }
 
+// DryRunTransaction provides a mock function with given fields: ctx, tx, skipSignatureVerification
+func (_m *API) DryRunTransaction(ctx context.Context, tx *flow.TransactionBody, skipSignatureVerification bool) (*access.TransactionDryRunResult, error) {
+	ret := _m.Called(ctx, tx, skipSignatureVerification)
+
+	var r0 *access.TransactionDryRunResult
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody, bool) *access.TransactionDryRunResult); ok {
+		r0 = rf(ctx, tx, skipSignatureVerification)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.TransactionDryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody, bool) error); ok {
+		r1 = rf(ctx, tx, skipSignatureVerification)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecuteScriptAtBlockHeight provides a mock function with given fields: ctx, blockHeight, script, arguments
 func (_m *API) ExecuteScriptAtBlockHeight(ctx context.Context, blockHeight uint64, script []byte, arguments [][]byte) ([]byte, error) {
 	ret := _m.Called(ctx, blockHeight, script, arguments)
@@ -86,6 +111,52 @@ func (_m *API) ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, ar
 	return r0, r1
 }
 
+// ExecuteScriptsAtBlockHeight provides a mock function with given fields: ctx, blockHeight, scripts
+func (_m *API) ExecuteScriptsAtBlockHeight(ctx context.Context, blockHeight uint64, scripts []access.Script) ([]access.ScriptResult, error) {
+	ret := _m.Called(ctx, blockHeight, scripts)
+
+	var r0 []access.ScriptResult
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, []access.Script) []access.ScriptResult); ok {
+		r0 = rf(ctx, blockHeight, scripts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]access.ScriptResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, []access.Script) error); ok {
+		r1 = rf(ctx, blockHeight, scripts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExecuteScriptsAtBlockID provides a mock function with given fields: ctx, blockID, scripts
+func (_m *API) ExecuteScriptsAtBlockID(ctx context.Context, blockID flow.Identifier, scripts []access.Script) ([]access.ScriptResult, error) {
+	ret := _m.Called(ctx, blockID, scripts)
+
+	var r0 []access.ScriptResult
+	if rf, ok := ret.Get(0).(func(context.Context, flow.Identifier, []access.Script) []access.ScriptResult); ok {
+		r0 = rf(ctx, blockID, scripts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]access.ScriptResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, flow.Identifier, []access.Script) error); ok {
+		r1 = rf(ctx, blockID, scripts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAccount provides a mock function with given fields: ctx, address
 func (_m *API) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
 	ret := _m.Called(ctx, address)
@@ -270,6 +341,29 @@ func (_m *API) GetCollectionByID(ctx context.Context, id flow.Identifier) (*flow
 	return r0, r1
 }
 
+// GetEventsByType provides a mock function with given fields: ctx, eventType, startHeight, endHeight, cursor, limit
+func (_m *API) GetEventsByType(ctx context.Context, eventType string, startHeight uint64, endHeight uint64, cursor *access.EventsCursor, limit uint) (*access.EventsPage, error) {
+	ret := _m.Called(ctx, eventType, startHeight, endHeight, cursor, limit)
+
+	var r0 *access.EventsPage
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint64, uint64, *access.EventsCursor, uint) *access.EventsPage); ok {
+		r0 = rf(ctx, eventType, startHeight, endHeight, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.EventsPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint64, uint64, *access.EventsCursor, uint) error); ok {
+		r1 = rf(ctx, eventType, startHeight, endHeight, cursor, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetEventsForBlockIDs provides a mock function with given fields: ctx, eventType, blockIDs
 func (_m *API) GetEventsForBlockIDs(ctx context.Context, eventType string, blockIDs []flow.Identifier) ([]flow.BlockEvents, error) {
 	ret := _m.Called(ctx, eventType, blockIDs)
@@ -537,6 +631,29 @@ func (_m *API) GetTransactionResultsByBlockID(ctx context.Context, blockID flow.
 	return r0, r1
 }
 
+// GetTransactionsByAccount provides a mock function with given fields: ctx, address, startHeight, endHeight, cursor, limit
+func (_m *API) GetTransactionsByAccount(ctx context.Context, address flow.Address, startHeight uint64, endHeight uint64, cursor *flow.AccountTransactionCursor, limit uint) (*access.AccountTransactions, error) {
+	ret := _m.Called(ctx, address, startHeight, endHeight, cursor, limit)
+
+	var r0 *access.AccountTransactions
+	if rf, ok := ret.Get(0).(func(context.Context, flow.Address, uint64, uint64, *flow.AccountTransactionCursor, uint) *access.AccountTransactions); ok {
+		r0 = rf(ctx, address, startHeight, endHeight, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.AccountTransactions)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, flow.Address, uint64, uint64, *flow.AccountTransactionCursor, uint) error); ok {
+		r1 = rf(ctx, address, startHeight, endHeight, cursor, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTransactionsByBlockID provides a mock function with given fields: ctx, blockID
 func (_m *API) GetTransactionsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*flow.TransactionBody, error) {
 	ret := _m.Called(ctx, blockID)
@@ -588,6 +705,22 @@ func (_m *API) SendTransaction(ctx context.Context, tx *flow.TransactionBody) er
 	return r0
 }
 
+// SubscribeTransactionStatuses provides a mock function with given fields: ctx, id
+func (_m *API) SubscribeTransactionStatuses(ctx context.Context, id flow.Identifier) subscription.Subscription {
+	ret := _m.Called(ctx, id)
+
+	var r0 subscription.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, flow.Identifier) subscription.Subscription); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(subscription.Subscription)
+		}
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewAPI interface {
 	mock.TestingT
 	Cleanup(func())
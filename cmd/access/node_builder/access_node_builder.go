@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	badgerDB "github.com/dgraph-io/badger/v2"
 	badger "github.com/ipfs/go-ds-badger2"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/routing"
@@ -35,16 +36,20 @@ import (
 	pingeng "github.com/onflow/flow-go/engine/access/ping"
 	"github.com/onflow/flow-go/engine/access/rpc"
 	"github.com/onflow/flow-go/engine/access/rpc/backend"
+	"github.com/onflow/flow-go/engine/access/state_stream"
 	"github.com/onflow/flow-go/engine/common/follower"
 	followereng "github.com/onflow/flow-go/engine/common/follower"
 	"github.com/onflow/flow-go/engine/common/requester"
 	synceng "github.com/onflow/flow-go/engine/common/synchronization"
+	"github.com/onflow/flow-go/fvm"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/blobs"
 	"github.com/onflow/flow-go/module/buffer"
 	"github.com/onflow/flow-go/module/chainsync"
 	"github.com/onflow/flow-go/module/compliance"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	finalizer "github.com/onflow/flow-go/module/finalizer/consensus"
 	"github.com/onflow/flow-go/module/id"
@@ -52,6 +57,7 @@ import (
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/module/metrics/unstaked"
 	"github.com/onflow/flow-go/module/state_synchronization"
+	"github.com/onflow/flow-go/module/state_synchronization/indexer"
 	edrequester "github.com/onflow/flow-go/module/state_synchronization/requester"
 	"github.com/onflow/flow-go/network"
 	netcache "github.com/onflow/flow-go/network/cache"
@@ -68,6 +74,7 @@ import (
 	"github.com/onflow/flow-go/state/protocol/blocktimer"
 	"github.com/onflow/flow-go/storage"
 	bstorage "github.com/onflow/flow-go/storage/badger"
+	sutil "github.com/onflow/flow-go/storage/util"
 	"github.com/onflow/flow-go/utils/grpcutils"
 )
 
@@ -105,10 +112,20 @@ type AccessNodeConfig struct {
 	logTxTimeToFinalizedExecuted bool
 	retryEnabled                 bool
 	rpcMetricsEnabled            bool
+	accountTransactionsEnabled   bool
 	executionDataSyncEnabled     bool
 	executionDataDir             string
 	executionDataStartHeight     uint64
 	executionDataConfig          edrequester.ExecutionDataConfig
+	executionDataIndexingEnabled bool
+	eventTypeIndexEnabled        bool
+	registersDir                 string
+	registersCheckpointFile      string
+	registersPruningEnabled      bool
+	registersPruningTarget       uint64
+	registersPruningThreshold    uint64
+	stateStreamEnabled           bool
+	stateStreamConf              state_stream.Config
 	baseOptions                  []cmd.Option
 
 	PublicNetworkConfig PublicNetworkConfig
@@ -149,6 +166,7 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 		pingEnabled:                  false,
 		retryEnabled:                 false,
 		rpcMetricsEnabled:            false,
+		accountTransactionsEnabled:   false,
 		nodeInfoFile:                 "",
 		apiRatelimits:                nil,
 		apiBurstlimits:               nil,
@@ -167,6 +185,15 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 			RetryDelay:         edrequester.DefaultRetryDelay,
 			MaxRetryDelay:      edrequester.DefaultMaxRetryDelay,
 		},
+		executionDataIndexingEnabled: false,
+		eventTypeIndexEnabled:        false,
+		registersDir:                 filepath.Join(homedir, ".flow", "execution_state"),
+		registersCheckpointFile:      "",
+		registersPruningEnabled:      false,
+		registersPruningTarget:       indexer.DefaultPruningHeightRangeTarget,
+		registersPruningThreshold:    indexer.DefaultPruningThreshold,
+		stateStreamEnabled:           false,
+		stateStreamConf:              state_stream.DefaultConfig,
 	}
 }
 
@@ -198,6 +225,13 @@ type FlowAccessNodeBuilder struct {
 	FollowerCore               module.HotStuffFollower
 	ExecutionDataDownloader    execution_data.Downloader
 	ExecutionDataRequester     state_synchronization.ExecutionDataRequester
+	StateStreamBackend         *state_stream.StateStreamBackend
+	RegisterIndexer            *indexer.Indexer
+	RegisterPruner             *indexer.Pruner
+	AccountTransactions        storage.AccountTransactions
+	EventIndexer               *indexer.EventIndexer
+	EventTypeIndex             storage.Events
+	ScriptExecutor             backend.ScriptExecutor
 
 	// The sync engine participants provider is the libp2p peer store for the access node
 	// which is not available until after the network has started.
@@ -395,6 +429,8 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 	var bs network.BlobService
 	var processedBlockHeight storage.ConsumerProgress
 	var processedNotifications storage.ConsumerProgress
+	var execDataStore execution_data.ExecutionDataStore
+	var registersDB *badgerDB.DB
 
 	builder.
 		Module("execution data datastore and blobstore", func(node *cmd.NodeConfig) error {
@@ -409,6 +445,8 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 				return err
 			}
 
+			execDataStore = execution_data.NewExecutionDataStore(blobs.NewBlobstore(ds), execution_data.DefaultSerializer)
+
 			builder.ShutdownFunc(func() error {
 				if err := ds.Close(); err != nil {
 					return fmt.Errorf("could not close execution data datastore: %w", err)
@@ -428,6 +466,39 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 			processedNotifications = bstorage.NewConsumerProgress(ds.DB, module.ConsumeProgressExecutionDataRequesterNotification)
 			return nil
 		}).
+		Module("state stream backend", func(node *cmd.NodeConfig) error {
+			if !builder.stateStreamEnabled {
+				return nil
+			}
+
+			// the root block of a spork has no execution data, so the first available height is
+			// the block after the root block, unless syncing started at a later height
+			rootHeight := builder.RootBlock.Header.Height + 1
+			if builder.executionDataStartHeight > 0 {
+				rootHeight = builder.executionDataStartHeight
+			}
+
+			highestAvailableHeight, err := processedNotifications.ProcessedIndex()
+			if err != nil {
+				if !errors.Is(err, storage.ErrNotFound) {
+					return fmt.Errorf("could not get highest processed execution data height: %w", err)
+				}
+				// no execution data has been processed yet
+				highestAvailableHeight = rootHeight - 1
+			}
+
+			builder.StateStreamBackend = state_stream.New(
+				node.Logger,
+				builder.stateStreamConf,
+				node.Storage.Headers,
+				node.Storage.Seals,
+				node.Storage.Results,
+				execDataStore,
+				rootHeight,
+				highestAvailableHeight,
+			)
+			return nil
+		}).
 		Component("execution data service", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 			var err error
 			bs, err = node.Network.RegisterBlobService(channels.ExecutionDataService, ds)
@@ -485,12 +556,165 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 
 			builder.FinalizationDistributor.AddOnBlockFinalizedConsumer(builder.ExecutionDataRequester.OnBlockFinalized)
 
+			if builder.StateStreamBackend != nil {
+				builder.ExecutionDataRequester.AddOnExecutionDataFetchedConsumer(builder.StateStreamBackend.OnExecutionData)
+			}
+
+			if builder.RegisterIndexer != nil {
+				builder.ExecutionDataRequester.AddOnExecutionDataFetchedConsumer(builder.RegisterIndexer.OnExecutionData)
+			}
+
+			if builder.AccountTransactions != nil {
+				builder.ExecutionDataRequester.AddOnExecutionDataFetchedConsumer(builder.IngestEng.OnExecutionData)
+			}
+
+			if builder.EventIndexer != nil {
+				builder.ExecutionDataRequester.AddOnExecutionDataFetchedConsumer(builder.EventIndexer.OnExecutionData)
+			}
+
 			return builder.ExecutionDataRequester, nil
 		})
 
+	if builder.eventTypeIndexEnabled {
+		builder.Module("event type index", func(node *cmd.NodeConfig) error {
+			builder.EventIndexer = indexer.NewEventIndexer(node.Logger, node.DB, node.Storage.Headers, node.Storage.Events)
+			builder.EventTypeIndex = node.Storage.Events
+			return nil
+		})
+	}
+
+	if builder.executionDataIndexingEnabled {
+		builder.
+			Module("register index database", func(node *cmd.NodeConfig) error {
+				err := os.MkdirAll(builder.registersDir, 0700)
+				if err != nil {
+					return err
+				}
+
+				opts := badgerDB.
+					DefaultOptions(builder.registersDir).
+					WithKeepL0InMemory(true).
+					WithLogger(sutil.NewLogger(node.Logger))
+
+				registersDB, err = badgerDB.Open(opts)
+				if err != nil {
+					return fmt.Errorf("could not open register index database: %w", err)
+				}
+
+				builder.ShutdownFunc(func() error {
+					if err := registersDB.Close(); err != nil {
+						return fmt.Errorf("could not close register index database: %w", err)
+					}
+					return nil
+				})
+
+				return nil
+			}).
+			Module("register indexer and script executor", func(node *cmd.NodeConfig) error {
+				// the register index starts with the state at the last height before the first height
+				// with execution data, and is then updated with the trie updates of each height
+				bootstrapHeight := builder.RootBlock.Header.Height
+				if builder.executionDataStartHeight > 0 {
+					bootstrapHeight = builder.executionDataStartHeight - 1
+				}
+
+				bootstrapped, err := bstorage.IsRegistersBootstrapped(registersDB)
+				if err != nil {
+					return err
+				}
+
+				if !bootstrapped {
+					if builder.registersCheckpointFile == "" {
+						return errors.New("execution-state-checkpoint must be set when the register index is empty")
+					}
+
+					commit, err := builder.stateCommitmentAtHeight(bootstrapHeight)
+					if err != nil {
+						return err
+					}
+
+					err = indexer.BootstrapRegisters(node.Logger, registersDB, builder.registersCheckpointFile, bootstrapHeight, commit)
+					if err != nil {
+						return fmt.Errorf("could not bootstrap register index: %w", err)
+					}
+				}
+
+				registers, err := bstorage.NewRegisters(registersDB)
+				if err != nil {
+					return fmt.Errorf("could not open register index: %w", err)
+				}
+
+				highestAvailableHeight, err := processedNotifications.ProcessedIndex()
+				if err != nil {
+					if !errors.Is(err, storage.ErrNotFound) {
+						return fmt.Errorf("could not get highest processed execution data height: %w", err)
+					}
+					// no execution data has been processed yet
+					highestAvailableHeight = bootstrapHeight
+				}
+
+				builder.RegisterIndexer = indexer.New(
+					node.Logger,
+					registers,
+					node.Storage.Headers,
+					node.Storage.Seals,
+					node.Storage.Results,
+					execDataStore,
+					highestAvailableHeight,
+				)
+
+				builder.ScriptExecutor = execution.NewScripts(
+					node.Logger,
+					fvm.NewVirtualMachine(fvm.NewInterpreterRuntime()),
+					fvm.NewContext(node.Logger, node.FvmOptions...),
+					node.Storage.Headers,
+					registers,
+				)
+
+				if builder.registersPruningEnabled {
+					builder.RegisterPruner = indexer.NewPruner(
+						node.Logger,
+						registers,
+						indexer.WithPruningHeightRangeTarget(builder.registersPruningTarget),
+						indexer.WithPruningThreshold(builder.registersPruningThreshold),
+					)
+				}
+
+				return nil
+			}).
+			Component("register indexer", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+				return builder.RegisterIndexer, nil
+			})
+
+		if builder.registersPruningEnabled {
+			builder.Component("register pruner", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+				return builder.RegisterPruner, nil
+			})
+		}
+	}
+
 	return builder
 }
 
+// stateCommitmentAtHeight returns the state commitment of the sealed block at the given height.
+func (builder *FlowAccessNodeBuilder) stateCommitmentAtHeight(height uint64) (flow.StateCommitment, error) {
+	if height == builder.RootBlock.Header.Height {
+		return builder.RootSeal.FinalState, nil
+	}
+
+	header, err := builder.Storage.Headers.ByHeight(height)
+	if err != nil {
+		return flow.DummyStateCommitment, fmt.Errorf("could not get header at height %d: %w", height, err)
+	}
+
+	seal, err := builder.Storage.Seals.FinalizedSealForBlock(header.ID())
+	if err != nil {
+		return flow.DummyStateCommitment, fmt.Errorf("could not get seal for block at height %d: %w", height, err)
+	}
+
+	return seal.FinalState, nil
+}
+
 type Option func(*AccessNodeConfig)
 
 func FlowAccessNode(opts ...Option) *FlowAccessNodeBuilder {
@@ -540,6 +764,7 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.BoolVar(&builder.pingEnabled, "ping-enabled", defaultConfig.pingEnabled, "whether to enable the ping process that pings all other peers and report the connectivity to metrics")
 		flags.BoolVar(&builder.retryEnabled, "retry-enabled", defaultConfig.retryEnabled, "whether to enable the retry mechanism at the access node level")
 		flags.BoolVar(&builder.rpcMetricsEnabled, "rpc-metrics-enabled", defaultConfig.rpcMetricsEnabled, "whether to enable the rpc metrics")
+		flags.BoolVar(&builder.accountTransactionsEnabled, "account-transactions-index-enabled", defaultConfig.accountTransactionsEnabled, "whether to index the transactions of each account. accounts emitting events are only indexed if execution-data-sync-enabled is true")
 		flags.StringVarP(&builder.nodeInfoFile, "node-info-file", "", defaultConfig.nodeInfoFile, "full path to a json file which provides more details about nodes when reporting its reachability metrics")
 		flags.StringToIntVar(&builder.apiRatelimits, "api-rate-limits", defaultConfig.apiRatelimits, "per second rate limits for Access API methods e.g. Ping=300,GetTransaction=500 etc.")
 		flags.StringToIntVar(&builder.apiBurstlimits, "api-burst-limits", defaultConfig.apiBurstlimits, "burst limits for Access API methods e.g. Ping=100,GetTransaction=100 etc.")
@@ -555,6 +780,22 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.DurationVar(&builder.executionDataConfig.MaxFetchTimeout, "execution-data-max-fetch-timeout", defaultConfig.executionDataConfig.MaxFetchTimeout, "maximum timeout to use when fetching execution data from the network e.g. 300s")
 		flags.DurationVar(&builder.executionDataConfig.RetryDelay, "execution-data-retry-delay", defaultConfig.executionDataConfig.RetryDelay, "initial delay for exponential backoff when fetching execution data fails e.g. 10s")
 		flags.DurationVar(&builder.executionDataConfig.MaxRetryDelay, "execution-data-max-retry-delay", defaultConfig.executionDataConfig.MaxRetryDelay, "maximum delay for exponential backoff when fetching execution data fails e.g. 5m")
+
+		// Execution state indexing config
+		flags.BoolVar(&builder.executionDataIndexingEnabled, "execution-data-indexing-enabled", defaultConfig.executionDataIndexingEnabled, "whether to index the execution state from the execution data and execute scripts locally. requires execution-data-sync-enabled")
+		flags.BoolVar(&builder.eventTypeIndexEnabled, "event-type-index-enabled", defaultConfig.eventTypeIndexEnabled, "whether to store the events from the execution data and index them by type, to serve paginated event queries locally. requires execution-data-sync-enabled")
+		flags.StringVar(&builder.registersDir, "execution-state-dir", defaultConfig.registersDir, "directory to use for the register index database")
+		flags.StringVar(&builder.registersCheckpointFile, "execution-state-checkpoint", defaultConfig.registersCheckpointFile, "execution state checkpoint file used to bootstrap an empty register index. must only contain the state of the block before execution-data-start-height, or of the root block if not set. the index can also be bootstrapped offline with the util bootstrap-registers command")
+		flags.BoolVar(&builder.registersPruningEnabled, "execution-state-pruning-enabled", defaultConfig.registersPruningEnabled, "whether to prune the register index, keeping the register values of the most recent execution-state-pruning-height-range-target heights. scripts cannot be executed locally at pruned heights")
+		flags.Uint64Var(&builder.registersPruningTarget, "execution-state-pruning-height-range-target", defaultConfig.registersPruningTarget, "number of most recent heights to keep in the register index when pruning")
+		flags.Uint64Var(&builder.registersPruningThreshold, "execution-state-pruning-threshold", defaultConfig.registersPruningThreshold, "number of heights the register index can exceed the height range target by before it is pruned")
+
+		// State Stream API config
+		flags.BoolVar(&builder.stateStreamEnabled, "state-stream-enabled", defaultConfig.stateStreamEnabled, "whether to enable the state stream API. requires execution-data-sync-enabled")
+		flags.DurationVar(&builder.stateStreamConf.ClientSendTimeout, "state-stream-send-timeout", defaultConfig.stateStreamConf.ClientSendTimeout, "maximum wait before timing out while sending a response to a streaming client e.g. 30s")
+		flags.IntVar(&builder.stateStreamConf.MaxEventTypes, "state-stream-max-event-types", defaultConfig.stateStreamConf.MaxEventTypes, "maximum number of event types that can be used in a single event filter")
+		flags.IntVar(&builder.stateStreamConf.MaxAddresses, "state-stream-max-addresses", defaultConfig.stateStreamConf.MaxAddresses, "maximum number of addresses that can be used in a single event filter")
+		flags.IntVar(&builder.stateStreamConf.MaxContracts, "state-stream-max-contracts", defaultConfig.stateStreamConf.MaxContracts, "maximum number of contracts that can be used in a single event filter")
 	}).ValidateFlags(func() error {
 		if builder.supportsObserver && (builder.PublicNetworkConfig.BindAddress == cmd.NotSet || builder.PublicNetworkConfig.BindAddress == "") {
 			return errors.New("public-network-address must be set if supports-observer is true")
@@ -576,6 +817,23 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 				return errors.New("execution-data-max-search-ahead must be greater than 0")
 			}
 		}
+		if builder.executionDataIndexingEnabled && !builder.executionDataSyncEnabled {
+			return errors.New("execution-data-sync-enabled must be true if execution-data-indexing-enabled is true")
+		}
+		if builder.registersPruningEnabled && !builder.executionDataIndexingEnabled {
+			return errors.New("execution-data-indexing-enabled must be true if execution-state-pruning-enabled is true")
+		}
+		if builder.eventTypeIndexEnabled && !builder.executionDataSyncEnabled {
+			return errors.New("execution-data-sync-enabled must be true if event-type-index-enabled is true")
+		}
+		if builder.stateStreamEnabled {
+			if !builder.executionDataSyncEnabled {
+				return errors.New("execution-data-sync-enabled must be true if state-stream-enabled is true")
+			}
+			if builder.stateStreamConf.ClientSendTimeout <= 0 {
+				return errors.New("state-stream-send-timeout must be greater than 0")
+			}
+		}
 
 		return nil
 	})
@@ -776,6 +1034,12 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 			builder.PingMetrics = metrics.NewPingCollector()
 			return nil
 		}).
+		Module("account transactions index", func(node *cmd.NodeConfig) error {
+			if builder.accountTransactionsEnabled {
+				builder.AccountTransactions = bstorage.NewAccountTransactions(node.DB)
+			}
+			return nil
+		}).
 		Module("server certificate", func(node *cmd.NodeConfig) error {
 			// generate the server certificate that will be served by the GRPC server
 			x509Certificate, err := grpcutils.X509Certificate(node.NetworkKey)
@@ -808,11 +1072,18 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 				builder.rpcMetricsEnabled,
 				builder.apiRatelimits,
 				builder.apiBurstlimits,
+				builder.ScriptExecutor,
+				builder.AccountTransactions,
+				builder.EventTypeIndex,
 			)
 			if err != nil {
 				return nil, err
 			}
 
+			if builder.StateStreamBackend != nil {
+				engineBuilder.WithStateStreamAPI(builder.StateStreamBackend, builder.stateStreamConf)
+			}
+
 			builder.RpcEng = engineBuilder.
 				WithLegacy().
 				WithBlockSignerDecoder(signature.NewBlockSignerDecoder(builder.Committee)).
@@ -853,6 +1124,7 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 				builder.CollectionsToMarkExecuted,
 				builder.BlocksToMarkExecuted,
 				builder.RpcEng,
+				builder.AccountTransactions,
 			)
 			if err != nil {
 				return nil, err
@@ -16,6 +16,7 @@ import (
 	"github.com/onflow/flow-go/cmd/bootstrap/utils"
 	hotstuff "github.com/onflow/flow-go/consensus/hotstuff/model"
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/ledger/complete"
 	model "github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/dkg"
 	"github.com/onflow/flow-go/model/encodable"
@@ -42,6 +43,7 @@ var (
 	flagProtocolVersion             uint
 	flagServiceAccountPublicKeyJSON string
 	flagGenesisTokenSupply          string
+	flagPathFinderVersion           uint8
 	flagEpochCounter                uint64
 	flagNumViewsInEpoch             uint64
 	flagNumViewsInStakingAuction    uint64
@@ -119,6 +121,8 @@ func addFinalizeCmdFlags() {
 		"encoded json of public key for the service account")
 	finalizeCmd.Flags().StringVar(&flagGenesisTokenSupply, "genesis-token-supply", "10000000.00000000",
 		"genesis flow token supply")
+	finalizeCmd.Flags().Uint8Var(&flagPathFinderVersion, "path-finder-version", complete.DefaultPathFinderVersion,
+		"version of the key to path conversion of the genesis execution state, must match the --ledger-path-finder-version of the nodes")
 }
 
 func finalize(cmd *cobra.Command, args []string) {
@@ -620,6 +624,7 @@ func generateEmptyExecutionState(
 		filepath.Join(flagOutdir, model.DirnameExecutionState),
 		serviceAccountPublicKey,
 		chainID.Chain(),
+		flagPathFinderVersion,
 		fvm.WithInitialTokenSupply(cdcInitialTokenSupply),
 		fvm.WithMinimumStorageReservation(fvm.DefaultMinimumStorageReservation),
 		fvm.WithAccountCreationFee(fvm.DefaultAccountCreationFee),
@@ -37,6 +37,7 @@ func GenerateExecutionState(
 	dbDir string,
 	accountKey flow.AccountPublicKey,
 	chain flow.Chain,
+	pathFinderVersion uint8,
 	bootstrapOptions ...fvm.BootstrapProcedureOption,
 ) (flow.StateCommitment, error) {
 	const (
@@ -52,7 +53,7 @@ func GenerateExecutionState(
 		return flow.DummyStateCommitment, err
 	}
 
-	ledgerStorage, err := ledger.NewLedger(diskWal, capacity, metricsCollector, zerolog.Nop(), ledger.DefaultPathFinderVersion)
+	ledgerStorage, err := ledger.NewLedger(diskWal, capacity, metricsCollector, zerolog.Nop(), pathFinderVersion)
 	if err != nil {
 		return flow.DummyStateCommitment, err
 	}
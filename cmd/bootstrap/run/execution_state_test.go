@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/ledger/complete"
 	"github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -29,6 +30,7 @@ func TestGenerateExecutionState(t *testing.T) {
 		trieDir,
 		pk,
 		flow.Testnet.Chain(),
+		complete.DefaultPathFinderVersion,
 		fvm.WithInitialTokenSupply(unittest.GenesisTokenSupply))
 	require.NoError(t, err)
 	fmt.Printf("sk: %v\n", sk)
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/onflow/flow-go/cmd/bootstrap/gcs"
+	"github.com/onflow/flow-go/ledger/complete/wal"
 	model "github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -94,16 +95,27 @@ func pull(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// move root checkpoint file if node role is execution
+	// move root checkpoint files if node role is execution
 	if role == flow.RoleExecution {
-		// root.checkpoint is downloaded to <bootstrap folder>/public-root-information after a pull
+		// root.checkpoint and its part files are downloaded to <bootstrap folder>/public-root-information after a pull
 		rootCheckpointSrc := filepath.Join(flagBootDir, model.DirnamePublicBootstrap, model.FilenameWALRootCheckpoint)
 		rootCheckpointDst := filepath.Join(flagBootDir, model.PathRootCheckpoint)
 
-		log.Info().Str("src", rootCheckpointSrc).Str("destination", rootCheckpointDst).Msgf("moving file")
-		err := moveFile(rootCheckpointSrc, rootCheckpointDst)
+		_, files, err := wal.CheckpointFiles(rootCheckpointSrc)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to move root.checkpoint")
+			log.Fatal().Err(err).Msg("Failed to read root.checkpoint")
+		}
+
+		// move root.checkpoint last, so that the checkpoint only exists once all its parts are moved
+		for i := len(files) - 1; i >= 0; i-- {
+			src := files[i]
+			dst := filepath.Join(filepath.Dir(rootCheckpointDst), filepath.Base(src))
+
+			log.Info().Str("src", src).Str("destination", dst).Msgf("moving file")
+			err := moveFile(src, dst)
+			if err != nil {
+				log.Fatal().Err(err).Msgf("Failed to move %s", filepath.Base(src))
+			}
 		}
 	}
 
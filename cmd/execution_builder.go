@@ -41,6 +41,7 @@ import (
 	"github.com/onflow/flow-go/engine/execution/checker"
 	"github.com/onflow/flow-go/engine/execution/computation"
 	"github.com/onflow/flow-go/engine/execution/computation/committer"
+	"github.com/onflow/flow-go/engine/execution/computation/computer"
 	"github.com/onflow/flow-go/engine/execution/computation/computer/uploader"
 	"github.com/onflow/flow-go/engine/execution/ingestion"
 	exeprovider "github.com/onflow/flow-go/engine/execution/provider"
@@ -51,8 +52,12 @@ import (
 	"github.com/onflow/flow-go/fvm"
 	"github.com/onflow/flow-go/fvm/programs"
 	"github.com/onflow/flow-go/fvm/systemcontracts"
+	led "github.com/onflow/flow-go/ledger"
+	ledgerhash "github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	ledger "github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/payloadstore"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	bootstrapFilenames "github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
@@ -82,11 +87,22 @@ type ExecutionConfig struct {
 	triedir                              string
 	executionDataDir                     string
 	mTrieCacheSize                       uint32
+	payloadStoreDir                      string
+	payloadStoreCacheSize                int
+	ledgerHasher                         string
+	ledgerPathFinderVersion              uint8
 	transactionResultsCacheSize          uint
 	checkpointDistance                   uint
 	checkpointsToKeep                    uint
+	checkpointDeltas                     uint
+	walCompression                       bool
+	walArchiveDir                        string
+	walArchiveGCPBucketName              string
+	walArchiveS3BucketName               string
 	stateDeltasLimit                     uint
 	cadenceExecutionCache                uint
+	cadenceExecutionCacheMaxPrograms     uint
+	parallelTransactionExecutionWorkers  int
 	cadenceTracing                       bool
 	chdpCacheSize                        uint
 	requestInterval                      time.Duration
@@ -135,13 +151,34 @@ func (e *ExecutionNodeBuilder) LoadFlags() {
 			flags.StringVar(&e.exeConf.triedir, "triedir", datadir, "directory to store the execution State")
 			flags.StringVar(&e.exeConf.executionDataDir, "execution-data-dir", filepath.Join(homedir, ".flow", "execution_data"), "directory to use for storing Execution Data")
 			flags.Uint32Var(&e.exeConf.mTrieCacheSize, "mtrie-cache-size", 500, "cache size for MTrie")
+			flags.StringVar(&e.exeConf.payloadStoreDir, "mtrie-payload-store-dir", "", "directory to keep MTrie payloads on disk instead of memory, "+
+				"which checkpoints reference unless they are archived, so it must be kept with the checkpoints (disabled if empty)")
+			flags.IntVar(&e.exeConf.payloadStoreCacheSize, "mtrie-payload-store-cache-size", payloadstore.DefaultCacheSize,
+				"number of recently used MTrie payloads kept in memory when using the payload store")
+			flags.StringVar(&e.exeConf.ledgerHasher, "ledger-hasher", ledgerhash.SHA3_256.String(), "hasher of the execution state MTrie (sha3-256 or blake3), "+
+				"must match the hasher of the root checkpoint")
+			flags.Uint8Var(&e.exeConf.ledgerPathFinderVersion, "ledger-path-finder-version", ledger.DefaultPathFinderVersion, "version of the key to path conversion of the execution state, "+
+				"must match the version of the root checkpoint, version 2 requires a spork migrating the state with the --path-finder-version flag of execution-state-extract")
 			flags.UintVar(&e.exeConf.checkpointDistance, "checkpoint-distance", 20, "number of WAL segments between checkpoints")
 			flags.UintVar(&e.exeConf.checkpointsToKeep, "checkpoints-to-keep", 5, "number of recent checkpoints to keep (0 to keep all)")
+			flags.UintVar(&e.exeConf.checkpointDeltas, "checkpoint-deltas", 0, "number of incremental checkpoints between full checkpoints "+
+				"(0 to only create full checkpoints)")
+			flags.BoolVar(&e.exeConf.walCompression, "wal-compression", false, "compress the trie updates recorded in the WAL")
+			flags.StringVar(&e.exeConf.walArchiveDir, "wal-archive-dir", "", "directory to archive checkpointed WAL segments and checkpoints to, "+
+				"instead of only keeping the recent ones (disabled if empty)")
+			flags.StringVar(&e.exeConf.walArchiveGCPBucketName, "wal-archive-gcp-bucket-name", "", "GCP Bucket name to archive checkpointed WAL "+
+				"segments and checkpoints to (disabled if empty)")
+			flags.StringVar(&e.exeConf.walArchiveS3BucketName, "wal-archive-s3-bucket-name", "", "S3 Bucket name to archive checkpointed WAL "+
+				"segments and checkpoints to (disabled if empty)")
 			flags.UintVar(&e.exeConf.stateDeltasLimit, "state-deltas-limit", 100, "maximum number of state deltas in the memory pool")
 			flags.UintVar(&e.exeConf.cadenceExecutionCache, "cadence-execution-cache", computation.DefaultProgramsCacheSize,
 				"cache size for Cadence execution")
+			flags.UintVar(&e.exeConf.cadenceExecutionCacheMaxPrograms, "cadence-execution-cache-max-programs", computation.DefaultProgramsCacheMaxPrograms,
+				"maximum number of programs cached for Cadence execution, the least recently used ones are evicted")
+			flags.IntVar(&e.exeConf.parallelTransactionExecutionWorkers, "parallel-transaction-execution-workers", 0,
+				"number of workers executing the transactions of a collection optimistically in parallel (sequential execution if less than 2)")
 			flags.BoolVar(&e.exeConf.extensiveTracing, "extensive-tracing", false, "adds high-overhead tracing to execution")
-			flags.BoolVar(&e.exeConf.cadenceTracing, "cadence-tracing", false, "enables cadence runtime level tracing")
+			flags.BoolVar(&e.exeConf.cadenceTracing, "cadence-tracing", false, "enables cadence runtime level tracing, required to attribute the computation of user contracts")
 			flags.UintVar(&e.exeConf.chdpCacheSize, "chdp-cache", storage.DefaultCacheSize, "cache size for Chunk Data Packs")
 			flags.DurationVar(&e.exeConf.requestInterval, "request-interval", 60*time.Second, "the interval between requests for the requester engine")
 			flags.DurationVar(&e.exeConf.scriptLogThreshold, "script-log-threshold", computation.DefaultScriptLogThreshold,
@@ -178,6 +215,15 @@ func (e *ExecutionNodeBuilder) LoadFlags() {
 					return fmt.Errorf("invalid flag. gcp-bucket-name or s3-bucket-name required when blockdata-uploader is enabled")
 				}
 			}
+			archives := 0
+			for _, archive := range []string{e.exeConf.walArchiveDir, e.exeConf.walArchiveGCPBucketName, e.exeConf.walArchiveS3BucketName} {
+				if archive != "" {
+					archives++
+				}
+			}
+			if archives > 1 {
+				return fmt.Errorf("invalid flag. only one of wal-archive-dir, wal-archive-gcp-bucket-name and wal-archive-s3-bucket-name can be set")
+			}
 			return nil
 		})
 }
@@ -408,18 +454,71 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 
 			// DiskWal is a dependent component because we need to ensure
 			// that all WAL updates are completed before closing opened WAL segment.
+			var walOpts []wal.DiskWALOption
+			if e.exeConf.walCompression {
+				walOpts = append(walOpts, wal.WithCompression())
+			}
 			diskWAL, err = wal.NewDiskWAL(node.Logger.With().Str("subcomponent", "wal").Logger(),
-				node.MetricsRegisterer, collector, e.exeConf.triedir, int(e.exeConf.mTrieCacheSize), pathfinder.PathByteSize, wal.SegmentSize)
+				node.MetricsRegisterer, collector, e.exeConf.triedir, int(e.exeConf.mTrieCacheSize), pathfinder.PathByteSize, wal.SegmentSize, walOpts...)
 			if err != nil {
 				return nil, fmt.Errorf("failed to initialize wal: %w", err)
 			}
 
+			hasherType, err := ledgerhash.ParseHasherType(e.exeConf.ledgerHasher)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ledger hasher: %w", err)
+			}
+			hasher, err := led.NewTrieHasher(hasherType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create ledger hasher: %w", err)
+			}
+
+			forestOpts := []mtrie.ForestOption{mtrie.WithHasher(hasher)}
+			if e.exeConf.payloadStoreDir != "" {
+				store, err := payloadstore.Open(e.exeConf.payloadStoreDir, e.exeConf.payloadStoreCacheSize)
+				if err != nil {
+					return nil, fmt.Errorf("failed to open payload store: %w", err)
+				}
+				e.FlowNodeBuilder.ShutdownFunc(store.Close)
+				forestOpts = append(forestOpts, mtrie.WithPayloadStore(store))
+			}
+
 			ledgerStorage, err = ledger.NewLedger(diskWAL, int(e.exeConf.mTrieCacheSize), collector, node.Logger.With().Str("subcomponent",
-				"ledger").Logger(), ledger.DefaultPathFinderVersion)
+				"ledger").Logger(), e.exeConf.ledgerPathFinderVersion, forestOpts...)
 			return ledgerStorage, err
 		}).
 		Component("execution state ledger WAL compactor", func(node *NodeConfig) (module.ReadyDoneAware, error) {
 
+			compactorOpts := []ledger.CompactorOption{ledger.WithIncrementalCheckpoints(e.exeConf.checkpointDeltas)}
+
+			var archiveStore wal.ArchiveStore
+			var err error
+			ctx := context.Background()
+			logger := node.Logger.With().Str("component_name", "wal_archive_uploader").Logger()
+			switch {
+			case e.exeConf.walArchiveDir != "":
+				archiveStore, err = wal.NewDirArchiveStore(e.exeConf.walArchiveDir)
+			case e.exeConf.walArchiveGCPBucketName != "":
+				archiveStore, err = uploader.NewGCPBucketUploader(ctx, e.exeConf.walArchiveGCPBucketName, logger)
+			case e.exeConf.walArchiveS3BucketName != "":
+				config, configErr := awsconfig.LoadDefaultConfig(ctx)
+				if configErr != nil {
+					return nil, fmt.Errorf("failed to load AWS configuration: %w", configErr)
+				}
+				archiveStore = uploader.NewS3Uploader(ctx, s3.NewFromConfig(config), e.exeConf.walArchiveS3BucketName, logger)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("cannot create WAL archive store: %w", err)
+			}
+
+			if archiveStore != nil {
+				archiver, err := wal.NewArchiver(e.exeConf.triedir, archiveStore, node.Logger)
+				if err != nil {
+					return nil, fmt.Errorf("cannot create WAL archiver: %w", err)
+				}
+				compactorOpts = append(compactorOpts, ledger.WithArchiver(archiver))
+			}
+
 			return ledger.NewCompactor(
 				ledgerStorage,
 				diskWAL,
@@ -428,6 +527,7 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 				e.exeConf.checkpointDistance,
 				e.exeConf.checkpointsToKeep,
 				toTriggerCheckpoint, // compactor will listen to the signal from admin tool for force triggering checkpointing
+				compactorOpts...,
 			)
 		}).
 		Component("execution data pruner", func(node *NodeConfig) (module.ReadyDoneAware, error) {
@@ -511,6 +611,13 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			}
 			vmCtx := fvm.NewContext(node.Logger, fvmOptions...)
 
+			blockComputerOpts := []computer.BlockComputerOption{
+				computer.WithRegisterAccessesInExecutionData(computer.RegisterAccessesActivationHeight(node.RootChainID)),
+			}
+			if e.exeConf.parallelTransactionExecutionWorkers > 1 {
+				blockComputerOpts = append(blockComputerOpts, computer.WithParallelTransactionExecution(e.exeConf.parallelTransactionExecutionWorkers))
+			}
+
 			ledgerViewCommitter := committer.NewLedgerViewCommitter(ledgerStorage, node.Tracer)
 			manager, err := computation.New(
 				node.Logger,
@@ -521,11 +628,13 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 				vm,
 				vmCtx,
 				e.exeConf.cadenceExecutionCache,
+				e.exeConf.cadenceExecutionCacheMaxPrograms,
 				ledgerViewCommitter,
 				e.exeConf.scriptLogThreshold,
 				e.exeConf.scriptExecutionTimeLimit,
 				blockDataUploaders,
 				executionDataProvider,
+				blockComputerOpts...,
 			)
 			if err != nil {
 				return nil, err
@@ -890,19 +999,40 @@ func copyBootstrapState(dir, trie string) error {
 
 	// copy from the bootstrap folder to the execution state folder
 	src := filepath.Join(dir, bootstrapFilenames.DirnameExecutionState, filename)
-	dst := filepath.Join(trie, filename)
+	files := []string{src}
+	if filename == bootstrapFilenames.FilenameWALRootCheckpoint {
+		// the root checkpoint might be stored in multiple files, which are all copied
+		var err error
+		_, files, err = wal.CheckpointFiles(src)
+		if err != nil {
+			return fmt.Errorf("cannot read root checkpoint: %w", err)
+		}
+	}
 
-	in, err := os.Open(src)
+	// It's possible that the trie dir does not yet exist. If not this will create the the required path
+	err := os.MkdirAll(trie, 0700)
 	if err != nil {
 		return err
 	}
-	defer in.Close()
 
-	// It's possible that the trie dir does not yet exist. If not this will create the the required path
-	err = os.MkdirAll(trie, 0700)
+	// copy the checkpoint file last, so that the checkpoint only exists once all its parts are copied
+	for i := len(files) - 1; i >= 0; i-- {
+		err = copyFile(files[i], filepath.Join(trie, filepath.Base(files[i])))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyFile copies the src file to the dst file.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
 	if err != nil {
 		return err
 	}
+	defer in.Close()
 
 	out, err := os.Create(dst)
 	if err != nil {
@@ -979,6 +979,9 @@ func (builder *ObserverServiceBuilder) enqueueRPCServer() {
 			builder.rpcMetricsEnabled,
 			builder.apiRatelimits,
 			builder.apiBurstlimits,
+			nil,
+			nil,
+			nil,
 		)
 		if err != nil {
 			return nil, err
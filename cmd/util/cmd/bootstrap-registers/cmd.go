@@ -0,0 +1,66 @@
+package bootstrap_registers
+
+import (
+	"encoding/hex"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/state_synchronization/indexer"
+)
+
+var (
+	flagCheckpoint      string
+	flagRegistersDir    string
+	flagHeight          uint64
+	flagStateCommitment string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "bootstrap-registers",
+	Short: "Bootstraps an empty register index with the execution state of a block from a checkpoint",
+	Run:   run,
+}
+
+func init() {
+
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"checkpoint file to read the execution state from")
+	_ = Cmd.MarkFlagRequired("checkpoint")
+
+	Cmd.Flags().StringVar(&flagRegistersDir, "registers-dir", "",
+		"directory of the register index database")
+	_ = Cmd.MarkFlagRequired("registers-dir")
+
+	Cmd.Flags().Uint64Var(&flagHeight, "height", 0,
+		"height of the block, which becomes the first indexed height")
+	_ = Cmd.MarkFlagRequired("height")
+
+	Cmd.Flags().StringVar(&flagStateCommitment, "state-commitment", "",
+		"state commitment of the block (hex-encoded), which must be the root hash of the only trie of the checkpoint")
+	_ = Cmd.MarkFlagRequired("state-commitment")
+}
+
+func run(*cobra.Command, []string) {
+
+	stateCommitmentBytes, err := hex.DecodeString(flagStateCommitment)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot decode the state commitment")
+	}
+	stateCommitment, err := flow.ToStateCommitment(stateCommitmentBytes)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid state commitment length")
+	}
+
+	db := common.InitStorage(flagRegistersDir)
+	defer db.Close()
+
+	err = indexer.BootstrapRegisters(log.Logger, db, flagCheckpoint, flagHeight, stateCommitment)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not bootstrap register index")
+	}
+
+	log.Info().Msg("register index bootstrapped")
+}
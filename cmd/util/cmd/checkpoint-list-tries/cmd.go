@@ -22,13 +22,17 @@ var Cmd = &cobra.Command{
 func init() {
 
 	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
-		"checkpoint file to read")
+		"checkpoint file to read, part files of multi-file checkpoints are read from the same directory")
 	_ = Cmd.MarkFlagRequired("checkpoint")
 }
 
 func run(*cobra.Command, []string) {
 
-	log.Info().Msgf("loading checkpoint %v", flagCheckpoint)
+	version, files, err := wal.CheckpointFiles(flagCheckpoint)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error while reading checkpoint header")
+	}
+	log.Info().Uint16("version", version).Strs("files", files).Msgf("loading checkpoint %v", flagCheckpoint)
 	tries, err := wal.LoadCheckpoint(flagCheckpoint, &log.Logger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("error while loading checkpoint")
@@ -0,0 +1,351 @@
+package checkpoint_verify
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
+	storagebadger "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+var (
+	flagCheckpoint        string
+	flagDatadir           string
+	flagBlocks            uint
+	flagRepair            bool
+	flagExecutionStateDir string
+	flagCheckpointNumber  int
+	flagOutputDir         string
+	flagLedgerHasher      string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "checkpoint-verify",
+	Short: "Verifies a checkpoint, or rebuilds it from an earlier valid checkpoint and the WAL segments",
+	Long: `Loads a checkpoint, which validates the checksums of its files, recomputes the hashes of all
+its nodes from the leaves up and reports the first node whose hash doesn't match. If --datadir is given,
+the root hashes of the tries are compared with the state commitments of the last executed blocks.
+
+With --repair, the checkpoint --checkpoint-number of --execution-state-dir is rebuilt from the latest
+earlier checkpoint which passes verification (or the root checkpoint) and the WAL segments since,
+and written to --output-dir. The execution state directory isn't modified.`,
+	Run: run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"checkpoint file to verify")
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory that stores the protocol state, to verify trie root hashes against state commitments")
+
+	Cmd.Flags().UintVar(&flagBlocks, "blocks", 1000,
+		"number of executed blocks, from the last executed block, to collect state commitments from")
+
+	Cmd.Flags().BoolVar(&flagRepair, "repair", false,
+		"rebuild the checkpoint from an earlier checkpoint and the WAL segments")
+
+	Cmd.Flags().StringVar(&flagExecutionStateDir, "execution-state-dir", "",
+		"execution node state dir containing the checkpoints and WAL segments, used by --repair")
+
+	Cmd.Flags().IntVar(&flagCheckpointNumber, "checkpoint-number", -1,
+		"number of the checkpoint to rebuild, used by --repair")
+
+	Cmd.Flags().StringVar(&flagOutputDir, "output-dir", "",
+		"directory to write the rebuilt checkpoint to, used by --repair")
+
+	Cmd.Flags().StringVar(&flagLedgerHasher, "ledger-hasher", hash.SHA3_256.String(),
+		"hasher of the ledger tries (sha3-256 or blake3), used by --repair")
+}
+
+func run(*cobra.Command, []string) {
+
+	var commitments map[ledger.RootHash]struct{}
+	if flagDatadir != "" {
+		db := common.InitStorage(flagDatadir)
+		defer db.Close()
+
+		var err error
+		commitments, err = stateCommitments(db, flagBlocks)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot read state commitments")
+		}
+		log.Info().Msgf("read %d state commitments", len(commitments))
+	}
+
+	if flagRepair {
+		runRepair(commitments)
+		return
+	}
+
+	if flagCheckpoint == "" {
+		log.Fatal().Msg("--checkpoint is required")
+	}
+
+	log.Info().Msgf("loading checkpoint %v", flagCheckpoint)
+	tries, err := wal.LoadCheckpoint(flagCheckpoint, &log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("checkpoint is invalid: cannot load checkpoint")
+	}
+	log.Info().Msgf("checkpoint loaded, total tries: %v", len(tries))
+
+	err = verifyNodes(tries)
+	if err != nil {
+		log.Fatal().Err(err).Msg("checkpoint is invalid")
+	}
+	log.Info().Msg("node hashes verified")
+
+	if commitments != nil {
+		err = verifyCommitments(log.Logger, tries, commitments)
+		if err != nil {
+			log.Fatal().Err(err).Msg("checkpoint is invalid")
+		}
+		log.Info().Msg("trie root hashes verified")
+	}
+
+	log.Info().Msg("checkpoint is valid")
+}
+
+func runRepair(commitments map[ledger.RootHash]struct{}) {
+	if flagExecutionStateDir == "" || flagOutputDir == "" || flagCheckpointNumber < 0 {
+		log.Fatal().Msg("--execution-state-dir, --checkpoint-number and --output-dir are required with --repair")
+	}
+
+	diskWal, err := wal.NewDiskWAL(
+		zerolog.Nop(),
+		nil,
+		metrics.NewNoopCollector(),
+		flagExecutionStateDir,
+		complete.DefaultCacheSize,
+		pathfinder.PathByteSize,
+		wal.SegmentSize,
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create disk WAL")
+	}
+
+	hasherType, err := hash.ParseHasherType(flagLedgerHasher)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid --ledger-hasher")
+	}
+	hasher, err := ledger.NewTrieHasher(hasherType)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create ledger hasher")
+	}
+
+	tries, err := rebuildCheckpoint(log.Logger, diskWal, flagCheckpointNumber, complete.DefaultCacheSize, hasher)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot rebuild checkpoint")
+	}
+
+	if commitments != nil {
+		err = verifyCommitments(log.Logger, tries, commitments)
+		if err != nil {
+			log.Fatal().Err(err).Msg("rebuilt checkpoint is invalid")
+		}
+		log.Info().Msg("trie root hashes verified")
+	}
+
+	fileName := wal.NumberToFilename(flagCheckpointNumber)
+	err = wal.StoreCheckpointV6(tries, flagOutputDir, fileName, &log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot store rebuilt checkpoint")
+	}
+
+	log.Info().Msgf("rebuilt checkpoint %s in %s with %d tries", fileName, flagOutputDir, len(tries))
+}
+
+// verifyNodes recomputes the hashes of all nodes of the given tries from the leaves up,
+// visiting nodes shared between tries once, and returns an error describing the first
+// node whose hash doesn't match its stored hash. Nodes are hashed with the hasher of their trie.
+func verifyNodes(tries []*trie.MTrie) error {
+	visitedNodes := make(map[*node.Node]uint64)
+	nodeCount := uint64(0)
+
+	for i, t := range tries {
+		for itr := flattener.NewUniqueNodeIterator(t.RootNode(), visitedNodes); itr.Next(); {
+			n := itr.Value()
+			nodeCount++
+			visitedNodes[n] = nodeCount
+
+			if n.VerifyHashWithHasher(t.Hasher()) {
+				continue
+			}
+
+			if n.IsLeaf() {
+				return fmt.Errorf("leaf node %d (height %d, path %x, hash %v) of trie %d (root hash %v) has an invalid hash",
+					nodeCount, n.Height(), *n.Path(), n.Hash(), i, t.RootHash())
+			}
+			return fmt.Errorf("interim node %d (height %d, hash %v) of trie %d (root hash %v) has an invalid hash",
+				nodeCount, n.Height(), n.Hash(), i, t.RootHash())
+		}
+	}
+
+	return nil
+}
+
+// verifyCommitments verifies that the last trie is one of the given state commitments.
+// Older tries may precede the collected state commitments, or belong to blocks which
+// haven't been executed to the end, so they are only reported.
+func verifyCommitments(log zerolog.Logger, tries []*trie.MTrie, commitments map[ledger.RootHash]struct{}) error {
+	if len(tries) == 0 {
+		return fmt.Errorf("checkpoint has no tries")
+	}
+
+	unknown := 0
+	for i, t := range tries[:len(tries)-1] {
+		if _, ok := commitments[t.RootHash()]; !ok {
+			unknown++
+			log.Debug().Int("trie", i).Msgf("root hash %v isn't a known state commitment", t.RootHash())
+		}
+	}
+	if unknown > 0 {
+		log.Warn().Msgf("%d of %d tries don't match a known state commitment", unknown, len(tries))
+	}
+
+	last := tries[len(tries)-1].RootHash()
+	if _, ok := commitments[last]; !ok {
+		return fmt.Errorf("root hash %v of the last trie isn't a known state commitment", last)
+	}
+
+	return nil
+}
+
+// stateCommitments returns the state commitments and chunk end states of the last
+// executed blocks, walking back from the last executed block.
+func stateCommitments(db *badger.DB, blocks uint) (map[ledger.RootHash]struct{}, error) {
+	headers := storagebadger.NewHeaders(&metrics.NoopCollector{}, db)
+	commits := storagebadger.NewCommits(&metrics.NoopCollector{}, db)
+	results := storagebadger.NewExecutionResults(&metrics.NoopCollector{}, db)
+
+	var blockID flow.Identifier
+	err := db.View(operation.RetrieveExecutedBlock(&blockID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot get last executed block: %w", err)
+	}
+
+	commitments := make(map[ledger.RootHash]struct{})
+	for i := uint(0); i < blocks; i++ {
+		commit, err := commits.ByBlockID(blockID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get state commitment of block %v: %w", blockID, err)
+		}
+		commitments[ledger.RootHash(commit)] = struct{}{}
+
+		result, err := results.ByBlockID(blockID)
+		if err == nil {
+			for _, chunk := range result.Chunks {
+				commitments[ledger.RootHash(chunk.EndState)] = struct{}{}
+			}
+		} else if !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("cannot get execution result of block %v: %w", blockID, err)
+		}
+
+		header, err := headers.ByBlockID(blockID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get header of block %v: %w", blockID, err)
+		}
+
+		// the parent of the root block isn't stored
+		_, err = headers.ByBlockID(header.ParentID)
+		if errors.Is(err, storage.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot get header of block %v: %w", header.ParentID, err)
+		}
+		blockID = header.ParentID
+	}
+
+	return commitments, nil
+}
+
+// rebuildCheckpoint rebuilds the tries of the given checkpoint from the latest earlier
+// checkpoint which can be loaded and passes node verification, or from the root checkpoint
+// if there is none, and the WAL segments since. The tries are rebuilt with the given hasher.
+func rebuildCheckpoint(log zerolog.Logger, diskWal *wal.DiskWAL, checkpoint int, capacity int, hasher *ledger.TrieHasher) ([]*trie.MTrie, error) {
+	checkpointer, err := diskWal.NewCheckpointer()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create checkpointer: %w", err)
+	}
+
+	checkpoints, err := checkpointer.Checkpoints()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get list of checkpoints: %w", err)
+	}
+
+	forest, err := mtrie.NewForest(capacity, &metrics.NoopCollector{}, nil, mtrie.WithHasher(hasher))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create forest: %w", err)
+	}
+
+	from := 0
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if checkpoints[i] >= checkpoint {
+			continue
+		}
+
+		log.Info().Int("checkpoint", checkpoints[i]).Msg("loading checkpoint")
+		tries, err := checkpointer.LoadCheckpoint(checkpoints[i])
+		if err == nil {
+			err = verifyNodes(tries)
+		}
+		if err != nil {
+			log.Warn().Err(err).Int("checkpoint", checkpoints[i]).Msg("checkpoint is invalid")
+			continue
+		}
+
+		err = forest.AddTries(tries)
+		if err != nil {
+			return nil, fmt.Errorf("cannot add tries to forest: %w", err)
+		}
+
+		log.Info().Int("checkpoint", checkpoints[i]).Msg("rebuilding from checkpoint")
+		from = checkpoints[i] + 1
+		break
+	}
+
+	if from == 0 {
+		log.Info().Msg("no valid checkpoint found, rebuilding from the root checkpoint")
+	}
+
+	err = diskWal.ReplayRange(from, checkpoint,
+		func(tries []*trie.MTrie) error {
+			err := verifyNodes(tries)
+			if err != nil {
+				return fmt.Errorf("root checkpoint is invalid: %w", err)
+			}
+			return forest.AddTries(tries)
+		},
+		func(update *ledger.TrieUpdate) error {
+			_, err := forest.Update(update)
+			return err
+		},
+		func(rootHash ledger.RootHash) error {
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replay segments %d to %d: %w", from, checkpoint, err)
+	}
+
+	return forest.GetTries()
+}
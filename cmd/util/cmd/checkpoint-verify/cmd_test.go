@@ -0,0 +1,133 @@
+package checkpoint_verify
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestVerifyNodes(t *testing.T) {
+	paths := testutils.RandomPaths(20)
+	payloads := make([]ledger.Payload, len(paths))
+	for i, p := range testutils.RandomPayloads(len(paths), 1, 20) {
+		payloads[i] = *p
+	}
+
+	tr, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, payloads, true)
+	require.NoError(t, err)
+
+	t.Run("valid tries", func(t *testing.T) {
+		err := verifyNodes([]*trie.MTrie{trie.NewEmptyMTrie(), tr})
+		require.NoError(t, err)
+	})
+
+	t.Run("leaf with invalid hash", func(t *testing.T) {
+		leaf := node.NewNode(ledger.NodeMaxHeight, nil, nil, paths[0], &payloads[0], hash.DummyHash)
+		invalid, err := trie.NewMTrie(leaf, 1, uint64(payloads[0].Size()))
+		require.NoError(t, err)
+
+		err = verifyNodes([]*trie.MTrie{tr, invalid})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "leaf node")
+	})
+
+	t.Run("interim node with invalid hash", func(t *testing.T) {
+		root := tr.RootNode()
+		interim := node.NewNode(ledger.NodeMaxHeight, root.LeftChild(), root.RightChild(), ledger.DummyPath, nil, hash.DummyHash)
+		invalid, err := trie.NewMTrie(interim, tr.AllocatedRegCount(), tr.AllocatedRegSize())
+		require.NoError(t, err)
+
+		err = verifyNodes([]*trie.MTrie{tr, invalid})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "interim node")
+	})
+}
+
+func TestVerifyCommitments(t *testing.T) {
+	empty := trie.NewEmptyMTrie()
+	tr, _, err := trie.NewTrieWithUpdatedRegisters(empty, testutils.RandomPaths(1), []ledger.Payload{*testutils.RandomPayload(1, 20)}, true)
+	require.NoError(t, err)
+
+	commitments := map[ledger.RootHash]struct{}{
+		tr.RootHash(): {},
+	}
+
+	err = verifyCommitments(zerolog.Nop(), []*trie.MTrie{empty, tr}, commitments)
+	require.NoError(t, err)
+
+	err = verifyCommitments(zerolog.Nop(), []*trie.MTrie{tr, empty}, commitments)
+	require.Error(t, err)
+
+	err = verifyCommitments(zerolog.Nop(), nil, commitments)
+	require.Error(t, err)
+}
+
+func TestRebuildCheckpoint(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		const capacity = 100
+
+		diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, capacity, pathfinder.PathByteSize, 32*1024)
+		require.NoError(t, err)
+		<-diskWal.Ready()
+
+		forest, err := mtrie.NewForest(capacity, &metrics.NoopCollector{}, nil)
+		require.NoError(t, err)
+
+		rootHash := trie.EmptyTrieRootHash()
+		for i := 0; i < 20; i++ {
+			update := &ledger.TrieUpdate{
+				RootHash: rootHash,
+				Paths:    testutils.RandomPaths(10),
+				Payloads: testutils.RandomPayloads(10, 100, 1000),
+			}
+
+			_, _, err := diskWal.RecordUpdate(update)
+			require.NoError(t, err)
+
+			rootHash, err = forest.Update(update)
+			require.NoError(t, err)
+		}
+		<-diskWal.Done()
+
+		expected, err := forest.GetTries()
+		require.NoError(t, err)
+
+		diskWal, err = wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, capacity, pathfinder.PathByteSize, 32*1024)
+		require.NoError(t, err)
+
+		_, last, err := diskWal.Segments()
+		require.NoError(t, err)
+		require.Greater(t, last, 0)
+
+		// a corrupted earlier checkpoint is skipped
+		err = os.WriteFile(path.Join(dir, wal.NumberToFilename(last-1)), []byte("corrupted"), 0644)
+		require.NoError(t, err)
+
+		tries, err := rebuildCheckpoint(zerolog.Nop(), diskWal, last, capacity, ledger.DefaultTrieHasher)
+		require.NoError(t, err)
+
+		require.Equal(t, len(expected), len(tries))
+		expectedRootHashes := make([]ledger.RootHash, len(expected))
+		rootHashes := make([]ledger.RootHash, len(tries))
+		for i := range expected {
+			expectedRootHashes[i] = expected[i].RootHash()
+			rootHashes[i] = tries[i].RootHash()
+		}
+		require.ElementsMatch(t, expectedRootHashes, rootHashes)
+		require.NoError(t, verifyNodes(tries))
+	})
+}
@@ -0,0 +1,145 @@
+package diff_states
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+var (
+	flagCheckpoint string
+	flagFromCommit string
+	flagToCommit   string
+	flagOutput     string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "diff-states",
+	Short: "Prints the payloads which differ between two state commitments stored in a checkpoint",
+	Long: `Loads the tries of a checkpoint and prints the payloads which have been added, changed or
+removed between the two given state commitments, as one JSON object per line ordered by path.`,
+	Run: run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"checkpoint file containing both states")
+	_ = Cmd.MarkFlagRequired("checkpoint")
+
+	Cmd.Flags().StringVar(&flagFromCommit, "from-commit", "",
+		"state commitment to diff from (hex-encoded, 64 characters)")
+	_ = Cmd.MarkFlagRequired("from-commit")
+
+	Cmd.Flags().StringVar(&flagToCommit, "to-commit", "",
+		"state commitment to diff to (hex-encoded, 64 characters)")
+	_ = Cmd.MarkFlagRequired("to-commit")
+
+	Cmd.Flags().StringVar(&flagOutput, "output", "",
+		"file to write the diff to, defaults to stdout")
+}
+
+// payloadDiff is the JSON representation of a ledger.PayloadDiff
+type payloadDiff struct {
+	Change string          `json:"change"`
+	Path   string          `json:"path"`
+	From   *ledger.Payload `json:"from,omitempty"`
+	To     *ledger.Payload `json:"to,omitempty"`
+}
+
+func run(*cobra.Command, []string) {
+
+	from, err := parseRootHash(flagFromCommit)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid --from-commit")
+	}
+
+	to, err := parseRootHash(flagToCommit)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid --to-commit")
+	}
+
+	log.Info().Msgf("loading checkpoint %v", flagCheckpoint)
+	tries, err := wal.LoadCheckpoint(flagCheckpoint, &log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error while loading checkpoint")
+	}
+	log.Info().Msgf("checkpoint loaded, total tries: %v", len(tries))
+
+	// +1 for the empty trie added by the forest
+	forest, err := mtrie.NewForest(len(tries)+1, &metrics.NoopCollector{}, nil)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create forest")
+	}
+
+	err = forest.AddTries(tries)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot add tries to forest")
+	}
+
+	diffs, err := forest.Diff(from, to)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot diff states")
+	}
+
+	var output io.Writer = os.Stdout
+	if flagOutput != "" {
+		f, err := os.Create(flagOutput)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot create output file")
+		}
+		defer f.Close()
+		output = f
+	}
+
+	err = writeDiffs(output, diffs)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot write diff")
+	}
+
+	log.Info().Int("payloads", len(diffs)).Msg("states diffed")
+}
+
+func parseRootHash(commit string) (ledger.RootHash, error) {
+	b, err := hex.DecodeString(commit)
+	if err != nil {
+		return ledger.RootHash{}, fmt.Errorf("cannot decode state commitment: %w", err)
+	}
+	return ledger.ToRootHash(b)
+}
+
+func writeDiffs(w io.Writer, diffs []ledger.PayloadDiff) error {
+	writer := bufio.NewWriter(w)
+	enc := json.NewEncoder(writer)
+
+	for _, d := range diffs {
+		change := "changed"
+		if d.IsAdded() {
+			change = "added"
+		} else if d.IsRemoved() {
+			change = "removed"
+		}
+
+		err := enc.Encode(payloadDiff{
+			Change: change,
+			Path:   hex.EncodeToString(d.Path[:]),
+			From:   d.From,
+			To:     d.To,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,40 @@
+package diff_states
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+)
+
+func TestWriteDiffs(t *testing.T) {
+	from := testutils.LightPayload8('A', 'a')
+	to := testutils.LightPayload8('A', 'b')
+
+	diffs := []ledger.PayloadDiff{
+		{Path: testutils.PathByUint8(0), To: to},
+		{Path: testutils.PathByUint8(1), From: from, To: to},
+		{Path: testutils.PathByUint8(2), From: from},
+	}
+
+	var buf bytes.Buffer
+	err := writeDiffs(&buf, diffs)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, len(diffs))
+
+	for i, change := range []string{"added", "changed", "removed"} {
+		var d payloadDiff
+		err := json.Unmarshal([]byte(lines[i]), &d)
+		require.NoError(t, err)
+		require.Equal(t, change, d.Change)
+		require.True(t, diffs[i].From.Equals(d.From))
+		require.True(t, diffs[i].To.Equals(d.To))
+	}
+}
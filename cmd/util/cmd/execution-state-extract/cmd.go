@@ -10,6 +10,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
@@ -26,6 +29,8 @@ var (
 	flagChain             string
 	flagNoMigration       bool
 	flagNoReport          bool
+	flagLedgerHasher      string
+	flagPathFinderVersion uint8
 )
 
 func getChain(chainName string) (chain flow.Chain, err error) {
@@ -70,6 +75,13 @@ func init() {
 
 	Cmd.Flags().BoolVar(&flagNoReport, "no-report", false,
 		"don't report the state")
+
+	Cmd.Flags().StringVar(&flagLedgerHasher, "ledger-hasher", hash.SHA3_256.String(),
+		"hasher of the execution state tries (sha3-256 or blake3), the extracted checkpoint records it")
+
+	Cmd.Flags().Uint8Var(&flagPathFinderVersion, "path-finder-version", complete.DefaultPathFinderVersion,
+		"version of the key to path conversion of the extracted state, the paths of all registers are recomputed with it "+
+			"(version 2 changes the state commitment and is only applicable in a spork)")
 }
 
 func run(*cobra.Command, []string) {
@@ -137,6 +149,15 @@ func run(*cobra.Command, []string) {
 		log.Fatal().Err(err).Msgf("invalid chain name")
 	}
 
+	hasherType, err := hash.ParseHasherType(flagLedgerHasher)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid --ledger-hasher")
+	}
+	hasher, err := ledger.NewTrieHasher(hasherType)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create ledger hasher")
+	}
+
 	err = extractExecutionState(
 		flagExecutionStateDir,
 		stateCommitment,
@@ -145,6 +166,8 @@ func run(*cobra.Command, []string) {
 		chain,
 		!flagNoMigration,
 		!flagNoReport,
+		hasher,
+		flagPathFinderVersion,
 	)
 
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
@@ -31,6 +32,8 @@ func extractExecutionState(
 	chain flow.Chain,
 	migrate bool,
 	report bool,
+	hasher *ledger.TrieHasher,
+	pathFinderVersion uint8,
 ) error {
 
 	diskWal, err := wal.NewDiskWAL(
@@ -51,7 +54,8 @@ func extractExecutionState(
 		complete.DefaultCacheSize,
 		&metrics.NoopCollector{},
 		log,
-		complete.DefaultPathFinderVersion)
+		complete.DefaultPathFinderVersion,
+		mtrie.WithHasher(hasher))
 	if err != nil {
 		return fmt.Errorf("cannot create ledger from write-a-head logs and checkpoints: %w", err)
 	}
@@ -126,7 +130,7 @@ func extractExecutionState(
 		migrations,
 		preCheckpointReporters,
 		postCheckpointReporters,
-		complete.DefaultPathFinderVersion,
+		pathFinderVersion,
 		outputDir,
 		bootstrap.FilenameWALRootCheckpoint,
 	)
@@ -67,6 +67,8 @@ func TestExtractExecutionState(t *testing.T) {
 				flow.Emulator.Chain(),
 				false,
 				false,
+				ledger.DefaultTrieHasher,
+				complete.DefaultPathFinderVersion,
 			)
 			require.Error(t, err)
 		})
@@ -0,0 +1,282 @@
+package columnar_exporter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/ledger/reporters"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+var (
+	flagExecutionStateDir string
+	flagOutputDir         string
+	flagStateCommitment   string
+	flagBatchSize         int
+	flagPathFinderVersion uint8
+)
+
+// globalPartition is the partition of the registers without owner
+const globalPartition = "global"
+
+// partitionFileName is the name of the Arrow IPC file of each partition
+const partitionFileName = "payloads.arrow"
+
+// schema is the schema of the exported files
+var schema = arrow.NewSchema([]arrow.Field{
+	{Name: "owner", Type: arrow.BinaryTypes.String},
+	{Name: "key", Type: arrow.BinaryTypes.String},
+	{Name: "value_size", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "payload_type", Type: arrow.BinaryTypes.String},
+	{Name: "slab_type", Type: arrow.BinaryTypes.String},
+}, nil)
+
+var Cmd = &cobra.Command{
+	Use:   "export-columnar-execution-state",
+	Short: "exports the payloads of the execution state into files partitioned by owner prefix",
+	Long: `Streams every payload at the given state into Arrow IPC files with the columns
+owner, key, value_size, payload_type and slab_type, in one directory per first byte of the
+owner address (owner_prefix=<hex>, or owner_prefix=global for registers without owner).
+Each file is written in record batches, so at most one batch per partition is kept in memory.`,
+	Run: run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagExecutionStateDir, "execution-state-dir", "",
+		"Execution Node state dir (where WAL logs are written")
+	_ = Cmd.MarkFlagRequired("execution-state-dir")
+
+	Cmd.Flags().StringVar(&flagOutputDir, "output-dir", "",
+		"Directory to write the exported files to")
+	_ = Cmd.MarkFlagRequired("output-dir")
+
+	Cmd.Flags().StringVar(&flagStateCommitment, "state-commitment", "",
+		"State commitment (hex-encoded, 64 characters), defaults to the most recent state")
+
+	Cmd.Flags().IntVar(&flagBatchSize, "batch-size", 10_000,
+		"Number of rows of the record batches of the exported files")
+
+	Cmd.Flags().Uint8Var(&flagPathFinderVersion, "path-finder-version", complete.DefaultPathFinderVersion,
+		"version of the key to path conversion of the execution state")
+}
+
+func run(*cobra.Command, []string) {
+	log.Info().Msg("start exporting ledger")
+
+	if flagBatchSize < 1 {
+		log.Fatal().Int("batch_size", flagBatchSize).Msg("batch size must be positive")
+	}
+
+	noopMetrics := &metrics.NoopCollector{}
+
+	diskWal, err := wal.NewDiskWAL(
+		zerolog.Nop(),
+		nil,
+		noopMetrics,
+		flagExecutionStateDir,
+		complete.DefaultCacheSize,
+		pathfinder.PathByteSize,
+		wal.SegmentSize,
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create WAL")
+	}
+	defer func() {
+		<-diskWal.Done()
+	}()
+
+	led, err := complete.NewLedger(diskWal, complete.DefaultCacheSize, noopMetrics, log.Logger, flagPathFinderVersion)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create ledger from write-a-head logs and checkpoints")
+	}
+
+	var state ledger.State
+	if flagStateCommitment == "" {
+		state, err = led.MostRecentTouchedState()
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load most recently used state")
+		}
+	} else {
+		st, err := hex.DecodeString(flagStateCommitment)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to decode hex code of state")
+		}
+		state, err = ledger.ToState(st)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to convert bytes to state")
+		}
+	}
+
+	outputDir := filepath.Join(flagOutputDir, state.String())
+	writer := newPartitionedWriter(outputDir, flagBatchSize)
+
+	// the payloads are streamed from the trie, leaf by leaf
+	count := 0
+	err = led.PayloadsWithPrefix(state, nil, func(_ ledger.Path, payload *ledger.Payload) error {
+		row, err := reporters.DecodePayloadColumns(payload)
+		if err != nil {
+			return fmt.Errorf("cannot decode payload %s: %w", payload.String(), err)
+		}
+		count++
+		return writer.Write(row)
+	})
+	if err != nil {
+		_ = writer.Close()
+		log.Fatal().Err(err).Msg("cannot export payloads")
+	}
+
+	err = writer.Close()
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot close exported files")
+	}
+
+	log.Info().
+		Int("payloads", count).
+		Int("partitions", len(writer.partitions)).
+		Msgf("exported state %v to %s", state, outputDir)
+}
+
+// partitionedWriter writes rows to one Arrow IPC file per owner prefix, creating the files
+// when the first row of their partition is written. The rows of each partition are buffered
+// until a record batch is full.
+type partitionedWriter struct {
+	dir        string
+	batchSize  int
+	mem        memory.Allocator
+	partitions map[string]*partitionFile
+}
+
+type partitionFile struct {
+	file    *os.File
+	writer  *ipc.FileWriter
+	builder *array.RecordBuilder
+	rows    int
+}
+
+func newPartitionedWriter(dir string, batchSize int) *partitionedWriter {
+	return &partitionedWriter{
+		dir:        dir,
+		batchSize:  batchSize,
+		mem:        memory.NewGoAllocator(),
+		partitions: make(map[string]*partitionFile),
+	}
+}
+
+// partition returns the partition of the given hex-encoded owner
+func partition(owner string) string {
+	if len(owner) < 2 {
+		return globalPartition
+	}
+	return owner[:2]
+}
+
+func (w *partitionedWriter) Write(row reporters.PayloadColumns) error {
+	name := partition(row.Owner)
+
+	p, ok := w.partitions[name]
+	if !ok {
+		var err error
+		p, err = w.create(name)
+		if err != nil {
+			return fmt.Errorf("cannot create partition %s: %w", name, err)
+		}
+		w.partitions[name] = p
+	}
+
+	p.builder.Field(0).(*array.StringBuilder).Append(row.Owner)
+	p.builder.Field(1).(*array.StringBuilder).Append(row.Key)
+	p.builder.Field(2).(*array.Int64Builder).Append(int64(row.ValueSize))
+	p.builder.Field(3).(*array.StringBuilder).Append(row.PayloadType)
+	p.builder.Field(4).(*array.StringBuilder).Append(row.SlabType)
+	p.rows++
+
+	if p.rows < w.batchSize {
+		return nil
+	}
+
+	err := p.flush()
+	if err != nil {
+		return fmt.Errorf("cannot write record batch of partition %s: %w", name, err)
+	}
+	return nil
+}
+
+func (w *partitionedWriter) create(name string) (*partitionFile, error) {
+	dir := filepath.Join(w.dir, "owner_prefix="+name)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(filepath.Join(dir, partitionFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := ipc.NewFileWriter(file, ipc.WithSchema(schema), ipc.WithAllocator(w.mem))
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &partitionFile{
+		file:    file,
+		writer:  writer,
+		builder: array.NewRecordBuilder(w.mem, schema),
+	}, nil
+}
+
+// Close flushes and closes all files, and returns the first error.
+func (w *partitionedWriter) Close() error {
+	var firstErr error
+	for name, p := range w.partitions {
+		err := p.close()
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("cannot close partition %s: %w", name, err)
+		}
+	}
+	return firstErr
+}
+
+// flush writes the buffered rows as a record batch.
+func (p *partitionFile) flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+
+	record := p.builder.NewRecord()
+	defer record.Release()
+
+	p.rows = 0
+	return p.writer.Write(record)
+}
+
+func (p *partitionFile) close() error {
+	defer p.builder.Release()
+
+	err := p.flush()
+	if err == nil {
+		// writes the footer of the file
+		err = p.writer.Close()
+	}
+
+	closeErr := p.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package columnar_exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/cmd/util/ledger/reporters"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestPartitionedWriter(t *testing.T) {
+	rows := []reporters.PayloadColumns{
+		{Owner: "", Key: "uuid", ValueSize: 8, PayloadType: "fvm"},
+		{Owner: "0102030405060708", Key: "storage", ValueSize: 10, PayloadType: "storage"},
+		{Owner: "01ffffffffffffff", Key: "$000000000000002a", ValueSize: 20, PayloadType: "slab", SlabType: "map_data"},
+		{Owner: "01ffffffffffffff", Key: "$000000000000002b", ValueSize: 40, PayloadType: "slab", SlabType: "array_data"},
+		{Owner: "f102030405060708", Key: "contract_names", ValueSize: 30, PayloadType: "fvm"},
+	}
+
+	// readPartition returns the rows of the partition, and the number of record batches
+	readPartition := func(t *testing.T, dir string, name string) ([]reporters.PayloadColumns, int) {
+		f, err := os.Open(filepath.Join(dir, "owner_prefix="+name, partitionFileName))
+		require.NoError(t, err)
+		defer f.Close()
+
+		reader, err := ipc.NewFileReader(f)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		require.True(t, reader.Schema().Equal(schema))
+
+		var rows []reporters.PayloadColumns
+		for i := 0; i < reader.NumRecords(); i++ {
+			record, err := reader.Record(i)
+			require.NoError(t, err)
+
+			for j := 0; j < int(record.NumRows()); j++ {
+				rows = append(rows, reporters.PayloadColumns{
+					Owner:       record.Column(0).(*array.String).Value(j),
+					Key:         record.Column(1).(*array.String).Value(j),
+					ValueSize:   int(record.Column(2).(*array.Int64).Value(j)),
+					PayloadType: record.Column(3).(*array.String).Value(j),
+					SlabType:    record.Column(4).(*array.String).Value(j),
+				})
+			}
+		}
+		return rows, reader.NumRecords()
+	}
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		writer := newPartitionedWriter(dir, 2)
+		for _, row := range rows {
+			require.NoError(t, writer.Write(row))
+		}
+		require.NoError(t, writer.Close())
+
+		require.Len(t, writer.partitions, 3)
+
+		global, batches := readPartition(t, dir, globalPartition)
+		require.Equal(t, rows[:1], global)
+		require.Equal(t, 1, batches)
+
+		// a full batch, and the remaining row written on close
+		partition01, batches := readPartition(t, dir, "01")
+		require.Equal(t, rows[1:4], partition01)
+		require.Equal(t, 2, batches)
+
+		partitionF1, batches := readPartition(t, dir, "f1")
+		require.Equal(t, rows[4:], partitionF1)
+		require.Equal(t, 1, batches)
+	})
+}
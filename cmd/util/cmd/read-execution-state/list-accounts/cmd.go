@@ -29,6 +29,7 @@ var cmd = &cobra.Command{
 var stateLoader func() *mtrie.Forest = nil
 var flagStateCommitment string
 var flagChain string
+var flagPathFinderVersion uint8
 
 func Init(f func() *mtrie.Forest) *cobra.Command {
 	stateLoader = f
@@ -41,6 +42,9 @@ func Init(f func() *mtrie.Forest) *cobra.Command {
 		"Chain name")
 	_ = cmd.MarkFlagRequired("chain")
 
+	cmd.Flags().Uint8Var(&flagPathFinderVersion, "path-finder-version", complete.DefaultPathFinderVersion,
+		"version of the key to path conversion of the execution state")
+
 	return cmd
 }
 
@@ -77,7 +81,7 @@ func run(*cobra.Command, []string) {
 	ldg := delta.NewView(func(owner, key string) (flow.RegisterValue, error) {
 
 		ledgerKey := executionState.RegisterIDToKey(flow.NewRegisterID(owner, key))
-		path, err := pathfinder.KeyToPath(ledgerKey, complete.DefaultPathFinderVersion)
+		path, err := pathfinder.KeyToPath(ledgerKey, flagPathFinderVersion)
 		if err != nil {
 			log.Fatal().Err(err).Msgf("cannot convert key to path")
 		}
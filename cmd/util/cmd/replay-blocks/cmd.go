@@ -0,0 +1,143 @@
+package replay_blocks
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+var (
+	flagExecutionStateDir string
+	flagDatadir           string
+	flagChain             string
+	flagBlockID           string
+	flagFromHeight        uint64
+	flagToHeight          uint64
+	flagPathFinderVersion uint8
+)
+
+var Cmd = &cobra.Command{
+	Use:   "replay-blocks",
+	Short: "Re-executes blocks against the execution state of a checkpoint and compares the results with the stored ones",
+	Long: `Loads the execution state from a checkpoint and the WAL segments following it, re-executes
+the given blocks with the FVM and compares the produced state commitments, trie updates and events
+with the ones stored by the execution node. No network access is needed.
+
+The execution state must contain the state the first block is executed against. The directory is
+opened like an execution node would open it, copy it first if it is used by a node.`,
+	Run: run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagExecutionStateDir, "execution-state-dir", "",
+		"execution state dir, containing the checkpoint and optional WAL segments")
+	_ = Cmd.MarkFlagRequired("execution-state-dir")
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory that stores the protocol state of the execution node")
+	_ = Cmd.MarkFlagRequired("datadir")
+
+	Cmd.Flags().StringVar(&flagChain, "chain", "", "Chain name")
+	_ = Cmd.MarkFlagRequired("chain")
+
+	Cmd.Flags().StringVar(&flagBlockID, "block-id", "",
+		"ID of the block to replay (hex-encoded, 64 characters)")
+
+	Cmd.Flags().Uint64Var(&flagFromHeight, "from-height", 0,
+		"height of the first finalized block to replay")
+
+	Cmd.Flags().Uint64Var(&flagToHeight, "to-height", 0,
+		"height of the last finalized block to replay, defaults to --from-height")
+
+	Cmd.Flags().Uint8Var(&flagPathFinderVersion, "path-finder-version", complete.DefaultPathFinderVersion,
+		"version of the key to path conversion of the execution state")
+}
+
+func getChain(chainName string) (chain flow.Chain, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid chain: %s", r)
+		}
+	}()
+	chain = flow.ChainID(chainName).Chain()
+	return
+}
+
+func run(*cobra.Command, []string) {
+	if len(flagBlockID) > 0 && flagFromHeight > 0 {
+		log.Fatal().Msg("cannot run the command with both block id and heights as inputs, only one of them should be provided")
+	}
+	if len(flagBlockID) == 0 && flagFromHeight == 0 {
+		log.Fatal().Msg("either --block-id or --from-height must be provided")
+	}
+
+	chain, err := getChain(flagChain)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid chain name")
+	}
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	storages := common.InitStorages(db)
+
+	var headers []*flow.Header
+	if len(flagBlockID) > 0 {
+		blockID, err := flow.HexStringToIdentifier(flagBlockID)
+		if err != nil {
+			log.Fatal().Err(err).Msg("malformed block id")
+		}
+
+		header, err := storages.Headers.ByBlockID(blockID)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not get block")
+		}
+		headers = append(headers, header)
+	} else {
+		toHeight := flagToHeight
+		if toHeight == 0 {
+			toHeight = flagFromHeight
+		}
+		if toHeight < flagFromHeight {
+			log.Fatal().Msg("--to-height must not be lower than --from-height")
+		}
+
+		for height := flagFromHeight; height <= toHeight; height++ {
+			header, err := storages.Headers.ByHeight(height)
+			if err != nil {
+				log.Fatal().Err(err).Uint64("height", height).Msg("could not get finalized block")
+			}
+			headers = append(headers, header)
+		}
+	}
+
+	replayer, err := newBlockReplayer(log.Logger, chain, flagExecutionStateDir, flagPathFinderVersion, storages, headers)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not load execution state")
+	}
+	defer replayer.Close()
+
+	mismatches := 0
+	for _, header := range headers {
+		result, err := replayer.ReplayBlock(header)
+		if err != nil {
+			log.Fatal().Err(err).Uint64("height", header.Height).Msg("could not replay block")
+		}
+
+		result.Log(log.Logger)
+		if !result.Matches() {
+			mismatches++
+		}
+	}
+
+	if mismatches > 0 {
+		log.Fatal().Int("mismatching_blocks", mismatches).Int("blocks", len(headers)).Msg("replayed blocks do not match stored results")
+	}
+
+	log.Info().Int("blocks", len(headers)).Msg("all replayed blocks match stored results")
+}
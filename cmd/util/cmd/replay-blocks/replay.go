@@ -0,0 +1,599 @@
+package replay_blocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/computation/committer"
+	"github.com/onflow/flow-go/engine/execution/computation/computer"
+	execState "github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/mempool/entity"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/trace"
+	"github.com/onflow/flow-go/storage"
+)
+
+// blockReplayer re-executes blocks against an execution state loaded from a checkpoint and WAL
+// segments, without persisting anything.
+type blockReplayer struct {
+	log           zerolog.Logger
+	blockComputer computer.BlockComputer
+	storages      *storage.All
+	ledger        *complete.Ledger
+	compactor     *noopCompactor
+	// trieUpdates are the trie updates recorded in the WAL segments by the execution node,
+	// by the chunk they were produced by
+	trieUpdates map[chunkKey]*ledger.TrieUpdate
+}
+
+// chunkKey identifies a chunk by its block and its index in the block
+type chunkKey struct {
+	blockID flow.Identifier
+	index   int
+}
+
+// chunkStates are the start and end state of a chunk
+type chunkStates struct {
+	key        chunkKey
+	startState ledger.RootHash
+	endState   ledger.RootHash
+}
+
+func newBlockReplayer(
+	log zerolog.Logger,
+	chain flow.Chain,
+	dir string,
+	pathFinderVersion uint8,
+	storages *storage.All,
+	headers []*flow.Header,
+) (*blockReplayer, error) {
+
+	diskWal, err := wal.NewDiskWAL(
+		zerolog.Nop(),
+		nil,
+		metrics.NewNoopCollector(),
+		dir,
+		complete.DefaultCacheSize,
+		pathfinder.PathByteSize,
+		wal.SegmentSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create disk WAL: %w", err)
+	}
+
+	// collect the trie updates of the chunks to replay, so the trie updates produced by the
+	// replay can be compared with them. Chunks of different blocks can have the same start state,
+	// so the trie updates applied to it are kept until they are matched with the chunks.
+	var chunks []chunkStates
+	startStates := make(map[ledger.RootHash]struct{})
+	for _, header := range headers {
+		result, err := storages.Results.ByBlockID(header.ID())
+		if err != nil {
+			return nil, fmt.Errorf("cannot get execution result of block %v: %w", header.ID(), err)
+		}
+		for i, chunk := range result.Chunks {
+			chunks = append(chunks, chunkStates{
+				key:        chunkKey{blockID: header.ID(), index: i},
+				startState: ledger.RootHash(chunk.StartState),
+				endState:   ledger.RootHash(chunk.EndState),
+			})
+			startStates[ledger.RootHash(chunk.StartState)] = struct{}{}
+		}
+	}
+
+	storedUpdates := make(map[ledger.RootHash][]*ledger.TrieUpdate)
+	err = diskWal.ReplayLogsOnly(
+		func(tries []*trie.MTrie) error {
+			return nil
+		},
+		func(update *ledger.TrieUpdate) error {
+			if _, ok := startStates[update.RootHash]; ok && !update.IsEmpty() {
+				storedUpdates[update.RootHash] = append(storedUpdates[update.RootHash], update)
+			}
+			return nil
+		},
+		func(rootHash ledger.RootHash) error {
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read trie updates from WAL: %w", err)
+	}
+
+	led, err := complete.NewLedger(
+		diskWal,
+		complete.DefaultCacheSize,
+		&metrics.NoopCollector{},
+		log,
+		pathFinderVersion)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create ledger from write-a-head logs and checkpoints: %w", err)
+	}
+
+	compactor := newNoopCompactor(led)
+
+	trieUpdates, err := matchTrieUpdates(led, chunks, storedUpdates)
+	if err != nil {
+		<-led.Done()
+		<-compactor.Done()
+		return nil, fmt.Errorf("cannot match stored trie updates with chunks: %w", err)
+	}
+
+	log.Info().
+		Int("chunks", len(chunks)).
+		Int("trie_updates", len(trieUpdates)).
+		Msg("read stored trie updates from WAL segments")
+
+	// use the same options as execution nodes
+	vmOpts := []fvm.Option{
+		fvm.WithChain(chain),
+		fvm.WithBlocks(environment.NewBlockFinder(storages.Headers)),
+		fvm.WithAccountStorageLimit(true),
+		fvm.WithTransactionFeesEnabled(true),
+		fvm.WithExecutionParametersActivationHeight(fvm.ExecutionParametersActivationHeight(chain.ChainID())),
+	}
+	switch chain.ChainID() {
+	case flow.Testnet, flow.Stagingnet, flow.Localnet, flow.Benchnet:
+		vmOpts = append(vmOpts, fvm.WithContractDeploymentRestricted(false))
+	}
+
+	// the execution data of the replayed blocks is not provided
+	blockComputer, err := computer.NewBlockComputer(
+		fvm.NewVirtualMachine(fvm.NewInterpreterRuntime()),
+		fvm.NewContext(log, vmOpts...),
+		metrics.NewNoopCollector(),
+		trace.NewNoopTracer(),
+		log,
+		committer.NewLedgerViewCommitter(led, trace.NewNoopTracer()),
+		nil,
+	)
+	if err != nil {
+		<-led.Done()
+		<-compactor.Done()
+		return nil, fmt.Errorf("cannot create block computer: %w", err)
+	}
+
+	return &blockReplayer{
+		log:           log,
+		blockComputer: blockComputer,
+		storages:      storages,
+		ledger:        led,
+		compactor:     compactor,
+		trieUpdates:   trieUpdates,
+	}, nil
+}
+
+// matchTrieUpdates returns the stored trie updates of the given chunks, by chunk. A trie update
+// belongs to a chunk if it is applied to the start state of the chunk and results in its end state.
+// Trie updates applied to states no longer held by the ledger are not matched.
+func matchTrieUpdates(
+	led *complete.Ledger,
+	chunks []chunkStates,
+	storedUpdates map[ledger.RootHash][]*ledger.TrieUpdate,
+) (map[chunkKey]*ledger.TrieUpdate, error) {
+
+	tries, err := led.Tries()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get tries of ledger: %w", err)
+	}
+	triesByRootHash := make(map[ledger.RootHash]*trie.MTrie, len(tries))
+	for _, t := range tries {
+		triesByRootHash[t.RootHash()] = t
+	}
+
+	// the end states of the stored trie updates, by start state
+	updatesByEndState := make(map[ledger.RootHash]map[ledger.RootHash]*ledger.TrieUpdate, len(storedUpdates))
+	for startState, updates := range storedUpdates {
+		parentTrie, ok := triesByRootHash[startState]
+		if !ok {
+			continue
+		}
+		byEndState := make(map[ledger.RootHash]*ledger.TrieUpdate, len(updates))
+		for _, update := range updates {
+			endState, err := trieUpdateEndState(parentTrie, update)
+			if err != nil {
+				return nil, fmt.Errorf("cannot apply trie update to state %x: %w", startState, err)
+			}
+			byEndState[endState] = update
+		}
+		updatesByEndState[startState] = byEndState
+	}
+
+	trieUpdates := make(map[chunkKey]*ledger.TrieUpdate)
+	for _, chunk := range chunks {
+		update, ok := updatesByEndState[chunk.startState][chunk.endState]
+		if ok {
+			trieUpdates[chunk.key] = update
+		}
+	}
+	return trieUpdates, nil
+}
+
+// trieUpdateEndState returns the root hash of the trie resulting from applying the given trie update
+// to the given trie. Like the forest, only the last write to a register is applied.
+func trieUpdateEndState(parentTrie *trie.MTrie, update *ledger.TrieUpdate) (ledger.RootHash, error) {
+	paths := make([]ledger.Path, 0, len(update.Paths))
+	payloads := make([]ledger.Payload, 0, len(update.Paths))
+	indices := make(map[ledger.Path]int, len(update.Paths))
+	for i, path := range update.Paths {
+		if idx, ok := indices[path]; ok {
+			payloads[idx] = *update.Payloads[i]
+			continue
+		}
+		indices[path] = len(paths)
+		paths = append(paths, path)
+		payloads = append(payloads, *update.Payloads[i])
+	}
+
+	newTrie, _, err := trie.NewTrieWithUpdatedRegisters(parentTrie, paths, payloads, true)
+	if err != nil {
+		return ledger.RootHash(hash.DummyHash), err
+	}
+	return newTrie.RootHash(), nil
+}
+
+func (r *blockReplayer) Close() {
+	<-r.ledger.Done()
+	<-r.compactor.Done()
+}
+
+// ReplayBlock re-executes the given block against the state commitment of its parent and
+// compares the results with the stored execution result, events and trie updates.
+func (r *blockReplayer) ReplayBlock(header *flow.Header) (*blockReplayResult, error) {
+	blockID := header.ID()
+
+	block, err := r.storages.Blocks.ByID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get block: %w", err)
+	}
+
+	executionResult, err := r.storages.Results.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get execution result: %w", err)
+	}
+
+	expectedCommit, err := r.storages.Commits.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state commitment: %w", err)
+	}
+
+	expectedEvents, err := r.storages.Events.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get events: %w", err)
+	}
+
+	startState, err := r.storages.Commits.ByBlockID(header.ParentID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state commitment of parent block: %w", err)
+	}
+
+	if !r.ledger.HasState(ledger.State(startState)) {
+		return nil, fmt.Errorf("execution state does not contain the start state %x of the block", startState)
+	}
+
+	collections := make(map[flow.Identifier]*entity.CompleteCollection, len(block.Payload.Guarantees))
+	for _, guarantee := range block.Payload.Guarantees {
+		collection, err := r.storages.Collections.ByID(guarantee.CollectionID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get collection %v: %w", guarantee.CollectionID, err)
+		}
+		collections[guarantee.ID()] = &entity.CompleteCollection{
+			Guarantee:    guarantee,
+			Transactions: collection.Transactions,
+		}
+	}
+
+	executableBlock := &entity.ExecutableBlock{
+		Block:               block,
+		CompleteCollections: collections,
+		StartState:          &startState,
+	}
+
+	blockView := delta.NewView(execState.LedgerGetRegister(r.ledger, startState))
+	computationResult, err := r.blockComputer.ExecuteBlock(context.Background(), executableBlock, blockView, programs.NewEmptyPrograms())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute block: %w", err)
+	}
+
+	result := &blockReplayResult{
+		BlockID:        blockID,
+		Height:         header.Height,
+		ExpectedCommit: expectedCommit,
+		ExpectedChunks: len(executionResult.Chunks),
+	}
+
+	for _, txResult := range computationResult.TransactionResults {
+		if txResult.ErrorMessage != "" {
+			result.FailedTransactions = append(result.FailedTransactions, failedTransaction{
+				TransactionID: txResult.TransactionID,
+				ErrorMessage:  txResult.ErrorMessage,
+			})
+		}
+	}
+
+	var events []flow.Event
+	commit := startState
+	for i, endState := range computationResult.StateCommitments {
+		events = append(events, computationResult.Events[i]...)
+
+		chunkResult := chunkReplayResult{
+			Index:      i,
+			StartState: commit,
+			EndState:   endState,
+		}
+		if i < len(executionResult.Chunks) {
+			chunk := executionResult.Chunks[i]
+			chunkResult.ExpectedEndState = chunk.EndState
+
+			expectedTrieUpdate, ok := r.trieUpdates[chunkKey{blockID: blockID, index: i}]
+			if ok || chunk.StartState == chunk.EndState {
+				diff, err := diffTrieUpdates(expectedTrieUpdate, computationResult.TrieUpdates[i])
+				if err != nil {
+					return nil, fmt.Errorf("cannot compare trie updates of chunk %d: %w", i, err)
+				}
+				chunkResult.RegisterDiff = diff
+			} else {
+				chunkResult.TrieUpdateMissing = true
+			}
+		}
+		result.Chunks = append(result.Chunks, chunkResult)
+
+		commit = endState
+	}
+
+	result.Commit = commit
+	result.EventDiff = diffEvents(expectedEvents, events)
+
+	return result, nil
+}
+
+// noopCompactor consumes the trie updates of the ledger without writing them to the WAL,
+// so that replaying blocks doesn't change the execution state on disk.
+type noopCompactor struct {
+	trieUpdateCh <-chan *complete.WALTrieUpdate
+	done         chan struct{}
+}
+
+func newNoopCompactor(l *complete.Ledger) *noopCompactor {
+	c := &noopCompactor{
+		trieUpdateCh: l.TrieUpdateChan(),
+		done:         make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// run acknowledges the trie updates until the ledger closes the channel of trie updates
+func (c *noopCompactor) run() {
+	defer close(c.done)
+
+	for update := range c.trieUpdateCh {
+		update.ResultCh <- nil
+
+		// wait for the trie to be updated
+		<-update.TrieCh
+	}
+}
+
+// Done returns a channel closed once the ledger is done and all trie updates are consumed.
+func (c *noopCompactor) Done() <-chan struct{} {
+	return c.done
+}
+
+// failedTransaction is a transaction that failed during the replay.
+type failedTransaction struct {
+	TransactionID flow.Identifier
+	ErrorMessage  string
+}
+
+// registerDiff is a register updated differently by the replay than by the execution node.
+// A nil value means the register was not updated.
+type registerDiff struct {
+	RegisterID flow.RegisterID
+	Expected   ledger.Value
+	Actual     ledger.Value
+}
+
+// chunkReplayResult is the result of replaying a chunk.
+type chunkReplayResult struct {
+	Index            int
+	StartState       flow.StateCommitment
+	EndState         flow.StateCommitment
+	ExpectedEndState flow.StateCommitment
+	// RegisterDiff are the differences between the produced and the stored trie updates
+	RegisterDiff []registerDiff
+	// TrieUpdateMissing is set if the stored trie update of the chunk is not in the WAL segments
+	TrieUpdateMissing bool
+}
+
+func (c chunkReplayResult) Matches() bool {
+	return c.EndState == c.ExpectedEndState && len(c.RegisterDiff) == 0
+}
+
+// blockReplayResult is the result of replaying a block.
+type blockReplayResult struct {
+	BlockID            flow.Identifier
+	Height             uint64
+	Commit             flow.StateCommitment
+	ExpectedCommit     flow.StateCommitment
+	Chunks             []chunkReplayResult
+	ExpectedChunks     int
+	EventDiff          []string
+	FailedTransactions []failedTransaction
+}
+
+// Matches returns true if the replay produced the same results as the execution node.
+func (r *blockReplayResult) Matches() bool {
+	if r.Commit != r.ExpectedCommit || len(r.Chunks) != r.ExpectedChunks || len(r.EventDiff) > 0 {
+		return false
+	}
+	for _, chunk := range r.Chunks {
+		if !chunk.Matches() {
+			return false
+		}
+	}
+	return true
+}
+
+// Log logs the result of the replay, with the details of any mismatch.
+func (r *blockReplayResult) Log(log zerolog.Logger) {
+	lg := log.With().
+		Hex("block_id", r.BlockID[:]).
+		Uint64("height", r.Height).
+		Logger()
+
+	for _, tx := range r.FailedTransactions {
+		lg.Info().
+			Hex("tx_id", tx.TransactionID[:]).
+			Str("error_message", tx.ErrorMessage).
+			Msg("transaction failed")
+	}
+
+	if r.Matches() {
+		lg.Info().Hex("commit", r.Commit[:]).Msg("replayed block matches stored results")
+		return
+	}
+
+	if len(r.Chunks) != r.ExpectedChunks {
+		lg.Warn().
+			Int("chunks", len(r.Chunks)).
+			Int("expected_chunks", r.ExpectedChunks).
+			Msg("number of chunks mismatch")
+	}
+
+	for _, chunk := range r.Chunks {
+		if chunk.TrieUpdateMissing {
+			lg.Info().Int("chunk_index", chunk.Index).Msg("stored trie update not found in WAL segments, trie updates not compared")
+		}
+		if chunk.Matches() {
+			continue
+		}
+
+		lg.Warn().
+			Int("chunk_index", chunk.Index).
+			Hex("start_state", chunk.StartState[:]).
+			Hex("end_state", chunk.EndState[:]).
+			Hex("expected_end_state", chunk.ExpectedEndState[:]).
+			Msg("chunk mismatch")
+
+		for _, diff := range chunk.RegisterDiff {
+			lg.Warn().
+				Int("chunk_index", chunk.Index).
+				Str("register", diff.RegisterID.String()).
+				Str("value", hex.EncodeToString(diff.Actual)).
+				Str("expected_value", hex.EncodeToString(diff.Expected)).
+				Msg("register update mismatch")
+		}
+	}
+
+	for _, diff := range r.EventDiff {
+		lg.Warn().Str("diff", diff).Msg("event mismatch")
+	}
+
+	lg.Warn().
+		Hex("commit", r.Commit[:]).
+		Hex("expected_commit", r.ExpectedCommit[:]).
+		Msg("replayed block does not match stored results")
+}
+
+// diffTrieUpdates returns the registers updated differently by the given trie updates, ordered by
+// their paths. A nil trie update is an empty update.
+func diffTrieUpdates(expected, actual *ledger.TrieUpdate) ([]registerDiff, error) {
+	expectedPayloads := trieUpdatePayloads(expected)
+	actualPayloads := trieUpdatePayloads(actual)
+
+	paths := make([]ledger.Path, 0, len(expectedPayloads))
+	for path := range expectedPayloads {
+		paths = append(paths, path)
+	}
+	for path := range actualPayloads {
+		if _, ok := expectedPayloads[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return bytes.Compare(paths[i][:], paths[j][:]) < 0
+	})
+
+	var diffs []registerDiff
+	for _, path := range paths {
+		expectedPayload := expectedPayloads[path]
+		actualPayload := actualPayloads[path]
+		if expectedPayload != nil && actualPayload != nil && expectedPayload.ValueEquals(actualPayload) {
+			continue
+		}
+
+		payload := expectedPayload
+		if payload == nil {
+			payload = actualPayload
+		}
+		key, err := payload.Key()
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode key of payload at path %x: %w", path, err)
+		}
+		registerID, err := execState.KeyToRegisterID(key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert key to register ID: %w", err)
+		}
+
+		diff := registerDiff{RegisterID: registerID}
+		if expectedPayload != nil {
+			diff.Expected = expectedPayload.Value()
+		}
+		if actualPayload != nil {
+			diff.Actual = actualPayload.Value()
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+func trieUpdatePayloads(update *ledger.TrieUpdate) map[ledger.Path]*ledger.Payload {
+	payloads := make(map[ledger.Path]*ledger.Payload)
+	if update == nil {
+		return payloads
+	}
+	for i, path := range update.Paths {
+		payloads[path] = update.Payloads[i]
+	}
+	return payloads
+}
+
+// diffEvents returns a description of each difference between the expected and actual events.
+func diffEvents(expected, actual []flow.Event) []string {
+	var diffs []string
+	if len(expected) != len(actual) {
+		diffs = append(diffs, fmt.Sprintf("expected %d events, got %d", len(expected), len(actual)))
+	}
+
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		e, a := expected[i], actual[i]
+		if e.Type == a.Type &&
+			e.TransactionID == a.TransactionID &&
+			e.TransactionIndex == a.TransactionIndex &&
+			e.EventIndex == a.EventIndex &&
+			bytes.Equal(e.Payload, a.Payload) {
+			continue
+		}
+		diffs = append(diffs, fmt.Sprintf("event %d: expected %s, got %s", i, e, a))
+	}
+
+	return diffs
+}
@@ -0,0 +1,265 @@
+package replay_blocks
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	bootstrapRun "github.com/onflow/flow-go/cmd/bootstrap/run"
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	execState "github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/epochs"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func trieUpdateFixture(t *testing.T, registers map[flow.RegisterID]flow.RegisterValue) *ledger.TrieUpdate {
+	update := &ledger.TrieUpdate{}
+	for id, value := range registers {
+		key := execState.RegisterIDToKey(id)
+		path, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+		require.NoError(t, err)
+
+		update.Paths = append(update.Paths, path)
+		update.Payloads = append(update.Payloads, ledger.NewPayload(key, value))
+	}
+	return update
+}
+
+func TestDiffTrieUpdates(t *testing.T) {
+	same := flow.RegisterID{Owner: "owner", Key: "same"}
+	changed := flow.RegisterID{Owner: "owner", Key: "changed"}
+	expectedOnly := flow.RegisterID{Owner: "owner", Key: "expected"}
+	actualOnly := flow.RegisterID{Owner: "owner", Key: "actual"}
+
+	expected := trieUpdateFixture(t, map[flow.RegisterID]flow.RegisterValue{
+		same:         []byte{1},
+		changed:      []byte{2},
+		expectedOnly: []byte{3},
+	})
+	actual := trieUpdateFixture(t, map[flow.RegisterID]flow.RegisterValue{
+		same:       []byte{1},
+		changed:    []byte{4},
+		actualOnly: []byte{5},
+	})
+
+	t.Run("equal", func(t *testing.T) {
+		diff, err := diffTrieUpdates(expected, expected)
+		require.NoError(t, err)
+		require.Empty(t, diff)
+
+		diff, err = diffTrieUpdates(nil, nil)
+		require.NoError(t, err)
+		require.Empty(t, diff)
+	})
+
+	t.Run("different", func(t *testing.T) {
+		diff, err := diffTrieUpdates(expected, actual)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []registerDiff{
+			{RegisterID: changed, Expected: []byte{2}, Actual: []byte{4}},
+			{RegisterID: expectedOnly, Expected: []byte{3}},
+			{RegisterID: actualOnly, Actual: []byte{5}},
+		}, diff)
+	})
+
+	t.Run("missing update", func(t *testing.T) {
+		diff, err := diffTrieUpdates(nil, actual)
+		require.NoError(t, err)
+		require.Len(t, diff, 3)
+	})
+}
+
+func TestDiffEvents(t *testing.T) {
+	txID := unittest.IdentifierFixture()
+	events := []flow.Event{
+		unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0),
+		unittest.EventFixture(flow.EventAccountUpdated, 0, 1, txID, 0),
+	}
+
+	require.Empty(t, diffEvents(events, events))
+
+	changed := make([]flow.Event, len(events))
+	copy(changed, events)
+	changed[1].Payload = []byte("changed")
+	require.Len(t, diffEvents(events, changed), 1)
+
+	require.Len(t, diffEvents(events, events[:1]), 1)
+}
+
+func TestMatchTrieUpdates(t *testing.T) {
+	led, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+	compactor := newNoopCompactor(led)
+	defer func() {
+		<-led.Done()
+		<-compactor.Done()
+	}()
+
+	register := flow.RegisterID{Owner: "owner", Key: "key"}
+	key := execState.RegisterIDToKey(register)
+
+	u, err := ledger.NewUpdate(led.InitialState(), []ledger.Key{key}, []ledger.Value{[]byte{1}})
+	require.NoError(t, err)
+	startState, _, err := led.Set(u)
+	require.NoError(t, err)
+
+	// two forks execute chunks against the same start state
+	u, err = ledger.NewUpdate(startState, []ledger.Key{key}, []ledger.Value{[]byte{2}})
+	require.NoError(t, err)
+	endState1, update1, err := led.Set(u)
+	require.NoError(t, err)
+
+	u, err = ledger.NewUpdate(startState, []ledger.Key{key}, []ledger.Value{[]byte{3}})
+	require.NoError(t, err)
+	endState2, update2, err := led.Set(u)
+	require.NoError(t, err)
+
+	blockID1 := unittest.IdentifierFixture()
+	blockID2 := unittest.IdentifierFixture()
+	chunks := []chunkStates{
+		// chunk without register updates before the updating chunk
+		{key: chunkKey{blockID: blockID1, index: 0}, startState: ledger.RootHash(startState), endState: ledger.RootHash(startState)},
+		{key: chunkKey{blockID: blockID1, index: 1}, startState: ledger.RootHash(startState), endState: ledger.RootHash(endState1)},
+		{key: chunkKey{blockID: blockID2, index: 0}, startState: ledger.RootHash(startState), endState: ledger.RootHash(endState2)},
+		// chunk whose trie update is not stored
+		{key: chunkKey{blockID: blockID2, index: 1}, startState: ledger.RootHash(endState2), endState: ledger.RootHash(unittest.StateCommitmentFixture())},
+	}
+	storedUpdates := map[ledger.RootHash][]*ledger.TrieUpdate{
+		ledger.RootHash(startState): {update1, update2},
+	}
+
+	trieUpdates, err := matchTrieUpdates(led, chunks, storedUpdates)
+	require.NoError(t, err)
+	require.Equal(t, map[chunkKey]*ledger.TrieUpdate{
+		{blockID: blockID1, index: 1}: update1,
+		{blockID: blockID2, index: 0}: update2,
+	}, trieUpdates)
+}
+
+// storeExecutedBlock stores the given block as executed with the given results
+func storeExecutedBlock(
+	t *testing.T,
+	db *badger.DB,
+	storages *storage.All,
+	parent *flow.Header,
+	parentCommit flow.StateCommitment,
+	block *flow.Block,
+	chunkEndStates []flow.StateCommitment,
+	events []flow.Event,
+) {
+	require.NoError(t, storages.Headers.Store(parent))
+	require.NoError(t, storages.Commits.Store(parent.ID(), parentCommit))
+	require.NoError(t, storages.Blocks.Store(block))
+
+	result := unittest.ExecutionResultFixture(unittest.WithExecutionResultBlockID(block.ID()))
+	result.Chunks = nil
+	startState := parentCommit
+	for i, endState := range chunkEndStates {
+		chunk := unittest.ChunkFixture(block.ID(), uint(i))
+		chunk.StartState = startState
+		chunk.EndState = endState
+		result.Chunks = append(result.Chunks, chunk)
+		startState = endState
+	}
+	require.NoError(t, storages.Results.Store(result))
+	require.NoError(t, storages.Results.Index(block.ID(), result.ID()))
+	require.NoError(t, storages.Commits.Store(block.ID(), startState))
+
+	batch := bstorage.NewBatch(db)
+	require.NoError(t, storages.Events.BatchStore(block.ID(), []flow.EventsList{events}, batch))
+	require.NoError(t, batch.Flush())
+}
+
+func TestReplayBlock(t *testing.T) {
+	chain := flow.Emulator.Chain()
+
+	// the replayed block is in the staking auction, so that the system chunk doesn't start the epoch setup
+	epochConfig := epochs.DefaultEpochConfig()
+	epochConfig.NumViewsInEpoch = 10_000
+	epochConfig.NumViewsInStakingAuction = 1_000
+
+	unittest.RunWithTempDir(t, func(executionStateDir string) {
+		genesisCommit, err := bootstrapRun.GenerateExecutionState(
+			executionStateDir,
+			unittest.ServiceAccountPublicKey,
+			chain,
+			complete.DefaultPathFinderVersion,
+			fvm.WithInitialTokenSupply(unittest.GenesisTokenSupply),
+			fvm.WithEpochConfig(epochConfig),
+		)
+		require.NoError(t, err)
+
+		files := listFiles(t, executionStateDir)
+
+		parent := unittest.BlockHeaderFixtureOnChain(chain.ChainID(), func(header *flow.Header) {
+			header.Height = 1
+			header.View = 1
+		})
+		block := unittest.BlockWithParentFixture(parent)
+		block.SetPayload(flow.EmptyPayload())
+
+		replay := func(t *testing.T, chunkEndStates []flow.StateCommitment, events []flow.Event) *blockReplayResult {
+			var result *blockReplayResult
+			unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+				storages := common.InitStorages(db)
+				storeExecutedBlock(t, db, storages, parent, genesisCommit, block, chunkEndStates, events)
+
+				replayer, err := newBlockReplayer(zerolog.Nop(), chain, executionStateDir, complete.DefaultPathFinderVersion, storages, []*flow.Header{block.Header})
+				require.NoError(t, err)
+				defer replayer.Close()
+
+				result, err = replayer.ReplayBlock(block.Header)
+				require.NoError(t, err)
+			})
+			return result
+		}
+
+		// the block is replayed against stored results which don't match
+		mismatch := replay(t, []flow.StateCommitment{unittest.StateCommitmentFixture()}, nil)
+		require.False(t, mismatch.Matches())
+		require.Len(t, mismatch.Chunks, 1) // system chunk
+		require.Equal(t, genesisCommit, mismatch.Chunks[0].StartState)
+		require.Empty(t, mismatch.FailedTransactions)
+		require.Empty(t, mismatch.EventDiff)
+
+		// the block is replayed against the results it produces
+		match := replay(t, []flow.StateCommitment{mismatch.Commit}, nil)
+		require.True(t, match.Matches())
+		require.Equal(t, mismatch.Commit, match.Commit)
+
+		// the replays don't write the trie updates to the WAL, opening the WAL only creates empty segments
+		for name, size := range listFiles(t, executionStateDir) {
+			if expectedSize, ok := files[name]; ok {
+				require.Equal(t, expectedSize, size, name)
+			} else {
+				require.Zero(t, size, name)
+			}
+		}
+	})
+}
+
+// listFiles returns the sizes of the files in the given directory, by name
+func listFiles(t *testing.T, dir string) map[string]int64 {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	files := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		require.NoError(t, err)
+		files[entry.Name()] = info.Size()
+	}
+	return files
+}
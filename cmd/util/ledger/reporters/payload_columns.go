@@ -0,0 +1,115 @@
+package reporters
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/onflow/atree"
+
+	"github.com/onflow/flow-go/ledger"
+)
+
+// PayloadColumns are the decoded columns of a payload, as exported for analytics.
+type PayloadColumns struct {
+	// Owner is the hex-encoded owner of the register, empty for global registers.
+	Owner string
+	// Key is the register key, with the index of slab keys hex-encoded.
+	Key string
+	// ValueSize is the size of the register value in bytes.
+	ValueSize int
+	// PayloadType is one of "fvm", "storage" or "slab".
+	PayloadType string
+	// SlabType is the type of the atree slab stored in the register, for slab payloads only.
+	SlabType string
+}
+
+// DecodePayloadColumns decodes the columns of the given payload.
+func DecodePayloadColumns(p *ledger.Payload) (PayloadColumns, error) {
+	pt, err := getPayloadType(p)
+	if err != nil {
+		return PayloadColumns{}, err
+	}
+	if pt == unknownPayloadType {
+		return PayloadColumns{}, fmt.Errorf("unknown payload: %s", p.String())
+	}
+
+	k, err := p.Key()
+	if err != nil {
+		return PayloadColumns{}, err
+	}
+
+	owner := k.KeyParts[0].Value
+	key := k.KeyParts[1].Value
+
+	columns := PayloadColumns{
+		Owner:       hex.EncodeToString(owner),
+		Key:         string(key),
+		ValueSize:   len(p.Value()),
+		PayloadType: pt.String(),
+	}
+
+	if pt == slabPayloadType {
+		prefix := len(atree.LedgerBaseStorageSlabPrefix)
+		columns.Key = atree.LedgerBaseStorageSlabPrefix + hex.EncodeToString(key[prefix:])
+
+		columns.SlabType, err = slabTypeName(p.Value())
+		if err != nil {
+			return PayloadColumns{}, err
+		}
+	}
+
+	return columns, nil
+}
+
+func (t payloadType) String() string {
+	switch t {
+	case fvmPayloadType:
+		return "fvm"
+	case storagePayloadType:
+		return "storage"
+	case slabPayloadType:
+		return "slab"
+	default:
+		return "unknown"
+	}
+}
+
+// slabTypeName returns the name of the type of the given encoded atree slab.
+func slabTypeName(data []byte) (string, error) {
+	if len(data) < versionAndFlagSize {
+		return "", fmt.Errorf("data is too short for slab")
+	}
+
+	flag := data[flagIndex]
+
+	switch getSlabType(flag) {
+	case slabArray:
+		switch getSlabArrayType(flag) {
+		case slabArrayData:
+			return "array_data", nil
+		case slabArrayMeta:
+			return "array_meta", nil
+		case slabLargeImmutableArray:
+			return "array_large_immutable", nil
+		case slabBasicArray:
+			return "array_basic", nil
+		}
+
+	case slabMap:
+		switch getSlabMapType(flag) {
+		case slabMapData:
+			return "map_data", nil
+		case slabMapMeta:
+			return "map_meta", nil
+		case slabMapLargeEntry:
+			return "map_large_entry", nil
+		case slabMapCollisionGroup:
+			return "map_collision_group", nil
+		}
+
+	case slabStorable:
+		return "storable", nil
+	}
+
+	return "", fmt.Errorf("slab data has invalid flag 0x%x", flag)
+}
@@ -0,0 +1,80 @@
+package reporters_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/cmd/util/ledger/reporters"
+	"github.com/onflow/flow-go/ledger"
+)
+
+func payloadFixture(owner string, key string, value []byte) *ledger.Payload {
+	k := ledger.NewKey([]ledger.KeyPart{
+		ledger.NewKeyPart(0, []byte(owner)),
+		ledger.NewKeyPart(2, []byte(key)),
+	})
+	return ledger.NewPayload(k, value)
+}
+
+func TestDecodePayloadColumns(t *testing.T) {
+	owner := string([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08})
+
+	t.Run("fvm payload", func(t *testing.T) {
+		columns, err := reporters.DecodePayloadColumns(payloadFixture("", "uuid", []byte{1, 2, 3}))
+		require.NoError(t, err)
+		require.Equal(t, reporters.PayloadColumns{
+			Owner:       "",
+			Key:         "uuid",
+			ValueSize:   3,
+			PayloadType: "fvm",
+		}, columns)
+	})
+
+	t.Run("storage payload", func(t *testing.T) {
+		columns, err := reporters.DecodePayloadColumns(payloadFixture(owner, "storage", []byte{1}))
+		require.NoError(t, err)
+		require.Equal(t, reporters.PayloadColumns{
+			Owner:       "0102030405060708",
+			Key:         "storage",
+			ValueSize:   1,
+			PayloadType: "storage",
+		}, columns)
+	})
+
+	t.Run("slab payloads", func(t *testing.T) {
+		key := "$" + string([]byte{0, 0, 0, 0, 0, 0, 0, 0x2a})
+
+		testCases := []struct {
+			flag     byte
+			slabType string
+		}{
+			{flag: 0x80, slabType: "array_data"},
+			{flag: 0x81, slabType: "array_meta"},
+			{flag: 0x88, slabType: "map_data"},
+			{flag: 0x09, slabType: "map_meta"},
+			{flag: 0x0b, slabType: "map_collision_group"},
+			{flag: 0x1f, slabType: "storable"},
+		}
+
+		for _, tc := range testCases {
+			columns, err := reporters.DecodePayloadColumns(payloadFixture(owner, key, []byte{0x00, tc.flag}))
+			require.NoError(t, err)
+			require.Equal(t, reporters.PayloadColumns{
+				Owner:       "0102030405060708",
+				Key:         "$000000000000002a",
+				ValueSize:   2,
+				PayloadType: "slab",
+				SlabType:    tc.slabType,
+			}, columns)
+		}
+	})
+
+	t.Run("invalid slab", func(t *testing.T) {
+		_, err := reporters.DecodePayloadColumns(payloadFixture(owner, "$12345678", []byte{0x00}))
+		require.Error(t, err)
+
+		_, err = reporters.DecodePayloadColumns(payloadFixture(owner, "$12345678", []byte{0x00, 0x10}))
+		require.Error(t, err)
+	})
+}
@@ -21,6 +21,9 @@ import (
 	"github.com/onflow/flow-go/engine/verification/requester"
 	"github.com/onflow/flow-go/engine/verification/verifier"
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/ledger"
+	ledgerhash "github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/partial"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/buffer"
@@ -49,6 +52,9 @@ type VerificationConfig struct {
 
 	blockWorkers uint64 // number of blocks processed in parallel.
 	chunkWorkers uint64 // number of chunks processed in parallel.
+
+	ledgerHasher            string // hasher of the execution state trie the chunk data pack proofs are verified with.
+	ledgerPathFinderVersion uint8  // version of the key to path conversion of the execution state.
 }
 
 type VerificationNodeBuilder struct {
@@ -75,6 +81,10 @@ func (v *VerificationNodeBuilder) LoadFlags() {
 			flags.Uint64Var(&v.verConf.requestTargets, "request-targets", requester.DefaultRequestTargets, "maximum number of execution nodes a chunk data pack request is dispatched to")
 			flags.Uint64Var(&v.verConf.blockWorkers, "block-workers", blockconsumer.DefaultBlockWorkers, "maximum number of blocks being processed in parallel")
 			flags.Uint64Var(&v.verConf.chunkWorkers, "chunk-workers", chunkconsumer.DefaultChunkWorkers, "maximum number of execution nodes a chunk data pack request is dispatched to")
+			flags.StringVar(&v.verConf.ledgerHasher, "ledger-hasher", ledgerhash.SHA3_256.String(), "hasher of the execution state trie (sha3-256 or blake3), "+
+				"must match the hasher of the execution nodes")
+			flags.Uint8Var(&v.verConf.ledgerPathFinderVersion, "ledger-path-finder-version", partial.DefaultPathFinderVersion, "version of the key to path conversion of the execution state, "+
+				"must match the version of the execution nodes (1, or 2 after a spork migrating the state)")
 		})
 }
 
@@ -192,10 +202,21 @@ func (v *VerificationNodeBuilder) LoadComponentsAndModules() {
 		Component("verifier engine", func(node *NodeConfig) (module.ReadyDoneAware, error) {
 			var err error
 
+			hasherType, err := ledgerhash.ParseHasherType(v.verConf.ledgerHasher)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ledger hasher: %w", err)
+			}
+			hasher, err := ledger.NewTrieHasher(hasherType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create ledger hasher: %w", err)
+			}
+
 			rt := fvm.NewInterpreterRuntime()
 			vm := fvm.NewVirtualMachine(rt)
 			vmCtx := fvm.NewContext(node.Logger, node.FvmOptions...)
-			chunkVerifier := chunks.NewChunkVerifier(vm, vmCtx, node.Logger)
+			chunkVerifier := chunks.NewChunkVerifier(vm, vmCtx, node.Logger,
+				chunks.WithTrieHasher(hasher),
+				chunks.WithPathFinderVersion(v.verConf.ledgerPathFinderVersion))
 			approvalStorage := badger.NewResultApprovals(node.Metrics.Cache, node.DB)
 			verifierEng, err = verifier.New(
 				node.Logger,
@@ -0,0 +1,136 @@
+package ingestion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+)
+
+// accountTransactionKey identifies an entry of the account transaction index.
+type accountTransactionKey struct {
+	address flow.Address
+	txID    flow.Identifier
+}
+
+// accountTransactionEntries collects account transaction index entries, merging the roles of
+// entries for the same account and transaction.
+type accountTransactionEntries struct {
+	height  uint64
+	entries map[accountTransactionKey]flow.AccountTransactionRole
+}
+
+func newAccountTransactionEntries(height uint64) *accountTransactionEntries {
+	return &accountTransactionEntries{
+		height:  height,
+		entries: make(map[accountTransactionKey]flow.AccountTransactionRole),
+	}
+}
+
+func (a *accountTransactionEntries) add(address flow.Address, txID flow.Identifier, role flow.AccountTransactionRole) {
+	key := accountTransactionKey{address: address, txID: txID}
+	a.entries[key] |= role
+}
+
+func (a *accountTransactionEntries) list() []flow.AccountTransaction {
+	list := make([]flow.AccountTransaction, 0, len(a.entries))
+	for key, roles := range a.entries {
+		list = append(list, flow.AccountTransaction{
+			Address:       key.address,
+			BlockHeight:   a.height,
+			TransactionID: key.txID,
+			Roles:         roles,
+		})
+	}
+	return list
+}
+
+// AccountTransactionsForTransactions returns the account transaction index entries of the payer,
+// proposer and authorizers of the given transactions, included in the block at the given height.
+func AccountTransactionsForTransactions(height uint64, transactions []*flow.TransactionBody) []flow.AccountTransaction {
+	entries := newAccountTransactionEntries(height)
+
+	for _, tx := range transactions {
+		txID := tx.ID()
+		entries.add(tx.Payer, txID, flow.AccountTransactionRolePayer)
+		entries.add(tx.ProposalKey.Address, txID, flow.AccountTransactionRoleProposer)
+		for _, authorizer := range tx.Authorizers {
+			entries.add(authorizer, txID, flow.AccountTransactionRoleAuthorizer)
+		}
+	}
+
+	return entries.list()
+}
+
+// AccountTransactionsForEvents returns the account transaction index entries of the accounts that
+// emitted the given events, emitted in the block at the given height. Events are emitted by the
+// account of the contract that defines the event type, protocol events are not attributed to any
+// account.
+func AccountTransactionsForEvents(height uint64, events []flow.Event) []flow.AccountTransaction {
+	entries := newAccountTransactionEntries(height)
+
+	for _, event := range events {
+		address, ok := eventTypeAddress(event.Type)
+		if !ok {
+			continue
+		}
+		entries.add(address, event.TransactionID, flow.AccountTransactionRoleEventEmitter)
+	}
+
+	return entries.list()
+}
+
+// eventTypeAddress returns the address of the contract that defines the event type, if the type is
+// a contract event type (A.<address>.<contract>.<event>).
+func eventTypeAddress(eventType flow.EventType) (flow.Address, bool) {
+	parts := strings.Split(string(eventType), ".")
+	if len(parts) < 4 || parts[0] != "A" || len(parts[1]) != 2*flow.AddressLength {
+		return flow.EmptyAddress, false
+	}
+	return flow.HexToAddress(parts[1]), true
+}
+
+// indexAccountTransactions indexes the payer, proposer and authorizers of the transactions in the
+// collection.
+func (e *Engine) indexAccountTransactions(collection *flow.Collection) error {
+	block, err := e.blocks.ByCollectionID(collection.ID())
+	if err != nil {
+		return fmt.Errorf("could not get block for collection: %w", err)
+	}
+
+	entries := AccountTransactionsForTransactions(block.Header.Height, collection.Transactions)
+	err = e.accountTransactions.Store(entries)
+	if err != nil {
+		return fmt.Errorf("could not index account transactions: %w", err)
+	}
+
+	return nil
+}
+
+// OnExecutionData indexes the accounts that emitted events in the transactions of the block. It is
+// registered as a consumer of the ExecutionDataRequester if the account transaction index is
+// enabled. Errors are returned so that the ExecutionDataRequester retries the block, indexing the
+// same events again is a no-op.
+func (e *Engine) OnExecutionData(executionData *execution_data.BlockExecutionData) error {
+	if e.accountTransactions == nil {
+		return nil
+	}
+
+	header, err := e.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		return fmt.Errorf("could not get header for execution data of block %s: %w", executionData.BlockID, err)
+	}
+
+	var events []flow.Event
+	for _, chunkExecutionData := range executionData.ChunkExecutionDatas {
+		events = append(events, chunkExecutionData.Events...)
+	}
+
+	err = e.accountTransactions.Store(AccountTransactionsForEvents(header.Height, events))
+	if err != nil {
+		return fmt.Errorf("could not index event emitters of block %s: %w", executionData.BlockID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestAccountTransactionsForEvents(t *testing.T) {
+	address := unittest.RandomAddressFixture()
+	txID := unittest.IdentifierFixture()
+
+	events := []flow.Event{
+		unittest.EventFixture(flow.EventType("A."+address.Hex()+".Contract.Deposited"), 0, 0, txID, 0),
+		unittest.EventFixture(flow.EventType("A."+address.Hex()+".Contract.Withdrawn"), 0, 1, txID, 0),
+		unittest.EventFixture(flow.EventAccountCreated, 0, 2, txID, 0),
+		unittest.EventFixture(flow.EventType("A.invalid.Contract.Event"), 0, 3, txID, 0),
+	}
+
+	entries := AccountTransactionsForEvents(10, events)
+	require.Len(t, entries, 1)
+	assert.Equal(t, flow.AccountTransaction{
+		Address:       address,
+		BlockHeight:   10,
+		TransactionID: txID,
+		Roles:         flow.AccountTransactionRoleEventEmitter,
+	}, entries[0])
+}
+
+func TestAccountTransactionsForTransactions(t *testing.T) {
+	tx := unittest.TransactionBodyFixture()
+	tx.Payer = tx.ProposalKey.Address
+	tx.Authorizers = []flow.Address{tx.ProposalKey.Address, unittest.RandomAddressFixture()}
+
+	entries := AccountTransactionsForTransactions(10, []*flow.TransactionBody{&tx})
+	require.Len(t, entries, 2)
+
+	roles := make(map[flow.Address]flow.AccountTransactionRole)
+	for _, entry := range entries {
+		assert.Equal(t, tx.ID(), entry.TransactionID)
+		assert.Equal(t, uint64(10), entry.BlockHeight)
+		roles[entry.Address] = entry.Roles
+	}
+
+	assert.Equal(t, flow.AccountTransactionRolePayer|flow.AccountTransactionRoleProposer|flow.AccountTransactionRoleAuthorizer, roles[tx.Payer])
+	assert.Equal(t, flow.AccountTransactionRoleAuthorizer, roles[tx.Authorizers[1]])
+}
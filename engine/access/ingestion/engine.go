@@ -77,6 +77,9 @@ type Engine struct {
 	executionReceipts storage.ExecutionReceipts
 	executionResults  storage.ExecutionResults
 
+	// optional index of the transactions of each account, not maintained if nil
+	accountTransactions storage.AccountTransactions
+
 	// metrics
 	transactionMetrics         module.TransactionMetrics
 	collectionsToMarkFinalized *stdmap.Times
@@ -104,6 +107,7 @@ func New(
 	collectionsToMarkExecuted *stdmap.Times,
 	blocksToMarkExecuted *stdmap.Times,
 	rpcEngine *rpc.Engine,
+	accountTransactions storage.AccountTransactions,
 ) (*Engine, error) {
 	executionReceiptsRawQueue, err := fifoqueue.NewFifoQueue(
 		fifoqueue.WithCapacity(defaultQueueCapacity),
@@ -154,6 +158,7 @@ func New(
 		transactions:               transactions,
 		executionResults:           executionResults,
 		executionReceipts:          executionReceipts,
+		accountTransactions:        accountTransactions,
 		transactionMetrics:         transactionMetrics,
 		collectionsToMarkFinalized: collectionsToMarkFinalized,
 		collectionsToMarkExecuted:  collectionsToMarkExecuted,
@@ -500,6 +505,16 @@ func (e *Engine) handleCollection(originID flow.Identifier, entity flow.Entity)
 	// FIX: we can't index guarantees here, as we might have more than one block
 	// with the same collection as long as it is not finalized
 
+	// index the accounts of the transactions before storing the collection, so that the index is
+	// written again when the collection is received again after a failure. Collections which are
+	// not stored are re-requested, and indexing the same transactions again is a no-op.
+	if e.accountTransactions != nil {
+		err := e.indexAccountTransactions(collection)
+		if err != nil {
+			return fmt.Errorf("could not index account transactions for collection (%x): %w", light.ID(), err)
+		}
+	}
+
 	// store the light collection (collection minus the transaction body - those are stored separately)
 	// and add transaction ids as index
 	err := e.collections.StoreLightAndIndexByTransaction(&light)
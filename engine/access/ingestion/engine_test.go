@@ -19,6 +19,7 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	"github.com/onflow/flow-go/module/irrecoverable"
 	"github.com/onflow/flow-go/module/mempool/stdmap"
 	"github.com/onflow/flow-go/module/metrics"
@@ -102,14 +103,14 @@ func (suite *Suite) SetupTest() {
 	require.NoError(suite.T(), err)
 
 	rpcEngBuilder, err := rpc.NewBuilder(log, suite.proto.state, rpc.Config{}, nil, nil, suite.blocks, suite.headers, suite.collections,
-		suite.transactions, suite.receipts, suite.results, flow.Testnet, metrics.NewNoopCollector(), metrics.NewNoopCollector(), 0, 0, false, false, nil, nil)
+		suite.transactions, suite.receipts, suite.results, flow.Testnet, metrics.NewNoopCollector(), metrics.NewNoopCollector(), 0, 0, false, false, nil, nil, nil, nil, nil)
 	rpcEngBuilder.WithLegacy()
 	rpcEng := rpcEngBuilder.Build()
 	require.NoError(suite.T(), err)
 
 	eng, err := New(log, net, suite.proto.state, suite.me, suite.request, suite.blocks, suite.headers, suite.collections,
 		suite.transactions, suite.results, suite.receipts, metrics.NewNoopCollector(), collectionsToMarkFinalized, collectionsToMarkExecuted,
-		blocksToMarkExecuted, rpcEng)
+		blocksToMarkExecuted, rpcEng, nil)
 	require.NoError(suite.T(), err)
 
 	// stops requestMissingCollections from executing in processBackground worker
@@ -232,6 +233,104 @@ func (suite *Suite) TestOnCollection() {
 	suite.transactions.AssertNumberOfCalls(suite.T(), "Store", len(collection.Transactions))
 }
 
+// TestOnCollectionIndexesAccountTransactions checks that the payer, proposer and authorizers of the
+// transactions in a collection are indexed when the account transaction index is enabled
+func (suite *Suite) TestOnCollectionIndexesAccountTransactions() {
+	accountTransactions := storage.NewAccountTransactions(suite.T())
+	suite.eng.accountTransactions = accountTransactions
+
+	originID := unittest.IdentifierFixture()
+	collection := unittest.CollectionFixture(2)
+	light := collection.Light()
+
+	block := unittest.BlockFixture()
+	suite.blocks.On("ByCollectionID", light.ID()).Return(&block, nil).Once()
+	suite.collections.On("StoreLightAndIndexByTransaction", &light).Return(nil).Once()
+	suite.transactions.On("Store", mock.Anything).Return(nil)
+
+	accountTransactions.On("Store", mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			entries := args.Get(0).([]flow.AccountTransaction)
+
+			roles := make(map[flow.Identifier]map[flow.Address]flow.AccountTransactionRole)
+			for _, entry := range entries {
+				suite.Assert().Equal(block.Header.Height, entry.BlockHeight)
+				if roles[entry.TransactionID] == nil {
+					roles[entry.TransactionID] = make(map[flow.Address]flow.AccountTransactionRole)
+				}
+				roles[entry.TransactionID][entry.Address] = entry.Roles
+			}
+
+			for _, tx := range collection.Transactions {
+				txRoles := roles[tx.ID()]
+				suite.Assert().True(txRoles[tx.Payer].Has(flow.AccountTransactionRolePayer))
+				suite.Assert().True(txRoles[tx.ProposalKey.Address].Has(flow.AccountTransactionRoleProposer))
+				for _, authorizer := range tx.Authorizers {
+					suite.Assert().True(txRoles[authorizer].Has(flow.AccountTransactionRoleAuthorizer))
+				}
+			}
+		},
+	).Once()
+
+	suite.eng.OnCollection(originID, &collection)
+
+	suite.collections.AssertExpectations(suite.T())
+	suite.blocks.AssertExpectations(suite.T())
+}
+
+// TestOnCollectionRetriesAccountTransactions checks that a collection is not stored if its account
+// transactions could not be indexed, so the index is written when the collection is received again
+func (suite *Suite) TestOnCollectionRetriesAccountTransactions() {
+	accountTransactions := storage.NewAccountTransactions(suite.T())
+	suite.eng.accountTransactions = accountTransactions
+
+	originID := unittest.IdentifierFixture()
+	collection := unittest.CollectionFixture(2)
+	light := collection.Light()
+
+	block := unittest.BlockFixture()
+	suite.blocks.On("ByCollectionID", light.ID()).Return(&block, nil).Twice()
+	accountTransactions.On("Store", mock.Anything).Return(errors.New("index error")).Once()
+
+	suite.eng.OnCollection(originID, &collection)
+	suite.collections.AssertNotCalled(suite.T(), "StoreLightAndIndexByTransaction", mock.Anything)
+
+	accountTransactions.On("Store", mock.Anything).Return(nil).Once()
+	suite.collections.On("StoreLightAndIndexByTransaction", &light).Return(nil).Once()
+	suite.transactions.On("Store", mock.Anything).Return(nil)
+
+	suite.eng.OnCollection(originID, &collection)
+
+	suite.collections.AssertExpectations(suite.T())
+	suite.transactions.AssertNumberOfCalls(suite.T(), "Store", len(collection.Transactions))
+}
+
+// TestOnExecutionDataReturnsIndexError checks that failures to index the event emitters of a block
+// are returned, so the execution data is delivered again
+func (suite *Suite) TestOnExecutionDataReturnsIndexError() {
+	accountTransactions := storage.NewAccountTransactions(suite.T())
+	suite.eng.accountTransactions = accountTransactions
+
+	block := unittest.BlockFixture()
+	executionData := &execution_data.BlockExecutionData{
+		BlockID: block.ID(),
+		ChunkExecutionDatas: []*execution_data.ChunkExecutionData{
+			{Events: unittest.BlockEventsFixture(block.Header, 2).Events},
+		},
+	}
+
+	suite.headers.On("ByBlockID", block.ID()).Return(block.Header, nil)
+	accountTransactions.On("Store", mock.Anything).Return(errors.New("index error")).Once()
+
+	err := suite.eng.OnExecutionData(executionData)
+	suite.Require().Error(err)
+
+	accountTransactions.On("Store", mock.Anything).Return(nil).Once()
+
+	err = suite.eng.OnExecutionData(executionData)
+	suite.Require().NoError(err)
+}
+
 // TestExecutionReceiptsAreIndexed checks that execution receipts are properly indexed
 func (suite *Suite) TestExecutionReceiptsAreIndexed() {
 
@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+)
+
+// GetAccountTransactions handler retrieves a page of the transactions an account was involved in,
+// ordered by block height.
+func GetAccountTransactions(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetAccountTransactionsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	// if end height is provided with special values then load the height
+	if req.EndHeight == request.FinalHeight || req.EndHeight == request.SealedHeight {
+		latest, err := backend.GetLatestBlockHeader(r.Context(), req.EndHeight == request.SealedHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		req.EndHeight = latest.Height
+		// special check after we resolve special height value
+		if req.StartHeight > req.EndHeight {
+			return nil, NewBadRequestError(fmt.Errorf("current retrieved end height value is lower than start height"))
+		}
+	}
+
+	result, err := backend.GetTransactionsByAccount(r.Context(), req.Address, req.StartHeight, req.EndHeight, req.Cursor, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.AccountTransactions
+	err = response.Build(result, link)
+	return response, err
+}
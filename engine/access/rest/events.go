@@ -45,6 +45,18 @@ func GetEvents(r *request.Request, backend access.API, _ models.LinkGenerator) (
 		}
 	}
 
+	// if a cursor or limit is provided then return a page of the blocks containing events of the type
+	if req.Paginated {
+		page, err := backend.GetEventsByType(r.Context(), req.Type, req.StartHeight, req.EndHeight, req.Cursor, req.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		var response models.BlockEventsPage
+		response.Build(page)
+		return response, nil
+	}
+
 	// if request provided block height range then return events for that range
 	events, err := backend.GetEventsForHeightRange(r.Context(), req.Type, req.StartHeight, req.EndHeight)
 	if err != nil {
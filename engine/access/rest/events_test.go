@@ -14,6 +14,7 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
 
+	"github.com/onflow/flow-go/access"
 	mocks "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
@@ -116,6 +117,58 @@ func TestGetEvents(t *testing.T) {
 
 }
 
+func TestGetEventsPaginated(t *testing.T) {
+	backend := &mock.API{}
+	events := make([]flow.BlockEvents, 2)
+	for i := range events {
+		header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(uint64(10 * i)))
+		events[i] = unittest.BlockEventsFixture(header, 2)
+	}
+	last := events[len(events)-1]
+	eventType := "A.179b6b1cb6755e31.Foo.Bar"
+
+	backend.Mock.
+		On("GetEventsByType", mocks.Anything, eventType, uint64(0), uint64(500), (*access.EventsCursor)(nil), uint(2)).
+		Return(&access.EventsPage{
+			Events:     events,
+			NextCursor: &access.EventsCursor{BlockHeight: last.BlockHeight, BlockID: last.BlockID},
+		}, nil)
+
+	cursor := &access.EventsCursor{BlockHeight: last.BlockHeight, BlockID: last.BlockID}
+	backend.Mock.
+		On("GetEventsByType", mocks.Anything, eventType, uint64(0), uint64(500), cursor, uint(2)).
+		Return(&access.EventsPage{Events: []flow.BlockEvents{}}, nil)
+
+	t.Run("first page", func(t *testing.T) {
+		req := getPaginatedEventReq(t, eventType, "0", "500", "", "2")
+		expected := fmt.Sprintf(`{"block_events": %s, "next_cursor": "%s"}`,
+			testBlockEventResponse(events),
+			util.EncodeCursor(last.BlockHeight, last.BlockID),
+		)
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		req := getPaginatedEventReq(t, eventType, "0", "500", util.EncodeCursor(last.BlockHeight, last.BlockID), "2")
+		assertOKResponse(t, req, `{"block_events": []}`, backend)
+	})
+}
+
+func getPaginatedEventReq(t *testing.T, eventType string, start string, end string, cursor string, limit string) *http.Request {
+	req := getEventReq(t, eventType, start, end, nil)
+
+	q := req.URL.Query()
+	if cursor != "" {
+		q.Add("cursor", cursor)
+	}
+	if limit != "" {
+		q.Add("limit", limit)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req
+}
+
 func getEventReq(t *testing.T, eventType string, start string, end string, blockIDs []string) *http.Request {
 	u, _ := url.Parse("/v1/events")
 	q := u.Query()
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func (a *AccountTransaction) Build(entry flow.AccountTransaction, link LinkGenerator) error {
+	roles := entry.Roles.Roles()
+	a.Roles = make([]string, len(roles))
+	for i, role := range roles {
+		a.Roles[i] = role.String()
+	}
+
+	a.TransactionId = entry.TransactionID.String()
+	a.BlockHeight = util.FromUint64(entry.BlockHeight)
+
+	self, err := SelfLink(entry.TransactionID, link.TransactionLink)
+	if err != nil {
+		return err
+	}
+	a.Links = self
+
+	return nil
+}
+
+func (a *AccountTransactions) Build(result *access.AccountTransactions, link LinkGenerator) error {
+	txs := make([]AccountTransaction, len(result.Transactions))
+	for i, entry := range result.Transactions {
+		err := txs[i].Build(entry, link)
+		if err != nil {
+			return err
+		}
+	}
+	a.Transactions = txs
+
+	if result.NextCursor != nil {
+		a.NextCursor = util.EncodeCursor(result.NextCursor.BlockHeight, result.NextCursor.TransactionID)
+	}
+
+	return nil
+}
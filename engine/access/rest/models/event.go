@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/access/rest/util"
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -53,3 +54,13 @@ func (b *BlocksEvents) Build(blocksEvents []flow.BlockEvents) {
 
 	*b = evs
 }
+
+func (b *BlockEventsPage) Build(page *access.EventsPage) {
+	var blocksEvents BlocksEvents
+	blocksEvents.Build(page.Events)
+	b.BlockEvents = blocksEvents
+
+	if page.NextCursor != nil {
+		b.NextCursor = util.EncodeCursor(page.NextCursor.BlockHeight, page.NextCursor.BlockID)
+	}
+}
@@ -0,0 +1,18 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type RegisterEntry struct {
+	// Hex encoded owner of the register, empty for global registers.
+	Owner string `json:"owner"`
+	// Base64 encoded key of the register.
+	Key string `json:"key"`
+	// Base64 encoded value of the register, empty if the register is removed.
+	Value string `json:"value"`
+}
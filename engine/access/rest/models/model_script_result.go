@@ -0,0 +1,16 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type ScriptResult struct {
+	// Base64 encoded JSON-Cadence value returned by the script, if it succeeded.
+	Value string `json:"value,omitempty"`
+	// Error message of the script, if it failed.
+	Error string `json:"error,omitempty"`
+}
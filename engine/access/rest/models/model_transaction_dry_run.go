@@ -0,0 +1,26 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type TransactionDryRun struct {
+	BlockId         string `json:"block_id"`
+	BlockHeight     string `json:"block_height"`
+	ComputationUsed string `json:"computation_used"`
+	MemoryEstimate  string `json:"memory_estimate"`
+	// Computation used by each computation kind, keyed by the numeric computation kind.
+	ComputationBreakdown map[string]string `json:"computation_breakdown"`
+	// Memory estimate of each memory kind, keyed by the numeric memory kind.
+	MemoryBreakdown map[string]string `json:"memory_breakdown"`
+	// Fees the payer would be charged, as a decimal UFix64 value.
+	Fees         string          `json:"fees"`
+	Events       []Event         `json:"events"`
+	StorageDelta []RegisterEntry `json:"storage_delta"`
+	// Error the transaction would fail with, empty if the transaction would succeed.
+	ErrorMessage string `json:"error_message"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/util"
+)
+
+func (s *ScriptResult) Build(result access.ScriptResult) {
+	if result.Err != nil {
+		s.Error = status.Convert(result.Err).Message()
+		return
+	}
+
+	s.Value = util.ToBase64(result.Value)
+}
+
+type ScriptResults []ScriptResult
+
+func (s *ScriptResults) Build(results []access.ScriptResult) {
+	scriptResults := make([]ScriptResult, len(results))
+	for i, result := range results {
+		scriptResults[i].Build(result)
+	}
+
+	*s = scriptResults
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/hex"
+	"strconv"
+
+	"github.com/onflow/cadence"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func (t *TransactionDryRun) Build(result *access.TransactionDryRunResult) {
+	t.BlockId = result.BlockID.String()
+	t.BlockHeight = util.FromUint64(result.BlockHeight)
+	t.ComputationUsed = util.FromUint64(result.ComputationUsed)
+	t.MemoryEstimate = util.FromUint64(result.MemoryEstimate)
+	t.Fees = cadence.UFix64(result.Fees).String()
+	t.ErrorMessage = result.ErrorMessage
+
+	t.ComputationBreakdown = make(map[string]string, len(result.ComputationBreakdown))
+	for kind, used := range result.ComputationBreakdown {
+		t.ComputationBreakdown[strconv.FormatUint(uint64(kind), 10)] = util.FromUint64(used)
+	}
+
+	t.MemoryBreakdown = make(map[string]string, len(result.MemoryBreakdown))
+	for kind, estimate := range result.MemoryBreakdown {
+		t.MemoryBreakdown[strconv.FormatUint(uint64(kind), 10)] = util.FromUint64(estimate)
+	}
+
+	var events Events
+	events.Build(result.Events)
+	t.Events = events
+
+	t.StorageDelta = make([]RegisterEntry, len(result.StorageDelta))
+	for i, entry := range result.StorageDelta {
+		t.StorageDelta[i].Build(entry)
+	}
+}
+
+func (r *RegisterEntry) Build(entry flow.RegisterEntry) {
+	r.Owner = hex.EncodeToString([]byte(entry.Key.Owner))
+	r.Key = util.ToBase64([]byte(entry.Key.Key))
+	r.Value = util.ToBase64(entry.Value)
+}
@@ -0,0 +1,49 @@
+package request
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const skipSignatureVerificationQuery = "skip_signature_verification"
+
+type DryRunTransaction struct {
+	Transaction               flow.TransactionBody
+	SkipSignatureVerification bool
+}
+
+func (d *DryRunTransaction) Build(r *Request) error {
+	return d.Parse(
+		r.GetQueryParam(skipSignatureVerificationQuery),
+		r.Body,
+		r.Chain,
+	)
+}
+
+func (d *DryRunTransaction) Parse(rawSkipSignatureVerification string, rawTransaction io.Reader, chain flow.Chain) error {
+	if rawSkipSignatureVerification != "" {
+		skip, err := strconv.ParseBool(rawSkipSignatureVerification)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s", skipSignatureVerificationQuery)
+		}
+		d.SkipSignatureVerification = skip
+	}
+
+	// the transaction only needs to be signed if the signatures are verified
+	var tx Transaction
+	var err error
+	if d.SkipSignatureVerification {
+		err = tx.ParseUnsigned(rawTransaction, chain)
+	} else {
+		err = tx.Parse(rawTransaction, chain)
+	}
+	if err != nil {
+		return err
+	}
+
+	d.Transaction = tx.Flow()
+	return nil
+}
@@ -0,0 +1,39 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func TestDryRunTransaction_Parse(t *testing.T) {
+	t.Run("signatures are required by default", func(t *testing.T) {
+		tx := buildTransaction()
+		delete(tx, "envelope_signatures")
+
+		var dryRun DryRunTransaction
+		err := dryRun.Parse("", transactionToReader(tx), flow.Testnet.Chain())
+		assert.EqualError(t, err, "envelope signatures not provided")
+	})
+
+	t.Run("signatures are optional if not verified", func(t *testing.T) {
+		tx := buildTransaction()
+		delete(tx, "envelope_signatures")
+
+		var dryRun DryRunTransaction
+		err := dryRun.Parse("true", transactionToReader(tx), flow.Testnet.Chain())
+		require.NoError(t, err)
+		assert.True(t, dryRun.SkipSignatureVerification)
+		assert.Equal(t, tx["payer"], dryRun.Transaction.Payer.String())
+		assert.Empty(t, dryRun.Transaction.EnvelopeSignatures)
+	})
+
+	t.Run("invalid skip signature verification value", func(t *testing.T) {
+		var dryRun DryRunTransaction
+		err := dryRun.Parse("maybe", transactionToReader(buildTransaction()), flow.Testnet.Chain())
+		assert.EqualError(t, err, "invalid value for skip_signature_verification")
+	})
+}
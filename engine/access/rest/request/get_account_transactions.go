@@ -0,0 +1,86 @@
+package request
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const cursorQuery = "cursor"
+const limitQuery = "limit"
+
+type GetAccountTransactions struct {
+	Address     flow.Address
+	StartHeight uint64
+	EndHeight   uint64
+	Cursor      *flow.AccountTransactionCursor
+	Limit       uint
+}
+
+func (g *GetAccountTransactions) Build(r *Request) error {
+	return g.Parse(
+		r.GetVar(addressVar),
+		r.GetQueryParam(startHeightQuery),
+		r.GetQueryParam(endHeightQuery),
+		r.GetQueryParam(cursorQuery),
+		r.GetQueryParam(limitQuery),
+	)
+}
+
+func (g *GetAccountTransactions) Parse(rawAddress string, rawStart string, rawEnd string, rawCursor string, rawLimit string) error {
+	var address Address
+	err := address.Parse(rawAddress)
+	if err != nil {
+		return err
+	}
+	g.Address = address.Flow()
+
+	var height Height
+	err = height.Parse(rawStart)
+	if err != nil {
+		return fmt.Errorf("invalid start height: %w", err)
+	}
+	g.StartHeight = height.Flow()
+	err = height.Parse(rawEnd)
+	if err != nil {
+		return fmt.Errorf("invalid end height: %w", err)
+	}
+	g.EndHeight = height.Flow()
+
+	// default to the full indexed range
+	if g.StartHeight == EmptyHeight {
+		g.StartHeight = 0
+	}
+	if g.EndHeight == EmptyHeight {
+		g.EndHeight = FinalHeight
+	}
+
+	if g.StartHeight == FinalHeight || g.StartHeight == SealedHeight {
+		return fmt.Errorf("start height must be a block height")
+	}
+
+	if g.EndHeight != FinalHeight && g.EndHeight != SealedHeight && g.StartHeight > g.EndHeight {
+		return fmt.Errorf("start height must be less than or equal to end height")
+	}
+
+	if rawCursor != "" {
+		cursorHeight, cursorID, err := util.DecodeCursor(rawCursor)
+		if err != nil {
+			return err
+		}
+		g.Cursor = &flow.AccountTransactionCursor{
+			BlockHeight:   cursorHeight,
+			TransactionID: cursorID,
+		}
+	}
+
+	var limit Limit
+	err = limit.Parse(rawLimit)
+	if err != nil {
+		return err
+	}
+	g.Limit = limit.Flow()
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package request
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func Test_GetAccountTransactions_InvalidParse(t *testing.T) {
+	var getAccountTransactions GetAccountTransactions
+
+	tests := []struct {
+		address string
+		start   string
+		end     string
+		cursor  string
+		limit   string
+		err     string
+	}{
+		{"", "", "", "", "", "invalid address"},
+		{"f8d6e0586b0a20c7", "-1", "", "", "", "invalid start height: invalid height format"},
+		{"f8d6e0586b0a20c7", "sealed", "", "", "", "start height must be a block height"},
+		{"f8d6e0586b0a20c7", "10", "5", "", "", "start height must be less than or equal to end height"},
+		{"f8d6e0586b0a20c7", "", "", "foo", "", "invalid cursor"},
+		{"f8d6e0586b0a20c7", "", "", "", "-1", "invalid limit"},
+	}
+
+	for i, test := range tests {
+		err := getAccountTransactions.Parse(test.address, test.start, test.end, test.cursor, test.limit)
+		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
+	}
+}
+
+func Test_GetAccountTransactions_ValidParse(t *testing.T) {
+	var getAccountTransactions GetAccountTransactions
+
+	addr := "f8d6e0586b0a20c7"
+	err := getAccountTransactions.Parse(addr, "", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, addr, getAccountTransactions.Address.String())
+	assert.Equal(t, uint64(0), getAccountTransactions.StartHeight)
+	assert.Equal(t, FinalHeight, getAccountTransactions.EndHeight)
+	assert.Nil(t, getAccountTransactions.Cursor)
+	assert.Equal(t, uint(0), getAccountTransactions.Limit)
+
+	txID := unittest.IdentifierFixture()
+	err = getAccountTransactions.Parse(addr, "5", "sealed", util.EncodeCursor(7, txID), "20")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), getAccountTransactions.StartHeight)
+	assert.Equal(t, SealedHeight, getAccountTransactions.EndHeight)
+	require.NotNil(t, getAccountTransactions.Cursor)
+	assert.Equal(t, uint64(7), getAccountTransactions.Cursor.BlockHeight)
+	assert.Equal(t, txID, getAccountTransactions.Cursor.TransactionID)
+	assert.Equal(t, uint(20), getAccountTransactions.Limit)
+}
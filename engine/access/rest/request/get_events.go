@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/util"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -16,6 +18,11 @@ type GetEvents struct {
 	EndHeight   uint64
 	Type        string
 	BlockIDs    []flow.Identifier
+	// Paginated is true if a cursor or limit was provided, in which case a page of the blocks
+	// containing events of the type is requested
+	Paginated bool
+	Cursor    *access.EventsCursor
+	Limit     uint
 }
 
 func (g *GetEvents) Build(r *Request) error {
@@ -24,10 +31,12 @@ func (g *GetEvents) Build(r *Request) error {
 		r.GetQueryParam(startHeightQuery),
 		r.GetQueryParam(endHeightQuery),
 		r.GetQueryParams(blockQuery),
+		r.GetQueryParam(cursorQuery),
+		r.GetQueryParam(limitQuery),
 	)
 }
 
-func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlockIDs []string) error {
+func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlockIDs []string, rawCursor string, rawLimit string) error {
 	var height Height
 	err := height.Parse(rawStart)
 	if err != nil {
@@ -71,13 +80,38 @@ func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlo
 		return fmt.Errorf("invalid event type format")
 	}
 
+	g.Cursor = nil
+	if rawCursor != "" {
+		cursorHeight, cursorID, err := util.DecodeCursor(rawCursor)
+		if err != nil {
+			return err
+		}
+		g.Cursor = &access.EventsCursor{
+			BlockHeight: cursorHeight,
+			BlockID:     cursorID,
+		}
+	}
+
+	var limit Limit
+	err = limit.Parse(rawLimit)
+	if err != nil {
+		return err
+	}
+	g.Limit = limit.Flow()
+
+	g.Paginated = rawCursor != "" || rawLimit != ""
+	if g.Paginated && len(blockIDs) > 0 {
+		return fmt.Errorf("cursor and limit can only be provided with a start and end height range")
+	}
+
 	// validate start end height option
 	if g.StartHeight != EmptyHeight && g.EndHeight != EmptyHeight {
 		if g.StartHeight > g.EndHeight {
 			return fmt.Errorf("start height must be less than or equal to end height")
 		}
-		// check if range exceeds maximum but only if end is not equal to special value which is not known yet
-		if g.EndHeight-g.StartHeight >= MaxEventRequestHeightRange && g.EndHeight != FinalHeight && g.EndHeight != SealedHeight {
+		// check if range exceeds maximum but only if end is not equal to special value which is not known yet.
+		// paginated requests are not limited, since the size of each page is
+		if !g.Paginated && g.EndHeight-g.StartHeight >= MaxEventRequestHeightRange && g.EndHeight != FinalHeight && g.EndHeight != SealedHeight {
 			return fmt.Errorf("height range %d exceeds maximum allowed of %d", g.EndHeight-g.StartHeight, MaxEventRequestHeightRange)
 		}
 	}
@@ -5,6 +5,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/utils/unittest"
 )
 
 func TestGetEvents_InvalidParse(t *testing.T) {
@@ -29,7 +33,7 @@ func TestGetEvents_InvalidParse(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		err := getEvents.Parse(test.eventType, test.start, test.end, test.ids)
+		err := getEvents.Parse(test.eventType, test.start, test.end, test.ids, "", "")
 		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
 	}
 }
@@ -38,7 +42,7 @@ func TestGetEvents_ValidParse(t *testing.T) {
 	var getEvents GetEvents
 
 	event := "A.f8d6e0586b0a20c7.Foo.Bar"
-	err := getEvents.Parse(event, "5", "10", nil)
+	err := getEvents.Parse(event, "5", "10", nil, "", "")
 	assert.NoError(t, err)
 	assert.Equal(t, getEvents.Type, event)
 	assert.Equal(t, getEvents.StartHeight, uint64(5))
@@ -50,7 +54,7 @@ func TestGetEvents_ValidParse(t *testing.T) {
 		"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7",
 		"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7", // intentional duplication
 		"2ab81061b12d95fb81f2923001e340bc808e67e1eaae3c62479057cc14eb57fd",
-	})
+	}, "", "")
 	assert.NoError(t, err)
 	assert.Equal(t, getEvents.Type, event)
 	assert.Equal(t, getEvents.StartHeight, EmptyHeight)
@@ -58,5 +62,33 @@ func TestGetEvents_ValidParse(t *testing.T) {
 	assert.Equal(t, len(getEvents.BlockIDs), 2)
 	assert.Equal(t, getEvents.BlockIDs[0].String(), "7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7")
 	assert.Equal(t, getEvents.BlockIDs[1].String(), "2ab81061b12d95fb81f2923001e340bc808e67e1eaae3c62479057cc14eb57fd")
+	assert.False(t, getEvents.Paginated)
+
+}
+
+func TestGetEvents_PaginatedParse(t *testing.T) {
+	var getEvents GetEvents
+
+	event := "A.f8d6e0586b0a20c7.Foo.Bar"
+
+	// paginated requests are not limited to the maximum height range
+	err := getEvents.Parse(event, "0", "500", nil, "", "20")
+	require.NoError(t, err)
+	assert.True(t, getEvents.Paginated)
+	assert.Equal(t, uint(20), getEvents.Limit)
+	assert.Nil(t, getEvents.Cursor)
+
+	blockID := unittest.IdentifierFixture()
+	err = getEvents.Parse(event, "0", "sealed", nil, util.EncodeCursor(7, blockID), "")
+	require.NoError(t, err)
+	assert.True(t, getEvents.Paginated)
+	require.NotNil(t, getEvents.Cursor)
+	assert.Equal(t, uint64(7), getEvents.Cursor.BlockHeight)
+	assert.Equal(t, blockID, getEvents.Cursor.BlockID)
+
+	err = getEvents.Parse(event, "", "", []string{"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7"}, "", "20")
+	assert.EqualError(t, err, "cursor and limit can only be provided with a start and end height range")
 
+	err = getEvents.Parse(event, "0", "10", nil, "foo", "")
+	assert.EqualError(t, err, "invalid cursor")
 }
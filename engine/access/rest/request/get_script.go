@@ -9,10 +9,18 @@ import (
 
 const blockIDQuery = "block_id"
 
+// scriptsBody is the body of a script request, which contains either a single script or a batch of scripts.
+type scriptsBody struct {
+	scriptBody
+	Scripts []scriptBody `json:"scripts,omitempty"`
+}
+
 type GetScript struct {
 	BlockID     flow.Identifier
 	BlockHeight uint64
 	Script      Script
+	// Scripts is set instead of Script if a batch of scripts is requested
+	Scripts []Script
 }
 
 func (g *GetScript) Build(r *Request) error {
@@ -38,12 +46,32 @@ func (g *GetScript) Parse(rawHeight string, rawID string, rawScript io.Reader) e
 	}
 	g.BlockID = id.Flow()
 
-	var script Script
-	err = script.Parse(rawScript)
+	var body scriptsBody
+	err = parseBody(rawScript, &body)
 	if err != nil {
 		return err
 	}
-	g.Script = script
+
+	g.Script = Script{}
+	g.Scripts = nil
+	if len(body.Scripts) > 0 {
+		if body.Script != "" || len(body.Arguments) > 0 {
+			return fmt.Errorf("can not provide both a script and a batch of scripts")
+		}
+
+		g.Scripts = make([]Script, len(body.Scripts))
+		for i, scriptBody := range body.Scripts {
+			err = g.Scripts[i].parse(scriptBody)
+			if err != nil {
+				return fmt.Errorf("invalid script at index %d: %w", i, err)
+			}
+		}
+	} else {
+		err = g.Script.parse(body.scriptBody)
+		if err != nil {
+			return err
+		}
+	}
 
 	// default to last sealed block
 	if g.BlockHeight == EmptyHeight && g.BlockID == flow.ZeroID {
@@ -49,3 +49,40 @@ func TestGetScript_ValidParse(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, getScript.BlockHeight, SealedHeight)
 }
+
+func TestGetScript_BatchParse(t *testing.T) {
+	var getScript GetScript
+
+	source1 := "pub fun main(): Int { return 1 }"
+	source2 := "pub fun main(): Int { return 2 }"
+	arg := util.ToBase64([]byte(`{"type": "Int", "value": "1"}`))
+
+	batch := strings.NewReader(fmt.Sprintf(
+		`{ "scripts": [{ "script": "%s", "arguments": [] }, { "script": "%s", "arguments": ["%s"] }] }`,
+		util.ToBase64([]byte(source1)),
+		util.ToBase64([]byte(source2)),
+		arg,
+	))
+
+	err := getScript.Parse("1", "", batch)
+	assert.NoError(t, err)
+	assert.Len(t, getScript.Scripts, 2)
+	assert.Equal(t, source1, string(getScript.Scripts[0].Source))
+	assert.Equal(t, source2, string(getScript.Scripts[1].Source))
+	assert.Len(t, getScript.Scripts[1].Args, 1)
+
+	invalid := strings.NewReader(fmt.Sprintf(
+		`{ "scripts": [{ "script": "%s" }, { "script": "123" }] }`,
+		util.ToBase64([]byte(source1)),
+	))
+	err = getScript.Parse("1", "", invalid)
+	assert.EqualError(t, err, "invalid script at index 1: invalid script source encoding")
+
+	both := strings.NewReader(fmt.Sprintf(
+		`{ "script": "%s", "scripts": [{ "script": "%s" }] }`,
+		util.ToBase64([]byte(source1)),
+		util.ToBase64([]byte(source2)),
+	))
+	err = getScript.Parse("1", "", both)
+	assert.EqualError(t, err, "can not provide both a script and a batch of scripts")
+}
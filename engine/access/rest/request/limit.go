@@ -0,0 +1,28 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Limit is the maximum number of items in a page, zero if not provided.
+type Limit uint
+
+func (l *Limit) Parse(raw string) error {
+	if raw == "" { // allow empty
+		*l = 0
+		return nil
+	}
+
+	limit, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid limit")
+	}
+
+	*l = Limit(limit)
+	return nil
+}
+
+func (l Limit) Flow() uint {
+	return uint(l)
+}
@@ -0,0 +1,72 @@
+package request
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const startBlockIdQuery = "start_block_id"
+const eventTypesQuery = "event_types"
+const addressesQuery = "addresses"
+const contractsQuery = "contracts"
+
+type SubscribeEvents struct {
+	StartBlockID flow.Identifier
+	StartHeight  uint64
+
+	EventTypes []string
+	Addresses  []string
+	Contracts  []string
+}
+
+func (g *SubscribeEvents) Build(r *Request) error {
+	return g.Parse(
+		r.GetQueryParam(startBlockIdQuery),
+		r.GetQueryParam(startHeightQuery),
+		r.GetQueryParams(eventTypesQuery),
+		r.GetQueryParams(addressesQuery),
+		r.GetQueryParams(contractsQuery),
+	)
+}
+
+func (g *SubscribeEvents) Parse(
+	rawStartBlockID string,
+	rawStartHeight string,
+	rawTypes []string,
+	rawAddresses []string,
+	rawContracts []string,
+) error {
+	var startBlockID ID
+	err := startBlockID.Parse(rawStartBlockID)
+	if err != nil {
+		return fmt.Errorf("invalid start block ID: %w", err)
+	}
+	g.StartBlockID = startBlockID.Flow()
+
+	var height Height
+	err = height.Parse(rawStartHeight)
+	if err != nil {
+		return fmt.Errorf("invalid start height: %w", err)
+	}
+	g.StartHeight = height.Flow()
+
+	// start height only supports explicit values, the latest available block is used if it is omitted
+	if g.StartHeight == FinalHeight || g.StartHeight == SealedHeight {
+		return fmt.Errorf("invalid start height: must be a height")
+	}
+	if g.StartHeight == EmptyHeight {
+		g.StartHeight = 0
+	}
+
+	// if both start_block_id and start_height are provided
+	if g.StartBlockID != flow.ZeroID && g.StartHeight > 0 {
+		return fmt.Errorf("can only provide either block ID or start height")
+	}
+
+	g.EventTypes = rawTypes
+	g.Addresses = rawAddresses
+	g.Contracts = rawContracts
+
+	return nil
+}
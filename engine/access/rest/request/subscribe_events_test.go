@@ -0,0 +1,53 @@
+package request
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeEvents_InvalidParse(t *testing.T) {
+	var subscribeEvents SubscribeEvents
+
+	tests := []struct {
+		startBlockID string
+		startHeight  string
+		err          string
+	}{
+		{"invalid", "", "invalid start block ID: invalid ID format"},
+		{"", "foo", "invalid start height: invalid height format"},
+		{"", "sealed", "invalid start height: must be a height"},
+		{"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7", "10", "can only provide either block ID or start height"},
+	}
+
+	for i, test := range tests {
+		err := subscribeEvents.Parse(test.startBlockID, test.startHeight, nil, nil, nil)
+		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
+	}
+}
+
+func TestSubscribeEvents_ValidParse(t *testing.T) {
+	var subscribeEvents SubscribeEvents
+
+	eventTypes := []string{"A.f8d6e0586b0a20c7.Foo.Bar", "flow.AccountCreated"}
+	addresses := []string{"f8d6e0586b0a20c7"}
+	contracts := []string{"A.f8d6e0586b0a20c7.Foo"}
+
+	err := subscribeEvents.Parse("", "10", eventTypes, addresses, contracts)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), subscribeEvents.StartHeight)
+	assert.Equal(t, eventTypes, subscribeEvents.EventTypes)
+	assert.Equal(t, addresses, subscribeEvents.Addresses)
+	assert.Equal(t, contracts, subscribeEvents.Contracts)
+
+	err = subscribeEvents.Parse("7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7", "", nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), subscribeEvents.StartHeight)
+	assert.Equal(t, "7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7", subscribeEvents.StartBlockID.String())
+
+	// neither start block nor height means start from the latest block
+	err = subscribeEvents.Parse("", "", nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), subscribeEvents.StartHeight)
+}
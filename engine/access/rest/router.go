@@ -9,10 +9,17 @@ import (
 	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/access/rest/middleware"
 	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/state_stream"
 	"github.com/onflow/flow-go/model/flow"
 )
 
-func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain) (*mux.Router, error) {
+func newRouter(
+	backend access.API,
+	logger zerolog.Logger,
+	chain flow.Chain,
+	stateStreamApi state_stream.API,
+	stateStreamConfig state_stream.Config,
+) (*mux.Router, error) {
 	router := mux.NewRouter().StrictSlash(true)
 	v1SubRouter := router.PathPrefix("/v1").Subrouter()
 
@@ -31,6 +38,16 @@ func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain) (*mu
 			Name(r.Name).
 			Handler(h)
 	}
+
+	for _, r := range WSRoutes {
+		h := NewWebsocketHandler(logger, backend, stateStreamApi, stateStreamConfig, r.Handler, linkGenerator, chain)
+		v1SubRouter.
+			Methods(r.Method).
+			Path(r.Pattern).
+			Name(r.Name).
+			Handler(h)
+	}
+
 	return router, nil
 }
 
@@ -51,6 +68,11 @@ var Routes = []route{{
 	Pattern: "/transactions",
 	Name:    "createTransaction",
 	Handler: CreateTransaction,
+}, {
+	Method:  http.MethodPost,
+	Pattern: "/transactions/dry_run",
+	Name:    "dryRunTransaction",
+	Handler: DryRunTransaction,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/transaction_results/{id}",
@@ -96,9 +118,33 @@ var Routes = []route{{
 	Pattern: "/accounts/{address}",
 	Name:    "getAccount",
 	Handler: GetAccount,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/accounts/{address}/transactions",
+	Name:    "getAccountTransactions",
+	Handler: GetAccountTransactions,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/events",
 	Name:    "getEvents",
 	Handler: GetEvents,
 }}
+
+type wsroute struct {
+	Name    string
+	Method  string
+	Pattern string
+	Handler SubscribeHandlerFunc
+}
+
+var WSRoutes = []wsroute{{
+	Method:  http.MethodGet,
+	Pattern: "/subscribe_events",
+	Name:    "subscribeEvents",
+	Handler: SubscribeEvents,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/subscribe_transaction_statuses/{id}",
+	Name:    "subscribeTransactionStatuses",
+	Handler: SubscribeTransactionStatuses,
+}}
@@ -15,6 +15,10 @@ func ExecuteScript(r *request.Request, backend access.API, _ models.LinkGenerato
 		return nil, NewBadRequestError(err)
 	}
 
+	if len(req.Scripts) > 0 {
+		return executeScripts(r, req, backend)
+	}
+
 	if req.BlockID != flow.ZeroID {
 		return backend.ExecuteScriptAtBlockID(r.Context(), req.BlockID, req.Script.Source, req.Script.Args)
 	}
@@ -34,3 +38,38 @@ func ExecuteScript(r *request.Request, backend access.API, _ models.LinkGenerato
 
 	return backend.ExecuteScriptAtBlockHeight(r.Context(), req.BlockHeight, req.Script.Source, req.Script.Args)
 }
+
+// executeScripts executes the batch of scripts from the request against the same block.
+func executeScripts(r *request.Request, req request.GetScript, backend access.API) (interface{}, error) {
+	scripts := make([]access.Script, len(req.Scripts))
+	for i, script := range req.Scripts {
+		scripts[i] = access.Script{
+			Code:      script.Source,
+			Arguments: script.Args,
+		}
+	}
+
+	var results []access.ScriptResult
+	var err error
+	if req.BlockID != flow.ZeroID {
+		results, err = backend.ExecuteScriptsAtBlockID(r.Context(), req.BlockID, scripts)
+	} else {
+		// default to sealed height
+		if req.BlockHeight == request.SealedHeight || req.BlockHeight == request.EmptyHeight || req.BlockHeight == request.FinalHeight {
+			header, err := backend.GetLatestBlockHeader(r.Context(), req.BlockHeight != request.FinalHeight)
+			if err != nil {
+				return nil, err
+			}
+			req.BlockHeight = header.Height
+		}
+
+		results, err = backend.ExecuteScriptsAtBlockHeight(r.Context(), req.BlockHeight, scripts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.ScriptResults
+	response.Build(results)
+	return response, nil
+}
@@ -15,6 +15,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/access/mock"
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -105,6 +106,31 @@ func TestScripts(t *testing.T) {
 		)
 	})
 
+	t.Run("get batch by height", func(t *testing.T) {
+		backend := &mock.API{}
+		height := uint64(1337)
+		batchBody := map[string]interface{}{
+			"scripts": []map[string]interface{}{validBody, validBody},
+		}
+		scripts := []access.Script{
+			{Code: validCode, Arguments: [][]byte{validArgs}},
+			{Code: validCode, Arguments: [][]byte{validArgs}},
+		}
+
+		backend.Mock.
+			On("ExecuteScriptsAtBlockHeight", mocks.Anything, height, scripts).
+			Return([]access.ScriptResult{
+				{Value: []byte("hello world")},
+				{Err: status.Error(codes.InvalidArgument, "failed to execute script")},
+			}, nil)
+
+		req := scriptReq("", fmt.Sprintf("%d", height), batchBody)
+		assertOKResponse(t, req, fmt.Sprintf(
+			`[{"value":"%s"},{"error":"failed to execute script"}]`,
+			base64.StdEncoding.EncodeToString([]byte(`hello world`)),
+		), backend)
+	})
+
 	t.Run("get invalid", func(t *testing.T) {
 		backend := &mock.API{}
 		backend.Mock.
@@ -8,13 +8,22 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/state_stream"
 	"github.com/onflow/flow-go/model/flow"
 )
 
-// NewServer returns an HTTP server initialized with the REST API handler
-func NewServer(backend access.API, listenAddress string, logger zerolog.Logger, chain flow.Chain) (*http.Server, error) {
+// NewServer returns an HTTP server initialized with the REST API handler.
+// If stateStreamApi is not nil, the streaming endpoints are served as well.
+func NewServer(
+	backend access.API,
+	listenAddress string,
+	logger zerolog.Logger,
+	chain flow.Chain,
+	stateStreamApi state_stream.API,
+	stateStreamConfig state_stream.Config,
+) (*http.Server, error) {
 
-	router, err := newRouter(backend, logger, chain)
+	router, err := newRouter(backend, logger, chain, stateStreamApi, stateStreamConfig)
 	if err != nil {
 		return nil, err
 	}
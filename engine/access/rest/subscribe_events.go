@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// SubscribeEvents streams the events of each block starting at the provided start block ID or
+// height, filtered by the provided event types, emitting accounts and contracts.
+// Each message contains the events of a single block, and a message is sent for every block even
+// if none of its events match, so clients can resume from the height after the last block received.
+func SubscribeEvents(
+	r *request.Request,
+	_ access.API,
+	stateStreamApi state_stream.API,
+	config state_stream.Config,
+	_ models.LinkGenerator,
+) (subscription.Subscription, error) {
+	if stateStreamApi == nil {
+		return nil, NewRestError(http.StatusServiceUnavailable, "event streaming is not enabled on this node", nil)
+	}
+
+	req, err := r.SubscribeEventsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	filter, err := state_stream.NewEventFilter(config.EventFilterConfig, r.Chain, req.EventTypes, req.Addresses, req.Contracts)
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	sub := stateStreamApi.SubscribeEvents(r.Context(), req.StartBlockID, req.StartHeight, filter)
+
+	return newConvertedSubscription(r.Context(), sub, func(v interface{}) (interface{}, error) {
+		blockEvents, ok := v.(*flow.BlockEvents)
+		if !ok {
+			return nil, fmt.Errorf("unexpected response type: %T", v)
+		}
+
+		var response models.BlockEvents
+		response.Build(*blockEvents)
+		return response, nil
+	}), nil
+}
@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/engine/access/subscription"
+)
+
+// SubscribeTransactionStatuses streams the result of the requested transaction each time its status
+// changes. The stream is closed after the transaction is sealed or expired.
+func SubscribeTransactionStatuses(
+	r *request.Request,
+	backend access.API,
+	_ state_stream.API,
+	_ state_stream.Config,
+	link models.LinkGenerator,
+) (subscription.Subscription, error) {
+	req, err := r.GetTransactionResultRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	sub := backend.SubscribeTransactionStatuses(r.Context(), req.ID)
+
+	return newConvertedSubscription(r.Context(), sub, func(v interface{}) (interface{}, error) {
+		txr, ok := v.(*access.TransactionResult)
+		if !ok {
+			return nil, fmt.Errorf("unexpected response type: %T", v)
+		}
+
+		var response models.TransactionResult
+		response.Build(txr, req.ID, link)
+		return response, nil
+	}), nil
+}
@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/onflow/flow-go/engine/access/subscription"
+)
+
+// convertedSubscription converts the values received from a backend subscription into the REST
+// response models before they are sent to the client.
+type convertedSubscription struct {
+	subscription.Subscription
+	ch  <-chan interface{}
+	err error
+}
+
+// newConvertedSubscription returns a subscription that applies convert to each value received
+// from sub. If a value cannot be converted, the subscription is closed and Err returns the
+// conversion error.
+func newConvertedSubscription(
+	ctx context.Context,
+	sub subscription.Subscription,
+	convert func(interface{}) (interface{}, error),
+) *convertedSubscription {
+	// the channel is unbuffered to preserve the backpressure applied by the backend subscription
+	ch := make(chan interface{})
+	s := &convertedSubscription{Subscription: sub, ch: ch}
+
+	go func() {
+		defer close(ch)
+		for v := range sub.Channel() {
+			response, err := convert(v)
+			if err != nil {
+				s.err = err
+				return
+			}
+
+			select {
+			case ch <- response:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+func (s *convertedSubscription) Channel() <-chan interface{} {
+	return s.ch
+}
+
+// Err returns the conversion error if one occurred, otherwise the error of the backend subscription
+func (s *convertedSubscription) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.Subscription.Err()
+}
@@ -65,3 +65,21 @@ func CreateTransaction(r *request.Request, backend access.API, link models.LinkG
 	response.Build(&req.Transaction, nil, link)
 	return response, nil
 }
+
+// DryRunTransaction executes the transaction from the provided payload without submitting it, and
+// returns the resources it would use and the fees it would be charged.
+func DryRunTransaction(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	req, err := r.DryRunTransactionRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	result, err := backend.DryRunTransaction(r.Context(), &req.Transaction, req.SkipSignatureVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.TransactionDryRun
+	response.Build(result)
+	return response, nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/onflow/cadence/runtime/common"
 	mocks "github.com/stretchr/testify/mock"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -19,6 +20,7 @@ import (
 	"github.com/onflow/flow-go/access/mock"
 	"github.com/onflow/flow-go/engine/access/rest/models"
 	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/fvm/meter"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
 )
@@ -404,6 +406,90 @@ func TestCreateTransaction(t *testing.T) {
 	})
 }
 
+func dryRunTransactionReq(body interface{}, skipSignatureVerification bool) *http.Request {
+	u, _ := url.Parse("/v1/transactions/dry_run")
+	if skipSignatureVerification {
+		q := u.Query()
+		q.Add("skip_signature_verification", "true")
+		u.RawQuery = q.Encode()
+	}
+
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", u.String(), bytes.NewBuffer(jsonBody))
+	return req
+}
+
+func TestDryRunTransaction(t *testing.T) {
+
+	t.Run("dry run unsigned transaction", func(t *testing.T) {
+		backend := &mock.API{}
+		tx := unittest.TransactionBodyFixture()
+		tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+		body := validCreateBody(tx)
+		delete(body, "envelope_signatures")
+
+		blockID := unittest.IdentifierFixture()
+		result := &access.TransactionDryRunResult{
+			BlockID:              blockID,
+			BlockHeight:          42,
+			ComputationUsed:      10,
+			MemoryEstimate:       1000,
+			ComputationBreakdown: meter.ComputationBreakdown{common.ComputationKindStatement: 10},
+			MemoryBreakdown:      meter.MemoryBreakdown{common.MemoryKindBoolValue: 1000},
+			Fees:                 1_000,
+			Events:               []flow.Event{},
+			StorageDelta: flow.RegisterEntries{{
+				Key:   flow.NewRegisterID("\x01", "key"),
+				Value: []byte("value"),
+			}},
+			ErrorMessage: "transaction failed",
+		}
+
+		backend.Mock.
+			On("DryRunTransaction", mocks.Anything, mocks.Anything, true).
+			Return(result, nil)
+
+		expected := fmt.Sprintf(`
+			{
+			   "block_id":"%s",
+			   "block_height":"42",
+			   "computation_used":"10",
+			   "memory_estimate":"1000",
+			   "computation_breakdown":{"%d":"10"},
+			   "memory_breakdown":{"%d":"1000"},
+			   "fees":"0.00001000",
+			   "events":[],
+			   "storage_delta":[
+				  {
+					 "owner":"01",
+					 "key":"%s",
+					 "value":"%s"
+				  }
+			   ],
+			   "error_message":"transaction failed"
+			}`,
+			blockID, common.ComputationKindStatement, common.MemoryKindBoolValue,
+			util.ToBase64([]byte("key")), util.ToBase64([]byte("value")))
+		assertOKResponse(t, dryRunTransactionReq(body, true), expected, backend)
+	})
+
+	t.Run("dry run requires signatures if verified", func(t *testing.T) {
+		backend := &mock.API{}
+		tx := unittest.TransactionBodyFixture()
+		tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+		body := validCreateBody(tx)
+		delete(body, "envelope_signatures")
+
+		assertResponse(
+			t,
+			dryRunTransactionReq(body, false),
+			http.StatusBadRequest,
+			`{"code":400, "message":"envelope signatures not provided"}`,
+			backend,
+		)
+	})
+}
+
 func transactionResultFixture(tx flow.Transaction) *access.TransactionResult {
 	return &access.TransactionResult{
 		Status:     flow.TransactionStatusSealed,
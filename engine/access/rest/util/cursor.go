@@ -0,0 +1,33 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const cursorLength = 8 + flow.IdentifierLen
+
+// EncodeCursor encodes a pagination cursor pointing to an entity at a block height into an opaque,
+// URL safe string.
+func EncodeCursor(height uint64, id flow.Identifier) string {
+	raw := make([]byte, cursorLength)
+	binary.BigEndian.PutUint64(raw, height)
+	copy(raw[8:], id[:])
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor decodes a pagination cursor encoded with EncodeCursor.
+func DecodeCursor(cursor string) (uint64, flow.Identifier, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) != cursorLength {
+		return 0, flow.ZeroID, fmt.Errorf("invalid cursor") // hide error from user
+	}
+
+	var id flow.Identifier
+	copy(id[:], raw[8:])
+
+	return binary.BigEndian.Uint64(raw), id, nil
+}
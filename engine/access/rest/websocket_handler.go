@@ -0,0 +1,181 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const (
+	// writeWait is the time allowed to write a message to the peer.
+	writeWait = 10 * time.Second
+
+	// pongWait is the time allowed to read the next pong message from the peer.
+	pongWait = 60 * time.Second
+
+	// pingPeriod is the period at which pings are sent to the peer. Must be less than pongWait.
+	pingPeriod = (pongWait * 9) / 10
+)
+
+// SubscribeHandlerFunc is a function that contains endpoint handling logic for subscriptions,
+// it validates the request and returns the subscription whose data is streamed to the client.
+type SubscribeHandlerFunc func(
+	r *request.Request,
+	backend access.API,
+	stateStreamApi state_stream.API,
+	stateStreamConfig state_stream.Config,
+	generator models.LinkGenerator,
+) (subscription.Subscription, error)
+
+// WebsocketHandler is custom http handler implementing custom handler function for streaming
+// endpoints. The connection is upgraded to a websocket and each value received from the
+// subscription is sent to the client as a JSON message.
+type WebsocketHandler struct {
+	*Handler
+	stateStreamApi       state_stream.API
+	stateStreamConfig    state_stream.Config
+	subscribeHandlerFunc SubscribeHandlerFunc
+	upgrader             websocket.Upgrader
+}
+
+func NewWebsocketHandler(
+	logger zerolog.Logger,
+	backend access.API,
+	stateStreamApi state_stream.API,
+	stateStreamConfig state_stream.Config,
+	subscribeFunc SubscribeHandlerFunc,
+	generator models.LinkGenerator,
+	chain flow.Chain,
+) *WebsocketHandler {
+	return &WebsocketHandler{
+		Handler: &Handler{
+			logger:        logger,
+			backend:       backend,
+			linkGenerator: generator,
+			chain:         chain,
+		},
+		stateStreamApi:       stateStreamApi,
+		stateStreamConfig:    stateStreamConfig,
+		subscribeHandlerFunc: subscribeFunc,
+		upgrader: websocket.Upgrader{
+			// origin checks are handled by the CORS configuration of the server
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// ServeHTTP validates the subscription request, upgrades the connection to a websocket and streams
+// the subscription data to the client until either side closes the connection.
+func (h *WebsocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	errLog := h.logger.With().Str("request_url", r.URL.String()).Logger()
+
+	err := r.ParseForm()
+	if err != nil {
+		h.errorHandler(w, err, errLog)
+		return
+	}
+
+	// the subscription lives as long as the websocket connection, which outlives the request context
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	decoratedRequest := request.Decorate(r.WithContext(ctx), h.chain)
+
+	sub, err := h.subscribeHandlerFunc(decoratedRequest, h.backend, h.stateStreamApi, h.stateStreamConfig, h.linkGenerator)
+	if err != nil {
+		h.errorHandler(w, err, errLog)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already responded to the client with an error
+		errLog.Debug().Err(err).Msg("could not upgrade connection to websocket")
+		return
+	}
+	defer conn.Close()
+
+	h.stream(ctx, cancel, conn, sub, errLog)
+}
+
+// stream writes all values received from the subscription to the websocket connection.
+func (h *WebsocketHandler) stream(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	conn *websocket.Conn,
+	sub subscription.Subscription,
+	logger zerolog.Logger,
+) {
+	// the read loop is required to process control messages (pong, close) from the client. It
+	// cancels the subscription when the client goes away.
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				logger.Debug().Err(err).Msg("could not send ping")
+				return
+			}
+
+		case v, ok := <-sub.Channel():
+			if !ok {
+				h.closeConnection(conn, sub.Err(), logger)
+				return
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(v); err != nil {
+				logger.Debug().Err(err).Msg("could not write response")
+				return
+			}
+		}
+	}
+}
+
+// closeConnection sends a close message to the client, including the error message if the
+// subscription failed.
+func (h *WebsocketHandler) closeConnection(conn *websocket.Conn, err error, logger zerolog.Logger) {
+	code := websocket.CloseNormalClosure
+	msg := ""
+	if err != nil {
+		code = websocket.CloseInternalServerErr
+		msg = fmt.Sprintf("stream encountered an error: %v", err)
+		logger.Debug().Err(err).Msg("subscription failed")
+	}
+
+	deadline := time.Now().Add(writeWait)
+	err = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, msg), deadline)
+	if err != nil {
+		logger.Debug().Err(err).Msg("could not send close message")
+	}
+}
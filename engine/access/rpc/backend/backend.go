@@ -14,6 +14,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/subscription"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
@@ -60,6 +61,7 @@ var SnapshotHistoryLimitErr = fmt.Errorf("reached the snapshot history limit")
 // Block details related calls are handled by backendBlockDetails.
 // Event related calls are handled by backendEvents.
 // Account related calls are handled by backendAccounts.
+// Account transaction index calls are handled by backendAccountTransactions.
 //
 // All remaining calls are handled by the base Backend in this file.
 type Backend struct {
@@ -70,6 +72,7 @@ type Backend struct {
 	backendBlockDetails
 	backendAccounts
 	backendExecutionResults
+	backendAccountTransactions
 
 	state                protocol.State
 	chainID              flow.ChainID
@@ -98,6 +101,9 @@ func New(
 	fixedExecutionNodeIDs []string,
 	log zerolog.Logger,
 	snapshotHistoryLimit int,
+	scriptExecutor ScriptExecutor,
+	accountTransactions storage.AccountTransactions,
+	events storage.Events,
 ) *Backend {
 	retry := newRetry()
 	if retryEnabled {
@@ -122,6 +128,7 @@ func New(
 			log:               log,
 			metrics:           transactionMetrics,
 			loggedScripts:     loggedScripts,
+			scriptExecutor:    scriptExecutor,
 		},
 		backendTransactions: backendTransactions{
 			staticCollectionRPC:  collectionRPC,
@@ -135,6 +142,8 @@ func New(
 			transactionMetrics:   transactionMetrics,
 			retry:                retry,
 			connFactory:          connFactory,
+			scriptExecutor:       scriptExecutor,
+			finalizedBroadcaster: subscription.NewBroadcaster(),
 			previousAccessNodes:  historicalAccessNodes,
 			log:                  log,
 		},
@@ -145,6 +154,7 @@ func New(
 			connFactory:       connFactory,
 			log:               log,
 			maxHeightRange:    maxHeightRange,
+			events:            events,
 		},
 		backendBlockHeaders: backendBlockHeaders{
 			headers: headers,
@@ -160,10 +170,14 @@ func New(
 			executionReceipts: executionReceipts,
 			connFactory:       connFactory,
 			log:               log,
+			scriptExecutor:    scriptExecutor,
 		},
 		backendExecutionResults: backendExecutionResults{
 			executionResults: executionResults,
 		},
+		backendAccountTransactions: backendAccountTransactions{
+			accountTransactions: accountTransactions,
+		},
 		collections:          collections,
 		executionReceipts:    executionReceipts,
 		connFactory:          connFactory,
@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// DefaultAccountTransactionsLimit is the number of transactions returned per page if no limit is requested
+const DefaultAccountTransactionsLimit = 50
+
+// MaxAccountTransactionsLimit is the maximum number of transactions returned per page
+const MaxAccountTransactionsLimit = 500
+
+type backendAccountTransactions struct {
+	accountTransactions storage.AccountTransactions
+}
+
+// GetTransactionsByAccount returns a page of the transactions the account was involved in between
+// the start and end heights (inclusive). If the cursor is not nil, the page starts after the
+// transaction the cursor points to.
+func (b *backendAccountTransactions) GetTransactionsByAccount(
+	_ context.Context,
+	address flow.Address,
+	startHeight uint64,
+	endHeight uint64,
+	cursor *flow.AccountTransactionCursor,
+	limit uint,
+) (*access.AccountTransactions, error) {
+	if b.accountTransactions == nil {
+		return nil, status.Errorf(codes.Unimplemented, "account transaction index is not enabled")
+	}
+
+	if startHeight > endHeight {
+		return nil, status.Errorf(codes.InvalidArgument, "start height %d must be less than or equal to end height %d", startHeight, endHeight)
+	}
+
+	if limit == 0 {
+		limit = DefaultAccountTransactionsLimit
+	}
+	if limit > MaxAccountTransactionsLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit %d exceeds the maximum of %d", limit, MaxAccountTransactionsLimit)
+	}
+
+	entries, err := b.accountTransactions.ByAddress(address, startHeight, endHeight, cursor, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get transactions for account %s: %v", address, err)
+	}
+
+	page := &access.AccountTransactions{
+		Transactions: entries,
+	}
+
+	// a full page may be followed by more transactions
+	if uint(len(entries)) == limit {
+		next := entries[len(entries)-1].Cursor()
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
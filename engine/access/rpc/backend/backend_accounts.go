@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -11,7 +12,9 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	fvmerrors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 )
@@ -22,6 +25,7 @@ type backendAccounts struct {
 	executionReceipts storage.ExecutionReceipts
 	connFactory       ConnectionFactory
 	log               zerolog.Logger
+	scriptExecutor    ScriptExecutor
 }
 
 func (b *backendAccounts) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
@@ -36,12 +40,9 @@ func (b *backendAccounts) GetAccountAtLatestBlock(ctx context.Context, address f
 		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
 	}
 
-	// get the block id of the latest sealed header
-	latestBlockID := latestHeader.ID()
-
-	account, err := b.getAccountAtBlockID(ctx, address, latestBlockID)
+	account, err := b.getAccount(ctx, address, latestHeader)
 	if err != nil {
-		b.log.Error().Err(err).Msgf("failed to get account at blockID: %v", latestBlockID)
+		b.log.Error().Err(err).Msgf("failed to get account at blockID: %v", latestHeader.ID())
 		return nil, err
 	}
 
@@ -60,10 +61,7 @@ func (b *backendAccounts) GetAccountAtBlockHeight(
 		return nil, err
 	}
 
-	// get block ID of the header at the given height
-	blockID := header.ID()
-
-	account, err := b.getAccountAtBlockID(ctx, address, blockID)
+	account, err := b.getAccount(ctx, address, header)
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +69,36 @@ func (b *backendAccounts) GetAccountAtBlockHeight(
 	return account, nil
 }
 
+// getAccount reads the account locally if the execution state for the block is indexed, otherwise
+// it requests the account from the execution nodes.
+func (b *backendAccounts) getAccount(
+	ctx context.Context,
+	address flow.Address,
+	header *flow.Header,
+) (*flow.Account, error) {
+	blockID := header.ID()
+
+	if b.scriptExecutor != nil {
+		account, err := b.scriptExecutor.GetAccountAtBlockHeight(ctx, address, header.Height)
+		if err == nil {
+			return account, nil
+		}
+
+		if fvmerrors.IsAccountNotFoundError(err) {
+			return nil, status.Errorf(codes.NotFound, "account with address %s not found: %v", address, err)
+		}
+
+		if !errors.Is(err, execution.ErrDataNotAvailable) {
+			b.log.Error().Err(err).
+				Hex("block_id", blockID[:]).
+				Uint64("height", header.Height).
+				Msg("failed to get account locally, falling back to execution nodes")
+		}
+	}
+
+	return b.getAccountAtBlockID(ctx, address, blockID)
+}
+
 func (b *backendAccounts) getAccountAtBlockID(
 	ctx context.Context,
 	address flow.Address,
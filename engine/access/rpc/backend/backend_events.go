@@ -12,12 +12,19 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 )
 
+// DefaultEventsByTypeLimit is the number of blocks returned per page if no limit is requested
+const DefaultEventsByTypeLimit = 50
+
+// MaxEventsByTypeLimit is the maximum number of blocks returned per page
+const MaxEventsByTypeLimit = 250
+
 type backendEvents struct {
 	headers           storage.Headers
 	executionReceipts storage.ExecutionReceipts
@@ -25,6 +32,7 @@ type backendEvents struct {
 	connFactory       ConnectionFactory
 	log               zerolog.Logger
 	maxHeightRange    uint
+	events            storage.Events // optional local event type index
 }
 
 // GetEventsForHeightRange retrieves events for all sealed blocks between the start block height and
@@ -103,6 +111,103 @@ func (b *backendEvents) GetEventsForBlockIDs(
 	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
 }
 
+// GetEventsByType returns a page of the sealed blocks between the start and end heights (inclusive)
+// that contain events of the given type, with their events of that type. If the cursor is not nil,
+// the page starts after the block the cursor points to.
+//
+// The events are read from the local event type index, so the time to answer a query is
+// proportional to the number of matching blocks rather than the size of the height range.
+func (b *backendEvents) GetEventsByType(
+	_ context.Context,
+	eventType string,
+	startHeight uint64,
+	endHeight uint64,
+	cursor *access.EventsCursor,
+	limit uint,
+) (*access.EventsPage, error) {
+	if b.events == nil {
+		return nil, status.Errorf(codes.Unimplemented, "event type index is not enabled")
+	}
+
+	if endHeight < startHeight {
+		return nil, status.Error(codes.InvalidArgument, "invalid start or end height")
+	}
+
+	if limit == 0 {
+		limit = DefaultEventsByTypeLimit
+	}
+	if limit > MaxEventsByTypeLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit %d exceeds the maximum of %d", limit, MaxEventsByTypeLimit)
+	}
+
+	firstHeight, latestHeight, err := b.events.EventTypeIndexHeights()
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.OutOfRange, "no blocks have been indexed yet")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get indexed heights: %v", err)
+	}
+
+	// the index is only complete for the indexed height range
+	if startHeight < firstHeight {
+		return nil, status.Errorf(codes.OutOfRange,
+			"start height %d is lower than the first indexed height %d", startHeight, firstHeight)
+	}
+	if startHeight > latestHeight {
+		return nil, status.Errorf(codes.OutOfRange,
+			"start height %d is greater than the latest indexed height %d", startHeight, latestHeight)
+	}
+	if endHeight > latestHeight {
+		endHeight = latestHeight
+	}
+
+	page := &access.EventsPage{
+		Events: []flow.BlockEvents{},
+	}
+
+	if cursor != nil && cursor.BlockHeight >= startHeight {
+		if cursor.BlockHeight >= endHeight {
+			return page, nil
+		}
+		startHeight = cursor.BlockHeight + 1
+	}
+
+	blockIDs, err := b.events.BlockIDsByEventType(flow.EventType(eventType), startHeight, endHeight, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get blocks by event type: %v", err)
+	}
+
+	for _, blockID := range blockIDs {
+		header, err := b.headers.ByBlockID(blockID)
+		if err != nil {
+			return nil, convertStorageError(fmt.Errorf("failed to get events: %w", err))
+		}
+
+		events, err := b.events.ByBlockIDEventType(blockID, flow.EventType(eventType))
+		if err != nil {
+			return nil, convertStorageError(fmt.Errorf("failed to get events: %w", err))
+		}
+
+		page.Events = append(page.Events, flow.BlockEvents{
+			BlockID:        blockID,
+			BlockHeight:    header.Height,
+			BlockTimestamp: header.Timestamp,
+			Events:         events,
+		})
+	}
+
+	// a full page may be followed by more blocks
+	if uint(len(blockIDs)) == limit {
+		last := page.Events[len(page.Events)-1]
+		page.NextCursor = &access.EventsCursor{
+			BlockHeight: last.BlockHeight,
+			BlockID:     last.BlockID,
+		}
+	}
+
+	return page, nil
+}
+
 func (b *backendEvents) getBlockEventsFromExecutionNode(
 	ctx context.Context,
 	blockHeaders []*flow.Header,
@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"crypto/md5" //nolint:gosec
+	"errors"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -10,11 +11,14 @@ import (
 	"github.com/hashicorp/go-multierror"
 	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
 	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 )
@@ -22,6 +26,20 @@ import (
 // uniqueScriptLoggingTimeWindow is the duration for checking the uniqueness of scripts sent for execution
 const uniqueScriptLoggingTimeWindow = 10 * time.Minute
 
+// MaxScriptsPerBatch is the maximum number of scripts that can be executed in a single batch
+const MaxScriptsPerBatch = 100
+
+// BatchComputationLimit is the maximum computation used by all scripts of a batch combined, when the
+// batch is executed locally
+const BatchComputationLimit = 1_000_000
+
+// MaxScriptsPerExecutionNodeBatch is the maximum number of scripts of a batch that can be executed on the
+// execution nodes, when the batch can't be executed locally. Each script is a separate request.
+const MaxScriptsPerExecutionNodeBatch = 10
+
+// executionNodeBatchConcurrency is the maximum number of scripts of a batch executed on the execution nodes concurrently
+const executionNodeBatchConcurrency = 4
+
 type backendScripts struct {
 	headers           storage.Headers
 	executionReceipts storage.ExecutionReceipts
@@ -30,6 +48,37 @@ type backendScripts struct {
 	log               zerolog.Logger
 	metrics           module.BackendScriptsMetrics
 	loggedScripts     *lru.Cache
+	scriptExecutor    ScriptExecutor
+}
+
+// ScriptExecutor executes scripts and reads accounts locally, against the execution state indexed
+// by the access node.
+type ScriptExecutor interface {
+	// DryRunAtLatestHeight executes the transaction against the latest indexed execution state,
+	// without committing its changes.
+	// Expected errors:
+	// - execution.ErrDataNotAvailable if no execution state is available locally
+	DryRunAtLatestHeight(ctx context.Context, tx *flow.TransactionBody, skipSignatureVerification bool) (*execution.TransactionDryRunResult, error)
+
+	// ExecuteAtBlockHeight executes the script at the given block height and returns the JSON-CDC
+	// encoded result.
+	// Expected errors:
+	// - execution.ErrDataNotAvailable if the state for the height is not available locally
+	// - execution.ScriptExecutionError if the script failed
+	ExecuteAtBlockHeight(ctx context.Context, script []byte, arguments [][]byte, height uint64) ([]byte, error)
+
+	// ExecuteBatchAtBlockHeight executes the scripts at the given block height, sharing loaded programs
+	// between the scripts, and returns the result of each script. The computation used by all scripts
+	// combined is limited to computationLimit.
+	// Expected errors:
+	// - execution.ErrDataNotAvailable if the state for the height is not available locally
+	ExecuteBatchAtBlockHeight(ctx context.Context, scripts []execution.Script, height uint64, computationLimit uint64) ([]execution.ScriptResult, error)
+
+	// GetAccountAtBlockHeight returns the account with the given address at the given block height.
+	// Expected errors:
+	// - execution.ErrDataNotAvailable if the state for the height is not available locally
+	// - fvm errors.AccountNotFoundError if the account does not exist at the height
+	GetAccountAtBlockHeight(ctx context.Context, address flow.Address, height uint64) (*flow.Account, error)
 }
 
 func (b *backendScripts) ExecuteScriptAtLatestBlock(
@@ -44,11 +93,7 @@ func (b *backendScripts) ExecuteScriptAtLatestBlock(
 		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
 	}
 
-	// get the block id of the latest sealed header
-	latestBlockID := latestHeader.ID()
-
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, latestBlockID, script, arguments)
+	return b.executeScript(ctx, latestHeader, script, arguments)
 }
 
 func (b *backendScripts) ExecuteScriptAtBlockID(
@@ -57,6 +102,15 @@ func (b *backendScripts) ExecuteScriptAtBlockID(
 	script []byte,
 	arguments [][]byte,
 ) ([]byte, error) {
+	if b.scriptExecutor != nil {
+		header, err := b.headers.ByBlockID(blockID)
+		if err == nil {
+			return b.executeScript(ctx, header, script, arguments)
+		}
+		// the block may not be known yet, the execution nodes can still answer
+		b.log.Debug().Err(err).Hex("block_id", blockID[:]).Msg("failed to get header for local script execution")
+	}
+
 	// execute script on the execution node at that block id
 	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
 }
@@ -74,12 +128,205 @@ func (b *backendScripts) ExecuteScriptAtBlockHeight(
 		return nil, err
 	}
 
+	return b.executeScript(ctx, header, script, arguments)
+}
+
+// executeScript executes the script locally if the execution state for the block is indexed,
+// otherwise it forwards the request to the execution nodes.
+func (b *backendScripts) executeScript(
+	ctx context.Context,
+	header *flow.Header,
+	script []byte,
+	arguments [][]byte,
+) ([]byte, error) {
 	blockID := header.ID()
 
+	if b.scriptExecutor != nil {
+		execStartTime := time.Now()
+		result, err := b.scriptExecutor.ExecuteAtBlockHeight(ctx, script, arguments, header.Height)
+		if err == nil {
+			b.metrics.ScriptExecuted(time.Since(execStartTime), len(script))
+			return result, nil
+		}
+
+		if execution.IsScriptExecutionError(err) {
+			// the script itself failed, the execution nodes would return the same error
+			return nil, status.Errorf(codes.InvalidArgument, "failed to execute script: %v", err)
+		}
+
+		if !errors.Is(err, execution.ErrDataNotAvailable) {
+			b.log.Error().Err(err).
+				Hex("block_id", blockID[:]).
+				Uint64("height", header.Height).
+				Msg("failed to execute script locally, falling back to execution nodes")
+		}
+	}
+
 	// execute script on the execution node at that block id
 	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
 }
 
+// ExecuteScriptsAtBlockID executes a batch of scripts against the state of the block with the given ID.
+func (b *backendScripts) ExecuteScriptsAtBlockID(
+	ctx context.Context,
+	blockID flow.Identifier,
+	scripts []access.Script,
+) ([]access.ScriptResult, error) {
+	err := validateScriptBatch(scripts)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.scriptExecutor != nil {
+		header, err := b.headers.ByBlockID(blockID)
+		if err == nil {
+			return b.executeScripts(ctx, header, scripts)
+		}
+		// the block may not be known yet, the execution nodes can still answer
+		b.log.Debug().Err(err).Hex("block_id", blockID[:]).Msg("failed to get header for local script execution")
+	}
+
+	return b.executeScriptsOnExecutionNode(ctx, blockID, scripts)
+}
+
+// ExecuteScriptsAtBlockHeight executes a batch of scripts against the state of the block at the given height.
+func (b *backendScripts) ExecuteScriptsAtBlockHeight(
+	ctx context.Context,
+	blockHeight uint64,
+	scripts []access.Script,
+) ([]access.ScriptResult, error) {
+	err := validateScriptBatch(scripts)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := b.headers.ByHeight(blockHeight)
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+
+	return b.executeScripts(ctx, header, scripts)
+}
+
+// validateScriptBatch checks that the number of scripts in the batch is within the allowed range.
+func validateScriptBatch(scripts []access.Script) error {
+	if len(scripts) == 0 {
+		return status.Errorf(codes.InvalidArgument, "no scripts provided")
+	}
+	if len(scripts) > MaxScriptsPerBatch {
+		return status.Errorf(codes.InvalidArgument, "number of scripts (%d) exceeds the maximum of %d", len(scripts), MaxScriptsPerBatch)
+	}
+	return nil
+}
+
+// executeScripts executes the batch of scripts locally if the execution state for the block is
+// indexed, otherwise it forwards each script to the execution nodes.
+func (b *backendScripts) executeScripts(
+	ctx context.Context,
+	header *flow.Header,
+	scripts []access.Script,
+) ([]access.ScriptResult, error) {
+	blockID := header.ID()
+
+	if b.scriptExecutor != nil {
+		batch := make([]execution.Script, len(scripts))
+		for i, script := range scripts {
+			batch[i] = execution.Script{
+				Code:      script.Code,
+				Arguments: script.Arguments,
+			}
+		}
+
+		execStartTime := time.Now()
+		batchResults, err := b.scriptExecutor.ExecuteBatchAtBlockHeight(ctx, batch, header.Height, BatchComputationLimit)
+		if err == nil {
+			results := make([]access.ScriptResult, len(batchResults))
+			for i, result := range batchResults {
+				results[i] = convertScriptResult(result)
+			}
+
+			b.log.Debug().
+				Hex("block_id", blockID[:]).
+				Int("scripts", len(scripts)).
+				Dur("duration", time.Since(execStartTime)).
+				Msg("executed script batch locally")
+
+			return results, nil
+		}
+
+		if !errors.Is(err, execution.ErrDataNotAvailable) {
+			b.log.Error().Err(err).
+				Hex("block_id", blockID[:]).
+				Uint64("height", header.Height).
+				Msg("failed to execute script batch locally, falling back to execution nodes")
+		}
+	}
+
+	return b.executeScriptsOnExecutionNode(ctx, blockID, scripts)
+}
+
+// executeScriptsOnExecutionNode executes each script of the batch on the execution nodes. Execution
+// nodes execute one script per request, so loaded programs are not shared between the scripts, and
+// the computation of each script is only limited by the execution node. Therefore, the number of
+// scripts is limited to MaxScriptsPerExecutionNodeBatch, and only a few scripts are executed concurrently.
+func (b *backendScripts) executeScriptsOnExecutionNode(
+	ctx context.Context,
+	blockID flow.Identifier,
+	scripts []access.Script,
+) ([]access.ScriptResult, error) {
+	if len(scripts) > MaxScriptsPerExecutionNodeBatch {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"number of scripts (%d) exceeds the maximum of %d for batches executed on the execution nodes",
+			len(scripts), MaxScriptsPerExecutionNodeBatch)
+	}
+
+	// find few execution nodes which have executed the block earlier and provided an execution receipt for it
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find execution nodes at blockId %v: %v", blockID.String(), err)
+	}
+
+	results := make([]access.ScriptResult, len(scripts))
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(executionNodeBatchConcurrency)
+	for i, script := range scripts {
+		i, script := i, script
+		g.Go(func() error {
+			value, err := b.executeScriptOnExecutionNodes(gCtx, blockID, execNodes, script.Code, script.Arguments)
+			if err != nil && status.Code(err) != codes.InvalidArgument {
+				// the execution nodes failed, the remaining scripts would fail as well
+				return err
+			}
+
+			results[i] = access.ScriptResult{
+				Value: value,
+				Err:   err,
+			}
+			return nil
+		})
+	}
+
+	err = g.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// convertScriptResult converts the result of a script executed locally into the access API format.
+func convertScriptResult(result execution.ScriptResult) access.ScriptResult {
+	switch {
+	case result.Err == nil:
+		return access.ScriptResult{Value: result.Value}
+	case errors.Is(result.Err, execution.ErrBatchComputationLimitExceeded):
+		return access.ScriptResult{Err: status.Errorf(codes.ResourceExhausted, "failed to execute script: %v", result.Err)}
+	default:
+		return access.ScriptResult{Err: status.Errorf(codes.InvalidArgument, "failed to execute script: %v", result.Err)}
+	}
+}
+
 // executeScriptOnExecutionNode forwards the request to the execution node using the execution node
 // grpc client and converts the response back to the access node api response format
 func (b *backendScripts) executeScriptOnExecutionNode(
@@ -89,17 +336,31 @@ func (b *backendScripts) executeScriptOnExecutionNode(
 	arguments [][]byte,
 ) ([]byte, error) {
 
+	// find few execution nodes which have executed the block earlier and provided an execution receipt for it
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find execution nodes at blockId %v: %v", blockID.String(), err)
+	}
+
+	return b.executeScriptOnExecutionNodes(ctx, blockID, execNodes, script, arguments)
+}
+
+// executeScriptOnExecutionNodes tries to execute the script on each of the given execution nodes,
+// until one of them executes it
+func (b *backendScripts) executeScriptOnExecutionNodes(
+	ctx context.Context,
+	blockID flow.Identifier,
+	execNodes flow.IdentityList,
+	script []byte,
+	arguments [][]byte,
+) ([]byte, error) {
+
 	execReq := execproto.ExecuteScriptAtBlockIDRequest{
 		BlockId:   blockID[:],
 		Script:    script,
 		Arguments: arguments,
 	}
 
-	// find few execution nodes which have executed the block earlier and provided an execution receipt for it
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to find execution nodes at blockId %v: %v", blockID.String(), err)
-	}
 	// encode to MD5 as low compute/memory lookup key
 	// CAUTION: cryptographically insecure md5 is used here, but only to de-duplicate logs.
 	// *DO NOT* use this hash for any protocol-related or cryptographic functions.
@@ -147,7 +408,7 @@ func (b *backendScripts) executeScriptOnExecutionNode(
 	}
 	errToReturn := errors.ErrorOrNil()
 	if errToReturn != nil {
-		b.log.Error().Err(err).Msg("script execution failed for execution node internal reasons")
+		b.log.Error().Err(errToReturn).Msg("script execution failed for execution node internal reasons")
 	}
 	return nil, errToReturn
 }
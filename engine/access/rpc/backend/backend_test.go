@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/dgraph-io/badger/v2"
+	"github.com/onflow/cadence/runtime/common"
 	accessproto "github.com/onflow/flow/protobuf/go/flow/access"
 	entitiesproto "github.com/onflow/flow/protobuf/go/flow/entities"
 	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
@@ -16,16 +17,22 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	bprotocol "github.com/onflow/flow-go/state/protocol/badger"
 	"github.com/onflow/flow-go/state/protocol/util"
 
+	accessapi "github.com/onflow/flow-go/access"
 	access "github.com/onflow/flow-go/engine/access/mock"
 	backendmock "github.com/onflow/flow-go/engine/access/rpc/backend/mock"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	"github.com/onflow/flow-go/fvm/meter"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/module/metrics"
 	protocol "github.com/onflow/flow-go/state/protocol/mock"
 	"github.com/onflow/flow-go/storage"
@@ -107,6 +114,9 @@ func (suite *Suite) TestPing() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	err := backend.Ping(context.Background())
@@ -139,6 +149,9 @@ func (suite *Suite) TestGetLatestFinalizedBlockHeader() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// query the handler for the latest finalized block
@@ -202,6 +215,9 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_NoTransitionSpan() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -273,6 +289,9 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_TransitionSpans() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -337,6 +356,9 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_PhaseTransitionSpan() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -412,6 +434,9 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_EpochTransitionSpan() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -471,6 +496,9 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_HistoryLimit() {
 			nil,
 			suite.log,
 			snapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// the handler should return a snapshot history limit error
@@ -505,6 +533,9 @@ func (suite *Suite) TestGetLatestSealedBlockHeader() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// query the handler for the latest sealed block
@@ -549,6 +580,9 @@ func (suite *Suite) TestGetTransaction() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	actual, err := backend.GetTransaction(context.Background(), transaction.ID())
@@ -588,6 +622,9 @@ func (suite *Suite) TestGetCollection() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	actual, err := backend.GetCollectionByID(context.Background(), expected.ID())
@@ -650,6 +687,9 @@ func (suite *Suite) TestGetTransactionResultByIndex() {
 		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 	suite.execClient.
 		On("GetTransactionResultByIndex", ctx, &exeEventReq).
@@ -712,6 +752,9 @@ func (suite *Suite) TestGetTransactionResultsByBlockID() {
 		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 	suite.execClient.
 		On("GetTransactionResultsByBlockID", ctx, &exeEventReq).
@@ -797,6 +840,9 @@ func (suite *Suite) TestTransactionStatusTransition() {
 		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// Successfully return empty event list
@@ -916,6 +962,9 @@ func (suite *Suite) TestTransactionExpiredStatusTransition() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// should return pending status when we have not observed an expiry block
@@ -1074,6 +1123,9 @@ func (suite *Suite) TestTransactionPendingToFinalizedStatusTransition() {
 		flow.IdentifierList(enIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
@@ -1131,6 +1183,9 @@ func (suite *Suite) TestTransactionResultUnknown() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// first call - when block under test is greater height than the sealed head, but execution node does not know about Tx
@@ -1141,6 +1196,271 @@ func (suite *Suite) TestTransactionResultUnknown() {
 	suite.Assert().Equal(flow.TransactionStatusUnknown, result.Status)
 }
 
+// TestSubscribeTransactionStatuses tests that all status transitions of a transaction are streamed in
+// order, including transitions skipped between two finalized blocks, and that the subscription is
+// closed once the transaction is sealed.
+func (suite *Suite) TestSubscribeTransactionStatuses() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	collection := unittest.CollectionFixture(1)
+	transactionBody := collection.Transactions[0]
+	block := unittest.BlockFixture()
+	block.Header.Height = 2
+	headBlock := unittest.BlockFixture()
+
+	// head is behind the current block until the transaction was executed
+	executed := atomic.NewBool(false)
+	suite.snapshot.
+		On("Head").
+		Return(func() *flow.Header {
+			header := *headBlock.Header
+			header.Height = block.Header.Height - 1
+			if executed.Load() {
+				header.Height = block.Header.Height + 1
+			}
+			return &header
+		}, nil)
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+
+	light := collection.Light()
+	suite.transactions.
+		On("ByID", transactionBody.ID()).
+		Return(transactionBody, nil)
+	suite.collections.
+		On("LightByTransactionID", transactionBody.ID()).
+		Return(&light, nil)
+	suite.blocks.
+		On("ByCollectionID", collection.ID()).
+		Return(&block, nil)
+
+	txID := transactionBody.ID()
+	blockID := block.ID()
+	_, fixedENIDs := suite.setupReceipts(&block)
+	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
+	suite.snapshot.On("Identities", mock.Anything).Return(fixedENIDs, nil)
+
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExecutionAPIClient", mock.Anything).Return(suite.execClient, &mockCloser{}, nil)
+	connFactory.On("InvalidateExecutionAPIClient", mock.Anything)
+
+	exeEventReq := execproto.GetTransactionResultRequest{
+		BlockId:       blockID[:],
+		TransactionId: txID[:],
+	}
+
+	// the execution node knows about the transaction only once it was executed
+	suite.execClient.
+		On("GetTransactionResult", mock.Anything, &exeEventReq).
+		Return(&execproto.GetTransactionResultResponse{}, func(context.Context, *execproto.GetTransactionResultRequest, ...grpc.CallOption) error {
+			if executed.Load() {
+				return nil
+			}
+			return status.Errorf(codes.NotFound, "not found")
+		})
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		suite.blocks,
+		suite.headers,
+		suite.collections,
+		suite.transactions,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		connFactory,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
+	)
+
+	sub := backend.SubscribeTransactionStatuses(ctx, txID)
+
+	receive := func() (*accessapi.TransactionResult, bool) {
+		select {
+		case v, ok := <-sub.Channel():
+			if !ok {
+				return nil, false
+			}
+			result, isResult := v.(*accessapi.TransactionResult)
+			suite.Require().True(isResult, "unexpected response type: %T", v)
+			return result, true
+		case <-time.After(time.Second):
+			suite.FailNow("timed out waiting for transaction status")
+		}
+		return nil, false
+	}
+
+	// the current status is sent right away
+	result, ok := receive()
+	suite.Require().True(ok)
+	suite.Assert().Equal(flow.TransactionStatusFinalized, result.Status)
+	suite.Assert().Equal(blockID, result.BlockID)
+
+	// the transaction is executed and sealed before the next block is finalized
+	executed.Store(true)
+	backend.NotifyFinalizedBlockHeight(block.Header.Height + 1)
+
+	result, ok = receive()
+	suite.Require().True(ok)
+	suite.Assert().Equal(flow.TransactionStatusExecuted, result.Status)
+
+	result, ok = receive()
+	suite.Require().True(ok)
+	suite.Assert().Equal(flow.TransactionStatusSealed, result.Status)
+
+	// no more transitions are possible after the transaction was sealed
+	_, ok = receive()
+	suite.Assert().False(ok)
+	suite.Assert().NoError(sub.Err())
+}
+
+// TestSubscribeTransactionStatuses_ExecutionNodesUnavailable tests that the subscription is kept open
+// while the execution nodes can't be reached, and that the status is re-checked on the next block.
+func (suite *Suite) TestSubscribeTransactionStatuses_ExecutionNodesUnavailable() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	collection := unittest.CollectionFixture(1)
+	transactionBody := collection.Transactions[0]
+	block := unittest.BlockFixture()
+	block.Header.Height = 2
+	headBlock := unittest.BlockFixture()
+
+	// head is behind the current block until the transaction was executed
+	executed := atomic.NewBool(false)
+	suite.snapshot.
+		On("Head").
+		Return(func() *flow.Header {
+			header := *headBlock.Header
+			header.Height = block.Header.Height - 1
+			if executed.Load() {
+				header.Height = block.Header.Height + 1
+			}
+			return &header
+		}, nil)
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+
+	light := collection.Light()
+	suite.transactions.
+		On("ByID", transactionBody.ID()).
+		Return(transactionBody, nil)
+	suite.collections.
+		On("LightByTransactionID", transactionBody.ID()).
+		Return(&light, nil)
+	suite.blocks.
+		On("ByCollectionID", collection.ID()).
+		Return(&block, nil)
+
+	txID := transactionBody.ID()
+	blockID := block.ID()
+	_, fixedENIDs := suite.setupReceipts(&block)
+	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
+	suite.snapshot.On("Identities", mock.Anything).Return(fixedENIDs, nil)
+
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExecutionAPIClient", mock.Anything).Return(suite.execClient, &mockCloser{}, nil)
+	connFactory.On("InvalidateExecutionAPIClient", mock.Anything)
+
+	exeEventReq := execproto.GetTransactionResultRequest{
+		BlockId:       blockID[:],
+		TransactionId: txID[:],
+	}
+
+	// both execution nodes are unavailable the first time they are asked after the transaction was executed
+	failures := atomic.NewInt32(int32(len(fixedENIDs)))
+	suite.execClient.
+		On("GetTransactionResult", mock.Anything, &exeEventReq).
+		Return(&execproto.GetTransactionResultResponse{}, func(context.Context, *execproto.GetTransactionResultRequest, ...grpc.CallOption) error {
+			if !executed.Load() {
+				return status.Errorf(codes.NotFound, "not found")
+			}
+			if failures.Dec() >= 0 {
+				return status.Errorf(codes.Unavailable, "unavailable")
+			}
+			return nil
+		})
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		suite.blocks,
+		suite.headers,
+		suite.collections,
+		suite.transactions,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		connFactory,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
+	)
+
+	sub := backend.SubscribeTransactionStatuses(ctx, txID)
+
+	receive := func() (*accessapi.TransactionResult, bool) {
+		select {
+		case v, ok := <-sub.Channel():
+			if !ok {
+				return nil, false
+			}
+			result, isResult := v.(*accessapi.TransactionResult)
+			suite.Require().True(isResult, "unexpected response type: %T", v)
+			return result, true
+		case <-time.After(time.Second):
+			suite.FailNow("timed out waiting for transaction status")
+		}
+		return nil, false
+	}
+
+	// the current status is sent right away
+	result, ok := receive()
+	suite.Require().True(ok)
+	suite.Assert().Equal(flow.TransactionStatusFinalized, result.Status)
+	suite.Assert().Equal(blockID, result.BlockID)
+
+	// the transaction is executed, but the execution nodes can't be reached
+	executed.Store(true)
+	backend.NotifyFinalizedBlockHeight(block.Header.Height + 1)
+	suite.Require().Eventually(func() bool {
+		return failures.Load() <= 0
+	}, time.Second, 10*time.Millisecond)
+
+	// the status is checked again once the next block is finalized
+	backend.NotifyFinalizedBlockHeight(block.Header.Height + 2)
+
+	result, ok = receive()
+	suite.Require().True(ok)
+	suite.Assert().Equal(flow.TransactionStatusExecuted, result.Status)
+
+	result, ok = receive()
+	suite.Require().True(ok)
+	suite.Assert().Equal(flow.TransactionStatusSealed, result.Status)
+
+	// no more transitions are possible after the transaction was sealed
+	_, ok = receive()
+	suite.Assert().False(ok)
+	suite.Assert().NoError(sub.Err())
+}
+
 func (suite *Suite) TestGetLatestFinalizedBlock() {
 	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
 	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
@@ -1178,6 +1498,9 @@ func (suite *Suite) TestGetLatestFinalizedBlock() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// query the handler for the latest finalized header
@@ -1306,6 +1629,9 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// execute request
@@ -1337,6 +1663,9 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// execute request with an empty block id list and expect an empty list of events and no error
@@ -1395,6 +1724,9 @@ func (suite *Suite) TestGetExecutionResultByID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// execute request
@@ -1424,6 +1756,9 @@ func (suite *Suite) TestGetExecutionResultByID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// execute request
@@ -1486,6 +1821,9 @@ func (suite *Suite) TestGetExecutionResultByBlockID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// execute request
@@ -1516,6 +1854,9 @@ func (suite *Suite) TestGetExecutionResultByBlockID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// execute request
@@ -1665,6 +2006,9 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		_, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), maxHeight, minHeight)
@@ -1703,6 +2047,9 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		// execute request
@@ -1740,6 +2087,9 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		actualResp, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), minHeight, maxHeight)
@@ -1776,6 +2126,9 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		_, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), minHeight, minHeight+1)
@@ -1812,6 +2165,9 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
+			nil,
+			nil,
 		)
 
 		_, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), minHeight, maxHeight)
@@ -1820,6 +2176,91 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 
 }
 
+func (suite *Suite) TestGetEventsByType() {
+	ctx := context.Background()
+	eventType := string(flow.EventAccountCreated)
+
+	headers := []*flow.Header{
+		unittest.BlockHeaderFixture(unittest.WithHeaderHeight(10)),
+		unittest.BlockHeaderFixture(unittest.WithHeaderHeight(12)),
+	}
+	for _, header := range headers {
+		suite.headers.On("ByBlockID", header.ID()).Return(header, nil)
+	}
+	blockEvents := map[flow.Identifier][]flow.Event{
+		headers[0].ID(): {unittest.EventFixture(flow.EventAccountCreated, 0, 0, unittest.IdentifierFixture(), 0)},
+		headers[1].ID(): {unittest.EventFixture(flow.EventAccountCreated, 1, 0, unittest.IdentifierFixture(), 0)},
+	}
+
+	events := storagemock.NewEvents(suite.T())
+	events.On("EventTypeIndexHeights").Return(uint64(5), uint64(20), nil)
+	events.On("ByBlockIDEventType", mock.Anything, flow.EventAccountCreated).Return(
+		func(blockID flow.Identifier, _ flow.EventType) []flow.Event {
+			return blockEvents[blockID]
+		},
+		nil,
+	)
+
+	backend := backendEvents{
+		headers: suite.headers,
+		log:     suite.log,
+		events:  events,
+	}
+
+	suite.Run("returns a page of blocks with events", func() {
+		events.On("BlockIDsByEventType", flow.EventAccountCreated, uint64(5), uint64(20), uint(2)).
+			Return([]flow.Identifier{headers[0].ID(), headers[1].ID()}, nil).
+			Once()
+
+		// the end height is limited to the latest indexed height
+		page, err := backend.GetEventsByType(ctx, eventType, 5, 100, nil, 2)
+		suite.Require().NoError(err)
+		suite.Require().Len(page.Events, 2)
+		suite.Assert().Equal(headers[0].Height, page.Events[0].BlockHeight)
+		suite.Assert().Equal(blockEvents[headers[0].ID()], page.Events[0].Events)
+		suite.Assert().Equal(headers[1].ID(), page.Events[1].BlockID)
+
+		// a full page has a cursor pointing to the last block
+		suite.Require().NotNil(page.NextCursor)
+		suite.Assert().Equal(headers[1].Height, page.NextCursor.BlockHeight)
+		suite.Assert().Equal(headers[1].ID(), page.NextCursor.BlockID)
+	})
+
+	suite.Run("continues after the cursor", func() {
+		events.On("BlockIDsByEventType", flow.EventAccountCreated, uint64(13), uint64(20), uint(2)).
+			Return([]flow.Identifier{}, nil).
+			Once()
+
+		cursor := &accessapi.EventsCursor{BlockHeight: headers[1].Height, BlockID: headers[1].ID()}
+		page, err := backend.GetEventsByType(ctx, eventType, 5, 20, cursor, 2)
+		suite.Require().NoError(err)
+		suite.Assert().Empty(page.Events)
+		suite.Assert().Nil(page.NextCursor)
+	})
+
+	suite.Run("start height must be indexed", func() {
+		_, err := backend.GetEventsByType(ctx, eventType, 1, 20, nil, 2)
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.OutOfRange, status.Code(err))
+
+		_, err = backend.GetEventsByType(ctx, eventType, 21, 30, nil, 2)
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.OutOfRange, status.Code(err))
+	})
+
+	suite.Run("limit must not exceed the maximum", func() {
+		_, err := backend.GetEventsByType(ctx, eventType, 5, 20, nil, MaxEventsByTypeLimit+1)
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	suite.Run("requires the event type index", func() {
+		_, err := (&backendEvents{}).GetEventsByType(ctx, eventType, 5, 20, nil, 2)
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.Unimplemented, status.Code(err))
+	})
+}
+
 func (suite *Suite) TestGetAccount() {
 	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
 	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
@@ -1888,6 +2329,9 @@ func (suite *Suite) TestGetAccount() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
@@ -1968,6 +2412,9 @@ func (suite *Suite) TestGetAccountAtBlockHeight() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
@@ -2005,6 +2452,9 @@ func (suite *Suite) TestGetNetworkParameters() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	params := backend.GetNetworkParameters(context.Background())
@@ -2183,6 +2633,9 @@ func (suite *Suite) TestExecuteScriptOnExecutionNode() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// mock parameters
@@ -2227,6 +2680,407 @@ func (suite *Suite) TestExecuteScriptOnExecutionNode() {
 	})
 }
 
+// TestExecuteScriptAtBlockHeightLocally tests that scripts are executed with the local script
+// executor when the state is available, and forwarded to the execution nodes otherwise
+func (suite *Suite) TestExecuteScriptAtBlockHeightLocally() {
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
+
+	ctx := context.Background()
+	script := []byte("dummy script")
+	arguments := [][]byte(nil)
+
+	block := unittest.BlockFixture()
+	header := block.Header
+	blockID := header.ID()
+
+	suite.headers.
+		On("ByHeight", header.Height).
+		Return(header, nil)
+
+	receipts, ids := suite.setupReceipts(&block)
+	suite.snapshot.On("Identities", mock.Anything).Return(ids, nil)
+
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExecutionAPIClient", mock.Anything).Return(suite.execClient, &mockCloser{}, nil)
+
+	scriptExecutor := backendmock.NewScriptExecutor(suite.T())
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		suite.results,
+		flow.Testnet,
+		metrics.NewNoopCollector(),
+		connFactory,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		scriptExecutor,
+		nil,
+		nil,
+	)
+
+	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
+
+	suite.Run("executes the script locally", func() {
+		expected := []byte{1, 2, 3}
+		scriptExecutor.
+			On("ExecuteAtBlockHeight", mock.Anything, script, arguments, header.Height).
+			Return(expected, nil).
+			Once()
+
+		res, err := backend.ExecuteScriptAtBlockHeight(ctx, header.Height, script, arguments)
+		suite.Require().NoError(err)
+		suite.Require().Equal(expected, res)
+	})
+
+	suite.Run("script failure returns status code InvalidArgument", func() {
+		scriptExecutor.
+			On("ExecuteAtBlockHeight", mock.Anything, script, arguments, header.Height).
+			Return(nil, execution.NewScriptExecutionErrorf("script failed")).
+			Once()
+
+		_, err := backend.ExecuteScriptAtBlockHeight(ctx, header.Height, script, arguments)
+		suite.Require().Error(err)
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	suite.Run("falls back to execution nodes if the data is not available", func() {
+		scriptExecutor.
+			On("ExecuteAtBlockHeight", mock.Anything, script, arguments, header.Height).
+			Return(nil, execution.ErrDataNotAvailable).
+			Once()
+
+		execReq := &execproto.ExecuteScriptAtBlockIDRequest{
+			BlockId:   blockID[:],
+			Script:    script,
+			Arguments: arguments,
+		}
+		execRes := &execproto.ExecuteScriptAtBlockIDResponse{
+			Value: []byte{4, 5, 6},
+		}
+		suite.execClient.On("ExecuteScriptAtBlockID", ctx, execReq).Return(execRes, nil).Once()
+
+		res, err := backend.ExecuteScriptAtBlockHeight(ctx, header.Height, script, arguments)
+		suite.Require().NoError(err)
+		suite.Require().Equal(execRes.GetValue(), res)
+		suite.execClient.AssertExpectations(suite.T())
+	})
+}
+
+func (suite *Suite) TestExecuteScriptsAtBlockHeight() {
+	ctx := context.Background()
+
+	block := unittest.BlockFixture()
+	header := block.Header
+
+	suite.headers.
+		On("ByHeight", header.Height).
+		Return(header, nil)
+
+	scriptExecutor := backendmock.NewScriptExecutor(suite.T())
+
+	backend := backendScripts{
+		headers:        suite.headers,
+		log:            suite.log,
+		metrics:        metrics.NewNoopCollector(),
+		scriptExecutor: scriptExecutor,
+	}
+
+	scripts := []accessapi.Script{
+		{Code: []byte("script 1")},
+		{Code: []byte("script 2"), Arguments: [][]byte{[]byte("argument")}},
+		{Code: []byte("script 3")},
+	}
+	batch := []execution.Script{
+		{Code: []byte("script 1")},
+		{Code: []byte("script 2"), Arguments: [][]byte{[]byte("argument")}},
+		{Code: []byte("script 3")},
+	}
+
+	suite.Run("executes the batch locally", func() {
+		scriptExecutor.
+			On("ExecuteBatchAtBlockHeight", mock.Anything, batch, header.Height, uint64(BatchComputationLimit)).
+			Return([]execution.ScriptResult{
+				{Value: []byte{1}, ComputationUsed: 10},
+				{Err: execution.NewScriptExecutionErrorf("script failed"), ComputationUsed: 20},
+				{Err: execution.ErrBatchComputationLimitExceeded},
+			}, nil).
+			Once()
+
+		results, err := backend.ExecuteScriptsAtBlockHeight(ctx, header.Height, scripts)
+		suite.Require().NoError(err)
+		suite.Require().Len(results, 3)
+
+		suite.Assert().Equal([]byte{1}, results[0].Value)
+		suite.Assert().NoError(results[0].Err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(results[1].Err))
+		suite.Assert().Equal(codes.ResourceExhausted, status.Code(results[2].Err))
+	})
+
+	suite.Run("rejects empty and oversized batches", func() {
+		_, err := backend.ExecuteScriptsAtBlockHeight(ctx, header.Height, nil)
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(err))
+
+		_, err = backend.ExecuteScriptsAtBlockHeight(ctx, header.Height, make([]accessapi.Script, MaxScriptsPerBatch+1))
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
+// TestExecuteScriptsOnExecutionNodes tests that batches of scripts are forwarded to the execution
+// nodes when the state is not available locally
+func (suite *Suite) TestExecuteScriptsOnExecutionNodes() {
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
+
+	ctx := context.Background()
+
+	block := unittest.BlockFixture()
+	header := block.Header
+	blockID := header.ID()
+
+	suite.headers.
+		On("ByHeight", header.Height).
+		Return(header, nil)
+
+	receipts, ids := suite.setupReceipts(&block)
+	suite.snapshot.On("Identities", mock.Anything).Return(ids, nil)
+
+	scriptExecutor := backendmock.NewScriptExecutor(suite.T())
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		suite.results,
+		flow.Testnet,
+		metrics.NewNoopCollector(),
+		suite.setupConnectionFactory(),
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		scriptExecutor,
+		nil,
+		nil,
+	)
+
+	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
+
+	scripts := []accessapi.Script{
+		{Code: []byte("script 1")},
+		{Code: []byte("script 2"), Arguments: [][]byte{[]byte("argument")}},
+		{Code: []byte("script 3")},
+	}
+
+	suite.Run("executes each script on the execution nodes", func() {
+		scriptExecutor.
+			On("ExecuteBatchAtBlockHeight", mock.Anything, mock.Anything, header.Height, uint64(BatchComputationLimit)).
+			Return(nil, execution.ErrDataNotAvailable).
+			Once()
+
+		for i, script := range scripts {
+			execReq := &execproto.ExecuteScriptAtBlockIDRequest{
+				BlockId:   blockID[:],
+				Script:    script.Code,
+				Arguments: script.Arguments,
+			}
+			if i == 1 {
+				suite.execClient.
+					On("ExecuteScriptAtBlockID", mock.Anything, execReq).
+					Return(nil, status.Error(codes.InvalidArgument, "script failed")).
+					Once()
+				continue
+			}
+			suite.execClient.
+				On("ExecuteScriptAtBlockID", mock.Anything, execReq).
+				Return(&execproto.ExecuteScriptAtBlockIDResponse{Value: []byte{byte(i)}}, nil).
+				Once()
+		}
+
+		results, err := backend.ExecuteScriptsAtBlockHeight(ctx, header.Height, scripts)
+		suite.Require().NoError(err)
+		suite.Require().Len(results, 3)
+
+		suite.Assert().Equal([]byte{0}, results[0].Value)
+		suite.Assert().NoError(results[0].Err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(results[1].Err))
+		suite.Assert().Equal([]byte{2}, results[2].Value)
+		suite.Assert().NoError(results[2].Err)
+		suite.execClient.AssertExpectations(suite.T())
+	})
+
+	suite.Run("rejects oversized batches", func() {
+		scriptExecutor.
+			On("ExecuteBatchAtBlockHeight", mock.Anything, mock.Anything, header.Height, uint64(BatchComputationLimit)).
+			Return(nil, execution.ErrDataNotAvailable).
+			Once()
+
+		_, err := backend.ExecuteScriptsAtBlockHeight(ctx, header.Height, make([]accessapi.Script, MaxScriptsPerExecutionNodeBatch+1))
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func (suite *Suite) TestDryRunTransaction() {
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
+
+	ctx := context.Background()
+
+	block := unittest.BlockFixture()
+	header := block.Header
+	blockID := header.ID()
+	tx := unittest.TransactionBodyFixture()
+
+	receipts, ids := suite.setupReceipts(&block)
+	suite.snapshot.On("Identities", mock.Anything).Return(ids, nil)
+	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
+
+	extendedExecClient := access.NewExtendedExecutionAPIClient(suite.T())
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExtendedExecutionAPIClient", mock.Anything).Return(extendedExecClient, &mockCloser{}, nil)
+
+	exeReq := &extended.DryRunTransactionAtBlockIDRequest{
+		BlockId:                   blockID[:],
+		Transaction:               convert.TransactionToMessage(tx),
+		SkipSignatureVerification: true,
+	}
+
+	events := unittest.BlockEventsFixture(header, 2).Events
+	exeResp := &extended.DryRunTransactionAtBlockIDResponse{
+		ComputationUsed: 42,
+		ComputationBreakdown: []*extended.MeteredKind{{
+			Kind:   uint32(common.ComputationKindStatement),
+			Amount: 40,
+		}},
+		Fees:   100,
+		Events: convert.EventsToMessages(events),
+		StorageDelta: []*extended.RegisterEntry{{
+			Owner: []byte("owner"),
+			Key:   []byte("key"),
+			Value: []byte{1},
+		}},
+		ErrorMessage: "transaction failed",
+	}
+
+	assertExecutionNodeResult := func(result *accessapi.TransactionDryRunResult) {
+		suite.Assert().Equal(blockID, result.BlockID)
+		suite.Assert().Equal(header.Height, result.BlockHeight)
+		suite.Assert().Equal(uint64(42), result.ComputationUsed)
+		suite.Assert().Equal(meter.ComputationBreakdown{common.ComputationKindStatement: 40}, result.ComputationBreakdown)
+		suite.Assert().Equal(uint64(100), result.Fees)
+		suite.Assert().Equal(events, result.Events)
+		suite.Assert().Equal(flow.RegisterEntries{{
+			Key:   flow.NewRegisterID("owner", "key"),
+			Value: []byte{1},
+		}}, result.StorageDelta)
+		suite.Assert().Equal("transaction failed", result.ErrorMessage)
+	}
+
+	suite.Run("dry runs the transaction on the execution nodes without a script executor", func() {
+		backend := backendTransactions{
+			state:             suite.state,
+			executionReceipts: suite.receipts,
+			connFactory:       connFactory,
+			log:               suite.log,
+		}
+
+		suite.snapshot.On("Head").Return(header, nil).Once()
+		extendedExecClient.On("DryRunTransactionAtBlockID", ctx, exeReq).Return(exeResp, nil).Once()
+
+		result, err := backend.DryRunTransaction(ctx, &tx, true)
+		suite.Require().NoError(err)
+		assertExecutionNodeResult(result)
+	})
+
+	suite.Run("dry runs the transaction locally", func() {
+		scriptExecutor := backendmock.NewScriptExecutor(suite.T())
+		backend := backendTransactions{scriptExecutor: scriptExecutor}
+
+		scriptExecutor.
+			On("DryRunAtLatestHeight", mock.Anything, &tx, true).
+			Return(&execution.TransactionDryRunResult{
+				BlockHeader:     header,
+				ComputationUsed: 42,
+				Fees:            100,
+				Events:          events,
+				Err:             fmt.Errorf("transaction failed"),
+			}, nil).
+			Once()
+
+		result, err := backend.DryRunTransaction(ctx, &tx, true)
+		suite.Require().NoError(err)
+
+		suite.Assert().Equal(blockID, result.BlockID)
+		suite.Assert().Equal(header.Height, result.BlockHeight)
+		suite.Assert().Equal(uint64(42), result.ComputationUsed)
+		suite.Assert().Equal(uint64(100), result.Fees)
+		suite.Assert().Equal(events, result.Events)
+		suite.Assert().Equal("transaction failed", result.ErrorMessage)
+	})
+
+	suite.Run("falls back to the execution nodes if the execution state is not indexed", func() {
+		scriptExecutor := backendmock.NewScriptExecutor(suite.T())
+		backend := backendTransactions{
+			state:             suite.state,
+			executionReceipts: suite.receipts,
+			connFactory:       connFactory,
+			scriptExecutor:    scriptExecutor,
+			log:               suite.log,
+		}
+
+		scriptExecutor.
+			On("DryRunAtLatestHeight", mock.Anything, &tx, true).
+			Return(nil, execution.ErrDataNotAvailable).
+			Once()
+		suite.snapshot.On("Head").Return(header, nil).Once()
+		extendedExecClient.On("DryRunTransactionAtBlockID", ctx, exeReq).Return(exeResp, nil).Once()
+
+		result, err := backend.DryRunTransaction(ctx, &tx, true)
+		suite.Require().NoError(err)
+		assertExecutionNodeResult(result)
+	})
+
+	suite.Run("returns the error of the execution nodes for invalid requests", func() {
+		backend := backendTransactions{
+			state:             suite.state,
+			executionReceipts: suite.receipts,
+			connFactory:       connFactory,
+			log:               suite.log,
+		}
+
+		suite.snapshot.On("Head").Return(header, nil).Once()
+		extendedExecClient.
+			On("DryRunTransactionAtBlockID", ctx, exeReq).
+			Return(nil, status.Error(codes.InvalidArgument, "invalid transaction")).
+			Once()
+
+		_, err := backend.DryRunTransaction(ctx, &tx, true)
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
 func (suite *Suite) assertAllExpectations() {
 	suite.snapshot.AssertExpectations(suite.T())
 	suite.state.AssertExpectations(suite.T())
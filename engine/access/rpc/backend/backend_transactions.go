@@ -15,6 +15,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/subscription"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/fvm/blueprints"
 	"github.com/onflow/flow-go/model/flow"
@@ -37,6 +38,10 @@ type backendTransactions struct {
 	transactionValidator *access.TransactionValidator
 	retry                *Retry
 	connFactory          ConnectionFactory
+	scriptExecutor       ScriptExecutor
+
+	// finalizedBroadcaster notifies transaction status subscriptions when a new block is finalized
+	finalizedBroadcaster *subscription.Broadcaster
 
 	previousAccessNodes []accessproto.AccessAPIClient
 	log                 zerolog.Logger
@@ -274,7 +279,8 @@ func (b *backendTransactions) GetTransactionResult(
 		transactionWasExecuted, events, statusCode, txError, err = b.lookupTransactionResult(ctx, txID, blockID)
 		blockHeight = block.Header.Height
 		if err != nil {
-			return nil, convertStorageError(err)
+			// already converted to a status error with the code of the execution nodes
+			return nil, err
 		}
 	}
 
@@ -657,7 +663,8 @@ func (b *backendTransactions) getTransactionResultFromExecutionNode(
 		if status.Code(err) == codes.NotFound {
 			return nil, 0, "", err
 		}
-		return nil, 0, "", status.Errorf(codes.Internal, "failed to retrieve result from execution node: %v", err)
+		// keep the code of the execution node errors, so that callers can tell whether they are transient
+		return nil, 0, "", status.Errorf(executionNodesErrorCode(err), "failed to retrieve result from execution node: %v", err)
 	}
 
 	events := convert.MessagesToEvents(resp.GetEvents())
@@ -665,8 +672,29 @@ func (b *backendTransactions) getTransactionResultFromExecutionNode(
 	return events, resp.GetStatusCode(), resp.GetErrorMessage(), nil
 }
 
+// executionNodesErrorCode returns the code of the errors returned by the execution nodes
+// if all of them have the same code, or codes.Internal otherwise.
+func executionNodesErrorCode(err error) codes.Code {
+	var errs *multierror.Error
+	if !errors.As(err, &errs) || len(errs.Errors) == 0 {
+		return codes.Internal
+	}
+
+	code := status.Code(errs.Errors[0])
+	for _, err := range errs.Errors[1:] {
+		if status.Code(err) != code {
+			return codes.Internal
+		}
+	}
+	if code == codes.Unknown {
+		return codes.Internal
+	}
+	return code
+}
+
 func (b *backendTransactions) NotifyFinalizedBlockHeight(height uint64) {
 	b.retry.Retry(height)
+	b.finalizedBroadcaster.Publish()
 }
 
 func (b *backendTransactions) getTransactionResultFromAnyExeNode(
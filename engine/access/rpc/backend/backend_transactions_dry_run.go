@@ -0,0 +1,169 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/onflow/cadence/runtime/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	"github.com/onflow/flow-go/fvm/meter"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/execution"
+)
+
+// DryRunTransaction executes the transaction against the latest sealed execution state, without
+// committing its changes, and returns the resources it used, the fees the payer would be charged
+// and the events it emitted. If skipSignatureVerification is set, the transaction does not need to
+// be signed.
+//
+// The transaction is executed locally if the node indexes the execution state, otherwise it is
+// forwarded to the execution nodes which executed the latest sealed block.
+func (b *backendTransactions) DryRunTransaction(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+	skipSignatureVerification bool,
+) (*access.TransactionDryRunResult, error) {
+	if b.scriptExecutor != nil {
+		result, err := b.scriptExecutor.DryRunAtLatestHeight(ctx, tx, skipSignatureVerification)
+		if err == nil {
+			return convertDryRunResult(result), nil
+		}
+
+		if !errors.Is(err, execution.ErrDataNotAvailable) {
+			txID := tx.ID()
+			b.log.Error().Err(err).
+				Hex("transaction_id", txID[:]).
+				Msg("failed to dry run transaction locally, falling back to execution nodes")
+		}
+	}
+
+	return b.dryRunTransactionOnExecutionNode(ctx, tx, skipSignatureVerification)
+}
+
+// dryRunTransactionOnExecutionNode forwards the dry run to the execution nodes which executed the
+// latest sealed block, until one of them executes it.
+func (b *backendTransactions) dryRunTransactionOnExecutionNode(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+	skipSignatureVerification bool,
+) (*access.TransactionDryRunResult, error) {
+	latestHeader, err := b.state.Sealed().Head()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
+	}
+	blockID := latestHeader.ID()
+
+	// find few execution nodes which have executed the block earlier and provided an execution receipt for it
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find execution nodes at blockId %v: %v", blockID.String(), err)
+	}
+
+	req := &extended.DryRunTransactionAtBlockIDRequest{
+		BlockId:                   blockID[:],
+		Transaction:               convert.TransactionToMessage(*tx),
+		SkipSignatureVerification: skipSignatureVerification,
+	}
+
+	var errs *multierror.Error
+	for _, execNode := range execNodes {
+		resp, err := b.tryDryRunTransaction(ctx, execNode, req)
+		if err == nil {
+			return messageToDryRunResult(latestHeader, resp), nil
+		}
+		// the request itself is invalid, the other execution nodes would reject it as well
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		errs = multierror.Append(errs, err)
+	}
+
+	errToReturn := errs.ErrorOrNil()
+	if errToReturn == nil {
+		return nil, status.Errorf(codes.Unavailable, "no execution nodes found for block %v", blockID)
+	}
+	b.log.Error().Err(errToReturn).Msg("transaction dry run failed for execution node internal reasons")
+	return nil, status.Errorf(codes.Internal, "failed to dry run transaction on the execution nodes: %v", errToReturn)
+}
+
+func (b *backendTransactions) tryDryRunTransaction(
+	ctx context.Context,
+	execNode *flow.Identity,
+	req *extended.DryRunTransactionAtBlockIDRequest,
+) (*extended.DryRunTransactionAtBlockIDResponse, error) {
+	execRPCClient, closer, err := b.connFactory.GetExtendedExecutionAPIClient(execNode.Address)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create client for execution node %s: %v", execNode.String(), err)
+	}
+	defer closer.Close()
+
+	resp, err := execRPCClient.DryRunTransactionAtBlockID(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.Unavailable {
+			b.connFactory.InvalidateExecutionAPIClient(execNode.Address)
+		}
+		return nil, status.Errorf(status.Code(err), "failed to dry run transaction on the execution node %s: %v", execNode.String(), err)
+	}
+	return resp, nil
+}
+
+// convertDryRunResult converts the result of a dry run executed locally into the access API format.
+func convertDryRunResult(result *execution.TransactionDryRunResult) *access.TransactionDryRunResult {
+	dryRunResult := &access.TransactionDryRunResult{
+		BlockID:              result.BlockHeader.ID(),
+		BlockHeight:          result.BlockHeader.Height,
+		ComputationUsed:      result.ComputationUsed,
+		MemoryEstimate:       result.MemoryEstimate,
+		ComputationBreakdown: result.ComputationBreakdown,
+		MemoryBreakdown:      result.MemoryBreakdown,
+		Fees:                 result.Fees,
+		Events:               result.Events,
+		StorageDelta:         result.StorageDelta,
+	}
+	if result.Err != nil {
+		dryRunResult.ErrorMessage = result.Err.Error()
+	}
+
+	return dryRunResult
+}
+
+// messageToDryRunResult converts the response of a dry run executed by an execution node against
+// the state of the block with the given header into the access API format.
+func messageToDryRunResult(header *flow.Header, resp *extended.DryRunTransactionAtBlockIDResponse) *access.TransactionDryRunResult {
+	computationBreakdown := make(meter.ComputationBreakdown, len(resp.GetComputationBreakdown()))
+	for _, used := range resp.GetComputationBreakdown() {
+		computationBreakdown[common.ComputationKind(used.GetKind())] = used.GetAmount()
+	}
+
+	memoryBreakdown := make(meter.MemoryBreakdown, len(resp.GetMemoryBreakdown()))
+	for _, estimate := range resp.GetMemoryBreakdown() {
+		memoryBreakdown[common.MemoryKind(estimate.GetKind())] = estimate.GetAmount()
+	}
+
+	storageDelta := make(flow.RegisterEntries, len(resp.GetStorageDelta()))
+	for i, entry := range resp.GetStorageDelta() {
+		storageDelta[i] = flow.RegisterEntry{
+			Key:   flow.NewRegisterID(string(entry.GetOwner()), string(entry.GetKey())),
+			Value: entry.GetValue(),
+		}
+	}
+
+	return &access.TransactionDryRunResult{
+		BlockID:              header.ID(),
+		BlockHeight:          header.Height,
+		ComputationUsed:      resp.GetComputationUsed(),
+		MemoryEstimate:       resp.GetMemoryEstimate(),
+		ComputationBreakdown: computationBreakdown,
+		MemoryBreakdown:      memoryBreakdown,
+		Fees:                 resp.GetFees(),
+		Events:               convert.MessagesToEvents(resp.GetEvents()),
+		StorageDelta:         storageDelta,
+		ErrorMessage:         resp.GetErrorMessage(),
+	}
+}
@@ -0,0 +1,143 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// SubscribeTransactionStatuses streams the status transitions of the given transaction, starting
+// with its current status. A new result is sent each time the status changes. Intermediate statuses
+// that were skipped between two checks (e.g. a transaction that went from finalized to sealed
+// within a single block) are sent as well, so clients always observe the transitions in order.
+//
+// The subscription is closed after the transaction is sealed or expired.
+// The status is re-checked each time a new block is finalized.
+func (b *backendTransactions) SubscribeTransactionStatuses(ctx context.Context, txID flow.Identifier) subscription.Subscription {
+	finalized, err := b.state.Final().Head()
+	if err != nil {
+		return subscription.NewFailedSubscription(err, "could not get latest finalized block")
+	}
+
+	sub := &transactionStatusSubscription{
+		SubscriptionImpl: subscription.NewSubscription(),
+		txID:             txID,
+		backend:          b,
+		startHeight:      finalized.Height,
+		lastStatus:       flow.TransactionStatusUnknown,
+	}
+
+	go subscription.NewStreamer(b.log, b.finalizedBroadcaster, subscription.DefaultSendTimeout, sub).Stream(ctx)
+
+	return sub
+}
+
+var _ subscription.Streamable = (*transactionStatusSubscription)(nil)
+
+// transactionStatusSubscription is a subscription that sends a transaction result each time the
+// status of the transaction changes.
+type transactionStatusSubscription struct {
+	*subscription.SubscriptionImpl
+
+	txID    flow.Identifier
+	backend *backendTransactions
+
+	// startHeight is the latest finalized height when the subscription was created
+	startHeight uint64
+	// lastStatus is the status of the last result queued for the client
+	lastStatus flow.TransactionStatus
+	// queued contains results for status transitions that were not sent yet
+	queued []*access.TransactionResult
+}
+
+// Next returns the next status transition of the transaction.
+// Expected errors:
+// - subscription.ErrBlockNotReady if the status did not change since the last check
+// - subscription.ErrEndOfData if the final status was already sent
+// - codes.NotFound if the transaction was not found within the expiry window
+func (s *transactionStatusSubscription) Next(ctx context.Context) (interface{}, error) {
+	if len(s.queued) > 0 {
+		result := s.queued[0]
+		s.queued = s.queued[1:]
+		return result, nil
+	}
+
+	if isFinalTransactionStatus(s.lastStatus) {
+		return nil, subscription.ErrEndOfData
+	}
+
+	result, err := s.backend.GetTransactionResult(ctx, s.txID)
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded:
+			// the execution nodes could not be reached (see executionNodesErrorCode). retry on the next block
+			return nil, fmt.Errorf("could not get transaction result: %v: %w", err, subscription.ErrBlockNotReady)
+		default:
+			return nil, err
+		}
+	}
+
+	if result.Status == flow.TransactionStatusUnknown {
+		// the transaction may have been submitted to a different node, in which case it becomes
+		// known once its collection is received. give up once it could no longer be included.
+		finalized, err := s.backend.state.Final().Head()
+		if err != nil {
+			return nil, fmt.Errorf("could not get latest finalized block: %w", err)
+		}
+		if s.backend.isExpired(s.startHeight, finalized.Height) {
+			return nil, status.Errorf(codes.NotFound, "transaction %v not found", s.txID)
+		}
+		return nil, fmt.Errorf("transaction %v not known yet: %w", s.txID, subscription.ErrBlockNotReady)
+	}
+
+	if result.Status <= s.lastStatus {
+		return nil, fmt.Errorf("transaction %v status unchanged: %w", s.txID, subscription.ErrBlockNotReady)
+	}
+
+	s.queued = transactionStatusTransitions(s.lastStatus, result)
+	s.lastStatus = result.Status
+
+	next := s.queued[0]
+	s.queued = s.queued[1:]
+	return next, nil
+}
+
+// transactionStatusTransitions returns the results for all status transitions between the previous
+// status and the status of the given result, in order. Results for skipped intermediate statuses are
+// derived from the given result.
+// No intermediate results are generated for the first status sent to the client, since it only
+// observes the transaction from that point on, nor for expired transactions, which never went
+// through any other status than pending.
+func transactionStatusTransitions(previous flow.TransactionStatus, result *access.TransactionResult) []*access.TransactionResult {
+	if previous == flow.TransactionStatusUnknown || result.Status == flow.TransactionStatusExpired {
+		return []*access.TransactionResult{result}
+	}
+
+	transitions := make([]*access.TransactionResult, 0, result.Status-previous)
+	for txStatus := previous + 1; txStatus < result.Status; txStatus++ {
+		intermediate := *result
+		intermediate.Status = txStatus
+
+		if txStatus < flow.TransactionStatusExecuted {
+			// execution results are not available before the transaction was executed
+			intermediate.StatusCode = 0
+			intermediate.Events = nil
+			intermediate.ErrorMessage = ""
+		}
+
+		transitions = append(transitions, &intermediate)
+	}
+
+	return append(transitions, result)
+}
+
+// isFinalTransactionStatus returns true if the status of a transaction can no longer change.
+func isFinalTransactionStatus(txStatus flow.TransactionStatus) bool {
+	return txStatus == flow.TransactionStatusSealed || txStatus == flow.TransactionStatusExpired
+}
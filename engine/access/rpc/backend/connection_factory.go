@@ -17,6 +17,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/utils/grpcutils"
 )
@@ -29,6 +30,9 @@ type ConnectionFactory interface {
 	GetAccessAPIClient(address string) (access.AccessAPIClient, io.Closer, error)
 	InvalidateAccessAPIClient(address string)
 	GetExecutionAPIClient(address string) (execution.ExecutionAPIClient, io.Closer, error)
+	// GetExtendedExecutionAPIClient returns a client of the extended execution API, which shares the
+	// connection of the execution API client, so InvalidateExecutionAPIClient invalidates both.
+	GetExtendedExecutionAPIClient(address string) (extended.ExtendedExecutionAPIClient, io.Closer, error)
 	InvalidateExecutionAPIClient(address string)
 }
 
@@ -51,6 +55,10 @@ func (p *ProxyConnectionFactory) GetExecutionAPIClient(address string) (executio
 	return p.ConnectionFactory.GetExecutionAPIClient(p.targetAddress)
 }
 
+func (p *ProxyConnectionFactory) GetExtendedExecutionAPIClient(address string) (extended.ExtendedExecutionAPIClient, io.Closer, error) {
+	return p.ConnectionFactory.GetExtendedExecutionAPIClient(p.targetAddress)
+}
+
 type ConnectionFactoryImpl struct {
 	CollectionGRPCPort        uint
 	ExecutionGRPCPort         uint
@@ -204,6 +212,32 @@ func (cf *ConnectionFactoryImpl) GetExecutionAPIClient(address string) (executio
 	return executionAPIClient, closer, nil
 }
 
+func (cf *ConnectionFactoryImpl) GetExtendedExecutionAPIClient(address string) (extended.ExtendedExecutionAPIClient, io.Closer, error) {
+
+	grpcAddress, err := getGRPCAddress(address, cf.ExecutionGRPCPort)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var conn *grpc.ClientConn
+	if cf.ConnectionsCache != nil {
+		conn, err = cf.retrieveConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout)
+		if err != nil {
+			return nil, nil, err
+		}
+		return extended.NewExtendedExecutionAPIClient(conn), &noopCloser{}, nil
+	}
+
+	conn, err = cf.createConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	extendedExecutionAPIClient := extended.NewExtendedExecutionAPIClient(conn)
+	closer := io.Closer(conn)
+	return extendedExecutionAPIClient, closer, nil
+}
+
 func (cf *ConnectionFactoryImpl) InvalidateExecutionAPIClient(address string) {
 	if cf.ConnectionsCache != nil {
 		cf.Log.Debug().Str("cached_execution_client_invalidated", address).Msg("invalidating cached execution client")
@@ -55,6 +55,9 @@ func (suite *Suite) TestHistoricalTransactionResult() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// Successfully return the transaction from the historical node
@@ -112,6 +115,9 @@ func (suite *Suite) TestHistoricalTransaction() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 
 	// Successfully return the transaction from the historical node
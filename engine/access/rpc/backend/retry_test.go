@@ -60,6 +60,9 @@ func (suite *Suite) TestTransactionRetry() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 	retry := newRetry().SetBackend(backend).Activate()
 	backend.retry = retry
@@ -140,6 +143,9 @@ func (suite *Suite) TestSuccessfulTransactionsDontRetry() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
+		nil,
+		nil,
 	)
 	retry := newRetry().SetBackend(backend).Activate()
 	backend.retry = retry
@@ -19,6 +19,7 @@ import (
 	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/access/rest"
 	"github.com/onflow/flow-go/engine/access/rpc/backend"
+	"github.com/onflow/flow-go/engine/access/state_stream"
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
@@ -61,6 +62,10 @@ type Engine struct {
 	config             Config
 	chain              flow.Chain
 
+	// optional state stream API, used to serve the streaming REST endpoints
+	stateStreamAPI    state_stream.API
+	stateStreamConfig state_stream.Config
+
 	addrLock            sync.RWMutex
 	unsecureGrpcAddress net.Addr
 	secureGrpcAddress   net.Addr
@@ -88,6 +93,9 @@ func NewBuilder(log zerolog.Logger,
 	rpcMetricsEnabled bool,
 	apiRatelimits map[string]int, // the api rate limit (max calls per second) for each of the Access API e.g. Ping->100, GetTransaction->300
 	apiBurstLimits map[string]int, // the api burst limit (max calls at the same time) for each of the Access API e.g. Ping->50, GetTransaction->10
+	scriptExecutor backend.ScriptExecutor, // optional local script executor, scripts are only executed on execution nodes if nil
+	accountTransactions storage.AccountTransactions, // optional account transaction index, GetTransactionsByAccount is unavailable if nil
+	events storage.Events, // optional local event type index, GetEventsByType is unavailable if nil
 ) (*RPCEngineBuilder, error) {
 
 	log = log.With().Str("engine", "rpc").Logger()
@@ -185,6 +193,9 @@ func NewBuilder(log zerolog.Logger,
 		config.FixedExecutionNodeIDs,
 		log,
 		backend.DefaultSnapshotHistoryLimit,
+		scriptExecutor,
+		accountTransactions,
+		events,
 	)
 
 	eng := &Engine{
@@ -352,7 +363,7 @@ func (e *Engine) serveREST() {
 
 	e.log.Info().Str("rest_api_address", e.config.RESTListenAddr).Msg("starting REST server on address")
 
-	r, err := rest.NewServer(e.backend, e.config.RESTListenAddr, e.log, e.chain)
+	r, err := rest.NewServer(e.backend, e.config.RESTListenAddr, e.log, e.chain, e.stateStreamAPI, e.stateStreamConfig)
 	if err != nil {
 		e.log.Err(err).Msg("failed to initialize the REST server")
 		return
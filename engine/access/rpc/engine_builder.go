@@ -6,9 +6,11 @@ import (
 	legacyaccessproto "github.com/onflow/flow/protobuf/go/flow/legacy/access"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/access/extended"
 	legacyaccess "github.com/onflow/flow-go/access/legacy"
 	"github.com/onflow/flow-go/consensus/hotstuff"
 	"github.com/onflow/flow-go/consensus/hotstuff/signature"
+	"github.com/onflow/flow-go/engine/access/state_stream"
 )
 
 type RPCEngineBuilder struct {
@@ -61,6 +63,15 @@ func (builder *RPCEngineBuilder) WithLegacy() *RPCEngineBuilder {
 	return builder
 }
 
+// WithStateStreamAPI specifies that the streaming endpoints should be served by the REST server
+// using the given state stream API.
+// Returns self-reference for chaining.
+func (builder *RPCEngineBuilder) WithStateStreamAPI(api state_stream.API, config state_stream.Config) *RPCEngineBuilder {
+	builder.stateStreamAPI = api
+	builder.stateStreamConfig = config
+	return builder
+}
+
 // WithMetrics specifies the metrics should be collected.
 // Returns self-reference for chaining.
 func (builder *RPCEngineBuilder) WithMetrics() *RPCEngineBuilder {
@@ -74,5 +85,10 @@ func (builder *RPCEngineBuilder) WithMetrics() *RPCEngineBuilder {
 func (builder *RPCEngineBuilder) Build() *Engine {
 	accessproto.RegisterAccessAPIServer(builder.unsecureGrpcServer, builder.handler)
 	accessproto.RegisterAccessAPIServer(builder.secureGrpcServer, builder.handler)
+
+	extendedHandler := access.NewExtendedHandler(builder.backend, builder.chain, builder.stateStreamAPI, builder.stateStreamConfig)
+	extended.RegisterExtendedAccessAPIServer(builder.unsecureGrpcServer, extendedHandler)
+	extended.RegisterExtendedAccessAPIServer(builder.secureGrpcServer, extendedHandler)
+
 	return builder.Engine
 }
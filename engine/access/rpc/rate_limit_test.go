@@ -109,7 +109,7 @@ func (suite *RateLimitTestSuite) SetupTest() {
 	}
 
 	rpcEngBuilder, err := NewBuilder(suite.log, suite.state, config, suite.collClient, nil, suite.blocks, suite.headers, suite.collections, suite.transactions,
-		nil, nil, suite.chainID, suite.metrics, suite.metrics, 0, 0, false, false, apiRateLimt, apiBurstLimt)
+		nil, nil, suite.chainID, suite.metrics, suite.metrics, 0, 0, false, false, apiRateLimt, apiBurstLimt, nil, nil, nil)
 	rpcEngBuilder.WithLegacy()
 	suite.rpcEng = rpcEngBuilder.Build()
 	assert.NoError(suite.T(), err)
@@ -0,0 +1,228 @@
+package state_stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/engine/consensus/sealing/counters"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// Config defines the configurable options for the state stream backend.
+type Config struct {
+	EventFilterConfig
+
+	// ClientSendTimeout is the timeout for sending a message to the client. After the timeout,
+	// the stream is closed with an error.
+	ClientSendTimeout time.Duration
+}
+
+// DefaultConfig is the default configuration for the state stream backend.
+var DefaultConfig = Config{
+	EventFilterConfig: DefaultEventFilterConfig,
+	ClientSendTimeout: subscription.DefaultSendTimeout,
+}
+
+// API provides streaming access to data derived from execution data synced by the node.
+type API interface {
+	// SubscribeEvents streams events for all blocks starting at the specified block ID or height
+	// up until the latest available block. Once the latest block is reached, the stream will
+	// remain open and responses are sent for each new block as it becomes available.
+	//
+	// Only one of startBlockID and startHeight may be set. If neither is set, the stream starts
+	// from the latest block with available execution data. Each response contains the events of
+	// one block, even if no events matched the filter, so clients can resume a broken stream by
+	// subscribing again from the height after the last one received.
+	SubscribeEvents(ctx context.Context, startBlockID flow.Identifier, startHeight uint64, filter EventFilter) subscription.Subscription
+}
+
+var _ API = (*StateStreamBackend)(nil)
+
+// StateStreamBackend implements the state stream API using the execution data downloaded by the
+// node's execution data requester.
+type StateStreamBackend struct {
+	log           zerolog.Logger
+	headers       storage.Headers
+	seals         storage.Seals
+	results       storage.ExecutionResults
+	execDataStore execution_data.ExecutionDataStore
+	broadcaster   *subscription.Broadcaster
+	sendTimeout   time.Duration
+
+	// rootHeight is the lowest height with available execution data
+	rootHeight uint64
+	// highestHeight is the highest height with available execution data
+	highestHeight counters.StrictMonotonousCounter
+}
+
+// New creates a new StateStreamBackend.
+// rootHeight is the first height for which execution data is synced, and highestAvailableHeight is
+// the highest height for which execution data was already downloaded when the node started.
+func New(
+	log zerolog.Logger,
+	config Config,
+	headers storage.Headers,
+	seals storage.Seals,
+	results storage.ExecutionResults,
+	execDataStore execution_data.ExecutionDataStore,
+	rootHeight uint64,
+	highestAvailableHeight uint64,
+) *StateStreamBackend {
+	return &StateStreamBackend{
+		log:           log.With().Str("module", "state_stream_api").Logger(),
+		headers:       headers,
+		seals:         seals,
+		results:       results,
+		execDataStore: execDataStore,
+		broadcaster:   subscription.NewBroadcaster(),
+		sendTimeout:   config.ClientSendTimeout,
+		rootHeight:    rootHeight,
+		highestHeight: counters.NewMonotonousCounter(highestAvailableHeight),
+	}
+}
+
+// OnExecutionData is called to notify the backend that new execution data has been received.
+// It is registered as a consumer of the ExecutionDataRequester, which delivers execution data in
+// height order.
+func (b *StateStreamBackend) OnExecutionData(executionData *execution_data.BlockExecutionData) error {
+	header, err := b.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		// if the block is not found here, the execution data requester processed a block that
+		// the node does not know about, which indicates a corrupted state.
+		b.log.Fatal().Err(err).Str("block_id", executionData.BlockID.String()).Msg("failed to get header for execution data")
+		return nil
+	}
+
+	b.log.Trace().
+		Hex("block_id", logging.ID(executionData.BlockID)).
+		Uint64("height", header.Height).
+		Msg("received execution data")
+
+	_ = b.highestHeight.Set(header.Height)
+	b.broadcaster.Publish()
+
+	return nil
+}
+
+// SubscribeEvents streams the events of each block matching the given filter, starting at the
+// given start block. See API.SubscribeEvents for details.
+func (b *StateStreamBackend) SubscribeEvents(ctx context.Context, startBlockID flow.Identifier, startHeight uint64, filter EventFilter) subscription.Subscription {
+	nextHeight, err := b.getStartHeight(startBlockID, startHeight)
+	if err != nil {
+		return subscription.NewFailedSubscription(err, "could not get start height")
+	}
+
+	sub := subscription.NewHeightBasedSubscription(nextHeight, b.getEventsResponseFactory(filter))
+
+	go subscription.NewStreamer(b.log, b.broadcaster, b.sendTimeout, sub).Stream(ctx)
+
+	return sub
+}
+
+// getEventsResponseFactory returns a function that retrieves the filtered events for a given height
+func (b *StateStreamBackend) getEventsResponseFactory(filter EventFilter) subscription.GetDataByHeightFunc {
+	return func(ctx context.Context, height uint64) (interface{}, error) {
+		header, executionData, err := b.getExecutionDataByHeight(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+
+		events := flow.EventsList{}
+		for _, chunkExecutionData := range executionData.ChunkExecutionDatas {
+			events = append(events, filter.Filter(chunkExecutionData.Events)...)
+		}
+
+		b.log.Trace().
+			Hex("block_id", logging.ID(header.ID())).
+			Uint64("height", header.Height).
+			Int("events", len(events)).
+			Msg("sending events")
+
+		return &flow.BlockEvents{
+			BlockID:        header.ID(),
+			BlockHeight:    header.Height,
+			BlockTimestamp: header.Timestamp,
+			Events:         events,
+		}, nil
+	}
+}
+
+// getExecutionDataByHeight returns the header and execution data for the block at the given height.
+// Expected errors:
+// - subscription.ErrBlockNotReady if the execution data for the height is not available yet
+func (b *StateStreamBackend) getExecutionDataByHeight(ctx context.Context, height uint64) (*flow.Header, *execution_data.BlockExecutionData, error) {
+	if height > b.highestHeight.Value() {
+		return nil, nil, fmt.Errorf("execution data for block %d is not available yet: %w", height, subscription.ErrBlockNotReady)
+	}
+
+	header, err := b.headers.ByHeight(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get header for height %d: %w", height, err)
+	}
+
+	seal, err := b.seals.FinalizedSealForBlock(header.ID())
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get finalized seal for block %s: %w", header.ID(), err)
+	}
+
+	result, err := b.results.ByID(seal.ResultID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get execution result (id: %s): %w", seal.ResultID, err)
+	}
+
+	executionData, err := b.execDataStore.GetExecutionData(ctx, result.ExecutionDataID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get execution data (id: %s): %w", result.ExecutionDataID, err)
+	}
+
+	return header, executionData, nil
+}
+
+// getStartHeight returns the start height to use when searching.
+// Only one of startBlockID and startHeight may be set. Otherwise, an InvalidArgument error is returned.
+// If a block is provided and does not exist, a NotFound error is returned.
+// If neither startBlockID nor startHeight is provided, the latest available height is used.
+func (b *StateStreamBackend) getStartHeight(startBlockID flow.Identifier, startHeight uint64) (uint64, error) {
+	// make sure only one of start block ID and start height is provided
+	if startBlockID != flow.ZeroID && startHeight > 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "only one of start block ID and start height may be provided")
+	}
+
+	// if no start is provided, start from the latest available block
+	if startBlockID == flow.ZeroID && startHeight == 0 {
+		highestHeight := b.highestHeight.Value()
+		if highestHeight < b.rootHeight {
+			// no execution data is available yet, start from the first block that will have it
+			return b.rootHeight, nil
+		}
+		return highestHeight, nil
+	}
+
+	if startBlockID != flow.ZeroID {
+		header, err := b.headers.ByBlockID(startBlockID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return 0, status.Errorf(codes.NotFound, "could not get header for block %v: %v", startBlockID, err)
+			}
+			return 0, status.Errorf(codes.Internal, "could not get header for block %v: %v", startBlockID, err)
+		}
+		startHeight = header.Height
+	}
+
+	// heights below the root height have no execution data available on this node
+	if startHeight < b.rootHeight {
+		return 0, status.Errorf(codes.InvalidArgument, "start height must be greater than or equal to the root height %d", b.rootHeight)
+	}
+
+	return startHeight, nil
+}
@@ -0,0 +1,108 @@
+package state_stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+var eventTypes = map[flow.EventType]bool{
+	"flow.AccountCreated":                  true,
+	"flow.AccountKeyAdded":                 true,
+	"A.0x1.Foo.Bar":                        true,
+	"A.0x2.Zoo.Moo":                        true,
+	"A.0x3.Goo.Hoo":                        true,
+	"A.0000000000000001.Contract1.EventA":  true,
+	"A.0000000000000001.Contract1.EventB":  true,
+	"A.0000000000000002.Contract2.EventA":  true,
+	"invalid.event.type.with.extra.parts":  false,
+	"A.0000000000000001.Contract1":         false,
+	"flow":                                 false,
+	"B.0000000000000001.Contract1.EventA1": false,
+}
+
+func TestParseEvent(t *testing.T) {
+	t.Parallel()
+
+	for eventType, valid := range eventTypes {
+		parsed, err := state_stream.ParseEvent(eventType)
+		if !valid {
+			assert.Error(t, err, "expected error for %s", eventType)
+			continue
+		}
+		require.NoError(t, err, "unexpected error for %s", eventType)
+		assert.Equal(t, eventType, parsed.EventType)
+	}
+
+	parsed, err := state_stream.ParseEvent("A.0000000000000001.Contract1.EventA")
+	require.NoError(t, err)
+	assert.Equal(t, state_stream.AccountEventType, parsed.Type)
+	assert.Equal(t, "0000000000000001", parsed.Address)
+	assert.Equal(t, "A.0000000000000001.Contract1", parsed.Contract)
+	assert.Equal(t, "Contract1", parsed.ContractName)
+	assert.Equal(t, "EventA", parsed.Name)
+
+	parsed, err = state_stream.ParseEvent("flow.AccountCreated")
+	require.NoError(t, err)
+	assert.Equal(t, state_stream.ProtocolEventType, parsed.Type)
+	assert.Equal(t, "flow", parsed.Contract)
+	assert.Equal(t, "AccountCreated", parsed.Name)
+}
+
+func TestEventFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	chain := flow.MonotonicEmulator.Chain()
+	address1 := flow.HexToAddress("0000000000000001").String()
+
+	events := flow.EventsList{
+		unittest.EventFixture("flow.AccountCreated", 0, 0, unittest.IdentifierFixture(), 0),
+		unittest.EventFixture("A.0000000000000001.Contract1.EventA", 0, 1, unittest.IdentifierFixture(), 0),
+		unittest.EventFixture("A.0000000000000001.Contract1.EventB", 0, 2, unittest.IdentifierFixture(), 0),
+		unittest.EventFixture("A.0000000000000002.Contract2.EventA", 0, 3, unittest.IdentifierFixture(), 0),
+	}
+
+	t.Run("empty filter matches all events", func(t *testing.T) {
+		filter, err := state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, nil, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, events, filter.Filter(events))
+	})
+
+	t.Run("filter by event type", func(t *testing.T) {
+		filter, err := state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, []string{"flow.AccountCreated", "A.0000000000000002.Contract2.EventA"}, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, flow.EventsList{events[0], events[3]}, filter.Filter(events))
+	})
+
+	t.Run("filter by address", func(t *testing.T) {
+		filter, err := state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, nil, []string{address1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, flow.EventsList{events[1], events[2]}, filter.Filter(events))
+	})
+
+	t.Run("filter by contract", func(t *testing.T) {
+		filter, err := state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, nil, nil, []string{"A.0000000000000002.Contract2", "flow"})
+		require.NoError(t, err)
+		assert.Equal(t, flow.EventsList{events[0], events[3]}, filter.Filter(events))
+	})
+
+	t.Run("invalid inputs", func(t *testing.T) {
+		_, err := state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, []string{"invalid"}, nil, nil)
+		assert.Error(t, err)
+
+		_, err = state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, nil, nil, []string{"A.0000000000000001"})
+		assert.Error(t, err)
+
+		_, err = state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, nil, []string{"ffffffffffffffff"}, nil)
+		assert.Error(t, err)
+
+		config := state_stream.EventFilterConfig{MaxEventTypes: 1, MaxAddresses: 1, MaxContracts: 1}
+		_, err = state_stream.NewEventFilter(config, chain, []string{"flow.AccountCreated", "flow.AccountKeyAdded"}, nil, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,51 @@
+package subscription
+
+import (
+	"sync"
+
+	"github.com/onflow/flow-go/engine"
+)
+
+// Broadcaster notifies all registered subscribers that new data is available. Each subscriber
+// receives its own engine.Notifier, so notifications never block the publisher and repeated
+// notifications collapse into a single pending one.
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[string]engine.Notifier
+}
+
+// NewBroadcaster creates a new Broadcaster
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[string]engine.Notifier),
+	}
+}
+
+// Subscribe registers a new subscriber with the given ID and returns the notifier it should
+// listen on.
+func (b *Broadcaster) Subscribe(id string) engine.Notifier {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	notifier := engine.NewNotifier()
+	b.subscribers[id] = notifier
+	return notifier
+}
+
+// Unsubscribe removes the subscriber with the given ID. It is a no-op if the subscriber does not exist.
+func (b *Broadcaster) Unsubscribe(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, id)
+}
+
+// Publish notifies all subscribers that new data is available.
+func (b *Broadcaster) Publish() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, notifier := range b.subscribers {
+		notifier.Notify()
+	}
+}
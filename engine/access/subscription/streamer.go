@@ -0,0 +1,107 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+// Streamer represents a streaming subscription that delivers data to clients.
+type Streamer struct {
+	log         zerolog.Logger
+	broadcaster *Broadcaster
+	sendTimeout time.Duration
+	sub         Streamable
+}
+
+// NewStreamer creates a new Streamer.
+func NewStreamer(
+	log zerolog.Logger,
+	broadcaster *Broadcaster,
+	sendTimeout time.Duration,
+	sub Streamable,
+) *Streamer {
+	return &Streamer{
+		log:         log.With().Str("sub_id", sub.ID()).Logger(),
+		broadcaster: broadcaster,
+		sendTimeout: sendTimeout,
+		sub:         sub,
+	}
+}
+
+// Stream is a blocking method that streams data to the subscription until either the context is
+// cancelled or it encounters an error.
+func (s *Streamer) Stream(ctx context.Context) {
+	s.log.Debug().Msg("starting streaming")
+	defer s.log.Debug().Msg("finished streaming")
+
+	notifier := s.broadcaster.Subscribe(s.sub.ID())
+	defer s.broadcaster.Unsubscribe(s.sub.ID())
+
+	// always check the first time. This ensures that streaming continues to work even if the
+	// execution sync is not functioning (e.g. on a past spork network, or during an temporary outage)
+	notifier.Notify()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.sub.Fail(fmt.Errorf("client disconnected: %w", ctx.Err()))
+			return
+		case <-notifier.Channel():
+			s.log.Debug().Msg("received broadcast notification")
+		}
+
+		err := s.sendAllAvailable(ctx)
+
+		if errors.Is(err, ErrEndOfData) {
+			s.log.Debug().Msg("no more data to stream")
+			s.sub.Close()
+			return
+		}
+
+		if err != nil {
+			s.log.Err(err).Msg("error sending response")
+			s.sub.Fail(err)
+			return
+		}
+	}
+}
+
+// sendAllAvailable reads data from the streamable and sends it to the client until no more data is
+// available. Since the subscription channel is unbuffered, each send blocks until the client has
+// consumed the previous value.
+// ErrEndOfData is returned once the streamable indicates that no more data will be available.
+func (s *Streamer) sendAllAvailable(ctx context.Context) error {
+	for {
+		response, err := s.sub.Next(ctx)
+
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) || errors.Is(err, ErrBlockNotReady) {
+				// no more available
+				return nil
+			}
+
+			if errors.Is(err, ErrEndOfData) {
+				return err
+			}
+
+			return fmt.Errorf("could not get response: %w", err)
+		}
+
+		if ssub, ok := s.sub.(*HeightBasedSubscription); ok {
+			s.log.Trace().
+				Uint64("next_height", ssub.nextHeight).
+				Msg("sending response")
+		}
+
+		err = s.sub.Send(ctx, response, s.sendTimeout)
+		if err != nil {
+			return err
+		}
+	}
+}
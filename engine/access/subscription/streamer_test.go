@@ -0,0 +1,156 @@
+package subscription_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestStream tests the happy path for streaming data up to the highest available height, and
+// resuming once new data is published.
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broadcaster := subscription.NewBroadcaster()
+
+	available := uint64(10)
+	availableCh := make(chan uint64, 1)
+	getData := func(_ context.Context, height uint64) (interface{}, error) {
+		select {
+		case available = <-availableCh:
+		default:
+		}
+		if height > available {
+			return nil, subscription.ErrBlockNotReady
+		}
+		return height, nil
+	}
+
+	sub := subscription.NewHeightBasedSubscription(1, getData)
+	streamer := subscription.NewStreamer(unittest.Logger(), broadcaster, subscription.DefaultSendTimeout, sub)
+
+	go streamer.Stream(ctx)
+
+	for i := uint64(1); i <= 10; i++ {
+		v := receive(t, sub)
+		assert.Equal(t, i, v)
+	}
+
+	// no more data is sent until new data is published
+	select {
+	case v := <-sub.Channel():
+		t.Fatalf("unexpected data received: %v", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	availableCh <- 12
+	broadcaster.Publish()
+
+	assert.Equal(t, uint64(11), receive(t, sub))
+	assert.Equal(t, uint64(12), receive(t, sub))
+
+	cancel()
+	unittest.RequireReturnsBefore(t, func() {
+		for range sub.Channel() {
+		}
+	}, time.Second, "subscription not closed")
+	assert.ErrorIs(t, sub.Err(), context.Canceled)
+}
+
+// TestStreamSendTimeout tests that the subscription is failed if the client does not consume data
+// within the send timeout.
+func TestStreamSendTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broadcaster := subscription.NewBroadcaster()
+	getData := func(_ context.Context, height uint64) (interface{}, error) {
+		return height, nil
+	}
+
+	sub := subscription.NewHeightBasedSubscription(1, getData)
+	streamer := subscription.NewStreamer(unittest.Logger(), broadcaster, 10*time.Millisecond, sub)
+
+	unittest.RequireReturnsBefore(t, func() {
+		streamer.Stream(ctx)
+	}, time.Second, "streamer did not stop after send timeout")
+
+	assert.ErrorIs(t, sub.Err(), context.DeadlineExceeded)
+}
+
+// TestStreamError tests that the subscription is failed with the error returned by getData
+func TestStreamError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expectedErr := fmt.Errorf("expected error")
+	broadcaster := subscription.NewBroadcaster()
+	getData := func(_ context.Context, height uint64) (interface{}, error) {
+		return nil, expectedErr
+	}
+
+	sub := subscription.NewHeightBasedSubscription(1, getData)
+	streamer := subscription.NewStreamer(unittest.Logger(), broadcaster, subscription.DefaultSendTimeout, sub)
+
+	unittest.RequireReturnsBefore(t, func() {
+		streamer.Stream(ctx)
+	}, time.Second, "streamer did not stop after error")
+
+	assert.ErrorIs(t, sub.Err(), expectedErr)
+}
+
+// TestStreamEndOfData tests that the subscription is closed without error once the streamable
+// signals that no more data is available.
+func TestStreamEndOfData(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broadcaster := subscription.NewBroadcaster()
+	getData := func(_ context.Context, height uint64) (interface{}, error) {
+		if height > 3 {
+			return nil, subscription.ErrEndOfData
+		}
+		return height, nil
+	}
+
+	sub := subscription.NewHeightBasedSubscription(1, getData)
+	streamer := subscription.NewStreamer(unittest.Logger(), broadcaster, subscription.DefaultSendTimeout, sub)
+
+	go streamer.Stream(ctx)
+
+	for i := uint64(1); i <= 3; i++ {
+		assert.Equal(t, i, receive(t, sub))
+	}
+
+	unittest.RequireReturnsBefore(t, func() {
+		_, ok := <-sub.Channel()
+		assert.False(t, ok)
+	}, time.Second, "subscription not closed")
+	assert.NoError(t, sub.Err())
+}
+
+func receive(t *testing.T, sub subscription.Subscription) interface{} {
+	var v interface{}
+	var ok bool
+	unittest.RequireReturnsBefore(t, func() {
+		v, ok = <-sub.Channel()
+	}, time.Second, "timed out waiting for data")
+	require.True(t, ok, "subscription closed unexpectedly: %v", sub.Err())
+	return v
+}
@@ -0,0 +1,11 @@
+package wrapper
+
+import (
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+)
+
+// ExtendedExecutionAPIClient allows for generation of a mock (via mockery) for the ExtendedExecutionAPIClient
+// generated from the extended execution API protobuf definitions
+type ExtendedExecutionAPIClient interface {
+	extended.ExtendedExecutionAPIClient
+}
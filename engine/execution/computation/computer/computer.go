@@ -34,6 +34,7 @@ const SystemChunkLedgerIntractionLimit = 1_000_000_000 // ~1GB
 // VirtualMachine runs procedures
 type VirtualMachine interface {
 	Run(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error
+	ApplyExecutionParameters(fvm.Context, state.View, *programs.Programs) (fvm.Context, error)
 }
 
 // ViewCommitter commits views's deltas to the ledger and collects the proofs
@@ -56,6 +57,23 @@ type blockComputer struct {
 	systemChunkCtx        fvm.Context
 	committer             ViewCommitter
 	executionDataProvider *provider.Provider
+	parallelWorkers       int
+
+	// registerAccessesActivationHeight is the height of the first block whose execution data includes the
+	// register accesses of the transactions
+	registerAccessesActivationHeight uint64
+}
+
+// BlockComputerOption is an option of NewBlockComputer.
+type BlockComputerOption func(*blockComputer)
+
+// WithParallelTransactionExecution makes the block computer execute the transactions of each collection
+// optimistically in parallel using the given number of workers, see executeTransactionsOptimistically.
+// The results are the same as with sequential execution, which is used if workers is less than 2.
+func WithParallelTransactionExecution(workers int) BlockComputerOption {
+	return func(e *blockComputer) {
+		e.parallelWorkers = workers
+	}
 }
 
 func SystemChunkContext(vmCtx fvm.Context, logger zerolog.Logger) fvm.Context {
@@ -73,7 +91,32 @@ func SystemChunkContext(vmCtx fvm.Context, logger zerolog.Logger) fvm.Context {
 	)
 }
 
+// RegisterAccessesActivationHeight returns the height of the first block of the given chain whose
+// execution data includes the registers read and written by each transaction. Including them changes
+// the execution data ID, which is part of the execution result, so all execution nodes must start
+// including them at the same height. Chains where they aren't included yet return math.MaxUint64.
+func RegisterAccessesActivationHeight(chainID flow.ChainID) uint64 {
+	switch chainID {
+	case flow.Emulator, flow.MonotonicEmulator, flow.Localnet, flow.Benchnet, flow.BftTestnet:
+		return 0
+	default:
+		// activated on live networks with a height coordinated upgrade
+		return math.MaxUint64
+	}
+}
+
+// WithRegisterAccessesInExecutionData makes the block computer include the registers read and written
+// by each transaction in the execution data of the chunks, from the given activation height, see
+// RegisterAccessesActivationHeight. The register accesses are always part of the computation result.
+func WithRegisterAccessesInExecutionData(activationHeight uint64) BlockComputerOption {
+	return func(e *blockComputer) {
+		e.registerAccessesActivationHeight = activationHeight
+	}
+}
+
 // NewBlockComputer creates a new block executor.
+// The execution data of executed blocks is not provided if executionDataProvider is nil,
+// for example when blocks are re-executed offline.
 func NewBlockComputer(
 	vm VirtualMachine,
 	vmCtx fvm.Context,
@@ -82,8 +125,9 @@ func NewBlockComputer(
 	logger zerolog.Logger,
 	committer ViewCommitter,
 	executionDataProvider *provider.Provider,
+	opts ...BlockComputerOption,
 ) (BlockComputer, error) {
-	return &blockComputer{
+	e := &blockComputer{
 		vm:                    vm,
 		vmCtx:                 vmCtx,
 		metrics:               metrics,
@@ -92,7 +136,13 @@ func NewBlockComputer(
 		systemChunkCtx:        SystemChunkContext(vmCtx, logger),
 		committer:             committer,
 		executionDataProvider: executionDataProvider,
-	}, nil
+
+		registerAccessesActivationHeight: math.MaxUint64,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
 }
 
 // ExecuteBlock executes a block and returns the resulting chunks.
@@ -143,6 +193,7 @@ func (e *blockComputer) executeBlock(
 		Events:             make([]flow.EventsList, chunksSize),
 		ServiceEvents:      make(flow.EventsList, 0),
 		TransactionResults: make([]flow.TransactionResult, 0),
+		RegisterAccesses:   make([]flow.RegisterAccesses, 0),
 		StateCommitments:   make([]flow.StateCommitment, 0, chunksSize),
 		Proofs:             make([][]byte, 0, chunksSize),
 		TrieUpdates:        make([]*ledger.TrieUpdate, 0, chunksSize),
@@ -225,7 +276,12 @@ func (e *blockComputer) executeBlock(
 
 	res.StateReads = stateView.(*delta.View).ReadsCount()
 
-	executionData := generateExecutionData(res, collections, systemCol)
+	if e.executionDataProvider == nil {
+		return res, nil
+	}
+
+	includeRegisterAccesses := block.Height() >= e.registerAccessesActivationHeight
+	executionData := generateExecutionData(res, collections, systemCol, includeRegisterAccesses)
 
 	executionDataID, err := e.executionDataProvider.Provide(ctx, block.Height(), executionData)
 	if err != nil {
@@ -241,26 +297,37 @@ func generateExecutionData(
 	res *execution.ComputationResult,
 	collections []*entity.CompleteCollection,
 	systemCol *flow.Collection,
+	includeRegisterAccesses bool,
 ) *execution_data.BlockExecutionData {
 	executionData := &execution_data.BlockExecutionData{
 		BlockID:             res.ExecutableBlock.ID(),
 		ChunkExecutionDatas: make([]*execution_data.ChunkExecutionData, 0, len(collections)+1),
 	}
 
+	txIndex := 0
 	for i, collection := range collections {
 		col := collection.Collection()
-		executionData.ChunkExecutionDatas = append(executionData.ChunkExecutionDatas, &execution_data.ChunkExecutionData{
+		chunkExecutionData := &execution_data.ChunkExecutionData{
 			Collection: &col,
 			Events:     res.Events[i],
 			TrieUpdate: res.TrieUpdates[i],
-		})
+		}
+		if includeRegisterAccesses {
+			chunkExecutionData.RegisterAccesses = res.RegisterAccesses[txIndex : txIndex+len(col.Transactions)]
+		}
+		executionData.ChunkExecutionDatas = append(executionData.ChunkExecutionDatas, chunkExecutionData)
+		txIndex += len(col.Transactions)
 	}
 
-	executionData.ChunkExecutionDatas = append(executionData.ChunkExecutionDatas, &execution_data.ChunkExecutionData{
+	systemChunkExecutionData := &execution_data.ChunkExecutionData{
 		Collection: systemCol,
 		Events:     res.Events[len(res.Events)-1],
 		TrieUpdate: res.TrieUpdates[len(res.TrieUpdates)-1],
-	})
+	}
+	if includeRegisterAccesses {
+		systemChunkExecutionData.RegisterAccesses = res.RegisterAccesses[txIndex:]
+	}
+	executionData.ChunkExecutionDatas = append(executionData.ChunkExecutionDatas, systemChunkExecutionData)
 
 	return executionData
 }
@@ -336,13 +403,28 @@ func (e *blockComputer) executeCollection(
 		colSpan.End()
 	}()
 
+	// from their activation height, the execution parameters are read in every chunk, so that verifiers read
+	// them from the chunk data pack. They are the same for all the chunks of a block, as they can only be
+	// scheduled for future blocks.
+	blockCtx, err := e.vm.ApplyExecutionParameters(blockCtx, collectionView, programs)
+	if err != nil {
+		return txIndex, fmt.Errorf("cannot apply execution parameters: %w", err)
+	}
+
 	txCtx := fvm.NewContextFromParent(blockCtx, fvm.WithMetricsReporter(e.metrics), fvm.WithTracer(e.tracer))
-	for _, txBody := range collection.Transactions {
-		err := e.executeTransaction(txBody, colSpan, collectionView, programs, txCtx, collectionIndex, txIndex, res, false)
-		txIndex++
+	if e.parallelWorkers > 1 && len(collection.Transactions) > 1 {
+		txIndex, err = e.executeTransactionsOptimistically(collection.Transactions, colSpan, collectionView, programs, txCtx, collectionIndex, txIndex, res)
 		if err != nil {
 			return txIndex, err
 		}
+	} else {
+		for _, txBody := range collection.Transactions {
+			err := e.executeTransaction(txBody, colSpan, collectionView, programs, txCtx, collectionIndex, txIndex, res, false)
+			txIndex++
+			if err != nil {
+				return txIndex, err
+			}
+		}
 	}
 	res.AddStateSnapshot(collectionView.(*delta.View).Interactions())
 	e.log.Info().Str("collectionID", collection.Guarantee.CollectionID.String()).
@@ -368,26 +450,158 @@ func (e *blockComputer) executeTransaction(
 	res *execution.ComputationResult,
 	isSystemChunk bool,
 ) error {
-	startedAt := time.Now()
-	memAllocBefore := debug.GetHeapAllocsBytes()
+	run := e.startTransaction(txBody, colSpan, collectionIndex, txIndex, res, isSystemChunk)
+	e.runTransaction(run, ctx, collectionView.NewChild(), programs)
+	return e.finishTransaction(run, collectionView, collectionIndex, res)
+}
+
+// executeTransactionsOptimistically executes the given transactions of a collection speculatively in
+// parallel, each on its own child view of the collection view, and then merges their views into the
+// collection view in order.
+// A transaction which touched a register written by a previous transaction of the collection has
+// seen an outdated state, so it's executed again on the updated collection view before being merged.
+// This makes the results identical to executing the transactions sequentially.
+// It returns the index of the next transaction.
+func (e *blockComputer) executeTransactionsOptimistically(
+	txBodies []*flow.TransactionBody,
+	colSpan otelTrace.Span,
+	collectionView state.View,
+	blockPrograms *programs.Programs,
+	ctx fvm.Context,
+	collectionIndex int,
+	txIndex uint32,
+	res *execution.ComputationResult,
+) (uint32, error) {
+	colView, ok := collectionView.(*delta.View)
+	if !ok {
+		return txIndex, fmt.Errorf("optimistic execution requires a delta.View, given: %T", collectionView)
+	}
+
+	// the collection view isn't modified until all speculative executions are done,
+	// but the read function of its parent isn't safe for concurrent use.
+	var readLock sync.Mutex
+	readFunc := func(owner, key string) (flow.RegisterValue, error) {
+		readLock.Lock()
+		defer readLock.Unlock()
+		return colView.Peek(owner, key)
+	}
+
+	runs := make([]*transactionRun, len(txBodies))
+	for i, txBody := range txBodies {
+		runs[i] = e.startTransaction(txBody, colSpan, collectionIndex, txIndex+uint32(i), res, false)
+	}
+
+	// programs loaded by speculative executions are kept in child programs until the
+	// execution is known to be valid, as they might have been loaded from outdated state.
+	speculativePrograms := make([]*programs.Programs, len(runs))
+
+	runCh := make(chan int, len(runs))
+	for i := range runs {
+		runCh <- i
+	}
+	close(runCh)
+
+	var wg sync.WaitGroup
+	for w := 0; w < e.parallelWorkers && w < len(runs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range runCh {
+				speculativePrograms[i] = blockPrograms.ChildPrograms()
+				e.runTransaction(runs[i], ctx, delta.NewView(readFunc), speculativePrograms[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	reexecuted := 0
+	written := make(map[string]struct{})
+	for i, run := range runs {
+		if run.err == nil && !touchesAny(run.view, written) {
+			blockPrograms.MergeChild(speculativePrograms[i])
+		} else {
+			// the speculative execution is discarded, and the transaction is executed again
+			e.endTransactionSpans(run)
+			reexecuted++
+
+			run = e.startTransaction(run.txBody, colSpan, collectionIndex, txIndex+uint32(i), res, false)
+			e.runTransaction(run, ctx, collectionView.NewChild(), blockPrograms)
+		}
+
+		if run.err == nil {
+			for id := range run.view.(*delta.View).Delta().Data {
+				written[id] = struct{}{}
+			}
+		}
+
+		err := e.finishTransaction(run, collectionView, collectionIndex, res)
+		if err != nil {
+			return txIndex + uint32(i) + 1, err
+		}
+	}
+
+	e.log.Debug().
+		Int("transactions", len(runs)).
+		Int("reexecuted", reexecuted).
+		Msg("transactions executed optimistically")
+
+	return txIndex + uint32(len(runs)), nil
+}
+
+// touchesAny returns true if any register touched (read or written) by the given view is in the given set.
+func touchesAny(view state.View, registers map[string]struct{}) bool {
+	for id := range view.(*delta.View).Interactions().Reads {
+		if _, ok := registers[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionRun is the execution of a transaction on its own view, which is merged
+// into the collection view by finishTransaction.
+type transactionRun struct {
+	txBody         *flow.TransactionBody
+	tx             *fvm.TransactionProcedure
+	view           state.View
+	err            error
+	txSpan         otelTrace.Span
+	txInternalSpan otelTrace.Span
+	traceID        string
+	startedAt      time.Time
+	memAllocBefore uint64
+}
+
+// startTransaction starts the spans of a transaction execution, and prepares its procedure.
+func (e *blockComputer) startTransaction(
+	txBody *flow.TransactionBody,
+	colSpan otelTrace.Span,
+	collectionIndex int,
+	txIndex uint32,
+	res *execution.ComputationResult,
+	isSystemChunk bool,
+) *transactionRun {
+	run := &transactionRun{
+		txBody:         txBody,
+		startedAt:      time.Now(),
+		memAllocBefore: debug.GetHeapAllocsBytes(),
+	}
 	txID := txBody.ID()
 
 	// we capture two spans one for tx-based view and one for the current context (block-based) view
-	txSpan := e.tracer.StartSpanFromParent(colSpan, trace.EXEComputeTransaction)
-	txSpan.SetAttributes(
+	run.txSpan = e.tracer.StartSpanFromParent(colSpan, trace.EXEComputeTransaction)
+	run.txSpan.SetAttributes(
 		attribute.String("tx_id", txID.String()),
 		attribute.Int64("tx_index", int64(txIndex)),
 		attribute.Int("col_index", collectionIndex),
 	)
-	defer txSpan.End()
 
-	var traceID string
 	txInternalSpan, _, isSampled := e.tracer.StartTransactionSpan(context.Background(), txID, trace.EXERunTransaction)
 	if isSampled {
 		txInternalSpan.SetAttributes(attribute.String("tx_id", txID.String()))
-		traceID = txInternalSpan.SpanContext().TraceID().String()
+		run.traceID = txInternalSpan.SpanContext().TraceID().String()
 	}
-	defer txInternalSpan.End()
+	run.txInternalSpan = txInternalSpan
 
 	e.log.Info().
 		Str("tx_id", txID.String()).
@@ -397,37 +611,64 @@ func (e *blockComputer) executeTransaction(
 		Bool("system_chunk", isSystemChunk).
 		Msg("executing transaction in fvm")
 
-	tx := fvm.Transaction(txBody, txIndex)
+	run.tx = fvm.Transaction(txBody, txIndex)
 	if isSampled {
-		tx.SetTraceSpan(txInternalSpan)
+		run.tx.SetTraceSpan(txInternalSpan)
 	}
 
-	txView := collectionView.NewChild()
-	err := e.vm.Run(ctx, tx, txView, programs)
-	if err != nil {
+	return run
+}
+
+// runTransaction runs the transaction on the given view, without modifying the collection view.
+func (e *blockComputer) runTransaction(run *transactionRun, ctx fvm.Context, txView state.View, programs *programs.Programs) {
+	run.view = txView
+	run.err = e.vm.Run(ctx, run.tx, txView, programs)
+}
+
+// endTransactionSpans ends the spans started by startTransaction.
+func (e *blockComputer) endTransactionSpans(run *transactionRun) {
+	run.txInternalSpan.End()
+	run.txSpan.End()
+}
+
+// finishTransaction merges the view of the transaction into the collection view,
+// and adds the transaction results to the computation results.
+func (e *blockComputer) finishTransaction(
+	run *transactionRun,
+	collectionView state.View,
+	collectionIndex int,
+	res *execution.ComputationResult,
+) error {
+	defer e.endTransactionSpans(run)
+
+	tx := run.tx
+	txID := run.txBody.ID()
+
+	if run.err != nil {
 		return fmt.Errorf("failed to execute transaction %v for block %v at height %v: %w",
 			txID.String(),
 			res.ExecutableBlock.ID(),
 			res.ExecutableBlock.Block.Header.Height,
-			err)
+			run.err)
 	}
 
 	txResult := flow.TransactionResult{
 		TransactionID:   tx.ID,
 		ComputationUsed: tx.ComputationUsed,
+		ContractUsages:  tx.ContractUsages,
 	}
 
 	if tx.Err != nil {
 		txResult.ErrorMessage = tx.Err.Error()
 	}
 
-	postProcessSpan := e.tracer.StartSpanFromParent(txSpan, trace.EXEPostProcessTransaction)
+	postProcessSpan := e.tracer.StartSpanFromParent(run.txSpan, trace.EXEPostProcessTransaction)
 	defer postProcessSpan.End()
 
 	// always merge the view, fvm take cares of reverting changes
 	// of failed transaction invocation
 
-	err = e.mergeView(collectionView, txView, postProcessSpan, trace.EXEMergeTransactionView)
+	err := e.mergeView(collectionView, run.view, postProcessSpan, trace.EXEMergeTransactionView)
 	if err != nil {
 		return fmt.Errorf("merging tx view to collection view failed for tx %v: %w",
 			txID.String(), err)
@@ -436,6 +677,11 @@ func (e *blockComputer) executeTransaction(
 	res.AddEvents(collectionIndex, tx.Events)
 	res.AddServiceEvents(tx.ServiceEvents)
 	res.AddTransactionResult(&txResult)
+	res.AddRegisterAccesses(flow.RegisterAccesses{
+		TransactionID: tx.ID,
+		Reads:         tx.RegisterReads,
+		Writes:        tx.RegisterWrites,
+	})
 	res.AddComputationUsed(tx.ComputationUsed)
 
 	memAllocAfter := debug.GetHeapAllocsBytes()
@@ -443,11 +689,11 @@ func (e *blockComputer) executeTransaction(
 	lg := e.log.With().
 		Hex("tx_id", txResult.TransactionID[:]).
 		Str("block_id", res.ExecutableBlock.ID().String()).
-		Str("traceID", traceID).
+		Str("traceID", run.traceID).
 		Uint64("computation_used", txResult.ComputationUsed).
 		Uint64("memory_used", tx.MemoryEstimate).
-		Uint64("memAlloc", memAllocAfter-memAllocBefore).
-		Int64("timeSpentInMS", time.Since(startedAt).Milliseconds()).
+		Uint64("memAlloc", memAllocAfter-run.memAllocBefore).
+		Int64("timeSpentInMS", time.Since(run.startedAt).Milliseconds()).
 		Logger()
 
 	if tx.Err != nil {
@@ -460,9 +706,9 @@ func (e *blockComputer) executeTransaction(
 	}
 
 	e.metrics.ExecutionTransactionExecuted(
-		time.Since(startedAt),
+		time.Since(run.startedAt),
 		tx.ComputationUsed,
-		memAllocAfter-memAllocBefore,
+		memAllocAfter-run.memAllocBefore,
 		tx.MemoryEstimate,
 		len(tx.Events),
 		tx.Err != nil,
@@ -36,6 +36,7 @@ import (
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/fvm/systemcontracts"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
 	"github.com/onflow/flow-go/module/epochs"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	"github.com/onflow/flow-go/module/executiondatasync/provider"
@@ -67,6 +68,9 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 				tx.Events = generateEvents(1, tx.TxIndex)
 			}).
 			Times(2 + 1) // 2 txs in collection + system chunk
+		vm.On("ApplyExecutionParameters", mock.Anything, mock.Anything, mock.Anything).
+			Return(withoutExecutionParameters, nil).
+			Once() // 1 collection
 
 		committer := new(computermock.ViewCommitter)
 		committer.On("CommitView", mock.Anything, mock.Anything).
@@ -111,6 +115,12 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 		assert.Len(t, result.StateSnapshots, 1+1) // +1 system chunk
 		assert.Len(t, result.TrieUpdates, 1+1)    // +1 system chunk
 
+		// register accesses are recorded for every transaction
+		require.Len(t, result.RegisterAccesses, 2+1) // +1 system chunk tx
+		for i, accesses := range result.RegisterAccesses {
+			assert.Equal(t, result.TransactionResults[i].TransactionID, accesses.TransactionID)
+		}
+
 		assertEventHashesMatch(t, 1+1, result)
 
 		vm.AssertExpectations(t)
@@ -169,6 +179,77 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 		vm.AssertExpectations(t)
 	})
 
+	t.Run("register accesses are included in execution data from activation height", func(t *testing.T) {
+
+		// the generated block has height 42
+		for activationHeight, included := range map[uint64]bool{42: true, 43: false} {
+			execCtx := fvm.NewContext(zerolog.Nop())
+
+			vm := new(computermock.VirtualMachine)
+			committer := new(computermock.ViewCommitter)
+
+			ds := dssync.MutexWrap(datastore.NewMapDatastore())
+			bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(ds))
+			trackerStorage := new(mocktracker.Storage)
+			trackerStorage.On("Update", mock.Anything).Return(func(fn tracker.UpdateFn) error {
+				return fn(func(uint64, ...cid.Cid) error { return nil })
+			})
+
+			prov := provider.NewProvider(
+				zerolog.Nop(),
+				metrics.NewNoopCollector(),
+				execution_data.DefaultSerializer,
+				bservice,
+				trackerStorage,
+			)
+
+			exe, err := computer.NewBlockComputer(
+				vm,
+				execCtx,
+				metrics.NewNoopCollector(),
+				trace.NewNoopTracer(),
+				zerolog.Nop(),
+				committer,
+				prov,
+				computer.WithRegisterAccessesInExecutionData(activationHeight),
+			)
+			require.NoError(t, err)
+
+			// create an empty block
+			block := generateBlock(0, 0, rag)
+			programs := programs.NewEmptyPrograms()
+
+			vm.On("Run", mock.Anything, mock.Anything, mock.Anything, programs).
+				Return(nil).
+				Once() // just system chunk
+
+			committer.On("CommitView", mock.Anything, mock.Anything).
+				Return(nil, nil, nil, nil).
+				Once() // just system chunk
+
+			view := delta.NewView(func(owner, key string) (flow.RegisterValue, error) {
+				return nil, nil
+			})
+
+			result, err := exe.ExecuteBlock(context.Background(), block, view, programs)
+			require.NoError(t, err)
+			require.Len(t, result.RegisterAccesses, 1)
+
+			eds := execution_data.NewExecutionDataStore(blobs.NewBlobstore(ds), execution_data.DefaultSerializer)
+			executionData, err := eds.GetExecutionData(context.Background(), result.ExecutionDataID)
+			require.NoError(t, err)
+			require.Len(t, executionData.ChunkExecutionDatas, 1)
+
+			if included {
+				assert.Equal(t, result.RegisterAccesses, executionData.ChunkExecutionDatas[0].RegisterAccesses)
+			} else {
+				assert.Empty(t, executionData.ChunkExecutionDatas[0].RegisterAccesses)
+			}
+
+			vm.AssertExpectations(t)
+		}
+	})
+
 	t.Run("system chunk transaction should not fail", func(t *testing.T) {
 
 		// include all fees. System chunk should ignore them
@@ -289,6 +370,10 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 			Return(nil).
 			Times(totalTransactionCount)
 
+		vm.On("ApplyExecutionParameters", mock.Anything, mock.Anything, programs).
+			Return(withoutExecutionParameters, nil).
+			Times(collectionCount)
+
 		committer.On("CommitView", mock.Anything, mock.Anything).
 			Return(nil, nil, nil, nil).
 			Times(collectionCount + 1)
@@ -981,3 +1066,8 @@ func generateEvents(eventCount int, txIndex uint32) []flow.Event {
 	}
 	return events
 }
+
+// withoutExecutionParameters returns the given context, as if no execution parameters were stored
+func withoutExecutionParameters(ctx fvm.Context, _ state.View, _ *programs.Programs) fvm.Context {
+	return ctx
+}
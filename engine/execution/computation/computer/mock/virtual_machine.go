@@ -16,6 +16,27 @@ type VirtualMachine struct {
 	mock.Mock
 }
 
+// ApplyExecutionParameters provides a mock function with given fields: _a0, _a1, _a2
+func (_m *VirtualMachine) ApplyExecutionParameters(_a0 fvm.Context, _a1 state.View, _a2 *programs.Programs) (fvm.Context, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 fvm.Context
+	if rf, ok := ret.Get(0).(func(fvm.Context, state.View, *programs.Programs) fvm.Context); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(fvm.Context)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(fvm.Context, state.View, *programs.Programs) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Run provides a mock function with given fields: _a0, _a1, _a2, _a3
 func (_m *VirtualMachine) Run(_a0 fvm.Context, _a1 fvm.Procedure, _a2 state.View, _a3 *programs.Programs) error {
 	ret := _m.Called(_a0, _a1, _a2, _a3)
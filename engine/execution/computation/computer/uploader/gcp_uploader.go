@@ -3,6 +3,7 @@ package uploader
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"cloud.google.com/go/storage"
 	"github.com/rs/zerolog"
@@ -55,6 +56,27 @@ func (u *GCPBucketUploader) Upload(computationResult *execution.ComputationResul
 	return WriteComputationResultsTo(computationResult, writer)
 }
 
+// UploadFile uploads the content read from reader to the bucket as an object with the given name.
+// Unlike Upload, it fails if the object can't be closed, as the content isn't stored in that case.
+func (u *GCPBucketUploader) UploadFile(name string, reader io.Reader) error {
+	// canceling the context aborts the upload, so a partially written object is never stored
+	ctx, cancel := context.WithCancel(u.ctx)
+	defer cancel()
+
+	writer := u.bucket.Object(name).NewWriter(ctx)
+
+	_, err := io.Copy(writer, reader)
+	if err != nil {
+		return fmt.Errorf("cannot write object %s: %w", name, err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("cannot close object %s: %w", name, err)
+	}
+	return nil
+}
+
 func GCPBlockDataObjectName(computationResult *execution.ComputationResult) string {
 	return fmt.Sprintf("%s.cbor", computationResult.ExecutableBlock.ID().String())
 }
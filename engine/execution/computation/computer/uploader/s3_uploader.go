@@ -3,6 +3,8 @@ package uploader
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -50,3 +52,17 @@ func (u *S3Uploader) Upload(result *execution.ComputationResult) error {
 
 	return err
 }
+
+// UploadFile uploads the content read from reader to the configured S3 bucket with the given key.
+func (u *S3Uploader) UploadFile(name string, reader io.Reader) error {
+	uploader := manager.NewUploader(u.client)
+	_, err := uploader.Upload(u.ctx, &s3.PutObjectInput{
+		Bucket: &u.bucket,
+		Key:    &name,
+		Body:   reader,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot upload %s: %w", name, err)
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
+	execmodule "github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/module/executiondatasync/provider"
 	"github.com/onflow/flow-go/module/mempool/entity"
 	"github.com/onflow/flow-go/module/trace"
@@ -41,10 +42,12 @@ func SetUploaderEnabled(enabled bool) {
 type VirtualMachine interface {
 	Run(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error
 	GetAccount(fvm.Context, flow.Address, state.View, *programs.Programs) (*flow.Account, error)
+	ApplyExecutionParameters(fvm.Context, state.View, *programs.Programs) (fvm.Context, error)
 }
 
 type ComputationManager interface {
 	ExecuteScript(context.Context, []byte, [][]byte, *flow.Header, state.View) ([]byte, error)
+	DryRunTransaction(ctx context.Context, tx *flow.TransactionBody, blockHeader *flow.Header, view state.View, skipSignatureVerification bool) (*execmodule.TransactionDryRunResult, error)
 	ComputeBlock(
 		ctx context.Context,
 		block *entity.ExecutableBlock,
@@ -85,11 +88,13 @@ func New(
 	vm VirtualMachine,
 	vmCtx fvm.Context,
 	programsCacheSize uint,
+	programsCacheMaxPrograms uint,
 	committer computer.ViewCommitter,
 	scriptLogThreshold time.Duration,
 	scriptExecutionTimeLimit time.Duration,
 	uploaders []uploader.Uploader,
 	executionDataProvider *provider.Provider,
+	blockComputerOpts ...computer.BlockComputerOption,
 ) (*Manager, error) {
 	log := logger.With().Str("engine", "computation").Logger()
 
@@ -101,13 +106,14 @@ func New(
 		log.With().Str("component", "block_computer").Logger(),
 		committer,
 		executionDataProvider,
+		blockComputerOpts...,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("cannot create block computer: %w", err)
 	}
 
-	programsCache, err := NewProgramsCache(programsCacheSize)
+	programsCache, err := NewProgramsCache(programsCacheSize, programsCacheMaxPrograms, metrics)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create programs cache: %w", err)
 	}
@@ -135,7 +141,7 @@ func New(
 func (e *Manager) getChildProgramsOrEmpty(blockID flow.Identifier) *programs.Programs {
 	blockPrograms := e.programsCache.Get(blockID)
 	if blockPrograms == nil {
-		return programs.NewEmptyPrograms()
+		return e.programsCache.NewEmptyPrograms()
 	}
 	return blockPrograms.ChildPrograms()
 }
@@ -207,12 +213,20 @@ func (e *Manager) ExecuteScript(
 			}
 		}()
 
+		blockCtx, err = e.vm.ApplyExecutionParameters(blockCtx, view, programs)
+		if err != nil {
+			return fmt.Errorf("cannot apply execution parameters: %w", err)
+		}
+
 		return e.vm.Run(blockCtx, script, view, programs)
 	}()
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute script (internal error): %w", err)
 	}
 
+	// the programs loaded by the script are valid for the block, whether the script failed or not
+	e.programsCache.MergeScriptPrograms(blockHeader.ID(), programs)
+
 	if script.Err != nil {
 		scriptErrMsg := script.Err.Error()
 		if len(scriptErrMsg) > MaxScriptErrorMessageSize {
@@ -238,6 +252,27 @@ func (e *Manager) ExecuteScript(
 	return encodedValue, nil
 }
 
+// DryRunTransaction executes the transaction against the view of the block without committing its
+// changes, and returns the resources it used, the fees the payer would be charged and the events it
+// emitted. The transaction failing is not an error, the failure is returned in the result.
+func (e *Manager) DryRunTransaction(
+	_ context.Context,
+	tx *flow.TransactionBody,
+	blockHeader *flow.Header,
+	view state.View,
+	skipSignatureVerification bool,
+) (*execmodule.TransactionDryRunResult, error) {
+	// the transaction may deploy or update contracts, so the cached programs must not be updated
+	blockPrograms := e.getChildProgramsOrEmpty(blockHeader.ID())
+
+	result, err := execmodule.DryRunTransaction(e.log, e.vm, e.vmCtx, blockHeader, view, blockPrograms, tx, skipSignatureVerification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dry run transaction at block (%s): %w", blockHeader.ID(), err)
+	}
+
+	return result, nil
+}
+
 func (e *Manager) ComputeBlock(
 	ctx context.Context,
 	block *entity.ExecutableBlock,
@@ -252,7 +287,7 @@ func (e *Manager) ComputeBlock(
 	fromCache := e.programsCache.Get(block.ParentID())
 
 	if fromCache == nil {
-		blockPrograms = programs.NewEmptyPrograms()
+		blockPrograms = e.programsCache.NewEmptyPrograms()
 	} else {
 		blockPrograms = fromCache.ChildPrograms()
 	}
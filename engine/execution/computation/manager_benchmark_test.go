@@ -127,7 +127,7 @@ func BenchmarkComputeBlock(b *testing.B) {
 	blockComputer, err := computer.NewBlockComputer(vm, execCtx, metrics.NewNoopCollector(), tracer, zerolog.Nop(), committer.NewNoopViewCommitter(), prov)
 	require.NoError(b, err)
 
-	programsCache, err := NewProgramsCache(1000)
+	programsCache, err := NewProgramsCache(1000, DefaultProgramsCacheMaxPrograms, metrics.NewNoopCollector())
 	require.NoError(b, err)
 
 	engine := &Manager{
@@ -138,7 +138,7 @@ func TestComputeBlockWithStorage(t *testing.T) {
 	blockComputer, err := computer.NewBlockComputer(vm, execCtx, metrics.NewNoopCollector(), trace.NewNoopTracer(), zerolog.Nop(), committer.NewNoopViewCommitter(), prov)
 	require.NoError(t, err)
 
-	programsCache, err := NewProgramsCache(10)
+	programsCache, err := NewProgramsCache(10, DefaultProgramsCacheMaxPrograms, metrics.NewNoopCollector())
 	require.NoError(t, err)
 
 	engine := &Manager{
@@ -186,7 +186,7 @@ func TestComputeBlock_Uploader(t *testing.T) {
 		computationResult: computationResult,
 	}
 
-	programsCache, err := NewProgramsCache(10)
+	programsCache, err := NewProgramsCache(10, DefaultProgramsCacheMaxPrograms, metrics.NewNoopCollector())
 	require.NoError(t, err)
 
 	fakeUploader := &FakeUploader{}
@@ -261,6 +261,7 @@ func TestExecuteScript(t *testing.T) {
 		vm,
 		execCtx,
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		scriptLogThreshold,
 		DefaultScriptExecutionTimeLimit,
@@ -326,6 +327,7 @@ func TestExecuteScript_BalanceScriptFailsIfViewIsEmpty(t *testing.T) {
 		vm,
 		execCtx,
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		scriptLogThreshold,
 		DefaultScriptExecutionTimeLimit,
@@ -371,6 +373,7 @@ func TestExecuteScripPanicsAreHandled(t *testing.T) {
 		vm,
 		ctx,
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		scriptLogThreshold,
 		DefaultScriptExecutionTimeLimit,
@@ -418,6 +421,7 @@ func TestExecuteScript_LongScriptsAreLogged(t *testing.T) {
 		vm,
 		ctx,
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		1*time.Millisecond,
 		DefaultScriptExecutionTimeLimit,
@@ -465,6 +469,7 @@ func TestExecuteScript_ShortScriptsAreNotLogged(t *testing.T) {
 		vm,
 		ctx,
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		1*time.Second,
 		DefaultScriptExecutionTimeLimit,
@@ -489,6 +494,10 @@ func (p *PanickingVM) GetAccount(f fvm.Context, address flow.Address, view state
 	panic("not expected")
 }
 
+func (p *PanickingVM) ApplyExecutionParameters(f fvm.Context, view state.View, p2 *programs.Programs) (fvm.Context, error) {
+	return f, nil
+}
+
 type LongRunningVM struct {
 	duration time.Duration
 }
@@ -507,6 +516,10 @@ func (l *LongRunningVM) GetAccount(f fvm.Context, address flow.Address, view sta
 	panic("not expected")
 }
 
+func (l *LongRunningVM) ApplyExecutionParameters(f fvm.Context, view state.View, p2 *programs.Programs) (fvm.Context, error) {
+	return f, nil
+}
+
 type FakeBlockComputer struct {
 	computationResult *execution.ComputationResult
 }
@@ -545,6 +558,7 @@ func TestExecuteScriptTimeout(t *testing.T) {
 		fvm.NewVirtualMachine(fvm.NewInterpreterRuntime()),
 		fvm.NewContext(zerolog.Nop()),
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		DefaultScriptLogThreshold,
 		timeout,
@@ -584,6 +598,7 @@ func TestExecuteScriptCancelled(t *testing.T) {
 		fvm.NewVirtualMachine(fvm.NewInterpreterRuntime()),
 		fvm.NewContext(zerolog.Nop()),
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		DefaultScriptLogThreshold,
 		timeout,
@@ -635,6 +650,7 @@ func TestScriptStorageMutationsDiscarded(t *testing.T) {
 		vm,
 		ctx,
 		DefaultProgramsCacheSize,
+		DefaultProgramsCacheMaxPrograms,
 		committer.NewNoopViewCommitter(),
 		DefaultScriptLogThreshold,
 		timeout,
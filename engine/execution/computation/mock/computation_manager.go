@@ -10,6 +10,8 @@ import (
 
 	flow "github.com/onflow/flow-go/model/flow"
 
+	execmodule "github.com/onflow/flow-go/module/execution"
+
 	mock "github.com/stretchr/testify/mock"
 
 	state "github.com/onflow/flow-go/fvm/state"
@@ -43,6 +45,29 @@ func (_m *ComputationManager) ComputeBlock(ctx context.Context, block *entity.Ex
 	return r0, r1
 }
 
+// DryRunTransaction provides a mock function with given fields: ctx, tx, blockHeader, view, skipSignatureVerification
+func (_m *ComputationManager) DryRunTransaction(ctx context.Context, tx *flow.TransactionBody, blockHeader *flow.Header, view state.View, skipSignatureVerification bool) (*execmodule.TransactionDryRunResult, error) {
+	ret := _m.Called(ctx, tx, blockHeader, view, skipSignatureVerification)
+
+	var r0 *execmodule.TransactionDryRunResult
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody, *flow.Header, state.View, bool) *execmodule.TransactionDryRunResult); ok {
+		r0 = rf(ctx, tx, blockHeader, view, skipSignatureVerification)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*execmodule.TransactionDryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody, *flow.Header, state.View, bool) error); ok {
+		r1 = rf(ctx, tx, blockHeader, view, skipSignatureVerification)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecuteScript provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
 func (_m *ComputationManager) ExecuteScript(_a0 context.Context, _a1 []byte, _a2 [][]byte, _a3 *flow.Header, _a4 state.View) ([]byte, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
@@ -0,0 +1,203 @@
+package computation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution"
+	"github.com/onflow/flow-go/engine/execution/computation/committer"
+	"github.com/onflow/flow-go/engine/execution/computation/computer"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/engine/execution/testutil"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/executiondatasync/provider"
+	"github.com/onflow/flow-go/module/executiondatasync/tracker"
+	mocktracker "github.com/onflow/flow-go/module/executiondatasync/tracker/mock"
+	"github.com/onflow/flow-go/module/mempool/entity"
+	"github.com/onflow/flow-go/module/metrics"
+	requesterunit "github.com/onflow/flow-go/module/state_synchronization/requester/unittest"
+	"github.com/onflow/flow-go/module/trace"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestParallelTransactionExecution checks that executing the transactions of collections
+// optimistically in parallel produces the same results as executing them sequentially.
+func TestParallelTransactionExecution(t *testing.T) {
+	rt := fvm.NewInterpreterRuntime()
+	chain := flow.Mainnet.Chain()
+	vm := fvm.NewVirtualMachine(rt)
+	execCtx := fvm.NewContext(zerolog.Nop(), fvm.WithChain(chain))
+
+	privateKeys, err := testutil.GenerateAccountPrivateKeys(4)
+	require.NoError(t, err)
+	ledger := testutil.RootBootstrappedLedger(vm, execCtx)
+	accounts, err := testutil.CreateAccounts(vm, ledger, programs.NewEmptyPrograms(), privateKeys, chain)
+	require.NoError(t, err)
+
+	// every account deploys a contract in a first collection, and calls it in a second one
+	deployments := make([]*flow.TransactionBody, len(accounts))
+	calls := make([]*flow.TransactionBody, len(accounts))
+	for i, account := range accounts {
+		deployments[i] = testutil.DeployEventContractTransaction(account, chain, i)
+		prepareTx(t, deployments[i], account, privateKeys[i], 0, chain)
+
+		calls[i] = testutil.CreateEmitEventTransaction(account, account)
+		prepareTx(t, calls[i], account, privateKeys[i], 1, chain)
+	}
+
+	t.Run("independent transactions", func(t *testing.T) {
+		block := parallelExecutionTestBlock(deployments, calls)
+		sequential, parallel := executeSequentiallyAndInParallel(t, vm, execCtx, ledger, block)
+
+		require.Len(t, sequential.Events, 3) // 2 collections + 1 system chunk
+		for i := range accounts {
+			hasValidEventValue(t, sequential.Events[1][i], i)
+		}
+		requireSameComputationResults(t, sequential, parallel)
+	})
+
+	t.Run("conflicting transactions", func(t *testing.T) {
+		account := accounts[0]
+		privKey := privateKeys[0]
+
+		// the contract is updated and called within the same collection,
+		// so calls executed in parallel with the update see the outdated contract
+		tx1 := testutil.CreateEmitEventTransaction(account, account)
+		prepareTx(t, tx1, account, privKey, 2, chain)
+
+		tx2 := testutil.UpdateEventContractTransaction(account, chain, 10)
+		prepareTx(t, tx2, account, privKey, 3, chain)
+
+		tx3 := testutil.CreateEmitEventTransaction(account, account)
+		prepareTx(t, tx3, account, privKey, 4, chain)
+
+		// fails, as the update isn't authorized by the service account
+		tx4 := testutil.UnauthorizedDeployEventContractTransaction(account, chain, 11)
+		tx4.SetProposalKey(account, 0, 5).SetPayer(account)
+		err := testutil.SignEnvelope(tx4, account, privKey)
+		require.NoError(t, err)
+
+		tx5 := testutil.CreateEmitEventTransaction(account, account)
+		prepareTx(t, tx5, account, privKey, 6, chain)
+
+		// transactions of other accounts don't conflict with the ones above
+		tx6 := testutil.CreateEmitEventTransaction(accounts[1], accounts[1])
+		prepareTx(t, tx6, accounts[1], privateKeys[1], 2, chain)
+
+		// uses the proposal key sequence number used by tx6
+		tx7 := testutil.CreateEmitEventTransaction(accounts[1], accounts[1])
+		prepareTx(t, tx7, accounts[1], privateKeys[1], 2, chain)
+
+		block := parallelExecutionTestBlock(deployments, calls, []*flow.TransactionBody{tx1, tx2, tx3, tx4, tx5, tx6, tx7})
+		sequential, parallel := executeSequentiallyAndInParallel(t, vm, execCtx, ledger, block)
+
+		require.Len(t, sequential.Events, 4) // 3 collections + 1 system chunk
+		events := sequential.Events[2]
+		hasValidEventValue(t, events[0], 0)
+		require.EqualValues(t, "flow.AccountContractUpdated", events[1].Type)
+		hasValidEventValue(t, events[2], 10)
+		hasValidEventValue(t, events[3], 10)
+		hasValidEventValue(t, events[4], 1)
+
+		results := sequential.TransactionResults[2*len(accounts):]
+		require.NotEmpty(t, results[3].ErrorMessage)
+		require.NotEmpty(t, results[6].ErrorMessage)
+		requireSameComputationResults(t, sequential, parallel)
+	})
+}
+
+func parallelExecutionTestBlock(collections ...[]*flow.TransactionBody) *entity.ExecutableBlock {
+	block := &flow.Block{
+		Header: &flow.Header{
+			View: 26,
+		},
+		Payload: &flow.Payload{},
+	}
+	completeCollections := make(map[flow.Identifier]*entity.CompleteCollection, len(collections))
+
+	for _, transactions := range collections {
+		col := flow.Collection{Transactions: transactions}
+		guarantee := &flow.CollectionGuarantee{
+			CollectionID: col.ID(),
+		}
+		block.Payload.Guarantees = append(block.Payload.Guarantees, guarantee)
+		completeCollections[guarantee.ID()] = &entity.CompleteCollection{
+			Guarantee:    guarantee,
+			Transactions: transactions,
+		}
+	}
+
+	return &entity.ExecutableBlock{
+		Block:               block,
+		CompleteCollections: completeCollections,
+		StartState:          unittest.StateCommitmentPointerFixture(),
+	}
+}
+
+// executeSequentiallyAndInParallel executes the given block on the given ledger
+// with a sequential block computer, and with a parallel one.
+func executeSequentiallyAndInParallel(
+	t *testing.T,
+	vm *fvm.VirtualMachine,
+	execCtx fvm.Context,
+	ledger state.View,
+	block *entity.ExecutableBlock,
+) (*execution.ComputationResult, *execution.ComputationResult) {
+	execute := func(opts ...computer.BlockComputerOption) (*execution.ComputationResult, *delta.View) {
+		bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore())))
+		trackerStorage := new(mocktracker.Storage)
+		trackerStorage.On("Update", mock.Anything).Return(func(fn tracker.UpdateFn) error {
+			return fn(func(uint64, ...cid.Cid) error { return nil })
+		})
+
+		prov := provider.NewProvider(
+			zerolog.Nop(),
+			metrics.NewNoopCollector(),
+			execution_data.DefaultSerializer,
+			bservice,
+			trackerStorage,
+		)
+
+		blockComputer, err := computer.NewBlockComputer(vm, execCtx, metrics.NewNoopCollector(), trace.NewNoopTracer(), zerolog.Nop(), committer.NewNoopViewCommitter(), prov, opts...)
+		require.NoError(t, err)
+
+		view := delta.NewView(ledger.Get)
+		result, err := blockComputer.ExecuteBlock(context.Background(), block, view, programs.NewEmptyPrograms())
+		require.NoError(t, err)
+
+		return result, view
+	}
+
+	sequential, sequentialView := execute()
+	parallel, parallelView := execute(computer.WithParallelTransactionExecution(4))
+
+	require.Equal(t, sequentialView.Interactions(), parallelView.Interactions())
+	require.Equal(t, sequentialView.ReadsCount(), parallelView.ReadsCount())
+
+	return sequential, parallel
+}
+
+func requireSameComputationResults(t *testing.T, expected *execution.ComputationResult, actual *execution.ComputationResult) {
+	require.Equal(t, expected.StateSnapshots, actual.StateSnapshots)
+	require.Equal(t, expected.StateCommitments, actual.StateCommitments)
+	require.Equal(t, expected.Events, actual.Events)
+	require.Equal(t, expected.EventsHashes, actual.EventsHashes)
+	require.Equal(t, expected.ServiceEvents, actual.ServiceEvents)
+	require.Equal(t, expected.TransactionResults, actual.TransactionResults)
+	require.Equal(t, expected.RegisterAccesses, actual.RegisterAccesses)
+	require.Equal(t, expected.ComputationUsed, actual.ComputationUsed)
+	require.Equal(t, expected.StateReads, actual.StateReads)
+	require.Equal(t, expected.ExecutionDataID, actual.ExecutionDataID)
+}
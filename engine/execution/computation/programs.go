@@ -2,27 +2,77 @@ package computation
 
 import (
 	"fmt"
+	"sync"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/onflow/cadence/runtime/common"
 
 	"github.com/onflow/flow-go/fvm/programs"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
 )
 
 const DefaultProgramsCacheSize = 1000
 
+// DefaultProgramsCacheMaxPrograms is the default maximum number of programs kept by the programs cache.
+const DefaultProgramsCacheMaxPrograms = 1000
+
+// ProgramsCache caches the programs loaded while executing blocks and scripts, across blocks.
+//
+// The programs of a block are children of the programs of its parent, so the programs loaded
+// in a fork are shared by the following blocks, until a contract they depend on is updated.
+// The programs loaded by scripts are merged into the programs of the block they're executed on.
+//
+// The number of cached programs is bounded: the least recently used programs are evicted
+// from the programs of the blocks holding them, and are parsed and checked again when needed.
 type ProgramsCache struct {
-	cache *lru.Cache
+	cache   *lru.Cache // block ID -> *programs.Programs
+	metrics module.ExecutionMetrics
+
+	// lock protects entries and held, the recency of the cached programs
+	// and the programs holding them
+	lock    sync.Mutex
+	entries *simplelru.LRU // *programs.ProgramEntry -> holders, the programs holding the entry
+	held    map[*programs.Programs]*heldEntries
+}
+
+// holders is the set of cached programs holding an entry
+type holders map[*programs.Programs]struct{}
+
+// heldEntries are the entries tracked for programs cached for one or more blocks
+type heldEntries struct {
+	blocks  int
+	entries map[*programs.ProgramEntry]struct{}
 }
 
-func NewProgramsCache(size uint) (*ProgramsCache, error) {
-	cache, err := lru.New(int(size))
+var _ programs.Observer = (*ProgramsCache)(nil)
+
+// NewProgramsCache creates a programs cache for the given number of blocks,
+// keeping at most maxPrograms programs.
+func NewProgramsCache(size uint, maxPrograms uint, metrics module.ExecutionMetrics) (*ProgramsCache, error) {
+	pc := &ProgramsCache{
+		metrics: metrics,
+		held:    make(map[*programs.Programs]*heldEntries),
+	}
+
+	var err error
+	pc.cache, err = lru.NewWithEvict(int(size), pc.onBlockEvicted)
 	if err != nil {
 		return nil, fmt.Errorf("cannot crete LRU cache: %w", err)
 	}
-	return &ProgramsCache{
-		cache: cache,
-	}, nil
+
+	pc.entries, err = simplelru.NewLRU(int(maxPrograms), pc.onEvicted)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create programs LRU cache: %w", err)
+	}
+
+	return pc, nil
+}
+
+// NewEmptyPrograms returns empty programs reporting their lookups to the cache.
+func (pc *ProgramsCache) NewEmptyPrograms() *programs.Programs {
+	return programs.NewEmptyProgramsWithObserver(pc)
 }
 
 func (pc *ProgramsCache) Get(blockID flow.Identifier) *programs.Programs {
@@ -33,6 +83,163 @@ func (pc *ProgramsCache) Get(blockID flow.Identifier) *programs.Programs {
 	return get.(*programs.Programs)
 }
 
-func (pc *ProgramsCache) Set(blockId flow.Identifier, programs *programs.Programs) {
-	pc.cache.Add(blockId, programs)
+// Set caches the programs of a block, once it has been executed.
+// The same programs can be cached for several blocks, when a block doesn't change its parent's programs.
+func (pc *ProgramsCache) Set(blockId flow.Identifier, blockPrograms *programs.Programs) {
+	// the programs are held before being added, as adding them can evict other blocks
+	pc.lock.Lock()
+	pc.hold(blockPrograms)
+	pc.lock.Unlock()
+
+	previous, replaced := pc.cache.Peek(blockId)
+	pc.cache.Add(blockId, blockPrograms)
+
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	// replacing the programs of a block doesn't evict them from the cache
+	if replaced {
+		pc.release(previous.(*programs.Programs))
+	}
+	pc.track(blockPrograms)
+}
+
+// MergeScriptPrograms merges the programs loaded by a script executed on the given block,
+// created as children of the block's cached programs, into the block's cached programs.
+// The programs loaded by the script are cached for the block if the block's programs aren't cached.
+func (pc *ProgramsCache) MergeScriptPrograms(blockID flow.Identifier, scriptPrograms *programs.Programs) {
+	blockPrograms := pc.Get(blockID)
+	if blockPrograms == nil {
+		pc.Set(blockID, scriptPrograms)
+		return
+	}
+
+	blockPrograms.MergeChild(scriptPrograms)
+
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	pc.track(blockPrograms)
+}
+
+// ProgramHit marks the program as recently used, and reports the hit.
+func (pc *ProgramsCache) ProgramHit(entry *programs.ProgramEntry) {
+	pc.lock.Lock()
+	pc.entries.Get(entry)
+	pc.lock.Unlock()
+
+	pc.metrics.ExecutionProgramsCacheHit()
+}
+
+// ProgramMiss reports the miss.
+func (pc *ProgramsCache) ProgramMiss(_ common.AddressLocation) {
+	pc.metrics.ExecutionProgramsCacheMiss()
+}
+
+// hold counts one more block the given programs are cached for.
+// The caller must hold the lock.
+func (pc *ProgramsCache) hold(p *programs.Programs) {
+	held, ok := pc.held[p]
+	if !ok {
+		held = &heldEntries{entries: make(map[*programs.ProgramEntry]struct{})}
+		pc.held[p] = held
+	}
+	held.blocks++
+}
+
+// release counts one less block the given programs are cached for, and stops tracking
+// their entries once they aren't cached for any block.
+// The caller must hold the lock.
+func (pc *ProgramsCache) release(p *programs.Programs) {
+	held, ok := pc.held[p]
+	if !ok {
+		return
+	}
+
+	held.blocks--
+	if held.blocks > 0 {
+		return
+	}
+
+	for entry := range held.entries {
+		pc.removeHolder(entry, p)
+	}
+	delete(pc.held, p)
+
+	pc.metrics.ExecutionProgramsCacheEntries(pc.entries.Len())
+}
+
+// track adds the entries of the given cached programs to the entries cached, evicting the
+// least recently used programs if needed, and stops tracking the entries the programs
+// don't hold anymore, for example after being cleaned up.
+// The caller must hold the lock.
+func (pc *ProgramsCache) track(p *programs.Programs) {
+	held, ok := pc.held[p]
+	if !ok {
+		// the programs have been evicted from the cache in the meantime
+		return
+	}
+
+	current := p.Entries()
+
+	currentSet := make(map[*programs.ProgramEntry]struct{}, len(current))
+	for _, entry := range current {
+		currentSet[entry] = struct{}{}
+	}
+
+	for entry := range held.entries {
+		if _, ok := currentSet[entry]; !ok {
+			delete(held.entries, entry)
+			pc.removeHolder(entry, p)
+		}
+	}
+
+	for _, entry := range current {
+		if _, ok := held.entries[entry]; ok {
+			continue
+		}
+		held.entries[entry] = struct{}{}
+
+		if value, ok := pc.entries.Peek(entry); ok {
+			value.(holders)[p] = struct{}{}
+			continue
+		}
+
+		pc.entries.Add(entry, holders{p: {}})
+	}
+
+	pc.metrics.ExecutionProgramsCacheEntries(pc.entries.Len())
+}
+
+// removeHolder removes the given programs from the holders of the entry,
+// and removes the entry once it isn't held anymore.
+// The caller must hold the lock.
+func (pc *ProgramsCache) removeHolder(entry *programs.ProgramEntry, p *programs.Programs) {
+	value, ok := pc.entries.Peek(entry)
+	if !ok {
+		return
+	}
+
+	entryHolders := value.(holders)
+	delete(entryHolders, p)
+	if len(entryHolders) == 0 {
+		pc.entries.Remove(entry)
+	}
+}
+
+// onEvicted evicts the least recently used entry from the programs holding it.
+func (pc *ProgramsCache) onEvicted(key interface{}, value interface{}) {
+	entry := key.(*programs.ProgramEntry)
+	for holder := range value.(holders) {
+		holder.Evict(entry)
+		delete(pc.held[holder].entries, entry)
+	}
+}
+
+// onBlockEvicted stops tracking the entries of the programs of an evicted block.
+func (pc *ProgramsCache) onBlockEvicted(_ interface{}, value interface{}) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	pc.release(value.(*programs.Programs))
 }
@@ -12,6 +12,8 @@ import (
 	blockstore "github.com/ipfs/go-ipfs-blockstore"
 	"github.com/onflow/cadence"
 	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -128,7 +130,7 @@ func TestPrograms_TestContractUpdates(t *testing.T) {
 	blockComputer, err := computer.NewBlockComputer(vm, execCtx, metrics.NewNoopCollector(), trace.NewNoopTracer(), zerolog.Nop(), committer.NewNoopViewCommitter(), prov)
 	require.NoError(t, err)
 
-	programsCache, err := NewProgramsCache(10)
+	programsCache, err := NewProgramsCache(10, DefaultProgramsCacheMaxPrograms, metrics.NewNoopCollector())
 	require.NoError(t, err)
 
 	engine := &Manager{
@@ -227,7 +229,7 @@ func TestPrograms_TestBlockForks(t *testing.T) {
 	blockComputer, err := computer.NewBlockComputer(vm, execCtx, metrics.NewNoopCollector(), trace.NewNoopTracer(), zerolog.Nop(), committer.NewNoopViewCommitter(), prov)
 	require.NoError(t, err)
 
-	programsCache, err := NewProgramsCache(10)
+	programsCache, err := NewProgramsCache(10, DefaultProgramsCacheMaxPrograms, metrics.NewNoopCollector())
 	require.NoError(t, err)
 
 	engine := &Manager{
@@ -418,6 +420,151 @@ func TestPrograms_TestBlockForks(t *testing.T) {
 
 }
 
+// TestProgramsCache_Eviction tests that the least recently used programs
+// are evicted from the programs of the blocks holding them
+func TestProgramsCache_Eviction(t *testing.T) {
+	exeMetrics := new(module.ExecutionMetrics)
+	exeMetrics.On("ExecutionProgramsCacheEntries", mock.Anything)
+	exeMetrics.On("ExecutionProgramsCacheHit")
+	exeMetrics.On("ExecutionProgramsCacheMiss")
+
+	programsCache, err := NewProgramsCache(10, 2, exeMetrics)
+	require.NoError(t, err)
+
+	location := func(name string) common.AddressLocation {
+		return common.AddressLocation{
+			Address: common.MustBytesToAddress([]byte{1, 2, 3}),
+			Name:    name,
+		}
+	}
+	a, b, c := location("A"), location("B"), location("C")
+
+	block1 := unittest.IdentifierFixture()
+	block1Programs := programsCache.NewEmptyPrograms()
+	block1Programs.Set(a, &interpreter.Program{}, nil)
+	programsCache.Set(block1, block1Programs)
+
+	block2 := unittest.IdentifierFixture()
+	block2Programs := block1Programs.ChildPrograms()
+	block2Programs.Set(b, &interpreter.Program{}, nil)
+	programsCache.Set(block2, block2Programs)
+
+	// caching a block again doesn't change the recency of its programs
+	programsCache.Set(block1, block1Programs)
+
+	// A is used after B
+	_, _, has := block2Programs.Get(a)
+	require.True(t, has)
+
+	block3 := unittest.IdentifierFixture()
+	block3Programs := block2Programs.ChildPrograms()
+	block3Programs.Set(c, &interpreter.Program{}, nil)
+	programsCache.Set(block3, block3Programs)
+
+	// B is evicted from the programs of block 2
+	_, _, has = block3Programs.Get(b)
+	require.False(t, has)
+	_, _, has = programsCache.Get(block2).Get(b)
+	require.False(t, has)
+
+	_, _, has = block3Programs.Get(a)
+	require.True(t, has)
+	_, _, has = block3Programs.Get(c)
+	require.True(t, has)
+
+	exeMetrics.AssertNumberOfCalls(t, "ExecutionProgramsCacheHit", 3)
+	exeMetrics.AssertNumberOfCalls(t, "ExecutionProgramsCacheMiss", 2)
+	exeMetrics.AssertCalled(t, "ExecutionProgramsCacheEntries", 2)
+}
+
+// TestProgramsCache_Holders tests that the programs of evicted blocks, and the programs
+// removed by cleanups, don't hold the cached programs anymore
+func TestProgramsCache_Holders(t *testing.T) {
+	programsCache, err := NewProgramsCache(2, DefaultProgramsCacheMaxPrograms, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	address := common.MustBytesToAddress([]byte{1, 2, 3})
+	a := common.AddressLocation{Address: address, Name: "A"}
+	b := common.AddressLocation{Address: address, Name: "B"}
+
+	block1 := unittest.IdentifierFixture()
+	block1Programs := programsCache.NewEmptyPrograms()
+	block1Programs.Set(a, &interpreter.Program{}, nil)
+	programsCache.Set(block1, block1Programs)
+
+	// block 2 doesn't change the programs of block 1
+	block2 := unittest.IdentifierFixture()
+	programsCache.Set(block2, block1Programs)
+
+	block3 := unittest.IdentifierFixture()
+	block3Programs := programsCache.NewEmptyPrograms()
+	block3Programs.Set(b, &interpreter.Program{}, nil)
+	programsCache.Set(block3, block3Programs)
+
+	// block 1 is evicted, but its programs are still cached for block 2
+	require.Nil(t, programsCache.Get(block1))
+	require.Equal(t, 2, programsCache.entries.Len())
+	require.Len(t, programsCache.held, 2)
+
+	// A is only held by the programs of block 2, which are evicted
+	programsCache.Set(unittest.IdentifierFixture(), block3Programs.ChildPrograms())
+	require.Nil(t, programsCache.Get(block2))
+	require.Equal(t, 1, programsCache.entries.Len())
+	require.False(t, programsCache.entries.Contains(block1Programs.Entries()[0]))
+	require.Len(t, programsCache.held, 2)
+
+	// B is removed by a script updating the contract
+	scriptPrograms := block3Programs.ChildPrograms()
+	scriptPrograms.Cleanup(programs.ModifiedSets{
+		ContractUpdateKeys: []programs.ContractUpdateKey{{Address: flow.Address(address), Name: "B"}},
+	})
+	programsCache.MergeScriptPrograms(block3, scriptPrograms)
+
+	_, _, has := block3Programs.Get(b)
+	require.False(t, has)
+	require.Equal(t, 0, programsCache.entries.Len())
+}
+
+// TestProgramsCache_ScriptPrograms tests that the programs loaded by scripts
+// are shared with the following scripts and blocks
+func TestProgramsCache_ScriptPrograms(t *testing.T) {
+	programsCache, err := NewProgramsCache(10, DefaultProgramsCacheMaxPrograms, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	a := common.AddressLocation{
+		Address: common.MustBytesToAddress([]byte{1, 2, 3}),
+		Name:    "A",
+	}
+	b := common.AddressLocation{
+		Address: common.MustBytesToAddress([]byte{1, 2, 3}),
+		Name:    "B",
+	}
+
+	// programs of blocks which aren't cached are cached
+	block1 := unittest.IdentifierFixture()
+	scriptPrograms := programsCache.NewEmptyPrograms()
+	scriptPrograms.Set(a, &interpreter.Program{}, nil)
+	programsCache.MergeScriptPrograms(block1, scriptPrograms)
+
+	block1Programs := programsCache.Get(block1)
+	require.NotNil(t, block1Programs)
+	_, _, has := block1Programs.Get(a)
+	require.True(t, has)
+
+	// programs of cached blocks are merged into the block's programs
+	scriptPrograms = block1Programs.ChildPrograms()
+	scriptPrograms.Set(b, &interpreter.Program{}, nil)
+	programsCache.MergeScriptPrograms(block1, scriptPrograms)
+
+	require.Same(t, block1Programs, programsCache.Get(block1))
+	_, _, has = block1Programs.Get(b)
+	require.True(t, has)
+
+	// and are used by the blocks executed on top of the block
+	_, _, has = block1Programs.ChildPrograms().Get(b)
+	require.True(t, has)
+}
+
 func createTestBlockAndRun(t *testing.T, engine *Manager, parentBlock *flow.Block, col flow.Collection, view state.View) (*flow.Block, *execution.ComputationResult) {
 	guarantee := flow.CollectionGuarantee{
 		CollectionID: col.ID(),
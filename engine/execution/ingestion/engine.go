@@ -23,6 +23,7 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
+	execmodule "github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/module/executiondatasync/pruner"
 	"github.com/onflow/flow-go/module/mempool"
 	"github.com/onflow/flow-go/module/mempool/entity"
@@ -640,6 +641,7 @@ func (e *Engine) executeBlock(ctx context.Context, executableBlock *entity.Execu
 		Msg("block executed")
 
 	e.metrics.ExecutionBlockExecuted(time.Since(startedAt), computationResult.ComputationUsed, len(computationResult.TransactionResults), len(computationResult.ExecutableBlock.CompleteCollections))
+	e.metrics.ExecutionBlockContractUsages(computationResult.ContractUsages())
 
 	err = e.onBlockExecuted(executableBlock, finalState)
 	if err != nil {
@@ -1078,6 +1080,34 @@ func (e *Engine) ExecuteScriptAtBlockID(ctx context.Context, script []byte, argu
 	return e.computationManager.ExecuteScript(ctx, script, arguments, block, blockView)
 }
 
+func (e *Engine) DryRunTransactionAtBlockID(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+	blockID flow.Identifier,
+	skipSignatureVerification bool,
+) (*execmodule.TransactionDryRunResult, error) {
+
+	stateCommit, err := e.execState.StateCommitmentByBlockID(ctx, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state commitment for block (%s): %w", blockID, err)
+	}
+
+	// return early if state with the given state commitment is not in memory
+	// and already purged. This reduces allocations for dry runs targeting old blocks.
+	if !e.execState.HasState(stateCommit) {
+		return nil, fmt.Errorf("failed to dry run transaction at block (%s): state commitment not found (%s). this error usually happens if the block is not a recent block", blockID.String(), hex.EncodeToString(stateCommit[:]))
+	}
+
+	block, err := e.state.AtBlockID(blockID).Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
+	}
+
+	blockView := e.execState.NewView(stateCommit)
+
+	return e.computationManager.DryRunTransaction(ctx, tx, block, blockView, skipSignatureVerification)
+}
+
 func (e *Engine) GetRegisterAtBlockID(ctx context.Context, owner, key []byte, blockID flow.Identifier) ([]byte, error) {
 
 	stateCommit, err := e.execState.StateCommitmentByBlockID(ctx, blockID)
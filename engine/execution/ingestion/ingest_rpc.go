@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/execution"
 )
 
 // IngestRPC represents the RPC calls that the execution ingest engine exposes to support the Access Node API calls
@@ -12,6 +13,9 @@ type IngestRPC interface {
 	// ExecuteScriptAtBlockID executes a script at the given Block id
 	ExecuteScriptAtBlockID(ctx context.Context, script []byte, arguments [][]byte, blockID flow.Identifier) ([]byte, error)
 
+	// DryRunTransactionAtBlockID executes a transaction at the given Block id without committing its changes
+	DryRunTransactionAtBlockID(ctx context.Context, tx *flow.TransactionBody, blockID flow.Identifier, skipSignatureVerification bool) (*execution.TransactionDryRunResult, error)
+
 	// GetAccount returns the Account details at the given Block id
 	GetAccount(ctx context.Context, address flow.Address, blockID flow.Identifier) (*flow.Account, error)
 
@@ -5,6 +5,8 @@ package mock
 import (
 	context "context"
 
+	execution "github.com/onflow/flow-go/module/execution"
+
 	flow "github.com/onflow/flow-go/model/flow"
 
 	mock "github.com/stretchr/testify/mock"
@@ -15,6 +17,29 @@ type IngestRPC struct {
 	mock.Mock
 }
 
+// DryRunTransactionAtBlockID provides a mock function with given fields: ctx, tx, blockID, skipSignatureVerification
+func (_m *IngestRPC) DryRunTransactionAtBlockID(ctx context.Context, tx *flow.TransactionBody, blockID flow.Identifier, skipSignatureVerification bool) (*execution.TransactionDryRunResult, error) {
+	ret := _m.Called(ctx, tx, blockID, skipSignatureVerification)
+
+	var r0 *execution.TransactionDryRunResult
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody, flow.Identifier, bool) *execution.TransactionDryRunResult); ok {
+		r0 = rf(ctx, tx, blockID, skipSignatureVerification)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*execution.TransactionDryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody, flow.Identifier, bool) error); ok {
+		r1 = rf(ctx, tx, blockID, skipSignatureVerification)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecuteScriptAtBlockID provides a mock function with given fields: ctx, script, arguments, blockID
 func (_m *IngestRPC) ExecuteScriptAtBlockID(ctx context.Context, script []byte, arguments [][]byte, blockID flow.Identifier) ([]byte, error) {
 	ret := _m.Called(ctx, script, arguments, blockID)
@@ -25,6 +25,7 @@ type ComputationResult struct {
 	EventsHashes       []flow.Identifier
 	ServiceEvents      flow.EventsList
 	TransactionResults []flow.TransactionResult
+	RegisterAccesses   []flow.RegisterAccesses
 	ComputationUsed    uint64
 	StateReads         uint64
 	TrieUpdates        []*ledger.TrieUpdate
@@ -43,6 +44,19 @@ func (cr *ComputationResult) AddTransactionResult(inp *flow.TransactionResult) {
 	cr.TransactionResults = append(cr.TransactionResults, *inp)
 }
 
+func (cr *ComputationResult) AddRegisterAccesses(inp flow.RegisterAccesses) {
+	cr.RegisterAccesses = append(cr.RegisterAccesses, inp)
+}
+
+// ContractUsages returns the contract usages of all the transactions of the block.
+func (cr *ComputationResult) ContractUsages() []flow.ContractUsage {
+	var usages []flow.ContractUsage
+	for _, txResult := range cr.TransactionResults {
+		usages = append(usages, txResult.ContractUsages...)
+	}
+	return usages
+}
+
 func (cr *ComputationResult) AddComputationUsed(inp uint64) {
 	cr.ComputationUsed += inp
 }
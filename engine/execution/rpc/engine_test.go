@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/onflow/cadence/runtime/common"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -17,7 +18,10 @@ import (
 
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	ingestion "github.com/onflow/flow-go/engine/execution/ingestion/mock"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	"github.com/onflow/flow-go/fvm/meter"
 	"github.com/onflow/flow-go/model/flow"
+	execmodule "github.com/onflow/flow-go/module/execution"
 	realstorage "github.com/onflow/flow-go/storage"
 	storage "github.com/onflow/flow-go/storage/mock"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -893,3 +897,80 @@ func (suite *Suite) TestGetTransactionResultsByBlockID() {
 		txResultsMock.AssertExpectations(suite.T())
 	})
 }
+
+// TestDryRunTransactionAtBlockID tests the DryRunTransactionAtBlockID API call
+func (suite *Suite) TestDryRunTransactionAtBlockID() {
+	mockEngine := new(ingestion.IngestRPC)
+	handler := &extendedHandler{
+		engine: mockEngine,
+		chain:  flow.Testnet,
+	}
+
+	ctx := context.Background()
+	blockID := unittest.IdentifierFixture()
+	tx := unittest.TransactionBodyFixture()
+	matchTx := mock.MatchedBy(func(actual *flow.TransactionBody) bool {
+		return actual.ID() == tx.ID()
+	})
+
+	req := &extended.DryRunTransactionAtBlockIDRequest{
+		BlockId:                   blockID[:],
+		Transaction:               convert.TransactionToMessage(tx),
+		SkipSignatureVerification: true,
+	}
+
+	suite.Run("happy path with successful dry run", func() {
+		events := unittest.BlockEventsFixture(unittest.BlockHeaderFixture(), 2).Events
+		registerID := flow.NewRegisterID("owner", "key")
+		mockEngine.On("DryRunTransactionAtBlockID", ctx, matchTx, blockID, true).
+			Return(&execmodule.TransactionDryRunResult{
+				ComputationUsed: 42,
+				MemoryEstimate:  1000,
+				ComputationBreakdown: meter.ComputationBreakdown{
+					common.ComputationKindStatement: 40,
+				},
+				Fees:         100,
+				Events:       events,
+				StorageDelta: flow.RegisterEntries{{Key: registerID, Value: []byte{1}}},
+				Err:          errors.New("transaction failed"),
+			}, nil).Once()
+
+		response, err := handler.DryRunTransactionAtBlockID(ctx, req)
+		suite.Require().NoError(err)
+
+		suite.Assert().Equal(uint64(42), response.ComputationUsed)
+		suite.Assert().Equal(uint64(1000), response.MemoryEstimate)
+		suite.Assert().Equal([]*extended.MeteredKind{{
+			Kind:   uint32(common.ComputationKindStatement),
+			Amount: 40,
+		}}, response.ComputationBreakdown)
+		suite.Assert().Empty(response.MemoryBreakdown)
+		suite.Assert().Equal(uint64(100), response.Fees)
+		suite.Assert().Equal(convert.EventsToMessages(events), response.Events)
+		suite.Assert().Equal([]*extended.RegisterEntry{{
+			Owner: []byte("owner"),
+			Key:   []byte("key"),
+			Value: []byte{1},
+		}}, response.StorageDelta)
+		suite.Assert().Equal("transaction failed", response.ErrorMessage)
+		mockEngine.AssertExpectations(suite.T())
+	})
+
+	suite.Run("dry run failure", func() {
+		mockEngine.On("DryRunTransactionAtBlockID", ctx, matchTx, blockID, true).
+			Return(nil, errors.New("state commitment not found")).Once()
+
+		_, err := handler.DryRunTransactionAtBlockID(ctx, req)
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.Internal, status.Code(err))
+		mockEngine.AssertExpectations(suite.T())
+	})
+
+	suite.Run("invalid request without transaction", func() {
+		_, err := handler.DryRunTransactionAtBlockID(ctx, &extended.DryRunTransactionAtBlockIDRequest{
+			BlockId: blockID[:],
+		})
+		suite.Require().Error(err)
+		suite.Assert().Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,504 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0
+// 	protoc        (unknown)
+// source: engine/execution/rpc/extended/extended.proto
+
+package extended
+
+import (
+	entities "github.com/onflow/flow/protobuf/go/flow/entities"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DryRunTransactionAtBlockIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockId     []byte                `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Transaction *entities.Transaction `protobuf:"bytes,2,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	// If set, the signatures of the transaction are not checked, so unsigned transactions can be
+	// estimated.
+	SkipSignatureVerification bool `protobuf:"varint,3,opt,name=skip_signature_verification,json=skipSignatureVerification,proto3" json:"skip_signature_verification,omitempty"`
+}
+
+func (x *DryRunTransactionAtBlockIDRequest) Reset() {
+	*x = DryRunTransactionAtBlockIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DryRunTransactionAtBlockIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunTransactionAtBlockIDRequest) ProtoMessage() {}
+
+func (x *DryRunTransactionAtBlockIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunTransactionAtBlockIDRequest.ProtoReflect.Descriptor instead.
+func (*DryRunTransactionAtBlockIDRequest) Descriptor() ([]byte, []int) {
+	return file_engine_execution_rpc_extended_extended_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DryRunTransactionAtBlockIDRequest) GetBlockId() []byte {
+	if x != nil {
+		return x.BlockId
+	}
+	return nil
+}
+
+func (x *DryRunTransactionAtBlockIDRequest) GetTransaction() *entities.Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *DryRunTransactionAtBlockIDRequest) GetSkipSignatureVerification() bool {
+	if x != nil {
+		return x.SkipSignatureVerification
+	}
+	return false
+}
+
+// MeteredKind is the amount of computation or memory metered for a computation or memory kind.
+type MeteredKind struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind   uint32 `protobuf:"varint,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Amount uint64 `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (x *MeteredKind) Reset() {
+	*x = MeteredKind{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MeteredKind) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MeteredKind) ProtoMessage() {}
+
+func (x *MeteredKind) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MeteredKind.ProtoReflect.Descriptor instead.
+func (*MeteredKind) Descriptor() ([]byte, []int) {
+	return file_engine_execution_rpc_extended_extended_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MeteredKind) GetKind() uint32 {
+	if x != nil {
+		return x.Kind
+	}
+	return 0
+}
+
+func (x *MeteredKind) GetAmount() uint64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+// RegisterEntry is a register the transaction would update, and its new value.
+type RegisterEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Owner []byte `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Key   []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *RegisterEntry) Reset() {
+	*x = RegisterEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterEntry) ProtoMessage() {}
+
+func (x *RegisterEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterEntry.ProtoReflect.Descriptor instead.
+func (*RegisterEntry) Descriptor() ([]byte, []int) {
+	return file_engine_execution_rpc_extended_extended_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RegisterEntry) GetOwner() []byte {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *RegisterEntry) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RegisterEntry) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type DryRunTransactionAtBlockIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ComputationUsed uint64 `protobuf:"varint,1,opt,name=computation_used,json=computationUsed,proto3" json:"computation_used,omitempty"`
+	MemoryEstimate  uint64 `protobuf:"varint,2,opt,name=memory_estimate,json=memoryEstimate,proto3" json:"memory_estimate,omitempty"`
+	// The computation used by each computation kind, and the memory estimate of each memory kind,
+	// excluding the fee deduction and storage checks.
+	ComputationBreakdown []*MeteredKind `protobuf:"bytes,3,rep,name=computation_breakdown,json=computationBreakdown,proto3" json:"computation_breakdown,omitempty"`
+	MemoryBreakdown      []*MeteredKind `protobuf:"bytes,4,rep,name=memory_breakdown,json=memoryBreakdown,proto3" json:"memory_breakdown,omitempty"`
+	// The amount of fees the payer would be charged, as a UFix64.
+	Fees         uint64            `protobuf:"varint,5,opt,name=fees,proto3" json:"fees,omitempty"`
+	Events       []*entities.Event `protobuf:"bytes,6,rep,name=events,proto3" json:"events,omitempty"`
+	StorageDelta []*RegisterEntry  `protobuf:"bytes,7,rep,name=storage_delta,json=storageDelta,proto3" json:"storage_delta,omitempty"`
+	// The error the transaction would fail with, empty if it would succeed.
+	ErrorMessage string `protobuf:"bytes,8,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) Reset() {
+	*x = DryRunTransactionAtBlockIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunTransactionAtBlockIDResponse) ProtoMessage() {}
+
+func (x *DryRunTransactionAtBlockIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_execution_rpc_extended_extended_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunTransactionAtBlockIDResponse.ProtoReflect.Descriptor instead.
+func (*DryRunTransactionAtBlockIDResponse) Descriptor() ([]byte, []int) {
+	return file_engine_execution_rpc_extended_extended_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetComputationUsed() uint64 {
+	if x != nil {
+		return x.ComputationUsed
+	}
+	return 0
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetMemoryEstimate() uint64 {
+	if x != nil {
+		return x.MemoryEstimate
+	}
+	return 0
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetComputationBreakdown() []*MeteredKind {
+	if x != nil {
+		return x.ComputationBreakdown
+	}
+	return nil
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetMemoryBreakdown() []*MeteredKind {
+	if x != nil {
+		return x.MemoryBreakdown
+	}
+	return nil
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetFees() uint64 {
+	if x != nil {
+		return x.Fees
+	}
+	return 0
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetEvents() []*entities.Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetStorageDelta() []*RegisterEntry {
+	if x != nil {
+		return x.StorageDelta
+	}
+	return nil
+}
+
+func (x *DryRunTransactionAtBlockIDResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+var File_engine_execution_rpc_extended_extended_proto protoreflect.FileDescriptor
+
+var file_engine_execution_rpc_extended_extended_proto_rawDesc = []byte{
+	0x0a, 0x2c, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x2f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x2f,
+	0x65, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x17,
+	0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x65,
+	0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x1a, 0x19, 0x66, 0x6c, 0x6f, 0x77, 0x2f, 0x65, 0x6e,
+	0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1f, 0x66, 0x6c, 0x6f, 0x77, 0x2f, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65,
+	0x73, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xbc, 0x01, 0x0a, 0x21, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x49, 0x64, 0x12, 0x3c, 0x0a, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x66, 0x6c, 0x6f, 0x77,
+	0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x3e, 0x0a, 0x1b, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x19, 0x73, 0x6b, 0x69, 0x70, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0x39, 0x0a, 0x0b, 0x4d, 0x65, 0x74, 0x65, 0x72, 0x65, 0x64, 0x4b, 0x69, 0x6e,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x4d, 0x0a,
+	0x0d, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6f,
+	0x77, 0x6e, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xd8, 0x03, 0x0a,
+	0x22, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x41, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x63,
+	0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x73, 0x65, 0x64, 0x12, 0x27,
+	0x0a, 0x0f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x65, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x45,
+	0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x12, 0x59, 0x0a, 0x15, 0x63, 0x6f, 0x6d, 0x70, 0x75,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78,
+	0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64,
+	0x2e, 0x4d, 0x65, 0x74, 0x65, 0x72, 0x65, 0x64, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x14, 0x63, 0x6f,
+	0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f,
+	0x77, 0x6e, 0x12, 0x4f, 0x0a, 0x10, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x72, 0x65,
+	0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x66,
+	0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x65, 0x78,
+	0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x2e, 0x4d, 0x65, 0x74, 0x65, 0x72, 0x65, 0x64, 0x4b, 0x69,
+	0x6e, 0x64, 0x52, 0x0f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64,
+	0x6f, 0x77, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x65, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x04, 0x66, 0x65, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65,
+	0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x4b, 0x0a, 0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x5f, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x66,
+	0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x65, 0x78,
+	0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x44, 0x65, 0x6c,
+	0x74, 0x61, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0xae, 0x01, 0x0a, 0x14, 0x45, 0x78, 0x74, 0x65,
+	0x6e, 0x64, 0x65, 0x64, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x50, 0x49,
+	0x12, 0x95, 0x01, 0x0a, 0x1a, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x12,
+	0x3a, 0x2e, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x2e, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x74, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x3b, 0x2e, 0x66, 0x6c,
+	0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x65, 0x78, 0x74,
+	0x65, 0x6e, 0x64, 0x65, 0x64, 0x2e, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x39, 0x5a, 0x37, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x6e, 0x66, 0x6c, 0x6f, 0x77, 0x2f, 0x66, 0x6c,
+	0x6f, 0x77, 0x2d, 0x67, 0x6f, 0x2f, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x2f, 0x65, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x65, 0x78, 0x74, 0x65, 0x6e,
+	0x64, 0x65, 0x64, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_engine_execution_rpc_extended_extended_proto_rawDescOnce sync.Once
+	file_engine_execution_rpc_extended_extended_proto_rawDescData = file_engine_execution_rpc_extended_extended_proto_rawDesc
+)
+
+func file_engine_execution_rpc_extended_extended_proto_rawDescGZIP() []byte {
+	file_engine_execution_rpc_extended_extended_proto_rawDescOnce.Do(func() {
+		file_engine_execution_rpc_extended_extended_proto_rawDescData = protoimpl.X.CompressGZIP(file_engine_execution_rpc_extended_extended_proto_rawDescData)
+	})
+	return file_engine_execution_rpc_extended_extended_proto_rawDescData
+}
+
+var file_engine_execution_rpc_extended_extended_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_engine_execution_rpc_extended_extended_proto_goTypes = []interface{}{
+	(*DryRunTransactionAtBlockIDRequest)(nil),  // 0: flow.execution.extended.DryRunTransactionAtBlockIDRequest
+	(*MeteredKind)(nil),                        // 1: flow.execution.extended.MeteredKind
+	(*RegisterEntry)(nil),                      // 2: flow.execution.extended.RegisterEntry
+	(*DryRunTransactionAtBlockIDResponse)(nil), // 3: flow.execution.extended.DryRunTransactionAtBlockIDResponse
+	(*entities.Transaction)(nil),               // 4: flow.entities.Transaction
+	(*entities.Event)(nil),                     // 5: flow.entities.Event
+}
+var file_engine_execution_rpc_extended_extended_proto_depIdxs = []int32{
+	4, // 0: flow.execution.extended.DryRunTransactionAtBlockIDRequest.transaction:type_name -> flow.entities.Transaction
+	1, // 1: flow.execution.extended.DryRunTransactionAtBlockIDResponse.computation_breakdown:type_name -> flow.execution.extended.MeteredKind
+	1, // 2: flow.execution.extended.DryRunTransactionAtBlockIDResponse.memory_breakdown:type_name -> flow.execution.extended.MeteredKind
+	5, // 3: flow.execution.extended.DryRunTransactionAtBlockIDResponse.events:type_name -> flow.entities.Event
+	2, // 4: flow.execution.extended.DryRunTransactionAtBlockIDResponse.storage_delta:type_name -> flow.execution.extended.RegisterEntry
+	0, // 5: flow.execution.extended.ExtendedExecutionAPI.DryRunTransactionAtBlockID:input_type -> flow.execution.extended.DryRunTransactionAtBlockIDRequest
+	3, // 6: flow.execution.extended.ExtendedExecutionAPI.DryRunTransactionAtBlockID:output_type -> flow.execution.extended.DryRunTransactionAtBlockIDResponse
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_engine_execution_rpc_extended_extended_proto_init() }
+func file_engine_execution_rpc_extended_extended_proto_init() {
+	if File_engine_execution_rpc_extended_extended_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_engine_execution_rpc_extended_extended_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DryRunTransactionAtBlockIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_engine_execution_rpc_extended_extended_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MeteredKind); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_engine_execution_rpc_extended_extended_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_engine_execution_rpc_extended_extended_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DryRunTransactionAtBlockIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_engine_execution_rpc_extended_extended_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_engine_execution_rpc_extended_extended_proto_goTypes,
+		DependencyIndexes: file_engine_execution_rpc_extended_extended_proto_depIdxs,
+		MessageInfos:      file_engine_execution_rpc_extended_extended_proto_msgTypes,
+	}.Build()
+	File_engine_execution_rpc_extended_extended_proto = out.File
+	file_engine_execution_rpc_extended_extended_proto_rawDesc = nil
+	file_engine_execution_rpc_extended_extended_proto_goTypes = nil
+	file_engine_execution_rpc_extended_extended_proto_depIdxs = nil
+}
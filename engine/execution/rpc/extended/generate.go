@@ -0,0 +1,8 @@
+// Package extended contains the gRPC service of the methods served by flow-go execution nodes in
+// addition to the flow.execution.ExecutionAPI defined in the onflow/flow repository.
+//
+// The service imports the flow protobuf definitions, so generating the code requires the protobuf
+// directory of a checkout of the onflow/flow repository to be set as FLOW_PROTOBUF_DIR.
+package extended
+
+//go:generate protoc -I../../../.. -I${FLOW_PROTOBUF_DIR} --go_out=../../../.. --go_opt=paths=source_relative --go-grpc_out=../../../.. --go-grpc_opt=paths=source_relative engine/execution/rpc/extended/extended.proto
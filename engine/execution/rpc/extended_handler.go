@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/engine/execution/ingestion"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/execution"
+)
+
+// extendedHandler implements the extended.ExtendedExecutionAPI, the methods of the execution API
+// which are not part of the flow.execution.ExecutionAPI.
+type extendedHandler struct {
+	extended.UnimplementedExtendedExecutionAPIServer
+
+	engine ingestion.IngestRPC
+	chain  flow.ChainID
+}
+
+var _ extended.ExtendedExecutionAPIServer = &extendedHandler{}
+
+// DryRunTransactionAtBlockID executes the transaction against the execution state of the block,
+// without committing its changes.
+func (h *extendedHandler) DryRunTransactionAtBlockID(
+	ctx context.Context,
+	req *extended.DryRunTransactionAtBlockIDRequest,
+) (*extended.DryRunTransactionAtBlockIDResponse, error) {
+
+	blockID, err := convert.BlockID(req.GetBlockId())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetTransaction() == nil {
+		return nil, status.Error(codes.InvalidArgument, "transaction is required")
+	}
+
+	tx, err := convert.MessageToTransaction(req.GetTransaction(), h.chain.Chain())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction: %v", err)
+	}
+
+	result, err := h.engine.DryRunTransactionAtBlockID(ctx, &tx, blockID, req.GetSkipSignatureVerification())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to dry run transaction: %v", err)
+	}
+
+	return dryRunResultToMessage(result), nil
+}
+
+// dryRunResultToMessage converts the result of a transaction dry run to its protobuf message.
+func dryRunResultToMessage(result *execution.TransactionDryRunResult) *extended.DryRunTransactionAtBlockIDResponse {
+	computationBreakdown := make([]*extended.MeteredKind, 0, len(result.ComputationBreakdown))
+	for kind, used := range result.ComputationBreakdown {
+		computationBreakdown = append(computationBreakdown, &extended.MeteredKind{
+			Kind:   uint32(kind),
+			Amount: used,
+		})
+	}
+
+	memoryBreakdown := make([]*extended.MeteredKind, 0, len(result.MemoryBreakdown))
+	for kind, estimate := range result.MemoryBreakdown {
+		memoryBreakdown = append(memoryBreakdown, &extended.MeteredKind{
+			Kind:   uint32(kind),
+			Amount: estimate,
+		})
+	}
+
+	storageDelta := make([]*extended.RegisterEntry, len(result.StorageDelta))
+	for i, entry := range result.StorageDelta {
+		storageDelta[i] = &extended.RegisterEntry{
+			Owner: []byte(entry.Key.Owner),
+			Key:   []byte(entry.Key.Key),
+			Value: entry.Value,
+		}
+	}
+
+	response := &extended.DryRunTransactionAtBlockIDResponse{
+		ComputationUsed:      result.ComputationUsed,
+		MemoryEstimate:       result.MemoryEstimate,
+		ComputationBreakdown: computationBreakdown,
+		MemoryBreakdown:      memoryBreakdown,
+		Fees:                 result.Fees,
+		Events:               convert.EventsToMessages(result.Events),
+		StorageDelta:         storageDelta,
+	}
+	if result.Err != nil {
+		response.ErrorMessage = result.Err.Error()
+	}
+
+	return response
+}
@@ -594,6 +594,7 @@ func ExecutionNode(t *testing.T, hub *stub.Hub, identity *flow.Identity, identit
 		vm,
 		vmCtx,
 		computation.DefaultProgramsCacheSize,
+		computation.DefaultProgramsCacheMaxPrograms,
 		committer,
 		computation.DefaultScriptLogThreshold,
 		computation.DefaultScriptExecutionTimeLimit,
@@ -0,0 +1,202 @@
+package blueprints
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ExecutionParametersPathIdentifier is the identifier of the storage path of the execution parameters record.
+//
+// The record is a dictionary of type {UInt64: {String: UInt64}}, mapping activation heights to the encoded
+// parameters that apply to the blocks from that height on, until the next activation height.
+const ExecutionParametersPathIdentifier = "executionParameters"
+
+// ExecutionParametersVersion is the latest version of the execution parameters encoding.
+//
+// The version must be incremented whenever the meaning of the encoded parameters changes,
+// so that nodes which don't support the new version stop executing instead of diverging.
+const ExecutionParametersVersion uint64 = 1
+
+// Keys of the encoded execution parameters.
+const (
+	ExecutionParametersVersionKey                      = "version"
+	ExecutionParametersComputationLimitKey             = "computationLimit"
+	ExecutionParametersMemoryLimitKey                  = "memoryLimit"
+	ExecutionParametersMaxStateKeySizeKey              = "maxStateKeySize"
+	ExecutionParametersMaxStateValueSizeKey            = "maxStateValueSize"
+	ExecutionParametersMaxStateInteractionSizeKey      = "maxStateInteractionSize"
+	ExecutionParametersEventCollectionByteSizeLimitKey = "eventCollectionByteSizeLimit"
+	ExecutionParametersRestrictContractDeploymentKey   = "restrictContractDeployment"
+	ExecutionParametersRestrictContractRemovalKey      = "restrictContractRemoval"
+	ExecutionParametersLimitAccountStorageKey          = "limitAccountStorage"
+)
+
+// ExecutionParameters are the limits of the execution context stored in the execution parameters record.
+type ExecutionParameters struct {
+	Version                      uint64
+	ComputationLimit             uint64
+	MemoryLimit                  uint64
+	MaxStateKeySize              uint64
+	MaxStateValueSize            uint64
+	MaxStateInteractionSize      uint64
+	EventCollectionByteSizeLimit uint64
+	RestrictContractDeployment   bool
+	RestrictContractRemoval      bool
+	LimitAccountStorage          bool
+}
+
+// Encode returns the parameters encoded as stored in the execution parameters record.
+// Flags are encoded as 0 or 1.
+func (p ExecutionParameters) Encode() map[string]uint64 {
+	return map[string]uint64{
+		ExecutionParametersVersionKey:                      p.Version,
+		ExecutionParametersComputationLimitKey:             p.ComputationLimit,
+		ExecutionParametersMemoryLimitKey:                  p.MemoryLimit,
+		ExecutionParametersMaxStateKeySizeKey:              p.MaxStateKeySize,
+		ExecutionParametersMaxStateValueSizeKey:            p.MaxStateValueSize,
+		ExecutionParametersMaxStateInteractionSizeKey:      p.MaxStateInteractionSize,
+		ExecutionParametersEventCollectionByteSizeLimitKey: p.EventCollectionByteSizeLimit,
+		ExecutionParametersRestrictContractDeploymentKey:   encodeFlag(p.RestrictContractDeployment),
+		ExecutionParametersRestrictContractRemovalKey:      encodeFlag(p.RestrictContractRemoval),
+		ExecutionParametersLimitAccountStorageKey:          encodeFlag(p.LimitAccountStorage),
+	}
+}
+
+// DecodeExecutionParameters decodes parameters encoded with ExecutionParameters.Encode.
+// The version of the encoding must be supported, and all parameters must be present.
+func DecodeExecutionParameters(encoded map[string]uint64) (ExecutionParameters, error) {
+	var p ExecutionParameters
+
+	version, ok := encoded[ExecutionParametersVersionKey]
+	if !ok {
+		return p, fmt.Errorf("missing execution parameter %s", ExecutionParametersVersionKey)
+	}
+	if version == 0 || version > ExecutionParametersVersion {
+		return p, fmt.Errorf("unsupported execution parameters version %d", version)
+	}
+	p.Version = version
+
+	values := map[string]*uint64{
+		ExecutionParametersComputationLimitKey:             &p.ComputationLimit,
+		ExecutionParametersMemoryLimitKey:                  &p.MemoryLimit,
+		ExecutionParametersMaxStateKeySizeKey:              &p.MaxStateKeySize,
+		ExecutionParametersMaxStateValueSizeKey:            &p.MaxStateValueSize,
+		ExecutionParametersMaxStateInteractionSizeKey:      &p.MaxStateInteractionSize,
+		ExecutionParametersEventCollectionByteSizeLimitKey: &p.EventCollectionByteSizeLimit,
+	}
+	for key, value := range values {
+		v, ok := encoded[key]
+		if !ok {
+			return p, fmt.Errorf("missing execution parameter %s", key)
+		}
+		*value = v
+	}
+
+	flags := map[string]*bool{
+		ExecutionParametersRestrictContractDeploymentKey: &p.RestrictContractDeployment,
+		ExecutionParametersRestrictContractRemovalKey:    &p.RestrictContractRemoval,
+		ExecutionParametersLimitAccountStorageKey:        &p.LimitAccountStorage,
+	}
+	for key, flag := range flags {
+		v, ok := encoded[key]
+		if !ok {
+			return p, fmt.Errorf("missing execution parameter %s", key)
+		}
+		if v > 1 {
+			return p, fmt.Errorf("invalid value %d of execution parameter %s", v, key)
+		}
+		*flag = v == 1
+	}
+
+	return p, nil
+}
+
+func encodeFlag(flag bool) uint64 {
+	if flag {
+		return 1
+	}
+	return 0
+}
+
+// SetExecutionParametersTransaction creates a transaction that schedules the given execution parameters
+// to apply to the blocks from the given activation height on.
+//
+// The activation height must be above the height of the block the transaction is executed in,
+// so that all the chunks of a block are executed with the same parameters.
+// Parameters that were previously scheduled at the same height are replaced,
+// and the parameters superseded by already active ones are removed from the record.
+func SetExecutionParametersTransaction(
+	service flow.Address,
+	activationHeight uint64,
+	params ExecutionParameters,
+) (*flow.TransactionBody, error) {
+	encoded := params.Encode()
+	pairs := make([]cadence.KeyValuePair, 0, len(encoded))
+	for k, v := range encoded {
+		pairs = append(pairs, cadence.KeyValuePair{
+			Key:   cadence.String(k),
+			Value: cadence.UInt64(v),
+		})
+	}
+
+	height, err := jsoncdc.Encode(cadence.UInt64(activationHeight))
+	if err != nil {
+		return nil, err
+	}
+
+	parameters, err := jsoncdc.Encode(cadence.NewDictionary(pairs))
+	if err != nil {
+		return nil, err
+	}
+
+	storagePath, err := jsoncdc.Encode(cadence.Path{
+		Domain:     TransactionExecutionParametersPathDomain,
+		Identifier: ExecutionParametersPathIdentifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := flow.NewTransactionBody().
+		SetScript([]byte(setExecutionParametersScript)).
+		AddArgument(height).
+		AddArgument(parameters).
+		AddArgument(storagePath).
+		AddAuthorizer(service)
+
+	return tx, nil
+}
+
+const setExecutionParametersScript = `
+	transaction(activationHeight: UInt64, parameters: {String: UInt64}, path: StoragePath) {
+		prepare(signer: AuthAccount) {
+			let currentHeight = getCurrentBlock().height
+			if activationHeight <= currentHeight {
+				panic("execution parameters can only be scheduled for future blocks")
+			}
+
+			var records: {UInt64: {String: UInt64}} = signer.load<{UInt64: {String: UInt64}}>(from: path) ?? {}
+			records[activationHeight] = parameters
+
+			var activeHeight: UInt64? = nil
+			for height in records.keys {
+				if height <= currentHeight && (activeHeight == nil || height > activeHeight!) {
+					activeHeight = height
+				}
+			}
+			if let active = activeHeight {
+				for height in records.keys {
+					if height < active {
+						records.remove(key: height)
+					}
+				}
+			}
+
+			signer.save(records, to: path)
+		}
+	}
+`
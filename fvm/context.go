@@ -20,13 +20,19 @@ type Context struct {
 	Tracer  module.Tracer
 	// AllowContextOverrideByExecutionState is a flag telling the fvm to override certain parts of the context from the state
 	AllowContextOverrideByExecutionState bool
-	ComputationLimit                     uint64
-	MemoryLimit                          uint64
-	MaxStateKeySize                      uint64
-	MaxStateValueSize                    uint64
-	MaxStateInteractionSize              uint64
-	EventCollectionByteSizeLimit         uint64
-	BlockHeader                          *flow.Header
+	// ExecutionParametersActivationHeight is the height of the first block executed with the execution
+	// parameters record stored in the service account, see VirtualMachine.ApplyExecutionParameters
+	ExecutionParametersActivationHeight uint64
+	// ExecutionParametersApplied is set when the limits of the context were set by the execution parameters
+	// record stored in the service account, see VirtualMachine.ApplyExecutionParameters
+	ExecutionParametersApplied   bool
+	ComputationLimit             uint64
+	MemoryLimit                  uint64
+	MaxStateKeySize              uint64
+	MaxStateValueSize            uint64
+	MaxStateInteractionSize      uint64
+	EventCollectionByteSizeLimit uint64
+	BlockHeader                  *flow.Header
 	// NOTE: The ServiceAccountEnabled option is used by the playground
 	// https://github.com/onflow/flow-playground-api/blob/1ad967055f31db8f1ce88e008960e5fc14a9fbd1/compute/computer.go#L76
 	ServiceAccountEnabled bool
@@ -39,6 +45,8 @@ type Context struct {
 	CadenceLoggingEnabled         bool
 	ServiceEventCollectionEnabled bool
 	ExtensiveTracing              bool
+	MeteringBreakdownEnabled      bool
+	ExecutionTracer               *environment.ExecutionTracer
 	TransactionProcessors         []TransactionProcessor
 	ScriptProcessors              []ScriptProcessor
 	Logger                        zerolog.Logger
@@ -77,6 +85,7 @@ func defaultContext(logger zerolog.Logger) Context {
 		Metrics:                              &handler.NoopMetricsReporter{},
 		Tracer:                               nil,
 		AllowContextOverrideByExecutionState: true,
+		ExecutionParametersActivationHeight:  math.MaxUint64,
 		ComputationLimit:                     DefaultComputationLimit,
 		MemoryLimit:                          DefaultMemoryLimit,
 		MaxStateKeySize:                      state.DefaultMaxKeySize,
@@ -90,6 +99,7 @@ func defaultContext(logger zerolog.Logger) Context {
 		CadenceLoggingEnabled:                false,
 		ServiceEventCollectionEnabled:        false,
 		ExtensiveTracing:                     false,
+		MeteringBreakdownEnabled:             false,
 		TransactionProcessors: []TransactionProcessor{
 			NewTransactionVerifier(AccountKeyWeightThreshold),
 			NewTransactionSequenceNumberChecker(),
@@ -130,6 +140,15 @@ func WithAllowContextOverrideByExecutionState(load bool) Option {
 	}
 }
 
+// WithExecutionParametersActivationHeight sets the height of the first block executed with the execution
+// parameters record stored in the service account, see ExecutionParametersActivationHeight.
+func WithExecutionParametersActivationHeight(height uint64) Option {
+	return func(ctx Context) Context {
+		ctx.ExecutionParametersActivationHeight = height
+		return ctx
+	}
+}
+
 // WithComputationLimit sets the computation limit for a virtual machine context.
 func WithComputationLimit(limit uint64) Option {
 	return func(ctx Context) Context {
@@ -206,6 +225,24 @@ func WithExtensiveTracing() Option {
 	}
 }
 
+// WithMeteringBreakdown enables or disables recording the computation and memory used by each
+// computation and memory kind in the results of transactions.
+func WithMeteringBreakdown(enabled bool) Option {
+	return func(ctx Context) Context {
+		ctx.MeteringBreakdownEnabled = enabled
+		return ctx
+	}
+}
+
+// WithExecutionTracer sets the tracer that records the execution trace of the procedure run with
+// the context. Execution traces are expensive to record and only meant for debugging.
+func WithExecutionTracer(tracer *environment.ExecutionTracer) Option {
+	return func(ctx Context) Context {
+		ctx.ExecutionTracer = tracer
+		return ctx
+	}
+}
+
 // WithBlocks sets the block storage provider for a virtual machine context.
 //
 // The VM uses the block storage provider to provide historical block information to
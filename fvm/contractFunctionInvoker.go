@@ -47,6 +47,9 @@ func (i *ContractFunctionInvoker) Invoke(env Environment) (cadence.Value, error)
 
 	predeclaredValues := valueDeclarations(env)
 
+	env.EnterContract(i.contractLocation)
+	defer env.ExitContract()
+
 	value, err := env.VM().Runtime.InvokeContractFunction(
 		i.contractLocation,
 		i.functionName,
@@ -3,6 +3,7 @@ package fvm
 import (
 	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/onflow/atree"
 	"github.com/onflow/cadence"
@@ -37,6 +38,10 @@ type Environment interface {
 
 	StartSpanFromRoot(name trace.SpanName) otelTrace.Span
 	StartExtensiveTracingSpanFromRoot(name trace.SpanName) otelTrace.Span
+
+	// EnterContract and ExitContract delimit the execution of contract code, see meter.Meter
+	EnterContract(location common.AddressLocation)
+	ExitContract()
 }
 
 // TODO(patrick): refactor this into an object
@@ -99,6 +104,20 @@ func (env *commonEnv) VM() *VirtualMachine {
 	return env.vm
 }
 
+// RecordTrace records an operation of the Cadence runtime.
+//
+// The Cadence runtime only reports the location of the code it is executing in the traces it
+// records (if tracing is enabled in the runtime): the operations executed by a function are
+// recorded with the location of the function, and its invocation is recorded with the location
+// of the caller once it returned. The computation and memory metered from now on are therefore
+// attributed to the contract at the location of the trace, if any.
+func (env *commonEnv) RecordTrace(operation string, location common.Location, duration time.Duration, attrs []attribute.KeyValue) {
+	if location != nil {
+		env.SetExecutingLocation(location)
+	}
+	env.ProgramLogger.RecordTrace(operation, location, duration, attrs)
+}
+
 // GetCurrentBlockHeight returns the current block height.
 func (env *commonEnv) GetCurrentBlockHeight() (uint64, error) {
 	defer env.StartExtensiveTracingSpanFromRoot(trace.FVMEnvGetCurrentBlockHeight).End()
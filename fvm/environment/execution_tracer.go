@@ -0,0 +1,375 @@
+package environment
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/onflow/flow-go/module/trace"
+)
+
+// ExecutionTraceEventKind is the kind of an event of an execution trace.
+type ExecutionTraceEventKind string
+
+const (
+	// ExecutionTraceEventCadenceFunction is the invocation of a Cadence function.
+	ExecutionTraceEventCadenceFunction ExecutionTraceEventKind = "cadence_function"
+	// ExecutionTraceEventCadenceOperation is an operation of the Cadence runtime, e.g. checking a
+	// program or transferring a value.
+	ExecutionTraceEventCadenceOperation ExecutionTraceEventKind = "cadence_operation"
+	// ExecutionTraceEventHostFunction is the invocation of a function the FVM provides to the
+	// Cadence runtime, e.g. reading a register or emitting an event.
+	ExecutionTraceEventHostFunction ExecutionTraceEventKind = "host_function"
+)
+
+// cadenceFunctionTracePrefix is the prefix of the operations the Cadence runtime records for
+// function invocations.
+const cadenceFunctionTracePrefix = "function."
+
+// ExecutionTraceEvent is a function invocation or operation recorded in an execution trace.
+type ExecutionTraceEvent struct {
+	Kind       ExecutionTraceEventKind `json:"kind"`
+	Name       string                  `json:"name"`
+	Location   string                  `json:"location,omitempty"`
+	Attributes map[string]string       `json:"attributes,omitempty"`
+	// Start is the time the event started at, relative to the start of the trace.
+	Start    time.Duration `json:"start_ns"`
+	Duration time.Duration `json:"duration_ns"`
+	// ComputationUsed and MemoryEstimate are the computation and memory metered during the event,
+	// including the events nested in it. They are only recorded for functions, as the Cadence runtime
+	// reports its operations once they completed.
+	ComputationUsed uint64 `json:"computation_used"`
+	MemoryEstimate  uint64 `json:"memory_estimate"`
+	// Parent is the index of the event this event is nested in, or -1 for top level events.
+	Parent int `json:"parent"`
+}
+
+// End returns the time the event ended at, relative to the start of the trace.
+func (e ExecutionTraceEvent) End() time.Duration {
+	return e.Start + e.Duration
+}
+
+// ExecutionTraceStatement is the number of times the statements starting on a line of a program
+// were executed.
+type ExecutionTraceStatement struct {
+	Location string `json:"location"`
+	Line     int    `json:"line"`
+	Hits     int    `json:"hits"`
+}
+
+// ExecutionTrace is the trace of the execution of a procedure. Events are ordered by their start,
+// and events nested in another event follow it. Statements are ordered by location and line.
+type ExecutionTrace struct {
+	Duration        time.Duration             `json:"duration_ns"`
+	ComputationUsed uint64                    `json:"computation_used"`
+	MemoryEstimate  uint64                    `json:"memory_estimate"`
+	Events          []ExecutionTraceEvent     `json:"events"`
+	Statements      []ExecutionTraceStatement `json:"statements,omitempty"`
+}
+
+// meterTotals is the computation used and memory estimate of the meter at a point of the execution.
+type meterTotals struct {
+	computationUsed uint64
+	memoryEstimate  uint64
+}
+
+// usedSince returns the computation and memory metered since the given totals.
+func (m meterTotals) usedSince(start meterTotals) (uint64, uint64) {
+	return saturatingSub(m.computationUsed, start.computationUsed),
+		saturatingSub(m.memoryEstimate, start.memoryEstimate)
+}
+
+// ExecutionTracer records an execution trace of a procedure: the Cadence functions it invokes,
+// the host functions it calls and the computation and memory metered during each of them.
+//
+// Recording a trace is expensive and only meant for debugging. A tracer records a single
+// procedure, and is provided to the FVM using the context.
+// Cadence functions are only recorded if tracing is enabled in the Cadence runtime, and executed
+// statements if the coverage report of the tracer is set in the Cadence runtime.
+type ExecutionTracer struct {
+	mu    sync.Mutex
+	start time.Time
+	meter Meter
+	// invocations are the meter totals at the invocation of the Cadence functions which did not
+	// return yet, innermost last.
+	invocations []meterTotals
+	events      []ExecutionTraceEvent
+	coverage    *runtime.CoverageReport
+}
+
+func NewExecutionTracer() *ExecutionTracer {
+	return &ExecutionTracer{
+		start:    time.Now(),
+		coverage: runtime.NewCoverageReport(),
+	}
+}
+
+// CoverageReport returns the report in which the Cadence runtime counts the executed statements of
+// each line, when it is set using runtime.Runtime.SetCoverageReport. The runtime is the only source
+// of statement positions, and it only reports them to a coverage report.
+//
+// The report is not synchronized, so Trace must only be called once the procedure was executed.
+func (t *ExecutionTracer) CoverageReport() *runtime.CoverageReport {
+	return t.coverage
+}
+
+// TraceMeter returns a meter that records the computation used and memory estimate of the given
+// meter each time a Cadence function is invoked, so that the meter usage of functions can be
+// recorded once they returned.
+func (t *ExecutionTracer) TraceMeter(meter Meter) Meter {
+	if t == nil {
+		return meter
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.meter = meter
+	return &tracingMeter{Meter: meter, tracer: t}
+}
+
+// now returns the time since the start of the trace.
+func (t *ExecutionTracer) now() time.Duration {
+	return time.Since(t.start)
+}
+
+// totals returns the current computation used and memory estimate of the meter.
+func (t *ExecutionTracer) totals() meterTotals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.totalsLocked()
+}
+
+func (t *ExecutionTracer) totalsLocked() meterTotals {
+	if t.meter == nil {
+		return meterTotals{}
+	}
+	return meterTotals{
+		computationUsed: t.meter.ComputationUsed(),
+		memoryEstimate:  t.meter.MemoryEstimate(),
+	}
+}
+
+// invokeFunction records the meter totals at the invocation of a Cadence function.
+func (t *ExecutionTracer) invokeFunction() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.invocations = append(t.invocations, t.totalsLocked())
+}
+
+// returnFunction returns the meter totals at the invocation of the innermost Cadence function,
+// which returned.
+func (t *ExecutionTracer) returnFunction() meterTotals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last := len(t.invocations) - 1
+	if last < 0 {
+		// the invocation of an optional chain on nil is recorded, but doesn't invoke a function
+		return t.totalsLocked()
+	}
+
+	start := t.invocations[last]
+	t.invocations = t.invocations[:last]
+	return start
+}
+
+func (t *ExecutionTracer) recordEvent(event ExecutionTraceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, event)
+}
+
+// startHostFunction returns a span that records a host function event when it ends.
+func (t *ExecutionTracer) startHostFunction(name trace.SpanName, span otelTrace.Span) otelTrace.Span {
+	if t == nil {
+		return span
+	}
+
+	return &executionTraceSpan{
+		Span:        span,
+		tracer:      t,
+		name:        string(name),
+		start:       t.now(),
+		startTotals: t.totals(),
+	}
+}
+
+// recordCadenceTrace records an operation traced by the Cadence runtime, which reports operations
+// once they completed. Functions report their meter usage since their invocation.
+func (t *ExecutionTracer) recordCadenceTrace(
+	operation string,
+	location common.Location,
+	duration time.Duration,
+	attrs []attribute.KeyValue,
+) {
+	if t == nil {
+		return
+	}
+
+	end := t.now()
+
+	event := ExecutionTraceEvent{
+		Kind:       ExecutionTraceEventCadenceOperation,
+		Name:       operation,
+		Attributes: attributesToMap(attrs),
+		Start:      end - duration,
+		Duration:   duration,
+	}
+	if location != nil {
+		event.Location = location.String()
+	}
+
+	if strings.HasPrefix(operation, cadenceFunctionTracePrefix) {
+		event.Kind = ExecutionTraceEventCadenceFunction
+		event.Name = strings.TrimPrefix(operation, cadenceFunctionTracePrefix)
+
+		start := t.returnFunction()
+		event.ComputationUsed, event.MemoryEstimate = t.totals().usedSince(start)
+	}
+
+	t.recordEvent(event)
+}
+
+// Trace returns the trace recorded so far.
+func (t *ExecutionTracer) Trace() *ExecutionTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	total := t.totalsLocked()
+
+	events := make([]ExecutionTraceEvent, len(t.events))
+	copy(events, t.events)
+
+	// order parents before the events nested in them
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Start != events[j].Start {
+			return events[i].Start < events[j].Start
+		}
+		return events[i].Duration > events[j].Duration
+	})
+
+	// the stack of the indices of the events enclosing the current event
+	var stack []int
+	for i := range events {
+		event := &events[i]
+
+		for len(stack) > 0 && events[stack[len(stack)-1]].End() < event.End() {
+			stack = stack[:len(stack)-1]
+		}
+
+		event.Parent = -1
+		if len(stack) > 0 {
+			event.Parent = stack[len(stack)-1]
+		}
+		stack = append(stack, i)
+	}
+
+	return &ExecutionTrace{
+		Duration:        t.now(),
+		ComputationUsed: total.computationUsed,
+		MemoryEstimate:  total.memoryEstimate,
+		Events:          events,
+		Statements:      t.statements(),
+	}
+}
+
+// statements returns the statement hits recorded in the coverage report.
+func (t *ExecutionTracer) statements() []ExecutionTraceStatement {
+	var statements []ExecutionTraceStatement
+	for location, coverage := range t.coverage.Coverage {
+		for line, hits := range coverage.LineHits {
+			statements = append(statements, ExecutionTraceStatement{
+				Location: string(location),
+				Line:     line,
+				Hits:     hits,
+			})
+		}
+	}
+
+	sort.Slice(statements, func(i, j int) bool {
+		if statements[i].Location != statements[j].Location {
+			return statements[i].Location < statements[j].Location
+		}
+		return statements[i].Line < statements[j].Line
+	})
+
+	return statements
+}
+
+// saturatingSub returns a-b, or 0 if b is larger than a. Meters may be reset during a procedure,
+// e.g. when the changes of a failed transaction are dropped.
+func saturatingSub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+func attributesToMap(attrs []attribute.KeyValue) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		m[string(attr.Key)] = attr.Value.Emit()
+	}
+	return m
+}
+
+// tracingMeter records the meter totals each time the Cadence runtime invokes a function. The
+// runtime meters each invocation it traces once, after evaluating the arguments of the function.
+type tracingMeter struct {
+	Meter
+	tracer *ExecutionTracer
+}
+
+func (m *tracingMeter) MeterComputation(kind common.ComputationKind, intensity uint) error {
+	if kind == common.ComputationKindFunctionInvocation {
+		m.tracer.invokeFunction()
+	}
+	return m.Meter.MeterComputation(kind, intensity)
+}
+
+// executionTraceSpan records a host function event when it ends.
+type executionTraceSpan struct {
+	otelTrace.Span
+
+	tracer      *ExecutionTracer
+	name        string
+	start       time.Duration
+	startTotals meterTotals
+	attrs       []attribute.KeyValue
+}
+
+func (s *executionTraceSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.attrs = append(s.attrs, kv...)
+	s.Span.SetAttributes(kv...)
+}
+
+func (s *executionTraceSpan) End(options ...otelTrace.SpanEndOption) {
+	end := s.tracer.now()
+	computationUsed, memoryEstimate := s.tracer.totals().usedSince(s.startTotals)
+
+	s.tracer.recordEvent(ExecutionTraceEvent{
+		Kind:            ExecutionTraceEventHostFunction,
+		Name:            s.name,
+		Attributes:      attributesToMap(s.attrs),
+		Start:           s.start,
+		Duration:        end - s.start,
+		ComputationUsed: computationUsed,
+		MemoryEstimate:  memoryEstimate,
+	})
+
+	s.Span.End(options...)
+}
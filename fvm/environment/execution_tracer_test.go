@@ -0,0 +1,121 @@
+package environment_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/trace"
+)
+
+type testMeter struct {
+	computationUsed uint64
+	memoryEstimate  uint64
+}
+
+func (m *testMeter) MeterComputation(_ common.ComputationKind, intensity uint) error {
+	m.computationUsed += uint64(intensity)
+	return nil
+}
+
+func (m *testMeter) ComputationUsed() uint64 {
+	return m.computationUsed
+}
+
+func (m *testMeter) MeterMemory(usage common.MemoryUsage) error {
+	m.memoryEstimate += usage.Amount
+	return nil
+}
+
+func (m *testMeter) MemoryEstimate() uint64 {
+	return m.memoryEstimate
+}
+
+func (m *testMeter) EnterContract(common.AddressLocation) {}
+
+func (m *testMeter) ExitContract() {}
+
+func (m *testMeter) SetExecutingLocation(common.Location) {}
+
+func TestExecutionTracer(t *testing.T) {
+	executionTracer := environment.NewExecutionTracer()
+	tracer := environment.NewTracer(nil, nil, false, executionTracer)
+	logger := environment.NewProgramLogger(tracer, zerolog.Nop(), metrics.NewNoopCollector(), false)
+	meter := executionTracer.TraceMeter(&testMeter{})
+
+	location := common.StringLocation("test")
+
+	span := tracer.StartSpanFromRoot(trace.FVMEnvGetValue)
+	span.SetAttributes(attribute.String("key", "value"))
+
+	require.NoError(t, meter.MeterComputation(common.ComputationKindStatement, 10))
+	require.NoError(t, meter.MeterMemory(common.MemoryUsage{Kind: common.MemoryKindBytes, Amount: 20}))
+
+	// the Cadence runtime meters the invocation of a function once its arguments are evaluated,
+	// and records the function once it returned
+	functionStart := time.Now()
+	require.NoError(t, meter.MeterComputation(common.ComputationKindFunctionInvocation, 1))
+	require.NoError(t, meter.MeterComputation(common.ComputationKindStatement, 5))
+
+	nestedFunctionStart := time.Now()
+	require.NoError(t, meter.MeterComputation(common.ComputationKindFunctionInvocation, 1))
+	require.NoError(t, meter.MeterComputation(common.ComputationKindStatement, 2))
+	require.NoError(t, meter.MeterMemory(common.MemoryUsage{Kind: common.MemoryKindBytes, Amount: 4}))
+	nestedFunctionDuration := time.Since(nestedFunctionStart)
+	logger.RecordTrace("function.bar", location, nestedFunctionDuration, nil)
+
+	require.NoError(t, meter.MeterComputation(common.ComputationKindStatement, 1))
+	functionDuration := time.Since(functionStart)
+	logger.RecordTrace("function.foo", location, functionDuration, nil)
+
+	span.End()
+
+	// the Cadence runtime counts the executed statements of each line in the coverage report
+	executionTracer.CoverageReport().AddLineHit(location, 3)
+	executionTracer.CoverageReport().AddLineHit(location, 3)
+	executionTracer.CoverageReport().AddLineHit(location, 1)
+
+	// spans started without an execution tracer are not recorded
+	environment.NewTracer(nil, nil, false, nil).StartSpanFromRoot(trace.FVMEnvSetValue).End()
+
+	executionTrace := executionTracer.Trace()
+	require.Equal(t, uint64(20), executionTrace.ComputationUsed)
+	require.Equal(t, uint64(24), executionTrace.MemoryEstimate)
+	require.Len(t, executionTrace.Events, 3)
+
+	hostFunction := executionTrace.Events[0]
+	require.Equal(t, environment.ExecutionTraceEventHostFunction, hostFunction.Kind)
+	require.Equal(t, string(trace.FVMEnvGetValue), hostFunction.Name)
+	require.Equal(t, map[string]string{"key": "value"}, hostFunction.Attributes)
+	require.Equal(t, uint64(20), hostFunction.ComputationUsed)
+	require.Equal(t, uint64(24), hostFunction.MemoryEstimate)
+	require.Equal(t, -1, hostFunction.Parent)
+
+	function := executionTrace.Events[1]
+	require.Equal(t, environment.ExecutionTraceEventCadenceFunction, function.Kind)
+	require.Equal(t, "foo", function.Name)
+	require.Equal(t, location.String(), function.Location)
+	require.Equal(t, functionDuration, function.Duration)
+	require.Equal(t, uint64(10), function.ComputationUsed)
+	require.Equal(t, uint64(4), function.MemoryEstimate)
+	require.Equal(t, 0, function.Parent)
+
+	nestedFunction := executionTrace.Events[2]
+	require.Equal(t, environment.ExecutionTraceEventCadenceFunction, nestedFunction.Kind)
+	require.Equal(t, "bar", nestedFunction.Name)
+	require.Equal(t, nestedFunctionDuration, nestedFunction.Duration)
+	require.Equal(t, uint64(3), nestedFunction.ComputationUsed)
+	require.Equal(t, uint64(4), nestedFunction.MemoryEstimate)
+	require.Equal(t, 1, nestedFunction.Parent)
+
+	require.Equal(t, []environment.ExecutionTraceStatement{
+		{Location: string(location.ID()), Line: 1, Hits: 1},
+		{Location: string(location.ID()), Line: 3, Hits: 2},
+	}, executionTrace.Statements)
+}
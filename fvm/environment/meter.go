@@ -15,6 +15,10 @@ type Meter interface {
 
 	MeterMemory(usage common.MemoryUsage) error
 	MemoryEstimate() uint64
+
+	EnterContract(location common.AddressLocation)
+	ExitContract()
+	SetExecutingLocation(location common.Location)
 }
 
 type meterImpl struct {
@@ -52,6 +56,18 @@ func (meter *meterImpl) MemoryEstimate() uint64 {
 	return meter.stTxn.TotalMemoryEstimate()
 }
 
+func (meter *meterImpl) EnterContract(location common.AddressLocation) {
+	meter.stTxn.EnterContract(location)
+}
+
+func (meter *meterImpl) ExitContract() {
+	meter.stTxn.ExitContract()
+}
+
+func (meter *meterImpl) SetExecutingLocation(location common.Location) {
+	meter.stTxn.SetExecutingLocation(location)
+}
+
 type cancellableMeter struct {
 	meterImpl
 
@@ -55,6 +55,8 @@ func (logger *ProgramLogger) Logs() []string {
 }
 
 func (logger *ProgramLogger) RecordTrace(operation string, location common.Location, duration time.Duration, attrs []attribute.KeyValue) {
+	logger.tracer.executionTracer.recordCadenceTrace(operation, location, duration, attrs)
+
 	if location != nil {
 		attrs = append(attrs, attribute.String("location", location.String()))
 	}
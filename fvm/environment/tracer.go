@@ -15,13 +15,15 @@ type Tracer struct {
 
 	rootSpan         otelTrace.Span
 	extensiveTracing bool
+	executionTracer  *ExecutionTracer
 }
 
 func NewTracer(
 	tracer module.Tracer,
 	root otelTrace.Span,
-	extensiveTracing bool) *Tracer {
-	return &Tracer{tracer, root, extensiveTracing}
+	extensiveTracing bool,
+	executionTracer *ExecutionTracer) *Tracer {
+	return &Tracer{tracer, root, extensiveTracing, executionTracer}
 }
 
 func (tracer *Tracer) isTraceable() bool {
@@ -29,19 +31,21 @@ func (tracer *Tracer) isTraceable() bool {
 }
 
 func (tracer *Tracer) StartSpanFromRoot(name trace.SpanName) otelTrace.Span {
+	span := trace.NoopSpan
 	if tracer.isTraceable() {
-		return tracer.Tracer.StartSpanFromParent(tracer.rootSpan, name)
+		span = tracer.Tracer.StartSpanFromParent(tracer.rootSpan, name)
 	}
 
-	return trace.NoopSpan
+	return tracer.executionTracer.startHostFunction(name, span)
 }
 
 func (tracer *Tracer) StartExtensiveTracingSpanFromRoot(name trace.SpanName) otelTrace.Span {
+	span := trace.NoopSpan
 	if tracer.isTraceable() && tracer.extensiveTracing {
-		return tracer.Tracer.StartSpanFromParent(tracer.rootSpan, name)
+		span = tracer.Tracer.StartSpanFromParent(tracer.rootSpan, name)
 	}
 
-	return trace.NoopSpan
+	return tracer.executionTracer.startHostFunction(name, span)
 }
 
 func (tracer *Tracer) RecordSpanFromRoot(
@@ -2,6 +2,8 @@ package fvm
 
 import (
 	"context"
+	"fmt"
+	"math"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/runtime"
@@ -13,6 +15,7 @@ import (
 	"github.com/onflow/flow-go/fvm/programs"
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/fvm/utils"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 func getEnvironmentMeterParameters(
@@ -111,6 +114,11 @@ func fillEnvironmentMeterParameters(
 		return params, err
 	}
 
+	// the memory limit of the execution parameters record takes precedence
+	if ctx.ExecutionParametersApplied {
+		return params, nil
+	}
+
 	memoryLimit, err := GetExecutionMemoryLimit(env, service)
 	err = setIfOk(
 		"execution memory limit",
@@ -236,3 +244,171 @@ func GetExecutionMemoryLimit(
 
 	return memoryLimitRaw.ToGoValue().(uint64), nil
 }
+
+// ExecutionParametersActivationHeight returns the height of the first block of the given chain executed
+// with the execution parameters record stored in the service account, see ApplyExecutionParameters.
+// Reading the record changes the registers read by every chunk, so execution and verification nodes
+// must start reading it at the same height. Chains where reading the record isn't activated yet
+// return math.MaxUint64.
+func ExecutionParametersActivationHeight(chainID flow.ChainID) uint64 {
+	switch chainID {
+	case flow.Emulator, flow.MonotonicEmulator, flow.Localnet, flow.Benchnet, flow.BftTestnet:
+		return 0
+	default:
+		// activated on live networks with a height coordinated upgrade
+		return math.MaxUint64
+	}
+}
+
+// ApplyExecutionParameters returns the given context with the limits set by the execution parameters
+// record stored in the service account, see blueprints.SetExecutionParametersTransaction.
+//
+// The parameters active at the height of the context's block header are applied. The context is
+// returned unchanged if it doesn't allow overrides by the execution state, if it has no block header,
+// or if no valid parameters are active at that height. The record is read from the given view,
+// so the registers of the record are part of the view's reads, and of the SPoCK of the chunk.
+// Below the activation height of the context, the record isn't read, so the view isn't changed.
+//
+// An error is returned if the active parameters have a version not supported by this node,
+// as executing with the node's own limits could diverge from the other nodes.
+func (vm *VirtualMachine) ApplyExecutionParameters(
+	ctx Context,
+	v state.View,
+	programs *programs.Programs,
+) (
+	Context,
+	error,
+) {
+	if !ctx.AllowContextOverrideByExecutionState ||
+		ctx.BlockHeader == nil ||
+		ctx.BlockHeader.Height < ctx.ExecutionParametersActivationHeight {
+		return ctx, nil
+	}
+
+	stTxn := state.NewStateTransaction(
+		v,
+		state.DefaultParameters().
+			WithMaxKeySizeAllowed(ctx.MaxStateKeySize).
+			WithMaxValueSizeAllowed(ctx.MaxStateValueSize).
+			WithMaxInteractionSizeAllowed(ctx.MaxStateInteractionSize))
+
+	stTxn.DisableAllLimitEnforcements()
+
+	env := NewScriptEnvironment(context.Background(), ctx, vm, stTxn, programs)
+
+	params, err := GetExecutionParameters(
+		env,
+		runtime.Address(ctx.Chain.ServiceAddress()),
+		ctx.BlockHeader.Height)
+	txErr, fatal := errors.SplitErrorTypes(err)
+	if fatal != nil {
+		return ctx, fatal
+	}
+	if txErr != nil {
+		// no parameters are active at this height, or they are not valid,
+		// which is deterministic, so the limits of the context are used.
+		ctx.Logger.
+			Debug().
+			Err(txErr).
+			Msg("could not get execution parameters. Using defaults")
+		return ctx, nil
+	}
+
+	ctx = NewContextFromParent(ctx,
+		WithComputationLimit(params.ComputationLimit),
+		WithMemoryLimit(params.MemoryLimit),
+		WithMaxStateKeySize(params.MaxStateKeySize),
+		WithMaxStateValueSize(params.MaxStateValueSize),
+		WithMaxStateInteractionSize(params.MaxStateInteractionSize),
+		WithEventCollectionSizeLimit(params.EventCollectionByteSizeLimit),
+		WithContractDeploymentRestricted(params.RestrictContractDeployment),
+		WithContractRemovalRestricted(params.RestrictContractRemoval),
+		WithAccountStorageLimit(params.LimitAccountStorage),
+	)
+	ctx.ExecutionParametersApplied = true
+
+	return ctx, nil
+}
+
+// GetExecutionParameters reads the execution parameters record from the service account,
+// and returns the parameters active at the given block height.
+func GetExecutionParameters(
+	env Environment,
+	service runtime.Address,
+	height uint64,
+) (
+	params blueprints.ExecutionParameters,
+	err error,
+) {
+	value, err := env.VM().Runtime.ReadStored(
+		service,
+		cadence.Path{
+			Domain:     blueprints.TransactionExecutionParametersPathDomain,
+			Identifier: blueprints.ExecutionParametersPathIdentifier,
+		},
+		runtime.Context{Interface: env},
+	)
+	if err != nil {
+		// this might be fatal, return as is
+		return params, err
+	}
+
+	notFound := errors.NewCouldNotGetExecutionParameterFromStateError(
+		service.Hex(),
+		blueprints.TransactionExecutionParametersPathDomain,
+		blueprints.ExecutionParametersPathIdentifier)
+
+	records, ok := utils.CadenceValueToExecutionParametersRecords(value)
+	if !ok {
+		// this is a non-fatal error. It is expected if the record is not set up on the network yet.
+		return params, notFound
+	}
+
+	// the active parameters are the ones with the highest activation height not above the height
+	var encoded map[string]uint64
+	var activationHeight uint64
+	for h, record := range records {
+		if h <= height && (encoded == nil || h > activationHeight) {
+			activationHeight = h
+			encoded = record
+		}
+	}
+	if encoded == nil {
+		return params, notFound
+	}
+
+	version := encoded[blueprints.ExecutionParametersVersionKey]
+	if version > blueprints.ExecutionParametersVersion {
+		// this is a fatal error, the node needs to be upgraded to interpret the parameters.
+		return params, fmt.Errorf(
+			"unsupported execution parameters version %d activated at height %d, latest supported version is %d",
+			version,
+			activationHeight,
+			blueprints.ExecutionParametersVersion)
+	}
+
+	params, err = blueprints.DecodeExecutionParameters(encoded)
+	if err != nil {
+		// this is a non-fatal error, invalid parameters are ignored the same way by all nodes.
+		return params, notFound
+	}
+
+	return params, nil
+}
+
+// ExecutionParametersFromContext returns the execution parameters with the limits of the given context,
+// for instance to schedule the limits a node is configured with, see blueprints.SetExecutionParametersTransaction.
+func ExecutionParametersFromContext(ctx Context) blueprints.ExecutionParameters {
+	return blueprints.ExecutionParameters{
+		Version:                      blueprints.ExecutionParametersVersion,
+		ComputationLimit:             ctx.ComputationLimit,
+		MemoryLimit:                  ctx.MemoryLimit,
+		MaxStateKeySize:              ctx.MaxStateKeySize,
+		MaxStateValueSize:            ctx.MaxStateValueSize,
+		MaxStateInteractionSize:      ctx.MaxStateInteractionSize,
+		EventCollectionByteSizeLimit: ctx.EventCollectionByteSizeLimit,
+		RestrictContractDeployment:   ctx.RestrictContractDeployment,
+		RestrictContractRemoval:      ctx.RestrictContractRemoval,
+		LimitAccountStorage:          ctx.LimitAccountStorage,
+	}
+}
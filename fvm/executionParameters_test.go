@@ -285,6 +285,126 @@ func TestGetExecutionEffortWeights(t *testing.T) {
 
 var _ runtime.Runtime = &TestInterpreterRuntime{}
 
+func TestGetExecutionParameters(t *testing.T) {
+	address := common.Address{}
+
+	setupEnvMock := func(value cadence.Value) fvm.Environment {
+		r := &TestInterpreterRuntime{
+			readStored: func(address common.Address, path cadence.Path, context runtime.Context) (cadence.Value, error) {
+				return value, nil
+			},
+		}
+		vm := fvm.VirtualMachine{
+			Runtime: r,
+		}
+		envMock := &fvmmock.Environment{}
+		envMock.On("VM").
+			Return(&vm).
+			Once()
+		return envMock
+	}
+
+	record := func(encoded map[string]uint64) cadence.Dictionary {
+		pairs := make([]cadence.KeyValuePair, 0, len(encoded))
+		for k, v := range encoded {
+			pairs = append(pairs, cadence.KeyValuePair{
+				Key:   cadence.String(k),
+				Value: cadence.UInt64(v),
+			})
+		}
+		return cadence.NewDictionary(pairs)
+	}
+
+	records := func(records map[uint64]cadence.Dictionary) cadence.Dictionary {
+		pairs := make([]cadence.KeyValuePair, 0, len(records))
+		for h, r := range records {
+			pairs = append(pairs, cadence.KeyValuePair{
+				Key:   cadence.UInt64(h),
+				Value: r,
+			})
+		}
+		return cadence.NewDictionary(pairs)
+	}
+
+	notFound := errors.NewCouldNotGetExecutionParameterFromStateError(
+		address.Hex(),
+		blueprints.TransactionExecutionParametersPathDomain,
+		blueprints.ExecutionParametersPathIdentifier).Error()
+
+	params := blueprints.ExecutionParameters{
+		Version:                      blueprints.ExecutionParametersVersion,
+		ComputationLimit:             1,
+		MemoryLimit:                  2,
+		MaxStateKeySize:              3,
+		MaxStateValueSize:            4,
+		MaxStateInteractionSize:      5,
+		EventCollectionByteSizeLimit: 6,
+		RestrictContractDeployment:   true,
+		LimitAccountStorage:          true,
+	}
+
+	t.Run("return error if nothing is stored",
+		func(t *testing.T) {
+			_, err := fvm.GetExecutionParameters(setupEnvMock(nil), address, 10)
+			require.EqualError(t, err, notFound)
+		},
+	)
+	t.Run("return error if no parameters are active",
+		func(t *testing.T) {
+			envMock := setupEnvMock(records(map[uint64]cadence.Dictionary{
+				11: record(params.Encode()),
+			}))
+			_, err := fvm.GetExecutionParameters(envMock, address, 10)
+			require.EqualError(t, err, notFound)
+		},
+	)
+	t.Run("return error if the active parameters are incomplete",
+		func(t *testing.T) {
+			encoded := params.Encode()
+			delete(encoded, blueprints.ExecutionParametersMaxStateKeySizeKey)
+
+			envMock := setupEnvMock(records(map[uint64]cadence.Dictionary{
+				10: record(encoded),
+			}))
+			_, err := fvm.GetExecutionParameters(envMock, address, 10)
+			require.EqualError(t, err, notFound)
+		},
+	)
+	t.Run("return the parameters with the highest activation height",
+		func(t *testing.T) {
+			previous := params
+			previous.ComputationLimit = 100
+
+			next := params
+			next.ComputationLimit = 200
+
+			envMock := setupEnvMock(records(map[uint64]cadence.Dictionary{
+				5:  record(previous.Encode()),
+				8:  record(params.Encode()),
+				11: record(next.Encode()),
+			}))
+			active, err := fvm.GetExecutionParameters(envMock, address, 10)
+			require.NoError(t, err)
+			require.Equal(t, params, active)
+		},
+	)
+	t.Run("return fatal error if the version is not supported",
+		func(t *testing.T) {
+			unsupported := params
+			unsupported.Version = blueprints.ExecutionParametersVersion + 1
+
+			envMock := setupEnvMock(records(map[uint64]cadence.Dictionary{
+				10: record(unsupported.Encode()),
+			}))
+			_, err := fvm.GetExecutionParameters(envMock, address, 10)
+			require.Error(t, err)
+
+			_, fatal := errors.SplitErrorTypes(err)
+			require.Error(t, fatal)
+		},
+	)
+}
+
 type TestInterpreterRuntime struct {
 	readStored             func(address common.Address, path cadence.Path, context runtime.Context) (cadence.Value, error)
 	invokeContractFunction func(a common.AddressLocation, s string, values []cadence.Value, types []sema.Type, ctx runtime.Context) (cadence.Value, error)
@@ -17,10 +17,13 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
 	"github.com/onflow/flow-go/engine/execution/testutil"
 	exeUtils "github.com/onflow/flow-go/engine/execution/utils"
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/blueprints"
 	fvmCrypto "github.com/onflow/flow-go/fvm/crypto"
+	"github.com/onflow/flow-go/fvm/environment"
 	errors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/fvm/meter"
 	"github.com/onflow/flow-go/fvm/programs"
@@ -1824,3 +1827,263 @@ func TestScriptAccountKeyMutationsFailure(t *testing.T) {
 		),
 	)
 }
+
+func TestExecutionParameters(t *testing.T) {
+
+	t.Run("scheduled parameters apply from the activation height", newVMTest().
+		withContextOptions(
+			fvm.WithBlocks(&environment.NoopBlockFinder{}),
+			fvm.WithExecutionParametersActivationHeight(0),
+		).
+		run(
+			func(t *testing.T, vm *fvm.VirtualMachine, chain flow.Chain, ctx fvm.Context, view state.View, programs *programs.Programs) {
+				blockCtx := func(height uint64) fvm.Context {
+					header := unittest.BlockHeaderFixture(func(header *flow.Header) {
+						header.Height = height
+					})
+					return fvm.NewContextFromParent(ctx, fvm.WithBlockHeader(header))
+				}
+
+				setParameters := func(height uint64, activationHeight uint64, seqNum uint64, params blueprints.ExecutionParameters) *fvm.TransactionProcedure {
+					txBody, err := blueprints.SetExecutionParametersTransaction(chain.ServiceAddress(), activationHeight, params)
+					require.NoError(t, err)
+
+					txBody.SetProposalKey(chain.ServiceAddress(), 0, seqNum).
+						SetPayer(chain.ServiceAddress())
+
+					err = testutil.SignTransactionAsServiceAccount(txBody, seqNum, chain)
+					require.NoError(t, err)
+
+					tx := fvm.Transaction(txBody, 0)
+					err = vm.Run(blockCtx(height), tx, view, programs)
+					require.NoError(t, err)
+					return tx
+				}
+
+				first := fvm.ExecutionParametersFromContext(ctx)
+				first.ComputationLimit = 1234
+				first.MaxStateKeySize = 5678
+				first.RestrictContractDeployment = !ctx.RestrictContractDeployment
+
+				second := first
+				second.EventCollectionByteSizeLimit = 4321
+
+				tx := setParameters(10, 20, 0, first)
+				require.NoError(t, tx.Err)
+
+				tx = setParameters(10, 30, 1, second)
+				require.NoError(t, tx.Err)
+
+				// only future blocks can be scheduled
+				tx = setParameters(10, 10, 2, second)
+				require.Error(t, tx.Err)
+
+				// no parameters are active before the first activation height
+				applied, err := vm.ApplyExecutionParameters(blockCtx(19), view, programs)
+				require.NoError(t, err)
+				require.False(t, applied.ExecutionParametersApplied)
+				require.Equal(t, ctx.ComputationLimit, applied.ComputationLimit)
+
+				applied, err = vm.ApplyExecutionParameters(blockCtx(20), view, programs)
+				require.NoError(t, err)
+				require.True(t, applied.ExecutionParametersApplied)
+				require.Equal(t, first, fvm.ExecutionParametersFromContext(applied))
+
+				applied, err = vm.ApplyExecutionParameters(blockCtx(35), view, programs)
+				require.NoError(t, err)
+				require.Equal(t, second, fvm.ExecutionParametersFromContext(applied))
+
+				// contexts that don't allow overrides by the execution state are not changed
+				applied, err = vm.ApplyExecutionParameters(
+					fvm.NewContextFromParent(blockCtx(35), fvm.WithAllowContextOverrideByExecutionState(false)),
+					view,
+					programs)
+				require.NoError(t, err)
+				require.False(t, applied.ExecutionParametersApplied)
+				require.Equal(t, ctx.EventCollectionByteSizeLimit, applied.EventCollectionByteSizeLimit)
+
+				// the record is ignored below the activation height of the context
+				applied, err = vm.ApplyExecutionParameters(
+					fvm.NewContextFromParent(blockCtx(35), fvm.WithExecutionParametersActivationHeight(36)),
+					view,
+					programs)
+				require.NoError(t, err)
+				require.False(t, applied.ExecutionParametersApplied)
+				require.Equal(t, ctx.EventCollectionByteSizeLimit, applied.EventCollectionByteSizeLimit)
+			},
+		),
+	)
+
+	t.Run("interactions and SPoCK are unchanged below the activation height", newVMTest().
+		withContextOptions(
+			fvm.WithBlocks(&environment.NoopBlockFinder{}),
+			fvm.WithExecutionParametersActivationHeight(20),
+		).
+		run(
+			func(t *testing.T, vm *fvm.VirtualMachine, chain flow.Chain, ctx fvm.Context, view state.View, programs *programs.Programs) {
+				blockCtx := fvm.NewContextFromParent(ctx, fvm.WithBlockHeader(unittest.BlockHeaderFixture(func(header *flow.Header) {
+					header.Height = 19
+				})))
+
+				// no record is stored, chunks only read the registers of their transactions
+				chunkView := func() *delta.View {
+					return delta.NewView(view.Get)
+				}
+
+				expected := chunkView()
+
+				applied := chunkView()
+				appliedCtx, err := vm.ApplyExecutionParameters(blockCtx, applied, programs)
+				require.NoError(t, err)
+				require.False(t, appliedCtx.ExecutionParametersApplied)
+
+				require.Equal(t, expected.Interactions(), applied.Interactions())
+				require.Equal(t, expected.SpockSecret(), applied.SpockSecret())
+
+				// from the activation height, the registers of the missing record are read
+				activated := chunkView()
+				activatedCtx, err := vm.ApplyExecutionParameters(
+					fvm.NewContextFromParent(blockCtx, fvm.WithExecutionParametersActivationHeight(19)),
+					activated,
+					programs)
+				require.NoError(t, err)
+				require.False(t, activatedCtx.ExecutionParametersApplied)
+				require.NotEmpty(t, activated.Interactions().Reads)
+			},
+		),
+	)
+}
+
+func TestContractUsages(t *testing.T) {
+
+	chain := flow.Testnet.Chain()
+	// the Cadence runtime only reports the location of the executing code in its traces
+	vm := fvm.NewVirtualMachine(fvm.NewInterpreterRuntime(runtime.WithTracingEnabled(true)))
+
+	ctx := fvm.NewContext(
+		zerolog.Nop(),
+		fvm.WithChain(chain),
+		fvm.WithTransactionProcessors(
+			fvm.NewTransactionInvoker(zerolog.Nop()),
+		),
+	)
+
+	ledger := testutil.RootBootstrappedLedger(vm, ctx)
+	programs := programs.NewEmptyPrograms()
+
+	counterContract := `
+	access(all) contract Counter {
+		access(all) var count: Int
+
+		access(all) fun increment(times: Int) {
+			var i = 0
+			while i < times {
+				self.count = self.count + 1
+				i = i + 1
+			}
+		}
+
+		init() {
+			self.count = 0
+		}
+	}
+	`
+
+	txBody := flow.NewTransactionBody().
+		SetScript([]byte(fmt.Sprintf(`
+		transaction {
+			prepare(signer: AuthAccount) {
+				signer.contracts.add(name: "Counter", code: "%s".decodeHex())
+			}
+		}`, hex.EncodeToString([]byte(counterContract))))).
+		SetPayer(chain.ServiceAddress()).
+		AddAuthorizer(chain.ServiceAddress())
+
+	tx := fvm.Transaction(txBody, 0)
+	err := vm.Run(ctx, tx, ledger, programs)
+	require.NoError(t, err)
+	require.NoError(t, tx.Err)
+
+	counter := func(times int) flow.ContractUsage {
+		txBody := flow.NewTransactionBody().
+			SetScript([]byte(fmt.Sprintf(`
+			import Counter from 0x%s
+
+			transaction {
+				prepare(signer: AuthAccount) {}
+
+				execute {
+					Counter.increment(times: %d)
+				}
+			}`, chain.ServiceAddress(), times))).
+			SetPayer(chain.ServiceAddress()).
+			AddAuthorizer(chain.ServiceAddress())
+
+		tx := fvm.Transaction(txBody, 0)
+		err := vm.Run(ctx, tx, ledger, programs)
+		require.NoError(t, err)
+		require.NoError(t, tx.Err)
+
+		for _, usage := range tx.ContractUsages {
+			if usage.Address == chain.ServiceAddress() && usage.Name == "Counter" {
+				return usage
+			}
+		}
+		require.Fail(t, "no usage of the called contract", "usages: %v", tx.ContractUsages)
+		return flow.ContractUsage{}
+	}
+
+	once := counter(1)
+	require.NotZero(t, once.ComputationUsed)
+
+	// the loop executes in the contract, so its computation is attributed to the contract
+	many := counter(100)
+	require.Greater(t, many.ComputationUsed, once.ComputationUsed+100)
+}
+
+func TestExecutionTraceStatements(t *testing.T) {
+
+	chain := flow.Testnet.Chain()
+	tracer := environment.NewExecutionTracer()
+
+	rt := fvm.NewInterpreterRuntime(runtime.WithTracingEnabled(true))
+	rt.SetCoverageReport(tracer.CoverageReport())
+	vm := fvm.NewVirtualMachine(rt)
+
+	ctx := fvm.NewContext(
+		zerolog.Nop(),
+		fvm.WithChain(chain),
+		fvm.WithTransactionProcessors(
+			fvm.NewTransactionInvoker(zerolog.Nop()),
+		),
+	)
+
+	ledger := testutil.RootBootstrappedLedger(vm, ctx)
+
+	txBody := flow.NewTransactionBody().
+		SetScript([]byte(`
+		transaction {
+			execute {
+				var i = 0
+				while i < 3 {
+					i = i + 1
+				}
+			}
+		}`))
+
+	tx := fvm.Transaction(txBody, 0)
+	err := vm.Run(fvm.NewContextFromParent(ctx, fvm.WithExecutionTracer(tracer)), tx, ledger, programs.NewEmptyPrograms())
+	require.NoError(t, err)
+	require.NoError(t, tx.Err)
+
+	location := string(common.TransactionLocation(tx.ID).ID())
+	hits := make(map[int]int)
+	for _, statement := range tracer.Trace().Statements {
+		if statement.Location == location {
+			hits[statement.Line] = statement.Hits
+		}
+	}
+
+	// the declaration and the loop are executed once, and the loop body once per iteration
+	require.Equal(t, map[int]int{4: 1, 5: 1, 6: 3}, hits)
+}
@@ -120,7 +120,10 @@ func (h *ProgramsHandler) Get(location common.Location) (*interpreter.Program, b
 		parentState = h.viewsStack[len(h.viewsStack)-1].state
 	}
 
+	// the program is loaded in a child state, which is discarded once merged,
+	// so the loading is attributed to the contract until then
 	childState := parentState.NewChild()
+	childState.EnterContract(address)
 
 	h.viewsStack = append(h.viewsStack, stackEntry{
 		state:    childState,
@@ -53,6 +53,14 @@ func Test_Programs(t *testing.T) {
 		}
 	`
 
+	contractA2Code := `
+		pub contract A {
+			pub fun hello(): String {
+        		return "hello from updated A"
+    		}
+		}
+	`
+
 	contractBCode := `
 		import A from 0xa
 	
@@ -242,7 +250,7 @@ func Test_Programs(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("deploying another contract cleans programs storage", func(t *testing.T) {
+	t.Run("deploying another contract keeps programs not depending on it", func(t *testing.T) {
 
 		// deploy contract B
 		procContractB := fvm.Transaction(contractDeployTx("B", contractBCode, addressB), 3)
@@ -252,7 +260,8 @@ func Test_Programs(t *testing.T) {
 		_, _, hasA := programs.Get(contractALocation)
 		_, _, hasB := programs.Get(contractBLocation)
 
-		require.False(t, hasA)
+		// A doesn't depend on B, and the deployed contract isn't cached
+		require.True(t, hasA)
 		require.False(t, hasB)
 	})
 
@@ -260,7 +269,7 @@ func Test_Programs(t *testing.T) {
 
 	t.Run("contract B imports contract A", func(t *testing.T) {
 
-		// programs should have no entries for A and B, as per previous test
+		// programs should have an entry for A only, as per previous test
 
 		// run a TX using contract B
 		procCallB := fvm.Transaction(callTx("B", addressB), 4)
@@ -360,7 +369,7 @@ func Test_Programs(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("deploying contract C cleans programs", func(t *testing.T) {
+	t.Run("deploying contract C keeps programs not depending on it", func(t *testing.T) {
 		require.NotNil(t, contractBView)
 
 		// deploy contract C
@@ -372,10 +381,10 @@ func Test_Programs(t *testing.T) {
 		_, _, hasB := programs.Get(contractBLocation)
 		_, _, hasC := programs.Get(contractCLocation)
 
-		require.False(t, hasA)
-		require.False(t, hasB)
+		// neither A nor B depend on C, and the deployed contract isn't cached
+		require.True(t, hasA)
+		require.True(t, hasB)
 		require.False(t, hasC)
-
 	})
 
 	t.Run("importing C should chain-import B and A", func(t *testing.T) {
@@ -404,6 +413,33 @@ func Test_Programs(t *testing.T) {
 		deltaB := programBState.View().(*delta.View)
 		compareViews(t, contractBView, deltaB)
 	})
+
+	t.Run("updating contract A cleans programs depending on it", func(t *testing.T) {
+		// update contract A
+		procContractA := fvm.Transaction(updateContractTx("A", contractA2Code, addressA), 9)
+		err := vm.Run(context, procContractA, mainView, programs)
+		require.NoError(t, err)
+		require.NoError(t, procContractA.Err)
+
+		_, _, hasA := programs.Get(contractALocation)
+		_, _, hasB := programs.Get(contractBLocation)
+		_, _, hasC := programs.Get(contractCLocation)
+
+		// B imports A, and C imports B
+		require.False(t, hasA)
+		require.False(t, hasB)
+		require.False(t, hasC)
+
+		// importing C uses the updated contract A
+		procCallC := fvm.Transaction(callTx("C", addressC), 10)
+
+		viewExecC := delta.NewView(mainView.Peek)
+
+		err = vm.Run(context, procCallC, viewExecC, programs)
+		require.NoError(t, err)
+
+		require.Contains(t, procCallC.Logs, "\"hello from C, hello from B but also hello from updated A\"")
+	})
 }
 
 // compareViews compares views using only data that matters (ie. two different hasher instances
@@ -41,6 +41,15 @@ const (
 type MeteredComputationIntensities map[common.ComputationKind]uint
 type MeteredMemoryIntensities map[common.MemoryKind]uint
 
+// ContractUsage is the computation and memory used while the code of a contract was executing
+type ContractUsage struct {
+	ComputationUsed uint64
+	MemoryEstimate  uint64
+}
+
+// ContractUsages are the usages of each contract, identified by its address and name
+type ContractUsages map[common.AddressLocation]ContractUsage
+
 type Meter interface {
 	// merge child funcionality
 	NewChild() Meter
@@ -49,15 +58,27 @@ type Meter interface {
 	// computation metering
 	MeterComputation(kind common.ComputationKind, intensity uint) error
 	ComputationIntensities() MeteredComputationIntensities
+	ComputationWeights() ExecutionEffortWeights
 	TotalComputationUsed() uint
 	TotalComputationLimit() uint
 
 	// memory metering
 	MeterMemory(kind common.MemoryKind, intensity uint) error
 	MemoryIntensities() MeteredMemoryIntensities
+	MemoryWeights() ExecutionMemoryWeights
 	TotalMemoryEstimate() uint64
 	TotalMemoryLimit() uint64
 
+	// contract attribution, the computation and memory metered between EnterContract
+	// and the matching ExitContract are attributed to the entered contract.
+	// Children inherit the contract the parent is in.
+	EnterContract(location common.AddressLocation)
+	ExitContract()
+	// SetExecutingLocation changes the location of the code executing in the current contract
+	// (or top level) frame, the metering is attributed to the location if it is a contract.
+	SetExecutingLocation(location common.Location)
+	ContractUsages() ContractUsages
+
 	// TODO move storage metering to here
 	// MeterStorageRead(byteSize uint) error
 	// MeterStorageWrite(byteSize uint) error
@@ -324,6 +324,12 @@ type WeightedMeter struct {
 
 	computationIntensities MeteredComputationIntensities
 	memoryIntensities      MeteredMemoryIntensities
+
+	// contracts is the stack of the locations of the entered frames, the last one is executing.
+	// Only the computation and memory metered in contract locations are attributed.
+	contracts               []common.Location
+	contractComputationUsed map[common.AddressLocation]uint64
+	contractMemoryEstimate  map[common.AddressLocation]uint64
 }
 
 type WeightedMeterOptions func(*WeightedMeter)
@@ -331,9 +337,11 @@ type WeightedMeterOptions func(*WeightedMeter)
 // NewMeter constructs a new Meter
 func NewMeter(params MeterParameters) Meter {
 	m := &WeightedMeter{
-		MeterParameters:        params,
-		computationIntensities: make(MeteredComputationIntensities),
-		memoryIntensities:      make(MeteredMemoryIntensities),
+		MeterParameters:         params,
+		computationIntensities:  make(MeteredComputationIntensities),
+		memoryIntensities:       make(MeteredMemoryIntensities),
+		contractComputationUsed: make(map[common.AddressLocation]uint64),
+		contractMemoryEstimate:  make(map[common.AddressLocation]uint64),
 	}
 
 	return m
@@ -342,9 +350,12 @@ func NewMeter(params MeterParameters) Meter {
 // NewChild construct a new Meter instance with the same limits as parent
 func (m *WeightedMeter) NewChild() Meter {
 	return &WeightedMeter{
-		MeterParameters:        m.MeterParameters,
-		computationIntensities: make(MeteredComputationIntensities),
-		memoryIntensities:      make(MeteredMemoryIntensities),
+		MeterParameters:         m.MeterParameters,
+		computationIntensities:  make(MeteredComputationIntensities),
+		memoryIntensities:       make(MeteredMemoryIntensities),
+		contracts:               append([]common.Location(nil), m.contracts...),
+		contractComputationUsed: make(map[common.AddressLocation]uint64),
+		contractMemoryEstimate:  make(map[common.AddressLocation]uint64),
 	}
 }
 
@@ -380,6 +391,20 @@ func (m *WeightedMeter) MergeMeter(child Meter, enforceLimits bool) error {
 	for key, intensity := range child.MemoryIntensities() {
 		m.memoryIntensities[key] += intensity
 	}
+
+	if basic, ok := child.(*WeightedMeter); ok {
+		for location, used := range basic.contractComputationUsed {
+			m.contractComputationUsed[location] += used
+		}
+		for location, estimate := range basic.contractMemoryEstimate {
+			m.contractMemoryEstimate[location] += estimate
+		}
+	} else {
+		for location, usage := range child.ContractUsages() {
+			m.contractComputationUsed[location] += usage.ComputationUsed << MeterExecutionInternalPrecisionBytes
+			m.contractMemoryEstimate[location] += usage.MemoryEstimate
+		}
+	}
 	return nil
 }
 
@@ -391,6 +416,9 @@ func (m *WeightedMeter) MeterComputation(kind common.ComputationKind, intensity
 		return nil
 	}
 	m.computationUsed += w * uint64(intensity)
+	if contract, ok := m.currentContract(); ok {
+		m.contractComputationUsed[contract] += w * uint64(intensity)
+	}
 	if m.computationUsed > m.computationLimit {
 		return errors.NewComputationLimitExceededError(uint64(m.TotalComputationLimit()))
 	}
@@ -415,6 +443,9 @@ func (m *WeightedMeter) MeterMemory(kind common.MemoryKind, intensity uint) erro
 		return nil
 	}
 	m.memoryEstimate += w * uint64(intensity)
+	if contract, ok := m.currentContract(); ok {
+		m.contractMemoryEstimate[contract] += w * uint64(intensity)
+	}
 	if m.memoryEstimate > m.memoryLimit {
 		return errors.NewMemoryLimitExceededError(uint64(m.TotalMemoryLimit()))
 	}
@@ -430,3 +461,93 @@ func (m *WeightedMeter) MemoryIntensities() MeteredMemoryIntensities {
 func (m *WeightedMeter) TotalMemoryEstimate() uint64 {
 	return m.memoryEstimate
 }
+
+// EnterContract attributes the computation and memory metered from now on to the given contract,
+// until the matching ExitContract.
+func (m *WeightedMeter) EnterContract(location common.AddressLocation) {
+	m.contracts = append(m.contracts, location)
+}
+
+// SetExecutingLocation attributes the computation and memory metered from now on to the contract
+// at the given location, instead of the location executing in the current frame.
+// Nothing is attributed if the location is not a contract, e.g. a transaction.
+func (m *WeightedMeter) SetExecutingLocation(location common.Location) {
+	if len(m.contracts) == 0 {
+		m.contracts = append(m.contracts, location)
+		return
+	}
+	m.contracts[len(m.contracts)-1] = location
+}
+
+// ExitContract attributes the computation and memory metered from now on to the contract
+// entered before the last entered contract, if any.
+func (m *WeightedMeter) ExitContract() {
+	if len(m.contracts) > 0 {
+		m.contracts = m.contracts[:len(m.contracts)-1]
+	}
+}
+
+func (m *WeightedMeter) currentContract() (common.AddressLocation, bool) {
+	if len(m.contracts) == 0 {
+		return common.AddressLocation{}, false
+	}
+	contract, ok := m.contracts[len(m.contracts)-1].(common.AddressLocation)
+	return contract, ok
+}
+
+// ContractUsages returns the computation, in computation units, and memory attributed to each contract
+func (m *WeightedMeter) ContractUsages() ContractUsages {
+	usages := make(ContractUsages, len(m.contractComputationUsed)+len(m.contractMemoryEstimate))
+	for location, used := range m.contractComputationUsed {
+		usage := usages[location]
+		usage.ComputationUsed = used >> MeterExecutionInternalPrecisionBytes
+		usages[location] = usage
+	}
+	for location, estimate := range m.contractMemoryEstimate {
+		usage := usages[location]
+		usage.MemoryEstimate = estimate
+		usages[location] = usage
+	}
+	return usages
+}
+
+// ComputationBreakdown is the computation used by each computation kind, in computation units
+type ComputationBreakdown map[common.ComputationKind]uint64
+
+// MemoryBreakdown is the memory estimate of each memory kind
+type MemoryBreakdown map[common.MemoryKind]uint64
+
+// NewComputationBreakdown returns the computation used by each of the metered computation kinds,
+// which is the intensity of the kind multiplied by its weight. Kinds without a weight do not use
+// any computation and are omitted. Values are rounded down to whole computation units.
+func NewComputationBreakdown(
+	intensities MeteredComputationIntensities,
+	weights ExecutionEffortWeights,
+) ComputationBreakdown {
+	breakdown := make(ComputationBreakdown, len(intensities))
+	for kind, intensity := range intensities {
+		w, ok := weights[kind]
+		if !ok {
+			continue
+		}
+		breakdown[kind] = (w * uint64(intensity)) >> MeterExecutionInternalPrecisionBytes
+	}
+	return breakdown
+}
+
+// NewMemoryBreakdown returns the memory estimate of each of the metered memory kinds, which is the
+// intensity of the kind multiplied by its weight. Kinds without a weight are omitted.
+func NewMemoryBreakdown(
+	intensities MeteredMemoryIntensities,
+	weights ExecutionMemoryWeights,
+) MemoryBreakdown {
+	breakdown := make(MemoryBreakdown, len(intensities))
+	for kind, intensity := range intensities {
+		w, ok := weights[kind]
+		if !ok {
+			continue
+		}
+		breakdown[kind] = w * uint64(intensity)
+	}
+	return breakdown
+}
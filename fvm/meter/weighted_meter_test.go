@@ -371,3 +371,66 @@ func TestMemoryWeights(t *testing.T) {
 		)
 	}
 }
+
+func TestMeteringBreakdown(t *testing.T) {
+	m := meter.NewMeter(
+		meter.DefaultParameters().
+			WithComputationWeights(map[common.ComputationKind]uint64{
+				0: 1 << meter.MeterExecutionInternalPrecisionBytes,
+				1: 1 << (meter.MeterExecutionInternalPrecisionBytes + 1),
+			}).
+			WithMemoryWeights(map[common.MemoryKind]uint64{0: 3}),
+	)
+
+	require.NoError(t, m.MeterComputation(0, 5))
+	require.NoError(t, m.MeterComputation(1, 5))
+	require.NoError(t, m.MeterComputation(2, 5))
+	require.NoError(t, m.MeterMemory(0, 2))
+	require.NoError(t, m.MeterMemory(1, 2))
+
+	computation := meter.NewComputationBreakdown(m.ComputationIntensities(), m.ComputationWeights())
+	require.Equal(t, meter.ComputationBreakdown{0: 5, 1: 10}, computation)
+
+	memory := meter.NewMemoryBreakdown(m.MemoryIntensities(), m.MemoryWeights())
+	require.Equal(t, meter.MemoryBreakdown{0: 6}, memory)
+}
+
+func TestContractUsages(t *testing.T) {
+	m := meter.NewMeter(
+		meter.DefaultParameters().
+			WithComputationWeights(map[common.ComputationKind]uint64{
+				0: 1 << meter.MeterExecutionInternalPrecisionBytes,
+			}).
+			WithMemoryWeights(map[common.MemoryKind]uint64{0: 3}),
+	)
+
+	contractA := common.AddressLocation{Address: common.Address{1}, Name: "A"}
+	contractB := common.AddressLocation{Address: common.Address{2}, Name: "B"}
+
+	// not attributed
+	require.NoError(t, m.MeterComputation(0, 1))
+
+	m.EnterContract(contractA)
+	require.NoError(t, m.MeterComputation(0, 2))
+	require.NoError(t, m.MeterMemory(0, 1))
+
+	// children are in the contract of their parent
+	child := m.NewChild()
+	require.NoError(t, child.MeterComputation(0, 3))
+	child.EnterContract(contractB)
+	require.NoError(t, child.MeterComputation(0, 4))
+	require.NoError(t, child.MeterMemory(0, 2))
+	require.NoError(t, m.MergeMeter(child, true))
+
+	m.ExitContract()
+	require.NoError(t, m.MeterComputation(0, 5))
+
+	// exiting without entered contracts is a no-op
+	m.ExitContract()
+
+	require.Equal(t, uint(15), m.TotalComputationUsed())
+	require.Equal(t, meter.ContractUsages{
+		contractA: {ComputationUsed: 5, MemoryEstimate: 3},
+		contractB: {ComputationUsed: 4, MemoryEstimate: 6},
+	}, m.ContractUsages())
+}
@@ -237,6 +237,16 @@ func (_m *Environment) EmitEvent(_a0 cadence.Event) error {
 	return r0
 }
 
+// EnterContract provides a mock function with given fields: location
+func (_m *Environment) EnterContract(location common.AddressLocation) {
+	_m.Called(location)
+}
+
+// ExitContract provides a mock function with given fields:
+func (_m *Environment) ExitContract() {
+	_m.Called()
+}
+
 // GenerateUUID provides a mock function with given fields:
 func (_m *Environment) GenerateUUID() (uint64, error) {
 	ret := _m.Called()
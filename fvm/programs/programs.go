@@ -32,15 +32,27 @@ type ProgramEntry struct {
 	State    *state.State
 }
 
+// Observer is notified of the lookups of programs, for example to report cache metrics
+// or to track how recently cached programs were used.
+type Observer interface {
+	// ProgramHit is called when the program of a location is found.
+	ProgramHit(entry *ProgramEntry)
+
+	// ProgramMiss is called when the program of a location isn't found, and has to be loaded.
+	ProgramMiss(location common.AddressLocation)
+}
+
 // Programs is a cumulative cache-like storage for Programs helping speed up execution of Cadence
 // Programs don't evict elements at will, like a typical cache would, but it does it only
-// during a cleanup method, which must be called only when the Cadence execution has finished.
+// during a cleanup method, which must be called only when the Cadence execution has finished,
+// or when explicitly asked to.
 // It it also fork-aware, support cheap creation of children capturing local changes.
 type Programs struct {
 	lock     sync.RWMutex
 	programs map[common.LocationID]*ProgramEntry
 	parent   *Programs
 	cleaned  bool
+	observer Observer
 }
 
 func NewEmptyPrograms() *Programs {
@@ -49,26 +61,53 @@ func NewEmptyPrograms() *Programs {
 	}
 }
 
+// NewEmptyProgramsWithObserver returns empty programs notifying the given observer of the lookups
+// of programs. The children of the programs notify the same observer.
+func NewEmptyProgramsWithObserver(observer Observer) *Programs {
+	return &Programs{
+		programs: map[common.LocationID]*ProgramEntry{},
+		observer: observer,
+	}
+}
+
 func (p *Programs) ChildPrograms() *Programs {
 	return &Programs{
 		programs: map[common.LocationID]*ProgramEntry{},
 		parent:   p,
+		observer: p.observer,
 	}
 }
 
 // Get returns stored program, state which contains changes which correspond to loading this program,
 // and boolean indicating if the value was found
 func (p *Programs) Get(location common.AddressLocation) (*interpreter.Program, *state.State, bool) {
+	// the observer is notified without holding any lock,
+	// so that it can evict programs
+	entry := p.getEntry(location)
+	if entry == nil {
+		if p.observer != nil {
+			p.observer.ProgramMiss(location)
+		}
+		return nil, nil, false
+	}
+
+	if p.observer != nil {
+		p.observer.ProgramHit(entry)
+	}
+	return entry.Program, entry.State, true
+}
+
+func (p *Programs) getEntry(location common.AddressLocation) *ProgramEntry {
 	entry, parent := p.get(location)
 	if entry != nil {
-		return entry.Program, entry.State, true
+		return entry
 	}
 
 	if parent != nil {
-		return parent.Get(location)
+		return parent.getEntry(location)
 	}
 
-	return nil, nil, false
+	return nil
 }
 
 func (p *Programs) get(location common.AddressLocation) (*ProgramEntry, *Programs) {
@@ -98,25 +137,126 @@ func (p *Programs) HasChanges() bool {
 	return len(p.programs) > 0 || p.cleaned
 }
 
+// Cleanup invalidates the programs depending on the contracts updated or on the accounts frozen,
+// that is the programs which read the code or the contract names of an updated contract's account,
+// or any register of a frozen account, while being loaded.
+// The other programs, including the ones of the parents, are kept in these programs,
+// which stop using the parents to prevent infinite chaining of objects.
 func (p *Programs) Cleanup(modifiedSets ModifiedSets) {
 	if len(modifiedSets.ContractUpdateKeys) == 0 &&
 		len(modifiedSets.FrozenAccounts) == 0 {
 		return
 	}
 
+	updatedRegisters := make(map[flow.RegisterID]struct{}, 2*len(modifiedSets.ContractUpdateKeys))
+	for _, key := range modifiedSets.ContractUpdateKeys {
+		owner := string(key.Address.Bytes())
+		updatedRegisters[flow.NewRegisterID(owner, state.ContractKey(key.Name))] = struct{}{}
+		updatedRegisters[flow.NewRegisterID(owner, state.KeyContractNames)] = struct{}{}
+	}
+
+	frozenOwners := make(map[string]struct{}, len(modifiedSets.FrozenAccounts))
+	for _, address := range modifiedSets.FrozenAccounts {
+		frozenOwners[string(address[:])] = struct{}{}
+	}
+
+	dependsOnModifiedSets := func(entry *ProgramEntry) bool {
+		// the dependencies of programs loaded without state are unknown
+		if entry.State == nil {
+			return true
+		}
+
+		for _, read := range entry.State.ReadSet() {
+			if _, ok := updatedRegisters[read.Register]; ok {
+				return true
+			}
+			if _, ok := frozenOwners[read.Register.Owner]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	// In mature system, we would track dependencies between contracts
-	// and invalidate only affected ones, possibly setting them to
-	// nil so they will override parent's data, but for now
-	// just throw everything away and use a special flag for this
-	p.cleaned = true
+	programs := make(map[common.LocationID]*ProgramEntry)
+	for id, entry := range p.entriesWithParents() {
+		if !dependsOnModifiedSets(entry) {
+			programs[id] = entry
+		}
+	}
 
-	// Stop using parent's data to prevent
-	// infinite chaining of objects
+	p.cleaned = true
 	p.parent = nil
+	p.programs = programs
+}
 
-	// start with empty storage
-	p.programs = make(map[common.LocationID]*ProgramEntry)
+// entriesWithParents returns the entries of these programs and of their parents,
+// the entries of the children overriding the ones of the parents.
+// The caller must hold the lock of these programs.
+func (p *Programs) entriesWithParents() map[common.LocationID]*ProgramEntry {
+	var entries map[common.LocationID]*ProgramEntry
+	if p.parent != nil {
+		p.parent.lock.RLock()
+		entries = p.parent.entriesWithParents()
+		p.parent.lock.RUnlock()
+	} else {
+		entries = make(map[common.LocationID]*ProgramEntry, len(p.programs))
+	}
+
+	for id, entry := range p.programs {
+		entries[id] = entry
+	}
+	return entries
+}
+
+// Entries returns the entries of these programs, not including the ones of the parents.
+func (p *Programs) Entries() []*ProgramEntry {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	entries := make([]*ProgramEntry, 0, len(p.programs))
+	for _, entry := range p.programs {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Evict removes the given entry from these programs, if it is stored in these programs and not only
+// in the parents, and returns whether it has been removed.
+// The program of an evicted entry is loaded again when needed.
+func (p *Programs) Evict(entry *ProgramEntry) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	id := entry.Location.ID()
+	if p.programs[id] != entry {
+		return false
+	}
+
+	delete(p.programs, id)
+	return true
+}
+
+// MergeChild applies the changes of a child created by ChildPrograms to these programs,
+// as if they had been made on these programs directly: the programs are cleaned up
+// if the child has been cleaned up, keeping the programs the child kept, and the programs set
+// in the child are set.
+func (p *Programs) MergeChild(child *Programs) {
+	child.lock.RLock()
+	defer child.lock.RUnlock()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if child.cleaned {
+		p.cleaned = true
+		p.parent = nil
+		p.programs = make(map[common.LocationID]*ProgramEntry)
+	}
+
+	for id, entry := range child.programs {
+		p.programs[id] = entry
+	}
 }
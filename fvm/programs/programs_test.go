@@ -10,6 +10,7 @@ import (
 
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/fvm/utils"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 func Test_Programs(t *testing.T) {
@@ -33,6 +34,20 @@ func Test_Programs(t *testing.T) {
 		Name:    "address",
 	}
 
+	// loadedState returns a state which read the code of the given contracts,
+	// like the state of a program importing them
+	loadedState := func(locations ...common.AddressLocation) *state.State {
+		loaded := state.NewState(
+			utils.NewSimpleView(),
+			state.DefaultParameters(),
+		)
+		for _, location := range locations {
+			_, err := loaded.Get(string(location.Address[:]), state.ContractKey(location.Name), false)
+			require.NoError(t, err)
+		}
+		return loaded
+	}
+
 	t.Run("cleanup without changed programs", func(t *testing.T) {
 		parentLocation := common.AddressLocation{
 			Address: common.MustBytesToAddress([]byte{3, 4, 5}),
@@ -81,11 +96,11 @@ func Test_Programs(t *testing.T) {
 		}
 
 		parent := NewEmptyPrograms()
-		parent.Set(parentLocation, &interpreter.Program{}, newState)
+		parent.Set(parentLocation, &interpreter.Program{}, loadedState(parentLocation))
 
 		programs := parent.ChildPrograms()
-		programs.Set(someLocation, someProgram, newState)
-		programs.Set(addressLocation, &interpreter.Program{}, newState)
+		programs.Set(someLocation, someProgram, loadedState(someLocation, parentLocation))
+		programs.Set(addressLocation, &interpreter.Program{}, loadedState(addressLocation))
 
 		retrieved, _, has := programs.Get(someLocation)
 		require.NotNil(t, retrieved)
@@ -100,21 +115,72 @@ func Test_Programs(t *testing.T) {
 		require.True(t, has)
 
 		programs.Cleanup(ModifiedSets{
-			// we don't care about the changed program, just their amount (for
-			// now)
-			[]ContractUpdateKey{{}, {}},
+			[]ContractUpdateKey{
+				{
+					Address: flow.Address(parentLocation.Address),
+					Name:    parentLocation.Name,
+				},
+			},
 			nil,
 		})
+		require.True(t, programs.HasChanges())
 
+		// the updated program, and the programs importing it are invalidated
 		retrieved, _, has = programs.Get(someLocation)
 		require.Nil(t, retrieved)
 		require.False(t, has)
 
-		retrieved, _, has = programs.Get(addressLocation)
+		retrieved, _, has = programs.Get(parentLocation)
 		require.Nil(t, retrieved)
 		require.False(t, has)
 
-		retrieved, _, has = programs.Get(parentLocation)
+		// other programs are kept
+		retrieved, _, has = programs.Get(addressLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+
+		// the parent is left untouched
+		retrieved, _, has = parent.Get(parentLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+	})
+
+	t.Run("cleanup with changed contract names", func(t *testing.T) {
+		programs := NewEmptyPrograms()
+		programs.Set(someLocation, someProgram, loadedState(someLocation))
+
+		// a contract added to the account of the program
+		programs.Cleanup(ModifiedSets{
+			[]ContractUpdateKey{
+				{
+					Address: flow.Address(someLocation.Address),
+					Name:    "added",
+				},
+			},
+			nil,
+		})
+
+		retrieved, _, has := programs.Get(someLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+
+		// programs importing all the contracts of the account read its contract names
+		namesState := loadedState(someLocation)
+		_, err := namesState.Get(string(someLocation.Address[:]), state.KeyContractNames, false)
+		require.NoError(t, err)
+		programs.Set(someLocation, someProgram, namesState)
+
+		programs.Cleanup(ModifiedSets{
+			[]ContractUpdateKey{
+				{
+					Address: flow.Address(someLocation.Address),
+					Name:    "added",
+				},
+			},
+			nil,
+		})
+
+		retrieved, _, has = programs.Get(someLocation)
 		require.Nil(t, retrieved)
 		require.False(t, has)
 	})
@@ -127,17 +193,23 @@ func Test_Programs(t *testing.T) {
 		}
 
 		parent := NewEmptyPrograms()
-		parent.Set(parentLocation, &interpreter.Program{}, newState)
+		parent.Set(parentLocation, &interpreter.Program{}, loadedState(parentLocation))
 
 		programs := parent.ChildPrograms()
-		programs.Set(someLocation, someProgram, newState)
-		programs.Set(addressLocation, &interpreter.Program{}, newState)
+		programs.Set(someLocation, someProgram, loadedState(someLocation, parentLocation))
+		programs.Set(addressLocation, &interpreter.Program{}, loadedState(addressLocation))
 
-		retrieved, _, has := programs.Get(someLocation)
-		require.NotNil(t, retrieved)
-		require.True(t, has)
+		programs.Cleanup(ModifiedSets{
+			nil,
+			[]common.Address{addressLocation.Address},
+		})
+		require.True(t, programs.HasChanges())
 
-		retrieved, _, has = programs.Get(addressLocation)
+		retrieved, _, has := programs.Get(addressLocation)
+		require.Nil(t, retrieved)
+		require.False(t, has)
+
+		retrieved, _, has = programs.Get(someLocation)
 		require.NotNil(t, retrieved)
 		require.True(t, has)
 
@@ -147,19 +219,13 @@ func Test_Programs(t *testing.T) {
 
 		programs.Cleanup(ModifiedSets{
 			nil,
-			// For now, we don't care about the frozen account's address, just
-			// their presences.
-			make([]common.Address, 1),
+			[]common.Address{parentLocation.Address},
 		})
 
 		retrieved, _, has = programs.Get(someLocation)
 		require.Nil(t, retrieved)
 		require.False(t, has)
 
-		retrieved, _, has = programs.Get(addressLocation)
-		require.Nil(t, retrieved)
-		require.False(t, has)
-
 		retrieved, _, has = programs.Get(parentLocation)
 		require.Nil(t, retrieved)
 		require.False(t, has)
@@ -232,4 +298,110 @@ func Test_Programs(t *testing.T) {
 		require.True(t, child.HasChanges())
 	})
 
+	t.Run("merge child", func(t *testing.T) {
+		grandparent := NewEmptyPrograms()
+		grandparent.Set(someLocation, someProgram, loadedState(someLocation))
+
+		parent := grandparent.ChildPrograms()
+
+		child := parent.ChildPrograms()
+		child.Set(addressLocation, &interpreter.Program{}, loadedState(addressLocation))
+
+		parent.MergeChild(child)
+
+		retrieved, _, has := parent.Get(addressLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+
+		retrieved, _, has = parent.Get(someLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+
+		// cleaning up the child cleans up the parent, including inherited programs
+		child = parent.ChildPrograms()
+		child.Cleanup(ModifiedSets{
+			[]ContractUpdateKey{
+				{
+					Address: flow.Address(someLocation.Address),
+					Name:    someLocation.Name,
+				},
+			},
+			nil,
+		})
+		parent.MergeChild(child)
+		require.True(t, parent.HasChanges())
+
+		retrieved, _, has = parent.Get(addressLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+
+		retrieved, _, has = parent.Get(someLocation)
+		require.Nil(t, retrieved)
+		require.False(t, has)
+
+		retrieved, _, has = grandparent.Get(someLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+	})
+
+	t.Run("evict", func(t *testing.T) {
+		parent := NewEmptyPrograms()
+		parent.Set(someLocation, someProgram, newState)
+
+		programs := parent.ChildPrograms()
+		programs.Set(addressLocation, &interpreter.Program{}, newState)
+
+		entries := programs.Entries()
+		require.Len(t, entries, 1)
+		require.Equal(t, addressLocation, entries[0].Location)
+
+		// entries of the parents aren't evicted from the children
+		parentEntries := parent.Entries()
+		require.Len(t, parentEntries, 1)
+		require.False(t, programs.Evict(parentEntries[0]))
+
+		require.True(t, programs.Evict(entries[0]))
+		require.False(t, programs.Evict(entries[0]))
+		require.Empty(t, programs.Entries())
+
+		retrieved, _, has := programs.Get(addressLocation)
+		require.Nil(t, retrieved)
+		require.False(t, has)
+
+		retrieved, _, has = programs.Get(someLocation)
+		require.NotNil(t, retrieved)
+		require.True(t, has)
+	})
+
+	t.Run("observer", func(t *testing.T) {
+		observer := &countingObserver{}
+
+		parent := NewEmptyProgramsWithObserver(observer)
+		parent.Set(someLocation, someProgram, newState)
+
+		// children notify the observer of their parents
+		programs := parent.ChildPrograms()
+
+		_, _, has := programs.Get(someLocation)
+		require.True(t, has)
+
+		_, _, has = programs.Get(addressLocation)
+		require.False(t, has)
+
+		require.Equal(t, []common.Location{someLocation}, observer.hits)
+		require.Equal(t, []common.Location{addressLocation}, observer.misses)
+	})
+}
+
+type countingObserver struct {
+	hits   []common.Location
+	misses []common.Location
+}
+
+func (o *countingObserver) ProgramHit(entry *ProgramEntry) {
+	o.hits = append(o.hits, entry.Location)
+}
+
+func (o *countingObserver) ProgramMiss(location common.AddressLocation) {
+	o.misses = append(o.misses, location)
 }
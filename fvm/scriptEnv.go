@@ -34,8 +34,8 @@ func NewScriptEnvironment(
 	accounts := state.NewAccounts(sth)
 	programsHandler := handler.NewProgramsHandler(programs, sth)
 	accountKeys := handler.NewAccountKeyHandler(accounts)
-	tracer := environment.NewTracer(fvmContext.Tracer, nil, fvmContext.ExtensiveTracing)
-	meter := environment.NewCancellableMeter(reqContext, sth)
+	tracer := environment.NewTracer(fvmContext.Tracer, nil, fvmContext.ExtensiveTracing, fvmContext.ExecutionTracer)
+	meter := fvmContext.ExecutionTracer.TraceMeter(environment.NewCancellableMeter(reqContext, sth))
 
 	env := &ScriptEnv{
 		commonEnv: commonEnv{
@@ -32,6 +32,8 @@ type State struct {
 	meter            meter.Meter
 	updatedAddresses map[flow.Address]struct{}
 	updateSize       map[mapKey]uint64
+	readSet          map[mapKey]uint64
+	writeSet         map[mapKey]uint64
 	stateLimits
 	ReadCounter       uint64
 	WriteCounter      uint64
@@ -110,6 +112,8 @@ func NewState(view View, params StateParameters) *State {
 		meter:            m,
 		updatedAddresses: make(map[flow.Address]struct{}),
 		updateSize:       make(map[mapKey]uint64),
+		readSet:          make(map[mapKey]uint64),
+		writeSet:         make(map[mapKey]uint64),
 		stateLimits:      params.stateLimits,
 	}
 }
@@ -121,6 +125,8 @@ func (s *State) NewChild() *State {
 		meter:            s.meter.NewChild(),
 		updatedAddresses: make(map[flow.Address]struct{}),
 		updateSize:       make(map[mapKey]uint64),
+		readSet:          make(map[mapKey]uint64),
+		writeSet:         make(map[mapKey]uint64),
 		stateLimits:      s.stateLimits,
 	}
 }
@@ -154,6 +160,14 @@ func (s *State) Get(owner, key string, enforceLimit bool) (flow.RegisterValue, e
 		s.TotalBytesRead += uint64(len(owner) + len(key) + len(value))
 	}
 
+	// only the first read of registers not written before is part of the read set
+	mapKey := mapKey{owner, key}
+	if _, ok := s.writeSet[mapKey]; !ok {
+		if _, ok := s.readSet[mapKey]; !ok {
+			s.readSet[mapKey] = uint64(len(value))
+		}
+	}
+
 	if enforceLimit {
 		return value, s.checkMaxInteraction()
 	}
@@ -196,6 +210,7 @@ func (s *State) Set(owner, key string, value flow.RegisterValue, enforceLimit bo
 	s.WriteCounter++
 	s.TotalBytesWritten += updateSize
 	s.updateSize[mapKey] = updateSize
+	s.writeSet[mapKey] = uint64(len(value))
 
 	return nil
 }
@@ -205,6 +220,12 @@ func (s *State) Delete(owner, key string, enforceLimit bool) error {
 	return s.Set(owner, key, nil, enforceLimit)
 }
 
+// DropDelta drops the register updates of the state, which are then no longer part of its write set.
+func (s *State) DropDelta() {
+	s.view.DropDelta()
+	s.writeSet = make(map[mapKey]uint64)
+}
+
 // Touch touches a register
 func (s *State) Touch(owner, key string) error {
 	return s.view.Touch(owner, key)
@@ -225,6 +246,11 @@ func (s *State) ComputationIntensities() meter.MeteredComputationIntensities {
 	return s.meter.ComputationIntensities()
 }
 
+// ComputationBreakdown returns the computation used by each computation kind
+func (s *State) ComputationBreakdown() meter.ComputationBreakdown {
+	return meter.NewComputationBreakdown(s.meter.ComputationIntensities(), s.meter.ComputationWeights())
+}
+
 // TotalComputationLimit returns total computation limit
 func (s *State) TotalComputationLimit() uint {
 	return s.meter.TotalComputationLimit()
@@ -240,6 +266,11 @@ func (s *State) MemoryIntensities() meter.MeteredMemoryIntensities {
 	return s.meter.MemoryIntensities()
 }
 
+// MemoryBreakdown returns the memory estimate of each memory kind
+func (s *State) MemoryBreakdown() meter.MemoryBreakdown {
+	return meter.NewMemoryBreakdown(s.meter.MemoryIntensities(), s.meter.MemoryWeights())
+}
+
 // TotalMemoryEstimate returns total memory used
 func (s *State) TotalMemoryEstimate() uint64 {
 	return s.meter.TotalMemoryEstimate()
@@ -250,6 +281,27 @@ func (s *State) TotalMemoryLimit() uint {
 	return uint(s.meter.TotalMemoryLimit())
 }
 
+// EnterContract attributes the computation and memory metered from now on to the given contract
+func (s *State) EnterContract(location common.AddressLocation) {
+	s.meter.EnterContract(location)
+}
+
+// ExitContract ends the attribution to the last entered contract
+func (s *State) ExitContract() {
+	s.meter.ExitContract()
+}
+
+// SetExecutingLocation attributes the computation and memory metered from now on to the contract
+// at the given location, if it is one, instead of the location executing in the current frame
+func (s *State) SetExecutingLocation(location common.Location) {
+	s.meter.SetExecutingLocation(location)
+}
+
+// ContractUsages returns the computation and memory attributed to each contract
+func (s *State) ContractUsages() meter.ContractUsages {
+	return s.meter.ContractUsages()
+}
+
 // MergeState applies the changes from a the given view to this view.
 func (s *State) MergeState(other *State, enforceLimit bool) error {
 	err := s.view.MergeView(other.view)
@@ -272,6 +324,19 @@ func (s *State) MergeState(other *State, enforceLimit bool) error {
 		s.updateSize[k] = v
 	}
 
+	// apply read and write sets, registers read by the other state after being
+	// written by this state are not read from the view of this state
+	for k, v := range other.readSet {
+		_, written := s.writeSet[k]
+		_, read := s.readSet[k]
+		if !written && !read {
+			s.readSet[k] = v
+		}
+	}
+	for k, v := range other.writeSet {
+		s.writeSet[k] = v
+	}
+
 	// update ledger interactions
 	s.ReadCounter += other.ReadCounter
 	s.WriteCounter += other.WriteCounter
@@ -285,6 +350,38 @@ func (s *State) MergeState(other *State, enforceLimit bool) error {
 	return nil
 }
 
+// ReadSet returns the registers read by the state, including the ones read by the merged states,
+// with the size of the values read, sorted by register ID.
+// Registers read after being written by the state aren't part of the read set.
+func (s *State) ReadSet() []flow.RegisterAccess {
+	return sortedRegisterAccesses(s.readSet)
+}
+
+// WriteSet returns the registers written by the state, including the ones written by the merged states,
+// with the size of the values written, sorted by register ID.
+func (s *State) WriteSet() []flow.RegisterAccess {
+	return sortedRegisterAccesses(s.writeSet)
+}
+
+func sortedRegisterAccesses(set map[mapKey]uint64) []flow.RegisterAccess {
+	accesses := make([]flow.RegisterAccess, 0, len(set))
+	for k, size := range set {
+		accesses = append(accesses, flow.RegisterAccess{
+			Register: flow.NewRegisterID(k.owner, k.key),
+			Size:     size,
+		})
+	}
+
+	sort.Slice(accesses, func(i, j int) bool {
+		if accesses[i].Register.Owner != accesses[j].Register.Owner {
+			return accesses[i].Register.Owner < accesses[j].Register.Owner
+		}
+		return accesses[i].Register.Key < accesses[j].Register.Key
+	})
+
+	return accesses
+}
+
 type sortedAddresses []flow.Address
 
 func (a sortedAddresses) Len() int           { return len(a) }
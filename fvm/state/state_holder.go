@@ -1,6 +1,9 @@
 package state
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/onflow/cadence/runtime/common"
 
 	"github.com/onflow/flow-go/fvm/meter"
@@ -67,6 +70,14 @@ func (s *StateHolder) UpdatedAddresses() []flow.Address {
 	return s.activeState.UpdatedAddresses()
 }
 
+func (s *StateHolder) ReadSet() []flow.RegisterAccess {
+	return s.activeState.ReadSet()
+}
+
+func (s *StateHolder) WriteSet() []flow.RegisterAccess {
+	return s.activeState.WriteSet()
+}
+
 func (s *StateHolder) MeterComputation(
 	kind common.ComputationKind,
 	intensity uint,
@@ -85,6 +96,10 @@ func (s *StateHolder) ComputationIntensities() meter.MeteredComputationIntensiti
 	return s.activeState.ComputationIntensities()
 }
 
+func (s *StateHolder) ComputationBreakdown() meter.ComputationBreakdown {
+	return s.activeState.ComputationBreakdown()
+}
+
 func (s *StateHolder) TotalComputationLimit() uint {
 	return s.activeState.TotalComputationLimit()
 }
@@ -97,10 +112,48 @@ func (s *StateHolder) MemoryIntensities() meter.MeteredMemoryIntensities {
 	return s.activeState.MemoryIntensities()
 }
 
+func (s *StateHolder) MemoryBreakdown() meter.MemoryBreakdown {
+	return s.activeState.MemoryBreakdown()
+}
+
 func (s *StateHolder) TotalMemoryEstimate() uint64 {
 	return s.activeState.TotalMemoryEstimate()
 }
 
+func (s *StateHolder) EnterContract(location common.AddressLocation) {
+	s.activeState.EnterContract(location)
+}
+
+func (s *StateHolder) ExitContract() {
+	s.activeState.ExitContract()
+}
+
+func (s *StateHolder) SetExecutingLocation(location common.Location) {
+	s.activeState.SetExecutingLocation(location)
+}
+
+// ContractUsages returns the computation and memory attributed to each contract, sorted by contract
+func (s *StateHolder) ContractUsages() []flow.ContractUsage {
+	usages := make([]flow.ContractUsage, 0)
+	for location, usage := range s.activeState.ContractUsages() {
+		usages = append(usages, flow.ContractUsage{
+			Address:         flow.Address(location.Address),
+			Name:            location.Name,
+			ComputationUsed: usage.ComputationUsed,
+			MemoryEstimate:  usage.MemoryEstimate,
+		})
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Address != usages[j].Address {
+			return bytes.Compare(usages[i].Address[:], usages[j].Address[:]) < 0
+		}
+		return usages[i].Name < usages[j].Name
+	})
+
+	return usages
+}
+
 func (s *StateHolder) InteractionUsed() uint64 {
 	return s.activeState.InteractionUsed()
 }
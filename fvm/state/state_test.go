@@ -10,6 +10,7 @@ import (
 
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/fvm/utils"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 func TestState_ChildMergeFunctionality(t *testing.T) {
@@ -114,6 +115,57 @@ func TestState_InteractionMeasuring(t *testing.T) {
 	require.Equal(t, keySize, st.TotalBytesRead)
 }
 
+func TestState_ReadWriteSets(t *testing.T) {
+	view := utils.NewSimpleView()
+	err := view.Set("address", "existing", createByteArray(5))
+	require.NoError(t, err)
+
+	st := state.NewState(view, state.DefaultParameters())
+
+	// reads of registers written before aren't part of the read set
+	err = st.Set("address", "written", createByteArray(2), true)
+	require.NoError(t, err)
+	_, err = st.Get("address", "written", true)
+	require.NoError(t, err)
+
+	_, err = st.Get("address", "existing", true)
+	require.NoError(t, err)
+	err = st.Set("address", "existing", createByteArray(1), true)
+	require.NoError(t, err)
+
+	child := st.NewChild()
+	_, err = child.Get("address", "written", true)
+	require.NoError(t, err)
+	_, err = child.Get("", "missing", true)
+	require.NoError(t, err)
+	err = child.Set("address", "child", createByteArray(3), true)
+	require.NoError(t, err)
+
+	// dropped updates aren't part of the write set
+	dropped := st.NewChild()
+	_, err = dropped.Get("address", "dropped-read", true)
+	require.NoError(t, err)
+	err = dropped.Set("address", "dropped", createByteArray(4), true)
+	require.NoError(t, err)
+	dropped.DropDelta()
+
+	err = st.MergeState(child, true)
+	require.NoError(t, err)
+	err = st.MergeState(dropped, true)
+	require.NoError(t, err)
+
+	require.Equal(t, []flow.RegisterAccess{
+		{Register: flow.NewRegisterID("", "missing"), Size: 0},
+		{Register: flow.NewRegisterID("address", "dropped-read"), Size: 0},
+		{Register: flow.NewRegisterID("address", "existing"), Size: 5},
+	}, st.ReadSet())
+	require.Equal(t, []flow.RegisterAccess{
+		{Register: flow.NewRegisterID("address", "child"), Size: 3},
+		{Register: flow.NewRegisterID("address", "existing"), Size: 1},
+		{Register: flow.NewRegisterID("address", "written"), Size: 2},
+	}, st.WriteSet())
+}
+
 func TestState_MaxValueSize(t *testing.T) {
 	view := utils.NewSimpleView()
 	st := state.NewState(view, state.DefaultParameters().WithMaxValueSizeAllowed(6))
@@ -8,6 +8,7 @@ import (
 	otelTrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/fvm/meter"
 	"github.com/onflow/flow-go/fvm/programs"
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/model/flow"
@@ -34,8 +35,19 @@ type TransactionProcedure struct {
 	ServiceEvents   []flow.Event
 	ComputationUsed uint64
 	MemoryEstimate  uint64
-	Err             errors.Error
-	TraceSpan       otelTrace.Span
+	// ComputationBreakdown and MemoryBreakdown are only set if the metering breakdown is enabled
+	// in the context
+	ComputationBreakdown meter.ComputationBreakdown
+	MemoryBreakdown      meter.MemoryBreakdown
+	// RegisterReads and RegisterWrites are the registers read and written by the transaction,
+	// including the fee deduction and sequence number increment, but not the execution
+	// parameters read by the virtual machine before running the transaction
+	RegisterReads  []flow.RegisterAccess
+	RegisterWrites []flow.RegisterAccess
+	// ContractUsages are the computation and memory attributed to the contracts executed by the transaction
+	ContractUsages []flow.ContractUsage
+	Err            errors.Error
+	TraceSpan      otelTrace.Span
 }
 
 func (proc *TransactionProcedure) SetTraceSpan(traceSpan otelTrace.Span) {
@@ -77,6 +89,10 @@ func (proc *TransactionProcedure) Run(vm *VirtualMachine, ctx Context, st *state
 		}
 	}
 
+	proc.RegisterReads = st.ReadSet()
+	proc.RegisterWrites = st.WriteSet()
+	proc.ContractUsages = st.ContractUsages()
+
 	return nil
 }
 
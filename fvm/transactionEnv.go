@@ -54,8 +54,8 @@ func NewTransactionEnvironment(
 		ctx.EventCollectionByteSizeLimit,
 	)
 	accountKeys := handler.NewAccountKeyHandler(accounts)
-	tracer := environment.NewTracer(ctx.Tracer, traceSpan, ctx.ExtensiveTracing)
-	meter := environment.NewMeter(sth)
+	tracer := environment.NewTracer(ctx.Tracer, traceSpan, ctx.ExtensiveTracing, ctx.ExecutionTracer)
+	meter := ctx.ExecutionTracer.TraceMeter(environment.NewMeter(sth))
 
 	env := &TransactionEnv{
 		commonEnv: commonEnv{
@@ -66,7 +66,7 @@ func (i *TransactionInvoker) Process(
 				Msg(msg)
 
 			// drop delta
-			childState.View().DropDelta()
+			childState.DropDelta()
 			proc.Err = errors.NewFVMInternalErrorf(msg)
 			proc.Logs = make([]string, 0)
 			proc.Events = make([]flow.Event, 0)
@@ -114,6 +114,10 @@ func (i *TransactionInvoker) Process(
 	// log te execution intensities here, so tha they do not contain data from storage limit checks and
 	// transaction deduction, because the payer is not charged for those.
 	i.logExecutionIntensities(sth, txIDStr)
+	if ctx.MeteringBreakdownEnabled {
+		proc.ComputationBreakdown = sth.ComputationBreakdown()
+		proc.MemoryBreakdown = sth.MemoryBreakdown()
+	}
 
 	// disable the limit checks on states
 	sth.DisableAllLimitEnforcements()
@@ -151,7 +155,7 @@ func (i *TransactionInvoker) Process(
 		defer sth.EnableAllLimitEnforcements()
 
 		// drop delta since transaction failed
-		childState.View().DropDelta()
+		childState.DropDelta()
 		// if tx fails just do clean up
 		programs.Cleanup(programsCache.ModifiedSets{})
 		// log transaction as failed
@@ -175,7 +179,7 @@ func (i *TransactionInvoker) Process(
 		// if fee deduction fails just do clean up and exit
 		if feesError != nil {
 			// drop delta
-			childState.View().DropDelta()
+			childState.DropDelta()
 			programs.Cleanup(programsCache.ModifiedSets{})
 			i.logger.Info().
 				Str("txHash", txIDStr).
@@ -72,7 +72,7 @@ func (c *TransactionSequenceNumberChecker) checkAndIncrementSequenceNumber(
 
 	_, err = accounts.SetPublicKey(proposalKey.Address, proposalKey.KeyIndex, accountKey)
 	if err != nil {
-		childState.View().DropDelta()
+		childState.DropDelta()
 		return fmt.Errorf("checking sequence number failed: %w", err)
 	}
 	return nil
@@ -26,6 +26,8 @@ func TestTransactionStorageLimiter_Process(t *testing.T) {
 		env.On("Context").Return(ctx)
 		env.On("AccountFreezeEnabled").Return(false)
 		env.On("StartSpanFromRoot", mock.Anything).Return(trace.NoopSpan)
+		env.On("EnterContract", mock.Anything).Return()
+		env.On("ExitContract").Return()
 		env.On("GetStorageUsed", mock.Anything).Return(uint64(99), nil)
 		env.On("VM", mock.Anything).Return(&fvm.VirtualMachine{
 			Runtime: &TestInterpreterRuntime{
@@ -47,6 +49,8 @@ func TestTransactionStorageLimiter_Process(t *testing.T) {
 		env.On("Context").Return(ctx)
 		env.On("AccountFreezeEnabled").Return(false)
 		env.On("StartSpanFromRoot", mock.Anything).Return(trace.NoopSpan)
+		env.On("EnterContract", mock.Anything).Return()
+		env.On("ExitContract").Return()
 		env.On("GetStorageUsed", mock.Anything).Return(uint64(100), nil)
 		env.On("VM", mock.Anything).Return(&fvm.VirtualMachine{
 			Runtime: &TestInterpreterRuntime{
@@ -68,6 +72,8 @@ func TestTransactionStorageLimiter_Process(t *testing.T) {
 		env.On("Context").Return(ctx)
 		env.On("AccountFreezeEnabled").Return(false)
 		env.On("StartSpanFromRoot", mock.Anything).Return(trace.NoopSpan)
+		env.On("EnterContract", mock.Anything).Return()
+		env.On("ExitContract").Return()
 		env.On("GetStorageUsed", mock.Anything).Return(uint64(101), nil)
 		env.On("VM", mock.Anything).Return(&fvm.VirtualMachine{
 			Runtime: &TestInterpreterRuntime{
@@ -89,6 +95,8 @@ func TestTransactionStorageLimiter_Process(t *testing.T) {
 		env.On("Context").Return(ctx)
 		env.On("AccountFreezeEnabled").Return(false)
 		env.On("StartSpanFromRoot", mock.Anything).Return(trace.NoopSpan)
+		env.On("EnterContract", mock.Anything).Return()
+		env.On("ExitContract").Return()
 		env.On("GetStorageCapacity", mock.Anything).Return(uint64(100), nil)
 		env.On("GetStorageUsed", mock.Anything).Return(uint64(101), nil)
 		env.On("VM", mock.Anything).Return(&fvm.VirtualMachine{
@@ -111,6 +119,8 @@ func TestTransactionStorageLimiter_Process(t *testing.T) {
 		env.On("Context").Return(ctx)
 		env.On("AccountFreezeEnabled").Return(false)
 		env.On("StartSpanFromRoot", mock.Anything).Return(trace.NoopSpan)
+		env.On("EnterContract", mock.Anything).Return()
+		env.On("ExitContract").Return()
 		env.On("GetStorageUsed", mock.Anything).Return(uint64(0), errors.NewAccountNotFoundError(owner))
 		env.On("VM", mock.Anything).Return(&fvm.VirtualMachine{
 			Runtime: &TestInterpreterRuntime{
@@ -67,3 +67,45 @@ func CadenceValueToWeights(value cadence.Value) (map[uint]uint64, bool) {
 
 	return result, true
 }
+
+// CadenceValueToExecutionParametersRecords converts a cadence value to the execution parameters record,
+// a map of activation heights to encoded execution parameters
+func CadenceValueToExecutionParametersRecords(value cadence.Value) (map[uint64]map[string]uint64, bool) {
+	result := make(map[uint64]map[string]uint64)
+
+	dict, ok := value.(cadence.Dictionary)
+	if !ok {
+		return nil, false
+	}
+
+	for _, p := range dict.Pairs {
+		height, ok := p.Key.(cadence.UInt64)
+		if !ok {
+			return nil, false
+		}
+
+		params, ok := p.Value.(cadence.Dictionary)
+		if !ok {
+			return nil, false
+		}
+
+		record := make(map[string]uint64, len(params.Pairs))
+		for _, param := range params.Pairs {
+			key, ok := param.Key.(cadence.String)
+			if !ok {
+				return nil, false
+			}
+
+			value, ok := param.Value.(cadence.UInt64)
+			if !ok {
+				return nil, false
+			}
+
+			record[string(key)] = uint64(value)
+		}
+
+		result[uint64(height)] = record
+	}
+
+	return result, true
+}
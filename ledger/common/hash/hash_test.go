@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"golang.org/x/crypto/sha3"
+	"lukechampine.com/blake3"
 
 	"github.com/stretchr/testify/assert"
 
@@ -57,6 +58,57 @@ func TestHash(t *testing.T) {
 	})
 }
 
+func TestHasher(t *testing.T) {
+	var path, h1, h2 hash.Hash
+	rand.Read(path[:])
+	rand.Read(h1[:])
+	rand.Read(h2[:])
+	value := []byte("value")
+
+	t.Run("parse hasher type", func(t *testing.T) {
+		for _, hasherType := range []hash.HasherType{hash.SHA3_256, hash.BLAKE3} {
+			parsed, err := hash.ParseHasherType(hasherType.String())
+			assert.NoError(t, err)
+			assert.Equal(t, hasherType, parsed)
+		}
+
+		_, err := hash.ParseHasherType("md5")
+		assert.Error(t, err)
+
+		_, err = hash.NewHasher(hash.HasherType(100))
+		assert.Error(t, err)
+	})
+
+	t.Run("SHA3_256", func(t *testing.T) {
+		hasher, err := hash.NewHasher(hash.SHA3_256)
+		assert.NoError(t, err)
+
+		assert.Equal(t, hash.HashLeaf(path, value), hasher.HashLeaf(path, value))
+		assert.Equal(t, hash.HashInterNode(h1, h2), hasher.HashInterNode(h1, h2))
+
+		expected := sha3.Sum256(value)
+		h := hasher.HashData(value)
+		assert.Equal(t, expected[:], h[:])
+	})
+
+	t.Run("BLAKE3", func(t *testing.T) {
+		hasher, err := hash.NewHasher(hash.BLAKE3)
+		assert.NoError(t, err)
+
+		expected := blake3.Sum256(append(path[:], value...))
+		h := hasher.HashLeaf(path, value)
+		assert.Equal(t, expected[:], h[:])
+
+		expected = blake3.Sum256(append(h1[:], h2[:]...))
+		h = hasher.HashInterNode(h1, h2)
+		assert.Equal(t, expected[:], h[:])
+
+		expected = blake3.Sum256(value)
+		h = hasher.HashData(value)
+		assert.Equal(t, expected[:], h[:])
+	})
+}
+
 // Test_GetDefaultHashForHeight tests getting default hash for given heights
 func Test_GetDefaultHashForHeight(t *testing.T) {
 	var defaultLeafHash [cryhash.HashLenSHA3_256]byte
@@ -106,6 +158,16 @@ func BenchmarkHash(b *testing.B) {
 		b.StopTimer()
 	})
 
+	// BLAKE3 ledger hasher
+	b.Run("LedgerBlake3", func(b *testing.B) {
+		hasher, _ := hash.NewHasher(hash.BLAKE3)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = hasher.HashInterNode(h1, h2)
+		}
+		b.StopTimer()
+	})
+
 	// flow crypto generic sha3
 	b.Run("GenericSha3", func(b *testing.B) {
 		b.ResetTimer()
@@ -0,0 +1,101 @@
+package hash
+
+import (
+	"fmt"
+
+	"lukechampine.com/blake3"
+
+	cryptoHash "github.com/onflow/flow-go/crypto/hash"
+)
+
+// Hasher computes the hashes of ledger trie nodes.
+type Hasher interface {
+	// HashLeaf returns the hash value for leaf nodes, from the path and value of the leaf.
+	HashLeaf(path Hash, value []byte) Hash
+	// HashInterNode returns the hash value for intermediate nodes, from the hashes of its children.
+	HashInterNode(hash1 Hash, hash2 Hash) Hash
+	// HashData returns the hash of arbitrary data, such as the default leaf value.
+	HashData(data []byte) Hash
+}
+
+// HasherType identifies a Hasher, for instance in checkpoint files.
+type HasherType uint8
+
+const (
+	// SHA3_256 is the default hasher, implemented by HashLeaf and HashInterNode.
+	SHA3_256 HasherType = iota
+	// BLAKE3 is a hasher based on BLAKE3 with a 256 bits output.
+	BLAKE3
+)
+
+func (t HasherType) String() string {
+	switch t {
+	case SHA3_256:
+		return "sha3-256"
+	case BLAKE3:
+		return "blake3"
+	default:
+		return fmt.Sprintf("unknown hasher %d", t)
+	}
+}
+
+// ParseHasherType returns the hasher type with the given name, see HasherType.String.
+func ParseHasherType(name string) (HasherType, error) {
+	for _, t := range []HasherType{SHA3_256, BLAKE3} {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hasher %s", name)
+}
+
+// NewHasher returns the Hasher of the given type.
+func NewHasher(t HasherType) (Hasher, error) {
+	switch t {
+	case SHA3_256:
+		return sha3Hasher{}, nil
+	case BLAKE3:
+		return blake3Hasher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown hasher %d", t)
+	}
+}
+
+type sha3Hasher struct{}
+
+func (sha3Hasher) HashLeaf(path Hash, value []byte) Hash {
+	return HashLeaf(path, value)
+}
+
+func (sha3Hasher) HashInterNode(hash1 Hash, hash2 Hash) Hash {
+	return HashInterNode(hash1, hash2)
+}
+
+func (sha3Hasher) HashData(data []byte) Hash {
+	var h Hash
+	cryptoHash.ComputeSHA3_256((*[HashLen]byte)(&h), data)
+	return h
+}
+
+type blake3Hasher struct{}
+
+func (blake3Hasher) HashLeaf(path Hash, value []byte) Hash {
+	hasher := blake3.New(HashLen, nil)
+	_, _ = hasher.Write(path[:])
+	_, _ = hasher.Write(value)
+
+	var h Hash
+	copy(h[:], hasher.Sum(nil))
+	return h
+}
+
+func (blake3Hasher) HashInterNode(hash1 Hash, hash2 Hash) Hash {
+	var data [2 * HashLen]byte
+	copy(data[:HashLen], hash1[:])
+	copy(data[HashLen:], hash2[:])
+	return blake3.Sum256(data[:])
+}
+
+func (blake3Hasher) HashData(data []byte) Hash {
+	return blake3.Sum256(data)
+}
@@ -12,8 +12,16 @@ import (
 // PathByteSize captures number of bytes each path takes
 const PathByteSize = 32
 
+// OwnerPrefixByteSize captures number of bytes of the owner prefix of paths (version two)
+const OwnerPrefixByteSize = 8
+
 // KeyToPath converts key into a path
 // version zero applies sha2-256 on value of the key parts (in order ignoring types)
+// version one applies sha3-256 on the canonical form of the key
+// version two prefixes the path with the owner prefix (see OwnerPathPrefix) of the first
+// key part, followed by the first bytes of the sha3-256 of the canonical form of the key.
+// Version two keeps all registers of an owner in the same subtrie, which can be iterated
+// and proven as a whole.
 func KeyToPath(key ledger.Key, version uint8) (ledger.Path, error) {
 	switch version {
 	case 0:
@@ -39,10 +47,45 @@ func KeyToPath(key ledger.Key, version uint8) (ledger.Path, error) {
 			hash.ComputeSHA3_256((*[ledger.PathLen]byte)(&path), key.CanonicalForm())
 			return path, nil
 		}
+	case 2:
+		{
+			if len(key.KeyParts) == 0 {
+				return ledger.DummyPath, fmt.Errorf("key has no owner key part")
+			}
+			var keyHash [ledger.PathLen]byte
+			hash.ComputeSHA3_256(&keyHash, key.CanonicalForm())
+
+			var path ledger.Path
+			prefix := ownerPathPrefix(key.KeyParts[0].Value)
+			copy(path[:], prefix[:])
+			copy(path[OwnerPrefixByteSize:], keyHash[:])
+			return path, nil
+		}
 	}
 	return ledger.DummyPath, fmt.Errorf("unsupported key to path version")
 }
 
+// OwnerPathPrefix returns the prefix of the paths of all registers of the given owner
+// (the value of the first key part of their keys), which is the first OwnerPrefixByteSize
+// bytes of the sha3-256 of the owner.
+// Only key to path version two supports owner prefixes.
+func OwnerPathPrefix(owner []byte, version uint8) ([]byte, error) {
+	if version != 2 {
+		return nil, fmt.Errorf("key to path version %d does not support owner prefixes", version)
+	}
+	prefix := ownerPathPrefix(owner)
+	return prefix[:], nil
+}
+
+func ownerPathPrefix(owner []byte) [OwnerPrefixByteSize]byte {
+	var ownerHash [ledger.PathLen]byte
+	hash.ComputeSHA3_256(&ownerHash, owner)
+
+	var prefix [OwnerPrefixByteSize]byte
+	copy(prefix[:], ownerHash[:])
+	return prefix
+}
+
 // KeysToPaths converts an slice of keys into a paths
 func KeysToPaths(keys []ledger.Key, version uint8) ([]ledger.Path, error) {
 	paths := make([]ledger.Path, len(keys))
@@ -51,3 +51,45 @@ func Test_KeyToPathV1(t *testing.T) {
 	copy(expected[:], hasher.SumHash())
 	require.Equal(t, path, expected)
 }
+
+func Test_KeyToPathV2(t *testing.T) {
+
+	kp1 := testutils.KeyPartFixture(0, "owner")
+	kp2 := testutils.KeyPartFixture(22, "key part 2")
+	k := ledger.NewKey([]ledger.KeyPart{kp1, kp2})
+
+	path, err := pathfinder.KeyToPath(k, 2)
+	require.NoError(t, err)
+
+	prefix, err := pathfinder.OwnerPathPrefix([]byte("owner"), 2)
+	require.NoError(t, err)
+	require.Len(t, prefix, pathfinder.OwnerPrefixByteSize)
+
+	// compute expected value
+	hasher := hash.NewSHA3_256()
+	_, err = hasher.Write([]byte("/0/owner/22/key part 2"))
+	require.NoError(t, err)
+
+	var expected ledger.Path
+	copy(expected[:], prefix)
+	copy(expected[pathfinder.OwnerPrefixByteSize:], hasher.SumHash())
+	require.Equal(t, path, expected)
+
+	t.Run("registers of the same owner share the prefix", func(t *testing.T) {
+		k := ledger.NewKey([]ledger.KeyPart{kp1, testutils.KeyPartFixture(22, "key part 3")})
+		other, err := pathfinder.KeyToPath(k, 2)
+		require.NoError(t, err)
+		require.Equal(t, prefix, other[:pathfinder.OwnerPrefixByteSize])
+		require.NotEqual(t, path, other)
+	})
+
+	t.Run("key without key parts", func(t *testing.T) {
+		_, err := pathfinder.KeyToPath(ledger.NewKey(nil), 2)
+		require.Error(t, err)
+	})
+
+	t.Run("owner prefix not supported by older versions", func(t *testing.T) {
+		_, err := pathfinder.OwnerPathPrefix([]byte("owner"), 1)
+		require.Error(t, err)
+	})
+}
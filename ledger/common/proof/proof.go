@@ -1,6 +1,8 @@
 package proof
 
 import (
+	"bytes"
+
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/bitutils"
 	"github.com/onflow/flow-go/ledger/common/hash"
@@ -11,6 +13,12 @@ import (
 // VerifyTrieProof verifies the proof, by constructing all the
 // hash from the leaf to the root and comparing the rootHash
 func VerifyTrieProof(p *ledger.TrieProof, expectedState ledger.State) bool {
+	return VerifyTrieProofWithHasher(p, expectedState, ledger.DefaultTrieHasher)
+}
+
+// VerifyTrieProofWithHasher verifies the proof like VerifyTrieProof, for a trie
+// hashed by the given hasher.
+func VerifyTrieProofWithHasher(p *ledger.TrieProof, expectedState ledger.State, hasher *ledger.TrieHasher) bool {
 	treeHeight := ledger.NodeMaxHeight
 	leafHeight := treeHeight - int(p.Steps)             // p.Steps is the number of edges we are traversing until we hit the compactified leaf.
 	if !(0 <= leafHeight && leafHeight <= treeHeight) { // sanity check
@@ -18,7 +26,7 @@ func VerifyTrieProof(p *ledger.TrieProof, expectedState ledger.State) bool {
 	}
 	// We start with the leaf and hash our way upwards towards the root
 	proofIndex := len(p.Interims) - 1                                                        // the index of the last non-default value furthest down the tree (-1 if there is none)
-	computed := ledger.ComputeCompactValue(hash.Hash(p.Path), p.Payload.Value(), leafHeight) // we first compute the hash of the compact leaf (at height leafHeight)
+	computed := hasher.ComputeCompactValue(hash.Hash(p.Path), p.Payload.Value(), leafHeight) // we first compute the hash of the compact leaf (at height leafHeight)
 	for h := leafHeight + 1; h <= treeHeight; h++ {                                          // then, we hash our way upwards until we hit the root (at height `treeHeight`)
 		// we are currently at a node n (initially the leaf). In this iteration, we want to compute the
 		// parent's hash. Here, h is the height of the parent, whose hash want to compute.
@@ -35,15 +43,15 @@ func VerifyTrieProof(p *ledger.TrieProof, expectedState ledger.State) bool {
 			siblingHash = p.Interims[proofIndex]
 			proofIndex--
 		} else { // otherwise, siblingHash is a default hash
-			siblingHash = ledger.GetDefaultHashForHeight(h - 1)
+			siblingHash = hasher.GetDefaultHashForHeight(h - 1)
 		}
 
 		bit := bitutils.ReadBit(p.Path[:], treeHeight-h)
 		// hashing is order dependent
 		if bit == 1 { // we hash our way up to the parent along the parent's right branch
-			computed = hash.HashInterNode(siblingHash, computed)
+			computed = hasher.HashInterNode(siblingHash, computed)
 		} else { // we hash our way up to the parent along the parent's left branch
-			computed = hash.HashInterNode(computed, siblingHash)
+			computed = hasher.HashInterNode(computed, siblingHash)
 		}
 	}
 	return (computed == hash.Hash(expectedState)) == p.Inclusion
@@ -51,11 +59,118 @@ func VerifyTrieProof(p *ledger.TrieProof, expectedState ledger.State) bool {
 
 // VerifyTrieBatchProof verifies all the proof inside the batchproof
 func VerifyTrieBatchProof(bp *ledger.TrieBatchProof, expectedState ledger.State) bool {
+	return VerifyTrieBatchProofWithHasher(bp, expectedState, ledger.DefaultTrieHasher)
+}
+
+// VerifyTrieBatchProofWithHasher verifies all the proof inside the batchproof,
+// for a trie hashed by the given hasher.
+func VerifyTrieBatchProofWithHasher(bp *ledger.TrieBatchProof, expectedState ledger.State, hasher *ledger.TrieHasher) bool {
 	for _, p := range bp.Proofs {
 		// any invalid proof
-		if !VerifyTrieProof(p, expectedState) {
+		if !VerifyTrieProofWithHasher(p, expectedState, hasher) {
 			return false
 		}
 	}
 	return true
 }
+
+// VerifyTrieRangeProof verifies the range proof, by computing the hash of the
+// proven subtrie from its payloads, hashing our way up to the root and comparing
+// the rootHash. A valid proof guarantees that the payloads of the proof are all
+// the payloads stored under the proof's prefix.
+func VerifyTrieRangeProof(p *ledger.TrieRangeProof, expectedState ledger.State) bool {
+	return VerifyTrieRangeProofWithHasher(p, expectedState, ledger.DefaultTrieHasher)
+}
+
+// VerifyTrieRangeProofWithHasher verifies the range proof like VerifyTrieRangeProof,
+// for a trie hashed by the given hasher.
+func VerifyTrieRangeProofWithHasher(p *ledger.TrieRangeProof, expectedState ledger.State, hasher *ledger.TrieHasher) bool {
+	treeHeight := ledger.NodeMaxHeight
+	steps := int(p.Steps) // number of edges from the root to the proven subtrie
+	// sanity checks: the proven subtrie must be on the prefix branch
+	if len(p.Prefix) > ledger.PathLen || steps > 8*len(p.Prefix) || steps > 8*len(p.Flags) {
+		return false
+	}
+	if len(p.Paths) != len(p.Payloads) {
+		return false
+	}
+	// paths must be sorted, unique, non-empty and located in the proven subtrie
+	for i, path := range p.Paths {
+		if i > 0 && bytes.Compare(p.Paths[i-1][:], path[:]) >= 0 {
+			return false
+		}
+		if p.Payloads[i].IsEmpty() {
+			return false
+		}
+		for j := 0; j < steps; j++ {
+			if bitutils.ReadBit(path[:], j) != bitutils.ReadBit(p.Prefix, j) {
+				return false
+			}
+		}
+	}
+
+	subtrieHeight := treeHeight - steps
+	computed, ok := computeSubtrieHash(p.Paths, p.Payloads, subtrieHeight, hasher)
+	if !ok {
+		return false
+	}
+
+	// We hash our way upwards from the proven subtrie towards the root
+	proofIndex := len(p.Interims) - 1
+	for h := subtrieHeight + 1; h <= treeHeight; h++ {
+		var siblingHash hash.Hash
+		if bitutils.ReadBit(p.Flags, treeHeight-h) == 1 { // if flag is set, siblingHash is stored in the proof
+			if proofIndex < 0 { // proof invalid: too few values
+				return false
+			}
+			siblingHash = p.Interims[proofIndex]
+			proofIndex--
+		} else { // otherwise, siblingHash is a default hash
+			siblingHash = hasher.GetDefaultHashForHeight(h - 1)
+		}
+
+		// hashing is order dependent
+		if bitutils.ReadBit(p.Prefix, treeHeight-h) == 1 {
+			computed = hasher.HashInterNode(siblingHash, computed)
+		} else {
+			computed = hasher.HashInterNode(computed, siblingHash)
+		}
+	}
+	// proof invalid: too many values
+	if proofIndex != -1 {
+		return false
+	}
+	return computed == hash.Hash(expectedState)
+}
+
+// computeSubtrieHash computes the hash of the subtrie at the given height, which
+// holds exactly the given payloads, ordered by path.
+// It returns false if the payloads can't be held by a single subtrie at that height.
+func computeSubtrieHash(paths []ledger.Path, payloads []*ledger.Payload, height int, hasher *ledger.TrieHasher) (hash.Hash, bool) {
+	switch len(paths) {
+	case 0:
+		return hasher.GetDefaultHashForHeight(height), true
+	case 1:
+		return hasher.ComputeCompactValue(hash.Hash(paths[0]), payloads[0].Value(), height), true
+	}
+	if height == 0 {
+		return hash.DummyHash, false
+	}
+
+	// paths are sorted, so the paths of the left subtrie come first
+	bitIndex := ledger.NodeMaxHeight - height
+	split := 0
+	for split < len(paths) && bitutils.ReadBit(paths[split][:], bitIndex) == 0 {
+		split++
+	}
+
+	left, ok := computeSubtrieHash(paths[:split], payloads[:split], height-1, hasher)
+	if !ok {
+		return hash.DummyHash, false
+	}
+	right, ok := computeSubtrieHash(paths[split:], payloads[split:], height-1, hasher)
+	if !ok {
+		return hash.DummyHash, false
+	}
+	return hasher.HashInterNode(left, right), true
+}
@@ -11,6 +11,7 @@ import (
 	"golang.org/x/sync/semaphore"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/module/lifecycle"
@@ -45,6 +46,12 @@ type checkpointResult struct {
 // then ledger state and checkpointing queue may contain different tries.
 // This will be resolved automaticaly after the forest LRU Cache
 // (code outside checkpointing) is replaced by something like a FIFO queue.
+//
+// If the forest keeps payloads in a payload store (see mtrie.WithPayloadStore), the tries
+// in the checkpointing queue, being checkpointed, or being the base of the next incremental
+// checkpoint are retained in the forest, so that their payloads are kept. Checkpoints then
+// reference the stored payloads (unless they are archived), and payloads released by the
+// forest are removed from the store once no kept checkpoint references them.
 type Compactor struct {
 	checkpointer                         *realWAL.Checkpointer
 	wal                                  realWAL.LedgerWAL
@@ -57,6 +64,43 @@ type Compactor struct {
 	stopCh                               chan chan struct{}
 	trieUpdateCh                         <-chan *WALTrieUpdate
 	triggerCheckpointOnNextSegmentFinish *atomic.Bool // to trigger checkpoint manually
+	deltaCheckpoints                     uint
+	base                                 *checkpointBase // only used by checkpointing goroutine
+	archiver                             *realWAL.Archiver
+	forest                               *mtrie.Forest
+	payloadGenerations                   map[int]uint64 // payload store generation of created checkpoints, only used by checkpointing goroutine
+}
+
+// checkpointBase is the last checkpoint created by Compactor, which is
+// the base of the next incremental checkpoint.
+type checkpointBase struct {
+	num    int
+	tries  []*trie.MTrie
+	deltas uint // number of incremental checkpoints since last full checkpoint
+}
+
+// CompactorOption is an option of NewCompactor.
+type CompactorOption func(*Compactor)
+
+// WithIncrementalCheckpoints makes Compactor create up to deltaCheckpoints incremental
+// checkpoints (see realWAL.StoreCheckpointDelta) between full checkpoints.
+// Each incremental checkpoint is based on the previous checkpoint, so loading it requires
+// loading all checkpoints since the last full checkpoint. As incremental checkpoints need
+// the tries of their base in memory, the first checkpoint created by Compactor is always full.
+func WithIncrementalCheckpoints(deltaCheckpoints uint) CompactorOption {
+	return func(c *Compactor) {
+		c.deltaCheckpoints = deltaCheckpoints
+	}
+}
+
+// WithArchiver makes Compactor archive every checkpoint it creates, archive checkpoints
+// before removing them, and archive and remove the segments included in the oldest
+// kept checkpoint, so that all trie updates are kept in the archive (see realWAL.Archiver).
+// Checkpoints and segments are only removed once they have been archived.
+func WithArchiver(archiver *realWAL.Archiver) CompactorOption {
+	return func(c *Compactor) {
+		c.archiver = archiver
+	}
 }
 
 // NewCompactor creates new Compactor which writes WAL record and triggers
@@ -76,6 +120,7 @@ func NewCompactor(
 	checkpointDistance uint,
 	checkpointsToKeep uint,
 	triggerCheckpointOnNextSegmentFinish *atomic.Bool,
+	opts ...CompactorOption,
 ) (*Compactor, error) {
 	if checkpointDistance < 1 {
 		checkpointDistance = 1
@@ -102,7 +147,10 @@ func NewCompactor(
 	// Create trieQueue with initial values from ledger state.
 	trieQueue := realWAL.NewTrieQueueWithValues(checkpointCapacity, tries)
 
-	return &Compactor{
+	// Tries in trieQueue are retained until they are evicted from trieQueue.
+	l.forest.RetainTries(trieQueue.Tries()...)
+
+	c := &Compactor{
 		checkpointer:                         checkpointer,
 		wal:                                  w,
 		trieQueue:                            trieQueue,
@@ -114,7 +162,15 @@ func NewCompactor(
 		checkpointDistance:                   checkpointDistance,
 		checkpointsToKeep:                    checkpointsToKeep,
 		triggerCheckpointOnNextSegmentFinish: triggerCheckpointOnNextSegmentFinish,
-	}, nil
+		forest:                               l.forest,
+		payloadGenerations:                   make(map[int]uint64),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
 }
 
 // Subscribe subscribes observer to Compactor.
@@ -255,10 +311,13 @@ Loop:
 
 				go func() {
 					defer checkpointSem.Release(1)
+					defer c.releaseTries(checkpointTries...)
 					err := c.checkpoint(ctx, checkpointTries, checkpointNum)
 					checkpointResultCh <- checkpointResult{checkpointNum, err}
 				}()
 			} else {
+				c.releaseTries(checkpointTries...)
+
 				// Failed to get semaphore because checkpointing is running.
 				// Try again when active segment is finalized.
 				c.logger.Info().Msgf("compactor delayed checkpoint %d because prior checkpointing is ongoing", nextCheckpointNum)
@@ -283,16 +342,54 @@ Loop:
 
 // checkpoint creates checkpoint of tries snapshot,
 // deletes prior checkpoint files (if needed), and notifies observers.
-// Errors indicate that checkpoint file can't be created or prior checkpoints can't be removed.
+// Errors indicate that checkpoint file can't be created, prior checkpoints can't be removed,
+// or checkpoints and segments can't be archived.
 // Caller should handle returned errors by retrying checkpointing when appropriate.
+// Files which failed to be archived are archived again by the next checkpointing.
 // Since this function is only for checkpointing, Compactor isn't affected by returned error.
 func (c *Compactor) checkpoint(ctx context.Context, tries []*trie.MTrie, checkpointNum int) error {
 
-	err := createCheckpoint(c.checkpointer, c.logger, tries, checkpointNum)
-	if err != nil {
-		return &createCheckpointError{num: checkpointNum, err: err}
+	// Payloads released before the next generation aren't referenced by the tries,
+	// which are retained since they were taken from the checkpointing queue.
+	var payloadGeneration uint64
+	store := c.forest.PayloadStore()
+	if store != nil {
+		payloadGeneration = store.NextGeneration()
 	}
 
+	// archived checkpoints must not depend on the payload store
+	payloadReferences := store != nil && c.archiver == nil
+
+	base := c.base
+	if base != nil && base.deltas < c.deltaCheckpoints {
+		err := createCheckpointDelta(c.checkpointer, c.logger, tries, checkpointNum, base, payloadReferences)
+		if err != nil {
+			// a full checkpoint doesn't depend on the base checkpoint
+			c.logger.Warn().Err(err).Msgf("failed to create incremental checkpoint %d, creating full checkpoint", checkpointNum)
+			base = nil
+		} else {
+			c.setBase(&checkpointBase{num: checkpointNum, tries: tries, deltas: base.deltas + 1})
+		}
+	} else {
+		base = nil
+	}
+
+	if base == nil {
+		err := createCheckpoint(c.checkpointer, c.logger, tries, checkpointNum, payloadReferences)
+		if err != nil {
+			return &createCheckpointError{num: checkpointNum, err: err}
+		}
+
+		// tries are only kept if they can be the base of an incremental checkpoint
+		var newBase *checkpointBase
+		if c.deltaCheckpoints > 0 {
+			newBase = &checkpointBase{num: checkpointNum, tries: tries}
+		}
+		c.setBase(newBase)
+	}
+
+	c.payloadGenerations[checkpointNum] = payloadGeneration
+
 	// Return if context is canceled.
 	select {
 	case <-ctx.Done():
@@ -300,11 +397,30 @@ func (c *Compactor) checkpoint(ctx context.Context, tries []*trie.MTrie, checkpo
 	default:
 	}
 
-	err = cleanupCheckpoints(c.checkpointer, int(c.checkpointsToKeep))
+	if c.archiver != nil {
+		err := c.archiver.ArchiveCheckpoint(checkpointNum)
+		if err != nil {
+			return &archiveError{err: err}
+		}
+	}
+
+	err := cleanupCheckpoints(c.checkpointer, int(c.checkpointsToKeep), c.archiver)
 	if err != nil {
 		return &removeCheckpointError{err: err}
 	}
 
+	err = c.removeReleasedPayloads()
+	if err != nil {
+		return fmt.Errorf("cannot remove released payloads: %w", err)
+	}
+
+	if c.archiver != nil {
+		err := archiveSegments(c.checkpointer, c.archiver)
+		if err != nil {
+			return &archiveError{err: err}
+		}
+	}
+
 	if checkpointNum > 0 {
 		for observer := range c.observers {
 			// Don't notify observer if context is canceled.
@@ -322,28 +438,70 @@ func (c *Compactor) checkpoint(ctx context.Context, tries []*trie.MTrie, checkpo
 	return nil
 }
 
+// setBase replaces the base of the next incremental checkpoint, retaining
+// the tries of the new base and releasing the tries of the previous base.
+func (c *Compactor) setBase(base *checkpointBase) {
+	if base != nil {
+		c.forest.RetainTries(base.tries...)
+	}
+	if c.base != nil {
+		c.releaseTries(c.base.tries...)
+	}
+	c.base = base
+}
+
+// releaseTries releases the given tries retained by Compactor, see mtrie.Forest.ReleaseTries.
+func (c *Compactor) releaseTries(tries ...*trie.MTrie) {
+	err := c.forest.ReleaseTries(tries...)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("compactor failed to release tries")
+	}
+}
+
+// removeReleasedPayloads removes the payloads released before the oldest kept checkpoint was
+// created from the payload store, as no kept checkpoint references them. Payloads released
+// before a checkpoint created by a previous process are kept until the checkpoint is removed.
+func (c *Compactor) removeReleasedPayloads() error {
+	store := c.forest.PayloadStore()
+	if store == nil {
+		return nil
+	}
+
+	checkpoints, err := c.checkpointer.Checkpoints()
+	if err != nil {
+		return fmt.Errorf("cannot list checkpoints: %w", err)
+	}
+	if len(checkpoints) == 0 {
+		return nil
+	}
+
+	oldest := checkpoints[0]
+	for num := range c.payloadGenerations {
+		if num < oldest {
+			delete(c.payloadGenerations, num)
+		}
+	}
+
+	return store.RemoveReleased(c.payloadGenerations[oldest])
+}
+
 // createCheckpoint creates checkpoint with given checkpointNum and tries.
 // Errors indicate that checkpoint file can't be created.
 // Caller should handle returned errors by retrying checkpointing when appropriate.
-func createCheckpoint(checkpointer *realWAL.Checkpointer, logger zerolog.Logger, tries []*trie.MTrie, checkpointNum int) error {
+// If payloadReferences is true, payloads kept in a payload store (see mtrie.WithPayloadStore)
+// are referenced by their key instead of being copied to the checkpoint.
+func createCheckpoint(checkpointer *realWAL.Checkpointer, logger zerolog.Logger, tries []*trie.MTrie, checkpointNum int, payloadReferences bool) error {
 
 	logger.Info().Msgf("serializing checkpoint %d with %v tries", checkpointNum, len(tries))
 
 	startTime := time.Now()
 
-	writer, err := checkpointer.CheckpointWriter(checkpointNum)
-	if err != nil {
-		return fmt.Errorf("cannot generate checkpoint writer: %w", err)
+	storeCheckpoint := realWAL.StoreCheckpointV6
+	if payloadReferences {
+		storeCheckpoint = realWAL.StoreCheckpointV6WithPayloadReferences
 	}
-	defer func() {
-		closeErr := writer.Close()
-		// Return close error if there isn't any prior error to return.
-		if err == nil {
-			err = closeErr
-		}
-	}()
 
-	err = realWAL.StoreCheckpoint(writer, tries...)
+	err := storeCheckpoint(tries, checkpointer.Dir(), realWAL.NumberToFilename(checkpointNum), &logger)
 	if err != nil {
 		return fmt.Errorf("error serializing checkpoint (%d): %w", checkpointNum, err)
 	}
@@ -354,9 +512,37 @@ func createCheckpoint(checkpointer *realWAL.Checkpointer, logger zerolog.Logger,
 	return nil
 }
 
+// createCheckpointDelta creates an incremental checkpoint with given checkpointNum and tries,
+// based on the given checkpoint.
+// Errors indicate that checkpoint file can't be created.
+// Payloads are referenced by their key if payloadReferences is true, see createCheckpoint.
+func createCheckpointDelta(checkpointer *realWAL.Checkpointer, logger zerolog.Logger, tries []*trie.MTrie, checkpointNum int, base *checkpointBase, payloadReferences bool) error {
+
+	logger.Info().Msgf("serializing incremental checkpoint %d with %v tries, based on checkpoint %d", checkpointNum, len(tries), base.num)
+
+	startTime := time.Now()
+
+	storeCheckpointDelta := realWAL.StoreCheckpointDelta
+	if payloadReferences {
+		storeCheckpointDelta = realWAL.StoreCheckpointDeltaWithPayloadReferences
+	}
+
+	err := storeCheckpointDelta(tries, base.tries, realWAL.NumberToFilename(base.num), checkpointer.Dir(), realWAL.NumberToFilename(checkpointNum), &logger)
+	if err != nil {
+		return fmt.Errorf("error serializing incremental checkpoint (%d): %w", checkpointNum, err)
+	}
+
+	duration := time.Since(startTime)
+	logger.Info().Float64("total_time_s", duration.Seconds()).Msgf("created incremental checkpoint %d", checkpointNum)
+
+	return nil
+}
+
 // cleanupCheckpoints deletes prior checkpoint files if needed.
+// Checkpoints which are needed to load the kept incremental checkpoints are never deleted.
+// If archiver isn't nil, checkpoints are archived before being deleted.
 // Since the function is side-effect free, all failures are simply a no-op.
-func cleanupCheckpoints(checkpointer *realWAL.Checkpointer, checkpointsToKeep int) error {
+func cleanupCheckpoints(checkpointer *realWAL.Checkpointer, checkpointsToKeep int, archiver *realWAL.Archiver) error {
 	// Don't list checkpoints if we keep them all
 	if checkpointsToKeep == 0 {
 		return nil
@@ -369,7 +555,21 @@ func cleanupCheckpoints(checkpointer *realWAL.Checkpointer, checkpointsToKeep in
 		// if condition guarantees this never fails
 		checkpointsToRemove := checkpoints[:len(checkpoints)-int(checkpointsToKeep)]
 
+		bases, err := checkpointBases(checkpointer, checkpoints[len(checkpoints)-int(checkpointsToKeep):])
+		if err != nil {
+			return fmt.Errorf("cannot get base checkpoints: %w", err)
+		}
+
 		for _, checkpoint := range checkpointsToRemove {
+			if _, ok := bases[checkpoint]; ok {
+				continue
+			}
+			if archiver != nil {
+				err := archiver.ArchiveCheckpoint(checkpoint)
+				if err != nil {
+					return fmt.Errorf("cannot archive checkpoint %d before removing it: %w", checkpoint, err)
+				}
+			}
 			err := checkpointer.RemoveCheckpoint(checkpoint)
 			if err != nil {
 				return fmt.Errorf("cannot remove checkpoint %d: %w", checkpoint, err)
@@ -379,6 +579,42 @@ func cleanupCheckpoints(checkpointer *realWAL.Checkpointer, checkpointsToKeep in
 	return nil
 }
 
+// archiveSegments archives and removes the segments included in the oldest checkpoint,
+// which are no longer needed to load any of the checkpoints.
+func archiveSegments(checkpointer *realWAL.Checkpointer, archiver *realWAL.Archiver) error {
+	checkpoints, err := checkpointer.Checkpoints()
+	if err != nil {
+		return fmt.Errorf("cannot list checkpoints: %w", err)
+	}
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	return archiver.ArchiveSegments(checkpoints[0])
+}
+
+// checkpointBases returns the checkpoints which are needed to load the given checkpoints,
+// i.e. the base checkpoints of incremental checkpoints.
+func checkpointBases(checkpointer *realWAL.Checkpointer, checkpoints []int) (map[int]struct{}, error) {
+	bases := make(map[int]struct{})
+	for _, checkpoint := range checkpoints {
+		for {
+			base, err := checkpointer.CheckpointBase(checkpoint)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get base of checkpoint %d: %w", checkpoint, err)
+			}
+			if base == -1 {
+				break
+			}
+			if _, ok := bases[base]; ok {
+				break
+			}
+			bases[base] = struct{}{}
+			checkpoint = base
+		}
+	}
+	return bases, nil
+}
+
 // processTrieUpdate writes trie update to WAL, updates activeSegmentNum,
 // and returns tries for checkpointing if needed.
 // It sends WAL update result, receives updated trie, and pushes updated trie to trieQueue.
@@ -412,7 +648,11 @@ func (c *Compactor) processTrieUpdate(
 			return
 		}
 
-		trieQueue.Push(trie)
+		// the trie is retained by Ledger for trieQueue
+		evicted := trieQueue.Push(trie)
+		if evicted != nil {
+			c.releaseTries(evicted)
+		}
 	}()
 
 	if activeSegmentNum == -1 {
@@ -456,6 +696,10 @@ func (c *Compactor) processTrieUpdate(
 	// until updated trie is received and added to trieQueue.
 	tries := trieQueue.Tries()
 
+	// Tries are retained for checkpointing, as they can be evicted from trieQueue meanwhile.
+	// The caller releases them once checkpointing is done.
+	c.forest.RetainTries(tries...)
+
 	checkpointNum = nextCheckpointNum
 
 	return activeSegmentNum, checkpointNum, tries
@@ -473,6 +717,17 @@ func (e *createCheckpointError) Error() string {
 
 func (e *createCheckpointError) Unwrap() error { return e.err }
 
+// archiveError is an error archiving checkpoints or segments.
+type archiveError struct {
+	err error
+}
+
+func (e *archiveError) Error() string {
+	return fmt.Sprintf("cannot archive WAL files: %s", e.err)
+}
+
+func (e *archiveError) Unwrap() error { return e.err }
+
 // removeCheckpointError creates a checkpoint removal error.
 type removeCheckpointError struct {
 	err error
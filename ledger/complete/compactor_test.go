@@ -252,6 +252,267 @@ func TestCompactor(t *testing.T) {
 	})
 }
 
+// TestCompactorIncrementalCheckpoints tests creation of incremental checkpoints,
+// and checks if the ledger state rebuilt from them matches previous ledger state.
+func TestCompactorIncrementalCheckpoints(t *testing.T) {
+	const (
+		numInsPerStep      = 2
+		pathByteSize       = 32
+		minPayloadByteSize = 2 << 15
+		maxPayloadByteSize = 2 << 16
+		size               = 10
+		checkpointDistance = 3
+		checkpointsToKeep  = 1
+		deltaCheckpoints   = 2
+		forestCapacity     = size * 10
+	)
+
+	metricsCollector := &metrics.NoopCollector{}
+
+	unittest.RunWithTempDir(t, func(dir string) {
+
+		wal, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, forestCapacity, pathByteSize, 32*1024)
+		require.NoError(t, err)
+
+		l, err := NewLedger(wal, size*10, metricsCollector, zerolog.Logger{}, DefaultPathFinderVersion)
+		require.NoError(t, err)
+
+		compactor, err := NewCompactor(l, wal, zerolog.Nop(), forestCapacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false),
+			WithIncrementalCheckpoints(deltaCheckpoints))
+		require.NoError(t, err)
+
+		co := CompactorObserver{fromBound: 8, done: make(chan struct{})}
+		compactor.Subscribe(&co)
+
+		// Run Compactor in background.
+		<-compactor.Ready()
+
+		rootState := l.InitialState()
+		states := make([]ledger.State, 0, size)
+		queries := make([]*ledger.Query, 0, size)
+
+		for i := 0; i < size; i++ {
+			payloads := testutils.RandomPayloads(numInsPerStep, minPayloadByteSize, maxPayloadByteSize)
+
+			keys := make([]ledger.Key, len(payloads))
+			values := make([]ledger.Value, len(payloads))
+			for i, p := range payloads {
+				k, err := p.Key()
+				require.NoError(t, err)
+				keys[i] = k
+				values[i] = p.Value()
+			}
+
+			update, err := ledger.NewUpdate(rootState, keys, values)
+			require.NoError(t, err)
+
+			rootState, _, err = l.Set(update)
+			require.NoError(t, err)
+
+			query, err := ledger.NewQuery(rootState, keys)
+			require.NoError(t, err)
+
+			states = append(states, rootState)
+			queries = append(queries, query)
+		}
+
+		select {
+		case <-co.done:
+		case <-time.After(60 * time.Second):
+			assert.FailNow(t, "timed out")
+		}
+
+		<-l.Done()
+		<-compactor.Done()
+
+		checkpointer, err := wal.NewCheckpointer()
+		require.NoError(t, err)
+
+		latest, err := checkpointer.LatestCheckpoint()
+		require.NoError(t, err)
+
+		// the latest checkpoint is incremental, and its bases are kept
+		checkpoints := []int{latest}
+		for {
+			base, err := checkpointer.CheckpointBase(checkpoints[len(checkpoints)-1])
+			require.NoError(t, err)
+			if base == -1 {
+				break
+			}
+			checkpoints = append(checkpoints, base)
+		}
+		require.Greater(t, len(checkpoints), 1)
+		require.LessOrEqual(t, len(checkpoints), deltaCheckpoints+1)
+
+		kept, err := checkpointer.Checkpoints()
+		require.NoError(t, err)
+		require.ElementsMatch(t, checkpoints, kept)
+
+		// rebuild ledger from checkpoints and WAL
+		wal2, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, forestCapacity, pathByteSize, 32*1024)
+		require.NoError(t, err)
+
+		l2, err := NewLedger(wal2, size*10, metricsCollector, zerolog.Logger{}, DefaultPathFinderVersion)
+		require.NoError(t, err)
+
+		for i, query := range queries {
+			require.True(t, l2.HasState(states[i]))
+
+			values, err := l.Get(query)
+			require.NoError(t, err)
+
+			values2, err := l2.Get(query)
+			require.NoError(t, err)
+			require.Equal(t, values, values2)
+		}
+
+		<-wal2.Done()
+	})
+}
+
+// TestCompactorArchive tests that checkpoints and checkpointed segments are archived,
+// and that the ledger state can be rebuilt from the archive and the remaining segments.
+func TestCompactorArchive(t *testing.T) {
+	const (
+		numInsPerStep      = 2
+		pathByteSize       = 32
+		minPayloadByteSize = 2 << 15
+		maxPayloadByteSize = 2 << 16
+		size               = 10
+		checkpointDistance = 3
+		checkpointsToKeep  = 1
+		forestCapacity     = size * 10
+	)
+
+	metricsCollector := &metrics.NoopCollector{}
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		unittest.RunWithTempDir(t, func(archiveDir string) {
+			unittest.RunWithTempDir(t, func(restoreDir string) {
+
+				wal, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, forestCapacity, pathByteSize, 32*1024, realWAL.WithCompression())
+				require.NoError(t, err)
+
+				l, err := NewLedger(wal, size*10, metricsCollector, zerolog.Logger{}, DefaultPathFinderVersion)
+				require.NoError(t, err)
+
+				store, err := realWAL.NewDirArchiveStore(archiveDir)
+				require.NoError(t, err)
+
+				archiver, err := realWAL.NewArchiver(dir, store, zerolog.Nop())
+				require.NoError(t, err)
+
+				compactor, err := NewCompactor(l, wal, zerolog.Nop(), forestCapacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false),
+					WithArchiver(archiver))
+				require.NoError(t, err)
+
+				co := CompactorObserver{fromBound: 8, done: make(chan struct{})}
+				compactor.Subscribe(&co)
+
+				// Run Compactor in background.
+				<-compactor.Ready()
+
+				rootState := l.InitialState()
+				states := make([]ledger.State, 0, size)
+				queries := make([]*ledger.Query, 0, size)
+
+				for i := 0; i < size; i++ {
+					payloads := testutils.RandomPayloads(numInsPerStep, minPayloadByteSize, maxPayloadByteSize)
+
+					keys := make([]ledger.Key, len(payloads))
+					values := make([]ledger.Value, len(payloads))
+					for i, p := range payloads {
+						k, err := p.Key()
+						require.NoError(t, err)
+						keys[i] = k
+						values[i] = p.Value()
+					}
+
+					update, err := ledger.NewUpdate(rootState, keys, values)
+					require.NoError(t, err)
+
+					rootState, _, err = l.Set(update)
+					require.NoError(t, err)
+
+					query, err := ledger.NewQuery(rootState, keys)
+					require.NoError(t, err)
+
+					states = append(states, rootState)
+					queries = append(queries, query)
+				}
+
+				select {
+				case <-co.done:
+				case <-time.After(60 * time.Second):
+					assert.FailNow(t, "timed out")
+				}
+
+				<-l.Done()
+				<-compactor.Done()
+
+				checkpointer, err := wal.NewCheckpointer()
+				require.NoError(t, err)
+
+				kept, err := checkpointer.Checkpoints()
+				require.NoError(t, err)
+				require.Len(t, kept, checkpointsToKeep)
+
+				// segments included in the kept checkpoint have been archived and removed
+				first, last, err := wal.Segments()
+				require.NoError(t, err)
+				require.Equal(t, kept[0]+1, first)
+
+				manifest := archiver.Manifest()
+				require.Len(t, manifest.Segments, first)
+				require.Equal(t, kept[0], manifest.Checkpoints[len(manifest.Checkpoints)-1].Number)
+				require.Greater(t, len(manifest.Checkpoints), 1)
+
+				// restore the archived files needed to load the last archived segment,
+				// followed by the remaining segments
+				checkpoints, segments, err := manifest.RestorePlan(first - 1)
+				require.NoError(t, err)
+				require.Len(t, checkpoints, 1)
+
+				copyFile := func(from string, to string) {
+					data, err := ioutil.ReadFile(from)
+					require.NoError(t, err)
+					err = ioutil.WriteFile(to, data, 0644)
+					require.NoError(t, err)
+				}
+				for _, file := range checkpoints[0].Files {
+					copyFile(path.Join(archiveDir, file.Name), path.Join(restoreDir, path.Base(file.Name)))
+				}
+				for _, segment := range segments {
+					copyFile(path.Join(archiveDir, segment.File.Name), path.Join(restoreDir, path.Base(segment.File.Name)))
+				}
+				for segment := first; segment <= last; segment++ {
+					name := realWAL.NumberToFilenamePart(segment)
+					copyFile(path.Join(dir, name), path.Join(restoreDir, name))
+				}
+
+				wal2, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), restoreDir, forestCapacity, pathByteSize, 32*1024)
+				require.NoError(t, err)
+
+				l2, err := NewLedger(wal2, size*10, metricsCollector, zerolog.Logger{}, DefaultPathFinderVersion)
+				require.NoError(t, err)
+
+				for i, query := range queries {
+					require.True(t, l2.HasState(states[i]))
+
+					values, err := l.Get(query)
+					require.NoError(t, err)
+
+					values2, err := l2.Get(query)
+					require.NoError(t, err)
+					require.Equal(t, values, values2)
+				}
+
+				<-wal2.Done()
+			})
+		})
+	})
+}
+
 // TestCompactorSkipCheckpointing tests that only one
 // checkpointing is running at a time.
 func TestCompactorSkipCheckpointing(t *testing.T) {
@@ -1,6 +1,7 @@
 package complete
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -43,16 +44,20 @@ type Ledger struct {
 }
 
 // NewLedger creates a new in-memory trie-backed ledger storage with persistence.
+// Forest options are applied to the forest holding the tries, for example
+// mtrie.WithPayloadStore to keep payloads on disk instead of memory.
 func NewLedger(
 	wal realWAL.LedgerWAL,
 	capacity int,
 	metrics module.LedgerMetrics,
 	log zerolog.Logger,
-	pathFinderVer uint8) (*Ledger, error) {
+	pathFinderVer uint8,
+	opts ...mtrie.ForestOption,
+) (*Ledger, error) {
 
 	logger := log.With().Str("ledger", "complete").Logger()
 
-	forest, err := mtrie.NewForest(capacity, metrics, nil)
+	forest, err := mtrie.NewForest(capacity, metrics, nil, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create forest: %w", err)
 	}
@@ -75,6 +80,15 @@ func NewLedger(
 		return nil, fmt.Errorf("cannot restore LedgerWAL: %w", err)
 	}
 
+	if store := forest.PayloadStore(); store != nil {
+		// payloads of the previous process which aren't referenced by the restored tries
+		// are removed by Compactor once the checkpoints referencing them are removed.
+		err = store.ReleaseUnreferenced()
+		if err != nil {
+			return nil, fmt.Errorf("cannot release unreferenced payloads: %w", err)
+		}
+	}
+
 	wal.UnpauseRecord()
 
 	// TODO update to proper value once https://github.com/onflow/flow-go/pull/3720 is merged
@@ -266,8 +280,17 @@ func (l *Ledger) set(trieUpdate *ledger.TrieUpdate) (newState ledger.State, err
 		return ledger.State(hash.DummyHash), fmt.Errorf("error while writing LedgerWAL: %w", walError)
 	}
 
+	// The trie is retained for the checkpointing queue of Compactor, which releases it
+	// once it is evicted from the queue. It is retained before being added to the forest,
+	// as the payloads of a trie already in the forest would be released otherwise.
+	l.forest.RetainTries(newTrie)
+
 	err = l.forest.AddTrie(newTrie)
 	if err != nil {
+		releaseErr := l.forest.ReleaseTries(newTrie)
+		if releaseErr != nil {
+			l.logger.Error().Err(releaseErr).Msg("failed to release trie which couldn't be added to forest")
+		}
 		return ledger.State(hash.DummyHash), fmt.Errorf("failed to add new trie to forest: %w", err)
 	}
 
@@ -371,7 +394,10 @@ func (l *Ledger) ExportCheckpointAt(
 	// l.logger.Info().Msg("Trie is valid.")
 
 	// get all payloads
-	payloads := t.AllPayloads()
+	payloads, err := t.AllPayloads()
+	if err != nil {
+		return ledger.State(hash.DummyHash), fmt.Errorf("cannot get payloads of trie: %w", err)
+	}
 	payloadSize := len(payloads)
 
 	// migrate payloads
@@ -408,7 +434,7 @@ func (l *Ledger) ExportCheckpointAt(
 		return ledger.State(hash.DummyHash), fmt.Errorf("cannot export checkpoint, can't construct paths: %w", err)
 	}
 
-	emptyTrie := trie.NewEmptyMTrie()
+	emptyTrie := trie.NewEmptyMTrieWithHasher(l.forest.Hasher())
 
 	// no need to prune the data since it has already been prunned through migrations
 	applyPruning := false
@@ -433,15 +459,10 @@ func (l *Ledger) ExportCheckpointAt(
 
 	l.logger.Info().Msgf("finished running pre-checkpoint reporters")
 
-	l.logger.Info().Msg("creating a checkpoint for the new trie")
-	writer, err := realWAL.CreateCheckpointWriterForFile(outputDir, outputFile, &l.logger)
-	if err != nil {
-		return ledger.State(hash.DummyHash), fmt.Errorf("failed to create a checkpoint writer: %w", err)
-	}
-
-	l.logger.Info().Msg("storing the checkpoint to the file")
+	l.logger.Info().Msg("creating a checkpoint for the new trie, storing the checkpoint to the files")
 
-	err = realWAL.StoreCheckpoint(writer, newTrie)
+	// the checkpoint records the hasher of the trie if it isn't the default one (version 8)
+	err = realWAL.StoreCheckpointV6([]*trie.MTrie{newTrie}, outputDir, outputFile, &l.logger)
 
 	// Writing the checkpoint takes time to write and copy.
 	// Without relying on an exit code or stdout, we need to know when the copy is complete.
@@ -453,7 +474,6 @@ func (l *Ledger) ExportCheckpointAt(
 	if err != nil {
 		return ledger.State(hash.DummyHash), fmt.Errorf("failed to store the checkpoint: %w", err)
 	}
-	writer.Close()
 
 	l.logger.Info().Msgf("checkpoint file successfully stored at: %v %v", outputDir, outputFile)
 
@@ -484,6 +504,62 @@ func (l *Ledger) HasState(state ledger.State) bool {
 	return l.forest.HasTrie(ledger.RootHash(state))
 }
 
+// Diff returns the payloads which differ between the given states, ordered by path.
+// Both states must be in memory.
+func (l *Ledger) Diff(from ledger.State, to ledger.State) ([]ledger.PayloadDiff, error) {
+	diffs, err := l.forest.Diff(ledger.RootHash(from), ledger.RootHash(to))
+	if err != nil {
+		return nil, fmt.Errorf("cannot diff states: %w", err)
+	}
+	return diffs, nil
+}
+
+// PayloadsWithPrefix calls fn for each non-empty payload stored at the given state
+// whose path starts with the given prefix, ordered by path.
+// Prefixes of the registers of an owner are provided by pathfinder.OwnerPathPrefix.
+func (l *Ledger) PayloadsWithPrefix(state ledger.State, prefix []byte, fn func(path ledger.Path, payload *ledger.Payload) error) error {
+	err := l.forest.PayloadsWithPrefix(ledger.RootHash(state), prefix, fn)
+	if err != nil {
+		return fmt.Errorf("cannot iterate payloads: %w", err)
+	}
+	return nil
+}
+
+// OwnerPayloads calls fn for each non-empty payload of the registers of the given owner
+// stored at the given state, ordered by path. Only the subtrie of the owner is traversed.
+// The registers of an owner only share a path prefix with key to path version two, an error
+// is returned for ledgers using older versions. Switching a network to version two changes
+// all state commitments, so its states have to be migrated in a spork first (see ExportCheckpointAt).
+func (l *Ledger) OwnerPayloads(state ledger.State, owner []byte, fn func(path ledger.Path, payload *ledger.Payload) error) error {
+	prefix, err := pathfinder.OwnerPathPrefix(owner, l.pathFinderVersion)
+	if err != nil {
+		return fmt.Errorf("cannot get path prefix of owner: %w", err)
+	}
+
+	return l.PayloadsWithPrefix(state, prefix, func(path ledger.Path, payload *ledger.Payload) error {
+		key, err := payload.Key()
+		if err != nil {
+			return fmt.Errorf("cannot decode key of payload: %w", err)
+		}
+		// owner prefixes are hash prefixes, so registers of other owners might share them
+		if len(key.KeyParts) == 0 || !bytes.Equal(key.KeyParts[0].Value, owner) {
+			return nil
+		}
+		return fn(path, payload)
+	})
+}
+
+// ProveRange returns an encoded proof that the payloads stored at the given state
+// under the given prefix are complete, which is verified by proof.VerifyTrieRangeProof.
+func (l *Ledger) ProveRange(state ledger.State, prefix []byte) (ledger.Proof, error) {
+	rangeProof, err := l.forest.RangeProof(ledger.RootHash(state), prefix)
+	if err != nil {
+		return nil, fmt.Errorf("could not get range proof: %w", err)
+	}
+
+	return ledger.EncodeTrieRangeProof(rangeProof), nil
+}
+
 // DumpTrieAsJSON export trie at specific state as JSONL (each line is JSON encoding of a payload)
 func (l *Ledger) DumpTrieAsJSON(state ledger.State, writer io.Writer) error {
 	fmt.Println(ledger.RootHash(state))
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"path"
 	"testing"
 	"time"
 
@@ -15,10 +16,12 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/testutils"
 	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
@@ -705,6 +708,63 @@ func TestLedgerFunctionality(t *testing.T) {
 	}
 }
 
+func TestLedger_OwnerPayloads(t *testing.T) {
+	owner1 := []byte("owner1")
+	owner2 := []byte("owner2")
+
+	keys := []ledger.Key{
+		ledger.NewKey([]ledger.KeyPart{ledger.NewKeyPart(0, owner1), ledger.NewKeyPart(2, []byte("a"))}),
+		ledger.NewKey([]ledger.KeyPart{ledger.NewKeyPart(0, owner2), ledger.NewKeyPart(2, []byte("a"))}),
+		ledger.NewKey([]ledger.KeyPart{ledger.NewKeyPart(0, owner1), ledger.NewKeyPart(2, []byte("b"))}),
+	}
+	values := []ledger.Value{[]byte{'A'}, []byte{'B'}, []byte{'C'}}
+
+	t.Run("registers of the owner", func(t *testing.T) {
+		led, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Logger{}, 2)
+		require.NoError(t, err)
+
+		compactor := fixtures.NewNoopCompactor(led)
+		<-compactor.Ready()
+		defer func() {
+			<-led.Done()
+			<-compactor.Done()
+		}()
+
+		u, err := ledger.NewUpdate(led.InitialState(), keys, values)
+		require.NoError(t, err)
+
+		state, _, err := led.Set(u)
+		require.NoError(t, err)
+
+		ownerValues := make(map[string]ledger.Value)
+		err = led.OwnerPayloads(state, owner1, func(_ ledger.Path, payload *ledger.Payload) error {
+			key, err := payload.Key()
+			require.NoError(t, err)
+			ownerValues[string(key.KeyParts[1].Value)] = payload.Value()
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]ledger.Value{"a": values[0], "b": values[2]}, ownerValues)
+	})
+
+	t.Run("path finder version without owner prefixes", func(t *testing.T) {
+		led, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Logger{}, 1)
+		require.NoError(t, err)
+
+		compactor := fixtures.NewNoopCompactor(led)
+		<-compactor.Ready()
+		defer func() {
+			<-led.Done()
+			<-compactor.Done()
+		}()
+
+		err = led.OwnerPayloads(led.InitialState(), owner1, func(ledger.Path, *ledger.Payload) error {
+			return nil
+		})
+		require.Error(t, err)
+	})
+}
+
 func Test_ExportCheckpointAt(t *testing.T) {
 	t.Run("noop migration", func(t *testing.T) {
 		// the exported state has two key/value pairs
@@ -870,6 +930,135 @@ func Test_ExportCheckpointAt(t *testing.T) {
 				assert.Equal(t, retValues[0], ledger.Value([]byte{'D'}))
 				assert.Equal(t, retValues[1], ledger.Value([]byte{'B'}))
 
+				<-led.Done()
+				<-compactor.Done()
+				<-led2.Done()
+				<-compactor2.Done()
+			})
+		})
+	})
+	t.Run("path finder version migration", func(t *testing.T) {
+		// the state is exported with key to path version two, which moves the registers to
+		// the paths of their owners, so the exported state has another state commitment
+		unittest.RunWithTempDir(t, func(dbDir string) {
+			unittest.RunWithTempDir(t, func(dir2 string) {
+
+				const (
+					capacity           = 100
+					checkpointDistance = math.MaxInt // A large number to prevent checkpoint creation.
+					checkpointsToKeep  = 1
+				)
+
+				diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dbDir, capacity, pathfinder.PathByteSize, wal.SegmentSize)
+				require.NoError(t, err)
+				led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, 1)
+				require.NoError(t, err)
+				compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
+				require.NoError(t, err)
+				<-compactor.Ready()
+
+				state := led.InitialState()
+				u := testutils.UpdateFixture()
+				u.SetState(state)
+
+				state, _, err = led.Set(u)
+				require.NoError(t, err)
+
+				newState, err := led.ExportCheckpointAt(state, []ledger.Migration{noOpMigration}, []ledger.Reporter{}, []ledger.Reporter{}, 2, dir2, "root.checkpoint")
+				require.NoError(t, err)
+				assert.NotEqual(t, newState, state)
+
+				diskWal2, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir2, capacity, pathfinder.PathByteSize, wal.SegmentSize)
+				require.NoError(t, err)
+				led2, err := complete.NewLedger(diskWal2, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, 2)
+				require.NoError(t, err)
+				compactor2, err := complete.NewCompactor(led2, diskWal2, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
+				require.NoError(t, err)
+				<-compactor2.Ready()
+
+				q, err := ledger.NewQuery(newState, u.Keys())
+				require.NoError(t, err)
+
+				retValues, err := led2.Get(q)
+				require.NoError(t, err)
+
+				for i, v := range u.Values() {
+					assert.Equal(t, v, retValues[i])
+				}
+
+				// the registers of an owner can be iterated once migrated
+				owner := u.Keys()[0].KeyParts[0].Value
+				count := 0
+				err = led2.OwnerPayloads(newState, owner, func(ledger.Path, *ledger.Payload) error {
+					count++
+					return nil
+				})
+				require.NoError(t, err)
+				assert.NotZero(t, count)
+
+				<-led.Done()
+				<-compactor.Done()
+				<-led2.Done()
+				<-compactor2.Done()
+			})
+		})
+	})
+	t.Run("non-default hasher", func(t *testing.T) {
+		// the exported checkpoint records the hasher of the trie,
+		// so that it can be loaded by a ledger using the same hasher
+		unittest.RunWithTempDir(t, func(dbDir string) {
+			unittest.RunWithTempDir(t, func(dir2 string) {
+
+				const (
+					capacity           = 100
+					checkpointDistance = math.MaxInt // A large number to prevent checkpoint creation.
+					checkpointsToKeep  = 1
+				)
+
+				hasher, err := ledger.NewTrieHasher(hash.BLAKE3)
+				require.NoError(t, err)
+
+				diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dbDir, capacity, pathfinder.PathByteSize, wal.SegmentSize)
+				require.NoError(t, err)
+				led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion, mtrie.WithHasher(hasher))
+				require.NoError(t, err)
+				compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
+				require.NoError(t, err)
+				<-compactor.Ready()
+
+				state := led.InitialState()
+				u := testutils.UpdateFixture()
+				u.SetState(state)
+
+				state, _, err = led.Set(u)
+				require.NoError(t, err)
+
+				newState, err := led.ExportCheckpointAt(state, []ledger.Migration{noOpMigration}, []ledger.Reporter{}, []ledger.Reporter{}, complete.DefaultPathFinderVersion, dir2, "root.checkpoint")
+				require.NoError(t, err)
+				assert.Equal(t, newState, state)
+
+				version, _, err := wal.CheckpointFiles(path.Join(dir2, "root.checkpoint"))
+				require.NoError(t, err)
+				require.Equal(t, wal.VersionV8, version)
+
+				diskWal2, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir2, capacity, pathfinder.PathByteSize, wal.SegmentSize)
+				require.NoError(t, err)
+				led2, err := complete.NewLedger(diskWal2, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion, mtrie.WithHasher(hasher))
+				require.NoError(t, err)
+				compactor2, err := complete.NewCompactor(led2, diskWal2, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
+				require.NoError(t, err)
+				<-compactor2.Ready()
+
+				q, err := ledger.NewQuery(state, u.Keys())
+				require.NoError(t, err)
+
+				retValues, err := led2.Get(q)
+				require.NoError(t, err)
+
+				for i, v := range u.Values() {
+					assert.Equal(t, v, retValues[i])
+				}
+
 				<-led.Done()
 				<-compactor.Done()
 				<-led2.Done()
@@ -16,6 +16,7 @@ type nodeType byte
 const (
 	leafNodeType nodeType = iota
 	interimNodeType
+	storedLeafNodeType // leaf node referencing its payload in a payload store
 )
 
 const (
@@ -31,7 +32,9 @@ const (
 	encodedTrieSize = encNodeIndexSize + encRegCountSize + encRegSizeSize + encHashSize
 )
 
-const payloadEncodingVersion = 1
+// payloadEncodingVersion is the encoding version of payloads in checkpoints, which
+// must match node.PayloadEncodingVersion to read payloads into payload stores.
+const payloadEncodingVersion = node.PayloadEncodingVersion
 
 // encodeLeafNode encodes leaf node in the following format:
 // - node type (1 byte)
@@ -47,24 +50,22 @@ const payloadEncodingVersion = 1
 // WARNING: The returned buffer is likely to share the same underlying array as
 // the scratch buffer. Caller is responsible for copying or using returned buffer
 // before scratch buffer is used again.
-func encodeLeafNode(n *node.Node, scratch []byte) []byte {
+// No errors are expected during normal operations.
+func encodeLeafNode(n *node.Node, scratch []byte) ([]byte, error) {
 
-	encPayloadSize := ledger.EncodedPayloadLengthWithoutPrefix(n.Payload(), payloadEncodingVersion)
-
-	encodedNodeSize := encNodeTypeSize +
+	encodedNodeHeaderSize := encNodeTypeSize +
 		encHeightSize +
 		encHashSize +
 		encPathSize +
-		encPayloadLengthSize +
-		encPayloadSize
+		encPayloadLengthSize
 
 	// buf uses received scratch buffer if it's large enough.
 	// Otherwise, a new buffer is allocated.
 	// buf is used directly so len(buf) must not be 0.
 	// buf will be resliced to proper size before being returned from this function.
 	buf := scratch
-	if len(scratch) < encodedNodeSize {
-		buf = make([]byte, encodedNodeSize)
+	if len(scratch) < encodedNodeHeaderSize {
+		buf = make([]byte, encodedNodeHeaderSize)
 	}
 
 	pos := 0
@@ -87,15 +88,80 @@ func encodeLeafNode(n *node.Node, scratch []byte) []byte {
 	copy(buf[pos:], path[:])
 	pos += encPathSize
 
-	// Encode payload (4 bytes Big Endian for encoded payload length and n bytes encoded payload)
-	binary.BigEndian.PutUint32(buf[pos:], uint32(encPayloadSize))
+	// Payload length is encoded once the payload is appended.
+	payloadLengthPos := pos
 	pos += encPayloadLengthSize
 
-	// EncodeAndAppendPayloadWithoutPrefix appends encoded payload to the resliced buf.
-	// Returned buf is resliced to include appended payload.
-	buf = ledger.EncodeAndAppendPayloadWithoutPrefix(buf[:pos], n.Payload(), payloadEncodingVersion)
+	// AppendEncodedPayload appends encoded payload to the resliced buf.
+	// Returned buf is resliced to include appended payload. Payloads kept
+	// in a payload store are copied from the store without decoding them.
+	buf, err := n.AppendEncodedPayload(buf[:pos], payloadEncodingVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	// Encode payload length (4 bytes Big Endian)
+	binary.BigEndian.PutUint32(buf[payloadLengthPos:], uint32(len(buf)-pos))
 
-	return buf
+	return buf, nil
+}
+
+// encodeStoredLeafNode encodes leaf node referencing its payload in a payload store
+// in the following format:
+// - node type (1 byte)
+// - height (2 bytes)
+// - hash (32 bytes)
+// - path (32 bytes)
+// - payload key (32 bytes)
+// Encoded stored leaf node size is 99 bytes (assuming length of hash/path is 32 bytes).
+// Scratch buffer is used to avoid allocs. It should be used directly instead
+// of using append.  This function uses len(scratch) and ignores cap(scratch),
+// so any extra capacity will not be utilized.
+// WARNING: The returned buffer is likely to share the same underlying array as
+// the scratch buffer. Caller is responsible for copying or using returned buffer
+// before scratch buffer is used again.
+func encodeStoredLeafNode(n *node.Node, key hash.Hash, scratch []byte) []byte {
+
+	const encodedNodeSize = encNodeTypeSize +
+		encHeightSize +
+		encHashSize +
+		encPathSize +
+		encHashSize
+
+	// buf uses received scratch buffer if it's large enough.
+	// Otherwise, a new buffer is allocated.
+	// buf is used directly so len(buf) must not be 0.
+	// buf will be resliced to proper size before being returned from this function.
+	buf := scratch
+	if len(scratch) < encodedNodeSize {
+		buf = make([]byte, encodedNodeSize)
+	}
+
+	pos := 0
+
+	// Encode node type (1 byte)
+	buf[pos] = byte(storedLeafNodeType)
+	pos += encNodeTypeSize
+
+	// Encode height (2 bytes Big Endian)
+	binary.BigEndian.PutUint16(buf[pos:], uint16(n.Height()))
+	pos += encHeightSize
+
+	// Encode hash (32 bytes hashValue)
+	h := n.Hash()
+	copy(buf[pos:], h[:])
+	pos += encHashSize
+
+	// Encode path (32 bytes path)
+	path := n.Path()
+	copy(buf[pos:], path[:])
+	pos += encPathSize
+
+	// Encode payload key (32 bytes)
+	copy(buf[pos:], key[:])
+	pos += encHashSize
+
+	return buf[:pos]
 }
 
 // encodeInterimNode encodes interim node in the following format:
@@ -159,11 +225,25 @@ func encodeInterimNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scr
 // WARNING: The returned buffer is likely to share the same underlying array as
 // the scratch buffer. Caller is responsible for copying or using returned buffer
 // before scratch buffer is used again.
-func EncodeNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) []byte {
+// No errors are expected during normal operations.
+func EncodeNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) ([]byte, error) {
 	if n.IsLeaf() {
 		return encodeLeafNode(n, scratch)
 	}
-	return encodeInterimNode(n, lchildIndex, rchildIndex, scratch)
+	return encodeInterimNode(n, lchildIndex, rchildIndex, scratch), nil
+}
+
+// EncodeNodeWithPayloadReference is EncodeNode, which encodes leaf nodes with payloads
+// kept in a payload store with the key of their payload instead of the payload.
+// Such nodes can only be read with a payload batch of the same store.
+// No errors are expected during normal operations.
+func EncodeNodeWithPayloadReference(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) ([]byte, error) {
+	if n.IsLeaf() {
+		if key, ok := n.StoredPayloadKey(); ok {
+			return encodeStoredLeafNode(n, key, scratch), nil
+		}
+	}
+	return EncodeNode(n, lchildIndex, rchildIndex, scratch)
 }
 
 // ReadNode reconstructs a node from data read from reader.
@@ -172,6 +252,20 @@ func EncodeNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []
 // so any extra capacity will not be utilized.
 // If len(scratch) < 1024, then a new buffer will be allocated and used.
 func ReadNode(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (*node.Node, error)) (*node.Node, error) {
+	return ReadNodeWithPayloadBatch(reader, scratch, getNode, nil)
+}
+
+// ReadNodeWithPayloadBatch is ReadNode, which adds the payloads of leaf nodes to the
+// given batch if it isn't nil. Payloads are added as read, without decoding them, so
+// leaf nodes must not be used until the batch has been flushed.
+// Leaf nodes referencing their payload in a payload store (see EncodeNodeWithPayloadReference)
+// can only be read with a batch of the same store.
+func ReadNodeWithPayloadBatch(
+	reader io.Reader,
+	scratch []byte,
+	getNode func(nodeIndex uint64) (*node.Node, error),
+	payloads *node.PayloadBatch,
+) (*node.Node, error) {
 
 	// minBufSize should be large enough for interim node and leaf node with small payload.
 	// minBufSize is a failsafe and is only used when len(scratch) is much smaller
@@ -196,7 +290,7 @@ func ReadNode(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 	nType := scratch[pos]
 	pos += encNodeTypeSize
 
-	if nType != byte(leafNodeType) && nType != byte(interimNodeType) {
+	if nType != byte(leafNodeType) && nType != byte(interimNodeType) && nType != byte(storedLeafNodeType) {
 		return nil, fmt.Errorf("failed to decode node type %d", nType)
 	}
 
@@ -210,6 +304,36 @@ func ReadNode(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 		return nil, fmt.Errorf("failed to decode hash of serialized node: %w", err)
 	}
 
+	if nType == byte(storedLeafNodeType) {
+
+		// Read path and payload key (64 bytes)
+		_, err := io.ReadFull(reader, scratch[:encPathSize+encHashSize])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read path and payload key of serialized node: %w", err)
+		}
+
+		path, err := ledger.ToPath(scratch[:encPathSize])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode path of serialized node: %w", err)
+		}
+
+		key, err := hash.ToHash(scratch[encPathSize : encPathSize+encHashSize])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode payload key of serialized node: %w", err)
+		}
+
+		if payloads == nil {
+			return nil, fmt.Errorf("serialized node references payload %x of a payload store, which is required to read it", key)
+		}
+
+		n := node.NewNode(int(height), nil, nil, path, nil, nodeHash)
+		err = payloads.AddReference(n, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reference payload of serialized node: %w", err)
+		}
+		return n, nil
+	}
+
 	if nType == byte(leafNodeType) {
 
 		// Read path (32 bytes)
@@ -225,6 +349,21 @@ func ReadNode(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 			return nil, fmt.Errorf("failed to decode path of serialized node: %w", err)
 		}
 
+		if payloads != nil {
+			// Checkpoints encode payloads in the version expected by payload stores.
+			encPayload, err := readEncodedPayloadFromReader(reader, scratch)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read payload of serialized node: %w", err)
+			}
+
+			n := node.NewNode(int(height), nil, nil, path, nil, nodeHash)
+			err = payloads.AddEncoded(n, encPayload)
+			if err != nil {
+				return nil, fmt.Errorf("failed to store payload of serialized node: %w", err)
+			}
+			return n, nil
+		}
+
 		// Read encoded payload data and create ledger.Payload.
 		payload, err := readPayloadFromReader(reader, scratch)
 		if err != nil {
@@ -268,6 +407,58 @@ func ReadNode(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 	return n, nil
 }
 
+// ReadLeafPayload reads a serialized node from reader without restoring it, and returns
+// its payload if it is a leaf node, or nil if it is an interim node. Child node indexes
+// aren't resolved, so nodes can be read without keeping previously read nodes.
+// Leaf nodes referencing their payload in a payload store (see EncodeNodeWithPayloadReference)
+// can't be read.
+func ReadLeafPayload(reader io.Reader, scratch []byte) (*ledger.Payload, error) {
+
+	// minBufSize is a failsafe, see ReadNodeWithPayloadBatch.
+	const minBufSize = 1024
+
+	if len(scratch) < minBufSize {
+		scratch = make([]byte, minBufSize)
+	}
+
+	// fixLengthSize is the size of shared data of leaf node and interim node
+	const fixLengthSize = encNodeTypeSize + encHeightSize + encHashSize
+
+	_, err := io.ReadFull(reader, scratch[:fixLengthSize])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixed-length part of serialized node: %w", err)
+	}
+
+	switch nType := nodeType(scratch[0]); nType {
+	case leafNodeType:
+		// Skip path (32 bytes)
+		_, err := io.ReadFull(reader, scratch[:encPathSize])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read path of serialized node: %w", err)
+		}
+
+		payload, err := readPayloadFromReader(reader, scratch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read and decode payload of serialized node: %w", err)
+		}
+		return payload, nil
+
+	case interimNodeType:
+		// Skip left and right child index (16 bytes)
+		_, err := io.ReadFull(reader, scratch[:encNodeIndexSize*2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read child index of serialized node: %w", err)
+		}
+		return nil, nil
+
+	case storedLeafNodeType:
+		return nil, fmt.Errorf("serialized node references its payload in a payload store, which is required to read it")
+
+	default:
+		return nil, fmt.Errorf("failed to decode node type %d", nType)
+	}
+}
+
 // EncodeTrie encodes trie in the following format:
 // - root node index (8 byte)
 // - allocated reg count (8 byte)
@@ -307,6 +498,11 @@ func EncodeTrie(trie *trie.MTrie, rootIndex uint64, scratch []byte) []byte {
 
 // ReadTrie reconstructs a trie from data read from reader.
 func ReadTrie(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (*node.Node, error)) (*trie.MTrie, error) {
+	return ReadTrieWithHasher(reader, scratch, getNode, ledger.DefaultTrieHasher)
+}
+
+// ReadTrieWithHasher reconstructs a trie using the given hasher from data read from reader.
+func ReadTrieWithHasher(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (*node.Node, error), hasher *ledger.TrieHasher) (*trie.MTrie, error) {
 
 	if len(scratch) < encodedTrieSize {
 		scratch = make([]byte, encodedTrieSize)
@@ -343,7 +539,7 @@ func ReadTrie(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 		return nil, fmt.Errorf("failed to find root node of serialized trie: %w", err)
 	}
 
-	mtrie, err := trie.NewMTrie(rootNode, regCount, regSize)
+	mtrie, err := trie.NewMTrieWithHasher(rootNode, regCount, regSize, hasher)
 	if err != nil {
 		return nil, fmt.Errorf("failed to restore serialized trie: %w", err)
 	}
@@ -356,6 +552,54 @@ func ReadTrie(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 	return mtrie, nil
 }
 
+// ReadTrieRootHash reads a serialized trie from reader without restoring it, and returns
+// its root hash.
+func ReadTrieRootHash(reader io.Reader, scratch []byte) (ledger.RootHash, error) {
+
+	if len(scratch) < encodedTrieSize {
+		scratch = make([]byte, encodedTrieSize)
+	}
+
+	// Read encoded trie
+	_, err := io.ReadFull(reader, scratch[:encodedTrieSize])
+	if err != nil {
+		return ledger.RootHash(hash.DummyHash), fmt.Errorf("failed to read serialized trie: %w", err)
+	}
+
+	// Root node hash is encoded last
+	rootHash, err := hash.ToHash(scratch[encodedTrieSize-encHashSize : encodedTrieSize])
+	if err != nil {
+		return ledger.RootHash(hash.DummyHash), fmt.Errorf("failed to decode hash of serialized trie: %w", err)
+	}
+
+	return ledger.RootHash(rootHash), nil
+}
+
+// readEncodedPayloadFromReader reads an encoded payload from reader.
+// Returned payload is a copy.
+func readEncodedPayloadFromReader(reader io.Reader, scratch []byte) ([]byte, error) {
+	if len(scratch) < encPayloadLengthSize {
+		scratch = make([]byte, encPayloadLengthSize)
+	}
+
+	// Read payload size
+	_, err := io.ReadFull(reader, scratch[:encPayloadLengthSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read payload length: %w", err)
+	}
+
+	// Decode payload size
+	size := binary.BigEndian.Uint32(scratch)
+
+	encPayload := make([]byte, size)
+	_, err = io.ReadFull(reader, encPayload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read payload: %w", err)
+	}
+
+	return encPayload, nil
+}
+
 // readPayloadFromReader reads and decodes payload from reader.
 // Returned payload is a copy.
 func readPayloadFromReader(reader io.Reader, scratch []byte) (*ledger.Payload, error) {
@@ -22,8 +22,11 @@ func TestNodeV3Decoding(t *testing.T) {
 	const leafNode1Index = 1
 	const leafNode2Index = 2
 
-	leafNode1 := node.NewNode(255, nil, nil, testutils.PathByUint8(0), testutils.LightPayload8('A', 'a'), hash.Hash([32]byte{1, 1, 1}))
-	leafNode2 := node.NewNode(255, nil, nil, testutils.PathByUint8(1), testutils.LightPayload8('B', 'b'), hash.Hash([32]byte{2, 2, 2}))
+	payload1 := testutils.LightPayload8('A', 'a')
+	payload2 := testutils.LightPayload8('B', 'b')
+
+	leafNode1 := node.NewNode(255, nil, nil, testutils.PathByUint8(0), payload1, hash.Hash([32]byte{1, 1, 1}))
+	leafNode2 := node.NewNode(255, nil, nil, testutils.PathByUint8(1), payload2, hash.Hash([32]byte{2, 2, 2}))
 
 	interimNode := node.NewNode(256, leafNode1, leafNode2, ledger.DummyPath, nil, hash.Hash([32]byte{3, 3, 3}))
 
@@ -75,7 +78,7 @@ func TestNodeV3Decoding(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, leafNode1, newNode)
 		require.Equal(t, uint64(1), regCount)
-		require.Equal(t, uint64(leafNode1.Payload().Size()), regSize)
+		require.Equal(t, uint64(payload1.Size()), regSize)
 	})
 
 	t.Run("interim node", func(t *testing.T) {
@@ -83,9 +86,9 @@ func TestNodeV3Decoding(t *testing.T) {
 		newNode, regCount, regSize, err := flattener.ReadNodeFromCheckpointV3AndEarlier(reader, func(nodeIndex uint64) (*node.Node, uint64, uint64, error) {
 			switch nodeIndex {
 			case leafNode1Index:
-				return leafNode1, 1, uint64(leafNode1.Payload().Size()), nil
+				return leafNode1, 1, uint64(payload1.Size()), nil
 			case leafNode2Index:
-				return leafNode2, 1, uint64(leafNode2.Payload().Size()), nil
+				return leafNode2, 1, uint64(payload2.Size()), nil
 			default:
 				return nil, 0, 0, fmt.Errorf("unexpected child node index %d ", nodeIndex)
 			}
@@ -93,7 +96,7 @@ func TestNodeV3Decoding(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, interimNode, newNode)
 		require.Equal(t, uint64(2), regCount)
-		require.Equal(t, uint64(leafNode1.Payload().Size()+leafNode2.Payload().Size()), regSize)
+		require.Equal(t, uint64(payload1.Size()+payload2.Size()), regSize)
 	})
 }
 
@@ -119,7 +119,9 @@ func TestLeafNodeV4Decoding(t *testing.T) {
 				assert.Equal(t, tc.node, newNode)
 				assert.Equal(t, 0, reader.Len())
 				require.Equal(t, uint64(1), regCount)
-				require.Equal(t, uint64(tc.node.Payload().Size()), regSize)
+				payload, err := tc.node.Payload()
+				require.NoError(t, err)
+				require.Equal(t, uint64(payload.Size()), regSize)
 			}
 		})
 	}
@@ -172,9 +174,9 @@ func TestInterimNodeV4Decoding(t *testing.T) {
 			newNode, regCount, regSize, err := flattener.ReadNodeFromCheckpointV4(reader, scratch, func(nodeIndex uint64) (*node.Node, uint64, uint64, error) {
 				switch nodeIndex {
 				case lchildIndex:
-					return leafNode1, 1, uint64(leafNode1.Payload().Size()), nil
+					return leafNode1, 1, uint64(payload1.Size()), nil
 				case rchildIndex:
-					return leafNode2, 1, uint64(leafNode2.Payload().Size()), nil
+					return leafNode2, 1, uint64(payload2.Size()), nil
 				default:
 					return nil, 0, 0, fmt.Errorf("unexpected child node index %d ", nodeIndex)
 				}
@@ -183,7 +185,7 @@ func TestInterimNodeV4Decoding(t *testing.T) {
 			assert.Equal(t, interimNode, newNode)
 			assert.Equal(t, 0, reader.Len())
 			require.Equal(t, uint64(2), regCount)
-			require.Equal(t, uint64(leafNode1.Payload().Size()+leafNode2.Payload().Size()), regSize)
+			require.Equal(t, uint64(payload1.Size()+payload2.Size()), regSize)
 		}
 	})
 
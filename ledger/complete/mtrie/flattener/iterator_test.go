@@ -53,12 +53,16 @@ func TestPopulatedTrie(t *testing.T) {
 	require.True(t, itr.Next())
 	p1_leaf := itr.Value()
 	require.Equal(t, p1, *p1_leaf.Path())
-	require.Equal(t, v1, p1_leaf.Payload())
+	p1_payload, err := p1_leaf.Payload()
+	require.NoError(t, err)
+	require.Equal(t, v1, p1_payload)
 
 	require.True(t, itr.Next())
 	p2_leaf := itr.Value()
 	require.Equal(t, p2, *p2_leaf.Path())
-	require.Equal(t, v2, p2_leaf.Payload())
+	p2_payload, err := p2_leaf.Payload()
+	require.NoError(t, err)
+	require.Equal(t, v2, p2_payload)
 
 	require.True(t, itr.Next())
 	p_parent := itr.Value()
@@ -2,9 +2,11 @@ package mtrie
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/module"
 )
@@ -28,6 +30,45 @@ type Forest struct {
 	forestCapacity int
 	onTreeEvicted  func(tree *trie.MTrie)
 	metrics        module.LedgerMetrics
+	// payloadStore keeps the payloads of all leaves outside of memory, if set.
+	payloadStore node.PayloadStore
+	// holders counts the holders of each trie with stored payloads: the forest while the
+	// trie isn't evicted, and callers of RetainTries. The payloads of tries without holders
+	// are released, unless they are referenced by a trie with holders.
+	holders map[*trie.MTrie]int
+	// evicted are the tries evicted from the forest, which holders aren't updated yet.
+	evicted []*trie.MTrie
+	// holdersLock serializes changes of the tries in the forest with changes of holders,
+	// so that the holders always match the tries in the forest.
+	holdersLock sync.Mutex
+	// hasher hashes the nodes of all tries in the forest.
+	hasher *ledger.TrieHasher
+}
+
+// ForestOption configures optional Forest behavior.
+type ForestOption func(*Forest)
+
+// WithPayloadStore configures the forest to keep the payloads of all tries in the
+// given store instead of memory. Payloads are moved into the store when a trie is
+// created by NewTrie or Update, or added by AddTries (e.g. when loading a checkpoint).
+// Reads, updates and proofs are not affected, besides loading payloads from the store.
+//
+// Payloads are released explicitly once the tries referencing them are evicted from the
+// forest and not retained anymore, see RetainTries. Evicted tries must not be used after
+// they are released.
+func WithPayloadStore(store node.PayloadStore) ForestOption {
+	return func(f *Forest) {
+		f.payloadStore = store
+	}
+}
+
+// WithHasher configures the trie hasher of the forest, which hashes the nodes of all tries
+// created by the forest. Tries added to the forest (e.g. when loading a checkpoint) must
+// have been hashed with the same hasher. Defaults to ledger.DefaultTrieHasher.
+func WithHasher(hasher *ledger.TrieHasher) ForestOption {
+	return func(f *Forest) {
+		f.hasher = hasher
+	}
 }
 
 // NewForest returns a new instance of memory forest.
@@ -36,15 +77,25 @@ type Forest struct {
 // If more tries are added than the capacity, the Least Recently Added trie is removed (evicted) from the Forest (FIFO queue).
 // Make sure you chose a sufficiently large forestCapacity, such that, when reaching the capacity, the
 // Least Recently Added trie will never be needed again.
-func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted func(tree *trie.MTrie)) (*Forest, error) {
-	forest := &Forest{tries: NewTrieCache(uint(forestCapacity), onTreeEvicted),
+func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted func(tree *trie.MTrie), opts ...ForestOption) (*Forest, error) {
+	forest := &Forest{
 		forestCapacity: forestCapacity,
 		onTreeEvicted:  onTreeEvicted,
 		metrics:        metrics,
+		hasher:         ledger.DefaultTrieHasher,
+	}
+	forest.tries = NewTrieCache(uint(forestCapacity), forest.onEvicted)
+
+	for _, opt := range opts {
+		opt(forest)
+	}
+
+	if forest.payloadStore != nil {
+		forest.holders = make(map[*trie.MTrie]int)
 	}
 
 	// add trie with no allocated registers
-	emptyTrie := trie.NewEmptyMTrie()
+	emptyTrie := trie.NewEmptyMTrieWithHasher(forest.hasher)
 	err := forest.AddTrie(emptyTrie)
 	if err != nil {
 		return nil, fmt.Errorf("adding empty trie to forest failed: %w", err)
@@ -82,7 +133,10 @@ func (f *Forest) ValueSizes(r *ledger.TrieRead) ([]int, error) {
 		pathOrgIndex[path] = append(indices, i)
 	}
 
-	sizes := trie.UnsafeValueSizes(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	sizes, err := trie.UnsafeValueSizes(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("reading value sizes failed: %w", err)
+	}
 
 	// reconstruct value sizes in the same key order that called the method
 	orderedValueSizes := make([]int, len(r.Paths))
@@ -109,7 +163,10 @@ func (f *Forest) ReadSingleValue(r *ledger.TrieReadSingleValue) (ledger.Value, e
 		return nil, err
 	}
 
-	payload := trie.ReadSinglePayload(r.Path)
+	payload, err := trie.ReadSinglePayload(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading value failed: %w", err)
+	}
 	return payload.Value().DeepCopy(), nil
 }
 
@@ -129,7 +186,10 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]ledger.Value, error) {
 
 	// call ReadSinglePayload if there is only one path
 	if len(r.Paths) == 1 {
-		payload := trie.ReadSinglePayload(r.Paths[0])
+		payload, err := trie.ReadSinglePayload(r.Paths[0])
+		if err != nil {
+			return nil, fmt.Errorf("reading value failed: %w", err)
+		}
 		return []ledger.Value{payload.Value().DeepCopy()}, nil
 	}
 
@@ -149,7 +209,10 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]ledger.Value, error) {
 		pathOrgIndex[path] = append(indices, i)
 	}
 
-	payloads := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	payloads, err := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("reading values failed: %w", err)
+	}
 
 	// reconstruct the payloads in the same key order that called the method
 	orderedValues := make([]ledger.Value, len(r.Paths))
@@ -236,6 +299,15 @@ func (f *Forest) NewTrie(u *ledger.TrieUpdate) (*trie.MTrie, error) {
 		return nil, fmt.Errorf("constructing updated trie failed: %w", err)
 	}
 
+	if f.payloadStore != nil {
+		// only the nodes created by the update are traversed, as all
+		// other nodes are shared with the parent trie.
+		err = node.StorePayloads(newTrie.RootNode(), parentTrie.RootNode(), f.payloadStore)
+		if err != nil {
+			return nil, fmt.Errorf("storing payloads of updated trie failed: %w", err)
+		}
+	}
+
 	f.metrics.LatestTrieRegCount(newTrie.AllocatedRegCount())
 	f.metrics.LatestTrieRegCountDiff(int64(newTrie.AllocatedRegCount() - parentTrie.AllocatedRegCount()))
 	f.metrics.LatestTrieRegSize(newTrie.AllocatedRegSize())
@@ -297,10 +369,79 @@ func (f *Forest) Proofs(r *ledger.TrieRead) (*ledger.TrieBatchProof, error) {
 		stateTrie = newTrie
 	}
 
-	bp := stateTrie.UnsafeProofs(r.Paths)
+	bp, err := stateTrie.UnsafeProofs(r.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("generating proofs failed: %w", err)
+	}
 	return bp, nil
 }
 
+// Diff returns the payloads which differ between the tries with the given root hashes,
+// ordered by path. Subtries shared by both tries are skipped, see trie.Diff for details.
+func (f *Forest) Diff(from ledger.RootHash, to ledger.RootHash) ([]ledger.PayloadDiff, error) {
+	fromTrie, err := f.GetTrie(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toTrie, err := f.GetTrie(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := trie.Diff(fromTrie, toTrie)
+	if err != nil {
+		return nil, fmt.Errorf("computing trie diff failed: %w", err)
+	}
+
+	// copy payloads, so they can't be modified by the caller
+	for i := range diffs {
+		if diffs[i].From != nil {
+			diffs[i].From = diffs[i].From.DeepCopy()
+		}
+		if diffs[i].To != nil {
+			diffs[i].To = diffs[i].To.DeepCopy()
+		}
+	}
+
+	return diffs, nil
+}
+
+// PayloadsWithPrefix calls fn for each non-empty payload of the trie with the given
+// root hash whose path starts with the given prefix, ordered by path.
+// Payloads are copied, so they can be retained by fn.
+func (f *Forest) PayloadsWithPrefix(rootHash ledger.RootHash, prefix []byte, fn func(path ledger.Path, payload *ledger.Payload) error) error {
+	t, err := f.GetTrie(rootHash)
+	if err != nil {
+		return err
+	}
+
+	return t.IteratePayloadsWithPrefix(prefix, func(path ledger.Path, payload *ledger.Payload) error {
+		return fn(path, payload.DeepCopy())
+	})
+}
+
+// RangeProof returns a proof that the payloads stored under the given prefix
+// in the trie with the given root hash are complete, see trie.RangeProof for details.
+func (f *Forest) RangeProof(rootHash ledger.RootHash, prefix []byte) (*ledger.TrieRangeProof, error) {
+	t, err := f.GetTrie(rootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := t.RangeProof(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// copy payloads, so they can't be modified by the caller
+	for i := range proof.Payloads {
+		proof.Payloads[i] = proof.Payloads[i].DeepCopy()
+	}
+
+	return proof, nil
+}
+
 // HasTrie returns true if trie exist at specific rootHash
 func (f *Forest) HasTrie(rootHash ledger.RootHash) bool {
 	_, found := f.tries.Get(rootHash)
@@ -322,9 +463,26 @@ func (f *Forest) GetTries() ([]*trie.MTrie, error) {
 	return f.tries.Tries(), nil
 }
 
+// PayloadStore returns the store keeping the payloads of the tries, or nil if payloads are kept in memory.
+func (f *Forest) PayloadStore() node.PayloadStore {
+	return f.payloadStore
+}
+
 // AddTries adds a trie to the forest
+// If the forest has a payload store, the payloads of the tries are moved into the store.
 func (f *Forest) AddTries(newTries []*trie.MTrie) error {
+	var previous *node.Node
 	for _, t := range newTries {
+		if f.payloadStore != nil && t != nil {
+			// consecutive tries (e.g. from a checkpoint) share most of their nodes,
+			// so only the nodes not shared with the previous trie are traversed.
+			err := node.StorePayloads(t.RootNode(), previous, f.payloadStore)
+			if err != nil {
+				return fmt.Errorf("storing payloads of trie failed: %w", err)
+			}
+			previous = t.RootNode()
+		}
+
 		err := f.AddTrie(t)
 		if err != nil {
 			return fmt.Errorf("adding tries to forest failed: %w", err)
@@ -334,26 +492,140 @@ func (f *Forest) AddTries(newTries []*trie.MTrie) error {
 }
 
 // AddTrie adds a trie to the forest
+// If the forest has a payload store, the payloads of evicted tries without holders are released.
+// Payloads stored for a trie with the same root hash as a trie in the forest are released, unless
+// the trie is retained.
 func (f *Forest) AddTrie(newTrie *trie.MTrie) error {
 	if newTrie == nil {
 		return nil
 	}
 
-	// TODO: check Thread safety
+	if newTrie.Hasher().Type() != f.hasher.Type() {
+		return fmt.Errorf("trie with root hash %v is hashed with %v, but the forest uses %v",
+			newTrie.RootHash(), newTrie.Hasher().Type(), f.hasher.Type())
+	}
+
+	f.holdersLock.Lock()
+	defer f.holdersLock.Unlock()
+
 	rootHash := newTrie.RootHash()
-	if _, found := f.tries.Get(rootHash); found {
-		// do no op
+	if found, ok := f.tries.Get(rootHash); ok {
+		if f.payloadStore != nil && found != newTrie {
+			return f.releaseUnheld([]*trie.MTrie{newTrie})
+		}
 		return nil
 	}
 	f.tries.Push(newTrie)
 	f.metrics.ForestNumberOfTrees(uint64(f.tries.Count()))
 
+	if f.payloadStore == nil {
+		return nil
+	}
+	f.holders[newTrie]++
+	return f.releaseEvicted()
+}
+
+// RetainTries adds a holder to the given tries, so that their payloads are kept in the
+// payload store even if the tries are evicted from the forest, until ReleaseTries is called.
+// It is a no-op if the forest keeps payloads in memory.
+func (f *Forest) RetainTries(tries ...*trie.MTrie) {
+	if f.payloadStore == nil {
+		return
+	}
+
+	f.holdersLock.Lock()
+	defer f.holdersLock.Unlock()
+
+	for _, t := range tries {
+		if t != nil {
+			f.holders[t]++
+		}
+	}
+}
+
+// ReleaseTries removes a holder added by RetainTries from the given tries, and releases
+// the payloads of the tries without holders which aren't referenced by a trie with holders.
+// It is a no-op if the forest keeps payloads in memory.
+// No errors are expected during normal operations.
+func (f *Forest) ReleaseTries(tries ...*trie.MTrie) error {
+	if f.payloadStore == nil {
+		return nil
+	}
+
+	f.holdersLock.Lock()
+	defer f.holdersLock.Unlock()
+
+	for _, t := range tries {
+		if f.holders[t] > 0 {
+			f.holders[t]--
+		}
+	}
+	return f.releaseUnheld(tries)
+}
+
+// onEvicted is called by the trie cache for every evicted trie. As tries are only pushed
+// and purged while holding holdersLock, evicted tries are recorded without locking.
+func (f *Forest) onEvicted(t *trie.MTrie) {
+	if f.onTreeEvicted != nil {
+		f.onTreeEvicted(t)
+	}
+	if f.payloadStore != nil {
+		f.evicted = append(f.evicted, t)
+	}
+}
+
+// releaseEvicted removes the forest from the holders of the evicted tries, and releases the
+// payloads of the tries without holders. Must be called while holding holdersLock.
+// No errors are expected during normal operations.
+func (f *Forest) releaseEvicted() error {
+	evicted := f.evicted
+	f.evicted = nil
+
+	for _, t := range evicted {
+		if f.holders[t] > 0 {
+			f.holders[t]--
+		}
+	}
+	return f.releaseUnheld(evicted)
+}
+
+// releaseUnheld releases the payloads of the given tries without holders, which aren't
+// referenced by any trie with holders. Must be called while holding holdersLock.
+// No errors are expected during normal operations.
+func (f *Forest) releaseUnheld(tries []*trie.MTrie) error {
+	var released []*node.Node
+	for _, t := range tries {
+		if t == nil || f.holders[t] > 0 {
+			continue
+		}
+		delete(f.holders, t)
+		released = append(released, t.RootNode())
+	}
+
+	if len(released) == 0 {
+		return nil
+	}
+
+	remaining := make([]*node.Node, 0, len(f.holders))
+	for t := range f.holders {
+		remaining = append(remaining, t.RootNode())
+	}
+
+	err := node.ReleasePayloads(released, remaining, f.payloadStore)
+	if err != nil {
+		return fmt.Errorf("releasing payloads of %d tries failed: %w", len(released), err)
+	}
 	return nil
 }
 
 // GetEmptyRootHash returns the rootHash of empty Trie
 func (f *Forest) GetEmptyRootHash() ledger.RootHash {
-	return trie.EmptyTrieRootHash()
+	return f.hasher.EmptyTrieRootHash()
+}
+
+// Hasher returns the trie hasher of the forest
+func (f *Forest) Hasher() *ledger.TrieHasher {
+	return f.hasher
 }
 
 // MostRecentTouchedRootHash returns the rootHash of the most recently touched trie
@@ -367,13 +639,21 @@ func (f *Forest) MostRecentTouchedRootHash() (ledger.RootHash, error) {
 
 // PurgeCacheExcept removes all tries in the memory except the one with the given root hash
 func (f *Forest) PurgeCacheExcept(rootHash ledger.RootHash) error {
+	f.holdersLock.Lock()
+	defer f.holdersLock.Unlock()
+
 	trie, found := f.tries.Get(rootHash)
 	if !found {
 		return fmt.Errorf("trie with the given root hash not found")
 	}
 	f.tries.Purge()
 	f.tries.Push(trie)
-	return nil
+
+	if f.payloadStore == nil {
+		return nil
+	}
+	f.holders[trie]++
+	return f.releaseEvicted()
 }
 
 // Size returns the number of active tries in this store
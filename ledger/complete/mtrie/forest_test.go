@@ -2,8 +2,10 @@ package mtrie
 
 import (
 	"bytes"
+	"fmt"
 	"math/rand"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,8 +13,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	prf "github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
 	"github.com/onflow/flow-go/module/metrics"
@@ -1089,3 +1094,360 @@ func TestPurgeCacheExcept(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, forest.tries.Count())
 }
+
+// mapPayloadStore is an in-memory node.PayloadStore, which removes payloads as soon as they are released.
+type mapPayloadStore struct {
+	mu       sync.Mutex
+	payloads map[hash.Hash][]byte
+	refs     map[hash.Hash]int
+}
+
+func newMapPayloadStore() *mapPayloadStore {
+	return &mapPayloadStore{
+		payloads: make(map[hash.Hash][]byte),
+		refs:     make(map[hash.Hash]int),
+	}
+}
+
+func (s *mapPayloadStore) Put(keys []hash.Hash, encodedPayloads [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, key := range keys {
+		s.payloads[key] = encodedPayloads[i]
+		s.refs[key]++
+	}
+	return nil
+}
+
+func (s *mapPayloadStore) Acquire(keys []hash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := s.payloads[key]; !ok {
+			return fmt.Errorf("payload %x not found", key)
+		}
+		s.refs[key]++
+	}
+	return nil
+}
+
+func (s *mapPayloadStore) Get(key hash.Hash) (*ledger.Payload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	encoded, ok := s.payloads[key]
+	if !ok {
+		return nil, fmt.Errorf("payload %x not found", key)
+	}
+	return ledger.DecodePayloadWithoutPrefix(encoded, false, node.PayloadEncodingVersion)
+}
+
+func (s *mapPayloadStore) GetEncoded(key hash.Hash, version uint16, buf []byte) ([]byte, error) {
+	payload, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return ledger.EncodeAndAppendPayloadWithoutPrefix(buf, payload, version), nil
+}
+
+func (s *mapPayloadStore) Release(keys []hash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if s.refs[key] == 0 {
+			return fmt.Errorf("payload %x isn't referenced", key)
+		}
+		s.refs[key]--
+		if s.refs[key] == 0 {
+			delete(s.refs, key)
+			delete(s.payloads, key)
+		}
+	}
+	return nil
+}
+
+func (s *mapPayloadStore) NextGeneration() uint64 { return 0 }
+
+func (s *mapPayloadStore) RemoveReleased(uint64) error { return nil }
+
+func (s *mapPayloadStore) ReleaseUnreferenced() error { return nil }
+
+func (s *mapPayloadStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.payloads)
+}
+
+// TestPayloadStore tests that a forest keeping payloads in a payload store
+// behaves the same as a forest keeping payloads in memory.
+func TestPayloadStore(t *testing.T) {
+
+	store := newMapPayloadStore()
+
+	storeForest, err := NewForest(5, &metrics.NoopCollector{}, nil, WithPayloadStore(store))
+	require.NoError(t, err)
+
+	memoryForest, err := NewForest(5, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+
+	activeRoot := memoryForest.GetEmptyRootHash()
+	var allPaths []ledger.Path
+
+	for i := 0; i < 10; i++ {
+		paths := testutils.RandomPaths(20)
+		payloads := testutils.RandomPayloads(len(paths), 2, 10)
+		allPaths = append(allPaths, paths...)
+
+		update := &ledger.TrieUpdate{RootHash: activeRoot, Paths: paths, Payloads: payloads}
+
+		storeRoot, err := storeForest.Update(update)
+		require.NoError(t, err)
+
+		activeRoot, err = memoryForest.Update(update)
+		require.NoError(t, err)
+		require.Equal(t, activeRoot, storeRoot)
+
+		storeTrie, err := storeForest.GetTrie(activeRoot)
+		require.NoError(t, err)
+		require.True(t, storeTrie.RootNode().VerifyCachedHash())
+
+		// all leaves have their payloads stored
+		for itr := flattener.NewNodeIterator(storeTrie.RootNode()); itr.Next(); {
+			n := itr.Value()
+			require.Equal(t, n.IsLeaf(), n.IsPayloadStored())
+		}
+	}
+
+	read := &ledger.TrieRead{RootHash: activeRoot, Paths: allPaths}
+
+	expectedValues, err := memoryForest.Read(read)
+	require.NoError(t, err)
+	values, err := storeForest.Read(read)
+	require.NoError(t, err)
+	require.Equal(t, expectedValues, values)
+
+	expectedSizes, err := memoryForest.ValueSizes(read)
+	require.NoError(t, err)
+	sizes, err := storeForest.ValueSizes(read)
+	require.NoError(t, err)
+	require.Equal(t, expectedSizes, sizes)
+
+	proofPaths := append(testutils.RandomPaths(10), allPaths[:10]...)
+	expectedProof, err := memoryForest.Proofs(&ledger.TrieRead{RootHash: activeRoot, Paths: sortedCopy(proofPaths)})
+	require.NoError(t, err)
+	proof, err := storeForest.Proofs(&ledger.TrieRead{RootHash: activeRoot, Paths: sortedCopy(proofPaths)})
+	require.NoError(t, err)
+	require.Equal(t, expectedProof, proof)
+
+	// checkpoints encode stored payloads the same way as payloads in memory
+	expectedTrie, err := memoryForest.GetTrie(activeRoot)
+	require.NoError(t, err)
+	storeTrie, err := storeForest.GetTrie(activeRoot)
+	require.NoError(t, err)
+
+	expectedItr := flattener.NewNodeIterator(expectedTrie.RootNode())
+	for itr := flattener.NewNodeIterator(storeTrie.RootNode()); itr.Next(); {
+		require.True(t, expectedItr.Next())
+		expected, err := flattener.EncodeNode(expectedItr.Value(), 0, 0, nil)
+		require.NoError(t, err)
+		encoded, err := flattener.EncodeNode(itr.Value(), 0, 0, nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, encoded)
+	}
+	require.False(t, expectedItr.Next())
+
+	// tries added to the forest have their payloads stored
+	addedForest, err := NewForest(5, &metrics.NoopCollector{}, nil, WithPayloadStore(store))
+	require.NoError(t, err)
+
+	tries, err := memoryForest.GetTries()
+	require.NoError(t, err)
+	err = addedForest.AddTries(tries)
+	require.NoError(t, err)
+
+	addedTrie, err := addedForest.GetTrie(activeRoot)
+	require.NoError(t, err)
+	require.True(t, addedTrie.RootNode().VerifyCachedHash())
+
+	for itr := flattener.NewNodeIterator(addedTrie.RootNode()); itr.Next(); {
+		n := itr.Value()
+		require.Equal(t, n.IsLeaf(), n.IsPayloadStored())
+	}
+
+	values, err = addedForest.Read(read)
+	require.NoError(t, err)
+	require.Equal(t, expectedValues, values)
+}
+
+// TestPayloadStoreRelease tests that the payloads of evicted tries are released,
+// while the payloads of tries in the forest or retained tries are kept.
+func TestPayloadStoreRelease(t *testing.T) {
+
+	store := newMapPayloadStore()
+
+	forest, err := NewForest(2, &metrics.NoopCollector{}, nil, WithPayloadStore(store))
+	require.NoError(t, err)
+
+	// every update overwrites the same registers, so evicted tries don't share leaves with the forest
+	paths := sortedCopy(testutils.RandomPaths(20))
+
+	var lastUpdate *ledger.TrieUpdate
+	update := func(root ledger.RootHash) ledger.RootHash {
+		lastUpdate = &ledger.TrieUpdate{RootHash: root, Paths: paths, Payloads: testutils.RandomPayloads(len(paths), 2, 10)}
+		root, err := forest.Update(lastUpdate)
+		require.NoError(t, err)
+		return root
+	}
+
+	activeRoot := forest.GetEmptyRootHash()
+	for i := 0; i < 10; i++ {
+		activeRoot = update(activeRoot)
+	}
+
+	// only the payloads of the two tries in the forest are kept
+	require.Equal(t, 2*len(paths), store.Len())
+
+	values, err := forest.Read(&ledger.TrieRead{RootHash: activeRoot, Paths: paths})
+	require.NoError(t, err)
+	require.Len(t, values, len(paths))
+
+	// payloads of a retained trie are kept once it is evicted, until it is released
+	retained, err := forest.GetTrie(activeRoot)
+	require.NoError(t, err)
+	forest.RetainTries(retained)
+
+	activeRoot = update(activeRoot)
+	activeRoot = update(activeRoot)
+	require.False(t, forest.HasTrie(retained.RootHash()))
+	require.Equal(t, 3*len(paths), store.Len())
+
+	retainedPayloads, err := retained.UnsafeRead(paths)
+	require.NoError(t, err)
+	for i, payload := range retainedPayloads {
+		require.Equal(t, values[i], payload.Value())
+	}
+
+	err = forest.ReleaseTries(retained)
+	require.NoError(t, err)
+	require.Equal(t, 2*len(paths), store.Len())
+
+	// payloads stored for a trie with the same root hash as a trie in the forest are released when it is added
+	duplicate, err := forest.NewTrie(lastUpdate)
+	require.NoError(t, err)
+	require.Equal(t, activeRoot, duplicate.RootHash())
+	for _, key := range storedPayloadKeys(t, duplicate) {
+		require.Equal(t, 2, store.refs[key])
+	}
+
+	err = forest.AddTrie(duplicate)
+	require.NoError(t, err)
+	for _, key := range storedPayloadKeys(t, duplicate) {
+		require.Equal(t, 1, store.refs[key])
+	}
+
+	// payloads of purged tries are released
+	err = forest.PurgeCacheExcept(activeRoot)
+	require.NoError(t, err)
+	require.Equal(t, len(paths), store.Len())
+
+	values, err = forest.Read(&ledger.TrieRead{RootHash: activeRoot, Paths: paths})
+	require.NoError(t, err)
+	require.Len(t, values, len(paths))
+}
+
+// TestPayloadStoreReleaseCompactifiedLeaves tests that payloads of evicted leaves
+// are kept if they are referenced by compactified copies of the leaves.
+func TestPayloadStoreReleaseCompactifiedLeaves(t *testing.T) {
+
+	store := newMapPayloadStore()
+
+	forest, err := NewForest(2, &metrics.NoopCollector{}, nil, WithPayloadStore(store))
+	require.NoError(t, err)
+
+	// paths share their first byte, so both leaves are deep in the trie
+	p1 := testutils.PathByUint16(0x0100)
+	p2 := testutils.PathByUint16(0x0101)
+	p3 := testutils.PathByUint16(0x8000)
+	payloads := testutils.RandomPayloads(3, 2, 10)
+
+	firstRoot, err := forest.Update(&ledger.TrieUpdate{
+		RootHash: forest.GetEmptyRootHash(),
+		Paths:    []ledger.Path{p1, p2},
+		Payloads: []*ledger.Payload{payloads[0], payloads[1]},
+	})
+	require.NoError(t, err)
+
+	// removing p2 compactifies the leaf at p1, which shares its stored payload
+	compactRoot, err := forest.Update(&ledger.TrieUpdate{
+		RootHash: firstRoot,
+		Paths:    []ledger.Path{p2},
+		Payloads: []*ledger.Payload{ledger.EmptyPayload()},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, store.Len())
+
+	// evicts the trie with the leaf at p1 before compactification, which releases p2 only.
+	// The compactified leaf at p1 is pushed down by the update, which stores p1 again.
+	root, err := forest.Update(&ledger.TrieUpdate{
+		RootHash: compactRoot,
+		Paths:    []ledger.Path{p3},
+		Payloads: []*ledger.Payload{payloads[2]},
+	})
+	require.NoError(t, err)
+	require.False(t, forest.HasTrie(firstRoot))
+	require.Equal(t, 3, store.Len())
+
+	values, err := forest.Read(&ledger.TrieRead{RootHash: compactRoot, Paths: []ledger.Path{p1, p2}})
+	require.NoError(t, err)
+	require.Equal(t, []ledger.Value{payloads[0].Value(), {}}, values)
+
+	values, err = forest.Read(&ledger.TrieRead{RootHash: root, Paths: []ledger.Path{p1, p2, p3}})
+	require.NoError(t, err)
+	require.Equal(t, []ledger.Value{payloads[0].Value(), {}, payloads[2].Value()}, values)
+}
+
+// storedPayloadKeys returns the keys of the stored payloads of all leaves of the given trie.
+func storedPayloadKeys(t *testing.T, tr *trie.MTrie) []hash.Hash {
+	var keys []hash.Hash
+	for itr := flattener.NewNodeIterator(tr.RootNode()); itr.Next(); {
+		n := itr.Value()
+		if !n.IsLeaf() {
+			continue
+		}
+		key, ok := n.StoredPayloadKey()
+		require.True(t, ok)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestForestWithHasher(t *testing.T) {
+
+	hasher, err := ledger.NewTrieHasher(hash.BLAKE3)
+	require.NoError(t, err)
+
+	forest, err := NewForest(5, &metrics.NoopCollector{}, nil, WithHasher(hasher))
+	require.NoError(t, err)
+	require.Equal(t, hasher.EmptyTrieRootHash(), forest.GetEmptyRootHash())
+
+	paths := testutils.RandomPaths(20)
+	payloads := testutils.RandomPayloads(len(paths), 2, 10)
+
+	update := &ledger.TrieUpdate{RootHash: forest.GetEmptyRootHash(), Paths: paths, Payloads: payloads}
+	activeRoot, err := forest.Update(update)
+	require.NoError(t, err)
+
+	updatedTrie, err := forest.GetTrie(activeRoot)
+	require.NoError(t, err)
+	require.Equal(t, hash.BLAKE3, updatedTrie.Hasher().Type())
+
+	proofPaths := sortedCopy(append(testutils.RandomPaths(10), paths[:10]...))
+	proof, err := forest.Proofs(&ledger.TrieRead{RootHash: activeRoot, Paths: proofPaths})
+	require.NoError(t, err)
+	require.True(t, prf.VerifyTrieBatchProofWithHasher(proof, ledger.State(activeRoot), hasher))
+	require.False(t, prf.VerifyTrieBatchProof(proof, ledger.State(activeRoot)))
+
+	// tries hashed with another hasher are rejected
+	defaultTrie, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths[:1], []ledger.Payload{*payloads[0]}, true)
+	require.NoError(t, err)
+	require.Error(t, forest.AddTrie(defaultTrie))
+}
@@ -35,6 +35,7 @@ type Node struct {
 	height    int             // height where the Node is at
 	path      ledger.Path     // the storage path (dummy value for interim nodes)
 	payload   *ledger.Payload // the payload this node is storing (leaf nodes only)
+	stored    *storedPayload  // reference to the payload if it is kept in a PayloadStore (leaf nodes only)
 	hashValue hash.Hash       // hash value of node (cached)
 }
 
@@ -66,6 +67,16 @@ func NewNode(height int,
 func NewLeaf(path ledger.Path,
 	payload *ledger.Payload,
 	height int,
+) *Node {
+	return NewLeafWithHasher(path, payload, height, ledger.DefaultTrieHasher)
+}
+
+// NewLeafWithHasher creates a compact leaf Node, hashed with the given trie hasher.
+// Same requirements as NewLeaf apply.
+func NewLeafWithHasher(path ledger.Path,
+	payload *ledger.Payload,
+	height int,
+	hasher *ledger.TrieHasher,
 ) *Node {
 	n := &Node{
 		lChild:  nil,
@@ -74,7 +85,7 @@ func NewLeaf(path ledger.Path,
 		path:    path,
 		payload: payload,
 	}
-	n.hashValue = n.computeHash()
+	n.hashValue = n.computeHash(hasher)
 	return n
 }
 
@@ -82,13 +93,19 @@ func NewLeaf(path ledger.Path,
 // UNCHECKED requirement:
 //  * for any child `c` that is non-nil, its height must satisfy: height = c.height + 1
 func NewInterimNode(height int, lchild, rchild *Node) *Node {
+	return NewInterimNodeWithHasher(height, lchild, rchild, ledger.DefaultTrieHasher)
+}
+
+// NewInterimNodeWithHasher creates a new interim Node, hashed with the given trie hasher.
+// Same requirements as NewInterimNode apply.
+func NewInterimNodeWithHasher(height int, lchild, rchild *Node, hasher *ledger.TrieHasher) *Node {
 	n := &Node{
 		lChild:  lchild,
 		rChild:  rchild,
 		height:  height,
 		payload: nil,
 	}
-	n.hashValue = n.computeHash()
+	n.hashValue = n.computeHash(hasher)
 	return n
 }
 
@@ -104,10 +121,16 @@ func NewInterimNode(height int, lchild, rchild *Node) *Node {
 // UNCHECKED requirement:
 //  * for any child `c` that is non-nil, its height must satisfy: height = c.height + 1
 func NewInterimCompactifiedNode(height int, lChild, rChild *Node) *Node {
-	if lChild.IsDefaultNode() {
+	return NewInterimCompactifiedNodeWithHasher(height, lChild, rChild, ledger.DefaultTrieHasher)
+}
+
+// NewInterimCompactifiedNodeWithHasher creates a new compactified interim Node, hashed with
+// the given trie hasher. Same requirements as NewInterimCompactifiedNode apply.
+func NewInterimCompactifiedNodeWithHasher(height int, lChild, rChild *Node, hasher *ledger.TrieHasher) *Node {
+	if lChild.IsDefaultNodeWithHasher(hasher) {
 		lChild = nil
 	}
-	if rChild.IsDefaultNode() {
+	if rChild.IsDefaultNodeWithHasher(hasher) {
 		rChild = nil
 	}
 
@@ -119,38 +142,39 @@ func NewInterimCompactifiedNode(height int, lChild, rChild *Node) *Node {
 	// CASE (b): one child is a compactified leaf (single allocated register) _and_ the other child represents
 	// an empty subtrie => in total we have one allocated register, which we represent as single leaf node
 	if rChild == nil && lChild.IsLeaf() {
-		h := hash.HashInterNode(lChild.hashValue, ledger.GetDefaultHashForHeight(lChild.height))
-		return &Node{height: height, path: lChild.path, payload: lChild.payload, hashValue: h}
+		h := hasher.HashInterNode(lChild.hashValue, hasher.GetDefaultHashForHeight(lChild.height))
+		return &Node{height: height, path: lChild.path, payload: lChild.payload, stored: lChild.stored, hashValue: h}
 	}
 	if lChild == nil && rChild.IsLeaf() {
-		h := hash.HashInterNode(ledger.GetDefaultHashForHeight(rChild.height), rChild.hashValue)
-		return &Node{height: height, path: rChild.path, payload: rChild.payload, hashValue: h}
+		h := hasher.HashInterNode(hasher.GetDefaultHashForHeight(rChild.height), rChild.hashValue)
+		return &Node{height: height, path: rChild.path, payload: rChild.payload, stored: rChild.stored, hashValue: h}
 	}
 
 	// CASE (b): both children contain some allocated registers => we can't compactify; return a full interim leaf
-	return NewInterimNode(height, lChild, rChild)
+	return NewInterimNodeWithHasher(height, lChild, rChild, hasher)
 }
 
 // IsDefaultNode returns true iff the sub-trie represented by this root node contains
 // only unallocated registers. This is the case, if the node is nil or the node's hash
 // is equal to the default hash value at the respective height.
 func (n *Node) IsDefaultNode() bool {
+	return n.IsDefaultNodeWithHasher(ledger.DefaultTrieHasher)
+}
+
+// IsDefaultNodeWithHasher is IsDefaultNode for nodes hashed with the given trie hasher.
+func (n *Node) IsDefaultNodeWithHasher(hasher *ledger.TrieHasher) bool {
 	if n == nil {
 		return true
 	}
-	return n.hashValue == ledger.GetDefaultHashForHeight(n.height)
+	return n.hashValue == hasher.GetDefaultHashForHeight(n.height)
 }
 
-// computeHash returns the hashValue of the node
-func (n *Node) computeHash() hash.Hash {
+// computeHash returns the hashValue of the node.
+// The payload of leaf nodes must be kept in memory, see computeLeafHash.
+func (n *Node) computeHash(hasher *ledger.TrieHasher) hash.Hash {
 	// check for leaf node
 	if n.lChild == nil && n.rChild == nil {
-		// if payload is non-nil, compute the hash based on the payload content
-		if n.payload != nil {
-			return ledger.ComputeCompactValue(hash.Hash(n.path), n.payload.Value(), n.height)
-		}
-		// if payload is nil, return the default hash
-		return ledger.GetDefaultHashForHeight(n.height)
+		return n.computeLeafHash(hasher, n.payload)
 	}
 
 	// this is an interim node at least one of lChild or rChild is not nil.
@@ -158,33 +182,68 @@ func (n *Node) computeHash() hash.Hash {
 	if n.lChild != nil {
 		h1 = n.lChild.Hash()
 	} else {
-		h1 = ledger.GetDefaultHashForHeight(n.height - 1)
+		h1 = hasher.GetDefaultHashForHeight(n.height - 1)
 	}
 
 	if n.rChild != nil {
 		h2 = n.rChild.Hash()
 	} else {
-		h2 = ledger.GetDefaultHashForHeight(n.height - 1)
+		h2 = hasher.GetDefaultHashForHeight(n.height - 1)
+	}
+	return hasher.HashInterNode(h1, h2)
+}
+
+// computeLeafHash returns the hashValue of the leaf node with the given payload
+func (n *Node) computeLeafHash(hasher *ledger.TrieHasher, payload *ledger.Payload) hash.Hash {
+	// if payload is non-nil, compute the hash based on the payload content
+	if payload != nil {
+		return hasher.ComputeCompactValue(hash.Hash(n.path), payload.Value(), n.height)
 	}
-	return hash.HashInterNode(h1, h2)
+	// if payload is nil, return the default hash
+	return hasher.GetDefaultHashForHeight(n.height)
 }
 
 // VerifyCachedHash verifies the hash of a node is valid
-func verifyCachedHashRecursive(n *Node) bool {
+func verifyCachedHashRecursive(n *Node, hasher *ledger.TrieHasher) bool {
 	if n == nil {
 		return true
 	}
-	if !verifyCachedHashRecursive(n.lChild) || !verifyCachedHashRecursive(n.rChild) {
+	if !verifyCachedHashRecursive(n.lChild, hasher) || !verifyCachedHashRecursive(n.rChild, hasher) {
 		return false
 	}
 
-	computedHash := n.computeHash()
-	return n.hashValue == computedHash
+	return n.VerifyHashWithHasher(hasher)
 }
 
 // VerifyCachedHash verifies the hash of a node is valid
 func (n *Node) VerifyCachedHash() bool {
-	return verifyCachedHashRecursive(n)
+	return verifyCachedHashRecursive(n, ledger.DefaultTrieHasher)
+}
+
+// VerifyCachedHashWithHasher verifies the hash of a node hashed with the given trie hasher is valid
+func (n *Node) VerifyCachedHashWithHasher(hasher *ledger.TrieHasher) bool {
+	return verifyCachedHashRecursive(n, hasher)
+}
+
+// VerifyHash verifies the cached hash of the node, without verifying the
+// cached hashes of its children. Verifying all nodes of a trie from the
+// leaves up is equivalent to VerifyCachedHash, but allows to visit nodes
+// shared between tries only once.
+func (n *Node) VerifyHash() bool {
+	return n.VerifyHashWithHasher(ledger.DefaultTrieHasher)
+}
+
+// VerifyHashWithHasher is VerifyHash for nodes hashed with the given trie hasher.
+// The hash of a leaf with a stored payload which can't be loaded isn't valid.
+func (n *Node) VerifyHashWithHasher(hasher *ledger.TrieHasher) bool {
+	if n.stored == nil {
+		return n.hashValue == n.computeHash(hasher)
+	}
+	payload, err := n.stored.get()
+	if err != nil {
+		return false
+	}
+	return n.hashValue == n.computeLeafHash(hasher, payload)
 }
 
 // Hash returns the Node's hash value.
@@ -210,9 +269,50 @@ func (n *Node) Path() *ledger.Path {
 }
 
 // Payload returns the the Node's payload.
+// If the payload is kept in a PayloadStore, it is loaded from the store.
+// No errors are expected during normal operations, as stored payloads are
+// kept until the tries holding the node are released, see ReleasePayloads.
 // Do NOT MODIFY returned slices!
-func (n *Node) Payload() *ledger.Payload {
-	return n.payload
+func (n *Node) Payload() (*ledger.Payload, error) {
+	if n.stored == nil {
+		return n.payload, nil
+	}
+	payload, err := n.stored.get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payload %x of node at path %x: %w", n.stored.key, n.path, err)
+	}
+	return payload, nil
+}
+
+// AppendEncodedPayload appends the Node's payload encoded with
+// ledger.EncodeAndAppendPayloadWithoutPrefix in the given version to buf.
+// Payloads kept in a PayloadStore are read without adding them to the
+// store's working set, see PayloadStore.GetEncoded.
+// No errors are expected during normal operations.
+func (n *Node) AppendEncodedPayload(buf []byte, version uint16) ([]byte, error) {
+	if n.stored == nil {
+		return ledger.EncodeAndAppendPayloadWithoutPrefix(buf, n.payload, version), nil
+	}
+	buf, err := n.stored.appendEncoded(buf, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payload %x of node at path %x: %w", n.stored.key, n.path, err)
+	}
+	return buf, nil
+}
+
+// IsPayloadStored returns true if the Node's payload is kept in a PayloadStore
+// instead of memory.
+func (n *Node) IsPayloadStored() bool {
+	return n.stored != nil
+}
+
+// StoredPayloadKey returns the key of the Node's payload in its PayloadStore,
+// and false if the payload is kept in memory.
+func (n *Node) StoredPayloadKey() (hash.Hash, bool) {
+	if n.stored == nil {
+		return hash.DummyHash, false
+	}
+	return n.stored.key, true
 }
 
 // LeftChild returns the the Node's left child.
@@ -242,8 +342,10 @@ func (n *Node) FmtStr(prefix string, subpath string) string {
 		left = fmt.Sprintf("\n%v", n.lChild.FmtStr(prefix+"\t", subpath+"0"))
 	}
 	payloadSize := 0
-	if n.payload != nil {
-		payloadSize = n.payload.Size()
+	if payload, err := n.Payload(); err != nil {
+		payloadSize = -1
+	} else if payload != nil {
+		payloadSize = payload.Size()
 	}
 	hashStr := hex.EncodeToString(n.hashValue[:])
 	hashStr = hashStr[:3] + "..." + hashStr[len(hashStr)-3:]
@@ -251,20 +353,27 @@ func (n *Node) FmtStr(prefix string, subpath string) string {
 }
 
 // AllPayloads returns the payload of this node and all payloads of the subtrie
-func (n *Node) AllPayloads() []ledger.Payload {
+// No errors are expected during normal operations.
+func (n *Node) AllPayloads() ([]ledger.Payload, error) {
 	return n.appendSubtreePayloads([]ledger.Payload{})
 }
 
 // appendSubtreePayloads appends the payloads of the subtree with this node as root
 // to the provided Payload slice. Follows same pattern as Go's native append method.
-func (n *Node) appendSubtreePayloads(result []ledger.Payload) []ledger.Payload {
+func (n *Node) appendSubtreePayloads(result []ledger.Payload) ([]ledger.Payload, error) {
 	if n == nil {
-		return result
+		return result, nil
 	}
 	if n.IsLeaf() {
-		return append(result, *n.Payload())
+		payload, err := n.Payload()
+		if err != nil {
+			return nil, err
+		}
+		return append(result, *payload), nil
+	}
+	result, err := n.lChild.appendSubtreePayloads(result)
+	if err != nil {
+		return nil, err
 	}
-	result = n.lChild.appendSubtreePayloads(result)
-	result = n.rChild.appendSubtreePayloads(result)
-	return result
+	return n.rChild.appendSubtreePayloads(result)
 }
@@ -100,7 +100,9 @@ func Test_AllPayloads(t *testing.T) {
 	n3 := node.NewLeaf(path, payload, 1)
 	n4 := node.NewInterimNode(1, n1, n2)
 	n5 := node.NewInterimNode(2, n4, n3)
-	require.Equal(t, 3, len(n5.AllPayloads()))
+	payloads, err := n5.AllPayloads()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(payloads))
 }
 
 func Test_VerifyCachedHash(t *testing.T) {
@@ -114,6 +116,25 @@ func Test_VerifyCachedHash(t *testing.T) {
 	require.True(t, n5.VerifyCachedHash())
 }
 
+func Test_VerifyHash(t *testing.T) {
+	path := testutils.PathByUint16(1)
+	payload := testutils.LightPayload(2, 3)
+	n1 := node.NewLeaf(path, payload, 0)
+	n2 := node.NewLeaf(path, payload, 0)
+	n3 := node.NewInterimNode(1, n1, n2)
+	require.True(t, n1.VerifyHash())
+	require.True(t, n3.VerifyHash())
+
+	// a leaf with a modified payload fails verification, but its parent
+	// is only verified against the cached hash of its children
+	modified := node.NewNode(0, nil, nil, path, testutils.LightPayload(2, 4), n1.Hash())
+	require.False(t, modified.VerifyHash())
+
+	n4 := node.NewNode(1, modified, n2, ledger.DummyPath, nil, n3.Hash())
+	require.True(t, n4.VerifyHash())
+	require.False(t, n4.VerifyCachedHash())
+}
+
 // Test_Compactify_EmptySubtrie tests constructing an interim node
 // with pruning/compactification, where both children are empty. We expect
 // the compactified node to be nil, as it represents a completely empty subtrie
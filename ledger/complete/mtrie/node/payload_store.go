@@ -0,0 +1,363 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
+)
+
+// PayloadEncodingVersion is the version of the encoded payloads passed to PayloadStore.Put,
+// see ledger.EncodeAndAppendPayloadWithoutPrefix. It is the version used by checkpoints,
+// so that payloads can be copied between checkpoints and stores without decoding them.
+const PayloadEncodingVersion = 1
+
+// payloadBatchSize is the number of payloads a PayloadBatch stores at once.
+const payloadBatchSize = 10_000
+
+// PayloadStore keeps leaf payloads outside of the trie, to reduce the memory used by
+// the nodes of large tries. Payloads are addressed by the hash of the leaf node they
+// were stored for, so leaves with the same path and payload share the stored payload.
+//
+// The store counts the references to each payload: every leaf node storing a payload,
+// or reading it from a checkpoint, adds a reference, which is released explicitly once
+// no trie holding the node is used anymore (see ReleasePayloads). Payloads without
+// references are only removed once no kept checkpoint can reference them, which is
+// tracked by generations: checkpoints are created from tries held when NextGeneration
+// is called, and payloads released in earlier generations are removed by RemoveReleased.
+//
+// Implementations must be concurrency safe.
+type PayloadStore interface {
+	// Put stores the given payloads, encoded with ledger.EncodeAndAppendPayloadWithoutPrefix
+	// in PayloadEncodingVersion, under the given keys, and adds a reference to each key.
+	// No errors are expected during normal operations.
+	Put(keys []hash.Hash, encodedPayloads [][]byte) error
+
+	// Acquire adds a reference to each of the given keys, which are already stored,
+	// for example by nodes read from a checkpoint referencing the store.
+	// No errors are expected during normal operations.
+	Acquire(keys []hash.Hash) error
+
+	// Get returns the payload with the given key.
+	// No errors are expected during normal operations, as payloads are never removed
+	// while being referenced by a node.
+	Get(key hash.Hash) (*ledger.Payload, error)
+
+	// GetEncoded appends the payload with the given key encoded with
+	// ledger.EncodeAndAppendPayloadWithoutPrefix in the given version to buf.
+	// Unlike Get, GetEncoded is meant for reading all payloads once (e.g. for
+	// checkpointing) and should not change which payloads are kept in memory.
+	// No errors are expected during normal operations.
+	GetEncoded(key hash.Hash, version uint16, buf []byte) ([]byte, error)
+
+	// Release removes a reference to each of the given keys. Payloads without
+	// references are released in the current generation.
+	// No errors are expected during normal operations.
+	Release(keys []hash.Hash) error
+
+	// NextGeneration starts a new generation of released payloads, and returns it.
+	NextGeneration() uint64
+
+	// RemoveReleased removes the payloads released before the given generation,
+	// which haven't been referenced again since.
+	// No errors are expected during normal operations.
+	RemoveReleased(generation uint64) error
+
+	// ReleaseUnreferenced releases all stored payloads without references in the
+	// current generation, for example payloads left by a previous process once
+	// the tries have been restored.
+	// No errors are expected during normal operations.
+	ReleaseUnreferenced() error
+}
+
+// storedPayload references a payload kept in a PayloadStore. Each storedPayload holds
+// one reference to its payload, which is shared by all nodes referencing the same
+// storedPayload (e.g. compactified copies of the leaf), and released by ReleasePayloads.
+type storedPayload struct {
+	store PayloadStore
+	key   hash.Hash
+}
+
+func (p *storedPayload) get() (*ledger.Payload, error) {
+	return p.store.Get(p.key)
+}
+
+func (p *storedPayload) appendEncoded(buf []byte, version uint16) ([]byte, error) {
+	return p.store.GetEncoded(p.key, version, buf)
+}
+
+// PayloadBatch moves the payloads of leaf nodes into a PayloadStore, storing
+// payloadBatchSize payloads at once. Nodes added to the batch must not be
+// accessed concurrently until the batch has been flushed.
+//
+// Not concurrency safe.
+type PayloadBatch struct {
+	store   PayloadStore
+	nodes   []*Node
+	keys    []hash.Hash
+	encoded [][]byte
+
+	// referenced are nodes read with a key of a payload already in the store
+	referenced    []*Node
+	referenceKeys []hash.Hash
+}
+
+// NewPayloadBatch returns a new batch storing payloads in the given store.
+func NewPayloadBatch(store PayloadStore) *PayloadBatch {
+	return &PayloadBatch{store: store}
+}
+
+// Add adds the payload of the given leaf node to the batch. Leaves without payload,
+// and leaves with payloads already kept in a store are skipped.
+// No errors are expected during normal operations.
+func (b *PayloadBatch) Add(n *Node) error {
+	if n.stored != nil || n.payload == nil {
+		return nil
+	}
+	return b.AddEncoded(n, ledger.EncodeAndAppendPayloadWithoutPrefix(nil, n.payload, PayloadEncodingVersion))
+}
+
+// AddEncoded adds the given leaf node with the given payload, encoded with
+// ledger.EncodeAndAppendPayloadWithoutPrefix in PayloadEncodingVersion, to the batch.
+// This allows to store payloads read from a checkpoint without decoding them, in which
+// case the node is created without payload, and must not be used until the batch is flushed.
+// The batch takes ownership of the encoded payload.
+// No errors are expected during normal operations.
+func (b *PayloadBatch) AddEncoded(n *Node, encodedPayload []byte) error {
+	b.nodes = append(b.nodes, n)
+	b.keys = append(b.keys, n.hashValue)
+	b.encoded = append(b.encoded, encodedPayload)
+
+	if len(b.nodes)+len(b.referenced) < payloadBatchSize {
+		return nil
+	}
+	return b.Flush()
+}
+
+// AddReference adds the given leaf node, created without payload, referencing the
+// payload with the given key already in the store, for example from a checkpoint
+// written with payload references. The node must not be used until the batch is flushed.
+// No errors are expected during normal operations.
+func (b *PayloadBatch) AddReference(n *Node, key hash.Hash) error {
+	b.referenced = append(b.referenced, n)
+	b.referenceKeys = append(b.referenceKeys, key)
+
+	if len(b.nodes)+len(b.referenced) < payloadBatchSize {
+		return nil
+	}
+	return b.Flush()
+}
+
+// Len returns the number of payloads which are not stored yet.
+func (b *PayloadBatch) Len() int {
+	return len(b.nodes) + len(b.referenced)
+}
+
+// Flush stores all payloads of the batch, and replaces the payloads of their
+// nodes by references to the store.
+// No errors are expected during normal operations.
+func (b *PayloadBatch) Flush() error {
+	if len(b.nodes) > 0 {
+		err := b.store.Put(b.keys, b.encoded)
+		if err != nil {
+			return fmt.Errorf("cannot store %d payloads: %w", len(b.encoded), err)
+		}
+
+		for i, n := range b.nodes {
+			n.stored = &storedPayload{store: b.store, key: b.keys[i]}
+			n.payload = nil
+		}
+
+		b.nodes = b.nodes[:0]
+		b.keys = b.keys[:0]
+		b.encoded = b.encoded[:0]
+	}
+
+	if len(b.referenced) > 0 {
+		err := b.store.Acquire(b.referenceKeys)
+		if err != nil {
+			return fmt.Errorf("cannot reference %d stored payloads: %w", len(b.referenceKeys), err)
+		}
+
+		for i, n := range b.referenced {
+			n.stored = &storedPayload{store: b.store, key: b.referenceKeys[i]}
+		}
+
+		b.referenced = b.referenced[:0]
+		b.referenceKeys = b.referenceKeys[:0]
+	}
+
+	return nil
+}
+
+// StorePayloads moves the payloads of all leaves of the trie with the given root into
+// the given store, except for the subtries which are shared with the trie with root
+// parent at the same position. Leaves with payloads already kept in a store are skipped.
+//
+// Nodes are supposed to be treated as immutable data structures, so StorePayloads
+// must only be called on tries which are not yet accessed concurrently, for example
+// right after the trie has been created by an update or read from a checkpoint.
+// Shared subtries should already have their payloads stored, so only the part of the
+// trie created by an update is traversed.
+//
+// No errors are expected during normal operations.
+func StorePayloads(root *Node, parent *Node, store PayloadStore) error {
+	batch := NewPayloadBatch(store)
+	err := addSubtriePayloads(batch, root, parent)
+	if err != nil {
+		return err
+	}
+	return batch.Flush()
+}
+
+func addSubtriePayloads(batch *PayloadBatch, root *Node, parent *Node) error {
+	if root == nil || root == parent {
+		return nil
+	}
+
+	if root.IsLeaf() {
+		return batch.Add(root)
+	}
+
+	var parentLeft, parentRight *Node
+	if parent != nil && parent.height == root.height {
+		parentLeft, parentRight = parent.lChild, parent.rChild
+	}
+
+	err := addSubtriePayloads(batch, root.lChild, parentLeft)
+	if err != nil {
+		return err
+	}
+	return addSubtriePayloads(batch, root.rChild, parentRight)
+}
+
+// ReleasePayloads releases the stored payloads of the tries with the given roots,
+// which aren't referenced by any of the tries with the remaining roots.
+// The released tries must not be used anymore, as their payloads can be removed
+// from the store once no checkpoint references them.
+//
+// Tries share most of their nodes, so the released tries are traversed together with
+// the nodes of the remaining tries at the same position, skipping shared subtries.
+//
+// No errors are expected during normal operations.
+func ReleasePayloads(roots []*Node, remaining []*Node, store PayloadStore) error {
+	var others []*Node
+	for _, n := range remaining {
+		if n != nil {
+			others = append(others, n)
+		}
+	}
+
+	released := make(map[*storedPayload]struct{})
+	for _, root := range roots {
+		collectUnreferencedPayloads(root, others, released)
+	}
+
+	if len(released) == 0 {
+		return nil
+	}
+
+	keys := make([]hash.Hash, 0, len(released))
+	for p := range released {
+		keys = append(keys, p.key)
+	}
+	return store.Release(keys)
+}
+
+// collectUnreferencedPayloads adds the stored payloads of the subtrie with the given root,
+// which aren't referenced by the given nodes at the same position, to released.
+// Compactified leaves of the given nodes are passed down to the children at their path,
+// as they can reference the payload of any leaf below them.
+func collectUnreferencedPayloads(root *Node, others []*Node, released map[*storedPayload]struct{}) {
+	if root == nil {
+		return
+	}
+	for _, o := range others {
+		if o == root {
+			return
+		}
+	}
+
+	if root.IsLeaf() {
+		if root.stored == nil {
+			return
+		}
+		if _, ok := released[root.stored]; ok {
+			return
+		}
+		for _, o := range others {
+			if referencesPayload(o, root.path, root.stored) {
+				return
+			}
+		}
+		released[root.stored] = struct{}{}
+		return
+	}
+
+	left, right := childrenAtPosition(others, ledger.NodeMaxHeight-root.height)
+	collectUnreferencedPayloads(root.lChild, left, released)
+	collectUnreferencedPayloads(root.rChild, right, released)
+}
+
+// childrenAtPosition returns the distinct left and right children of the given nodes
+// at the given depth. Compactified leaves are returned as the child at their path.
+func childrenAtPosition(nodes []*Node, depth int) (left []*Node, right []*Node) {
+	// tries mostly share their nodes, so only few distinct nodes are at the same
+	// position, except close to the root of many tries
+	var seen map[*Node]struct{}
+	if len(nodes) > 16 {
+		seen = make(map[*Node]struct{}, 2*len(nodes))
+	}
+	appendUnique := func(children []*Node, n *Node) []*Node {
+		if n == nil {
+			return children
+		}
+		if seen != nil {
+			if _, ok := seen[n]; ok {
+				return children
+			}
+			seen[n] = struct{}{}
+			return append(children, n)
+		}
+		for _, c := range children {
+			if c == n {
+				return children
+			}
+		}
+		return append(children, n)
+	}
+
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if n.IsLeaf() {
+			if bitutils.ReadBit(n.path[:], depth) == 0 {
+				left = appendUnique(left, n)
+			} else {
+				right = appendUnique(right, n)
+			}
+			continue
+		}
+		left = appendUnique(left, n.lChild)
+		right = appendUnique(right, n.rChild)
+	}
+	return left, right
+}
+
+// referencesPayload returns true if the leaf at the given path in the subtrie with
+// the given root references the given stored payload.
+func referencesPayload(root *Node, path ledger.Path, stored *storedPayload) bool {
+	for n := root; n != nil; {
+		if n.IsLeaf() {
+			return n.stored == stored
+		}
+		if bitutils.ReadBit(path[:], ledger.NodeMaxHeight-n.height) == 0 {
+			n = n.lChild
+		} else {
+			n = n.rChild
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package trie
+
+import (
+	"bytes"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// Diff returns the payloads which differ between the tries `from` and `to`, ordered by path.
+// Both tries are traversed in parallel, and subtries with identical hashes are skipped.
+// Therefore, the cost of Diff is proportional to the size of the difference instead of
+// the size of the tries.
+// Payloads with empty values are considered as non-existent, as they are pruned by updates.
+// Returned payloads are shared with the tries, and must not be modified.
+// Both tries must be hashed with the same trie hasher.
+// No errors are expected during normal operations.
+// Concurrency safe (as Tries are immutable structures by convention)
+func Diff(from *MTrie, to *MTrie) ([]ledger.PayloadDiff, error) {
+	return diffNodes(from.root, to.root, nil, from.Hasher())
+}
+
+// diffNodes appends the differences between the subtries with roots `from` and `to`
+// to the provided slice. Both nodes must be at the same height.
+// Follows same pattern as Go's native append method.
+func diffNodes(from *node.Node, to *node.Node, diffs []ledger.PayloadDiff, hasher *ledger.TrieHasher) ([]ledger.PayloadDiff, error) {
+	if from.IsDefaultNodeWithHasher(hasher) && to.IsDefaultNodeWithHasher(hasher) {
+		return diffs, nil
+	}
+	if from != nil && to != nil && from.Hash() == to.Hash() {
+		return diffs, nil
+	}
+
+	// A leaf holds at most one register, so the payloads of the other subtrie are
+	// compared directly. This also covers compactified leaves, which can't be
+	// traversed in parallel with an interim node.
+	if from.IsLeaf() || to.IsLeaf() {
+		fromLeaves, err := appendLeaves(from, nil)
+		if err != nil {
+			return nil, err
+		}
+		toLeaves, err := appendLeaves(to, nil)
+		if err != nil {
+			return nil, err
+		}
+		return diffLeaves(fromLeaves, toLeaves, diffs), nil
+	}
+
+	diffs, err := diffNodes(from.LeftChild(), to.LeftChild(), diffs, hasher)
+	if err != nil {
+		return nil, err
+	}
+	return diffNodes(from.RightChild(), to.RightChild(), diffs, hasher)
+}
+
+// leaf is the path and payload of a leaf node.
+type leaf struct {
+	path    ledger.Path
+	payload *ledger.Payload
+}
+
+// diffLeaves appends the differences between two lists of leaves ordered by path.
+func diffLeaves(from []leaf, to []leaf, diffs []ledger.PayloadDiff) []ledger.PayloadDiff {
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		cmp := 0
+		switch {
+		case i == len(from):
+			cmp = 1
+		case j == len(to):
+			cmp = -1
+		default:
+			cmp = bytes.Compare(from[i].path[:], to[j].path[:])
+		}
+
+		switch {
+		case cmp < 0:
+			diffs = append(diffs, ledger.PayloadDiff{Path: from[i].path, From: from[i].payload})
+			i++
+		case cmp > 0:
+			diffs = append(diffs, ledger.PayloadDiff{Path: to[j].path, To: to[j].payload})
+			j++
+		default:
+			fromPayload, toPayload := from[i].payload, to[j].payload
+			if !fromPayload.Equals(toPayload) {
+				diffs = append(diffs, ledger.PayloadDiff{Path: from[i].path, From: fromPayload, To: toPayload})
+			}
+			i++
+			j++
+		}
+	}
+	return diffs
+}
+
+// appendLeaves appends the leaves with non-empty payloads of the subtrie with root n,
+// ordered by path, to the provided slice.
+// Follows same pattern as Go's native append method.
+func appendLeaves(n *node.Node, leaves []leaf) ([]leaf, error) {
+	err := iterateLeaves(n, func(path ledger.Path, payload *ledger.Payload) error {
+		leaves = append(leaves, leaf{path: path, payload: payload})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
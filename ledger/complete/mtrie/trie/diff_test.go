@@ -0,0 +1,116 @@
+package trie_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+func TestDiff(t *testing.T) {
+	emptyTrie := trie.NewEmptyMTrie()
+
+	paths := testutils.RandomPaths(100)
+	payloads := testutils.RandomPayloads(len(paths), 1, 20)
+
+	fromPayloads := make([]ledger.Payload, len(payloads))
+	for i, p := range payloads {
+		fromPayloads[i] = *p
+	}
+
+	// the paths and payloads are permuted in place by the update, so it is given copies
+	from, _, err := trie.NewTrieWithUpdatedRegisters(emptyTrie, append([]ledger.Path(nil), paths...), fromPayloads, true)
+	require.NoError(t, err)
+
+	t.Run("identical tries", func(t *testing.T) {
+		diffs, err := trie.Diff(from, from)
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+
+		diffs, err = trie.Diff(emptyTrie, emptyTrie)
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+	})
+
+	t.Run("empty trie", func(t *testing.T) {
+		added, err := trie.Diff(emptyTrie, from)
+		require.NoError(t, err)
+		require.Len(t, added, len(paths))
+		for _, d := range added {
+			require.True(t, d.IsAdded())
+			require.False(t, d.IsRemoved())
+		}
+
+		removed, err := trie.Diff(from, emptyTrie)
+		require.NoError(t, err)
+		require.Len(t, removed, len(paths))
+		for _, d := range removed {
+			require.True(t, d.IsRemoved())
+			require.False(t, d.IsAdded())
+		}
+	})
+
+	t.Run("added, changed and removed payloads", func(t *testing.T) {
+		// change the first 10 payloads, remove the next 10 payloads, and add 10 payloads
+		changedPaths := paths[:10]
+		changedPayloads := make([]ledger.Payload, 0, 30)
+		for range changedPaths {
+			changedPayloads = append(changedPayloads, *testutils.RandomPayload(21, 30))
+		}
+
+		removedPaths := paths[10:20]
+		for range removedPaths {
+			changedPayloads = append(changedPayloads, *ledger.EmptyPayload())
+		}
+
+		addedPaths := testutils.RandomPaths(10)
+		for range addedPaths {
+			changedPayloads = append(changedPayloads, *testutils.RandomPayload(1, 20))
+		}
+
+		updatedPaths := append(append(append([]ledger.Path{}, changedPaths...), removedPaths...), addedPaths...)
+
+		to, _, err := trie.NewTrieWithUpdatedRegisters(from, updatedPaths, append([]ledger.Payload(nil), changedPayloads...), true)
+		require.NoError(t, err)
+
+		expected := make([]ledger.PayloadDiff, 0, len(updatedPaths))
+		for i, path := range changedPaths {
+			expected = append(expected, ledger.PayloadDiff{Path: path, From: payloads[i], To: &changedPayloads[i]})
+		}
+		for i, path := range removedPaths {
+			expected = append(expected, ledger.PayloadDiff{Path: path, From: payloads[10+i]})
+		}
+		for i, path := range addedPaths {
+			expected = append(expected, ledger.PayloadDiff{Path: path, To: &changedPayloads[20+i]})
+		}
+		sort.Slice(expected, func(i, j int) bool {
+			return bytes.Compare(expected[i].Path[:], expected[j].Path[:]) < 0
+		})
+
+		diffs, err := trie.Diff(from, to)
+		require.NoError(t, err)
+		require.Len(t, diffs, len(expected))
+		for i := range expected {
+			require.Equal(t, expected[i].Path, diffs[i].Path)
+			require.True(t, expected[i].From.Equals(diffs[i].From))
+			require.True(t, expected[i].To.Equals(diffs[i].To))
+			require.Equal(t, expected[i].IsAdded(), diffs[i].IsAdded())
+			require.Equal(t, expected[i].IsRemoved(), diffs[i].IsRemoved())
+		}
+
+		// diff in the other direction swaps the payloads
+		reversed, err := trie.Diff(to, from)
+		require.NoError(t, err)
+		require.Len(t, reversed, len(expected))
+		for i := range expected {
+			require.Equal(t, expected[i].Path, reversed[i].Path)
+			require.True(t, expected[i].From.Equals(reversed[i].To))
+			require.True(t, expected[i].To.Equals(reversed[i].From))
+		}
+	})
+}
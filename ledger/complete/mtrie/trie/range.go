@@ -0,0 +1,106 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// IteratePayloadsWithPrefix calls fn for each non-empty payload whose path starts
+// with the given prefix, ordered by path. Iteration stops at the first error returned by fn.
+// Only the subtrie holding the prefix is traversed.
+// Payloads are shared with the trie, and must not be modified.
+// Concurrency safe (as Tries are immutable structures by convention)
+func (mt *MTrie) IteratePayloadsWithPrefix(prefix []byte, fn func(path ledger.Path, payload *ledger.Payload) error) error {
+	if len(prefix) > ledger.PathLen {
+		return fmt.Errorf("prefix length (%d) exceeds path length (%d)", len(prefix), ledger.PathLen)
+	}
+
+	n, _ := findPrefixSubtrie(mt.root, prefix, nil, mt.Hasher())
+
+	return iterateLeaves(n, func(path ledger.Path, payload *ledger.Payload) error {
+		// the subtrie can be a compactified leaf of a path without the prefix
+		if !bytes.HasPrefix(path[:], prefix) {
+			return nil
+		}
+		return fn(path, payload)
+	})
+}
+
+// RangeProof returns a proof that the payloads stored under the given prefix are complete.
+// The proof contains the payloads of the smallest subtrie holding all paths with the prefix,
+// which can be a single compactified leaf, and the sibling hashes up to the root.
+// Payloads are shared with the trie, and must not be modified.
+// No errors are expected during normal operations.
+// Concurrency safe (as Tries are immutable structures by convention)
+func (mt *MTrie) RangeProof(prefix []byte) (*ledger.TrieRangeProof, error) {
+	if len(prefix) > ledger.PathLen {
+		return nil, fmt.Errorf("prefix length (%d) exceeds path length (%d)", len(prefix), ledger.PathLen)
+	}
+
+	proof := ledger.NewTrieRangeProof(prefix)
+
+	n, depth := findPrefixSubtrie(mt.root, prefix, proof, mt.Hasher())
+	proof.Steps = uint16(depth)
+
+	err := iterateLeaves(n, func(path ledger.Path, payload *ledger.Payload) error {
+		proof.Paths = append(proof.Paths, path)
+		proof.Payloads = append(proof.Payloads, payload)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// iterateLeaves calls fn with the path and payload of each leaf with a non-empty
+// payload of the subtrie rooted at n, ordered by path, without collecting the leaves first.
+// Iteration stops at the first error returned by fn, or when a payload can't be loaded.
+func iterateLeaves(n *node.Node, fn func(path ledger.Path, payload *ledger.Payload) error) error {
+	if n == nil {
+		return nil
+	}
+	if n.IsLeaf() {
+		payload, err := n.Payload()
+		if err != nil {
+			return err
+		}
+		if payload.IsEmpty() {
+			return nil
+		}
+		return fn(*n.Path(), payload)
+	}
+	err := iterateLeaves(n.LeftChild(), fn)
+	if err != nil {
+		return err
+	}
+	return iterateLeaves(n.RightChild(), fn)
+}
+
+// findPrefixSubtrie descends from head along the prefix, until it reaches the
+// subtrie holding all paths with the prefix, a leaf or an empty subtrie.
+// It returns the reached node (possibly nil) and its depth. If proof isn't nil,
+// the non-default sibling hashes along the way are added to it.
+func findPrefixSubtrie(head *node.Node, prefix []byte, proof *ledger.TrieRangeProof, hasher *ledger.TrieHasher) (*node.Node, int) {
+	depth := 0
+	for head != nil && !head.IsLeaf() && depth < 8*len(prefix) {
+		child, sibling := head.LeftChild(), head.RightChild()
+		if bitutils.ReadBit(prefix, depth) == 1 {
+			child, sibling = sibling, child
+		}
+
+		// in proofs, we only provide non-default sibling hashes
+		if proof != nil && !sibling.IsDefaultNodeWithHasher(hasher) {
+			bitutils.SetBit(proof.Flags, depth)
+			proof.Interims = append(proof.Interims, sibling.Hash())
+		}
+
+		head = child
+		depth++
+	}
+	return head, depth
+}
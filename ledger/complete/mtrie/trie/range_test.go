@@ -0,0 +1,137 @@
+package trie_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/proof"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+func TestRangeProof(t *testing.T) {
+	prefix := []byte{0x12, 0x34}
+
+	// paths with the prefix, along with random paths
+	prefixedPaths := testutils.RandomPaths(10)
+	for i := range prefixedPaths {
+		copy(prefixedPaths[i][:], prefix)
+	}
+	paths := append(testutils.RandomPaths(100), prefixedPaths...)
+
+	payloads := make([]ledger.Payload, len(paths))
+	for i := range paths {
+		payloads[i] = *testutils.RandomPayload(1, 20)
+	}
+
+	// the last prefixed register is unallocated, but kept in the trie
+	payloads[len(payloads)-1] = *ledger.EmptyPayload()
+
+	tr, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, payloads, false)
+	require.NoError(t, err)
+	state := ledger.State(tr.RootHash())
+
+	expected := append([]ledger.Path{}, prefixedPaths[:len(prefixedPaths)-1]...)
+	sort.Slice(expected, func(i, j int) bool {
+		return bytes.Compare(expected[i][:], expected[j][:]) < 0
+	})
+
+	t.Run("iterate payloads with prefix", func(t *testing.T) {
+		var iterated []ledger.Path
+		err := tr.IteratePayloadsWithPrefix(prefix, func(path ledger.Path, payload *ledger.Payload) error {
+			require.False(t, payload.IsEmpty())
+			iterated = append(iterated, path)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, iterated)
+
+		count := 0
+		err = tr.IteratePayloadsWithPrefix(nil, func(ledger.Path, *ledger.Payload) error {
+			count++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, len(paths)-1, count)
+
+		err = tr.IteratePayloadsWithPrefix(make([]byte, ledger.PathLen+1), nil)
+		require.Error(t, err)
+	})
+
+	t.Run("range proof", func(t *testing.T) {
+		p, err := tr.RangeProof(prefix)
+		require.NoError(t, err)
+		require.True(t, proof.VerifyTrieRangeProof(p, state))
+
+		inRange, _ := p.InRange()
+		require.Equal(t, expected, inRange)
+
+		decoded, err := ledger.DecodeTrieRangeProof(ledger.EncodeTrieRangeProof(p))
+		require.NoError(t, err)
+		require.True(t, proof.VerifyTrieRangeProof(decoded, state))
+	})
+
+	t.Run("range proof of a single path", func(t *testing.T) {
+		p, err := tr.RangeProof(expected[0][:])
+		require.NoError(t, err)
+		require.True(t, proof.VerifyTrieRangeProof(p, state))
+
+		inRange, _ := p.InRange()
+		require.Equal(t, expected[:1], inRange)
+	})
+
+	t.Run("range proof of an empty range", func(t *testing.T) {
+		// find a prefix without any path
+		empty := []byte{0x00, 0x00}
+		for ; ; empty[1]++ {
+			found := false
+			for _, path := range paths {
+				found = found || bytes.HasPrefix(path[:], empty)
+			}
+			if !found {
+				break
+			}
+		}
+
+		p, err := tr.RangeProof(empty)
+		require.NoError(t, err)
+		require.True(t, proof.VerifyTrieRangeProof(p, state))
+
+		inRange, _ := p.InRange()
+		require.Empty(t, inRange)
+	})
+
+	t.Run("tampered range proofs", func(t *testing.T) {
+		p, err := tr.RangeProof(prefix)
+		require.NoError(t, err)
+
+		// missing payload
+		missing := *p
+		missing.Paths = p.Paths[1:]
+		missing.Payloads = p.Payloads[1:]
+		require.False(t, proof.VerifyTrieRangeProof(&missing, state))
+
+		// modified payload
+		modified := *p
+		modified.Payloads = append([]*ledger.Payload{testutils.RandomPayload(1, 20)}, p.Payloads[1:]...)
+		require.False(t, proof.VerifyTrieRangeProof(&modified, state))
+
+		// unordered paths
+		unordered := *p
+		unordered.Paths = append([]ledger.Path{p.Paths[1], p.Paths[0]}, p.Paths[2:]...)
+		unordered.Payloads = append([]*ledger.Payload{p.Payloads[1], p.Payloads[0]}, p.Payloads[2:]...)
+		require.False(t, proof.VerifyTrieRangeProof(&unordered, state))
+
+		// other prefix
+		other := *p
+		other.Prefix = []byte{0x12, 0x35}
+		require.False(t, proof.VerifyTrieRangeProof(&other, state))
+
+		// other state
+		require.False(t, proof.VerifyTrieRangeProof(p, ledger.State(trie.EmptyTrieRootHash())))
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/bitutils"
 	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/testutils"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -701,13 +702,15 @@ func Test_Pruning(t *testing.T) {
 				queryPaths = append(queryPaths, path)
 			}
 
-			payloads := activeTrie.UnsafeRead(queryPaths)
+			payloads, err := activeTrie.UnsafeRead(queryPaths)
+			require.NoError(t, err)
 			for i, pp := range payloads {
 				expectedPayload := allPaths[queryPaths[i]]
 				require.True(t, pp.Equals(&expectedPayload))
 			}
 
-			payloads = activeTrieWithPruning.UnsafeRead(queryPaths)
+			payloads, err = activeTrieWithPruning.UnsafeRead(queryPaths)
+			require.NoError(t, err)
 			for i, pp := range payloads {
 				expectedPayload := allPaths[queryPaths[i]]
 				require.True(t, pp.Equals(&expectedPayload))
@@ -730,7 +733,8 @@ func TestValueSizes(t *testing.T) {
 	t.Run("empty trie", func(t *testing.T) {
 		path := testutils.PathByUint16LeftPadded(0)
 		pathsToGetValueSize := []ledger.Path{path}
-		sizes := emptyTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err := emptyTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, 0, sizes[0])
 	})
@@ -752,7 +756,8 @@ func TestValueSizes(t *testing.T) {
 
 		pathsToGetValueSize := []ledger.Path{path1, path2}
 
-		sizes := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, payload1.Value().Size(), sizes[0])
 		require.Equal(t, 0, sizes[1])
@@ -794,7 +799,8 @@ func TestValueSizes(t *testing.T) {
 		}
 
 		// Test value sizes for a mix of existent and non-existent paths.
-		sizes := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		for i, p := range pathsToGetValueSize {
 			switch p {
@@ -810,13 +816,15 @@ func TestValueSizes(t *testing.T) {
 
 		// Test value size for a single existent path
 		pathsToGetValueSize = []ledger.Path{path1}
-		sizes = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, payload1.Value().Size(), sizes[0])
 
 		// Test value size for a single non-existent path
 		pathsToGetValueSize = []ledger.Path{testutils.PathByUint16(3 << 12)}
-		sizes = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, 0, sizes[0])
 	})
@@ -845,7 +853,8 @@ func TestValueSizesWithDuplicatePaths(t *testing.T) {
 		path1, path2, path3,
 	}
 
-	sizes := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+	sizes, err := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+	require.NoError(t, err)
 	require.Equal(t, len(pathsToGetValueSize), len(sizes))
 	for i, p := range pathsToGetValueSize {
 		switch p {
@@ -1108,7 +1117,8 @@ func TestReadSinglePayload(t *testing.T) {
 		savedRootHash := emptyTrie.RootHash()
 
 		path := testutils.PathByUint16LeftPadded(0)
-		payload := emptyTrie.ReadSinglePayload(path)
+		payload, err := emptyTrie.ReadSinglePayload(path)
+		require.NoError(t, err)
 		require.True(t, payload.IsEmpty())
 		require.Equal(t, savedRootHash, emptyTrie.RootHash())
 	})
@@ -1129,13 +1139,15 @@ func TestReadSinglePayload(t *testing.T) {
 		savedRootHash := newTrie.RootHash()
 
 		// Get payload for existent path path
-		retPayload := newTrie.ReadSinglePayload(path1)
+		retPayload, err := newTrie.ReadSinglePayload(path1)
+		require.NoError(t, err)
 		require.Equal(t, payload1, retPayload)
 		require.Equal(t, savedRootHash, newTrie.RootHash())
 
 		// Get payload for non-existent path
 		path2 := testutils.PathByUint16LeftPadded(1)
-		retPayload = newTrie.ReadSinglePayload(path2)
+		retPayload, err = newTrie.ReadSinglePayload(path2)
+		require.NoError(t, err)
 		require.True(t, retPayload.IsEmpty())
 		require.Equal(t, savedRootHash, newTrie.RootHash())
 	})
@@ -1177,7 +1189,8 @@ func TestReadSinglePayload(t *testing.T) {
 		for i := 0; i < 16; i++ {
 			path := testutils.PathByUint16(uint16(i << 12))
 
-			retPayload := newTrie.ReadSinglePayload(path)
+			retPayload, err := newTrie.ReadSinglePayload(path)
+			require.NoError(t, err)
 			require.Equal(t, savedRootHash, newTrie.RootHash())
 			switch path {
 			case path1:
@@ -1190,3 +1203,48 @@ func TestReadSinglePayload(t *testing.T) {
 		}
 	})
 }
+
+// Test_TrieWithHasher tests that a trie hashed with another hasher has a different root hash
+// and proofs which only verify with that hasher.
+func Test_TrieWithHasher(t *testing.T) {
+	hasher, err := ledger.NewTrieHasher(hash.BLAKE3)
+	require.NoError(t, err)
+
+	emptyTrie := trie.NewEmptyMTrieWithHasher(hasher)
+	require.Equal(t, hash.BLAKE3, emptyTrie.Hasher().Type())
+	require.Equal(t, hasher.EmptyTrieRootHash(), emptyTrie.RootHash())
+	require.NotEqual(t, trie.EmptyTrieRootHash(), emptyTrie.RootHash())
+
+	paths := testutils.RandomPaths(100)
+	payloads := testutils.RandomPayloads(len(paths), 1, 20)
+	values := make([]ledger.Payload, len(payloads))
+	for i, p := range payloads {
+		values[i] = *p
+	}
+
+	// the paths and payloads are permuted in place by the update, so it is given copies
+	defaultTrie, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), append([]ledger.Path(nil), paths...), append([]ledger.Payload(nil), values...), true)
+	require.NoError(t, err)
+
+	updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(emptyTrie, append([]ledger.Path(nil), paths...), append([]ledger.Payload(nil), values...), true)
+	require.NoError(t, err)
+	require.Equal(t, hash.BLAKE3, updatedTrie.Hasher().Type())
+	require.NotEqual(t, defaultTrie.RootHash(), updatedTrie.RootHash())
+	require.True(t, updatedTrie.IsAValidTrie())
+	for i, path := range paths {
+		payload, err := updatedTrie.ReadSinglePayload(path)
+		require.NoError(t, err)
+		require.Equal(t, payloads[i], payload)
+	}
+
+	state := ledger.State(updatedTrie.RootHash())
+	batchProof, err := updatedTrie.UnsafeProofs(paths)
+	require.NoError(t, err)
+	require.True(t, proof.VerifyTrieBatchProofWithHasher(batchProof, state, hasher))
+	require.False(t, proof.VerifyTrieBatchProof(batchProof, state))
+
+	rangeProof, err := updatedTrie.RangeProof(paths[0][:1])
+	require.NoError(t, err)
+	require.True(t, proof.VerifyTrieRangeProofWithHasher(rangeProof, state, hasher))
+	require.False(t, proof.VerifyTrieRangeProof(rangeProof, state))
+}
@@ -0,0 +1,291 @@
+package payloadstore
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// DefaultCacheSize is the default number of payloads kept in memory.
+const DefaultCacheSize = 100_000
+
+// Store is a node.PayloadStore which keeps payloads in a badger database on disk,
+// and a working set of the most recently used payloads in memory.
+//
+// Payloads are kept across restarts, as checkpoints of tries with stored payloads
+// reference the payloads instead of containing them. The database should not be
+// used for anything else.
+//
+// References to payloads are counted in memory, since they are added again when the
+// tries are restored from checkpoints and WAL segments on startup. Payloads without
+// references are removed from the database by RemoveReleased, once no kept checkpoint
+// can reference them.
+type Store struct {
+	db    *badger.DB
+	cache *lru.Cache
+
+	mu         sync.Mutex
+	refs       map[hash.Hash]uint32
+	generation uint64
+	released   []releasedPayload // in order of release, so ordered by generation
+}
+
+// releasedPayload is the key of a payload released in the given generation,
+// which isn't removed yet.
+type releasedPayload struct {
+	key        hash.Hash
+	generation uint64
+}
+
+var _ node.PayloadStore = (*Store)(nil)
+
+// Open opens the store in the given directory, and keeps up to cacheSize payloads in memory.
+// Previously stored payloads are kept, but aren't referenced until they are acquired again.
+func Open(dir string, cacheSize int) (*Store, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create payload store dir: %w", err)
+	}
+
+	opts := badger.
+		DefaultOptions(dir).
+		WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not open payload store: %w", err)
+	}
+
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("could not create payload cache: %w", err)
+	}
+
+	return &Store{
+		db:    db,
+		cache: cache,
+		refs:  make(map[hash.Hash]uint32),
+	}, nil
+}
+
+// Put stores the given encoded payloads under the given keys in a single batch, and
+// adds a reference to each key. Payloads which are already referenced aren't written again.
+// No errors are expected during normal operations.
+func (s *Store) Put(keys []hash.Hash, encodedPayloads [][]byte) error {
+	if len(keys) != len(encodedPayloads) {
+		return fmt.Errorf("got %d keys for %d payloads", len(keys), len(encodedPayloads))
+	}
+
+	// payloads are written while holding the lock, so they can't be removed concurrently
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := s.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	for i := range keys {
+		if s.refs[keys[i]] > 0 {
+			continue
+		}
+		err := batch.Set(keys[i][:], encodedPayloads[i])
+		if err != nil {
+			return fmt.Errorf("could not store payload: %w", err)
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not store payloads: %w", err)
+	}
+
+	for _, key := range keys {
+		s.refs[key]++
+	}
+
+	return nil
+}
+
+// Acquire adds a reference to each of the given keys of stored payloads.
+// No errors are expected during normal operations.
+func (s *Store) Acquire(keys []hash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		s.refs[key]++
+	}
+	return nil
+}
+
+// Get returns the payload with the given key, and adds it to the payloads kept in memory.
+// No errors are expected during normal operations.
+func (s *Store) Get(key hash.Hash) (*ledger.Payload, error) {
+	if payload, ok := s.cache.Get(key); ok {
+		return payload.(*ledger.Payload), nil
+	}
+
+	var payload *ledger.Payload
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(key[:])
+		if err != nil {
+			return err
+		}
+		return item.Value(func(value []byte) error {
+			// value is only valid within the transaction, so it must be copied
+			payload, err = ledger.DecodePayloadWithoutPrefix(value, false, node.PayloadEncodingVersion)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load payload: %w", err)
+	}
+
+	s.cache.Add(key, payload)
+	return payload, nil
+}
+
+// GetEncoded appends the payload with the given key encoded with
+// ledger.EncodeAndAppendPayloadWithoutPrefix in the given version to buf.
+// Payloads are copied as stored if the version is node.PayloadEncodingVersion,
+// and the payloads kept in memory are not changed.
+// No errors are expected during normal operations.
+func (s *Store) GetEncoded(key hash.Hash, version uint16, buf []byte) ([]byte, error) {
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(key[:])
+		if err != nil {
+			return err
+		}
+		return item.Value(func(value []byte) error {
+			if version == node.PayloadEncodingVersion {
+				buf = append(buf, value...)
+				return nil
+			}
+
+			payload, err := ledger.DecodePayloadWithoutPrefix(value, true, node.PayloadEncodingVersion)
+			if err != nil {
+				return err
+			}
+			buf = ledger.EncodeAndAppendPayloadWithoutPrefix(buf, payload, version)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load payload: %w", err)
+	}
+
+	return buf, nil
+}
+
+// Release removes a reference to each of the given keys. Payloads without references
+// are removed from memory, and from disk by RemoveReleased.
+// No errors are expected during normal operations.
+func (s *Store) Release(keys []hash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		count := s.refs[key]
+		if count == 0 {
+			return fmt.Errorf("cannot release payload %x without references", key)
+		}
+		if count > 1 {
+			s.refs[key] = count - 1
+			continue
+		}
+
+		delete(s.refs, key)
+		s.cache.Remove(key)
+		s.released = append(s.released, releasedPayload{key: key, generation: s.generation})
+	}
+	return nil
+}
+
+// NextGeneration starts a new generation of released payloads, and returns it.
+func (s *Store) NextGeneration() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	return s.generation
+}
+
+// RemoveReleased removes the payloads released before the given generation from disk,
+// unless they have been referenced again since.
+// No errors are expected during normal operations.
+func (s *Store) RemoveReleased(generation uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := s.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	removed := 0
+	for ; removed < len(s.released) && s.released[removed].generation < generation; removed++ {
+		key := s.released[removed].key
+		if s.refs[key] > 0 {
+			continue
+		}
+		err := batch.Delete(s.released[removed].key[:])
+		if err != nil {
+			return fmt.Errorf("could not remove released payload: %w", err)
+		}
+	}
+
+	if removed == 0 {
+		return nil
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not remove released payloads: %w", err)
+	}
+
+	// copy the remaining keys, so the removed keys can be garbage collected
+	s.released = append([]releasedPayload(nil), s.released[removed:]...)
+	return nil
+}
+
+// ReleaseUnreferenced releases all stored payloads without references in the current
+// generation, so that payloads left by a previous process are removed by RemoveReleased.
+// No errors are expected during normal operations.
+func (s *Store) ReleaseUnreferenced() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			key, err := hash.ToHash(it.Item().Key())
+			if err != nil {
+				return fmt.Errorf("invalid payload key: %w", err)
+			}
+			if s.refs[key] > 0 {
+				continue
+			}
+			s.released = append(s.released, releasedPayload{key: key, generation: s.generation})
+		}
+		return nil
+	})
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	err := s.db.Close()
+	if err != nil {
+		return fmt.Errorf("could not close payload store: %w", err)
+	}
+	return nil
+}
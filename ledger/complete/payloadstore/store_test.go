@@ -0,0 +1,106 @@
+package payloadstore_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/payloadstore"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestStore(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		// a cache of a single payload makes sure payloads are read from disk
+		store, err := payloadstore.Open(dir, 1)
+		require.NoError(t, err)
+
+		payloads := testutils.RandomPayloads(10, 1, 100)
+		keys := make([]hash.Hash, len(payloads))
+		encoded := make([][]byte, len(payloads))
+		for i, payload := range payloads {
+			keys[i] = hash.Hash(testutils.PathByUint8(uint8(i)))
+			encoded[i] = ledger.EncodeAndAppendPayloadWithoutPrefix(nil, payload, node.PayloadEncodingVersion)
+		}
+
+		err = store.Put(keys, encoded)
+		require.NoError(t, err)
+
+		for i, key := range keys {
+			payload, err := store.Get(key)
+			require.NoError(t, err)
+			require.True(t, payloads[i].Equals(payload))
+
+			encoded, err := store.GetEncoded(key, node.PayloadEncodingVersion, []byte{0xff})
+			require.NoError(t, err)
+			require.Equal(t, ledger.EncodeAndAppendPayloadWithoutPrefix([]byte{0xff}, payloads[i], 1), encoded)
+
+			encoded, err = store.GetEncoded(key, 0, nil)
+			require.NoError(t, err)
+			require.Equal(t, ledger.EncodeAndAppendPayloadWithoutPrefix(nil, payloads[i], 0), encoded)
+		}
+
+		// payloads are kept until all references are released
+		err = store.Put(keys[:1], encoded[:1])
+		require.NoError(t, err)
+		err = store.Release(keys[:2])
+		require.NoError(t, err)
+
+		generation := store.NextGeneration()
+		err = store.Release(keys[:1])
+		require.NoError(t, err)
+		err = store.Release(keys[:1])
+		require.Error(t, err)
+
+		// released payloads are only removed once a later generation is removed
+		err = store.RemoveReleased(generation)
+		require.NoError(t, err)
+
+		_, err = store.Get(keys[0])
+		require.NoError(t, err)
+		_, err = store.Get(keys[1])
+		require.Error(t, err)
+
+		// payloads referenced again are kept
+		err = store.Acquire(keys[:1])
+		require.NoError(t, err)
+
+		err = store.RemoveReleased(store.NextGeneration())
+		require.NoError(t, err)
+
+		_, err = store.Get(keys[0])
+		require.NoError(t, err)
+
+		err = store.Close()
+		require.NoError(t, err)
+
+		// payloads are kept when the store is opened again
+		store, err = payloadstore.Open(dir, 1)
+		require.NoError(t, err)
+
+		_, err = store.Get(keys[2])
+		require.NoError(t, err)
+
+		// payloads which aren't referenced anymore are removed once released
+		err = store.Acquire(keys[2:3])
+		require.NoError(t, err)
+		err = store.ReleaseUnreferenced()
+		require.NoError(t, err)
+		err = store.RemoveReleased(store.NextGeneration())
+		require.NoError(t, err)
+
+		_, err = store.Get(keys[2])
+		require.NoError(t, err)
+		for _, key := range keys[3:] {
+			_, err = store.Get(key)
+			require.Error(t, err)
+		}
+
+		err = store.Close()
+		require.NoError(t, err)
+	})
+}
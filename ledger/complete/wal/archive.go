@@ -0,0 +1,451 @@
+package wal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	prometheusWAL "github.com/m4ksio/wal/wal"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/bootstrap"
+	utilsio "github.com/onflow/flow-go/utils/io"
+)
+
+// ArchiveManifestFilename is the name of the manifest in the archive, and in the WAL directory
+// where Archiver keeps a copy of it.
+const ArchiveManifestFilename = "archive-manifest.json"
+
+const (
+	archiveSegmentsDir    = "segments"
+	archiveCheckpointsDir = "checkpoints"
+)
+
+// ArchiveStore stores the files archived by Archiver.
+type ArchiveStore interface {
+	// UploadFile stores the content read from reader under the given name, replacing any
+	// content previously stored under the same name.
+	// Once UploadFile returns without error, the content must be stored durably.
+	UploadFile(name string, reader io.Reader) error
+}
+
+// DirArchiveStore is an ArchiveStore keeping the archived files in a local directory.
+type DirArchiveStore struct {
+	dir string
+}
+
+var _ ArchiveStore = (*DirArchiveStore)(nil)
+
+// NewDirArchiveStore returns an ArchiveStore keeping the archived files in the given directory,
+// which is created if it doesn't exist.
+func NewDirArchiveStore(dir string) (*DirArchiveStore, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create archive directory %s: %w", dir, err)
+	}
+	return &DirArchiveStore{dir: dir}, nil
+}
+
+// UploadFile writes the content to a temporary file, and renames it to the given name once it
+// has been synced, so an interrupted upload never leaves an incomplete file under the given name.
+func (s *DirArchiveStore) UploadFile(name string, reader io.Reader) error {
+	target := filepath.Join(s.dir, filepath.FromSlash(name))
+	dir := filepath.Dir(target)
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("cannot create directory %s: %w", dir, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "writing-archive-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file for %s: %w", name, err)
+	}
+
+	_, err = io.Copy(tmpFile, reader)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	closeErr := tmpFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return fmt.Errorf("cannot write %s: %w", name, err)
+	}
+
+	err = os.Rename(tmpFile.Name(), target)
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return fmt.Errorf("cannot rename %s to %s: %w", tmpFile.Name(), target, err)
+	}
+	return nil
+}
+
+// ArchiveManifest lists the WAL segments and checkpoints stored in an archive.
+// Together they contain every trie update since the root checkpoint (or since the first
+// archived checkpoint if the archive was enabled later), see RestorePlan.
+type ArchiveManifest struct {
+	// Segments are the archived WAL segments in ascending order.
+	Segments []ArchivedSegment `json:"segments"`
+	// Checkpoints are the archived checkpoints in ascending order.
+	Checkpoints []ArchivedCheckpoint `json:"checkpoints"`
+	// RootCheckpoint is the root checkpoint, which is loaded before replaying segment 0.
+	RootCheckpoint *ArchivedCheckpoint `json:"root_checkpoint,omitempty"`
+}
+
+// ArchivedFile is a file stored in the archive.
+type ArchivedFile struct {
+	// Name is the name of the file in the archive store.
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	CRC32 uint32 `json:"crc32"`
+}
+
+// ArchivedSegment is a WAL segment stored in the archive.
+type ArchivedSegment struct {
+	Number int          `json:"number"`
+	File   ArchivedFile `json:"file"`
+}
+
+// ArchivedCheckpoint is a checkpoint stored in the archive.
+type ArchivedCheckpoint struct {
+	Number int `json:"number"`
+	// Base is the number of the checkpoint an incremental checkpoint is based on,
+	// or -1 for full checkpoints.
+	Base int `json:"base"`
+	// Files are the checkpoint file followed by its part files, if any.
+	Files []ArchivedFile `json:"files"`
+}
+
+func (m *ArchiveManifest) checkpoint(number int) (ArchivedCheckpoint, bool) {
+	for _, c := range m.Checkpoints {
+		if c.Number == number {
+			return c, true
+		}
+	}
+	return ArchivedCheckpoint{}, false
+}
+
+// RestorePlan returns the archived checkpoints and segments needed to reconstruct the
+// state as of the end of the given segment. Checkpoints must be loaded in the returned order
+// (the base checkpoints of an incremental checkpoint come first), then the segments must be
+// replayed in the returned order.
+// If no archived checkpoint is old enough, the plan starts with the root checkpoint (if any)
+// and replays the segments from segment 0.
+// Returned errors indicate that the archive doesn't contain the needed files.
+func (m *ArchiveManifest) RestorePlan(segment int) ([]ArchivedCheckpoint, []ArchivedSegment, error) {
+	var checkpoints []ArchivedCheckpoint
+
+	// use the latest checkpoint not after the given segment, to replay the least segments
+	start := 0
+	for i := len(m.Checkpoints) - 1; i >= 0; i-- {
+		if m.Checkpoints[i].Number > segment {
+			continue
+		}
+
+		checkpoint := m.Checkpoints[i]
+		checkpoints = append(checkpoints, checkpoint)
+		for checkpoint.Base != -1 {
+			base, ok := m.checkpoint(checkpoint.Base)
+			if !ok {
+				return nil, nil, fmt.Errorf("base checkpoint %d of checkpoint %d isn't archived", checkpoint.Base, checkpoint.Number)
+			}
+			checkpoints = append([]ArchivedCheckpoint{base}, checkpoints...)
+			checkpoint = base
+		}
+
+		start = m.Checkpoints[i].Number + 1
+		break
+	}
+
+	if len(checkpoints) == 0 && m.RootCheckpoint != nil {
+		checkpoints = append(checkpoints, *m.RootCheckpoint)
+	}
+
+	var segments []ArchivedSegment
+	next := start
+	for _, s := range m.Segments {
+		if s.Number < start || s.Number > segment {
+			continue
+		}
+		if s.Number != next {
+			return nil, nil, fmt.Errorf("segment %d isn't archived", next)
+		}
+		segments = append(segments, s)
+		next++
+	}
+	if next <= segment {
+		return nil, nil, fmt.Errorf("segment %d isn't archived", next)
+	}
+
+	return checkpoints, segments, nil
+}
+
+// ReadArchiveManifest reads the manifest from the given file.
+func ReadArchiveManifest(filePath string) (*ArchiveManifest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read archive manifest: %w", err)
+	}
+
+	var manifest ArchiveManifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode archive manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// Archiver archives WAL segments and checkpoints to an ArchiveStore, so that any historical
+// state can be reconstructed from the archive after they have been removed from the WAL directory.
+// The manifest of the archive is uploaded after the files it lists, and a copy is kept in the
+// WAL directory to keep track of the archived files across restarts.
+// Archiver isn't safe for concurrent use.
+type Archiver struct {
+	dir      string
+	store    ArchiveStore
+	logger   zerolog.Logger
+	manifest *ArchiveManifest
+}
+
+// NewArchiver returns an Archiver of the segments and checkpoints in the given WAL directory.
+// The manifest is read from the WAL directory if files have already been archived.
+func NewArchiver(dir string, store ArchiveStore, logger zerolog.Logger) (*Archiver, error) {
+	manifest := &ArchiveManifest{}
+
+	manifestPath := path.Join(dir, ArchiveManifestFilename)
+	if utilsio.FileExists(manifestPath) {
+		var err error
+		manifest, err = ReadArchiveManifest(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Archiver{
+		dir:      dir,
+		store:    store,
+		logger:   logger.With().Str("component", "wal_archiver").Logger(),
+		manifest: manifest,
+	}, nil
+}
+
+// Manifest returns the manifest of the archived files.
+// The returned manifest must not be modified.
+func (a *Archiver) Manifest() *ArchiveManifest {
+	return a.manifest
+}
+
+// IsCheckpointArchived returns true if the given checkpoint has been archived.
+func (a *Archiver) IsCheckpointArchived(checkpoint int) bool {
+	_, ok := a.manifest.checkpoint(checkpoint)
+	return ok
+}
+
+// ArchiveCheckpoint archives the given checkpoint with its part files, unless it has already been archived.
+// The checkpoint files are kept in the WAL directory.
+func (a *Archiver) ArchiveCheckpoint(checkpoint int) error {
+	if a.IsCheckpointArchived(checkpoint) {
+		return nil
+	}
+
+	fileName := NumberToFilename(checkpoint)
+
+	baseFile, err := CheckpointBase(path.Join(a.dir, fileName))
+	if err != nil {
+		return fmt.Errorf("cannot get base of checkpoint %d: %w", checkpoint, err)
+	}
+	base := -1
+	if baseFile != "" {
+		base, err = checkpointNumberFromFilename(baseFile)
+		if err != nil {
+			return fmt.Errorf("invalid base of checkpoint %d: %w", checkpoint, err)
+		}
+	}
+
+	archived, err := a.archiveCheckpointFiles(fileName)
+	if err != nil {
+		return fmt.Errorf("cannot archive checkpoint %d: %w", checkpoint, err)
+	}
+	archived.Number = checkpoint
+	archived.Base = base
+
+	a.manifest.Checkpoints = insertCheckpoint(a.manifest.Checkpoints, archived)
+
+	err = a.saveManifest()
+	if err != nil {
+		return err
+	}
+
+	a.logger.Info().Int("checkpoint", checkpoint).Int("files", len(archived.Files)).Msg("archived checkpoint")
+
+	return nil
+}
+
+// ArchiveSegments archives the finalized segments up to the given segment (inclusive), and removes
+// them from the WAL directory once the manifest listing them has been saved.
+// The root checkpoint is archived with segment 0 if it exists.
+// Since removed segments are no longer replayed, the caller must only archive segments
+// which are included in a checkpoint kept in the WAL directory.
+func (a *Archiver) ArchiveSegments(to int) error {
+	first, last, err := prometheusWAL.Segments(a.dir)
+	if err != nil {
+		return fmt.Errorf("cannot get range of segments: %w", err)
+	}
+	if first == -1 {
+		return nil
+	}
+
+	// the last segment is the one being written to
+	if to >= last {
+		to = last - 1
+	}
+	if to < first {
+		return nil
+	}
+
+	if first == 0 && a.manifest.RootCheckpoint == nil && utilsio.FileExists(path.Join(a.dir, bootstrap.FilenameWALRootCheckpoint)) {
+		archived, err := a.archiveCheckpointFiles(bootstrap.FilenameWALRootCheckpoint)
+		if err != nil {
+			return fmt.Errorf("cannot archive root checkpoint: %w", err)
+		}
+		archived.Number = -1
+		archived.Base = -1
+		a.manifest.RootCheckpoint = &archived
+	}
+
+	for segment := first; segment <= to; segment++ {
+		fileName := NumberToFilenamePart(segment)
+		file, err := a.archiveFile(fileName, path.Join(archiveSegmentsDir, fileName))
+		if err != nil {
+			return fmt.Errorf("cannot archive segment %d: %w", segment, err)
+		}
+		a.manifest.Segments = insertSegment(a.manifest.Segments, ArchivedSegment{Number: segment, File: file})
+	}
+
+	err = a.saveManifest()
+	if err != nil {
+		return err
+	}
+
+	// segments are removed in ascending order, so the remaining segments are always sequential
+	for segment := first; segment <= to; segment++ {
+		err := os.Remove(path.Join(a.dir, NumberToFilenamePart(segment)))
+		if err != nil {
+			return fmt.Errorf("cannot remove archived segment %d: %w", segment, err)
+		}
+	}
+
+	a.logger.Info().Int("from", first).Int("to", to).Msg("archived segments")
+
+	return nil
+}
+
+// archiveCheckpointFiles uploads the checkpoint file with the given name and its part files,
+// and returns them as an ArchivedCheckpoint without number and base.
+func (a *Archiver) archiveCheckpointFiles(fileName string) (ArchivedCheckpoint, error) {
+	_, filePaths, err := CheckpointFiles(path.Join(a.dir, fileName))
+	if err != nil {
+		return ArchivedCheckpoint{}, err
+	}
+
+	files := make([]ArchivedFile, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		name := filepath.Base(filePath)
+		file, err := a.archiveFile(name, path.Join(archiveCheckpointsDir, name))
+		if err != nil {
+			return ArchivedCheckpoint{}, err
+		}
+		files = append(files, file)
+	}
+
+	return ArchivedCheckpoint{Files: files}, nil
+}
+
+// archiveFile uploads the given file of the WAL directory with the given archive name.
+func (a *Archiver) archiveFile(fileName string, name string) (ArchivedFile, error) {
+	f, err := os.Open(path.Join(a.dir, fileName))
+	if err != nil {
+		return ArchivedFile{}, fmt.Errorf("cannot open %s: %w", fileName, err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return ArchivedFile{}, fmt.Errorf("cannot stat %s: %w", fileName, err)
+	}
+
+	crc := crc32.New(crc32Table)
+	err = a.store.UploadFile(name, io.TeeReader(f, crc))
+	if err != nil {
+		return ArchivedFile{}, fmt.Errorf("cannot upload %s: %w", fileName, err)
+	}
+
+	return ArchivedFile{
+		Name:  name,
+		Size:  stat.Size(),
+		CRC32: crc.Sum32(),
+	}, nil
+}
+
+// saveManifest writes the manifest to the WAL directory and uploads it.
+func (a *Archiver) saveManifest() error {
+	data, err := json.MarshalIndent(a.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode archive manifest: %w", err)
+	}
+
+	local, err := NewDirArchiveStore(a.dir)
+	if err != nil {
+		return err
+	}
+	err = local.UploadFile(ArchiveManifestFilename, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("cannot save archive manifest: %w", err)
+	}
+
+	err = a.store.UploadFile(ArchiveManifestFilename, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("cannot upload archive manifest: %w", err)
+	}
+	return nil
+}
+
+// insertCheckpoint inserts or replaces the given checkpoint, keeping checkpoints in ascending order.
+func insertCheckpoint(checkpoints []ArchivedCheckpoint, checkpoint ArchivedCheckpoint) []ArchivedCheckpoint {
+	i := 0
+	for i < len(checkpoints) && checkpoints[i].Number < checkpoint.Number {
+		i++
+	}
+	if i < len(checkpoints) && checkpoints[i].Number == checkpoint.Number {
+		checkpoints[i] = checkpoint
+		return checkpoints
+	}
+	checkpoints = append(checkpoints, ArchivedCheckpoint{})
+	copy(checkpoints[i+1:], checkpoints[i:])
+	checkpoints[i] = checkpoint
+	return checkpoints
+}
+
+// insertSegment inserts or replaces the given segment, keeping segments in ascending order.
+func insertSegment(segments []ArchivedSegment, segment ArchivedSegment) []ArchivedSegment {
+	i := 0
+	for i < len(segments) && segments[i].Number < segment.Number {
+		i++
+	}
+	if i < len(segments) && segments[i].Number == segment.Number {
+		segments[i] = segment
+		return segments
+	}
+	segments = append(segments, ArchivedSegment{})
+	copy(segments[i+1:], segments[i:])
+	segments[i] = segment
+	return segments
+}
@@ -0,0 +1,133 @@
+package wal_test
+
+import (
+	"hash/crc32"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestArchiver(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		unittest.RunWithTempDir(t, func(archiveDir string) {
+			logger := zerolog.Nop()
+
+			wal, err := realWAL.NewDiskWAL(logger, nil, metrics.NewNoopCollector(), dir, 10, 32, 32*1024, realWAL.WithCompression())
+			require.NoError(t, err)
+
+			for {
+				_, _, err := wal.RecordUpdate(testutils.TrieUpdateFixture(2, 4*1024, 8*1024))
+				require.NoError(t, err)
+
+				_, last, err := wal.Segments()
+				require.NoError(t, err)
+				if last >= 5 {
+					break
+				}
+			}
+
+			// full checkpoint 1 and incremental checkpoint 2
+			baseTries := createTriesForCheckpointV6(t)
+			err = realWAL.StoreCheckpointV6(baseTries, dir, realWAL.NumberToFilename(1), &logger)
+			require.NoError(t, err)
+			err = realWAL.StoreCheckpointDelta(updateTries(t, baseTries), baseTries, realWAL.NumberToFilename(1), dir, realWAL.NumberToFilename(2), &logger)
+			require.NoError(t, err)
+
+			store, err := realWAL.NewDirArchiveStore(archiveDir)
+			require.NoError(t, err)
+
+			archiver, err := realWAL.NewArchiver(dir, store, logger)
+			require.NoError(t, err)
+
+			require.NoError(t, archiver.ArchiveCheckpoint(2))
+			require.True(t, archiver.IsCheckpointArchived(2))
+			require.False(t, archiver.IsCheckpointArchived(1))
+
+			// base of checkpoint 2 isn't archived
+			_, _, err = archiver.Manifest().RestorePlan(2)
+			require.Error(t, err)
+
+			require.NoError(t, archiver.ArchiveCheckpoint(1))
+			require.NoError(t, archiver.ArchiveSegments(2))
+
+			// archived segments are removed, checkpoints are kept
+			first, _, err := wal.Segments()
+			require.NoError(t, err)
+			require.Equal(t, 3, first)
+			require.FileExists(t, path.Join(dir, realWAL.NumberToFilename(1)))
+			require.FileExists(t, path.Join(dir, realWAL.NumberToFilename(2)))
+
+			manifest := archiver.Manifest()
+			require.Len(t, manifest.Checkpoints, 2)
+			require.Equal(t, -1, manifest.Checkpoints[0].Base)
+			require.Equal(t, 1, manifest.Checkpoints[1].Base)
+			require.Len(t, manifest.Checkpoints[0].Files, 1+len(realWAL.CheckpointPartFileNames(realWAL.NumberToFilename(1))))
+			require.Len(t, manifest.Checkpoints[1].Files, 1)
+			require.Len(t, manifest.Segments, 3)
+
+			// archived files match the manifest
+			for _, c := range manifest.Checkpoints {
+				for _, file := range c.Files {
+					requireArchivedFile(t, archiveDir, file)
+				}
+			}
+			for i, s := range manifest.Segments {
+				require.Equal(t, i, s.Number)
+				requireArchivedFile(t, archiveDir, s.File)
+			}
+
+			// the manifest is uploaded, and read again from the WAL directory
+			uploaded, err := realWAL.ReadArchiveManifest(path.Join(archiveDir, realWAL.ArchiveManifestFilename))
+			require.NoError(t, err)
+			require.Equal(t, manifest, uploaded)
+
+			archiver, err = realWAL.NewArchiver(dir, store, logger)
+			require.NoError(t, err)
+			require.Equal(t, manifest, archiver.Manifest())
+
+			// the segment being written to is never archived
+			require.NoError(t, archiver.ArchiveSegments(100))
+			first, last, err := wal.Segments()
+			require.NoError(t, err)
+			require.Equal(t, first, last)
+			require.Len(t, archiver.Manifest().Segments, last)
+
+			t.Run("restore plan", func(t *testing.T) {
+				checkpoints, segments, err := manifest.RestorePlan(1)
+				require.NoError(t, err)
+				require.Equal(t, manifest.Checkpoints[:1], checkpoints)
+				require.Empty(t, segments)
+
+				checkpoints, segments, err = manifest.RestorePlan(0)
+				require.NoError(t, err)
+				require.Empty(t, checkpoints)
+				require.Equal(t, manifest.Segments[:1], segments)
+
+				checkpoints, segments, err = archiver.Manifest().RestorePlan(4)
+				require.NoError(t, err)
+				require.Equal(t, manifest.Checkpoints, checkpoints)
+				require.Equal(t, archiver.Manifest().Segments[3:5], segments)
+
+				_, _, err = archiver.Manifest().RestorePlan(last)
+				require.Error(t, err)
+			})
+
+			<-wal.Done()
+		})
+	})
+}
+
+func requireArchivedFile(t *testing.T, archiveDir string, file realWAL.ArchivedFile) {
+	data, err := os.ReadFile(path.Join(archiveDir, file.Name))
+	require.NoError(t, err)
+	require.Equal(t, file.Size, int64(len(data)))
+	require.Equal(t, file.CRC32, crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+}
@@ -0,0 +1,504 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+const (
+	encBaseNameLengthSize = 2
+	encRefCountSize       = 8
+	encHeightSize         = 2
+	encBaseRefSize        = encHeightSize + ledger.PathLen + hash.HashLen
+)
+
+// StoreCheckpointDelta writes the given tries to an incremental checkpoint file (version 7)
+// in the given directory. An incremental checkpoint only stores the nodes which are new
+// since the base checkpoint with the given file name and tries, which must be in the same
+// directory. Loading an incremental checkpoint loads its base first, which can itself be
+// an incremental checkpoint.
+//
+// Subtries which are unchanged since the last trie of the base checkpoint are stored as
+// references to the base: their height, position (path prefix) and hash. The file contains:
+//   - the file name of the base checkpoint and the root hash of its last trie.
+//   - the references to base nodes, indexed from 1 (0 meaning nil).
+//   - the new nodes, indexed after the references, followed by the encoded tries.
+//   - the new node count and the trie count.
+//
+// The file starts with the magic bytes and the version, and ends with its CRC32 checksum.
+// Nodes and tries are encoded the same way as in version 5 checkpoints.
+func StoreCheckpointDelta(
+	tries []*trie.MTrie,
+	baseTries []*trie.MTrie,
+	baseFile string,
+	outputDir string,
+	outputFile string,
+	logger *zerolog.Logger,
+) error {
+	return storeCheckpointDelta(tries, baseTries, baseFile, outputDir, outputFile, logger, flattener.EncodeNode)
+}
+
+// StoreCheckpointDeltaWithPayloadReferences is StoreCheckpointDelta, which writes the keys
+// of payloads kept in a payload store instead of the payloads, see StoreCheckpointV6WithPayloadReferences.
+func StoreCheckpointDeltaWithPayloadReferences(
+	tries []*trie.MTrie,
+	baseTries []*trie.MTrie,
+	baseFile string,
+	outputDir string,
+	outputFile string,
+	logger *zerolog.Logger,
+) error {
+	return storeCheckpointDelta(tries, baseTries, baseFile, outputDir, outputFile, logger, flattener.EncodeNodeWithPayloadReference)
+}
+
+func storeCheckpointDelta(
+	tries []*trie.MTrie,
+	baseTries []*trie.MTrie,
+	baseFile string,
+	outputDir string,
+	outputFile string,
+	logger *zerolog.Logger,
+	encodeNode nodeEncoder,
+) (err error) {
+	if len(baseTries) == 0 {
+		return errors.New("base checkpoint of incremental checkpoint has no tries")
+	}
+	if len(baseFile) > 1<<(8*encBaseNameLengthSize)-1 {
+		return fmt.Errorf("base checkpoint file name %s is too long", baseFile)
+	}
+
+	baseTrie := baseTries[len(baseTries)-1]
+
+	// The hasher isn't recorded, incremental checkpoints use the hasher of their base checkpoint.
+	for _, t := range tries {
+		if t.Hasher().Type() != baseTrie.Hasher().Type() {
+			return fmt.Errorf("cannot store trie hashed with %s in incremental checkpoint of tries hashed with %s", t.Hasher().Type(), baseTrie.Hasher().Type())
+		}
+	}
+
+	delta := &deltaNodes{indexes: make(map[*node.Node]deltaNodeIndex)}
+	for _, t := range tries {
+		delta.collect(t.RootNode(), baseTrie.RootNode(), ledger.DummyPath)
+	}
+
+	writer, err := CreateCheckpointWriterForFile(outputDir, outputFile, logger)
+	if err != nil {
+		return fmt.Errorf("cannot create checkpoint writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	crc32Writer := NewCRC32Writer(writer)
+
+	// Scratch buffer is used as temporary buffer that node can encode into.
+	// See StoreCheckpoint for details.
+	scratch := make([]byte, 1024*4)
+
+	err = writeCheckpointHeader(crc32Writer, scratch, VersionV7)
+	if err != nil {
+		return err
+	}
+
+	// Write base checkpoint file name and root hash of its last trie
+	buf := scratch[:encBaseNameLengthSize]
+	binary.BigEndian.PutUint16(buf, uint16(len(baseFile)))
+	buf = append(buf, baseFile...)
+	baseRootHash := baseTrie.RootHash()
+	buf = append(buf, baseRootHash[:]...)
+
+	_, err = crc32Writer.Write(buf)
+	if err != nil {
+		return fmt.Errorf("cannot write base checkpoint: %w", err)
+	}
+
+	// Write references to base nodes
+	buf = scratch[:encRefCountSize]
+	binary.BigEndian.PutUint64(buf, uint64(len(delta.refs)))
+
+	_, err = crc32Writer.Write(buf)
+	if err != nil {
+		return fmt.Errorf("cannot write base node count: %w", err)
+	}
+
+	for i, ref := range delta.refs {
+		buf = scratch[:encBaseRefSize]
+		binary.BigEndian.PutUint16(buf, uint16(ref.Height()))
+		copy(buf[encHeightSize:], delta.refPaths[i][:])
+		refHash := ref.Hash()
+		copy(buf[encHeightSize+ledger.PathLen:], refHash[:])
+
+		_, err = crc32Writer.Write(buf)
+		if err != nil {
+			return fmt.Errorf("cannot write base node: %w", err)
+		}
+	}
+
+	// Write new nodes
+	for _, n := range delta.nodes {
+		encNode, err := encodeNode(n, delta.index(n.LeftChild()), delta.index(n.RightChild()), scratch)
+		if err != nil {
+			return fmt.Errorf("cannot encode node: %w", err)
+		}
+		_, err = crc32Writer.Write(encNode)
+		if err != nil {
+			return fmt.Errorf("cannot serialize node: %w", err)
+		}
+	}
+
+	// Write tries
+	for _, t := range tries {
+		encTrie := flattener.EncodeTrie(t, delta.index(t.RootNode()), scratch)
+		_, err = crc32Writer.Write(encTrie)
+		if err != nil {
+			return fmt.Errorf("cannot serialize trie: %w", err)
+		}
+	}
+
+	// Write footer with nodes count and tries count
+	footer := scratch[:encNodeCountSize+encTrieCountSize]
+	binary.BigEndian.PutUint64(footer, uint64(len(delta.nodes)))
+	binary.BigEndian.PutUint16(footer[encNodeCountSize:], uint16(len(tries)))
+
+	_, err = crc32Writer.Write(footer)
+	if err != nil {
+		return fmt.Errorf("cannot write checkpoint footer: %w", err)
+	}
+
+	_, err = writeChecksum(writer, crc32Writer, scratch)
+	if err != nil {
+		return err
+	}
+
+	logger.Info().
+		Str("base", baseFile).
+		Int("base_nodes", len(delta.refs)).
+		Int("new_nodes", len(delta.nodes)).
+		Msgf("stored incremental checkpoint %s", outputFile)
+
+	return nil
+}
+
+// deltaNodes collects the nodes of an incremental checkpoint.
+type deltaNodes struct {
+	refs     []*node.Node // base nodes referenced by new nodes
+	refPaths []ledger.Path
+	nodes    []*node.Node // new nodes, in Descendents-First-Relationship order
+	indexes  map[*node.Node]deltaNodeIndex
+}
+
+// deltaNodeIndex is the index of a node in either the base references or the new nodes.
+type deltaNodeIndex struct {
+	ref   bool
+	index uint64
+}
+
+// collect traverses the subtrie with root n and the subtrie at the same position of the
+// base trie, and collects the nodes which aren't in the base trie, or the references to
+// the base trie. The position of n is given by the first bits of path.
+func (d *deltaNodes) collect(n *node.Node, base *node.Node, path ledger.Path) {
+	if n == nil {
+		return
+	}
+	if _, ok := d.indexes[n]; ok {
+		return
+	}
+
+	if base != nil && base.Height() == n.Height() && base.IsLeaf() == n.IsLeaf() && base.Hash() == n.Hash() {
+		d.indexes[n] = deltaNodeIndex{ref: true, index: uint64(len(d.refs))}
+		d.refs = append(d.refs, n)
+		d.refPaths = append(d.refPaths, path)
+		return
+	}
+
+	if !n.IsLeaf() {
+		var lbase, rbase *node.Node
+		if base != nil && !base.IsLeaf() {
+			lbase, rbase = base.LeftChild(), base.RightChild()
+		}
+
+		d.collect(n.LeftChild(), lbase, path)
+
+		rpath := path
+		bitutils.SetBit(rpath[:], ledger.NodeMaxHeight-n.Height())
+		d.collect(n.RightChild(), rbase, rpath)
+	}
+
+	d.indexes[n] = deltaNodeIndex{index: uint64(len(d.nodes))}
+	d.nodes = append(d.nodes, n)
+}
+
+// index returns the index of the given collected node in the checkpoint file:
+// base references first, followed by new nodes. Index 0 is a special case with nil node.
+func (d *deltaNodes) index(n *node.Node) uint64 {
+	if n == nil {
+		return 0
+	}
+	i := d.indexes[n]
+	if i.ref {
+		return 1 + i.index
+	}
+	return 1 + uint64(len(d.refs)) + i.index
+}
+
+// CheckpointBase returns the file name of the base checkpoint of the given checkpoint file,
+// or an empty string if the checkpoint isn't an incremental checkpoint.
+func CheckpointBase(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot open checkpoint file %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReaderSize(f, defaultBufioReadSize)
+
+	header := make([]byte, headerSize)
+	_, err = io.ReadFull(reader, header)
+	if err != nil {
+		return "", fmt.Errorf("cannot read header: %w", err)
+	}
+
+	magicBytes := binary.BigEndian.Uint16(header)
+	version := binary.BigEndian.Uint16(header[encMagicSize:])
+	if magicBytes != MagicBytes {
+		return "", fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytes)
+	}
+
+	if version != VersionV7 {
+		return "", nil
+	}
+
+	baseFile, _, err := readCheckpointDeltaBase(reader)
+	return baseFile, err
+}
+
+// readCheckpointDeltaBase reads the file name of the base checkpoint and the root hash of its last trie.
+func readCheckpointDeltaBase(reader io.Reader) (string, hash.Hash, error) {
+	buf := make([]byte, encBaseNameLengthSize)
+	_, err := io.ReadFull(reader, buf)
+	if err != nil {
+		return "", hash.DummyHash, fmt.Errorf("cannot read base checkpoint file name length: %w", err)
+	}
+
+	buf = make([]byte, int(binary.BigEndian.Uint16(buf))+hash.HashLen)
+	_, err = io.ReadFull(reader, buf)
+	if err != nil {
+		return "", hash.DummyHash, fmt.Errorf("cannot read base checkpoint: %w", err)
+	}
+
+	baseFile := string(buf[:len(buf)-hash.HashLen])
+	baseRootHash, err := hash.ToHash(buf[len(buf)-hash.HashLen:])
+	if err != nil {
+		return "", hash.DummyHash, fmt.Errorf("cannot decode base checkpoint root hash: %w", err)
+	}
+
+	return baseFile, baseRootHash, nil
+}
+
+// readCheckpointDelta decodes an incremental checkpoint file (version 7), loads its base
+// checkpoint, and returns a list of tries. Payloads are moved into the given store, if it isn't nil.
+// Checkpoint file header (magic and version) are verified by the caller.
+func readCheckpointDelta(f *os.File, logger *zerolog.Logger, store node.PayloadStore) ([]*trie.MTrie, error) {
+
+	// Scratch buffer is used as temporary buffer that reader can read into.
+	// See readCheckpointV5 for details.
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+
+	// footer offset: nodes count (8 bytes) + tries count (2 bytes) + CRC32 sum (4 bytes)
+	const footerOffset = encNodeCountSize + encTrieCountSize + crc32SumSize
+	const footerSize = encNodeCountSize + encTrieCountSize // footer doesn't include crc32 sum
+
+	// Seek to footer
+	_, err := f.Seek(-footerOffset, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seek to footer: %w", err)
+	}
+
+	footer := scratch[:footerSize]
+
+	_, err = io.ReadFull(f, footer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	// Decode node count and trie count
+	nodesCount := binary.BigEndian.Uint64(footer)
+	triesCount := binary.BigEndian.Uint16(footer[encNodeCountSize:])
+
+	// Seek to the start of file
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(bufReader)
+	var reader io.Reader = crcReader
+
+	// Read header: magic (2 bytes) + version (2 bytes)
+	// No action is needed for header because it is verified by the caller.
+	_, err = io.ReadFull(reader, scratch[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	baseFile, baseRootHash, err := readCheckpointDeltaBase(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseFile == filepath.Base(f.Name()) {
+		return nil, fmt.Errorf("incremental checkpoint %s is its own base", baseFile)
+	}
+
+	logger.Info().Msgf("loading base checkpoint %s of incremental checkpoint %s", baseFile, f.Name())
+
+	baseTries, err := LoadCheckpointWithPayloadStore(filepath.Join(filepath.Dir(f.Name()), baseFile), logger, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load base checkpoint %s: %w", baseFile, err)
+	}
+
+	if len(baseTries) == 0 || baseTries[len(baseTries)-1].RootHash() != ledger.RootHash(baseRootHash) {
+		return nil, fmt.Errorf("base checkpoint %s doesn't match incremental checkpoint", baseFile)
+	}
+	baseRoot := baseTries[len(baseTries)-1].RootNode()
+
+	// Read references to base nodes
+	_, err = io.ReadFull(reader, scratch[:encRefCountSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read base node count: %w", err)
+	}
+	refsCount := binary.BigEndian.Uint64(scratch)
+
+	// nodes's element at index 0 is a special, meaning nil .
+	nodes := make([]*node.Node, 1+refsCount+nodesCount) //+1 for 0 index meaning nil
+	tries := make([]*trie.MTrie, triesCount)
+
+	for i := uint64(1); i <= refsCount; i++ {
+		buf := scratch[:encBaseRefSize]
+		_, err = io.ReadFull(reader, buf)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read base node %d: %w", i, err)
+		}
+
+		height := int(binary.BigEndian.Uint16(buf))
+		var path ledger.Path
+		copy(path[:], buf[encHeightSize:])
+		refHash, err := hash.ToHash(buf[encHeightSize+ledger.PathLen:])
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode hash of base node %d: %w", i, err)
+		}
+
+		n := findBaseNode(baseRoot, height, path)
+		if n == nil || n.Hash() != refHash {
+			return nil, fmt.Errorf("cannot find base node %d at height %d in base checkpoint", i, height)
+		}
+		nodes[i] = n
+	}
+
+	payloads := newPayloadBatch(store)
+	for i := 1 + refsCount; i < uint64(len(nodes)); i++ {
+		n, err := flattener.ReadNodeWithPayloadBatch(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+			if nodeIndex >= i {
+				return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+			}
+			return nodes[nodeIndex], nil
+		}, payloads)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		nodes[i] = n
+	}
+
+	err = flushPayloadBatch(payloads)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tries of an incremental checkpoint use the hasher of its base checkpoint
+	hasher := baseTries[len(baseTries)-1].Hasher()
+	for i := uint16(0); i < triesCount; i++ {
+		trie, err := flattener.ReadTrieWithHasher(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+			if nodeIndex >= uint64(len(nodes)) {
+				return nil, fmt.Errorf("sequence of stored nodes doesn't contain node")
+			}
+			return nodes[nodeIndex], nil
+		}, hasher)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read trie %d: %w", i, err)
+		}
+		tries[i] = trie
+	}
+
+	// Read footer again for crc32 computation
+	// No action is needed.
+	_, err = io.ReadFull(reader, footer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	// Read CRC32
+	crc32buf := scratch[:crc32SumSize]
+	_, err = io.ReadFull(bufReader, crc32buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CRC32: %w", err)
+	}
+
+	readCrc32 := binary.BigEndian.Uint32(crc32buf)
+
+	calculatedCrc32 := crcReader.Crc32()
+
+	if calculatedCrc32 != readCrc32 {
+		return nil, fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+	}
+
+	return tries, nil
+}
+
+// findBaseNode returns the node at the given height and position (path prefix) of the
+// trie with the given root, or nil if there is no such node.
+func findBaseNode(root *node.Node, height int, path ledger.Path) *node.Node {
+	n := root
+	for n != nil && n.Height() > height {
+		if n.IsLeaf() {
+			return nil
+		}
+		if bitutils.ReadBit(path[:], ledger.NodeMaxHeight-n.Height()) == 0 {
+			n = n.LeftChild()
+		} else {
+			n = n.RightChild()
+		}
+	}
+	if n == nil || n.Height() != height {
+		return nil
+	}
+	return n
+}
+
+// checkpointNumberFromFilename returns the number of the checkpoint with the given file name.
+func checkpointNumberFromFilename(fileName string) (int, error) {
+	if !strings.HasPrefix(fileName, checkpointFilenamePrefix) {
+		return -1, fmt.Errorf("%s isn't a checkpoint file name", fileName)
+	}
+	return strconv.Atoi(fileName[len(checkpointFilenamePrefix):])
+}
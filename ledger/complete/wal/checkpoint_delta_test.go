@@ -0,0 +1,170 @@
+package wal_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// updateTries returns the given tries without the first one, followed by
+// a trie with a few registers updated from the last given trie.
+func updateTries(t *testing.T, tries []*trie.MTrie) []*trie.MTrie {
+	paths := testutils.RandomPaths(10)
+	payloads := randomPayloadsForCheckpointV6(len(paths))
+
+	updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(tries[len(tries)-1], paths, payloads, true)
+	require.NoError(t, err)
+
+	return append(append([]*trie.MTrie{}, tries[1:]...), updatedTrie)
+}
+
+func requireTriesEqual(t *testing.T, expected []*trie.MTrie, actual []*trie.MTrie) {
+	require.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		require.Equal(t, expected[i].RootHash(), actual[i].RootHash())
+		require.Equal(t, expected[i].AllocatedRegCount(), actual[i].AllocatedRegCount())
+		require.Equal(t, expected[i].AllocatedRegSize(), actual[i].AllocatedRegSize())
+		require.True(t, actual[i].IsAValidTrie())
+		expectedPayloads, err := expected[i].AllPayloads()
+		require.NoError(t, err)
+		actualPayloads, err := actual[i].AllPayloads()
+		require.NoError(t, err)
+		require.ElementsMatch(t, expectedPayloads, actualPayloads)
+	}
+}
+
+func Test_StoringLoadingCheckpointDelta(t *testing.T) {
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := zerolog.Nop()
+
+		baseTries := createTriesForCheckpointV6(t)
+		baseFile := "checkpoint.00000010"
+		err := realWAL.StoreCheckpointV6(baseTries, dir, baseFile, &logger)
+		require.NoError(t, err)
+
+		// incremental checkpoint based on a full checkpoint
+		deltaTries := updateTries(t, baseTries)
+		deltaFile := "checkpoint.00000020"
+		err = realWAL.StoreCheckpointDelta(deltaTries, baseTries, baseFile, dir, deltaFile, &logger)
+		require.NoError(t, err)
+
+		// incremental checkpoint based on an incremental checkpoint
+		nextDeltaTries := updateTries(t, deltaTries)
+		nextDeltaFile := "checkpoint.00000030"
+		err = realWAL.StoreCheckpointDelta(nextDeltaTries, deltaTries, deltaFile, dir, nextDeltaFile, &logger)
+		require.NoError(t, err)
+
+		t.Run("loads tries", func(t *testing.T) {
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, deltaFile), &logger)
+			require.NoError(t, err)
+			requireTriesEqual(t, deltaTries, loadedTries)
+
+			loadedTries, err = realWAL.LoadCheckpoint(path.Join(dir, nextDeltaFile), &logger)
+			require.NoError(t, err)
+			requireTriesEqual(t, nextDeltaTries, loadedTries)
+		})
+
+		t.Run("stores only new nodes", func(t *testing.T) {
+			fullFile := "checkpoint.00000040"
+			err := realWAL.StoreCheckpointV6(deltaTries, dir, fullFile, &logger)
+			require.NoError(t, err)
+
+			_, files, err := realWAL.CheckpointFiles(path.Join(dir, fullFile))
+			require.NoError(t, err)
+
+			fullSize := int64(0)
+			for _, file := range files {
+				info, err := os.Stat(file)
+				require.NoError(t, err)
+				fullSize += info.Size()
+			}
+
+			info, err := os.Stat(path.Join(dir, deltaFile))
+			require.NoError(t, err)
+			require.Less(t, info.Size(), fullSize)
+		})
+
+		t.Run("reads base", func(t *testing.T) {
+			base, err := realWAL.CheckpointBase(path.Join(dir, nextDeltaFile))
+			require.NoError(t, err)
+			require.Equal(t, deltaFile, base)
+
+			base, err = realWAL.CheckpointBase(path.Join(dir, baseFile))
+			require.NoError(t, err)
+			require.Empty(t, base)
+		})
+
+		t.Run("reads last trie root hash", func(t *testing.T) {
+			f, err := os.Open(path.Join(dir, deltaFile))
+			require.NoError(t, err)
+			defer f.Close()
+
+			rootHash, err := realWAL.ReadLastTrieRootHashFromCheckpoint(f)
+			require.NoError(t, err)
+			require.Equal(t, deltaTries[len(deltaTries)-1].RootHash(), ledger.RootHash(rootHash))
+		})
+
+		t.Run("fails if checkpoint exists", func(t *testing.T) {
+			err := realWAL.StoreCheckpointDelta(deltaTries, baseTries, baseFile, dir, deltaFile, &logger)
+			require.Error(t, err)
+		})
+	})
+}
+
+func Test_CheckpointDeltaDetectsInvalidBase(t *testing.T) {
+	logger := zerolog.Nop()
+	baseTries := createTriesForCheckpointV6(t)
+	deltaTries := updateTries(t, baseTries)
+	baseFile := "checkpoint.00000010"
+	deltaFile := "checkpoint.00000020"
+
+	t.Run("modified delta", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			err := realWAL.StoreCheckpointV6(baseTries, dir, baseFile, &logger)
+			require.NoError(t, err)
+			err = realWAL.StoreCheckpointDelta(deltaTries, baseTries, baseFile, dir, deltaFile, &logger)
+			require.NoError(t, err)
+
+			modifyFileInTheMiddle(t, path.Join(dir, deltaFile))
+
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, deltaFile), &logger)
+			require.Error(t, err)
+			require.Nil(t, loadedTries)
+		})
+	})
+
+	t.Run("missing base", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			err := realWAL.StoreCheckpointDelta(deltaTries, baseTries, baseFile, dir, deltaFile, &logger)
+			require.NoError(t, err)
+
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, deltaFile), &logger)
+			require.Error(t, err)
+			require.Nil(t, loadedTries)
+		})
+	})
+
+	t.Run("other base", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			// the base checkpoint file contains other tries
+			err := realWAL.StoreCheckpointV6(deltaTries, dir, baseFile, &logger)
+			require.NoError(t, err)
+			err = realWAL.StoreCheckpointDelta(deltaTries, baseTries, baseFile, dir, deltaFile, &logger)
+			require.NoError(t, err)
+
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, deltaFile), &logger)
+			require.Error(t, err)
+			require.Nil(t, loadedTries)
+		})
+	})
+}
@@ -0,0 +1,1029 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	utilsio "github.com/onflow/flow-go/utils/io"
+)
+
+const (
+	// subtrieLevelV6 is number of edges from trie root to subtrie root.
+	subtrieLevelV6 = 4
+
+	// subtrieCountV6 is number of subtries at subtrieLevelV6, each stored in its own part file.
+	subtrieCountV6 = 1 << subtrieLevelV6
+
+	// partCountV6 is the number of part files, subtrie parts and the top level part.
+	partCountV6 = subtrieCountV6 + 1
+
+	encPartCountSize  = 2
+	encHasherTypeSize = 1
+)
+
+// CheckpointPartFileNames returns the names of the part files of a version 6 checkpoint with the
+// given file name: the subtrie parts, followed by the top level part.
+func CheckpointPartFileNames(fileName string) []string {
+	names := make([]string, partCountV6)
+	for i := range names {
+		names[i] = partFileName(fileName, i)
+	}
+	return names
+}
+
+// CheckpointFiles returns the version of the checkpoint stored in the given file, and the
+// paths of all files containing the checkpoint, starting with the given file.
+func CheckpointFiles(filePath string) (uint16, []string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot open checkpoint file %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	header := make([]byte, headerSize)
+	_, err = io.ReadFull(f, header)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	magicBytes := binary.BigEndian.Uint16(header)
+	version := binary.BigEndian.Uint16(header[encMagicSize:])
+	if magicBytes != MagicBytes {
+		return 0, nil, fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytes)
+	}
+
+	files := []string{filePath}
+	if version == VersionV6 || version == VersionV8 {
+		dir, fileName := filepath.Split(filePath)
+		for _, name := range CheckpointPartFileNames(fileName) {
+			files = append(files, path.Join(dir, name))
+		}
+	}
+
+	return version, files, nil
+}
+
+func partFileName(fileName string, partIndex int) string {
+	return fmt.Sprintf("%s.%03d", fileName, partIndex)
+}
+
+func topLevelPartFileName(fileName string) string {
+	return partFileName(fileName, subtrieCountV6)
+}
+
+// StoreCheckpointV6 writes the given tries to a checkpoint file (version 6) in the given directory.
+//
+// The nodes are split the same way StoreCheckpoint splits them to save memory, but each subtrie
+// part is written to its own file concurrently:
+//   - each subtrie part file contains the unique nodes of the subtries at the same path, indexed
+//     from 1 within the part (0 meaning nil), followed by the node count.
+//   - the top level part file contains the remaining unique nodes and the encoded tries, followed
+//     by the node count and the trie count. Nodes of the top level part are referenced by their
+//     global index: the nodes of all subtrie parts in order, followed by the top level nodes.
+//   - the checkpoint file contains the number of subtrie parts and the CRC32 checksums of all parts.
+//     If the tries don't use the default hasher, the checkpoint file is written with version 8
+//     and records the hasher type right after the header.
+//
+// All files start with the magic bytes and the version, and end with their CRC32 checksum.
+//
+// The checkpoint file is written last, so a checkpoint only exists once all its parts are written.
+func StoreCheckpointV6(tries []*trie.MTrie, outputDir string, outputFile string, logger *zerolog.Logger) error {
+	return writeCheckpointV6(tries, outputDir, outputFile, logger, flattener.EncodeNode)
+}
+
+// StoreCheckpointV6WithPayloadReferences is StoreCheckpointV6, which writes the keys of
+// payloads kept in a payload store (see mtrie.WithPayloadStore) instead of the payloads,
+// see flattener.EncodeNodeWithPayloadReference. The checkpoint can only be loaded with
+// the same payload store, which must keep the payloads as long as the checkpoint is kept.
+func StoreCheckpointV6WithPayloadReferences(tries []*trie.MTrie, outputDir string, outputFile string, logger *zerolog.Logger) error {
+	return writeCheckpointV6(tries, outputDir, outputFile, logger, flattener.EncodeNodeWithPayloadReference)
+}
+
+func writeCheckpointV6(tries []*trie.MTrie, outputDir string, outputFile string, logger *zerolog.Logger, encodeNode nodeEncoder) error {
+
+	fullname := path.Join(outputDir, outputFile)
+	if utilsio.FileExists(fullname) {
+		return fmt.Errorf("checkpoint file %s already exists", fullname)
+	}
+
+	// part files without checkpoint file were left by an interrupted checkpointing
+	err := removeCheckpointParts(outputDir, outputFile)
+	if err != nil {
+		return fmt.Errorf("cannot remove part files of incomplete checkpoint: %w", err)
+	}
+
+	err = storeCheckpointV6(tries, outputDir, outputFile, logger, encodeNode)
+	if err != nil {
+		cleanupErr := removeCheckpointParts(outputDir, outputFile)
+		if cleanupErr != nil {
+			logger.Warn().Err(cleanupErr).Msgf("failed to remove part files of checkpoint %s", outputFile)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func storeCheckpointV6(tries []*trie.MTrie, outputDir string, outputFile string, logger *zerolog.Logger, encodeNode nodeEncoder) error {
+
+	for _, t := range tries {
+		if t.Hasher().Type() != tries[0].Hasher().Type() {
+			return fmt.Errorf("cannot store tries hashed with %s and %s in the same checkpoint", tries[0].Hasher().Type(), t.Hasher().Type())
+		}
+	}
+
+	// subtrieRoots[i] is a list of all subtrie roots at the i-th path at subtrieLevelV6,
+	// see StoreCheckpoint for details.
+	var subtrieRoots [subtrieCountV6][]*node.Node
+	for i := 0; i < len(subtrieRoots); i++ {
+		subtrieRoots[i] = make([]*node.Node, len(tries))
+	}
+
+	for trieIndex, t := range tries {
+		subtries := getNodesAtLevel(t.RootNode(), subtrieLevelV6)
+		for subtrieIndex, subtrieRoot := range subtries {
+			subtrieRoots[subtrieIndex][trieIndex] = subtrieRoot
+		}
+	}
+
+	estimatedSubtrieNodeCount := 0
+	if len(tries) > 0 {
+		estimatedTrieNodeCount := 2*int(tries[0].AllocatedRegCount()) - 1
+		estimatedSubtrieNodeCount = estimatedTrieNodeCount / subtrieCountV6
+	}
+
+	var subtrieNodeCounts [subtrieCountV6]uint64
+	var subtrieRootIndices [subtrieCountV6]map[*node.Node]uint64
+	var checksums [partCountV6]uint32
+
+	var group errgroup.Group
+	for i := 0; i < subtrieCountV6; i++ {
+		i := i
+		group.Go(func() error {
+			nodeCount, rootIndices, checksum, err := storeSubtriePart(
+				outputDir,
+				partFileName(outputFile, i),
+				subtrieRoots[i],
+				estimatedSubtrieNodeCount,
+				logger,
+				encodeNode,
+			)
+			if err != nil {
+				return fmt.Errorf("cannot store subtrie part %d: %w", i, err)
+			}
+
+			subtrieNodeCounts[i] = nodeCount
+			subtrieRootIndices[i] = rootIndices
+			checksums[i] = checksum
+			return nil
+		})
+	}
+
+	err := group.Wait()
+	if err != nil {
+		return err
+	}
+
+	// topLevelNodes contains the global index of all subtrie roots, so that
+	// they are skipped when serializing the top level nodes.
+	topLevelNodes := make(map[*node.Node]uint64, 1<<(subtrieLevelV6+1))
+	topLevelNodes[nil] = 0
+
+	subtrieNodeCount := uint64(0)
+	for i := 0; i < subtrieCountV6; i++ {
+		for root, index := range subtrieRootIndices[i] {
+			topLevelNodes[root] = subtrieNodeCount + index
+		}
+		subtrieNodeCount += subtrieNodeCounts[i]
+	}
+
+	checksums[subtrieCountV6], err = storeTopLevelPart(
+		outputDir,
+		topLevelPartFileName(outputFile),
+		tries,
+		topLevelNodes,
+		subtrieNodeCount,
+		logger,
+		encodeNode,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot store top level part: %w", err)
+	}
+
+	hasherType := ledger.DefaultTrieHasher.Type()
+	if len(tries) > 0 {
+		hasherType = tries[0].Hasher().Type()
+	}
+
+	return storeCheckpointHeaderV6(outputDir, outputFile, checksums[:], hasherType, logger)
+}
+
+// storeSubtriePart writes the unique nodes of the given subtries to a part file, and returns the
+// number of nodes, the index of each subtrie root within the part, and the checksum of the part.
+func storeSubtriePart(
+	outputDir string,
+	fileName string,
+	roots []*node.Node,
+	estimatedNodeCount int,
+	logger *zerolog.Logger,
+	encodeNode nodeEncoder,
+) (nodeCount uint64, rootIndices map[*node.Node]uint64, checksum uint32, err error) {
+
+	writer, err := CreateCheckpointWriterForFile(outputDir, fileName, logger)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("cannot create part file writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	crc32Writer := NewCRC32Writer(writer)
+	scratch := make([]byte, 1024*4)
+
+	err = writeCheckpointHeader(crc32Writer, scratch, VersionV6)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	// visitedNodes contains all unique nodes of the subtries and their index.
+	// Index 0 is a special case with nil node.
+	visitedNodes := make(map[*node.Node]uint64, estimatedNodeCount)
+	visitedNodes[nil] = 0
+
+	rootIndices = make(map[*node.Node]uint64, len(roots))
+
+	nodeCounter := uint64(1)
+	for _, root := range roots {
+		if root == nil {
+			continue
+		}
+		nodeCounter, err = storeUniqueNodes(root, visitedNodes, nodeCounter, scratch, crc32Writer, encodeNode)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		rootIndices[root] = visitedNodes[root]
+	}
+
+	nodeCount = nodeCounter - 1 // -1 to account for 0 node meaning nil
+
+	footer := scratch[:encNodeCountSize]
+	binary.BigEndian.PutUint64(footer, nodeCount)
+
+	_, err = crc32Writer.Write(footer)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("cannot write part footer: %w", err)
+	}
+
+	checksum, err = writeChecksum(writer, crc32Writer, scratch)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	return nodeCount, rootIndices, checksum, nil
+}
+
+// storeTopLevelPart writes the nodes from the trie roots to the subtrie roots and the tries
+// to the top level part file, and returns the checksum of the part.
+func storeTopLevelPart(
+	outputDir string,
+	fileName string,
+	tries []*trie.MTrie,
+	topLevelNodes map[*node.Node]uint64,
+	subtrieNodeCount uint64,
+	logger *zerolog.Logger,
+	encodeNode nodeEncoder,
+) (checksum uint32, err error) {
+
+	writer, err := CreateCheckpointWriterForFile(outputDir, fileName, logger)
+	if err != nil {
+		return 0, fmt.Errorf("cannot create part file writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	crc32Writer := NewCRC32Writer(writer)
+	scratch := make([]byte, 1024*4)
+
+	err = writeCheckpointHeader(crc32Writer, scratch, VersionV6)
+	if err != nil {
+		return 0, err
+	}
+
+	// top level nodes are indexed after the nodes of all subtrie parts.
+	nodeCounter := subtrieNodeCount + 1
+	for _, t := range tries {
+		root := t.RootNode()
+		if root == nil {
+			continue
+		}
+		nodeCounter, err = storeUniqueNodes(root, topLevelNodes, nodeCounter, scratch, crc32Writer, encodeNode)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, t := range tries {
+		rootIndex, found := topLevelNodes[t.RootNode()]
+		if !found {
+			rootHash := t.RootHash()
+			return 0, fmt.Errorf("internal error: missing node with hash %s", hex.EncodeToString(rootHash[:]))
+		}
+
+		encTrie := flattener.EncodeTrie(t, rootIndex, scratch)
+		_, err = crc32Writer.Write(encTrie)
+		if err != nil {
+			return 0, fmt.Errorf("cannot serialize trie: %w", err)
+		}
+	}
+
+	footer := scratch[:encNodeCountSize+encTrieCountSize]
+	binary.BigEndian.PutUint64(footer, nodeCounter-1-subtrieNodeCount)
+	binary.BigEndian.PutUint16(footer[encNodeCountSize:], uint16(len(tries)))
+
+	_, err = crc32Writer.Write(footer)
+	if err != nil {
+		return 0, fmt.Errorf("cannot write part footer: %w", err)
+	}
+
+	return writeChecksum(writer, crc32Writer, scratch)
+}
+
+// storeCheckpointHeaderV6 writes the checkpoint file, listing the checksums of all parts.
+// The checkpoint file is written with version 8 if the hasher isn't the default one.
+func storeCheckpointHeaderV6(outputDir string, outputFile string, checksums []uint32, hasherType hash.HasherType, logger *zerolog.Logger) (err error) {
+	writer, err := CreateCheckpointWriterForFile(outputDir, outputFile, logger)
+	if err != nil {
+		return fmt.Errorf("cannot create checkpoint writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	crc32Writer := NewCRC32Writer(writer)
+	scratch := make([]byte, 1024)
+
+	version := VersionV6
+	if hasherType != ledger.DefaultTrieHasher.Type() {
+		version = VersionV8
+	}
+
+	err = writeCheckpointHeader(crc32Writer, scratch, version)
+	if err != nil {
+		return err
+	}
+
+	if version == VersionV8 {
+		buf := scratch[:encHasherTypeSize]
+		buf[0] = byte(hasherType)
+		_, err = crc32Writer.Write(buf)
+		if err != nil {
+			return fmt.Errorf("cannot write hasher type: %w", err)
+		}
+	}
+
+	buf := scratch[:encPartCountSize]
+	binary.BigEndian.PutUint16(buf, subtrieCountV6)
+	_, err = crc32Writer.Write(buf)
+	if err != nil {
+		return fmt.Errorf("cannot write part count: %w", err)
+	}
+
+	for _, checksum := range checksums {
+		buf := scratch[:crc32SumSize]
+		binary.BigEndian.PutUint32(buf, checksum)
+		_, err = crc32Writer.Write(buf)
+		if err != nil {
+			return fmt.Errorf("cannot write part checksum: %w", err)
+		}
+	}
+
+	_, err = writeChecksum(writer, crc32Writer, scratch)
+	return err
+}
+
+func writeCheckpointHeader(writer io.Writer, scratch []byte, version uint16) error {
+	header := scratch[:headerSize]
+	binary.BigEndian.PutUint16(header, MagicBytes)
+	binary.BigEndian.PutUint16(header[encMagicSize:], version)
+
+	_, err := writer.Write(header)
+	if err != nil {
+		return fmt.Errorf("cannot write checkpoint header: %w", err)
+	}
+	return nil
+}
+
+// writeChecksum writes the CRC32 sum of the data written to crc32Writer to writer, and returns it.
+func writeChecksum(writer io.Writer, crc32Writer *Crc32Writer, scratch []byte) (uint32, error) {
+	checksum := crc32Writer.Crc32()
+
+	crc32buf := scratch[:crc32SumSize]
+	binary.BigEndian.PutUint32(crc32buf, checksum)
+
+	_, err := writer.Write(crc32buf)
+	if err != nil {
+		return 0, fmt.Errorf("cannot write CRC32: %w", err)
+	}
+	return checksum, nil
+}
+
+// removeCheckpointParts removes the part files of the given checkpoint, if they exist.
+func removeCheckpointParts(dir string, fileName string) error {
+	for _, name := range CheckpointPartFileNames(fileName) {
+		err := os.Remove(path.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCheckpointHeaderV6 decodes the checkpoint file (version 6 or 8) and returns the checksums of
+// all parts, subtrie parts first, and the hasher of the tries.
+func readCheckpointHeaderV6(f *os.File) ([]uint32, *ledger.TrieHasher, error) {
+	data, err := io.ReadAll(bufio.NewReaderSize(f, defaultBufioReadSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read checkpoint header: %w", err)
+	}
+
+	if len(data) < headerSize+encPartCountSize+crc32SumSize {
+		return nil, nil, fmt.Errorf("checkpoint header is too short: %d bytes", len(data))
+	}
+
+	content := data[:len(data)-crc32SumSize]
+
+	readCrc32 := binary.BigEndian.Uint32(data[len(content):])
+	calculatedCrc32 := crc32.Checksum(content, crc32Table)
+	if calculatedCrc32 != readCrc32 {
+		return nil, nil, fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+	}
+
+	hasher := ledger.DefaultTrieHasher
+	offset := headerSize
+	if binary.BigEndian.Uint16(content[encMagicSize:]) == VersionV8 {
+		if len(content) < offset+encHasherTypeSize+encPartCountSize {
+			return nil, nil, fmt.Errorf("checkpoint header is too short: %d bytes", len(data))
+		}
+		hasherType := hash.HasherType(content[offset])
+		hasher, err = ledger.NewTrieHasher(hasherType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create hasher of checkpoint: %w", err)
+		}
+		offset += encHasherTypeSize
+	}
+
+	subtrieCount := binary.BigEndian.Uint16(content[offset:])
+	if subtrieCount != subtrieCountV6 {
+		return nil, nil, fmt.Errorf("unsupported subtrie part count %d, expected %d", subtrieCount, subtrieCountV6)
+	}
+
+	encChecksums := content[offset+encPartCountSize:]
+	if len(encChecksums) != partCountV6*crc32SumSize {
+		return nil, nil, fmt.Errorf("checkpoint header contains %d bytes of part checksums, expected %d", len(encChecksums), partCountV6*crc32SumSize)
+	}
+
+	checksums := make([]uint32, partCountV6)
+	for i := range checksums {
+		checksums[i] = binary.BigEndian.Uint32(encChecksums[i*crc32SumSize:])
+	}
+
+	return checksums, hasher, nil
+}
+
+// readCheckpointV6 decodes checkpoint file (version 6 or 8) and its part files, and returns a list of tries.
+// Subtrie parts are read concurrently, and payloads are moved into the given store, if it isn't nil.
+// Checkpoint file header (magic and version) are verified by the caller.
+func readCheckpointV6(f *os.File, logger *zerolog.Logger, store node.PayloadStore) ([]*trie.MTrie, error) {
+	checksums, hasher, err := readCheckpointHeaderV6(f)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, fileName := filepath.Split(f.Name())
+
+	var subtrieNodes [subtrieCountV6][]*node.Node
+
+	var group errgroup.Group
+	for i := 0; i < subtrieCountV6; i++ {
+		i := i
+		group.Go(func() error {
+			nodes, err := readSubtriePart(path.Join(dir, partFileName(fileName, i)), checksums[i], store, logger)
+			if err != nil {
+				return fmt.Errorf("cannot read subtrie part %d: %w", i, err)
+			}
+			subtrieNodes[i] = nodes
+			return nil
+		})
+	}
+
+	err = group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	tries, err := readTopLevelPart(
+		path.Join(dir, topLevelPartFileName(fileName)),
+		checksums[subtrieCountV6],
+		subtrieNodes[:],
+		hasher,
+		store,
+		logger,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read top level part: %w", err)
+	}
+
+	return tries, nil
+}
+
+// openCheckpointPart opens the given part file, verifies its header, and returns the file and
+// the footer of the given size.
+func openCheckpointPart(filePath string, footerSize int) (*os.File, []byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open part file %s: %w", filePath, err)
+	}
+
+	header := make([]byte, headerSize)
+	_, err = io.ReadFull(f, header)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	magicBytes := binary.BigEndian.Uint16(header)
+	version := binary.BigEndian.Uint16(header[encMagicSize:])
+	if magicBytes != MagicBytes {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytes)
+	}
+	if version != VersionV6 {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("unsupported part file version %x", version)
+	}
+
+	_, err = f.Seek(-int64(footerSize+crc32SumSize), io.SeekEnd)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("cannot seek to footer: %w", err)
+	}
+
+	footer := make([]byte, footerSize)
+	_, err = io.ReadFull(f, footer)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	// Seek to the start of the nodes, the header is not read again but included in checksum.
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	return f, footer, nil
+}
+
+func closeCheckpointPart(f *os.File, logger *zerolog.Logger) {
+	evictErr := evictFileFromLinuxPageCache(f, false, logger)
+	if evictErr != nil {
+		logger.Warn().Msgf("failed to evict file %s from Linux page cache: %s", f.Name(), evictErr)
+		// No need to return this error because it's possible to continue normal operations.
+	}
+
+	_ = f.Close()
+}
+
+// verifyPartChecksum reads the CRC32 sum at the end of a part and compares it with the
+// calculated checksum, and the checksum listed in the checkpoint file.
+func verifyPartChecksum(reader io.Reader, crcReader *Crc32Reader, expectedChecksum uint32, scratch []byte) error {
+	crc32buf := scratch[:crc32SumSize]
+	_, err := io.ReadFull(reader, crc32buf)
+	if err != nil {
+		return fmt.Errorf("cannot read CRC32: %w", err)
+	}
+
+	readCrc32 := binary.BigEndian.Uint32(crc32buf)
+	calculatedCrc32 := crcReader.Crc32()
+
+	if calculatedCrc32 != readCrc32 {
+		return fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+	}
+	if readCrc32 != expectedChecksum {
+		return fmt.Errorf("checkpoint checksum failed! Checkpoint header lists %x but part contains %x", expectedChecksum, readCrc32)
+	}
+
+	// the checksum must be the end of the part
+	_, err = reader.Read(crc32buf[:1])
+	if !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unexpected data after CRC32")
+	}
+
+	return nil
+}
+
+// readSubtriePart decodes a subtrie part file, and returns its nodes by index.
+// Element at index 0 is nil. Payloads are moved into the given store, if it isn't nil.
+func readSubtriePart(filePath string, expectedChecksum uint32, store node.PayloadStore, logger *zerolog.Logger) ([]*node.Node, error) {
+
+	f, footer, err := openCheckpointPart(filePath, encNodeCountSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCheckpointPart(f, logger)
+
+	nodesCount := binary.BigEndian.Uint64(footer)
+
+	// Scratch buffer is used as temporary buffer that reader can read into,
+	// see readCheckpointV5 for details.
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+
+	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(bufReader)
+	var reader io.Reader = crcReader
+
+	// Header is verified by openCheckpointPart.
+	_, err = io.ReadFull(reader, scratch[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	// nodes's element at index 0 is a special, meaning nil.
+	nodes := make([]*node.Node, nodesCount+1) //+1 for 0 index meaning nil
+
+	payloads := newPayloadBatch(store)
+	for i := uint64(1); i <= nodesCount; i++ {
+		n, err := flattener.ReadNodeWithPayloadBatch(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+			if nodeIndex >= i {
+				return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+			}
+			return nodes[nodeIndex], nil
+		}, payloads)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		nodes[i] = n
+	}
+
+	err = flushPayloadBatch(payloads)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read footer again for crc32 computation
+	_, err = io.ReadFull(reader, scratch[:encNodeCountSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	err = verifyPartChecksum(bufReader, crcReader, expectedChecksum, scratch)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// readTopLevelPart decodes the top level part file, resolving references to the nodes
+// of the given subtrie parts, and returns the tries using the given hasher.
+func readTopLevelPart(
+	filePath string,
+	expectedChecksum uint32,
+	subtrieNodes [][]*node.Node,
+	hasher *ledger.TrieHasher,
+	store node.PayloadStore,
+	logger *zerolog.Logger,
+) ([]*trie.MTrie, error) {
+
+	const footerSize = encNodeCountSize + encTrieCountSize
+
+	f, footer, err := openCheckpointPart(filePath, footerSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCheckpointPart(f, logger)
+
+	nodesCount := binary.BigEndian.Uint64(footer)
+	triesCount := binary.BigEndian.Uint16(footer[encNodeCountSize:])
+
+	// subtrieOffsets[i] is the global index of the last node of all subtrie parts before part i.
+	subtrieOffsets := make([]uint64, len(subtrieNodes)+1)
+	for i, nodes := range subtrieNodes {
+		subtrieOffsets[i+1] = subtrieOffsets[i] + uint64(len(nodes)-1)
+	}
+	subtrieNodeCount := subtrieOffsets[len(subtrieNodes)]
+
+	topLevelNodes := make([]*node.Node, nodesCount+1) //+1 for 0 index meaning nil
+
+	// getNode returns the node with the given global index.
+	getNode := func(nodeIndex uint64) (*node.Node, error) {
+		if nodeIndex > subtrieNodeCount {
+			topLevelIndex := nodeIndex - subtrieNodeCount
+			if topLevelIndex >= uint64(len(topLevelNodes)) {
+				return nil, fmt.Errorf("sequence of stored nodes doesn't contain node")
+			}
+			return topLevelNodes[topLevelIndex], nil
+		}
+		if nodeIndex == 0 {
+			return nil, nil
+		}
+
+		// find the subtrie part containing the node
+		part := sort.Search(len(subtrieNodes), func(i int) bool {
+			return subtrieOffsets[i+1] >= nodeIndex
+		})
+		return subtrieNodes[part][nodeIndex-subtrieOffsets[part]], nil
+	}
+
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+
+	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(bufReader)
+	var reader io.Reader = crcReader
+
+	// Header is verified by openCheckpointPart.
+	_, err = io.ReadFull(reader, scratch[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	payloads := newPayloadBatch(store)
+	for i := uint64(1); i <= nodesCount; i++ {
+		globalIndex := subtrieNodeCount + i
+		n, err := flattener.ReadNodeWithPayloadBatch(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+			if nodeIndex >= globalIndex {
+				return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+			}
+			return getNode(nodeIndex)
+		}, payloads)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		topLevelNodes[i] = n
+	}
+
+	err = flushPayloadBatch(payloads)
+	if err != nil {
+		return nil, err
+	}
+
+	tries := make([]*trie.MTrie, triesCount)
+	for i := uint16(0); i < triesCount; i++ {
+		trie, err := flattener.ReadTrieWithHasher(reader, scratch, getNode, hasher)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read trie %d: %w", i, err)
+		}
+		tries[i] = trie
+	}
+
+	// Read footer again for crc32 computation
+	_, err = io.ReadFull(reader, scratch[:footerSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	err = verifyPartChecksum(bufReader, crcReader, expectedChecksum, scratch)
+	if err != nil {
+		return nil, err
+	}
+
+	return tries, nil
+}
+
+// ReadCheckpointV6Payloads reads the payloads of a checkpoint file (version 6 or 8) containing
+// a single trie with the given root hash, and passes them to the given function as they are
+// read, part by part. Unlike LoadCheckpoint, the trie isn't restored, so the memory used doesn't
+// grow with the size of the trie.
+// The checksum of a part is verified after its payloads were passed to the function, so the
+// payloads must be discarded if an error is returned.
+func ReadCheckpointV6Payloads(filePath string, rootHash ledger.RootHash, logger *zerolog.Logger, processPayload func(*ledger.Payload) error) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot open checkpoint file %s: %w", filePath, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	header := make([]byte, headerSize)
+	_, err = io.ReadFull(f, header)
+	if err != nil {
+		return fmt.Errorf("cannot read header: %w", err)
+	}
+
+	magicBytes := binary.BigEndian.Uint16(header)
+	version := binary.BigEndian.Uint16(header[encMagicSize:])
+	if magicBytes != MagicBytes {
+		return fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytes)
+	}
+	if version != VersionV6 && version != VersionV8 {
+		return fmt.Errorf("unsupported file version %x, payloads can only be read from version 6 and 8 checkpoints", version)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	checksums, _, err := readCheckpointHeaderV6(f)
+	if err != nil {
+		return err
+	}
+
+	dir, fileName := filepath.Split(f.Name())
+	topLevelFilePath := path.Join(dir, topLevelPartFileName(fileName))
+
+	// The tries are listed at the end of the top level part, so they are checked before any
+	// payload is read, and verified again with the checksum of the part.
+	err = checkSingleTrieV6(topLevelFilePath, rootHash, logger)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < subtrieCountV6; i++ {
+		err = readSubtriePartPayloads(path.Join(dir, partFileName(fileName, i)), checksums[i], processPayload, logger)
+		if err != nil {
+			return fmt.Errorf("cannot read payloads of subtrie part %d: %w", i, err)
+		}
+	}
+
+	err = readTopLevelPartPayloads(topLevelFilePath, checksums[subtrieCountV6], rootHash, processPayload, logger)
+	if err != nil {
+		return fmt.Errorf("cannot read payloads of top level part: %w", err)
+	}
+
+	return nil
+}
+
+// checkSingleTrieV6 checks that the given top level part file contains a single trie with the
+// given root hash, without verifying the checksum of the part.
+func checkSingleTrieV6(filePath string, rootHash ledger.RootHash, logger *zerolog.Logger) error {
+	// the root hash of the last trie is encoded right before the footer
+	f, footer, err := openCheckpointPart(filePath, hash.HashLen+encNodeCountSize+encTrieCountSize)
+	if err != nil {
+		return err
+	}
+	defer closeCheckpointPart(f, logger)
+
+	triesCount := binary.BigEndian.Uint16(footer[hash.HashLen+encNodeCountSize:])
+	if triesCount != 1 {
+		return fmt.Errorf("checkpoint contains %d tries, expected a single trie", triesCount)
+	}
+
+	lastRootHash, err := hash.ToHash(footer[:hash.HashLen])
+	if err != nil {
+		return fmt.Errorf("cannot decode root hash of trie: %w", err)
+	}
+	if !rootHash.Equals(ledger.RootHash(lastRootHash)) {
+		return fmt.Errorf("checkpoint contains trie with root hash %s, expected %s", ledger.RootHash(lastRootHash), rootHash)
+	}
+
+	return nil
+}
+
+// readSubtriePartPayloads decodes a subtrie part file, and passes the payloads of its leaf nodes
+// to the given function.
+func readSubtriePartPayloads(filePath string, expectedChecksum uint32, processPayload func(*ledger.Payload) error, logger *zerolog.Logger) error {
+
+	f, footer, err := openCheckpointPart(filePath, encNodeCountSize)
+	if err != nil {
+		return err
+	}
+	defer closeCheckpointPart(f, logger)
+
+	nodesCount := binary.BigEndian.Uint64(footer)
+
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+
+	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(bufReader)
+	var reader io.Reader = crcReader
+
+	// Header is verified by openCheckpointPart.
+	_, err = io.ReadFull(reader, scratch[:headerSize])
+	if err != nil {
+		return fmt.Errorf("cannot read header: %w", err)
+	}
+
+	err = readNodePayloads(reader, scratch, nodesCount, processPayload)
+	if err != nil {
+		return err
+	}
+
+	// Read footer again for crc32 computation
+	_, err = io.ReadFull(reader, scratch[:encNodeCountSize])
+	if err != nil {
+		return fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	return verifyPartChecksum(bufReader, crcReader, expectedChecksum, scratch)
+}
+
+// readTopLevelPartPayloads decodes the top level part file, which must contain a single trie with
+// the given root hash, and passes the payloads of its leaf nodes to the given function.
+func readTopLevelPartPayloads(
+	filePath string,
+	expectedChecksum uint32,
+	rootHash ledger.RootHash,
+	processPayload func(*ledger.Payload) error,
+	logger *zerolog.Logger,
+) error {
+
+	const footerSize = encNodeCountSize + encTrieCountSize
+
+	f, footer, err := openCheckpointPart(filePath, footerSize)
+	if err != nil {
+		return err
+	}
+	defer closeCheckpointPart(f, logger)
+
+	nodesCount := binary.BigEndian.Uint64(footer)
+	triesCount := binary.BigEndian.Uint16(footer[encNodeCountSize:])
+	if triesCount != 1 {
+		return fmt.Errorf("checkpoint contains %d tries, expected a single trie", triesCount)
+	}
+
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+
+	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(bufReader)
+	var reader io.Reader = crcReader
+
+	// Header is verified by openCheckpointPart.
+	_, err = io.ReadFull(reader, scratch[:headerSize])
+	if err != nil {
+		return fmt.Errorf("cannot read header: %w", err)
+	}
+
+	err = readNodePayloads(reader, scratch, nodesCount, processPayload)
+	if err != nil {
+		return err
+	}
+
+	readRootHash, err := flattener.ReadTrieRootHash(reader, scratch)
+	if err != nil {
+		return fmt.Errorf("cannot read trie: %w", err)
+	}
+	if !rootHash.Equals(readRootHash) {
+		return fmt.Errorf("checkpoint contains trie with root hash %s, expected %s", readRootHash, rootHash)
+	}
+
+	// Read footer again for crc32 computation
+	_, err = io.ReadFull(reader, scratch[:footerSize])
+	if err != nil {
+		return fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	return verifyPartChecksum(bufReader, crcReader, expectedChecksum, scratch)
+}
+
+// readNodePayloads reads the given number of serialized nodes from reader, and passes the payloads
+// of the leaf nodes to the given function.
+func readNodePayloads(reader io.Reader, scratch []byte, nodesCount uint64, processPayload func(*ledger.Payload) error) error {
+	for i := uint64(1); i <= nodesCount; i++ {
+		payload, err := flattener.ReadLeafPayload(reader, scratch)
+		if err != nil {
+			return fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		if payload == nil {
+			continue
+		}
+
+		err = processPayload(payload)
+		if err != nil {
+			return fmt.Errorf("cannot process payload of node %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package wal_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/payloadstore"
+	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func createTriesForCheckpointV6(t *testing.T) []*trie.MTrie {
+	return createTriesForCheckpointV6WithHasher(t, ledger.DefaultTrieHasher)
+}
+
+func createTriesForCheckpointV6WithHasher(t *testing.T, hasher *ledger.TrieHasher) []*trie.MTrie {
+	emptyTrie := trie.NewEmptyMTrieWithHasher(hasher)
+
+	tries := []*trie.MTrie{emptyTrie}
+
+	// a trie with a single leaf above the subtrie level
+	paths := []ledger.Path{testutils.PathByUint8(0)}
+	payloads := []ledger.Payload{*testutils.LightPayload8('A', 'a')}
+
+	singleLeafTrie, _, err := trie.NewTrieWithUpdatedRegisters(emptyTrie, paths, payloads, true)
+	require.NoError(t, err)
+	tries = append(tries, singleLeafTrie)
+
+	// tries sharing nodes, with leaves in all subtries
+	paths = testutils.RandomPaths(200)
+	payloads = randomPayloadsForCheckpointV6(len(paths))
+
+	randomTrie, _, err := trie.NewTrieWithUpdatedRegisters(singleLeafTrie, paths, payloads, true)
+	require.NoError(t, err)
+	tries = append(tries, randomTrie)
+
+	paths = paths[:50]
+	payloads = randomPayloadsForCheckpointV6(len(paths))
+
+	updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(randomTrie, paths, payloads, true)
+	require.NoError(t, err)
+	tries = append(tries, updatedTrie)
+
+	return tries
+}
+
+func randomPayloadsForCheckpointV6(n int) []ledger.Payload {
+	payloads := make([]ledger.Payload, n)
+	for i, p := range testutils.RandomPayloads(n, 1, 100) {
+		payloads[i] = *p
+	}
+	return payloads
+}
+
+func modifyFileInTheMiddle(t *testing.T, filename string) {
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	// byte addition will simply wrap around
+	b[len(b)/2]++
+
+	err = os.WriteFile(filename, b, 0644)
+	require.NoError(t, err)
+}
+
+// requireLeavesStored requires the payloads of all leaves of the given subtrie to be kept in a payload store
+func requireLeavesStored(t *testing.T, n *node.Node) {
+	if n == nil {
+		return
+	}
+	if n.IsLeaf() {
+		require.True(t, n.IsPayloadStored())
+		return
+	}
+	requireLeavesStored(t, n.LeftChild())
+	requireLeavesStored(t, n.RightChild())
+}
+
+func Test_StoringLoadingCheckpointV6(t *testing.T) {
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		tries := createTriesForCheckpointV6(t)
+		logger := zerolog.Nop()
+
+		fileName := "checkpoint.00000010"
+		err := realWAL.StoreCheckpointV6(tries, dir, fileName, &logger)
+		require.NoError(t, err)
+
+		require.FileExists(t, path.Join(dir, fileName))
+		for _, name := range realWAL.CheckpointPartFileNames(fileName) {
+			require.FileExists(t, path.Join(dir, name))
+		}
+
+		t.Run("loads tries", func(t *testing.T) {
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, fileName), &logger)
+			require.NoError(t, err)
+			require.Equal(t, len(tries), len(loadedTries))
+			for i := range tries {
+				require.Equal(t, tries[i].RootHash(), loadedTries[i].RootHash())
+				require.Equal(t, tries[i].AllocatedRegCount(), loadedTries[i].AllocatedRegCount())
+				require.Equal(t, tries[i].AllocatedRegSize(), loadedTries[i].AllocatedRegSize())
+			}
+		})
+
+		t.Run("loads payloads into payload store", func(t *testing.T) {
+			unittest.RunWithTempDir(t, func(storeDir string) {
+				store, err := payloadstore.Open(storeDir, 10)
+				require.NoError(t, err)
+				defer store.Close()
+
+				loadedTries, err := realWAL.LoadCheckpointWithPayloadStore(path.Join(dir, fileName), &logger, store)
+				require.NoError(t, err)
+				require.Equal(t, len(tries), len(loadedTries))
+				for i := range tries {
+					require.Equal(t, tries[i].RootHash(), loadedTries[i].RootHash())
+					requireLeavesStored(t, loadedTries[i].RootNode())
+
+					expected, err := tries[i].AllPayloads()
+					require.NoError(t, err)
+					loaded, err := loadedTries[i].AllPayloads()
+					require.NoError(t, err)
+					require.Equal(t, expected, loaded)
+				}
+
+				// stored payloads are copied to new checkpoints
+				err = realWAL.StoreCheckpointV6(loadedTries, dir, "checkpoint.00000011", &logger)
+				require.NoError(t, err)
+
+				reloadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000011"), &logger)
+				require.NoError(t, err)
+				for i := range tries {
+					require.Equal(t, tries[i].RootHash(), reloadedTries[i].RootHash())
+				}
+			})
+		})
+
+		t.Run("references payloads of payload store", func(t *testing.T) {
+			unittest.RunWithTempDir(t, func(storeDir string) {
+				store, err := payloadstore.Open(storeDir, 10)
+				require.NoError(t, err)
+				defer store.Close()
+
+				storedTries, err := realWAL.LoadCheckpointWithPayloadStore(path.Join(dir, fileName), &logger, store)
+				require.NoError(t, err)
+
+				err = realWAL.StoreCheckpointV6WithPayloadReferences(storedTries, dir, "checkpoint.00000012", &logger)
+				require.NoError(t, err)
+
+				// payloads aren't copied to the checkpoint
+				_, err = realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000012"), &logger)
+				require.Error(t, err)
+
+				loadedTries, err := realWAL.LoadCheckpointWithPayloadStore(path.Join(dir, "checkpoint.00000012"), &logger, store)
+				require.NoError(t, err)
+				require.Equal(t, len(tries), len(loadedTries))
+				for i := range tries {
+					require.Equal(t, tries[i].RootHash(), loadedTries[i].RootHash())
+					requireLeavesStored(t, loadedTries[i].RootNode())
+
+					expected, err := tries[i].AllPayloads()
+					require.NoError(t, err)
+					loaded, err := loadedTries[i].AllPayloads()
+					require.NoError(t, err)
+					require.Equal(t, expected, loaded)
+				}
+			})
+		})
+
+		t.Run("reads last trie root hash", func(t *testing.T) {
+			f, err := os.Open(path.Join(dir, fileName))
+			require.NoError(t, err)
+			defer f.Close()
+
+			rootHash, err := realWAL.ReadLastTrieRootHashFromCheckpoint(f)
+			require.NoError(t, err)
+			require.Equal(t, tries[len(tries)-1].RootHash(), ledger.RootHash(rootHash))
+		})
+
+		t.Run("fails if checkpoint exists", func(t *testing.T) {
+			err := realWAL.StoreCheckpointV6(tries, dir, fileName, &logger)
+			require.Error(t, err)
+		})
+	})
+}
+
+func Test_ReadingPayloadsFromCheckpointV6(t *testing.T) {
+	tries := createTriesForCheckpointV6(t)
+	logger := zerolog.Nop()
+	fileName := "checkpoint.00000010"
+
+	readPayloads := func(dir string, rootHash ledger.RootHash) ([]ledger.Payload, error) {
+		var payloads []ledger.Payload
+		err := realWAL.ReadCheckpointV6Payloads(path.Join(dir, fileName), rootHash, &logger, func(payload *ledger.Payload) error {
+			payloads = append(payloads, *payload)
+			return nil
+		})
+		return payloads, err
+	}
+
+	// the single leaf trie has a leaf in the top level part, the others in subtrie parts
+	for _, tr := range tries[1:] {
+		tr := tr
+		t.Run(tr.RootHash().String(), func(t *testing.T) {
+			unittest.RunWithTempDir(t, func(dir string) {
+				err := realWAL.StoreCheckpointV6([]*trie.MTrie{tr}, dir, fileName, &logger)
+				require.NoError(t, err)
+
+				expected, err := tr.AllPayloads()
+				require.NoError(t, err)
+
+				payloads, err := readPayloads(dir, tr.RootHash())
+				require.NoError(t, err)
+				require.ElementsMatch(t, expected, payloads)
+
+				_, err = readPayloads(dir, tries[0].RootHash())
+				require.Error(t, err)
+			})
+		})
+	}
+
+	t.Run("multiple tries", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			err := realWAL.StoreCheckpointV6(tries, dir, fileName, &logger)
+			require.NoError(t, err)
+
+			payloads, err := readPayloads(dir, tries[len(tries)-1].RootHash())
+			require.Error(t, err)
+			require.Empty(t, payloads)
+		})
+	})
+
+	t.Run("modified part", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			tr := tries[len(tries)-1]
+			err := realWAL.StoreCheckpointV6([]*trie.MTrie{tr}, dir, fileName, &logger)
+			require.NoError(t, err)
+
+			modifyFileInTheMiddle(t, path.Join(dir, realWAL.CheckpointPartFileNames(fileName)[3]))
+
+			_, err = readPayloads(dir, tr.RootHash())
+			require.Error(t, err)
+		})
+	})
+}
+
+func Test_CheckpointV6DetectsModifiedParts(t *testing.T) {
+	tries := createTriesForCheckpointV6(t)
+	logger := zerolog.Nop()
+	fileName := "checkpoint.00000010"
+
+	for _, name := range append([]string{fileName}, realWAL.CheckpointPartFileNames(fileName)...) {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			unittest.RunWithTempDir(t, func(dir string) {
+				err := realWAL.StoreCheckpointV6(tries, dir, fileName, &logger)
+				require.NoError(t, err)
+
+				modifyFileInTheMiddle(t, path.Join(dir, name))
+
+				loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, fileName), &logger)
+				require.Error(t, err)
+				require.Nil(t, loadedTries)
+			})
+		})
+	}
+
+	t.Run("missing part", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			err := realWAL.StoreCheckpointV6(tries, dir, fileName, &logger)
+			require.NoError(t, err)
+
+			err = os.Remove(path.Join(dir, realWAL.CheckpointPartFileNames(fileName)[3]))
+			require.NoError(t, err)
+
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, fileName), &logger)
+			require.Error(t, err)
+			require.Nil(t, loadedTries)
+		})
+	})
+}
+
+func Test_StoringLoadingCheckpointWithHasher(t *testing.T) {
+	hasher, err := ledger.NewTrieHasher(hash.BLAKE3)
+	require.NoError(t, err)
+
+	logger := zerolog.Nop()
+	fileName := "checkpoint.00000010"
+
+	t.Run("default hasher is stored with version 6", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			err := realWAL.StoreCheckpointV6(createTriesForCheckpointV6(t), dir, fileName, &logger)
+			require.NoError(t, err)
+
+			version, _, err := realWAL.CheckpointFiles(path.Join(dir, fileName))
+			require.NoError(t, err)
+			require.Equal(t, realWAL.VersionV6, version)
+		})
+	})
+
+	t.Run("other hasher is stored with version 8", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			tries := createTriesForCheckpointV6WithHasher(t, hasher)
+
+			err := realWAL.StoreCheckpointV6(tries, dir, fileName, &logger)
+			require.NoError(t, err)
+
+			version, files, err := realWAL.CheckpointFiles(path.Join(dir, fileName))
+			require.NoError(t, err)
+			require.Equal(t, realWAL.VersionV8, version)
+			require.Len(t, files, 1+len(realWAL.CheckpointPartFileNames(fileName)))
+
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, fileName), &logger)
+			require.NoError(t, err)
+			require.Equal(t, len(tries), len(loadedTries))
+			for i := range tries {
+				require.Equal(t, hash.BLAKE3, loadedTries[i].Hasher().Type())
+				require.Equal(t, tries[i].RootHash(), loadedTries[i].RootHash())
+				require.True(t, loadedTries[i].IsAValidTrie())
+			}
+
+			f, err := os.Open(path.Join(dir, fileName))
+			require.NoError(t, err)
+			defer f.Close()
+
+			rootHash, err := realWAL.ReadLastTrieRootHashFromCheckpoint(f)
+			require.NoError(t, err)
+			require.Equal(t, tries[len(tries)-1].RootHash(), ledger.RootHash(rootHash))
+		})
+	})
+
+	t.Run("tries with different hashers are rejected", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			tries := append(createTriesForCheckpointV6(t), trie.NewEmptyMTrieWithHasher(hasher))
+
+			err := realWAL.StoreCheckpointV6(tries, dir, fileName, &logger)
+			require.Error(t, err)
+		})
+	})
+}
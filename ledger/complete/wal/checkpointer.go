@@ -47,9 +47,24 @@ const VersionV4 uint16 = 0x04
 // See EncodeNode() and EncodeTrie() for more details.
 const VersionV5 uint16 = 0x05
 
+// Version 6 splits the checkpoint into a header file and part files which are
+// written and read concurrently, the node and trie encoding is the same as version 5.
+// See StoreCheckpointV6() for more details.
+const VersionV6 uint16 = 0x06
+
+// Version 7 is an incremental checkpoint, which only contains the nodes which are new
+// since a base checkpoint, the node and trie encoding is the same as version 5.
+// See StoreCheckpointDelta() for more details.
+const VersionV7 uint16 = 0x07
+
+// Version 8 is the same as version 6, except that the checkpoint file records the
+// hasher of the tries, so it's only written for tries not using the default hasher.
+// See StoreCheckpointV6() for more details.
+const VersionV8 uint16 = 0x08
+
 // MaxVersion is the latest checkpoint version we support.
 // Need to update MaxVersion when creating a newer version.
-const MaxVersion = VersionV5
+const MaxVersion = VersionV8
 
 const (
 	encMagicSize     = 2
@@ -210,7 +225,7 @@ func (c *Checkpointer) Checkpoint(to int, targetWriter func() (io.WriteCloser, e
 			return err
 		}, func(rootHash ledger.RootHash) error {
 			return nil
-		}, true)
+		}, true, forest.PayloadStore())
 
 	if err != nil {
 		return fmt.Errorf("cannot replay WAL: %w", err)
@@ -294,6 +309,13 @@ func CreateCheckpointWriterForFile(dir, filename string, logger *zerolog.Logger)
 // TODO: add concurrency if the performance gains are enough to offset complexity.
 func StoreCheckpoint(writer io.Writer, tries ...*trie.MTrie) error {
 
+	// Version 5 checkpoints don't record the hasher, so they can only store default tries.
+	for _, t := range tries {
+		if t.Hasher().Type() != ledger.DefaultTrieHasher.Type() {
+			return fmt.Errorf("cannot store trie hashed with %s in checkpoint version %d, use StoreCheckpointV6", t.Hasher().Type(), VersionV5)
+		}
+	}
+
 	crc32Writer := NewCRC32Writer(writer)
 
 	// Scratch buffer is used as temporary buffer that node can encode into.
@@ -378,7 +400,7 @@ func StoreCheckpoint(writer io.Writer, tries ...*trie.MTrie) error {
 			if root == nil {
 				continue
 			}
-			nodeCounter, err = storeUniqueNodes(root, traversedSubtrieNodes, nodeCounter, scratch, crc32Writer)
+			nodeCounter, err = storeUniqueNodes(root, traversedSubtrieNodes, nodeCounter, scratch, crc32Writer, flattener.EncodeNode)
 			if err != nil {
 				return err
 			}
@@ -396,7 +418,7 @@ func StoreCheckpoint(writer io.Writer, tries ...*trie.MTrie) error {
 		if root == nil {
 			continue
 		}
-		nodeCounter, err = storeUniqueNodes(root, topLevelNodes, nodeCounter, scratch, crc32Writer)
+		nodeCounter, err = storeUniqueNodes(root, topLevelNodes, nodeCounter, scratch, crc32Writer, flattener.EncodeNode)
 		if err != nil {
 			return err
 		}
@@ -442,6 +464,9 @@ func StoreCheckpoint(writer io.Writer, tries ...*trie.MTrie) error {
 	return nil
 }
 
+// nodeEncoder encodes a node with the given child indexes, see flattener.EncodeNode.
+type nodeEncoder func(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) ([]byte, error)
+
 // storeUniqueNodes iterates and serializes unique nodes for trie with given root node.
 // It also saves unique nodes and node counter in visitedNodes map.
 // It returns nodeCounter and error (if any).
@@ -451,6 +476,7 @@ func storeUniqueNodes(
 	nodeCounter uint64,
 	scratch []byte,
 	writer io.Writer,
+	encodeNode nodeEncoder,
 ) (uint64, error) {
 
 	for itr := flattener.NewUniqueNodeIterator(root, visitedNodes); itr.Next(); {
@@ -478,8 +504,11 @@ func storeUniqueNodes(
 			}
 		}
 
-		encNode := flattener.EncodeNode(n, lchildIndex, rchildIndex, scratch)
-		_, err := writer.Write(encNode)
+		encNode, err := encodeNode(n, lchildIndex, rchildIndex, scratch)
+		if err != nil {
+			return 0, fmt.Errorf("cannot encode node: %w", err)
+		}
+		_, err = writer.Write(encNode)
 		if err != nil {
 			return 0, fmt.Errorf("cannot serialize node: %w", err)
 		}
@@ -517,13 +546,23 @@ func getNodesAtLevel(root *node.Node, level uint) []*node.Node {
 }
 
 func (c *Checkpointer) LoadCheckpoint(checkpoint int) ([]*trie.MTrie, error) {
+	return c.LoadCheckpointWithPayloadStore(checkpoint, nil)
+}
+
+// LoadCheckpointWithPayloadStore loads the given checkpoint, see LoadCheckpointWithPayloadStore.
+func (c *Checkpointer) LoadCheckpointWithPayloadStore(checkpoint int, store node.PayloadStore) ([]*trie.MTrie, error) {
 	filepath := path.Join(c.dir, NumberToFilename(checkpoint))
-	return LoadCheckpoint(filepath, &c.wal.log)
+	return LoadCheckpointWithPayloadStore(filepath, &c.wal.log, store)
 }
 
 func (c *Checkpointer) LoadRootCheckpoint() ([]*trie.MTrie, error) {
+	return c.LoadRootCheckpointWithPayloadStore(nil)
+}
+
+// LoadRootCheckpointWithPayloadStore loads the root checkpoint, see LoadCheckpointWithPayloadStore.
+func (c *Checkpointer) LoadRootCheckpointWithPayloadStore(store node.PayloadStore) ([]*trie.MTrie, error) {
 	filepath := path.Join(c.dir, bootstrap.FilenameWALRootCheckpoint)
-	return LoadCheckpoint(filepath, &c.wal.log)
+	return LoadCheckpointWithPayloadStore(filepath, &c.wal.log, store)
 }
 
 func (c *Checkpointer) HasRootCheckpoint() (bool, error) {
@@ -536,11 +575,47 @@ func (c *Checkpointer) HasRootCheckpoint() (bool, error) {
 	}
 }
 
+// RemoveCheckpoint removes the given checkpoint file, and its part files if the checkpoint
+// has been stored in multiple files.
 func (c *Checkpointer) RemoveCheckpoint(checkpoint int) error {
-	return os.Remove(path.Join(c.dir, NumberToFilename(checkpoint)))
+	name := NumberToFilename(checkpoint)
+
+	// remove the checkpoint file first, so the checkpoint is never listed without its parts.
+	err := os.Remove(path.Join(c.dir, name))
+	if err != nil {
+		return err
+	}
+
+	return removeCheckpointParts(c.dir, name)
+}
+
+// CheckpointBase returns the number of the checkpoint the given incremental checkpoint
+// is based on, or -1 if the given checkpoint isn't an incremental checkpoint.
+func (c *Checkpointer) CheckpointBase(checkpoint int) (int, error) {
+	baseFile, err := CheckpointBase(path.Join(c.dir, NumberToFilename(checkpoint)))
+	if err != nil {
+		return -1, err
+	}
+	if baseFile == "" {
+		return -1, nil
+	}
+	return checkpointNumberFromFilename(baseFile)
+}
+
+// Dir returns the directory containing the checkpoint files.
+func (c *Checkpointer) Dir() string {
+	return c.dir
 }
 
 func LoadCheckpoint(filepath string, logger *zerolog.Logger) ([]*trie.MTrie, error) {
+	return LoadCheckpointWithPayloadStore(filepath, logger, nil)
+}
+
+// LoadCheckpointWithPayloadStore loads the given checkpoint file, and moves the payloads
+// of the tries into the given store while reading them, without decoding them.
+// Payloads of checkpoints earlier than version 5 are read into memory, as they are
+// encoded differently. If store is nil, all payloads are kept in memory.
+func LoadCheckpointWithPayloadStore(filepath string, logger *zerolog.Logger, store node.PayloadStore) ([]*trie.MTrie, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open checkpoint file %s: %w", filepath, err)
@@ -555,10 +630,10 @@ func LoadCheckpoint(filepath string, logger *zerolog.Logger) ([]*trie.MTrie, err
 		_ = file.Close()
 	}()
 
-	return readCheckpoint(file)
+	return readCheckpoint(file, logger, store)
 }
 
-func readCheckpoint(f *os.File) ([]*trie.MTrie, error) {
+func readCheckpoint(f *os.File, logger *zerolog.Logger, store node.PayloadStore) ([]*trie.MTrie, error) {
 
 	// Read header: magic (2 bytes) + version (2 bytes)
 	header := make([]byte, headerSize)
@@ -587,12 +662,38 @@ func readCheckpoint(f *os.File) ([]*trie.MTrie, error) {
 	case VersionV4:
 		return readCheckpointV4(f)
 	case VersionV5:
-		return readCheckpointV5(f)
+		return readCheckpointV5(f, store)
+	case VersionV6, VersionV8:
+		return readCheckpointV6(f, logger, store)
+	case VersionV7:
+		return readCheckpointDelta(f, logger, store)
 	default:
 		return nil, fmt.Errorf("unsupported file version %x", version)
 	}
 }
 
+// newPayloadBatch returns a batch moving the payloads of the nodes read from a checkpoint
+// into the given store, or nil to keep payloads in memory if store is nil.
+func newPayloadBatch(store node.PayloadStore) *node.PayloadBatch {
+	if store == nil {
+		return nil
+	}
+	return node.NewPayloadBatch(store)
+}
+
+// flushPayloadBatch stores the remaining payloads of the given batch, if it isn't nil.
+// Nodes read with the batch must not be used before.
+func flushPayloadBatch(payloads *node.PayloadBatch) error {
+	if payloads == nil {
+		return nil
+	}
+	err := payloads.Flush()
+	if err != nil {
+		return fmt.Errorf("cannot store payloads: %w", err)
+	}
+	return nil
+}
+
 type nodeWithRegMetrics struct {
 	n        *node.Node
 	regCount uint64
@@ -795,8 +896,9 @@ func readCheckpointV4(f *os.File) ([]*trie.MTrie, error) {
 }
 
 // readCheckpointV5 decodes checkpoint file (version 5) and returns a list of tries.
+// Payloads are moved into the given store, if it isn't nil.
 // Checkpoint file header (magic and version) are verified by the caller.
-func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
+func readCheckpointV5(f *os.File, store node.PayloadStore) ([]*trie.MTrie, error) {
 
 	// Scratch buffer is used as temporary buffer that reader can read into.
 	// Raw data in scratch buffer should be copied or converted into desired
@@ -850,19 +952,25 @@ func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
 	nodes := make([]*node.Node, nodesCount+1) //+1 for 0 index meaning nil
 	tries := make([]*trie.MTrie, triesCount)
 
+	payloads := newPayloadBatch(store)
 	for i := uint64(1); i <= nodesCount; i++ {
-		n, err := flattener.ReadNode(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+		n, err := flattener.ReadNodeWithPayloadBatch(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
 			if nodeIndex >= uint64(i) {
 				return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
 			}
 			return nodes[nodeIndex], nil
-		})
+		}, payloads)
 		if err != nil {
 			return nil, fmt.Errorf("cannot read node %d: %w", i, err)
 		}
 		nodes[i] = n
 	}
 
+	err = flushPayloadBatch(payloads)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := uint16(0); i < triesCount; i++ {
 		trie, err := flattener.ReadTrie(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
 			if nodeIndex >= uint64(len(nodes)) {
@@ -904,6 +1012,7 @@ func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
 // ReadLastTrieRootHashFromCheckpoint returns last trie's root hash from checkpoint file f.
 // All returned errors indicate that the given checkpoint file is eiter corrupted or
 // incompatible.  As the function is side-effect free, all failures are simple a no-op.
+// For version 6 checkpoints, the root hash is read from the top level part file next to f.
 func ReadLastTrieRootHashFromCheckpoint(f *os.File) (hash.Hash, error) {
 
 	// read checkpoint version
@@ -924,6 +1033,17 @@ func ReadLastTrieRootHashFromCheckpoint(f *os.File) (hash.Hash, error) {
 		return hash.DummyHash, fmt.Errorf("unsupported version %d in checkpoint", version)
 	}
 
+	if version == VersionV6 || version == VersionV8 {
+		// the tries are stored in the top level part, which ends like version 4 and 5 checkpoints.
+		topLevelPart, err := os.Open(topLevelPartFileName(f.Name()))
+		if err != nil {
+			return hash.DummyHash, fmt.Errorf("cannot open top level part of checkpoint: %w", err)
+		}
+		defer topLevelPart.Close()
+
+		f = topLevelPart
+	}
+
 	if version <= 3 {
 		_, err = f.Seek(-(hash.HashLen + crc32SumSize), 2 /* relative from end */)
 		if err != nil {
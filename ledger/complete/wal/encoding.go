@@ -3,6 +3,8 @@ package wal
 import (
 	"fmt"
 
+	"github.com/golang/snappy"
+
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/utils"
 )
@@ -12,6 +14,9 @@ type WALOperation uint8
 const WALUpdate WALOperation = 1
 const WALDelete WALOperation = 2
 
+// WALUpdateCompressed is an update compressed with snappy, it's decoded as WALUpdate.
+const WALUpdateCompressed WALOperation = 3
+
 /*
 The LedgerWAL update record uses two operations so far - an update which must include all keys and values, and deletion
 which only needs a root tree state commitment.
@@ -29,6 +34,10 @@ If OP = WALUpdate, record has:
 and for every pair after
 bytes for key | 4 bytes Big Endian uint32 - length of value | value bytes
 
+If OP = WALUpdateCompressed, record has:
+
+1 byte Operation Type | snappy block encoding of the WALUpdate record without Operation Type
+
 The code here is deliberately simple, for performance.
 
 */
@@ -44,6 +53,17 @@ func EncodeUpdate(update *ledger.TrieUpdate) []byte {
 	return buf
 }
 
+// EncodeUpdateCompressed encodes the update like EncodeUpdate, but compresses the encoded
+// update with snappy, which reduces the size of updates with many similar payloads.
+func EncodeUpdateCompressed(update *ledger.TrieUpdate) []byte {
+	encUpdate := ledger.EncodeTrieUpdate(update)
+	buf := make([]byte, 1+snappy.MaxEncodedLen(len(encUpdate)))
+	// set WAL type
+	buf[0] = byte(WALUpdateCompressed)
+	compressed := snappy.Encode(buf[1:], encUpdate)
+	return buf[:1+len(compressed)]
+}
+
 func EncodeDelete(rootHash ledger.RootHash) []byte {
 	buf := make([]byte, 0, 1+2+len(rootHash))
 	buf = append(buf, byte(WALDelete))
@@ -51,6 +71,8 @@ func EncodeDelete(rootHash ledger.RootHash) []byte {
 	return buf
 }
 
+// Decode decodes a WAL record. Compressed updates are decompressed and returned as WALUpdate,
+// so records are replayed the same way whether or not they have been compressed.
 func Decode(data []byte) (operation WALOperation, rootHash ledger.RootHash, update *ledger.TrieUpdate, err error) {
 	if len(data) < 4 { // 1 byte op + 2 size + actual data = 4 minimum
 		err = fmt.Errorf("data corrupted, too short to represent operation - hexencoded data: %x", data)
@@ -62,6 +84,16 @@ func Decode(data []byte) (operation WALOperation, rootHash ledger.RootHash, upda
 	case WALUpdate:
 		update, err = ledger.DecodeTrieUpdate(data[1:])
 		return
+	case WALUpdateCompressed:
+		operation = WALUpdate
+		var encUpdate []byte
+		encUpdate, err = snappy.Decode(nil, data[1:])
+		if err != nil {
+			err = fmt.Errorf("cannot decompress update: %w", err)
+			return
+		}
+		update, err = ledger.DecodeTrieUpdate(encUpdate)
+		return
 	case WALDelete:
 		var rootHashBytes []byte
 		rootHashBytes, _, err = utils.ReadShortData(data[1:])
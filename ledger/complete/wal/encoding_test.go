@@ -73,3 +73,32 @@ func TestDelete(t *testing.T) {
 	})
 
 }
+
+func TestUpdateCompressed(t *testing.T) {
+
+	var rootHash ledger.RootHash
+	copy(rootHash[:], []byte{2, 1, 3, 7})
+	paths := []ledger.Path{testutils.PathByUint16(1), testutils.PathByUint16(772)}
+	payloads := []*ledger.Payload{
+		testutils.LightPayload(1, 2),
+		ledger.NewPayload(ledger.NewKey([]ledger.KeyPart{testutils.KeyPartFixture(0, "key")}), make([]byte, 1024)),
+	}
+	update := &ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: payloads}
+
+	data := realWAL.EncodeUpdateCompressed(update)
+	require.Equal(t, byte(realWAL.WALUpdateCompressed), data[0])
+	require.Less(t, len(data), len(realWAL.EncodeUpdate(update)))
+
+	t.Run("decode", func(t *testing.T) {
+		operation, _, up, err := realWAL.Decode(data)
+		require.NoError(t, err)
+		assert.Equal(t, realWAL.WALUpdate, operation)
+		assert.Equal(t, update, up)
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		corrupted := append([]byte{}, data[:len(data)/2]...)
+		_, _, _, err := realWAL.Decode(corrupted)
+		require.Error(t, err)
+	})
+}
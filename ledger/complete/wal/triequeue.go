@@ -43,13 +43,18 @@ func NewTrieQueueWithValues(capacity uint, tries []*trie.MTrie) *TrieQueue {
 	return q
 }
 
-// Push pushes trie to queue.  If queue is full, it overwrites the oldest element.
-func (q *TrieQueue) Push(t *trie.MTrie) {
+// Push pushes trie to queue.  If queue is full, it overwrites the oldest element,
+// which is returned.
+func (q *TrieQueue) Push(t *trie.MTrie) (evicted *trie.MTrie) {
+	if q.isFull() {
+		evicted = q.ts[q.tail]
+	}
 	q.ts[q.tail] = t
 	q.tail = (q.tail + 1) % q.capacity
 	if !q.isFull() {
 		q.count++
 	}
+	return evicted
 }
 
 // Tries returns elements in queue, starting from the oldest element
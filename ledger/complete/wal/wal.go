@@ -10,6 +10,7 @@ import (
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/module"
 )
@@ -23,22 +24,39 @@ type DiskWAL struct {
 	pathByteSize   int
 	log            zerolog.Logger
 	dir            string
+	compression    bool
+}
+
+// DiskWALOption is an option of NewDiskWAL.
+type DiskWALOption func(*DiskWAL)
+
+// WithCompression makes DiskWAL compress the trie updates it records (see EncodeUpdateCompressed).
+// Compressed and uncompressed records can be mixed in the same WAL, so compression can be
+// enabled or disabled on restart.
+func WithCompression() DiskWALOption {
+	return func(w *DiskWAL) {
+		w.compression = true
+	}
 }
 
 // TODO use real logger and metrics, but that would require passing them to Trie storage
-func NewDiskWAL(logger zerolog.Logger, reg prometheus.Registerer, metrics module.WALMetrics, dir string, forestCapacity int, pathByteSize int, segmentSize int) (*DiskWAL, error) {
+func NewDiskWAL(logger zerolog.Logger, reg prometheus.Registerer, metrics module.WALMetrics, dir string, forestCapacity int, pathByteSize int, segmentSize int, opts ...DiskWALOption) (*DiskWAL, error) {
 	w, err := prometheusWAL.NewSize(logger, reg, dir, segmentSize, false)
 	if err != nil {
 		return nil, err
 	}
-	return &DiskWAL{
+	diskWAL := &DiskWAL{
 		wal:            w,
 		paused:         false,
 		forestCapacity: forestCapacity,
 		pathByteSize:   pathByteSize,
 		log:            logger,
 		dir:            dir,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(diskWAL)
+	}
+	return diskWAL, nil
 }
 
 func (w *DiskWAL) PauseRecord() {
@@ -57,7 +75,12 @@ func (w *DiskWAL) RecordUpdate(update *ledger.TrieUpdate) (segmentNum int, skipp
 		return 0, true, nil
 	}
 
-	bytes := EncodeUpdate(update)
+	var bytes []byte
+	if w.compression {
+		bytes = EncodeUpdateCompressed(update)
+	} else {
+		bytes = EncodeUpdate(update)
+	}
 
 	locations, err := w.wal.Log(bytes)
 
@@ -86,8 +109,14 @@ func (w *DiskWAL) RecordDelete(rootHash ledger.RootHash) error {
 	return nil
 }
 
+// ReplayOnForest replays the checkpoints and segments on the given forest. If the forest
+// keeps payloads in a payload store, checkpoints are loaded directly into the store.
 func (w *DiskWAL) ReplayOnForest(forest *mtrie.Forest) error {
-	return w.Replay(
+	from, to, err := w.Segments()
+	if err != nil {
+		return err
+	}
+	return w.replay(from, to,
 		func(tries []*trie.MTrie) error {
 			err := forest.AddTries(tries)
 			if err != nil {
@@ -102,6 +131,8 @@ func (w *DiskWAL) ReplayOnForest(forest *mtrie.Forest) error {
 		func(rootHash ledger.RootHash) error {
 			return nil
 		},
+		true,
+		forest.PayloadStore(),
 	)
 }
 
@@ -118,7 +149,7 @@ func (w *DiskWAL) Replay(
 	if err != nil {
 		return err
 	}
-	return w.replay(from, to, checkpointFn, updateFn, deleteFn, true)
+	return w.replay(from, to, checkpointFn, updateFn, deleteFn, true, nil)
 }
 
 func (w *DiskWAL) ReplayLogsOnly(
@@ -130,7 +161,18 @@ func (w *DiskWAL) ReplayLogsOnly(
 	if err != nil {
 		return err
 	}
-	return w.replay(from, to, checkpointFn, updateFn, deleteFn, false)
+	return w.replay(from, to, checkpointFn, updateFn, deleteFn, false, nil)
+}
+
+// ReplayRange replays the segments from `from` to `to` (inclusive) without loading any
+// checkpoint, except the root checkpoint which is loaded before segment 0 if it exists.
+func (w *DiskWAL) ReplayRange(
+	from, to int,
+	checkpointFn func(tries []*trie.MTrie) error,
+	updateFn func(update *ledger.TrieUpdate) error,
+	deleteFn func(rootHash ledger.RootHash) error,
+) error {
+	return w.replay(from, to, checkpointFn, updateFn, deleteFn, false, nil)
 }
 
 func (w *DiskWAL) replay(
@@ -139,6 +181,7 @@ func (w *DiskWAL) replay(
 	updateFn func(update *ledger.TrieUpdate) error,
 	deleteFn func(rootHash ledger.RootHash) error,
 	useCheckpoints bool,
+	store node.PayloadStore,
 ) error {
 
 	w.log.Debug().Msgf("replaying WAL from %d to %d", from, to)
@@ -176,7 +219,7 @@ func (w *DiskWAL) replay(
 
 			w.log.Info().Int("checkpoint", latestCheckpoint).Msg("loading checkpoint")
 
-			forestSequencing, err := checkpointer.LoadCheckpoint(latestCheckpoint)
+			forestSequencing, err := checkpointer.LoadCheckpointWithPayloadStore(latestCheckpoint, store)
 			if err != nil {
 				w.log.Warn().Int("checkpoint", latestCheckpoint).Err(err).
 					Msg("checkpoint loading failed")
@@ -210,7 +253,7 @@ func (w *DiskWAL) replay(
 			return fmt.Errorf("cannot check root checkpoint existence: %w", err)
 		}
 		if hasRootCheckpoint {
-			flattenedForest, err := checkpointer.LoadRootCheckpoint()
+			flattenedForest, err := checkpointer.LoadRootCheckpointWithPayloadStore(store)
 			if err != nil {
 				return fmt.Errorf("cannot load root checkpoint: %w", err)
 			}
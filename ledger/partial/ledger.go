@@ -22,6 +22,12 @@ type Ledger struct {
 
 // NewLedger creates a new in-memory trie-backed ledger storage with persistence.
 func NewLedger(proof ledger.Proof, s ledger.State, pathFinderVer uint8) (*Ledger, error) {
+	return NewLedgerWithHasher(proof, s, pathFinderVer, ledger.DefaultTrieHasher)
+}
+
+// NewLedgerWithHasher creates a partial ledger like NewLedger, for a complete ledger whose
+// trie nodes are hashed with the given trie hasher.
+func NewLedgerWithHasher(proof ledger.Proof, s ledger.State, pathFinderVer uint8, hasher *ledger.TrieHasher) (*Ledger, error) {
 
 	// Decode proof encodings
 	if len(proof) < 1 {
@@ -33,7 +39,7 @@ func NewLedger(proof ledger.Proof, s ledger.State, pathFinderVer uint8) (*Ledger
 	}
 
 	// decode proof
-	psmt, err := ptrie.NewPSMT(ledger.RootHash(s), batchProof)
+	psmt, err := ptrie.NewPSMTWithHasher(ledger.RootHash(s), batchProof, hasher)
 
 	if err != nil {
 		// TODO provide more details based on the error type
@@ -43,6 +49,21 @@ func NewLedger(proof ledger.Proof, s ledger.State, pathFinderVer uint8) (*Ledger
 	return &Ledger{ptrie: psmt, proof: proof, state: s, pathFinderVersion: pathFinderVer}, nil
 }
 
+// MergeProof extends the partial ledger with the registers of the given proof, which must
+// be a proof of the current state of the partial ledger.
+func (l *Ledger) MergeProof(proof ledger.Proof) error {
+	batchProof, err := ledger.DecodeTrieBatchProof(proof)
+	if err != nil {
+		return fmt.Errorf("decoding proof failed: %w", err)
+	}
+
+	err = l.ptrie.MergeProofs(batchProof)
+	if err != nil {
+		return fmt.Errorf("merging proof failed: %w", err)
+	}
+	return nil
+}
+
 // Ready implements interface module.ReadyDoneAware
 func (l *Ledger) Ready() <-chan struct{} {
 	ready := make(chan struct{})
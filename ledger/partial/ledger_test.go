@@ -97,18 +97,49 @@ func TestFunctionalityWithCompleteTrie(t *testing.T) {
 	assert.Equal(t, len(e.Keys), 1)
 	require.True(t, e.Keys[0].Equals(&keys[2]))
 
-	// test missing keys (set)
-	update, err = ledger.NewUpdate(state, keys[1:3], values[1:3])
+	// test missing keys (set): keys[1] exists, but isn't part of the proof
+	query, err = ledger.NewQuery(newState, keys[0:1])
+	require.NoError(t, err)
+	proof, err = l.Prove(query)
+	require.NoError(t, err)
+
+	pled1, err := partial.NewLedger(proof, newState, partial.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	update, err = ledger.NewUpdate(state, keys[1:2], values[1:2])
 	require.NoError(t, err)
 
-	_, _, err = pled.Set(update)
+	_, _, err = pled1.Set(update)
 	require.Error(t, err)
 
 	e, ok = err.(*ledger.ErrMissingKeys)
 	require.True(t, ok)
 	assert.Equal(t, len(e.Keys), 1)
-	require.True(t, e.Keys[0].Equals(&keys[2]))
+	require.True(t, e.Keys[0].Equals(&keys[1]))
+
+	// test setting keys proven absent: keys[2] doesn't exist, and the proof covers all registers
+	update, err = ledger.NewUpdate(newState, keys[1:3], values[1:3])
+	require.NoError(t, err)
+
+	expectedState, _, err := l.Set(update)
+	require.NoError(t, err)
 
+	partialState, _, err := pled.Set(update)
+	require.NoError(t, err)
+	require.Equal(t, expectedState, partialState)
+
+	// test merging proofs: the proof of keys[0:2] extends the partial ledger of keys[0]
+	query, err = ledger.NewQuery(newState, keys[0:2])
+	require.NoError(t, err)
+	proof, err = l.Prove(query)
+	require.NoError(t, err)
+
+	err = pled1.MergeProof(proof)
+	require.NoError(t, err)
+
+	retValues, err = pled1.Get(query)
+	require.NoError(t, err)
+	require.Equal(t, values[0:2], retValues)
 }
 
 func TestProofsForEmptyRegisters(t *testing.T) {
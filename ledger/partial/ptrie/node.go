@@ -21,6 +21,7 @@ type node struct {
 	lChild    *node           // Left Child
 	rChild    *node           // Right Child
 	height    int             // Height where the node is at
+	path      ledger.Path     // path of the register, for leaves holding a payload
 	payload   *ledger.Payload // payload
 	hashValue hash.Hash       // hash value
 }
@@ -38,9 +39,31 @@ func newNode(v hash.Hash, height int) *node {
 // Hash returns the node's pre-computed hash value
 func (n *node) Hash() hash.Hash { return n.hashValue }
 
+// isLeaf returns true if the node has no children. A leaf either holds the payload
+// of a register, or only the hash of a subtrie (default hash for empty subtries).
+func (n *node) isLeaf() bool { return n.lChild == nil && n.rChild == nil }
+
+// setPayload turns the node into a leaf holding the given register, and computes its hash.
+func (n *node) setPayload(path ledger.Path, payload *ledger.Payload, hasher *ledger.TrieHasher) {
+	n.path = path
+	n.payload = payload
+	n.hashValue = hasher.ComputeCompactValue(hash.Hash(path), payload.Value(), n.height)
+}
+
+// deepCopy returns a copy of the sub-trie, payloads are shared.
+func (n *node) deepCopy() *node {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	c.lChild = n.lChild.deepCopy()
+	c.rChild = n.rChild.deepCopy()
+	return &c
+}
+
 // forceComputeHash computes (and updates) the hashes of _all interim nodes_
 // of this sub-trie. Caution: this is an expensive operation!
-func (n *node) forceComputeHash() hash.Hash {
+func (n *node) forceComputeHash(hasher *ledger.TrieHasher) hash.Hash {
 	// leaf node
 	if n.lChild == nil && n.rChild == nil {
 		return n.hashValue
@@ -48,16 +71,16 @@ func (n *node) forceComputeHash() hash.Hash {
 	// otherwise compute
 	var h1, h2 hash.Hash
 	if n.lChild != nil {
-		h1 = n.lChild.forceComputeHash()
+		h1 = n.lChild.forceComputeHash(hasher)
 	} else {
-		h1 = ledger.GetDefaultHashForHeight(n.height - 1)
+		h1 = hasher.GetDefaultHashForHeight(n.height - 1)
 	}
 
 	if n.rChild != nil {
-		h2 = n.rChild.forceComputeHash()
+		h2 = n.rChild.forceComputeHash(hasher)
 	} else {
-		h2 = ledger.GetDefaultHashForHeight(n.height - 1)
+		h2 = hasher.GetDefaultHashForHeight(n.height - 1)
 	}
-	n.hashValue = hash.HashInterNode(h1, h2)
+	n.hashValue = hasher.HashInterNode(h1, h2)
 	return n.hashValue
 }
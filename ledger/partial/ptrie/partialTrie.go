@@ -20,6 +20,7 @@ import (
 type PSMT struct {
 	root       *node // Root
 	pathLookUp map[ledger.Path]*node
+	hasher     *ledger.TrieHasher
 }
 
 // RootHash returns the rootNode hash value of the SMT
@@ -58,23 +59,37 @@ func (p *PSMT) Get(paths []ledger.Path) ([]*ledger.Payload, error) {
 
 // Update updates registers and returns rootValue after updates
 // in case of error, it returns a list of paths for which update failed
+//
+// Registers which aren't part of the proofs are inserted if the partial trie proves
+// their absence, i.e. their path ends in an empty subtrie or at the leaf of another
+// register. Otherwise, their paths are reported as missing.
 func (p *PSMT) Update(paths []ledger.Path, payloads []*ledger.Payload) (ledger.RootHash, error) {
 	var failedPaths []ledger.Path
 	for i, path := range paths {
 		payload := payloads[i]
 		// lookup the path and update the value
 		node, found := p.pathLookUp[path]
-		if !found {
+		if found {
+			node.setPayload(path, payload, p.hasher)
+			continue
+		}
+
+		// insert the register if it's proven absent
+		leaf, moved, ok := attach(p.root, path, payload, nil, p.hasher)
+		if !ok {
 			failedPaths = append(failedPaths, path)
 			continue
 		}
-		node.hashValue = ledger.ComputeCompactValue(hash.Hash(path), payload.Value(), node.height)
+		p.pathLookUp[path] = leaf
+		if moved != nil {
+			p.pathLookUp[moved.path] = moved
+		}
 	}
 	if len(failedPaths) > 0 {
 		return ledger.RootHash(hash.DummyHash), &ErrMissingPath{Paths: failedPaths}
 	}
 	// after updating all the nodes, compute the value recursively only once
-	return ledger.RootHash(p.root.forceComputeHash()), nil
+	return ledger.RootHash(p.root.forceComputeHash(p.hasher)), nil
 }
 
 // NewPSMT builds a Partial Sparse Merkle Tree (PSMT) given a chunkdatapack registertouches
@@ -82,9 +97,19 @@ func (p *PSMT) Update(paths []ledger.Path, payloads []*ledger.Payload) (ledger.R
 func NewPSMT(
 	rootValue ledger.RootHash,
 	batchProof *ledger.TrieBatchProof,
+) (*PSMT, error) {
+	return NewPSMTWithHasher(rootValue, batchProof, ledger.DefaultTrieHasher)
+}
+
+// NewPSMTWithHasher builds a Partial Sparse Merkle Tree (PSMT) like NewPSMT, for a trie whose
+// nodes are hashed with the given trie hasher.
+func NewPSMTWithHasher(
+	rootValue ledger.RootHash,
+	batchProof *ledger.TrieBatchProof,
+	hasher *ledger.TrieHasher,
 ) (*PSMT, error) {
 	height := ledger.NodeMaxHeight
-	psmt := PSMT{newNode(ledger.GetDefaultHashForHeight(height), height), make(map[ledger.Path]*node)}
+	psmt := PSMT{newNode(hasher.GetDefaultHashForHeight(height), height), make(map[ledger.Path]*node), hasher}
 
 	// iterating over proofs for building the tree
 	for i, pr := range batchProof.Proofs {
@@ -100,7 +125,7 @@ func NewPSMT(
 		for j := 0; j < int(pr.Steps); j++ {
 			// if a flag (bit j in flags) is false, the value is a default value
 			// otherwise the value is stored in the proofs
-			defaultHash := ledger.GetDefaultHashForHeight(currentNode.height - 1)
+			defaultHash := hasher.GetDefaultHashForHeight(currentNode.height - 1)
 			v := defaultHash
 			flag := bitutils.ReadBit(pr.Flags, j)
 			if flag == 1 {
@@ -135,18 +160,189 @@ func NewPSMT(
 			}
 		}
 
+		currentNode.path = path
 		currentNode.payload = payload
 		// update node's hash value only for inclusion proofs (for others we assume default value)
 		if pr.Inclusion {
-			currentNode.hashValue = ledger.ComputeCompactValue(hash.Hash(path), payload.Value(), currentNode.height)
+			currentNode.hashValue = hasher.ComputeCompactValue(hash.Hash(path), payload.Value(), currentNode.height)
 		}
 		// keep a reference to this node by path (for update purpose)
 		psmt.pathLookUp[path] = currentNode
 	}
 
 	// check if the rootHash matches the root node's hash value of the partial trie
-	if ledger.RootHash(psmt.root.forceComputeHash()) != rootValue {
+	if ledger.RootHash(psmt.root.forceComputeHash(hasher)) != rootValue {
 		return nil, fmt.Errorf("rootNode hash doesn't match the proofs expected [%x], got [%x]", psmt.root.Hash(), rootValue)
 	}
 	return &psmt, nil
 }
+
+// MergeProofs extends the partial trie with the registers of the given batch proof.
+// The batch proof must prove the registers at the current root hash of the partial trie,
+// i.e. it's generated from the same state if the partial trie hasn't been updated.
+// The partial trie is unchanged if an error is returned.
+func (p *PSMT) MergeProofs(batchProof *ledger.TrieBatchProof) error {
+	rootHash := p.RootHash()
+
+	other, err := NewPSMTWithHasher(rootHash, batchProof, p.hasher)
+	if err != nil {
+		return fmt.Errorf("cannot build partial trie from proofs: %w", err)
+	}
+
+	// nodes are modified when merging, so the partial trie is copied to be
+	// left unchanged if the proofs can't be merged.
+	root, err := mergeNodes(p.root.deepCopy(), other.root, p.hasher)
+	if err != nil {
+		return fmt.Errorf("cannot merge proofs: %w", err)
+	}
+
+	if ledger.RootHash(root.forceComputeHash(p.hasher)) != rootHash {
+		return fmt.Errorf("merged partial trie root hash [%x] doesn't match the proofs [%x]", root.Hash(), rootHash)
+	}
+
+	pathLookUp := make(map[ledger.Path]*node, len(p.pathLookUp)+len(other.pathLookUp))
+	collectLeaves(root, pathLookUp)
+
+	p.root = root
+	p.pathLookUp = pathLookUp
+	return nil
+}
+
+// mergeNodes merges the sub-tries at the same position of two partial tries of the same state,
+// keeping the registers of both, and returns the merged sub-trie which reuses nodes of both.
+func mergeNodes(a *node, b *node, hasher *ledger.TrieHasher) (*node, error) {
+	switch {
+	case !a.isLeaf() && !b.isLeaf():
+		lChild, err := mergeNodes(a.lChild, b.lChild, hasher)
+		if err != nil {
+			return nil, err
+		}
+		rChild, err := mergeNodes(a.rChild, b.rChild, hasher)
+		if err != nil {
+			return nil, err
+		}
+		a.lChild, a.rChild = lChild, rChild
+		return a, nil
+
+	case !a.isLeaf():
+		return mergeLeaf(a, b, hasher)
+
+	case !b.isLeaf():
+		return mergeLeaf(b, a, hasher)
+	}
+
+	// both nodes are leaves
+	if a.hashValue != b.hashValue {
+		return nil, fmt.Errorf("hash of node at height %d doesn't match: [%x] != [%x]", a.height, a.hashValue, b.hashValue)
+	}
+	if b.payload == nil || (a.payload != nil && a.path == b.path) {
+		return a, nil
+	}
+	if a.payload == nil {
+		return b, nil
+	}
+	// leaves of two unallocated registers, which are split
+	return mergeLeaf(a, b, hasher)
+}
+
+// mergeLeaf merges the leaf into the sub-trie at the same position: the register of the leaf
+// (if any) is attached to the sub-trie, otherwise the hashes of both must match.
+func mergeLeaf(subtrie *node, leaf *node, hasher *ledger.TrieHasher) (*node, error) {
+	if leaf.payload == nil {
+		if leaf.hashValue != subtrie.hashValue {
+			return nil, fmt.Errorf("hash of node at height %d doesn't match: [%x] != [%x]", leaf.height, leaf.hashValue, subtrie.hashValue)
+		}
+		return subtrie, nil
+	}
+
+	_, _, ok := attach(subtrie, leaf.path, leaf.payload, leaf.payload.Value(), hasher)
+	if !ok {
+		return nil, fmt.Errorf("register %v doesn't match the sub-trie at height %d", leaf.path, subtrie.height)
+	}
+	return subtrie, nil
+}
+
+// collectLeaves adds the leaves holding a register payload to the path lookup.
+func collectLeaves(n *node, pathLookUp map[ledger.Path]*node) {
+	if n.isLeaf() {
+		if n.payload != nil {
+			pathLookUp[n.path] = n
+		}
+		return
+	}
+	collectLeaves(n.lChild, pathLookUp)
+	collectLeaves(n.rChild, pathLookUp)
+}
+
+// attach walks down the sub-trie along the path, and turns the leaf where the register is
+// located into a leaf holding the given payload. The hashes of interim nodes aren't updated.
+//
+// The register must currently have the given value (nil for registers which don't exist):
+//   - if the walk ends at a leaf without payload, its hash must be the hash of the register with
+//     the given value.
+//   - if the walk ends at the leaf of another register, the given value must be empty. The leaf is
+//     split until both paths diverge, and the new leaf of the other register is returned as moved.
+//
+// It returns false if the sub-trie doesn't prove the current value of the register.
+func attach(n *node, path ledger.Path, payload *ledger.Payload, value ledger.Value, hasher *ledger.TrieHasher) (leaf *node, moved *node, ok bool) {
+	// interim nodes of the partial trie have both children
+	for !n.isLeaf() {
+		if bitutils.ReadBit(path[:], ledger.NodeMaxHeight-n.height) == 1 {
+			n = n.rChild
+		} else {
+			n = n.lChild
+		}
+	}
+
+	if n.payload == nil {
+		if n.hashValue != hasher.ComputeCompactValue(hash.Hash(path), value, n.height) {
+			return nil, nil, false
+		}
+		n.setPayload(path, payload, hasher)
+		return n, nil, true
+	}
+
+	if n.path == path {
+		n.setPayload(path, payload, hasher)
+		return n, nil, true
+	}
+
+	// the register doesn't exist, as the leaf of another register is at its position
+	if len(value) > 0 {
+		return nil, nil, false
+	}
+
+	// split the leaf of the other register until both paths diverge
+	otherPath, otherPayload := n.path, n.payload
+	n.path, n.payload = ledger.DummyPath, nil
+	for {
+		bit := bitutils.ReadBit(path[:], ledger.NodeMaxHeight-n.height)
+		otherBit := bitutils.ReadBit(otherPath[:], ledger.NodeMaxHeight-n.height)
+		height := n.height - 1
+		defaultHash := hasher.GetDefaultHashForHeight(height)
+
+		if bit == otherBit {
+			// Caution: the hash of the interim node is set when computing the root hash
+			child := newNode(defaultHash, height)
+			empty := newNode(defaultHash, height)
+			if bit == 1 {
+				n.lChild, n.rChild = empty, child
+			} else {
+				n.lChild, n.rChild = child, empty
+			}
+			n = child
+			continue
+		}
+
+		leaf = newNode(defaultHash, height)
+		leaf.setPayload(path, payload, hasher)
+		moved = newNode(defaultHash, height)
+		moved.setPayload(otherPath, otherPayload, hasher)
+		if bit == 1 {
+			n.lChild, n.rChild = moved, leaf
+		} else {
+			n.lChild, n.rChild = leaf, moved
+		}
+		return leaf, moved, true
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/ledger/common/testutils"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
 	"github.com/onflow/flow-go/module/metrics"
@@ -198,10 +199,17 @@ func TestPartialTrieLeafUpdates(t *testing.T) {
 		path3 := testutils.PathByUint16(2)
 		payload3 := testutils.LightPayload('E', 'e')
 
+		// path4 isn't part of the proofs, so the right subtrie of the root isn't proven empty
+		path4 := testutils.PathByUint16(1 << 15)
+		payload4 := testutils.LightPayload('F', 'f')
+
+		path5 := testutils.PathByUint16(1<<15 + 1)
+		payload5 := testutils.LightPayload('G', 'g')
+
 		paths := []ledger.Path{path1, path2}
 		payloads := []*ledger.Payload{payload1, payload2}
 
-		u := &ledger.TrieUpdate{RootHash: f.GetEmptyRootHash(), Paths: paths, Payloads: payloads}
+		u := &ledger.TrieUpdate{RootHash: f.GetEmptyRootHash(), Paths: append(paths, path4), Payloads: append(payloads, payload4)}
 		rootHash, err := f.Update(u)
 		require.NoError(t, err, "error updating trie")
 
@@ -221,12 +229,20 @@ func TestPartialTrieLeafUpdates(t *testing.T) {
 		require.NoError(t, err, "error updating psmt")
 		ensureRootHash(t, rootHash, psmt)
 
-		// Update on non-existent leafs
+		// Update on non-existent leafs which are proven absent
+		rootHash, err = f.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: []ledger.Path{path3}, Payloads: []*ledger.Payload{payload3}})
+		require.NoError(t, err, "error updating trie")
+
 		_, err = psmt.Update([]ledger.Path{path3}, []*ledger.Payload{payload3})
+		require.NoError(t, err, "error updating psmt")
+		ensureRootHash(t, rootHash, psmt)
+
+		// Update on non-existent leafs which aren't proven absent
+		_, err = psmt.Update([]ledger.Path{path5}, []*ledger.Payload{payload5})
 		missingPathErr, ok := err.(*ErrMissingPath)
 		require.True(t, ok)
 		require.Equal(t, 1, len(missingPathErr.Paths))
-		require.Equal(t, path3, missingPathErr.Paths[0])
+		require.Equal(t, path5, missingPathErr.Paths[0])
 	})
 
 }
@@ -421,6 +437,256 @@ func TestRandomProofs(t *testing.T) {
 	}
 }
 
+// TestPartialTrieInsertSplitsLeaf inserts a register at the position of the compact leaf of another register.
+func TestPartialTrieInsertSplitsLeaf(t *testing.T) {
+
+	withForest(t, 32, 10, func(t *testing.T, f *mtrie.Forest) {
+
+		path1 := testutils.PathByUint16(0)
+		payload1 := testutils.LightPayload('A', 'a')
+		updatedPayload1 := testutils.LightPayload('B', 'b')
+
+		path2 := testutils.PathByUint16(1)
+		payload2 := testutils.LightPayload('C', 'c')
+
+		rootHash, err := f.Update(&ledger.TrieUpdate{RootHash: f.GetEmptyRootHash(), Paths: []ledger.Path{path1}, Payloads: []*ledger.Payload{payload1}})
+		require.NoError(t, err, "error updating trie")
+
+		bp, err := f.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: []ledger.Path{path1}})
+		require.NoError(t, err, "error getting batch proof")
+
+		psmt, err := NewPSMT(rootHash, bp)
+		require.NoError(t, err, "error building partial trie")
+		ensureRootHash(t, rootHash, psmt)
+
+		paths := []ledger.Path{path2, path1}
+		payloads := []*ledger.Payload{payload2, updatedPayload1}
+
+		rootHash, err = f.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: payloads})
+		require.NoError(t, err, "error updating trie")
+
+		_, err = psmt.Update(paths, payloads)
+		require.NoError(t, err, "error updating psmt")
+		ensureRootHash(t, rootHash, psmt)
+
+		retPayloads, err := psmt.Get(paths)
+		require.NoError(t, err)
+		require.Equal(t, payloads, retPayloads)
+	})
+}
+
+func TestPartialTrieMergeProofs(t *testing.T) {
+
+	withForest(t, 32, 10, func(t *testing.T, f *mtrie.Forest) {
+
+		paths := testutils.RandomPaths(50)
+		payloads := testutils.RandomPayloads(len(paths), 2, 10)
+
+		rootHash, err := f.Update(&ledger.TrieUpdate{RootHash: f.GetEmptyRootHash(), Paths: paths[:40], Payloads: payloads[:40]})
+		require.NoError(t, err, "error updating trie")
+
+		// the first proofs cover existing registers, the second ones existing and absent registers.
+		// Proofs sorts the paths, so it's given copies.
+		bp1, err := f.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: append([]ledger.Path(nil), paths[:20]...)})
+		require.NoError(t, err, "error getting batch proof")
+		bp2, err := f.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: append([]ledger.Path(nil), paths[10:]...)})
+		require.NoError(t, err, "error getting batch proof")
+
+		psmt, err := NewPSMT(rootHash, bp1)
+		require.NoError(t, err, "error building partial trie")
+
+		_, err = psmt.Get(paths[20:])
+		require.Error(t, err)
+
+		err = psmt.MergeProofs(bp2)
+		require.NoError(t, err, "error merging proofs")
+		ensureRootHash(t, rootHash, psmt)
+
+		retPayloads, err := psmt.Get(paths[:40])
+		require.NoError(t, err)
+		require.Equal(t, payloads[:40], retPayloads)
+
+		retPayloads, err = psmt.Get(paths[40:])
+		require.NoError(t, err)
+		for _, payload := range retPayloads {
+			require.True(t, payload.IsEmpty())
+		}
+
+		updatedPayloads := testutils.RandomPayloads(len(paths), 2, 10)
+		rootHash, err = f.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: updatedPayloads})
+		require.NoError(t, err, "error updating trie")
+
+		_, err = psmt.Update(paths, updatedPayloads)
+		require.NoError(t, err, "error updating psmt")
+		ensureRootHash(t, rootHash, psmt)
+
+		// proofs of another state can't be merged, and leave the partial trie unchanged
+		err = psmt.MergeProofs(bp1)
+		require.Error(t, err)
+		ensureRootHash(t, rootHash, psmt)
+	})
+}
+
+// FuzzPartialTrieUpdates compares updates of a partial trie, built from merged batch proofs,
+// with updates of the complete trie.
+func FuzzPartialTrieUpdates(f *testing.F) {
+	f.Add(int64(0), uint8(10), uint8(10), uint8(10))
+	f.Add(int64(1), uint8(0), uint8(5), uint8(20))
+	f.Add(int64(2), uint8(100), uint8(1), uint8(50))
+	f.Add(int64(3), uint8(30), uint8(30), uint8(0))
+
+	f.Fuzz(func(t *testing.T, seed int64, registerCount uint8, proofCount uint8, updateCount uint8) {
+		rng := rand.New(rand.NewSource(seed))
+
+		// paths share few prefixes, so that inserts split leaves and end in empty subtries
+		randomPath := func() ledger.Path {
+			var path ledger.Path
+			_, _ = rng.Read(path[:])
+			path[0] &= 0x81
+			path[1] &= 0x03
+			return path
+		}
+		randomPayload := func() *ledger.Payload {
+			return testutils.LightPayload(uint16(rng.Intn(1<<16)), uint16(rng.Intn(1<<16)))
+		}
+
+		forest, err := mtrie.NewForest(3, &metrics.NoopCollector{}, nil)
+		require.NoError(t, err)
+
+		paths := make([]ledger.Path, registerCount)
+		payloads := make([]*ledger.Payload, registerCount)
+		for i := range paths {
+			paths[i] = randomPath()
+			payloads[i] = randomPayload()
+		}
+		rootHash, err := forest.Update(&ledger.TrieUpdate{RootHash: forest.GetEmptyRootHash(), Paths: paths, Payloads: payloads})
+		require.NoError(t, err)
+
+		// proofs of existing and absent registers, in two batches
+		proofPaths := make([]ledger.Path, 0, int(proofCount)+1)
+		for i := 0; i <= int(proofCount); i++ {
+			if len(paths) > 0 && rng.Intn(2) == 0 {
+				proofPaths = append(proofPaths, paths[rng.Intn(len(paths))])
+			} else {
+				proofPaths = append(proofPaths, randomPath())
+			}
+		}
+		split := rng.Intn(len(proofPaths))
+
+		bp1, err := forest.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: deduplicate(proofPaths[:split+1])})
+		require.NoError(t, err)
+		psmt, err := NewPSMT(rootHash, bp1)
+		require.NoError(t, err)
+
+		if split+1 < len(proofPaths) {
+			bp2, err := forest.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: deduplicate(proofPaths[split+1:])})
+			require.NoError(t, err)
+			err = psmt.MergeProofs(bp2)
+			require.NoError(t, err)
+		}
+		ensureRootHash(t, rootHash, psmt)
+
+		// updates of proven registers and inserts of new registers
+		proven := make(map[ledger.Path]struct{}, len(proofPaths))
+		for _, path := range proofPaths {
+			proven[path] = struct{}{}
+		}
+
+		updatePaths := make([]ledger.Path, 0, updateCount)
+		updatePayloads := make([]*ledger.Payload, 0, updateCount)
+		for i := 0; i < int(updateCount); i++ {
+			if rng.Intn(2) == 0 {
+				updatePaths = append(updatePaths, proofPaths[rng.Intn(len(proofPaths))])
+			} else {
+				updatePaths = append(updatePaths, randomPath())
+			}
+			updatePayloads = append(updatePayloads, randomPayload())
+		}
+
+		expectedRootHash, err := forest.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: updatePaths, Payloads: updatePayloads})
+		require.NoError(t, err)
+
+		updatedRootHash, err := psmt.Update(updatePaths, updatePayloads)
+		if err != nil {
+			// only inserts of registers which aren't proven absent can fail
+			missingPathErr, ok := err.(*ErrMissingPath)
+			require.True(t, ok)
+			for _, path := range missingPathErr.Paths {
+				require.NotContains(t, proven, path)
+			}
+			return
+		}
+		require.Equal(t, expectedRootHash, updatedRootHash)
+		ensureRootHash(t, expectedRootHash, psmt)
+	})
+}
+
+func deduplicate(paths []ledger.Path) []ledger.Path {
+	seen := make(map[ledger.Path]struct{}, len(paths))
+	unique := make([]ledger.Path, 0, len(paths))
+	for _, path := range paths {
+		if _, ok := seen[path]; !ok {
+			seen[path] = struct{}{}
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
+
+func TestPartialTrieWithHasher(t *testing.T) {
+
+	hasher, err := ledger.NewTrieHasher(hash.BLAKE3)
+	require.NoError(t, err)
+
+	f, err := mtrie.NewForest(10, &metrics.NoopCollector{}, nil, mtrie.WithHasher(hasher))
+	require.NoError(t, err)
+
+	path1 := testutils.PathByUint16(0)
+	payload1 := testutils.LightPayload('A', 'a')
+	updatedPayload1 := testutils.LightPayload('B', 'b')
+
+	path2 := testutils.PathByUint16(1 << 15)
+	payload2 := testutils.LightPayload('C', 'c')
+
+	// path3 is proven absent by the leaf of path1
+	path3 := testutils.PathByUint16(1)
+	payload3 := testutils.LightPayload('D', 'd')
+
+	paths := []ledger.Path{path1, path2}
+	rootHash, err := f.Update(&ledger.TrieUpdate{RootHash: f.GetEmptyRootHash(), Paths: paths, Payloads: []*ledger.Payload{payload1, payload2}})
+	require.NoError(t, err)
+
+	bp, err := f.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: []ledger.Path{path1}})
+	require.NoError(t, err)
+
+	// the proofs can't be verified with the default hasher
+	_, err = NewPSMT(rootHash, bp)
+	require.Error(t, err)
+
+	psmt, err := NewPSMTWithHasher(rootHash, bp, hasher)
+	require.NoError(t, err)
+	ensureRootHash(t, rootHash, psmt)
+
+	updatedPaths := []ledger.Path{path1, path3}
+	updatedPayloads := []*ledger.Payload{updatedPayload1, payload3}
+	rootHash, err = f.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: updatedPaths, Payloads: updatedPayloads})
+	require.NoError(t, err)
+
+	_, err = psmt.Update(updatedPaths, updatedPayloads)
+	require.NoError(t, err)
+	ensureRootHash(t, rootHash, psmt)
+
+	// merged proofs are verified with the hasher of the partial trie
+	bp, err = f.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: []ledger.Path{path2}})
+	require.NoError(t, err)
+	require.NoError(t, psmt.MergeProofs(bp))
+	ensureRootHash(t, rootHash, psmt)
+
+	payload, err := psmt.GetSinglePayload(path2)
+	require.NoError(t, err)
+	require.Equal(t, payload2, payload)
+}
+
 // TODO add test for incompatible proofs [Byzantine milestone]
 // TODO add test key not exist [Byzantine milestone]
 
@@ -431,7 +697,7 @@ func ensureRootHash(t *testing.T, expectedRootHash ledger.RootHash, psmt *PSMT)
 	if expectedRootHash != psmt.RootHash() {
 		t.Fatal("rootNode hash doesn't match")
 	}
-	if expectedRootHash != ledger.RootHash(psmt.root.forceComputeHash()) {
+	if expectedRootHash != ledger.RootHash(psmt.root.forceComputeHash(psmt.hasher)) {
 		t.Fatal("rootNode hash doesn't match")
 	}
 }
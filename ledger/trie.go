@@ -9,8 +9,6 @@ import (
 
 	"github.com/fxamacker/cbor/v2"
 
-	cryptoHash "github.com/onflow/flow-go/crypto/hash"
-	"github.com/onflow/flow-go/ledger/common/bitutils"
 	"github.com/onflow/flow-go/ledger/common/hash"
 )
 
@@ -37,53 +35,20 @@ const NodeMaxHeight = PathLen * 8
 // A path of length k is comprised of k+1 vertices. Hence, we need 257 default hashes.
 const defaultHashesNum = NodeMaxHeight + 1
 
-// array to store all default hashes
-var defaultHashes [defaultHashesNum]hash.Hash
-
-func init() {
-
-	// default value and default hash value for a default node
-	var defaultLeafHash hash.Hash
-	castedPointer := (*[hash.HashLen]byte)(&defaultLeafHash)
-	cryptoHash.ComputeSHA3_256(castedPointer, []byte("default:"))
-
-	// Creates the Default hashes from base to level height
-	defaultHashes[0] = defaultLeafHash
-	for i := 1; i < defaultHashesNum; i++ {
-		defaultHashes[i] = hash.HashInterNode(defaultHashes[i-1], defaultHashes[i-1])
-	}
-}
-
-// GetDefaultHashForHeight returns the default hashes of the SMT at a specified height.
+// GetDefaultHashForHeight returns the default hashes of the SMT at a specified height,
+// using the default trie hasher.
 //
 // For each tree level N, there is a default hash equal to the chained
 // hashing of the default value N times.
 func GetDefaultHashForHeight(height int) hash.Hash {
-	return defaultHashes[height]
+	return DefaultTrieHasher.GetDefaultHashForHeight(height)
 }
 
 // ComputeCompactValue computes the value for the node considering the sub tree
-// to only include this value and default values. It writes the hash result to the result input.
+// to only include this value and default values, using the default trie hasher.
 // UNCHECKED: payload!= nil
 func ComputeCompactValue(path hash.Hash, value []byte, nodeHeight int) hash.Hash {
-	// if register is unallocated: return default hash
-	if len(value) == 0 {
-		return GetDefaultHashForHeight(nodeHeight)
-	}
-
-	var out hash.Hash
-	out = hash.HashLeaf(path, value)   // we first compute the hash of the fully-expanded leaf
-	for h := 1; h <= nodeHeight; h++ { // then, we hash our way upwards towards the root until we hit the specified nodeHeight
-		// h is the height of the node, whose hash we are computing in this iteration.
-		// The hash is computed from the node's children at height h-1.
-		bit := bitutils.ReadBit(path[:], NodeMaxHeight-h)
-		if bit == 1 { // right branching
-			out = hash.HashInterNode(GetDefaultHashForHeight(h-1), out)
-		} else { // left branching
-			out = hash.HashInterNode(out, GetDefaultHashForHeight(h-1))
-		}
-	}
-	return out
+	return DefaultTrieHasher.ComputeCompactValue(path, value, nodeHeight)
 }
 
 // TrieRead captures a trie read query
@@ -158,6 +123,24 @@ func (u *TrieUpdate) Equals(other *TrieUpdate) bool {
 	return true
 }
 
+// PayloadDiff captures the change of the payload at a path between two tries.
+// From is nil if the payload has been added, and To is nil if the payload has been removed.
+type PayloadDiff struct {
+	Path Path
+	From *Payload
+	To   *Payload
+}
+
+// IsAdded returns true if the payload doesn't exist in the first trie.
+func (d *PayloadDiff) IsAdded() bool {
+	return d.From == nil
+}
+
+// IsRemoved returns true if the payload doesn't exist in the second trie.
+func (d *PayloadDiff) IsRemoved() bool {
+	return d.To == nil
+}
+
 // RootHash captures the root hash of a trie
 type RootHash hash.Hash
 
@@ -561,3 +544,79 @@ func (bp *TrieBatchProof) Equals(o *TrieBatchProof) bool {
 	}
 	return true
 }
+
+// TrieRangeProof includes all the information needed to verify that
+// a set of payloads is the complete set of payloads stored under a path prefix.
+//
+// The proof covers the subtrie at depth Steps along the prefix, which is either
+// the subtrie containing exactly the paths with the given prefix (Steps == 8*len(Prefix)),
+// or a smaller subtrie holding at most one payload. Paths and Payloads contain
+// all non-empty payloads stored in that subtrie, ordered by path.
+type TrieRangeProof struct {
+	Prefix   []byte      // path prefix of the range
+	Paths    []Path      // paths of all payloads in the proven subtrie
+	Payloads []*Payload  // payloads of all payloads in the proven subtrie
+	Interims []hash.Hash // the non-default sibling hashes from the root to the proven subtrie
+	Flags    []byte      // The flags of the proofs (is set if a sibling has a non-default hash)
+	Steps    uint16      // number of edges from the root to the proven subtrie
+}
+
+// NewTrieRangeProof creates a new instance of Trie Range Proof
+func NewTrieRangeProof(prefix []byte) *TrieRangeProof {
+	return &TrieRangeProof{
+		Prefix:   prefix,
+		Paths:    make([]Path, 0),
+		Payloads: make([]*Payload, 0),
+		Interims: make([]hash.Hash, 0),
+		Flags:    make([]byte, PathLen),
+		Steps:    0,
+	}
+}
+
+// InRange returns the paths and payloads of the proof which have the prefix of the range.
+func (p *TrieRangeProof) InRange() ([]Path, []*Payload) {
+	paths := make([]Path, 0, len(p.Paths))
+	payloads := make([]*Payload, 0, len(p.Payloads))
+	for i, path := range p.Paths {
+		if bytes.HasPrefix(path[:], p.Prefix) {
+			paths = append(paths, path)
+			payloads = append(payloads, p.Payloads[i])
+		}
+	}
+	return paths, payloads
+}
+
+// Equals compares this range proof to another range proof
+func (p *TrieRangeProof) Equals(o *TrieRangeProof) bool {
+	if o == nil {
+		return false
+	}
+	if !bytes.Equal(p.Prefix, o.Prefix) {
+		return false
+	}
+	if len(p.Paths) != len(o.Paths) || len(p.Payloads) != len(o.Payloads) {
+		return false
+	}
+	for i, path := range p.Paths {
+		if !path.Equals(o.Paths[i]) {
+			return false
+		}
+	}
+	for i, payload := range p.Payloads {
+		if !payload.Equals(o.Payloads[i]) {
+			return false
+		}
+	}
+	if len(p.Interims) != len(o.Interims) {
+		return false
+	}
+	for i, inter := range p.Interims {
+		if inter != o.Interims[i] {
+			return false
+		}
+	}
+	if !bytes.Equal(p.Flags, o.Flags) {
+		return false
+	}
+	return p.Steps == o.Steps
+}
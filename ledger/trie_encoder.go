@@ -22,6 +22,7 @@ const (
 	TrieUpdateVersion     = uint16(0) // Use payload version 0 encoding
 	TrieProofVersion      = uint16(0) // Use payload version 0 encoding
 	TrieBatchProofVersion = uint16(0) // Use payload version 0 encoding
+	TrieRangeProofVersion = uint16(0) // Use payload version 0 encoding
 )
 
 // Type capture the type of encoded entity (e.g. State, Key, Value, Path)
@@ -53,12 +54,15 @@ const (
 	TypeUpdate
 	// TypeTrieUpdate - type for trie update
 	TypeTrieUpdate
+	// TypeRangeProof - type for RangeProofs
+	// (all data needed to verify the payloads under a path prefix at specific state)
+	TypeRangeProof
 	// this is used to flag types from the future
 	typeUnsuported
 )
 
 func (e Type) String() string {
-	return [...]string{"Unknown", "State", "KeyPart", "Key", "Value", "Path", "Payload", "Proof", "BatchProof", "Query", "Update", "Trie Update"}[e]
+	return [...]string{"Unknown", "State", "KeyPart", "Key", "Value", "Path", "Payload", "Proof", "BatchProof", "Query", "Update", "Trie Update", "RangeProof"}[e]
 }
 
 // CheckVersion extracts encoding bytes from a raw encoded message
@@ -886,3 +890,152 @@ func decodeTrieBatchProof(inp []byte, version uint16) (*TrieBatchProof, error) {
 	}
 	return bp, nil
 }
+
+// EncodeTrieRangeProof encodes a range proof into a byte slice
+func EncodeTrieRangeProof(p *TrieRangeProof) []byte {
+	if p == nil {
+		return []byte{}
+	}
+	// encode version
+	buffer := utils.AppendUint16([]byte{}, TrieRangeProofVersion)
+
+	// encode range proof entity type
+	buffer = utils.AppendUint8(buffer, TypeRangeProof)
+
+	// append encoded range proof content
+	buffer = append(buffer, encodeTrieRangeProof(p, TrieRangeProofVersion)...)
+
+	return buffer
+}
+
+func encodeTrieRangeProof(p *TrieRangeProof, version uint16) []byte {
+	buffer := make([]byte, 0)
+
+	// include prefix size and content
+	buffer = utils.AppendUint8(buffer, uint8(len(p.Prefix)))
+	buffer = append(buffer, p.Prefix...)
+
+	// steps are encoded as two bytes, as a proof can reach the leaves (256 steps)
+	buffer = utils.AppendUint16(buffer, p.Steps)
+
+	// include flags size and content
+	buffer = utils.AppendUint8(buffer, uint8(len(p.Flags)))
+	buffer = append(buffer, p.Flags...)
+
+	// include all interims (hash values)
+	buffer = utils.AppendUint16(buffer, uint16(len(p.Interims)))
+	for _, inter := range p.Interims {
+		buffer = append(buffer, inter[:]...)
+	}
+
+	// and finally include all paths and payloads
+	buffer = utils.AppendUint32(buffer, uint32(len(p.Paths)))
+	for i, path := range p.Paths {
+		buffer = append(buffer, path[:]...)
+
+		encPayload := encodePayload(p.Payloads[i], version)
+		buffer = utils.AppendUint64(buffer, uint64(len(encPayload)))
+		buffer = append(buffer, encPayload...)
+	}
+
+	return buffer
+}
+
+// DecodeTrieRangeProof constructs a range proof from an encoded byte slice
+func DecodeTrieRangeProof(encodedProof []byte) (*TrieRangeProof, error) {
+	// check the enc dec version
+	rest, version, err := CheckVersion(encodedProof, TrieRangeProofVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+	// check the encoding type
+	rest, err = CheckType(rest, TypeRangeProof)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+	return decodeTrieRangeProof(rest, version)
+}
+
+func decodeTrieRangeProof(inp []byte, version uint16) (*TrieRangeProof, error) {
+	// read prefix
+	prefixSize, rest, err := utils.ReadUint8(inp)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+	prefix, rest, err := utils.ReadSlice(rest, int(prefixSize))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+	pInst := NewTrieRangeProof(prefix)
+
+	// read steps
+	pInst.Steps, rest, err = utils.ReadUint16(rest)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+
+	// read flags
+	flagsSize, rest, err := utils.ReadUint8(rest)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+	pInst.Flags, rest, err = utils.ReadSlice(rest, int(flagsSize))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+
+	// read interims
+	interimsLen, rest, err := utils.ReadUint16(rest)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+	pInst.Interims = make([]hash.Hash, interimsLen)
+	for i := range pInst.Interims {
+		var interimBytes []byte
+		interimBytes, rest, err = utils.ReadSlice(rest, hash.HashLen)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding range proof: %w", err)
+		}
+		pInst.Interims[i], err = hash.ToHash(interimBytes)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding range proof: %w", err)
+		}
+	}
+
+	// read paths and payloads
+	payloadsLen, rest, err := utils.ReadUint32(rest)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding range proof: %w", err)
+	}
+	pInst.Paths = make([]Path, payloadsLen)
+	pInst.Payloads = make([]*Payload, payloadsLen)
+	for i := 0; i < int(payloadsLen); i++ {
+		var pathBytes []byte
+		pathBytes, rest, err = utils.ReadSlice(rest, PathLen)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding range proof: %w", err)
+		}
+		pInst.Paths[i], err = ToPath(pathBytes)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding range proof: %w", err)
+		}
+
+		var encPayloadSize uint64
+		encPayloadSize, rest, err = utils.ReadUint64(rest)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding range proof: %w", err)
+		}
+		var encPayload []byte
+		encPayload, rest, err = utils.ReadSlice(rest, int(encPayloadSize))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding range proof: %w", err)
+		}
+		// Decode payload (zerocopy)
+		pInst.Payloads[i], err = decodePayload(encPayload, true, version)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding range proof: %w", err)
+		}
+	}
+
+	return pInst, nil
+}
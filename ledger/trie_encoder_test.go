@@ -616,3 +616,26 @@ func TestTrieUpdateSerialization(t *testing.T) {
 		require.True(t, decodedtu.Equals(tu))
 	})
 }
+
+// TestTrieRangeProofSerialization tests encoding and decoding functionality of a range proof
+func TestTrieRangeProofSerialization(t *testing.T) {
+	p := ledger.NewTrieRangeProof([]byte{0x01, 0x4a})
+	p.Paths = []ledger.Path{testutils.PathByUint16(330), testutils.PathByUint16(331)}
+	p.Payloads = []*ledger.Payload{testutils.LightPayload8('A', 'A'), testutils.LightPayload8('B', 'B')}
+	p.Interims = []hash.Hash{hash.Hash(testutils.PathByUint8(1)), hash.Hash(testutils.PathByUint8(2))}
+	p.Flags[0] = 0x82
+	p.Steps = 15
+
+	encoded := ledger.EncodeTrieRangeProof(p)
+	require.Equal(t, []byte{0x00, 0x00, byte(ledger.TypeRangeProof)}, encoded[:3])
+
+	decoded, err := ledger.DecodeTrieRangeProof(encoded)
+	require.NoError(t, err)
+	require.True(t, decoded.Equals(p))
+
+	_, err = ledger.DecodeTrieRangeProof(encoded[:len(encoded)-1])
+	require.Error(t, err)
+
+	_, err = ledger.DecodeTrieRangeProof(ledger.EncodeTrieProof(&ledger.TrieProof{Payload: ledger.EmptyPayload()}))
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
+)
+
+// defaultLeafValue is hashed to get the default hash of an unallocated register at height 0
+var defaultLeafValue = []byte("default:")
+
+// DefaultTrieHasher is the trie hasher used unless another one is configured, based on SHA3-256.
+var DefaultTrieHasher = mustNewTrieHasher(hash.SHA3_256)
+
+// TrieHasher computes the hashes of trie nodes with a given hash.Hasher.
+// The default hashes of empty subtries of all heights are computed once on construction.
+// Tries, proofs and checkpoints must use the same TrieHasher consistently,
+// as the hashes of the same trie computed by different hashers differ.
+type TrieHasher struct {
+	hasherType    hash.HasherType
+	hasher        hash.Hasher
+	defaultHashes [defaultHashesNum]hash.Hash
+}
+
+// NewTrieHasher returns the trie hasher based on the given hasher type.
+func NewTrieHasher(hasherType hash.HasherType) (*TrieHasher, error) {
+	hasher, err := hash.NewHasher(hasherType)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &TrieHasher{
+		hasherType: hasherType,
+		hasher:     hasher,
+	}
+
+	// Creates the Default hashes from base to level height
+	h.defaultHashes[0] = hasher.HashData(defaultLeafValue)
+	for i := 1; i < defaultHashesNum; i++ {
+		h.defaultHashes[i] = hasher.HashInterNode(h.defaultHashes[i-1], h.defaultHashes[i-1])
+	}
+
+	return h, nil
+}
+
+func mustNewTrieHasher(hasherType hash.HasherType) *TrieHasher {
+	h, err := NewTrieHasher(hasherType)
+	if err != nil {
+		panic(fmt.Sprintf("cannot create trie hasher: %v", err))
+	}
+	return h
+}
+
+// Type returns the type of the underlying hasher.
+func (h *TrieHasher) Type() hash.HasherType {
+	return h.hasherType
+}
+
+// HashLeaf returns the hash value for fully-expanded leaf nodes.
+func (h *TrieHasher) HashLeaf(path hash.Hash, value []byte) hash.Hash {
+	return h.hasher.HashLeaf(path, value)
+}
+
+// HashInterNode returns the hash value for intermediate nodes.
+func (h *TrieHasher) HashInterNode(hash1 hash.Hash, hash2 hash.Hash) hash.Hash {
+	return h.hasher.HashInterNode(hash1, hash2)
+}
+
+// GetDefaultHashForHeight returns the default hashes of the SMT at a specified height.
+//
+// For each tree level N, there is a default hash equal to the chained
+// hashing of the default value N times.
+func (h *TrieHasher) GetDefaultHashForHeight(height int) hash.Hash {
+	return h.defaultHashes[height]
+}
+
+// EmptyTrieRootHash returns the root hash of a trie without allocated registers.
+func (h *TrieHasher) EmptyTrieRootHash() RootHash {
+	return RootHash(h.defaultHashes[NodeMaxHeight])
+}
+
+// ComputeCompactValue computes the value for the node considering the sub tree
+// to only include this value and default values.
+// UNCHECKED: payload!= nil
+func (h *TrieHasher) ComputeCompactValue(path hash.Hash, value []byte, nodeHeight int) hash.Hash {
+	// if register is unallocated: return default hash
+	if len(value) == 0 {
+		return h.GetDefaultHashForHeight(nodeHeight)
+	}
+
+	var out hash.Hash
+	out = h.HashLeaf(path, value)         // we first compute the hash of the fully-expanded leaf
+	for ht := 1; ht <= nodeHeight; ht++ { // then, we hash our way upwards towards the root until we hit the specified nodeHeight
+		// ht is the height of the node, whose hash we are computing in this iteration.
+		// The hash is computed from the node's children at height ht-1.
+		bit := bitutils.ReadBit(path[:], NodeMaxHeight-ht)
+		if bit == 1 { // right branching
+			out = h.HashInterNode(h.GetDefaultHashForHeight(ht-1), out)
+		} else { // left branching
+			out = h.HashInterNode(out, h.GetDefaultHashForHeight(ht-1))
+		}
+	}
+	return out
+}
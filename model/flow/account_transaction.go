@@ -0,0 +1,79 @@
+package flow
+
+// AccountTransactionRole is a set of the roles an account had in a transaction.
+type AccountTransactionRole uint8
+
+const (
+	// AccountTransactionRolePayer is set if the account paid the fees of the transaction.
+	AccountTransactionRolePayer AccountTransactionRole = 1 << iota
+	// AccountTransactionRoleProposer is set if the account provided the proposal key of the transaction.
+	AccountTransactionRoleProposer
+	// AccountTransactionRoleAuthorizer is set if the account authorized the transaction.
+	AccountTransactionRoleAuthorizer
+	// AccountTransactionRoleEventEmitter is set if a contract deployed to the account emitted an
+	// event during the execution of the transaction.
+	AccountTransactionRoleEventEmitter
+)
+
+// accountTransactionRoles lists all account transaction roles, in the order of their bits.
+var accountTransactionRoles = []AccountTransactionRole{
+	AccountTransactionRolePayer,
+	AccountTransactionRoleProposer,
+	AccountTransactionRoleAuthorizer,
+	AccountTransactionRoleEventEmitter,
+}
+
+// Roles returns the individual roles in the set.
+func (r AccountTransactionRole) Roles() []AccountTransactionRole {
+	roles := make([]AccountTransactionRole, 0, len(accountTransactionRoles))
+	for _, role := range accountTransactionRoles {
+		if r&role != 0 {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Has returns true if the set contains all the given roles.
+func (r AccountTransactionRole) Has(role AccountTransactionRole) bool {
+	return r&role == role
+}
+
+func (r AccountTransactionRole) String() string {
+	switch r {
+	case AccountTransactionRolePayer:
+		return "payer"
+	case AccountTransactionRoleProposer:
+		return "proposer"
+	case AccountTransactionRoleAuthorizer:
+		return "authorizer"
+	case AccountTransactionRoleEventEmitter:
+		return "event_emitter"
+	default:
+		return "unknown"
+	}
+}
+
+// AccountTransaction is an entry of the account transaction index, recording that an account was
+// involved in a transaction.
+type AccountTransaction struct {
+	Address       Address
+	BlockHeight   uint64
+	TransactionID Identifier
+	Roles         AccountTransactionRole
+}
+
+// Cursor returns the cursor pointing to this entry.
+func (a AccountTransaction) Cursor() AccountTransactionCursor {
+	return AccountTransactionCursor{
+		BlockHeight:   a.BlockHeight,
+		TransactionID: a.TransactionID,
+	}
+}
+
+// AccountTransactionCursor points to an entry of the transactions of an account, which are ordered
+// by block height and transaction ID.
+type AccountTransactionCursor struct {
+	BlockHeight   uint64
+	TransactionID Identifier
+}
@@ -49,6 +49,20 @@ func NewRegisterID(owner, key string) RegisterID {
 // RegisterValue (value part of Register)
 type RegisterValue = []byte
 
+// RegisterAccess is a register read or written, with the size of the value read or written in bytes.
+type RegisterAccess struct {
+	Register RegisterID
+	Size     uint64
+}
+
+// RegisterAccesses are the registers read and written by a transaction, sorted by register ID.
+// Reads only contain the registers read before being written by the transaction.
+type RegisterAccesses struct {
+	TransactionID Identifier
+	Reads         []RegisterAccess
+	Writes        []RegisterAccess
+}
+
 type RegisterEntry struct {
 	Key   RegisterID
 	Value RegisterValue
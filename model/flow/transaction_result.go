@@ -13,6 +13,21 @@ type TransactionResult struct {
 	ErrorMessage string
 	// Computation used
 	ComputationUsed uint64
+	// ContractUsages are the computation and memory used while executing the code of each contract
+	ContractUsages []ContractUsage
+}
+
+// ContractUsage is the computation and memory used by a transaction while executing the code of a contract.
+type ContractUsage struct {
+	Address         Address
+	Name            string
+	ComputationUsed uint64
+	MemoryEstimate  uint64
+}
+
+// ContractID returns the identifier of the contract, as its address and name.
+func (u ContractUsage) ContractID() string {
+	return fmt.Sprintf("%s.%s", u.Address.Hex(), u.Name)
 }
 
 // String returns the string representation of this error.
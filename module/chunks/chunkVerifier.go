@@ -24,24 +24,54 @@ import (
 
 type VirtualMachine interface {
 	Run(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error
+	ApplyExecutionParameters(fvm.Context, state.View, *programs.Programs) (fvm.Context, error)
 }
 
 // ChunkVerifier is a verifier based on the current definitions of the flow network
 type ChunkVerifier struct {
-	vm             VirtualMachine
-	vmCtx          fvm.Context
-	systemChunkCtx fvm.Context
-	logger         zerolog.Logger
+	vm                VirtualMachine
+	vmCtx             fvm.Context
+	systemChunkCtx    fvm.Context
+	logger            zerolog.Logger
+	trieHasher        *ledger.TrieHasher
+	pathFinderVersion uint8
+}
+
+// ChunkVerifierOption configures a chunk verifier.
+type ChunkVerifierOption func(*ChunkVerifier)
+
+// WithTrieHasher configures the hasher of the execution state trie, which the proofs of the
+// chunk data packs are verified with. Defaults to ledger.DefaultTrieHasher.
+func WithTrieHasher(hasher *ledger.TrieHasher) ChunkVerifierOption {
+	return func(fcv *ChunkVerifier) {
+		fcv.trieHasher = hasher
+	}
+}
+
+// WithPathFinderVersion configures the version of the key to path conversion of the execution
+// state the chunk data packs are read from. Defaults to partial.DefaultPathFinderVersion.
+func WithPathFinderVersion(version uint8) ChunkVerifierOption {
+	return func(fcv *ChunkVerifier) {
+		fcv.pathFinderVersion = version
+	}
 }
 
 // NewChunkVerifier creates a chunk verifier containing a flow virtual machine
-func NewChunkVerifier(vm VirtualMachine, vmCtx fvm.Context, logger zerolog.Logger) *ChunkVerifier {
-	return &ChunkVerifier{
-		vm:             vm,
-		vmCtx:          vmCtx,
-		systemChunkCtx: computer.SystemChunkContext(vmCtx, vmCtx.Logger),
-		logger:         logger.With().Str("component", "chunk_verifier").Logger(),
+func NewChunkVerifier(vm VirtualMachine, vmCtx fvm.Context, logger zerolog.Logger, opts ...ChunkVerifierOption) *ChunkVerifier {
+	fcv := &ChunkVerifier{
+		vm:                vm,
+		vmCtx:             vmCtx,
+		systemChunkCtx:    computer.SystemChunkContext(vmCtx, vmCtx.Logger),
+		logger:            logger.With().Str("component", "chunk_verifier").Logger(),
+		trieHasher:        ledger.DefaultTrieHasher,
+		pathFinderVersion: partial.DefaultPathFinderVersion,
 	}
+
+	for _, opt := range opts {
+		opt(fcv)
+	}
+
+	return fcv
 }
 
 // Verify verifies a given VerifiableChunk corresponding to a non-system chunk.
@@ -110,7 +140,7 @@ func (fcv *ChunkVerifier) verifyTransactionsInContext(context fvm.Context, chunk
 	serviceEvents := make(flow.EventsList, 0)
 
 	// constructing a partial trie given chunk data package
-	psmt, err := partial.NewLedger(chunkDataPack.Proof, ledger.State(chunkDataPack.StartState), partial.DefaultPathFinderVersion)
+	psmt, err := partial.NewLedgerWithHasher(chunkDataPack.Proof, ledger.State(chunkDataPack.StartState), fcv.pathFinderVersion, fcv.trieHasher)
 
 	if err != nil {
 		// TODO provide more details based on the error type
@@ -163,6 +193,13 @@ func (fcv *ChunkVerifier) verifyTransactionsInContext(context fvm.Context, chunk
 
 	chunkView := delta.NewView(getRegister)
 
+	// from their activation height, the execution parameters are read at the start of every chunk,
+	// like execution nodes do
+	context, err = fcv.vm.ApplyExecutionParameters(context, chunkView, programs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to apply execution parameters: %w", err)
+	}
+
 	// executes all transactions in this chunk
 	for i, tx := range transactions {
 		txView := chunkView.NewChild()
@@ -373,6 +373,10 @@ func GetBaselineVerifiableChunk(t *testing.T, script string, system bool) *verif
 
 type vmMock struct{}
 
+func (vm *vmMock) ApplyExecutionParameters(ctx fvm.Context, led state.View, programs *programs.Programs) (fvm.Context, error) {
+	return ctx, nil
+}
+
 func (vm *vmMock) Run(ctx fvm.Context, proc fvm.Procedure, led state.View, programs *programs.Programs) error {
 
 	tx, ok := proc.(*fvm.TransactionProcedure)
@@ -411,6 +415,10 @@ func (vm *vmMock) Run(ctx fvm.Context, proc fvm.Procedure, led state.View, progr
 
 type vmSystemOkMock struct{}
 
+func (vm *vmSystemOkMock) ApplyExecutionParameters(ctx fvm.Context, led state.View, programs *programs.Programs) (fvm.Context, error) {
+	return ctx, nil
+}
+
 func (vm *vmSystemOkMock) Run(ctx fvm.Context, proc fvm.Procedure, led state.View, programs *programs.Programs) error {
 	tx, ok := proc.(*fvm.TransactionProcedure)
 	if !ok {
@@ -430,6 +438,10 @@ func (vm *vmSystemOkMock) Run(ctx fvm.Context, proc fvm.Procedure, led state.Vie
 
 type vmSystemBadMock struct{}
 
+func (vm *vmSystemBadMock) ApplyExecutionParameters(ctx fvm.Context, led state.View, programs *programs.Programs) (fvm.Context, error) {
+	return ctx, nil
+}
+
 func (vm *vmSystemBadMock) Run(ctx fvm.Context, proc fvm.Procedure, led state.View, programs *programs.Programs) error {
 	tx, ok := proc.(*fvm.TransactionProcedure)
 	if !ok {
@@ -0,0 +1,264 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ErrDataNotAvailable is returned when the registers for the requested block height are not
+// available in the local register index.
+var ErrDataNotAvailable = errors.New("data for block is not available")
+
+// ErrBatchComputationLimitExceeded is returned for the scripts of a batch that were not executed
+// because the scripts before them used up the computation limit of the batch.
+var ErrBatchComputationLimitExceeded = errors.New("computation limit of the batch exceeded")
+
+// ScriptExecutionError is returned when the execution of a script failed because of the script
+// itself, e.g. a cadence runtime error, as opposed to a failure of the executor.
+type ScriptExecutionError struct {
+	err error
+}
+
+func NewScriptExecutionErrorf(msg string, args ...interface{}) error {
+	return ScriptExecutionError{
+		err: fmt.Errorf(msg, args...),
+	}
+}
+
+func (e ScriptExecutionError) Unwrap() error {
+	return e.err
+}
+
+func (e ScriptExecutionError) Error() string {
+	return e.err.Error()
+}
+
+// IsScriptExecutionError returns whether the given error is a ScriptExecutionError
+func IsScriptExecutionError(err error) bool {
+	var scriptErr ScriptExecutionError
+	return errors.As(err, &scriptErr)
+}
+
+// Script is a script to execute as part of a batch.
+type Script struct {
+	Code      []byte
+	Arguments [][]byte
+}
+
+// ScriptResult is the result of a script executed as part of a batch. Either the JSON-CDC encoded
+// Value or Err is set. Err is a ScriptExecutionError if the script failed, or
+// ErrBatchComputationLimitExceeded if the script was not executed.
+type ScriptResult struct {
+	Value           []byte
+	ComputationUsed uint64
+	Err             error
+}
+
+// Scripts executes scripts and reads accounts using the FVM against the execution state stored in
+// a local register index, without involving execution nodes.
+type Scripts struct {
+	log       zerolog.Logger
+	vm        *fvm.VirtualMachine
+	vmCtx     fvm.Context
+	headers   storage.Headers
+	registers storage.Registers
+}
+
+func NewScripts(
+	log zerolog.Logger,
+	vm *fvm.VirtualMachine,
+	vmCtx fvm.Context,
+	headers storage.Headers,
+	registers storage.Registers,
+) *Scripts {
+	return &Scripts{
+		log:       log.With().Str("module", "script_executor").Logger(),
+		vm:        vm,
+		vmCtx:     vmCtx,
+		headers:   headers,
+		registers: registers,
+	}
+}
+
+// ExecuteAtBlockHeight executes the script against the execution state at the given block height,
+// and returns the JSON-CDC encoded result.
+// Expected errors:
+// - ErrDataNotAvailable if the registers for the height are not indexed
+// - ScriptExecutionError if the script failed
+func (s *Scripts) ExecuteAtBlockHeight(ctx context.Context, code []byte, arguments [][]byte, height uint64) ([]byte, error) {
+	header, view, err := s.stateAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	blockCtx := fvm.NewContextFromParent(s.vmCtx, fvm.WithBlockHeader(header))
+
+	value, _, err := s.execute(ctx, blockCtx, header, code, arguments, view, programs.NewEmptyPrograms())
+	return value, err
+}
+
+// ExecuteBatchAtBlockHeight executes the scripts against the execution state at the given block
+// height, sharing the cache of loaded contract programs between the scripts.
+//
+// The computation used by all scripts combined is limited to computationLimit. Each script may use
+// at most the computation remaining in the batch, and scripts after the limit was reached are not
+// executed and fail with ErrBatchComputationLimitExceeded. A script failure does not fail the batch,
+// it is returned as a ScriptExecutionError in the result of the script.
+// Expected errors:
+// - ErrDataNotAvailable if the registers for the height are not indexed
+func (s *Scripts) ExecuteBatchAtBlockHeight(ctx context.Context, scripts []Script, height uint64, computationLimit uint64) ([]ScriptResult, error) {
+	header, view, err := s.stateAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	// scripts can not update contracts, so programs loaded by one script are valid for all others
+	blockPrograms := programs.NewEmptyPrograms()
+
+	results := make([]ScriptResult, len(scripts))
+	remaining := computationLimit
+	for i, script := range scripts {
+		if remaining == 0 {
+			results[i].Err = ErrBatchComputationLimitExceeded
+			continue
+		}
+
+		limit := s.vmCtx.ComputationLimit
+		if limit == 0 || limit > remaining {
+			limit = remaining
+		}
+		blockCtx := fvm.NewContextFromParent(s.vmCtx, fvm.WithBlockHeader(header), fvm.WithComputationLimit(limit))
+
+		// each script gets its own view, so that changes made by one script are not visible to the others
+		value, computationUsed, err := s.execute(ctx, blockCtx, header, script.Code, script.Arguments, view.NewChild(), blockPrograms)
+		if err != nil && !IsScriptExecutionError(err) {
+			return nil, fmt.Errorf("failed to execute script %d: %w", i, err)
+		}
+
+		results[i] = ScriptResult{
+			Value:           value,
+			ComputationUsed: computationUsed,
+			Err:             err,
+		}
+
+		if computationUsed > remaining {
+			computationUsed = remaining
+		}
+		remaining -= computationUsed
+	}
+
+	return results, nil
+}
+
+// execute executes the script in the given context and returns the JSON-CDC encoded result and the
+// computation used.
+// Expected errors:
+// - ScriptExecutionError if the script failed
+func (s *Scripts) execute(
+	ctx context.Context,
+	blockCtx fvm.Context,
+	header *flow.Header,
+	code []byte,
+	arguments [][]byte,
+	view state.View,
+	programs *programs.Programs,
+) ([]byte, uint64, error) {
+	script := fvm.NewScriptWithContextAndArgs(code, ctx, arguments...)
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				s.log.Error().
+					Hex("script_hex", code).
+					Uint64("height", header.Height).
+					Interface("recovered", r).
+					Msg("script execution caused runtime panic")
+
+				err = fmt.Errorf("cadence runtime error: %s", r)
+			}
+		}()
+
+		return s.vm.Run(blockCtx, script, view, programs)
+	}()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute script (internal error): %w", err)
+	}
+
+	if script.Err != nil {
+		return nil, script.GasUsed, NewScriptExecutionErrorf("failed to execute script at block (%s): %w", header.ID(), script.Err)
+	}
+
+	encodedValue, err := jsoncdc.Encode(script.Value)
+	if err != nil {
+		return nil, script.GasUsed, fmt.Errorf("failed to encode runtime value: %w", err)
+	}
+
+	return encodedValue, script.GasUsed, nil
+}
+
+// GetAccountAtBlockHeight returns the account with the given address at the given block height.
+// Expected errors:
+// - ErrDataNotAvailable if the registers for the height are not indexed
+// - fvm errors.AccountNotFoundError if the account does not exist at the height
+func (s *Scripts) GetAccountAtBlockHeight(_ context.Context, address flow.Address, height uint64) (*flow.Account, error) {
+	header, view, err := s.stateAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	blockCtx := fvm.NewContextFromParent(s.vmCtx, fvm.WithBlockHeader(header))
+
+	account, err := s.vm.GetAccount(blockCtx, address, view, programs.NewEmptyPrograms())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account (%s) at height (%d): %w", address.String(), height, err)
+	}
+
+	return account, nil
+}
+
+// stateAtHeight returns the header and a read-only view of the execution state at the given height.
+// Expected errors:
+// - ErrDataNotAvailable if the registers for the height are not indexed
+func (s *Scripts) stateAtHeight(height uint64) (*flow.Header, *delta.View, error) {
+	if height < s.registers.FirstHeight() || height > s.registers.LatestHeight() {
+		return nil, nil, fmt.Errorf("registers for height %d are not indexed: %w", height, ErrDataNotAvailable)
+	}
+
+	header, err := s.headers.ByHeight(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get header for height %d: %w", height, err)
+	}
+
+	view := delta.NewView(func(owner, key string) (flow.RegisterValue, error) {
+		value, err := s.registers.Get(flow.NewRegisterID(owner, key), height)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				// registers that were never set are empty
+				return nil, nil
+			}
+			if errors.Is(err, storage.ErrHeightNotIndexed) {
+				return nil, fmt.Errorf("could not get register (%s): %w", key, ErrDataNotAvailable)
+			}
+			return nil, fmt.Errorf("could not get register (%s): %w", key, err)
+		}
+
+		if len(value) == 0 {
+			return nil, nil
+		}
+
+		return value, nil
+	})
+
+	return header, view, nil
+}
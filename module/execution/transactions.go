@@ -0,0 +1,169 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/meter"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// TransactionDryRunResult is the result of executing a transaction without committing its changes.
+type TransactionDryRunResult struct {
+	// BlockHeader is the header of the block the transaction was executed against
+	BlockHeader     *flow.Header
+	ComputationUsed uint64
+	MemoryEstimate  uint64
+	// ComputationBreakdown is the computation used by each computation kind, and MemoryBreakdown
+	// the memory estimate of each memory kind, excluding the fee deduction and storage checks
+	ComputationBreakdown meter.ComputationBreakdown
+	MemoryBreakdown      meter.MemoryBreakdown
+	// Fees is the amount of fees the payer would be charged, as a UFix64
+	Fees   uint64
+	Events []flow.Event
+	// StorageDelta are the registers the transaction would update
+	StorageDelta flow.RegisterEntries
+	// Err is the error the transaction would fail with, if any
+	Err error
+}
+
+// DryRunAtLatestHeight executes the transaction against the execution state at the latest indexed
+// height, which is the latest sealed state available locally, without committing any changes. See
+// DryRunTransaction.
+//
+// The transaction failing does not return an error, the failure is returned in the result.
+// Expected errors:
+// - ErrDataNotAvailable if no registers are indexed yet
+func (s *Scripts) DryRunAtLatestHeight(
+	_ context.Context,
+	tx *flow.TransactionBody,
+	skipSignatureVerification bool,
+) (*TransactionDryRunResult, error) {
+	header, view, err := s.stateAtHeight(s.registers.LatestHeight())
+	if err != nil {
+		return nil, err
+	}
+
+	return DryRunTransaction(s.log, s.vm, s.vmCtx, header, view, programs.NewEmptyPrograms(), tx, skipSignatureVerification)
+}
+
+// ProcedureRunner runs FVM procedures, it is implemented by fvm.VirtualMachine.
+type ProcedureRunner interface {
+	Run(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error
+}
+
+// DryRunTransaction executes the transaction against the view of the execution state of the block,
+// without committing any changes. If skipSignatureVerification is set, the signatures of the
+// transaction are not checked, so that unsigned transactions can be used to estimate the cost of a
+// transaction before signing it.
+//
+// The transaction failing does not return an error, the failure is returned in the result. Any
+// returned error is a failure of the executor.
+func DryRunTransaction(
+	log zerolog.Logger,
+	vm ProcedureRunner,
+	vmCtx fvm.Context,
+	header *flow.Header,
+	view state.View,
+	blockPrograms *programs.Programs,
+	tx *flow.TransactionBody,
+	skipSignatureVerification bool,
+) (*TransactionDryRunResult, error) {
+	opts := []fvm.Option{
+		fvm.WithBlockHeader(header),
+		fvm.WithMeteringBreakdown(true),
+	}
+	if skipSignatureVerification {
+		opts = append(opts, fvm.WithTransactionProcessors(
+			fvm.NewTransactionVerifier(-1), // a negative threshold skips signature verification
+			fvm.NewTransactionSequenceNumberChecker(),
+			fvm.NewTransactionInvoker(log),
+		))
+	}
+	blockCtx := fvm.NewContextFromParent(vmCtx, opts...)
+
+	proc := fvm.Transaction(tx, 0)
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().
+					Str("tx_id", tx.ID().String()).
+					Uint64("height", header.Height).
+					Interface("recovered", r).
+					Msg("transaction dry run caused runtime panic")
+
+				err = fmt.Errorf("cadence runtime error: %s", r)
+			}
+		}()
+
+		return vm.Run(blockCtx, proc, view, blockPrograms)
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute transaction (internal error): %w", err)
+	}
+
+	ids, values := view.RegisterUpdates()
+	delta := make(flow.RegisterEntries, len(ids))
+	for i := range ids {
+		delta[i] = flow.RegisterEntry{Key: ids[i], Value: values[i]}
+	}
+
+	fees, err := transactionFees(vmCtx.Chain, proc.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransactionDryRunResult{
+		BlockHeader:          header,
+		ComputationUsed:      proc.ComputationUsed,
+		MemoryEstimate:       proc.MemoryEstimate,
+		ComputationBreakdown: proc.ComputationBreakdown,
+		MemoryBreakdown:      proc.MemoryBreakdown,
+		Fees:                 fees,
+		Events:               proc.Events,
+		StorageDelta:         delta,
+	}
+	if proc.Err != nil {
+		result.Err = proc.Err
+	}
+
+	return result, nil
+}
+
+// transactionFees returns the amount of fees deducted by the fee deduction event in the events of
+// a transaction, or 0 if fees were not deducted.
+func transactionFees(chain flow.Chain, events []flow.Event) (uint64, error) {
+	feesDeductedType := flow.EventType(fmt.Sprintf("A.%s.FlowFees.FeesDeducted", fvm.FlowFeesAddress(chain)))
+
+	for _, event := range events {
+		if event.Type != feesDeductedType {
+			continue
+		}
+
+		value, err := jsoncdc.Decode(nil, event.Payload)
+		if err != nil {
+			return 0, fmt.Errorf("could not decode fee deduction event: %w", err)
+		}
+
+		feesDeducted, ok := value.(cadence.Event)
+		if !ok || len(feesDeducted.Fields) == 0 {
+			return 0, fmt.Errorf("unexpected fee deduction event: %s", value)
+		}
+
+		amount, ok := feesDeducted.Fields[0].(cadence.UFix64)
+		if !ok {
+			return 0, fmt.Errorf("unexpected fee amount: %s", feesDeducted.Fields[0])
+		}
+
+		return uint64(amount), nil
+	}
+
+	return 0, nil
+}
@@ -14,6 +14,10 @@ type ChunkExecutionData struct {
 	Collection *flow.Collection
 	Events     flow.EventsList
 	TrieUpdate *ledger.TrieUpdate
+	// RegisterAccesses are the registers read and written by each transaction of the collection.
+	// They are only included by execution nodes configured to do so, and omitted from the
+	// serialized execution data otherwise, which keeps the execution data ID unchanged.
+	RegisterAccesses []flow.RegisterAccesses `cbor:",omitempty"`
 }
 
 type BlockExecutionDataRoot struct {
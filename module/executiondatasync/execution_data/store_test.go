@@ -17,6 +17,8 @@ import (
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/model/encoding/cbor"
+	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/blobs"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -114,6 +116,48 @@ func TestHappyPath(t *testing.T) {
 	test(5, 5*execution_data.DefaultMaxBlobSize) // large execution data (multi level blob tree)
 }
 
+// TestRegisterAccessesOmitted tests that execution data without register accesses is serialized
+// like execution data which doesn't have them, so that its ID doesn't change.
+func TestRegisterAccessesOmitted(t *testing.T) {
+	t.Parallel()
+
+	ced := generateChunkExecutionData(t, 0)
+
+	legacy := struct {
+		Collection *flow.Collection
+		Events     flow.EventsList
+		TrieUpdate *ledger.TrieUpdate
+	}{
+		Collection: ced.Collection,
+		Events:     ced.Events,
+		TrieUpdate: ced.TrieUpdate,
+	}
+
+	marshaler := cbor.NewMarshaler()
+	encoded, err := marshaler.Marshal(ced)
+	require.NoError(t, err)
+	legacyEncoded, err := marshaler.Marshal(&legacy)
+	require.NoError(t, err)
+	assert.Equal(t, legacyEncoded, encoded)
+
+	// register accesses are kept once included
+	ced.RegisterAccesses = []flow.RegisterAccesses{{
+		TransactionID: unittest.IdentifierFixture(),
+		Reads:         []flow.RegisterAccess{{Register: flow.RegisterID{Owner: "owner", Key: "key"}, Size: 1}},
+	}}
+
+	eds := getExecutionDataStore(getBlobstore(), execution_data.DefaultSerializer)
+	expected := &execution_data.BlockExecutionData{
+		BlockID:             unittest.IdentifierFixture(),
+		ChunkExecutionDatas: []*execution_data.ChunkExecutionData{ced},
+	}
+	rootID, err := eds.AddExecutionData(context.Background(), expected)
+	require.NoError(t, err)
+	actual, err := eds.GetExecutionData(context.Background(), rootID)
+	require.NoError(t, err)
+	assert.Equal(t, ced.RegisterAccesses, actual.ChunkExecutionDatas[0].RegisterAccesses)
+}
+
 type randomSerializer struct{}
 
 func (rs *randomSerializer) Serialize(w io.Writer, v interface{}) error {
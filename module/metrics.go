@@ -439,6 +439,10 @@ type ExecutionMetrics interface {
 	// ExecutionBlockExecuted reports the total time and computation spent on executing a block
 	ExecutionBlockExecuted(dur time.Duration, compUsed uint64, txCounts int, colCounts int)
 
+	// ExecutionBlockContractUsages adds the computation and memory used by the contracts executed in a block
+	// to the usage reported for each contract
+	ExecutionBlockContractUsages(usages []flow.ContractUsage)
+
 	// ExecutionCollectionExecuted reports the total time and computation spent on executing a collection
 	ExecutionCollectionExecuted(dur time.Duration, compUsed uint64, txCounts int)
 
@@ -448,6 +452,15 @@ type ExecutionMetrics interface {
 	// ExecutionScriptExecuted reports the time and memory spent on executing an script
 	ExecutionScriptExecuted(dur time.Duration, compUsed, memoryUsed, memoryEstimate uint64)
 
+	// ExecutionProgramsCacheHit reports a program found in the programs cache
+	ExecutionProgramsCacheHit()
+
+	// ExecutionProgramsCacheMiss reports a program not found in the programs cache, which has to be parsed and checked
+	ExecutionProgramsCacheMiss()
+
+	// ExecutionProgramsCacheEntries reports the number of programs in the programs cache
+	ExecutionProgramsCacheEntries(entries int)
+
 	// ExecutionCollectionRequestSent reports when a request for a collection is sent to a collection node
 	ExecutionCollectionRequestSent()
 
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -67,8 +69,23 @@ type ExecutionCollector struct {
 	blockDataUploadsInProgress       prometheus.Gauge
 	blockDataUploadsDuration         prometheus.Histogram
 	maxCollectionHeight              prometheus.Gauge
+	contractUsagesLock               sync.Mutex
+	reportedContracts                map[string]struct{}
+	contractComputationUsed          *prometheus.CounterVec
+	contractMemoryEstimate           *prometheus.CounterVec
+	programsCacheHits                prometheus.Counter
+	programsCacheMisses              prometheus.Counter
+	programsCacheEntries             prometheus.Gauge
 }
 
+// ContractUsageMetricsLimit is the number of contracts whose computation and memory are reported
+// individually, to bound the metrics cardinality. The usage of other contracts is reported under
+// the OtherContracts label.
+const ContractUsageMetricsLimit = 20
+
+// OtherContracts is the contract label of the usage of contracts not reported individually.
+const OtherContracts = "other"
+
 func NewExecutionCollector(tracer module.Tracer) *ExecutionCollector {
 
 	forestApproxMemorySize := promauto.NewGauge(prometheus.GaugeOpts{
@@ -528,6 +545,43 @@ func NewExecutionCollector(tracer module.Tracer) *ExecutionCollector {
 			Subsystem: subsystemIngestion,
 			Help:      "gauge to track the maximum block height of collections received",
 		}),
+
+		reportedContracts: make(map[string]struct{}, ContractUsageMetricsLimit),
+
+		contractComputationUsed: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "contract_computation_used_total",
+			Help:      "the computation used by executing the code of each contract, contracts not reported individually are labeled as other",
+		}, []string{LabelContract}),
+
+		contractMemoryEstimate: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "contract_memory_estimate_total",
+			Help:      "the estimated memory used by executing the code of each contract, contracts not reported individually are labeled as other",
+		}, []string{LabelContract}),
+
+		programsCacheHits: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "programs_cache_hits_total",
+			Help:      "the number of programs found in the programs cache",
+		}),
+
+		programsCacheMisses: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "programs_cache_misses_total",
+			Help:      "the number of programs not found in the programs cache, which had to be parsed and checked",
+		}),
+
+		programsCacheEntries: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "programs_cache_entries",
+			Help:      "the number of programs in the programs cache",
+		}),
 	}
 
 	return ec
@@ -552,6 +606,57 @@ func (ec *ExecutionCollector) ExecutionBlockExecuted(dur time.Duration, compUsed
 	ec.blockCollectionCounts.Observe(float64(colCounts))
 }
 
+// ExecutionBlockContractUsages adds the computation and memory used by the contracts executed in a block
+// to the usage of each contract. At most ContractUsageMetricsLimit contracts are reported individually:
+// until the limit is reached, the contracts of each block which used the most computation are added to
+// the reported contracts, and are then reported as long as the node runs, so that their counters only
+// increase. The usage of the other contracts is reported under the OtherContracts label.
+func (ec *ExecutionCollector) ExecutionBlockContractUsages(usages []flow.ContractUsage) {
+	type usage struct {
+		computationUsed uint64
+		memoryEstimate  uint64
+	}
+
+	contracts := make([]string, 0)
+	contractUsages := make(map[string]*usage)
+	for _, u := range usages {
+		contract := u.ContractID()
+		total, ok := contractUsages[contract]
+		if !ok {
+			total = &usage{}
+			contractUsages[contract] = total
+			contracts = append(contracts, contract)
+		}
+		total.computationUsed += u.ComputationUsed
+		total.memoryEstimate += u.MemoryEstimate
+	}
+
+	sort.Slice(contracts, func(i, j int) bool {
+		ci, cj := contractUsages[contracts[i]], contractUsages[contracts[j]]
+		if ci.computationUsed != cj.computationUsed {
+			return ci.computationUsed > cj.computationUsed
+		}
+		return contracts[i] < contracts[j]
+	})
+
+	ec.contractUsagesLock.Lock()
+	defer ec.contractUsagesLock.Unlock()
+
+	for _, contract := range contracts {
+		label := contract
+		if _, ok := ec.reportedContracts[contract]; !ok {
+			if len(ec.reportedContracts) < ContractUsageMetricsLimit {
+				ec.reportedContracts[contract] = struct{}{}
+			} else {
+				label = OtherContracts
+			}
+		}
+
+		ec.contractComputationUsed.WithLabelValues(label).Add(float64(contractUsages[contract].computationUsed))
+		ec.contractMemoryEstimate.WithLabelValues(label).Add(float64(contractUsages[contract].memoryEstimate))
+	}
+}
+
 // ExecutionCollectionExecuted reports computation and total time spent on a block computation
 func (ec *ExecutionCollector) ExecutionCollectionExecuted(dur time.Duration, compUsed uint64, txCounts int) {
 	ec.totalExecutedCollectionsCounter.Inc()
@@ -589,6 +694,21 @@ func (ec *ExecutionCollector) ExecutionScriptExecuted(dur time.Duration, compUse
 	ec.scriptMemoryDifference.Observe(float64(memoryEstimated) - float64(memoryUsed))
 }
 
+// ExecutionProgramsCacheHit reports a program found in the programs cache
+func (ec *ExecutionCollector) ExecutionProgramsCacheHit() {
+	ec.programsCacheHits.Inc()
+}
+
+// ExecutionProgramsCacheMiss reports a program not found in the programs cache
+func (ec *ExecutionCollector) ExecutionProgramsCacheMiss() {
+	ec.programsCacheMisses.Inc()
+}
+
+// ExecutionProgramsCacheEntries reports the number of programs in the programs cache
+func (ec *ExecutionCollector) ExecutionProgramsCacheEntries(entries int) {
+	ec.programsCacheEntries.Set(float64(entries))
+}
+
 // ExecutionStateReadsPerBlock reports number of state access/read operations per block
 func (ec *ExecutionCollector) ExecutionStateReadsPerBlock(reads uint64) {
 	ec.stateReadsPerBlock.Observe(float64(reads))
@@ -13,6 +13,7 @@ const (
 	LabelNodeInfo    = "nodeinfo"
 	LabelNodeVersion = "nodeversion"
 	LabelPriority    = "priority"
+	LabelContract    = "contract"
 )
 
 const (
@@ -133,10 +133,14 @@ func (nc *NoopCollector) ExecutionStateReadsPerBlock(reads uint64)
 func (nc *NoopCollector) ExecutionStorageStateCommitment(bytes int64)                          {}
 func (nc *NoopCollector) ExecutionLastExecutedBlockHeight(height uint64)                       {}
 func (nc *NoopCollector) ExecutionBlockExecuted(_ time.Duration, _ uint64, _ int, _ int)       {}
+func (nc *NoopCollector) ExecutionBlockContractUsages(_ []flow.ContractUsage)                  {}
 func (nc *NoopCollector) ExecutionCollectionExecuted(_ time.Duration, _ uint64, _ int)         {}
 func (nc *NoopCollector) ExecutionTransactionExecuted(_ time.Duration, _, _, _ uint64, _ int, _ bool) {
 }
 func (nc *NoopCollector) ExecutionScriptExecuted(dur time.Duration, compUsed, _, _ uint64) {}
+func (nc *NoopCollector) ExecutionProgramsCacheHit()                                       {}
+func (nc *NoopCollector) ExecutionProgramsCacheMiss()                                      {}
+func (nc *NoopCollector) ExecutionProgramsCacheEntries(_ int)                              {}
 func (nc *NoopCollector) ForestApproxMemorySize(bytes uint64)                              {}
 func (nc *NoopCollector) ForestNumberOfTrees(number uint64)                                {}
 func (nc *NoopCollector) LatestTrieRegCount(number uint64)                                 {}
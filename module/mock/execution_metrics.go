@@ -34,6 +34,11 @@ func (_m *ExecutionMetrics) ExecutionBlockExecuted(dur time.Duration, compUsed u
 	_m.Called(dur, compUsed, txCounts, colCounts)
 }
 
+// ExecutionBlockContractUsages provides a mock function with given fields: usages
+func (_m *ExecutionMetrics) ExecutionBlockContractUsages(usages []flow.ContractUsage) {
+	_m.Called(usages)
+}
+
 // ExecutionCollectionExecuted provides a mock function with given fields: dur, compUsed, txCounts
 func (_m *ExecutionMetrics) ExecutionCollectionExecuted(dur time.Duration, compUsed uint64, txCounts int) {
 	_m.Called(dur, compUsed, txCounts)
@@ -54,6 +59,21 @@ func (_m *ExecutionMetrics) ExecutionLastExecutedBlockHeight(height uint64) {
 	_m.Called(height)
 }
 
+// ExecutionProgramsCacheEntries provides a mock function with given fields: entries
+func (_m *ExecutionMetrics) ExecutionProgramsCacheEntries(entries int) {
+	_m.Called(entries)
+}
+
+// ExecutionProgramsCacheHit provides a mock function with given fields:
+func (_m *ExecutionMetrics) ExecutionProgramsCacheHit() {
+	_m.Called()
+}
+
+// ExecutionProgramsCacheMiss provides a mock function with given fields:
+func (_m *ExecutionMetrics) ExecutionProgramsCacheMiss() {
+	_m.Called()
+}
+
 // ExecutionScriptExecuted provides a mock function with given fields: dur, compUsed, memoryUsed, memoryEstimate
 func (_m *ExecutionMetrics) ExecutionScriptExecuted(dur time.Duration, compUsed uint64, memoryUsed uint64, memoryEstimate uint64) {
 	_m.Called(dur, compUsed, memoryUsed, memoryEstimate)
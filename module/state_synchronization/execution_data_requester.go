@@ -6,8 +6,9 @@ import (
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 )
 
-// ExecutionDataReceivedCallback is a callback that is called ExecutionData is received for a new block
-type ExecutionDataReceivedCallback func(*execution_data.BlockExecutionData)
+// ExecutionDataReceivedCallback is a callback that is called ExecutionData is received for a new block.
+// If it returns an error, it is called again with the same ExecutionData until it succeeds.
+type ExecutionDataReceivedCallback func(*execution_data.BlockExecutionData) error
 
 // ExecutionDataRequester is a component that syncs ExecutionData from the network, and exposes
 // a callback that is called when a new ExecutionData is received
@@ -0,0 +1,88 @@
+package indexer
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+// bootstrapBatchSize is the number of registers stored in one write batch while bootstrapping.
+const bootstrapBatchSize = 1000
+
+// BootstrapRegisters initializes an empty register index with the full execution state from the
+// given checkpoint file. The checkpoint (version 6 or later) must only contain the trie with the
+// state commitment of the block at the given height, which becomes the first indexed height.
+// The registers are stored in batches as they are read from the checkpoint, without loading the trie.
+func BootstrapRegisters(
+	log zerolog.Logger,
+	db *badger.DB,
+	checkpointFile string,
+	height uint64,
+	commit flow.StateCommitment,
+) error {
+	log.Info().
+		Str("checkpoint", checkpointFile).
+		Uint64("height", height).
+		Hex("commit", commit[:]).
+		Msg("bootstrapping register index from checkpoint")
+
+	bootstrap, err := badgerstorage.NewRegistersBootstrap(db, height)
+	if err != nil {
+		return fmt.Errorf("could not bootstrap registers: %w", err)
+	}
+
+	count := 0
+	entries := make(flow.RegisterEntries, 0, bootstrapBatchSize)
+
+	storeEntries := func() error {
+		err := bootstrap.Store(entries)
+		if err != nil {
+			return fmt.Errorf("could not store registers: %w", err)
+		}
+		count += len(entries)
+		entries = entries[:0]
+		return nil
+	}
+
+	err = wal.ReadCheckpointV6Payloads(checkpointFile, ledger.RootHash(commit), &log, func(payload *ledger.Payload) error {
+		key, err := payload.Key()
+		if err != nil {
+			return fmt.Errorf("could not decode payload key: %w", err)
+		}
+
+		id, err := state.KeyToRegisterID(key)
+		if err != nil {
+			return fmt.Errorf("could not convert key to register ID: %w", err)
+		}
+
+		entries = append(entries, flow.RegisterEntry{Key: id, Value: flow.RegisterValue(payload.Value())})
+		if len(entries) < bootstrapBatchSize {
+			return nil
+		}
+		return storeEntries()
+	})
+	if err != nil {
+		return fmt.Errorf("could not read registers from checkpoint: %w", err)
+	}
+
+	err = storeEntries()
+	if err != nil {
+		return err
+	}
+
+	err = bootstrap.Finish()
+	if err != nil {
+		return fmt.Errorf("could not finish bootstrapping registers: %w", err)
+	}
+
+	log.Info().Int("registers", count).Msg("register index bootstrapped")
+
+	return nil
+}
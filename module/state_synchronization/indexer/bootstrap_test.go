@@ -0,0 +1,83 @@
+package indexer
+
+import (
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+// TestBootstrapRegisters tests that the register index is bootstrapped with all registers of the
+// checkpoint, stored in several batches
+func TestBootstrapRegisters(t *testing.T) {
+	owner := string(unittest.RandomAddressFixture().Bytes())
+
+	count := 2*bootstrapBatchSize + 1
+	expected := make(map[flow.RegisterID]string, count)
+	paths := make([]ledger.Path, 0, count)
+	payloads := make([]ledger.Payload, 0, count)
+	for i := 0; i < count; i++ {
+		id := flow.NewRegisterID(owner, fmt.Sprintf("key%d", i))
+		value := fmt.Sprintf("value%d", i)
+		expected[id] = value
+
+		key := state.RegisterIDToKey(id)
+		p, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+		require.NoError(t, err)
+		paths = append(paths, p)
+		payloads = append(payloads, *ledger.NewPayload(key, ledger.Value(value)))
+	}
+
+	tr, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, payloads, true)
+	require.NoError(t, err)
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := zerolog.Nop()
+		err := wal.StoreCheckpointV6([]*trie.MTrie{tr}, dir, "checkpoint.00000001", &logger)
+		require.NoError(t, err)
+		checkpointFile := path.Join(dir, "checkpoint.00000001")
+
+		t.Run("other state commitment", func(t *testing.T) {
+			unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+				err := BootstrapRegisters(logger, db, checkpointFile, 10, unittest.StateCommitmentFixture())
+				require.Error(t, err)
+
+				bootstrapped, err := badgerstorage.IsRegistersBootstrapped(db)
+				require.NoError(t, err)
+				assert.False(t, bootstrapped)
+			})
+		})
+
+		t.Run("state commitment of trie", func(t *testing.T) {
+			unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+				err := BootstrapRegisters(logger, db, checkpointFile, 10, flow.StateCommitment(tr.RootHash()))
+				require.NoError(t, err)
+
+				registers, err := badgerstorage.NewRegisters(db)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(10), registers.FirstHeight())
+
+				for id, value := range expected {
+					actual, err := registers.Get(id, 10)
+					require.NoError(t, err)
+					assert.Equal(t, value, string(actual))
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// EventIndexer stores the events included in the execution data of each sealed block, and indexes
+// them by event type, so that they can be queried over height ranges without contacting execution
+// nodes.
+type EventIndexer struct {
+	log     zerolog.Logger
+	db      *badger.DB
+	headers storage.Headers
+	events  storage.Events
+}
+
+// NewEventIndexer creates a new EventIndexer.
+func NewEventIndexer(log zerolog.Logger, db *badger.DB, headers storage.Headers, events storage.Events) *EventIndexer {
+	return &EventIndexer{
+		log:     log.With().Str("module", "event_indexer").Logger(),
+		db:      db,
+		headers: headers,
+		events:  events,
+	}
+}
+
+// OnExecutionData stores and indexes the events of the block. It is registered as a consumer of the
+// ExecutionDataRequester, which delivers execution data in height order.
+func (i *EventIndexer) OnExecutionData(executionData *execution_data.BlockExecutionData) error {
+	header, err := i.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		// if the block is not found here, the execution data requester processed a block that
+		// the node does not know about, which indicates a corrupted state.
+		i.log.Fatal().Err(err).Str("block_id", executionData.BlockID.String()).Msg("failed to get header for execution data")
+		return nil
+	}
+
+	var events []flow.Event
+	for _, chunkExecutionData := range executionData.ChunkExecutionDatas {
+		events = append(events, chunkExecutionData.Events...)
+	}
+
+	err = i.IndexEvents(header, events)
+	if err != nil {
+		// skipping a block would leave a gap in the index, which can not be recovered from
+		i.log.Fatal().Err(err).Str("block_id", executionData.BlockID.String()).Msg("failed to index events")
+		return nil
+	}
+
+	return nil
+}
+
+// IndexEvents stores the events of the block and indexes them by event type.
+func (i *EventIndexer) IndexEvents(header *flow.Header, events []flow.Event) error {
+	blockID := header.ID()
+	batch := bstorage.NewBatch(i.db)
+
+	err := i.events.BatchStore(blockID, []flow.EventsList{events}, batch)
+	if err != nil {
+		return fmt.Errorf("could not store events: %w", err)
+	}
+
+	err = i.events.BatchIndexByEventType(blockID, header.Height, events, batch)
+	if err != nil {
+		return fmt.Errorf("could not index events: %w", err)
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not commit events: %w", err)
+	}
+
+	i.log.Debug().
+		Hex("block_id", logging.ID(blockID)).
+		Uint64("height", header.Height).
+		Int("events", len(events)).
+		Msg("indexed events")
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestIndexEvents tests that indexed events can be retrieved by block ID and by event type
+func TestIndexEvents(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		events := bstorage.NewEvents(metrics.NewNoopCollector(), db)
+		indexer := NewEventIndexer(zerolog.Nop(), db, nil, events)
+
+		header := unittest.BlockHeaderFixture()
+		txID := unittest.IdentifierFixture()
+		blockEvents := []flow.Event{
+			unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0),
+			unittest.EventFixture(flow.EventAccountUpdated, 0, 1, txID, 0),
+		}
+
+		err := indexer.IndexEvents(header, blockEvents)
+		require.NoError(t, err)
+
+		stored, err := events.ByBlockID(header.ID())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, blockEvents, stored)
+
+		blockIDs, err := events.BlockIDsByEventType(flow.EventAccountUpdated, 0, header.Height, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Identifier{header.ID()}, blockIDs)
+
+		first, latest, err := events.EventTypeIndexHeights()
+		require.NoError(t, err)
+		assert.Equal(t, header.Height, first)
+		assert.Equal(t, header.Height, latest)
+	})
+}
@@ -0,0 +1,213 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/consensus/sealing/counters"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// Indexer builds the register index from the trie updates included in the execution data of each
+// sealed block. Heights are indexed in order, as soon as their execution data is available.
+//
+// The indexer reads execution data from the local execution data store, so it catches up on any
+// heights downloaded while it was not running, e.g. after a restart.
+type Indexer struct {
+	component.Component
+
+	log           zerolog.Logger
+	registers     storage.Registers
+	headers       storage.Headers
+	seals         storage.Seals
+	results       storage.ExecutionResults
+	execDataStore execution_data.ExecutionDataStore
+
+	// highestAvailableHeight is the highest height for which execution data was downloaded
+	highestAvailableHeight counters.StrictMonotonousCounter
+	notifier               engine.Notifier
+}
+
+// New creates a new Indexer. highestAvailableHeight is the highest height for which execution data
+// was already downloaded when the node started.
+func New(
+	log zerolog.Logger,
+	registers storage.Registers,
+	headers storage.Headers,
+	seals storage.Seals,
+	results storage.ExecutionResults,
+	execDataStore execution_data.ExecutionDataStore,
+	highestAvailableHeight uint64,
+) *Indexer {
+	i := &Indexer{
+		log:                    log.With().Str("module", "register_indexer").Logger(),
+		registers:              registers,
+		headers:                headers,
+		seals:                  seals,
+		results:                results,
+		execDataStore:          execDataStore,
+		highestAvailableHeight: counters.NewMonotonousCounter(highestAvailableHeight),
+		notifier:               engine.NewNotifier(),
+	}
+
+	i.Component = component.NewComponentManagerBuilder().
+		AddWorker(i.loop).
+		Build()
+
+	// index any heights that were downloaded but not yet indexed when the node stopped
+	i.notifier.Notify()
+
+	return i
+}
+
+// OnExecutionData is called to notify the indexer that new execution data has been received.
+// It is registered as a consumer of the ExecutionDataRequester, which delivers execution data in
+// height order.
+func (i *Indexer) OnExecutionData(executionData *execution_data.BlockExecutionData) error {
+	header, err := i.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		// if the block is not found here, the execution data requester processed a block that
+		// the node does not know about, which indicates a corrupted state.
+		i.log.Fatal().Err(err).Str("block_id", executionData.BlockID.String()).Msg("failed to get header for execution data")
+		return nil
+	}
+
+	_ = i.highestAvailableHeight.Set(header.Height)
+	i.notifier.Notify()
+
+	return nil
+}
+
+func (i *Indexer) loop(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	ready()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.notifier.Channel():
+			err := i.indexAvailable(ctx)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				ctx.Throw(err)
+			}
+		}
+	}
+}
+
+// indexAvailable indexes all heights for which execution data is available, starting from the
+// height after the latest indexed height.
+func (i *Indexer) indexAvailable(ctx context.Context) error {
+	highestAvailableHeight := i.highestAvailableHeight.Value()
+
+	for height := i.registers.LatestHeight() + 1; height <= highestAvailableHeight; height++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		err := i.indexHeight(ctx, height)
+		if err != nil {
+			return fmt.Errorf("could not index height %d: %w", height, err)
+		}
+	}
+
+	return nil
+}
+
+// indexHeight stores the register values updated by the block at the given height.
+func (i *Indexer) indexHeight(ctx context.Context, height uint64) error {
+	header, err := i.headers.ByHeight(height)
+	if err != nil {
+		return fmt.Errorf("could not get header: %w", err)
+	}
+
+	seal, err := i.seals.FinalizedSealForBlock(header.ID())
+	if err != nil {
+		return fmt.Errorf("could not get finalized seal for block %s: %w", header.ID(), err)
+	}
+
+	result, err := i.results.ByID(seal.ResultID)
+	if err != nil {
+		return fmt.Errorf("could not get execution result (id: %s): %w", seal.ResultID, err)
+	}
+
+	executionData, err := i.execDataStore.GetExecutionData(ctx, result.ExecutionDataID)
+	if err != nil {
+		return fmt.Errorf("could not get execution data (id: %s): %w", result.ExecutionDataID, err)
+	}
+
+	updates := make([]*ledger.TrieUpdate, 0, len(executionData.ChunkExecutionDatas))
+	for _, chunkExecutionData := range executionData.ChunkExecutionDatas {
+		updates = append(updates, chunkExecutionData.TrieUpdate)
+	}
+
+	entries, err := RegisterEntriesFromTrieUpdates(updates)
+	if err != nil {
+		return fmt.Errorf("could not get register entries: %w", err)
+	}
+
+	err = i.registers.Store(entries, height)
+	if err != nil {
+		return fmt.Errorf("could not store registers: %w", err)
+	}
+
+	i.log.Debug().
+		Hex("block_id", logging.ID(header.ID())).
+		Uint64("height", height).
+		Int("registers", len(entries)).
+		Msg("indexed registers")
+
+	return nil
+}
+
+// RegisterEntriesFromTrieUpdates returns the register values written by the given trie updates,
+// which must be ordered by execution. If a register is updated more than once, only the last value
+// is returned. Nil trie updates are skipped.
+func RegisterEntriesFromTrieUpdates(updates []*ledger.TrieUpdate) (flow.RegisterEntries, error) {
+	indices := make(map[flow.RegisterID]int)
+	entries := make(flow.RegisterEntries, 0)
+
+	for _, update := range updates {
+		if update == nil {
+			continue
+		}
+
+		for _, payload := range update.Payloads {
+			key, err := payload.Key()
+			if err != nil {
+				return nil, fmt.Errorf("could not decode payload key: %w", err)
+			}
+
+			id, err := state.KeyToRegisterID(key)
+			if err != nil {
+				return nil, fmt.Errorf("could not convert key to register ID: %w", err)
+			}
+
+			entry := flow.RegisterEntry{Key: id, Value: flow.RegisterValue(payload.Value())}
+			if index, ok := indices[id]; ok {
+				entries[index] = entry
+				continue
+			}
+
+			indices[id] = len(entries)
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,52 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func payloadFixture(id flow.RegisterID, value string) *ledger.Payload {
+	return ledger.NewPayload(state.RegisterIDToKey(id), ledger.Value(value))
+}
+
+// TestRegisterEntriesFromTrieUpdates tests that the register entries of a block contain the last
+// value written to each register, in the order the registers were first written
+func TestRegisterEntriesFromTrieUpdates(t *testing.T) {
+	owner := string(unittest.RandomAddressFixture().Bytes())
+	id1 := flow.NewRegisterID(owner, "key1")
+	id2 := flow.NewRegisterID(owner, "key2")
+	id3 := flow.NewRegisterID("", "key3")
+
+	updates := []*ledger.TrieUpdate{
+		{Payloads: []*ledger.Payload{payloadFixture(id1, "a"), payloadFixture(id2, "b")}},
+		nil, // chunks without updates
+		{Payloads: []*ledger.Payload{payloadFixture(id3, "c"), payloadFixture(id1, "d")}},
+	}
+
+	entries, err := RegisterEntriesFromTrieUpdates(updates)
+	require.NoError(t, err)
+
+	assert.Equal(t, flow.RegisterEntries{
+		{Key: id1, Value: []byte("d")},
+		{Key: id2, Value: []byte("b")},
+		{Key: id3, Value: []byte("c")},
+	}, entries)
+}
+
+// TestRegisterEntriesFromTrieUpdatesInvalidKey tests that keys which are not register keys are rejected
+func TestRegisterEntriesFromTrieUpdatesInvalidKey(t *testing.T) {
+	key := ledger.NewKey([]ledger.KeyPart{ledger.NewKeyPart(state.KeyPartOwner, []byte("owner"))})
+	updates := []*ledger.TrieUpdate{
+		{Payloads: []*ledger.Payload{ledger.NewPayload(key, ledger.Value("a"))}},
+	}
+
+	_, err := RegisterEntriesFromTrieUpdates(updates)
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package indexer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+const (
+	DefaultPruningHeightRangeTarget = uint64(400_000)
+	DefaultPruningThreshold         = uint64(100_000)
+
+	defaultPruningCheckInterval = time.Minute
+)
+
+// Pruner is a component responsible for pruning the register index. It is configured with the
+// following parameters:
+//   - Height range target: The target number of most recent heights to keep register values for.
+//     Scripts can only be executed at heights within this range.
+//   - Threshold: The number of heights that the indexed range can exceed the height range target
+//     by before pruning is triggered. This controls the frequency of pruning, as each pruning
+//     iterates over all indexed register values.
+//
+// The Pruner periodically checks the indexed range, and prunes the register index so that the
+// height range target is kept once the indexed range reaches the height range target + threshold.
+type Pruner struct {
+	component.Component
+
+	log       zerolog.Logger
+	registers storage.Registers
+
+	heightRangeTarget uint64
+	threshold         uint64
+	checkInterval     time.Duration
+}
+
+type PrunerOption func(*Pruner)
+
+// WithPruningHeightRangeTarget is used to configure the pruner with a custom height range target.
+func WithPruningHeightRangeTarget(heightRangeTarget uint64) PrunerOption {
+	return func(p *Pruner) {
+		p.heightRangeTarget = heightRangeTarget
+	}
+}
+
+// WithPruningThreshold is used to configure the pruner with a custom threshold.
+func WithPruningThreshold(threshold uint64) PrunerOption {
+	return func(p *Pruner) {
+		p.threshold = threshold
+	}
+}
+
+// WithPruningCheckInterval is used to configure how often the pruner checks the indexed range.
+func WithPruningCheckInterval(interval time.Duration) PrunerOption {
+	return func(p *Pruner) {
+		p.checkInterval = interval
+	}
+}
+
+// NewPruner creates a new Pruner of the given register index.
+func NewPruner(log zerolog.Logger, registers storage.Registers, opts ...PrunerOption) *Pruner {
+	p := &Pruner{
+		log:               log.With().Str("module", "register_pruner").Logger(),
+		registers:         registers,
+		heightRangeTarget: DefaultPruningHeightRangeTarget,
+		threshold:         DefaultPruningThreshold,
+		checkInterval:     defaultPruningCheckInterval,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.Component = component.NewComponentManagerBuilder().
+		AddWorker(p.loop).
+		Build()
+
+	return p
+}
+
+func (p *Pruner) loop(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	ready()
+
+	ticker := time.NewTicker(p.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		// check once on startup, in case the node was stopped for a while
+		err := p.checkPrune()
+		if err != nil {
+			ctx.Throw(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkPrune prunes the register index if the indexed range exceeds the height range target by
+// more than the threshold.
+func (p *Pruner) checkPrune() error {
+	firstHeight := p.registers.FirstHeight()
+	latestHeight := p.registers.LatestHeight()
+
+	if latestHeight <= p.heightRangeTarget+p.threshold+firstHeight {
+		return nil
+	}
+
+	pruneHeight := latestHeight - p.heightRangeTarget
+
+	p.log.Info().Uint64("prune_height", pruneHeight).Msg("pruning register index")
+	start := time.Now()
+
+	err := p.registers.Prune(pruneHeight)
+	if err != nil {
+		return fmt.Errorf("failed to prune register index to height %d: %w", pruneHeight, err)
+	}
+
+	p.log.Info().
+		Uint64("prune_height", pruneHeight).
+		Dur("duration", time.Since(start)).
+		Msg("pruned register index")
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package indexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	storagemock "github.com/onflow/flow-go/storage/mock"
+)
+
+// TestPrunerCheckPrune tests that the register index is only pruned once the indexed range exceeds
+// the height range target by more than the threshold, and that the height range target is kept
+func TestPrunerCheckPrune(t *testing.T) {
+	newPruner := func(registers *storagemock.Registers) *Pruner {
+		return NewPruner(zerolog.Nop(), registers,
+			WithPruningHeightRangeTarget(100),
+			WithPruningThreshold(10),
+		)
+	}
+
+	t.Run("within threshold", func(t *testing.T) {
+		registers := storagemock.NewRegisters(t)
+		registers.On("FirstHeight").Return(uint64(5))
+		registers.On("LatestHeight").Return(uint64(115))
+
+		err := newPruner(registers).checkPrune()
+		require.NoError(t, err)
+	})
+
+	t.Run("above threshold", func(t *testing.T) {
+		registers := storagemock.NewRegisters(t)
+		registers.On("FirstHeight").Return(uint64(5))
+		registers.On("LatestHeight").Return(uint64(116))
+		registers.On("Prune", uint64(16)).Return(nil).Once()
+
+		err := newPruner(registers).checkPrune()
+		require.NoError(t, err)
+	})
+
+	t.Run("pruning fails", func(t *testing.T) {
+		registers := storagemock.NewRegisters(t)
+		registers.On("FirstHeight").Return(uint64(0))
+		registers.On("LatestHeight").Return(uint64(200))
+		registers.On("Prune", uint64(100)).Return(fmt.Errorf("prune error")).Once()
+
+		err := newPruner(registers).checkPrune()
+		require.Error(t, err)
+	})
+}
@@ -257,6 +257,8 @@ func (e *executionDataRequester) OnBlockFinalized(*model.Block) {
 //   * be concurrency safe
 //   * be non-blocking
 //   * handle repetition of the same events (with some processing overhead).
+// Callbacks returning an error are retried using exponential backoff, and the following
+// ExecutionData is not delivered to any callback until they succeed.
 func (e *executionDataRequester) AddOnExecutionDataFetchedConsumer(fn state_synchronization.ExecutionDataReceivedCallback) {
 	e.consumerMu.Lock()
 	defer e.consumerMu.Unlock()
@@ -443,26 +445,56 @@ func (e *executionDataRequester) processNotificationJob(ctx irrecoverable.Signal
 		ctx.Throw(fmt.Errorf("failed to convert job to entry: %w", err))
 	}
 
-	e.processNotification(ctx, entry.Height, entry.ExecutionData)
+	err = e.processNotification(ctx, entry.Height, entry.ExecutionData)
+	if err != nil {
+		// the node is shutting down, the job is not completed so that it is processed after restarting
+		return
+	}
 	jobComplete()
 }
 
-func (e *executionDataRequester) processNotification(ctx irrecoverable.SignalerContext, height uint64, executionData *execution_data.BlockExecutionData) {
+func (e *executionDataRequester) processNotification(ctx irrecoverable.SignalerContext, height uint64, executionData *execution_data.BlockExecutionData) error {
 	e.log.Debug().Msgf("notifying for block %d", height)
 
 	// send notifications
-	e.notifyConsumers(executionData)
+	err := e.notifyConsumers(ctx, height, executionData)
+	if err != nil {
+		return err
+	}
 
 	e.metrics.NotificationSent(height)
+	return nil
 }
 
-func (e *executionDataRequester) notifyConsumers(executionData *execution_data.BlockExecutionData) {
+// notifyConsumers calls all consumers with the given ExecutionData. Consumers returning an error
+// are retried forever, using exponential backoff, so an error is only returned if the context is
+// canceled.
+func (e *executionDataRequester) notifyConsumers(ctx irrecoverable.SignalerContext, height uint64, executionData *execution_data.BlockExecutionData) error {
 	e.consumerMu.RLock()
 	defer e.consumerMu.RUnlock()
 
 	for _, fn := range e.consumers {
-		fn(executionData)
+		backoff := retry.NewExponential(e.config.RetryDelay)
+		backoff = retry.WithCappedDuration(e.config.MaxRetryDelay, backoff)
+		backoff = retry.WithJitterPercent(15, backoff)
+
+		err := retry.Do(ctx, backoff, func(context.Context) error {
+			err := fn(executionData)
+			if err != nil {
+				e.log.Warn().Err(err).
+					Str("block_id", executionData.BlockID.String()).
+					Uint64("height", height).
+					Msg("failed to notify consumer of execution data, retrying")
+				return retry.RetryableError(err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 func isInvalidBlobError(err error) bool {
@@ -236,6 +236,39 @@ func (suite *ExecutionDataRequesterSuite) TestRequesterResumesAfterRestart() {
 	})
 }
 
+// TestRequesterRetriesFailedConsumers tests that a consumer returning an error is called again with
+// the same execution data, and that later execution data is not delivered before it succeeds.
+func (suite *ExecutionDataRequesterSuite) TestRequesterRetriesFailedConsumers() {
+	unittest.RunWithBadgerDB(suite.T(), func(db *badger.DB) {
+		suite.db = db
+
+		suite.datastore = dssync.MutexWrap(datastore.NewMapDatastore())
+		suite.blobstore = blobs.NewBlobstore(suite.datastore)
+
+		testData := suite.generateTestData(suite.run.blockCount, suite.run.specialBlocks(suite.run.blockCount))
+		edr, fd := suite.prepareRequesterTest(testData)
+
+		// fails the first notification of each block
+		calls := make(map[flow.Identifier]int)
+		edr.AddOnExecutionDataFetchedConsumer(func(ed *execution_data.BlockExecutionData) error {
+			calls[ed.BlockID]++
+			if calls[ed.BlockID] == 1 {
+				return fmt.Errorf("consumer failed")
+			}
+			return nil
+		})
+
+		fetchedExecutionData := suite.runRequesterTest(edr, fd, testData)
+
+		verifyFetchedExecutionData(suite.T(), fetchedExecutionData, testData)
+		for blockID := range fetchedExecutionData {
+			suite.Assert().Equal(2, calls[blockID])
+		}
+
+		suite.T().Log("Shutting down test")
+	})
+}
+
 // TestRequesterCatchesUp tests that the requester processes all heights when it starts with a
 // backlog of sealed blocks.
 func (suite *ExecutionDataRequesterSuite) TestRequesterCatchesUp() {
@@ -527,11 +560,11 @@ func (suite *ExecutionDataRequesterSuite) runRequesterTest(edr state_synchroniza
 	return fetchedExecutionData
 }
 
-func (suite *ExecutionDataRequesterSuite) consumeExecutionDataNotifications(cfg *fetchTestRun, done func(), fetchedExecutionData map[flow.Identifier]*execution_data.BlockExecutionData) func(ed *execution_data.BlockExecutionData) {
-	return func(ed *execution_data.BlockExecutionData) {
+func (suite *ExecutionDataRequesterSuite) consumeExecutionDataNotifications(cfg *fetchTestRun, done func(), fetchedExecutionData map[flow.Identifier]*execution_data.BlockExecutionData) func(ed *execution_data.BlockExecutionData) error {
+	return func(ed *execution_data.BlockExecutionData) error {
 		if _, has := fetchedExecutionData[ed.BlockID]; has {
 			suite.T().Errorf("duplicate execution data for block %s", ed.BlockID)
-			return
+			return nil
 		}
 
 		fetchedExecutionData[ed.BlockID] = ed
@@ -540,6 +573,8 @@ func (suite *ExecutionDataRequesterSuite) consumeExecutionDataNotifications(cfg
 		if cfg.IsLastSeal(ed.BlockID) {
 			done()
 		}
+
+		return nil
 	}
 }
 
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// AccountTransactions represents persistent storage for the index of the transactions each account
+// was involved in.
+type AccountTransactions interface {
+	// Store indexes the given entries. The roles of entries for an account and transaction that
+	// was already indexed are added to the indexed roles.
+	Store(entries []flow.AccountTransaction) error
+
+	// ByAddress returns up to limit transactions the account was involved in between the start
+	// and end heights (inclusive), ordered by block height and transaction ID. If the cursor is not
+	// nil, only the transactions after the cursor are returned.
+	ByAddress(
+		address flow.Address,
+		startHeight uint64,
+		endHeight uint64,
+		cursor *flow.AccountTransactionCursor,
+		limit uint,
+	) ([]flow.AccountTransaction, error)
+}
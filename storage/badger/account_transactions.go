@@ -0,0 +1,63 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// AccountTransactions implements storage.AccountTransactions on top of a badger database.
+type AccountTransactions struct {
+	db *badger.DB
+}
+
+var _ storage.AccountTransactions = (*AccountTransactions)(nil)
+
+func NewAccountTransactions(db *badger.DB) *AccountTransactions {
+	return &AccountTransactions{
+		db: db,
+	}
+}
+
+// Store indexes the given entries. The roles of entries for an account and transaction that was
+// already indexed are added to the indexed roles.
+func (a *AccountTransactions) Store(entries []flow.AccountTransaction) error {
+	batch := a.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	for _, entry := range entries {
+		err := operation.BatchIndexAccountTransaction(entry)(batch)
+		if err != nil {
+			return fmt.Errorf("could not index transaction %s for account %s: %w", entry.TransactionID, entry.Address, err)
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush account transactions: %w", err)
+	}
+
+	return nil
+}
+
+// ByAddress returns up to limit transactions the account was involved in between the start and end
+// heights (inclusive), ordered by block height and transaction ID, and starting after the cursor
+// if it is not nil.
+func (a *AccountTransactions) ByAddress(
+	address flow.Address,
+	startHeight uint64,
+	endHeight uint64,
+	cursor *flow.AccountTransactionCursor,
+	limit uint,
+) ([]flow.AccountTransaction, error) {
+	var entries []flow.AccountTransaction
+	err := a.db.View(operation.LookupAccountTransactions(address, startHeight, endHeight, cursor, limit, &entries))
+	if err != nil {
+		return nil, fmt.Errorf("could not lookup transactions for account %s: %w", address, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,78 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+func TestAccountTransactionsStoreAndLookup(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		store := badgerstorage.NewAccountTransactions(db)
+
+		address := unittest.RandomAddressFixture()
+		other := unittest.RandomAddressFixture()
+
+		tx10 := unittest.IdentifierFixture()
+		tx11 := unittest.IdentifierFixture()
+		tx12 := unittest.IdentifierFixture()
+
+		err := store.Store([]flow.AccountTransaction{
+			{Address: address, BlockHeight: 10, TransactionID: tx10, Roles: flow.AccountTransactionRolePayer | flow.AccountTransactionRoleProposer},
+			{Address: address, BlockHeight: 11, TransactionID: tx11, Roles: flow.AccountTransactionRoleAuthorizer},
+			{Address: address, BlockHeight: 12, TransactionID: tx12, Roles: flow.AccountTransactionRolePayer},
+			{Address: other, BlockHeight: 11, TransactionID: tx11, Roles: flow.AccountTransactionRolePayer},
+		})
+		require.NoError(t, err)
+
+		// roles are added to the indexed roles
+		err = store.Store([]flow.AccountTransaction{
+			{Address: address, BlockHeight: 11, TransactionID: tx11, Roles: flow.AccountTransactionRoleEventEmitter},
+		})
+		require.NoError(t, err)
+
+		entries, err := store.ByAddress(address, 0, 100, nil, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, tx10, entries[0].TransactionID)
+		assert.Equal(t, flow.AccountTransactionRolePayer|flow.AccountTransactionRoleProposer, entries[0].Roles)
+		assert.Equal(t, tx11, entries[1].TransactionID)
+		assert.Equal(t, flow.AccountTransactionRoleAuthorizer|flow.AccountTransactionRoleEventEmitter, entries[1].Roles)
+		assert.Equal(t, uint64(12), entries[2].BlockHeight)
+
+		// height range
+		entries, err = store.ByAddress(address, 11, 11, nil, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, tx11, entries[0].TransactionID)
+
+		// pagination
+		entries, err = store.ByAddress(address, 0, 100, nil, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		cursor := entries[1].Cursor()
+		entries, err = store.ByAddress(address, 0, 100, &cursor, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, tx12, entries[0].TransactionID)
+
+		cursor = entries[0].Cursor()
+		entries, err = store.ByAddress(address, 0, 100, &cursor, 2)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		// other accounts are not included
+		entries, err = store.ByAddress(other, 0, 100, nil, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, other, entries[0].Address)
+	})
+}
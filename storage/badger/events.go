@@ -1,6 +1,7 @@
 package badger
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/dgraph-io/badger/v2"
@@ -124,6 +125,72 @@ func (e *Events) ByBlockIDEventType(blockID flow.Identifier, eventType flow.Even
 	return matched, nil
 }
 
+// BatchIndexByEventType indexes the events of the block at the given height by event type in a given batch.
+// Blocks must be indexed in height order, the first indexed block can be at any height. Indexing a height
+// again is allowed, so that indexing can be resumed after a crash.
+func (e *Events) BatchIndexByEventType(blockID flow.Identifier, height uint64, events []flow.Event, batch storage.BatchStorage) error {
+	writeBatch := batch.GetWriter()
+
+	first, latest, err := e.EventTypeIndexHeights()
+	if errors.Is(err, storage.ErrNotFound) {
+		err = operation.BatchInsertEventTypeFirstHeight(height)(writeBatch)
+		if err != nil {
+			return fmt.Errorf("cannot batch insert first indexed height: %w", err)
+		}
+		first, latest = height, height
+	} else if err != nil {
+		return err
+	}
+
+	if height < first || height > latest+1 {
+		return fmt.Errorf("height %d is not the next height to index (first: %d, latest: %d)", height, first, latest)
+	}
+
+	for _, event := range events {
+		err := operation.BatchIndexEventType(height, blockID, event)(writeBatch)
+		if err != nil {
+			return fmt.Errorf("cannot batch index event: %w", err)
+		}
+	}
+
+	if height >= latest {
+		err = operation.BatchUpdateEventTypeLatestHeight(height)(writeBatch)
+		if err != nil {
+			return fmt.Errorf("cannot batch update latest indexed height: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// BlockIDsByEventType returns the IDs of up to limit blocks between the start and end heights (inclusive)
+// that contain events of the given type, ordered by height.
+func (e *Events) BlockIDsByEventType(eventType flow.EventType, startHeight uint64, endHeight uint64, limit uint) ([]flow.Identifier, error) {
+	var blockIDs []flow.Identifier
+	err := e.db.View(operation.LookupBlockIDsByEventType(eventType, startHeight, endHeight, limit, &blockIDs))
+	if err != nil {
+		return nil, fmt.Errorf("could not lookup blocks by event type: %w", err)
+	}
+	return blockIDs, nil
+}
+
+// EventTypeIndexHeights returns the first and latest heights of the event type index.
+// Returns storage.ErrNotFound if no block was indexed yet.
+func (e *Events) EventTypeIndexHeights() (uint64, uint64, error) {
+	var first, latest uint64
+	err := e.db.View(func(tx *badger.Txn) error {
+		err := operation.RetrieveEventTypeFirstHeight(&first)(tx)
+		if err != nil {
+			return err
+		}
+		return operation.RetrieveEventTypeLatestHeight(&latest)(tx)
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not retrieve event type index heights: %w", err)
+	}
+	return first, latest, nil
+}
+
 // RemoveByBlockID removes events by block ID
 func (e *Events) RemoveByBlockID(blockID flow.Identifier) error {
 	return e.db.Update(operation.RemoveEventsByBlockID(blockID))
@@ -5,11 +5,13 @@ import (
 	"testing"
 
 	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/fvm/systemcontracts"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
 	badgerstorage "github.com/onflow/flow-go/storage/badger"
 	"github.com/onflow/flow-go/utils/unittest"
 )
@@ -122,3 +124,62 @@ func TestEventRetrieveWithoutStore(t *testing.T) {
 
 	})
 }
+
+func TestEventTypeIndex(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		metrics := metrics.NewNoopCollector()
+		store := badgerstorage.NewEvents(metrics, db)
+
+		_, _, err := store.EventTypeIndexHeights()
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		// "A.0000000000000001.Foo.Bar" must not match the longer "A.0000000000000001.Foo.BarBaz"
+		bar := flow.EventType("A.0000000000000001.Foo.Bar")
+		barBaz := flow.EventType("A.0000000000000001.Foo.BarBaz")
+
+		blockIDs := unittest.IdentifierListFixture(5)
+		events := map[uint64][]flow.Event{
+			10: {unittest.EventFixture(bar, 0, 0, unittest.IdentifierFixture(), 0), unittest.EventFixture(bar, 1, 1, unittest.IdentifierFixture(), 0)},
+			11: {unittest.EventFixture(barBaz, 0, 0, unittest.IdentifierFixture(), 0)},
+			12: {},
+			13: {unittest.EventFixture(bar, 0, 0, unittest.IdentifierFixture(), 0)},
+			14: {unittest.EventFixture(barBaz, 0, 0, unittest.IdentifierFixture(), 0), unittest.EventFixture(bar, 1, 1, unittest.IdentifierFixture(), 0)},
+		}
+
+		for height := uint64(10); height <= 14; height++ {
+			batch := badgerstorage.NewBatch(db)
+			require.NoError(t, store.BatchIndexByEventType(blockIDs[height-10], height, events[height], batch))
+			require.NoError(t, batch.Flush())
+		}
+
+		first, latest, err := store.EventTypeIndexHeights()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), first)
+		assert.Equal(t, uint64(14), latest)
+
+		// heights can only be indexed in order
+		err = store.BatchIndexByEventType(unittest.IdentifierFixture(), 16, nil, badgerstorage.NewBatch(db))
+		require.Error(t, err)
+
+		actual, err := store.BlockIDsByEventType(bar, 0, 100, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Identifier{blockIDs[0], blockIDs[3], blockIDs[4]}, actual)
+
+		actual, err = store.BlockIDsByEventType(barBaz, 0, 100, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Identifier{blockIDs[1], blockIDs[4]}, actual)
+
+		// height range and limit
+		actual, err = store.BlockIDsByEventType(bar, 11, 13, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Identifier{blockIDs[3]}, actual)
+
+		actual, err = store.BlockIDsByEventType(bar, 0, 100, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Identifier{blockIDs[0], blockIDs[3]}, actual)
+
+		actual, err = store.BlockIDsByEventType(flow.EventAccountCreated, 0, 100, 10)
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
@@ -0,0 +1,102 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// accountTransactionKey returns the key indexing one role of the account in the transaction. Each
+// role is stored under its own key, so that roles can be added without reading the indexed roles.
+func accountTransactionKey(address flow.Address, height uint64, txID flow.Identifier, role flow.AccountTransactionRole) []byte {
+	return makePrefix(codeAccountTransaction, address, height, txID, uint8(role))
+}
+
+// BatchIndexAccountTransaction indexes the roles of the account in the transaction.
+func BatchIndexAccountTransaction(entry flow.AccountTransaction) func(*badger.WriteBatch) error {
+	return func(batch *badger.WriteBatch) error {
+		for _, role := range entry.Roles.Roles() {
+			err := batch.Set(accountTransactionKey(entry.Address, entry.BlockHeight, entry.TransactionID, role), nil)
+			if err != nil {
+				return fmt.Errorf("could not index role %s: %w", role, err)
+			}
+		}
+		return nil
+	}
+}
+
+// LookupAccountTransactions retrieves up to limit transactions of the account between the start and
+// end heights (inclusive), ordered by height and transaction ID, and starting after the cursor if
+// it is not nil.
+func LookupAccountTransactions(
+	address flow.Address,
+	startHeight uint64,
+	endHeight uint64,
+	cursor *flow.AccountTransactionCursor,
+	limit uint,
+	entries *[]flow.AccountTransaction,
+) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		prefix := makePrefix(codeAccountTransaction, address)
+		keyLength := len(prefix) + 8 + flow.IdentifierLen + 1
+
+		start := makePrefix(codeAccountTransaction, address, startHeight)
+		if cursor != nil {
+			// the highest possible role skips all keys of the entry the cursor points to
+			after := accountTransactionKey(address, cursor.BlockHeight, cursor.TransactionID, flow.AccountTransactionRole(0xff))
+			if bytes.Compare(after, start) > 0 {
+				start = after
+			}
+		}
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		result := make([]flow.AccountTransaction, 0)
+		for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Item().Key()
+			if len(key) != keyLength {
+				return fmt.Errorf("invalid account transaction key: %x", key)
+			}
+
+			height := binary.BigEndian.Uint64(key[len(prefix):])
+			if height > endHeight {
+				break
+			}
+
+			var txID flow.Identifier
+			copy(txID[:], key[len(prefix)+8:])
+			role := flow.AccountTransactionRole(key[keyLength-1])
+
+			// the roles of an entry are stored under consecutive keys
+			last := len(result) - 1
+			if last >= 0 && result[last].BlockHeight == height && result[last].TransactionID == txID {
+				result[last].Roles |= role
+				continue
+			}
+
+			if uint(len(result)) >= limit {
+				break
+			}
+
+			result = append(result, flow.AccountTransaction{
+				Address:       address,
+				BlockHeight:   height,
+				TransactionID: txID,
+				Roles:         role,
+			})
+		}
+
+		*entries = result
+
+		return nil
+	}
+}
@@ -3,7 +3,11 @@
 package operation
 
 import (
+	"encoding/binary"
+	"fmt"
+
 	"github.com/dgraph-io/badger/v2"
+	"github.com/vmihailenco/msgpack/v4"
 
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -56,6 +60,89 @@ func RemoveEventsByBlockID(blockID flow.Identifier) func(*badger.Txn) error {
 	return removeByPrefix(makePrefix(codeEvent, blockID))
 }
 
+// eventTypePrefix returns the key prefix of the event type index for the given event type, followed
+// by the given keys. The event type is length prefixed, so that the keys of an event type never
+// share a prefix with the keys of another event type.
+func eventTypePrefix(eventType flow.EventType, keys ...interface{}) []byte {
+	return makePrefix(codeEventType, append([]interface{}{uint32(len(eventType)), string(eventType)}, keys...)...)
+}
+
+// BatchIndexEventType indexes the event by its type, mapping the height and position of the event
+// to the ID of the block containing it.
+func BatchIndexEventType(height uint64, blockID flow.Identifier, event flow.Event) func(*badger.WriteBatch) error {
+	return batchWrite(eventTypePrefix(event.Type, height, event.TransactionIndex, event.EventIndex), blockID)
+}
+
+// LookupBlockIDsByEventType retrieves the IDs of up to limit blocks between the start and end
+// heights (inclusive) that contain events of the given type, ordered by height. Only the first
+// index entry of each block is read, so the lookup time is proportional to the number of matching
+// blocks rather than the size of the height range.
+func LookupBlockIDsByEventType(
+	eventType flow.EventType,
+	startHeight uint64,
+	endHeight uint64,
+	limit uint,
+	blockIDs *[]flow.Identifier,
+) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		prefix := eventTypePrefix(eventType)
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		result := make([]flow.Identifier, 0)
+		it.Seek(eventTypePrefix(eventType, startHeight))
+		for uint(len(result)) < limit && it.ValidForPrefix(prefix) {
+			item := it.Item()
+			key := item.Key()
+			if len(key) < len(prefix)+8 {
+				return fmt.Errorf("invalid event type key: %x", key)
+			}
+
+			height := binary.BigEndian.Uint64(key[len(prefix):])
+			if height > endHeight {
+				break
+			}
+
+			var blockID flow.Identifier
+			err := item.Value(func(val []byte) error {
+				return msgpack.Unmarshal(val, &blockID)
+			})
+			if err != nil {
+				return fmt.Errorf("could not decode block ID: %w", err)
+			}
+			result = append(result, blockID)
+
+			// skip the remaining events of the block
+			it.Seek(eventTypePrefix(eventType, height+1))
+		}
+
+		*blockIDs = result
+
+		return nil
+	}
+}
+
+func BatchInsertEventTypeFirstHeight(height uint64) func(*badger.WriteBatch) error {
+	return batchWrite(makePrefix(codeEventTypeFirstHeight), height)
+}
+
+func RetrieveEventTypeFirstHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeEventTypeFirstHeight), height)
+}
+
+func BatchUpdateEventTypeLatestHeight(height uint64) func(*badger.WriteBatch) error {
+	return batchWrite(makePrefix(codeEventTypeLatestHeight), height)
+}
+
+func RetrieveEventTypeLatestHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeEventTypeLatestHeight), height)
+}
+
 // eventIterationFunc returns an in iteration function which returns all events found during traversal or iteration
 func eventIterationFunc(events *[]flow.Event) func() (checkFunc, createFunc, handleFunc) {
 	return func() (checkFunc, createFunc, handleFunc) {
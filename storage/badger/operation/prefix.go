@@ -31,6 +31,10 @@ const (
 	codeExecutedBlock           = 23 // latest executed block with max height
 	codeRootHeight              = 24 // the height of the first loaded block
 	codeLastCompleteBlockHeight = 25 // the height of the last block for which all collections were received
+	codeRegisterFirstHeight     = 26 // the first height of the register index
+	codeRegisterLatestHeight    = 27 // the latest height of the register index
+	codeEventTypeFirstHeight    = 28 // the first height of the event type index
+	codeEventTypeLatestHeight   = 29 // the latest height of the event type index
 
 	// codes for single entity storage
 	// 31 was used for identities before epochs
@@ -72,6 +76,15 @@ const (
 	codeDKGStarted       = 64 // flag that the DKG for an epoch has been started
 	codeDKGEnded         = 65 // flag that the DKG for an epoch has ended (stores end state)
 
+	// codes for the register index
+	codeRegister = 66 // register values keyed by register ID and height
+
+	// codes for the account transaction index
+	codeAccountTransaction = 67 // index mapping account address and height to transaction IDs
+
+	// codes for the event type index
+	codeEventType = 68 // index mapping event type and height to block IDs
+
 	// job queue consumers and producers
 	codeJobConsumerProcessed = 70
 	codeJobQueue             = 71
@@ -123,6 +136,8 @@ func b(v interface{}) []byte {
 		return []byte{byte(i)}
 	case flow.Identifier:
 		return i[:]
+	case flow.Address:
+		return i[:]
 	case flow.ChainID:
 		return []byte(i)
 	default:
@@ -0,0 +1,137 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// registerPrefix returns the prefix shared by all values of the given register. Owner and key are
+// length prefixed, so that the prefix of a register is never a prefix of another register's prefix.
+func registerPrefix(id flow.RegisterID) []byte {
+	return makePrefix(codeRegister, uint32(len(id.Owner)), id.Owner, uint32(len(id.Key)), id.Key)
+}
+
+// registerKey returns the key of the register value at the given height. The height is inverted,
+// so that the values of a register are ordered from the highest to the lowest height.
+func registerKey(id flow.RegisterID, height uint64) []byte {
+	return append(registerPrefix(id), b(^height)...)
+}
+
+// BatchInsertRegister inserts the value of the register at the given height.
+func BatchInsertRegister(height uint64, entry flow.RegisterEntry) func(*badger.WriteBatch) error {
+	return batchWrite(registerKey(entry.Key, height), entry.Value)
+}
+
+// RetrieveRegister retrieves the value of the register at the given height, which is the value
+// stored at the highest height lower than or equal to the given height.
+// Returns storage.ErrNotFound if no value was stored for the register at or below the height.
+func RetrieveRegister(height uint64, id flow.RegisterID, value *flow.RegisterValue) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		prefix := registerPrefix(id)
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// since heights are inverted, the first key at or after the seek key holds the value at
+		// the highest height lower than or equal to the given height
+		it.Seek(registerKey(id, height))
+		if !it.ValidForPrefix(prefix) {
+			return storage.ErrNotFound
+		}
+
+		err := it.Item().Value(func(val []byte) error {
+			return msgpack.Unmarshal(val, value)
+		})
+		if err != nil {
+			return fmt.Errorf("could not decode register value: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// PruneRegisters adds the deletion of all register values that are not needed to retrieve the value
+// of any register at a height greater than or equal to the given height to the write batch. For
+// each register, the value at the highest height lower than or equal to the given height is kept,
+// as it is the value of the register at the given height.
+func PruneRegisters(height uint64, batch *badger.WriteBatch) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		prefix := makePrefix(codeRegister)
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// the values of each register are ordered from the highest to the lowest height
+		var current []byte
+		kept := false
+		for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if len(key) < len(prefix)+8 {
+				return fmt.Errorf("invalid register key: %x", key)
+			}
+
+			registerPrefix := key[:len(key)-8]
+			valueHeight := ^binary.BigEndian.Uint64(key[len(key)-8:])
+
+			if !bytes.Equal(registerPrefix, current) {
+				current = registerPrefix
+				kept = false
+			}
+
+			if valueHeight > height {
+				continue
+			}
+
+			if !kept {
+				kept = true
+				continue
+			}
+
+			err := batch.Delete(key)
+			if err != nil {
+				return fmt.Errorf("could not delete register value: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func InsertRegisterFirstHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeRegisterFirstHeight), height)
+}
+
+func UpdateRegisterFirstHeight(height uint64) func(*badger.Txn) error {
+	return update(makePrefix(codeRegisterFirstHeight), height)
+}
+
+func RetrieveRegisterFirstHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeRegisterFirstHeight), height)
+}
+
+func InsertRegisterLatestHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeRegisterLatestHeight), height)
+}
+
+func BatchUpdateRegisterLatestHeight(height uint64) func(*badger.WriteBatch) error {
+	return batchWrite(makePrefix(codeRegisterLatestHeight), height)
+}
+
+func RetrieveRegisterLatestHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeRegisterLatestHeight), height)
+}
@@ -0,0 +1,77 @@
+package operation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestRegisterInsertRetrieve(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		id := flow.NewRegisterID(string(unittest.RandomAddressFixture().Bytes()), "key")
+
+		batch := db.NewWriteBatch()
+		require.NoError(t, BatchInsertRegister(10, flow.RegisterEntry{Key: id, Value: []byte("v10")})(batch))
+		require.NoError(t, BatchInsertRegister(12, flow.RegisterEntry{Key: id, Value: []byte("v12")})(batch))
+		require.NoError(t, batch.Flush())
+
+		var value flow.RegisterValue
+		err := db.View(RetrieveRegister(9, id, &value))
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		for height, expected := range map[uint64]string{10: "v10", 11: "v10", 12: "v12", 100: "v12"} {
+			err = db.View(RetrieveRegister(height, id, &value))
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(value), "height %d", height)
+		}
+	})
+}
+
+func TestPruneRegisters(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		owner := string(unittest.RandomAddressFixture().Bytes())
+		a := flow.NewRegisterID(owner, "a")
+		b := flow.NewRegisterID(owner, "b")
+
+		batch := db.NewWriteBatch()
+		for _, height := range []uint64{10, 11, 12, 13} {
+			require.NoError(t, BatchInsertRegister(height, flow.RegisterEntry{Key: a, Value: []byte{byte(height)}})(batch))
+		}
+		require.NoError(t, BatchInsertRegister(10, flow.RegisterEntry{Key: b, Value: []byte{10}})(batch))
+		require.NoError(t, BatchInsertRegister(13, flow.RegisterEntry{Key: b, Value: []byte{13}})(batch))
+		require.NoError(t, batch.Flush())
+
+		batch = db.NewWriteBatch()
+		require.NoError(t, db.View(PruneRegisters(12, batch)))
+		require.NoError(t, batch.Flush())
+
+		exists := func(id flow.RegisterID, height uint64) bool {
+			err := db.View(func(tx *badger.Txn) error {
+				_, err := tx.Get(registerKey(id, height))
+				return err
+			})
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return false
+			}
+			require.NoError(t, err)
+			return true
+		}
+
+		// values below the value at the pruned height are removed
+		assert.False(t, exists(a, 10))
+		assert.False(t, exists(a, 11))
+		assert.True(t, exists(a, 12))
+		assert.True(t, exists(a, 13))
+
+		// the value of b at the pruned height was written at 10, and is kept
+		assert.True(t, exists(b, 10))
+		assert.True(t, exists(b, 13))
+	})
+}
@@ -0,0 +1,248 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// Registers implements storage.Registers on top of a badger database. Every value is stored under
+// the register ID and the height at which it was written, so the value at any indexed height is
+// found with a single seek.
+type Registers struct {
+	db           *badger.DB
+	firstHeight  *atomic.Uint64
+	latestHeight *atomic.Uint64
+}
+
+var _ storage.Registers = (*Registers)(nil)
+
+// NewRegisters returns the register index stored in the given database.
+// Expected errors:
+// - storage.ErrNotFound if the index was not bootstrapped using BootstrapRegisters
+func NewRegisters(db *badger.DB) (*Registers, error) {
+	var firstHeight, latestHeight uint64
+
+	err := db.View(func(tx *badger.Txn) error {
+		err := operation.RetrieveRegisterFirstHeight(&firstHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve first height: %w", err)
+		}
+		err = operation.RetrieveRegisterLatestHeight(&latestHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve latest height: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Registers{
+		db:           db,
+		firstHeight:  atomic.NewUint64(firstHeight),
+		latestHeight: atomic.NewUint64(latestHeight),
+	}, nil
+}
+
+// BootstrapRegisters initializes an empty register index with the full register state at the given
+// height. The heights are written last, so an interrupted bootstrap can be restarted.
+// Expected errors:
+// - storage.ErrAlreadyExists if the index was already bootstrapped
+func BootstrapRegisters(db *badger.DB, height uint64, entries flow.RegisterEntries) error {
+	bootstrap, err := NewRegistersBootstrap(db, height)
+	if err != nil {
+		return err
+	}
+
+	err = bootstrap.Store(entries)
+	if err != nil {
+		return err
+	}
+
+	return bootstrap.Finish()
+}
+
+// RegistersBootstrap initializes an empty register index with a register state too large to be
+// kept in memory, which is stored in batches. The heights are written by Finish, once all
+// registers are stored, so an interrupted bootstrap can be restarted.
+type RegistersBootstrap struct {
+	db     *badger.DB
+	height uint64
+}
+
+// NewRegistersBootstrap returns a bootstrap of the empty register index in the given database with
+// the register state at the given height.
+// Expected errors:
+// - storage.ErrAlreadyExists if the index was already bootstrapped
+func NewRegistersBootstrap(db *badger.DB, height uint64) (*RegistersBootstrap, error) {
+	bootstrapped, err := IsRegistersBootstrapped(db)
+	if err != nil {
+		return nil, err
+	}
+	if bootstrapped {
+		return nil, storage.ErrAlreadyExists
+	}
+
+	return &RegistersBootstrap{
+		db:     db,
+		height: height,
+	}, nil
+}
+
+// Store stores a batch of the register values at the bootstrap height.
+func (b *RegistersBootstrap) Store(entries flow.RegisterEntries) error {
+	batch := b.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	for _, entry := range entries {
+		err := operation.BatchInsertRegister(b.height, entry)(batch)
+		if err != nil {
+			return fmt.Errorf("could not insert register %s: %w", entry.Key.String(), err)
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush registers: %w", err)
+	}
+
+	return nil
+}
+
+// Finish completes the bootstrap once all register values are stored, the bootstrap height
+// becomes the first and latest indexed height.
+func (b *RegistersBootstrap) Finish() error {
+	return operation.RetryOnConflict(b.db.Update, func(tx *badger.Txn) error {
+		err := operation.InsertRegisterFirstHeight(b.height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert first height: %w", err)
+		}
+		err = operation.InsertRegisterLatestHeight(b.height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert latest height: %w", err)
+		}
+		return nil
+	})
+}
+
+// IsRegistersBootstrapped returns true if the register index in the given database was bootstrapped.
+func IsRegistersBootstrapped(db *badger.DB) (bool, error) {
+	var firstHeight uint64
+	err := db.View(operation.RetrieveRegisterFirstHeight(&firstHeight))
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve first height: %w", err)
+	}
+	return true, nil
+}
+
+// Get returns the value of the register at the given height.
+// Expected errors:
+// - storage.ErrNotFound if the register was never set at or below the given height
+// - storage.ErrHeightNotIndexed if the height is outside of the indexed range
+func (r *Registers) Get(id flow.RegisterID, height uint64) (flow.RegisterValue, error) {
+	firstHeight := r.firstHeight.Load()
+	latestHeight := r.latestHeight.Load()
+	if height < firstHeight || height > latestHeight {
+		return nil, fmt.Errorf("height %d is outside of the indexed range [%d, %d]: %w",
+			height, firstHeight, latestHeight, storage.ErrHeightNotIndexed)
+	}
+
+	var value flow.RegisterValue
+	err := r.db.View(operation.RetrieveRegister(height, id, &value))
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Store stores the register values updated at the given height, which must be the latest indexed
+// height + 1. Storing the same height again after a crash overwrites the values that were
+// partially written.
+func (r *Registers) Store(entries flow.RegisterEntries, height uint64) error {
+	latestHeight := r.latestHeight.Load()
+	if height != latestHeight+1 {
+		return fmt.Errorf("must store registers with the next height %d, but got %d", latestHeight+1, height)
+	}
+
+	batch := r.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	for _, entry := range entries {
+		err := operation.BatchInsertRegister(height, entry)(batch)
+		if err != nil {
+			return fmt.Errorf("could not insert register %s: %w", entry.Key.String(), err)
+		}
+	}
+
+	err := operation.BatchUpdateRegisterLatestHeight(height)(batch)
+	if err != nil {
+		return fmt.Errorf("could not update latest height: %w", err)
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush registers: %w", err)
+	}
+
+	r.latestHeight.Store(height)
+
+	return nil
+}
+
+// Prune removes the register values that are only needed for heights lower than the given height,
+// which becomes the first indexed height. Pruning a height lower than the first indexed height is a
+// no-op, while pruning the first indexed height again completes a previously interrupted pruning.
+func (r *Registers) Prune(height uint64) error {
+	if height < r.firstHeight.Load() {
+		return nil
+	}
+
+	latestHeight := r.latestHeight.Load()
+	if height > latestHeight {
+		return fmt.Errorf("cannot prune height %d above the latest indexed height %d", height, latestHeight)
+	}
+
+	// the first height is updated before removing any value, so that no value is read at a
+	// pruned height, even if the pruning is interrupted
+	err := operation.RetryOnConflict(r.db.Update, operation.UpdateRegisterFirstHeight(height))
+	if err != nil {
+		return fmt.Errorf("could not update first height: %w", err)
+	}
+	r.firstHeight.Store(height)
+
+	batch := r.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	err = r.db.View(operation.PruneRegisters(height, batch))
+	if err != nil {
+		return fmt.Errorf("could not prune registers: %w", err)
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush pruned registers: %w", err)
+	}
+
+	return nil
+}
+
+// FirstHeight returns the first height of the indexed range.
+func (r *Registers) FirstHeight() uint64 {
+	return r.firstHeight.Load()
+}
+
+// LatestHeight returns the latest height of the indexed range.
+func (r *Registers) LatestHeight() uint64 {
+	return r.latestHeight.Load()
+}
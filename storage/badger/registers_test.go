@@ -0,0 +1,205 @@
+package badger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+// TestRegistersBootstrap tests that the register index can only be opened after it was bootstrapped,
+// and only be bootstrapped once
+func TestRegistersBootstrap(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		_, err := badgerstorage.NewRegisters(db)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		err = badgerstorage.BootstrapRegisters(db, 10, nil)
+		require.NoError(t, err)
+
+		err = badgerstorage.BootstrapRegisters(db, 10, nil)
+		assert.True(t, errors.Is(err, storage.ErrAlreadyExists))
+
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), registers.FirstHeight())
+		assert.Equal(t, uint64(10), registers.LatestHeight())
+	})
+}
+
+// TestRegistersBootstrapInBatches tests that the register index can only be opened once all batches
+// of a bootstrap were stored, and that an interrupted bootstrap can be restarted
+func TestRegistersBootstrapInBatches(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		owner := string(unittest.RandomAddressFixture().Bytes())
+		a := flow.NewRegisterID(owner, "a")
+		b := flow.NewRegisterID(owner, "b")
+
+		// interrupted bootstrap
+		bootstrap, err := badgerstorage.NewRegistersBootstrap(db, 10)
+		require.NoError(t, err)
+		require.NoError(t, bootstrap.Store(flow.RegisterEntries{{Key: a, Value: []byte("a")}}))
+
+		_, err = badgerstorage.NewRegisters(db)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		bootstrap, err = badgerstorage.NewRegistersBootstrap(db, 10)
+		require.NoError(t, err)
+		require.NoError(t, bootstrap.Store(flow.RegisterEntries{{Key: a, Value: []byte("a")}}))
+		require.NoError(t, bootstrap.Store(flow.RegisterEntries{{Key: b, Value: []byte("b")}}))
+		require.NoError(t, bootstrap.Finish())
+
+		_, err = badgerstorage.NewRegistersBootstrap(db, 10)
+		assert.True(t, errors.Is(err, storage.ErrAlreadyExists))
+
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+		for id, value := range map[flow.RegisterID]string{a: "a", b: "b"} {
+			actual, err := registers.Get(id, 10)
+			require.NoError(t, err)
+			assert.Equal(t, value, string(actual))
+		}
+	})
+}
+
+// TestRegistersGetAtHeight tests that the value of a register at any indexed height is the value
+// written at the highest height lower than or equal to the requested height
+func TestRegistersGetAtHeight(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		owner := string(unittest.RandomAddressFixture().Bytes())
+		id := flow.NewRegisterID(owner, "key")
+		// registers whose key is a prefix of, or prefixed by, the register's key
+		shorter := flow.NewRegisterID(owner, "ke")
+		longer := flow.NewRegisterID(owner, "key1")
+		other := flow.NewRegisterID(owner, "other")
+
+		err := badgerstorage.BootstrapRegisters(db, 10, flow.RegisterEntries{
+			{Key: id, Value: []byte("v10")},
+			{Key: shorter, Value: []byte("s10")},
+		})
+		require.NoError(t, err)
+
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: longer, Value: []byte("l11")}}, 11))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: id, Value: []byte("v12")}}, 12))
+		require.NoError(t, registers.Store(nil, 13))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: id, Value: []byte{}}}, 14))
+
+		expected := map[uint64]flow.RegisterValue{
+			10: []byte("v10"),
+			11: []byte("v10"),
+			12: []byte("v12"),
+			13: []byte("v12"),
+			14: {},
+		}
+		for height, value := range expected {
+			actual, err := registers.Get(id, height)
+			require.NoError(t, err)
+			assert.Equal(t, len(value), len(actual), "height %d", height)
+			assert.Equal(t, string(value), string(actual), "height %d", height)
+		}
+
+		value, err := registers.Get(shorter, 14)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("s10"), value)
+
+		// register written after the requested height
+		_, err = registers.Get(longer, 10)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		// register never written
+		_, err = registers.Get(other, 14)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		// heights outside of the indexed range
+		_, err = registers.Get(id, 9)
+		assert.True(t, errors.Is(err, storage.ErrHeightNotIndexed))
+		_, err = registers.Get(id, 15)
+		assert.True(t, errors.Is(err, storage.ErrHeightNotIndexed))
+	})
+}
+
+// TestRegistersStoreOutOfOrder tests that heights can only be stored in order
+func TestRegistersStoreOutOfOrder(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		err := badgerstorage.BootstrapRegisters(db, 10, nil)
+		require.NoError(t, err)
+
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		assert.Error(t, registers.Store(nil, 10))
+		assert.Error(t, registers.Store(nil, 12))
+		assert.NoError(t, registers.Store(nil, 11))
+		assert.Equal(t, uint64(11), registers.LatestHeight())
+
+		// the latest height is persisted
+		registers, err = badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(11), registers.LatestHeight())
+	})
+}
+
+// TestRegistersPrune tests that pruning keeps the values of all registers at and above the pruned
+// height, and removes the heights below it from the indexed range
+func TestRegistersPrune(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		owner := string(unittest.RandomAddressFixture().Bytes())
+		a := flow.NewRegisterID(owner, "a")
+		b := flow.NewRegisterID(owner, "b")
+		c := flow.NewRegisterID(owner, "c")
+
+		err := badgerstorage.BootstrapRegisters(db, 10, flow.RegisterEntries{
+			{Key: a, Value: []byte("a10")},
+			{Key: b, Value: []byte("b10")},
+		})
+		require.NoError(t, err)
+
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: a, Value: []byte("a11")}}, 11))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: a, Value: []byte("a12")}, {Key: c, Value: []byte("c12")}}, 12))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: a, Value: []byte("a13")}}, 13))
+
+		// pruning above the latest height fails
+		assert.Error(t, registers.Prune(14))
+
+		require.NoError(t, registers.Prune(12))
+		assert.Equal(t, uint64(12), registers.FirstHeight())
+
+		expected := map[uint64]map[flow.RegisterID]string{
+			12: {a: "a12", b: "b10", c: "c12"},
+			13: {a: "a13", b: "b10", c: "c12"},
+		}
+		for height, values := range expected {
+			for id, value := range values {
+				actual, err := registers.Get(id, height)
+				require.NoError(t, err)
+				assert.Equal(t, value, string(actual), "register %s at height %d", id, height)
+			}
+		}
+
+		_, err = registers.Get(a, 11)
+		assert.True(t, errors.Is(err, storage.ErrHeightNotIndexed))
+
+		// pruning below the first height is a no-op
+		require.NoError(t, registers.Prune(11))
+		assert.Equal(t, uint64(12), registers.FirstHeight())
+
+		// the first height is persisted
+		registers, err = badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(12), registers.FirstHeight())
+	})
+}
@@ -14,4 +14,8 @@ var (
 
 	ErrAlreadyExists = errors.New("key already exists")
 	ErrDataMismatch  = errors.New("data for key is different")
+
+	// ErrHeightNotIndexed is returned when data is requested for a height outside of the range
+	// covered by a height based index.
+	ErrHeightNotIndexed = errors.New("height not indexed")
 )
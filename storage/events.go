@@ -19,6 +19,18 @@ type Events interface {
 
 	// ByBlockIDEventType returns the events for the given block ID and event type
 	ByBlockIDEventType(blockID flow.Identifier, eventType flow.EventType) ([]flow.Event, error)
+
+	// BatchIndexByEventType indexes the events of the block at the given height by event type in a given batch.
+	// Blocks must be indexed in height order, the first indexed block can be at any height.
+	BatchIndexByEventType(blockID flow.Identifier, height uint64, events []flow.Event, batch BatchStorage) error
+
+	// BlockIDsByEventType returns the IDs of up to limit blocks between the start and end heights (inclusive)
+	// that contain events of the given type, ordered by height.
+	BlockIDsByEventType(eventType flow.EventType, startHeight uint64, endHeight uint64, limit uint) ([]flow.Identifier, error)
+
+	// EventTypeIndexHeights returns the first and latest heights of the event type index.
+	// Returns storage.ErrNotFound if no block was indexed yet.
+	EventTypeIndexHeights() (uint64, uint64, error)
 }
 
 type ServiceEvents interface {
@@ -14,6 +14,20 @@ type Events struct {
 	mock.Mock
 }
 
+// BatchIndexByEventType provides a mock function with given fields: blockID, height, events, batch
+func (_m *Events) BatchIndexByEventType(blockID flow.Identifier, height uint64, events []flow.Event, batch storage.BatchStorage) error {
+	ret := _m.Called(blockID, height, events, batch)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(flow.Identifier, uint64, []flow.Event, storage.BatchStorage) error); ok {
+		r0 = rf(blockID, height, events, batch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BatchStore provides a mock function with given fields: blockID, events, batch
 func (_m *Events) BatchStore(blockID flow.Identifier, events []flow.EventsList, batch storage.BatchStorage) error {
 	ret := _m.Called(blockID, events, batch)
@@ -28,6 +42,29 @@ func (_m *Events) BatchStore(blockID flow.Identifier, events []flow.EventsList,
 	return r0
 }
 
+// BlockIDsByEventType provides a mock function with given fields: eventType, startHeight, endHeight, limit
+func (_m *Events) BlockIDsByEventType(eventType flow.EventType, startHeight uint64, endHeight uint64, limit uint) ([]flow.Identifier, error) {
+	ret := _m.Called(eventType, startHeight, endHeight, limit)
+
+	var r0 []flow.Identifier
+	if rf, ok := ret.Get(0).(func(flow.EventType, uint64, uint64, uint) []flow.Identifier); ok {
+		r0 = rf(eventType, startHeight, endHeight, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]flow.Identifier)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(flow.EventType, uint64, uint64, uint) error); ok {
+		r1 = rf(eventType, startHeight, endHeight, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ByBlockID provides a mock function with given fields: blockID
 func (_m *Events) ByBlockID(blockID flow.Identifier) ([]flow.Event, error) {
 	ret := _m.Called(blockID)
@@ -120,6 +157,34 @@ func (_m *Events) ByBlockIDTransactionIndex(blockID flow.Identifier, txIndex uin
 	return r0, r1
 }
 
+// EventTypeIndexHeights provides a mock function with given fields:
+func (_m *Events) EventTypeIndexHeights() (uint64, uint64, error) {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func() uint64); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func() error); ok {
+		r2 = rf()
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 type mockConstructorTestingTNewEvents interface {
 	mock.TestingT
 	Cleanup(func())
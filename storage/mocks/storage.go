@@ -541,6 +541,20 @@ func (m *MockEvents) EXPECT() *MockEventsMockRecorder {
 	return m.recorder
 }
 
+// BatchIndexByEventType mocks base method
+func (m *MockEvents) BatchIndexByEventType(arg0 flow.Identifier, arg1 uint64, arg2 []flow.Event, arg3 storage.BatchStorage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchIndexByEventType", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchIndexByEventType indicates an expected call of BatchIndexByEventType
+func (mr *MockEventsMockRecorder) BatchIndexByEventType(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchIndexByEventType", reflect.TypeOf((*MockEvents)(nil).BatchIndexByEventType), arg0, arg1, arg2, arg3)
+}
+
 // BatchStore mocks base method
 func (m *MockEvents) BatchStore(arg0 flow.Identifier, arg1 []flow.EventsList, arg2 storage.BatchStorage) error {
 	m.ctrl.T.Helper()
@@ -555,6 +569,21 @@ func (mr *MockEventsMockRecorder) BatchStore(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchStore", reflect.TypeOf((*MockEvents)(nil).BatchStore), arg0, arg1, arg2)
 }
 
+// BlockIDsByEventType mocks base method
+func (m *MockEvents) BlockIDsByEventType(arg0 flow.EventType, arg1, arg2 uint64, arg3 uint) ([]flow.Identifier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockIDsByEventType", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]flow.Identifier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockIDsByEventType indicates an expected call of BlockIDsByEventType
+func (mr *MockEventsMockRecorder) BlockIDsByEventType(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockIDsByEventType", reflect.TypeOf((*MockEvents)(nil).BlockIDsByEventType), arg0, arg1, arg2, arg3)
+}
+
 // ByBlockID mocks base method
 func (m *MockEvents) ByBlockID(arg0 flow.Identifier) ([]flow.Event, error) {
 	m.ctrl.T.Helper()
@@ -615,6 +644,22 @@ func (mr *MockEventsMockRecorder) ByBlockIDTransactionIndex(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ByBlockIDTransactionIndex", reflect.TypeOf((*MockEvents)(nil).ByBlockIDTransactionIndex), arg0, arg1)
 }
 
+// EventTypeIndexHeights mocks base method
+func (m *MockEvents) EventTypeIndexHeights() (uint64, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EventTypeIndexHeights")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EventTypeIndexHeights indicates an expected call of EventTypeIndexHeights
+func (mr *MockEventsMockRecorder) EventTypeIndexHeights() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EventTypeIndexHeights", reflect.TypeOf((*MockEvents)(nil).EventTypeIndexHeights))
+}
+
 // MockServiceEvents is a mock of ServiceEvents interface
 type MockServiceEvents struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Registers represents persistent storage for the history of register values, indexed by block
+// height. The index covers a contiguous range of heights, starting at the height it was
+// bootstrapped with the full execution state.
+type Registers interface {
+	// Get returns the value of the register at the given height, which is the value written at
+	// the highest indexed height lower than or equal to the given height.
+	// Expected errors:
+	// - storage.ErrNotFound if the register was never set at or below the given height
+	// - storage.ErrHeightNotIndexed if the height is outside of the indexed range
+	Get(ID flow.RegisterID, height uint64) (flow.RegisterValue, error)
+
+	// Store stores the register values updated at the given height.
+	// Heights must be stored in order, the height must be the latest indexed height + 1.
+	Store(entries flow.RegisterEntries, height uint64) error
+
+	// Prune removes the register values that are only needed for heights lower than the given
+	// height, which becomes the first indexed height. The height must not be above the latest
+	// indexed height.
+	Prune(height uint64) error
+
+	// FirstHeight returns the first height of the indexed range.
+	FirstHeight() uint64
+
+	// LatestHeight returns the latest height of the indexed range.
+	LatestHeight() uint64
+}
@@ -0,0 +1,124 @@
+package debug
+
+import (
+	"fmt"
+
+	"github.com/google/pprof/profile"
+
+	"github.com/onflow/flow-go/fvm/environment"
+)
+
+// ExecutionTraceProfile converts an execution trace to a pprof profile, so it can be explored
+// with `go tool pprof`. Every event of the trace is a frame, and each event contributes a sample
+// with the time spent, computation used and memory estimated in the event itself, excluding the
+// events nested in it.
+//
+// Each line of the executed statements contributes a sample with the number of statements
+// executed on it, which can be explored line by line with `go tool pprof -lines`.
+func ExecutionTraceProfile(trace *environment.ExecutionTrace) *profile.Profile {
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "time", Unit: "nanoseconds"},
+			{Type: "computation", Unit: "count"},
+			{Type: "memory", Unit: "bytes"},
+			{Type: "statements", Unit: "count"},
+		},
+		DefaultSampleType: "computation",
+		DurationNanos:     int64(trace.Duration),
+	}
+
+	// the values of events excluding the values of their children
+	self := make([][3]int64, len(trace.Events))
+	for i, event := range trace.Events {
+		self[i][0] += int64(event.Duration)
+		self[i][1] += int64(event.ComputationUsed)
+		self[i][2] += int64(event.MemoryEstimate)
+
+		if event.Parent >= 0 {
+			self[event.Parent][0] -= int64(event.Duration)
+			self[event.Parent][1] -= int64(event.ComputationUsed)
+			self[event.Parent][2] -= int64(event.MemoryEstimate)
+		}
+	}
+
+	locations := make(map[string]*profile.Location)
+	location := func(event environment.ExecutionTraceEvent) *profile.Location {
+		name := fmt.Sprintf("%s %s", event.Kind, event.Name)
+		if loc, ok := locations[name]; ok {
+			return loc
+		}
+
+		function := &profile.Function{
+			ID:       uint64(len(prof.Function) + 1),
+			Name:     name,
+			Filename: event.Location,
+		}
+		prof.Function = append(prof.Function, function)
+
+		loc := &profile.Location{
+			ID:   uint64(len(prof.Location) + 1),
+			Line: []profile.Line{{Function: function}},
+		}
+		prof.Location = append(prof.Location, loc)
+		locations[name] = loc
+		return loc
+	}
+
+	for i := range trace.Events {
+		// pprof stacks start with the innermost frame
+		var stack []*profile.Location
+		for j := i; j >= 0; j = trace.Events[j].Parent {
+			stack = append(stack, location(trace.Events[j]))
+		}
+
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: stack,
+			Value: []int64{
+				nonNegative(self[i][0]),
+				nonNegative(self[i][1]),
+				nonNegative(self[i][2]),
+				0,
+			},
+		})
+	}
+
+	// statements are recorded without their call stack, so they are frames of their program
+	programs := make(map[string]*profile.Function)
+	for _, statement := range trace.Statements {
+		function, ok := programs[statement.Location]
+		if !ok {
+			function = &profile.Function{
+				ID:       uint64(len(prof.Function) + 1),
+				Name:     statement.Location,
+				Filename: statement.Location,
+			}
+			prof.Function = append(prof.Function, function)
+			programs[statement.Location] = function
+		}
+
+		loc := &profile.Location{
+			ID: uint64(len(prof.Location) + 1),
+			Line: []profile.Line{{
+				Function: function,
+				Line:     int64(statement.Line),
+			}},
+		}
+		prof.Location = append(prof.Location, loc)
+
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: []*profile.Location{loc},
+			Value:    []int64{0, 0, 0, int64(statement.Hits)},
+		})
+	}
+
+	return prof
+}
+
+// nonNegative clamps negative values to 0. Events are recorded with timers and meter samples
+// which are not perfectly aligned, so the self values of an event can be slightly off.
+func nonNegative(value int64) int64 {
+	if value < 0 {
+		return 0
+	}
+	return value
+}
@@ -2,9 +2,11 @@ package debug
 
 import (
 	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime"
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
 	"github.com/onflow/flow-go/fvm/programs"
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -40,6 +42,15 @@ func NewRemoteDebugger(grpcAddress string,
 	}
 }
 
+// newTracingVM returns a VM which traces the Cadence function calls and records the executed
+// statements for the given tracer. The coverage report is set on the runtime rather than the
+// procedure, so each trace uses its own VM.
+func newTracingVM(tracer *environment.ExecutionTracer) *fvm.VirtualMachine {
+	rt := fvm.NewInterpreterRuntime(runtime.WithTracingEnabled(true))
+	rt.SetCoverageReport(tracer.CoverageReport())
+	return fvm.NewVirtualMachine(rt)
+}
+
 // RunTransaction runs the transaction given the latest sealed block data
 func (d *RemoteDebugger) RunTransaction(txBody *flow.TransactionBody) (txErr, processError error) {
 	view := NewRemoteView(d.grpcAddress)
@@ -76,6 +87,52 @@ func (d *RemoteDebugger) RunTransactionAtBlockID(txBody *flow.TransactionBody, b
 	return tx.Err, nil
 }
 
+// TraceTransaction runs the transaction given the latest sealed block data, and records its
+// execution trace, including how many times the statements of each line were executed. The trace can be exported as JSON, or as a pprof profile using ExecutionTraceProfile.
+func (d *RemoteDebugger) TraceTransaction(txBody *flow.TransactionBody) (trace *environment.ExecutionTrace, txErr, processError error) {
+	view := NewRemoteView(d.grpcAddress)
+	tracer := environment.NewExecutionTracer()
+	blockCtx := fvm.NewContextFromParent(
+		d.ctx,
+		fvm.WithBlockHeader(d.ctx.BlockHeader),
+		fvm.WithExecutionTracer(tracer),
+	)
+	tx := fvm.Transaction(txBody, 0)
+	err := newTracingVM(tracer).Run(blockCtx, tx, view, programs.NewEmptyPrograms())
+	if err != nil {
+		return nil, nil, err
+	}
+	return tracer.Trace(), tx.Err, nil
+}
+
+// TraceTransactionAtBlockID runs the transaction with the registers at the given blockID, and
+// records its execution trace. See RunTransactionAtBlockID for how registers are collected and cached.
+// Running the transaction from the cache the second time gives a trace without remote register reads.
+func (d *RemoteDebugger) TraceTransactionAtBlockID(txBody *flow.TransactionBody, blockID flow.Identifier, regCachePath string) (trace *environment.ExecutionTrace, txErr, processError error) {
+	view := NewRemoteView(d.grpcAddress, WithBlockID(blockID))
+	defer view.Done()
+
+	tracer := environment.NewExecutionTracer()
+	blockCtx := fvm.NewContextFromParent(
+		d.ctx,
+		fvm.WithBlockHeader(d.ctx.BlockHeader),
+		fvm.WithExecutionTracer(tracer),
+	)
+	if len(regCachePath) > 0 {
+		view.Cache = newFileRegisterCache(regCachePath)
+	}
+	tx := fvm.Transaction(txBody, 0)
+	err := newTracingVM(tracer).Run(blockCtx, tx, view, programs.NewEmptyPrograms())
+	if err != nil {
+		return nil, nil, err
+	}
+	err = view.Cache.Persist()
+	if err != nil {
+		return nil, nil, err
+	}
+	return tracer.Trace(), tx.Err, nil
+}
+
 func (d *RemoteDebugger) RunScript(code []byte, arguments [][]byte) (value cadence.Value, scriptError, processError error) {
 	view := NewRemoteView(d.grpcAddress)
 	scriptCtx := fvm.NewContextFromParent(d.ctx, fvm.WithBlockHeader(d.ctx.BlockHeader))